@@ -17,77 +17,13 @@ package appservice
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strconv"
 )
 
-type DeployInformation struct {
-	Namespace                 string
-	ImageInformationName      string
-	CurrentVersion            string
-	CurrentVersionDescription string
-	Description               string
-}
-
-type Service struct {
-	Name            string
-	Namespace       string
-	PortSlice       []ServicePort
-	Selector        map[string]interface{}
-	ClusterIP       string
-	LabelMap        map[string]interface{}
-	SessionAffinity string
-	Display         string
-}
-
-type ServicePort struct {
-	Name       string
-	Protocol   string
-	Port       int
-	TargetPort string
-	NodePort   int
-}
-
-type ReplicationControllerAndRelatedPod struct {
-	Name               string
-	Namespace          string
-	ReplicaAmount      int
-	AliveReplicaAmount int
-	Selector           map[string]string
-	Label              map[string]string
-	PodSlice           []Pod
-}
-
-type Pod struct {
-	Name           string
-	Namespace      string
-	HostIP         string
-	PodIP          string
-	ContainerSlice []PodContainer
-}
-
-type PodContainer struct {
-	Name      string
-	Image     string
-	PortSlice []PodContainerPort
-}
-
-type PodContainerPort struct {
-	Name          string
-	ContainerPort int
-	Protocol      string
-}
-
-type DeployClusterApplication struct {
-	Name                           string
-	Size                           int
-	ServiceName                    string
-	ReplicationControllerNameSlice []string
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -121,18 +57,13 @@ type DataController struct {
 }
 
 func (c *DataController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	scope := c.GetString("scope")
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
 	namespaceSlice := make([]string, 0)
 	if scope == allKeyword {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/namespaces/"
-
-		_, err := restclient.RequestGetWithStructure(url, &namespaceSlice, tokenHeaderMap)
+		allNamespaceSlice, err := cloudoneClient.GetNamespaceNameSlice()
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -143,17 +74,14 @@ func (c *DataController) Get() {
 			c.ServeJSON()
 			return
 		}
+
+		namespaceSlice = allNamespaceSlice
 	} else {
 		namespace, _ := c.GetSession("namespace").(string)
 		namespaceSlice = append(namespaceSlice, namespace)
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/"
-
-	deployInformationSlice := make([]DeployInformation, 0)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := cloudoneClient.GetAllDeployInformationSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -192,12 +120,7 @@ func (c *DataController) Get() {
 		applicationNamespaceJsonMap["color"] = dashboard.TextColorNamespace
 		applicationNamespaceJsonMap["children"] = make([]interface{}, 0)
 
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/services/" + namespace
-
-		serviceSlice := make([]Service, 0)
-
-		_, err := restclient.RequestGetWithStructure(url, &serviceSlice, tokenHeaderMap)
+		serviceSlice, err := cloudoneClient.GetServiceSlice(namespace)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -208,12 +131,7 @@ func (c *DataController) Get() {
 			c.Data["json"].(map[string]interface{})["error"] = "Get service data error"
 			c.Data["json"].(map[string]interface{})["errorMap"].(map[string]interface{})[namespace] = err.Error()
 		} else {
-			url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-				"/api/v1/replicationcontrollers/" + namespace
-
-			replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
-
-			_, err := restclient.RequestGetWithStructure(url, &replicationControllerAndRelatedPodSlice, tokenHeaderMap)
+			replicationControllerAndRelatedPodSlice, err := cloudoneClient.GetReplicationControllerAndRelatedPodSlice(namespace)
 
 			if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 				return
@@ -285,12 +203,7 @@ func (c *DataController) Get() {
 		thirdpartyNamespaceJsonMap["color"] = dashboard.TextColorNamespace
 		thirdpartyNamespaceJsonMap["children"] = make([]interface{}, 0)
 
-		url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/deployclusterapplications/" + namespace
-
-		deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
-
-		_, err = restclient.RequestGetWithStructure(url, &deployClusterApplicationSlice, tokenHeaderMap)
+		deployClusterApplicationSlice, err := cloudoneClient.GetDeployClusterApplicationSlice(namespace)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -17,29 +17,13 @@ package bluegreen
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strconv"
 )
 
-type DeployBlueGreen struct {
-	ImageInformation string
-	Namespace        string
-	NodePort         int
-	Description      string
-	SessionAffinity  string
-}
-
-type DeployInformation struct {
-	Namespace                 string
-	ImageInformationName      string
-	CurrentVersion            string
-	CurrentVersionDescription string
-	Description               string
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -69,17 +53,9 @@ type DataController struct {
 }
 
 func (c *DataController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/deploybluegreens/"
-
-	deployBlueGreenSlice := make([]DeployBlueGreen, 0)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployBlueGreenSlice, tokenHeaderMap)
+	deployBlueGreenSlice, err := cloudoneClient.GetDeployBlueGreenSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -92,11 +68,7 @@ func (c *DataController) Get() {
 		return
 	}
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/deploys/"
-
-	deployInformationSlice := make([]DeployInformation, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := cloudoneClient.GetAllDeployInformationSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -109,7 +81,7 @@ func (c *DataController) Get() {
 		return
 	}
 
-	deployInformationMap := make(map[string]DeployInformation)
+	deployInformationMap := make(map[string]backend.DeployInformation)
 	for _, deployInformation := range deployInformationSlice {
 		deployInformationMap[deployInformation.ImageInformationName+"-"+deployInformation.Namespace] = deployInformation
 	}
@@ -126,12 +98,7 @@ func (c *DataController) Get() {
 
 	leafAmount := 0
 	for _, deployBlueGreen := range deployBlueGreenSlice {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/deploybluegreens/deployable/" + deployBlueGreen.ImageInformation
-
-		namespaceSlice := make([]string, 0)
-
-		_, err := restclient.RequestGetWithStructure(url, &namespaceSlice, tokenHeaderMap)
+		namespaceSlice, err := cloudoneClient.GetDeployBlueGreenDeployableNamespaceSlice(deployBlueGreen.ImageInformation)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -17,32 +17,14 @@ package appservice
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strconv"
 	"strings"
 )
 
-type DeployInformation struct {
-	Namespace                 string
-	ImageInformationName      string
-	CurrentVersion            string
-	CurrentVersionDescription string
-	Description               string
-	ReplicaAmount             int
-}
-
-type DeployClusterApplication struct {
-	Name                           string
-	Namespace                      string
-	Size                           int
-	EnvironmentSlice               []interface{}
-	ServiceName                    string
-	ReplicationControllerNameSlice []string
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -72,9 +54,7 @@ type DataController struct {
 }
 
 func (c *DataController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
 	// Json
 	c.Data["json"] = make(map[string]interface{})
@@ -88,14 +68,7 @@ func (c *DataController) Get() {
 	applicationJsonMap["children"] = make([]interface{}, 0)
 	c.Data["json"].(map[string]interface{})["applicationView"] = append(c.Data["json"].(map[string]interface{})["applicationView"].([]interface{}), applicationJsonMap)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/"
-
-	deployInformationSlice := make([]DeployInformation, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := cloudoneClient.GetAllDeployInformationSlice()
 
 	if err != nil {
 		c.Data["json"].(map[string]interface{})["error"] = err.Error()
@@ -103,10 +76,10 @@ func (c *DataController) Get() {
 		return
 	}
 
-	deployInformationMap := make(map[string][]DeployInformation)
+	deployInformationMap := make(map[string][]backend.DeployInformation)
 	for _, deployInformation := range deployInformationSlice {
 		if deployInformationMap[deployInformation.ImageInformationName] == nil {
-			deployInformationMap[deployInformation.ImageInformationName] = make([]DeployInformation, 0)
+			deployInformationMap[deployInformation.ImageInformationName] = make([]backend.DeployInformation, 0)
 		}
 		deployInformationMap[deployInformation.ImageInformationName] = append(deployInformationMap[deployInformation.ImageInformationName], deployInformation)
 	}
@@ -143,12 +116,7 @@ func (c *DataController) Get() {
 	thirdpartyJsonMap["children"] = make([]interface{}, 0)
 	c.Data["json"].(map[string]interface{})["thirdpartyView"] = append(c.Data["json"].(map[string]interface{})["thirdpartyView"].([]interface{}), thirdpartyJsonMap)
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/"
-
-	deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &deployClusterApplicationSlice, tokenHeaderMap)
+	deployClusterApplicationSlice, err := cloudoneClient.GetAllDeployClusterApplicationSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -160,10 +128,10 @@ func (c *DataController) Get() {
 		return
 	}
 
-	deployClusterApplicationMap := make(map[string][]DeployClusterApplication)
+	deployClusterApplicationMap := make(map[string][]backend.DeployClusterApplication)
 	for _, deployClusterApplication := range deployClusterApplicationSlice {
 		if deployClusterApplicationMap[deployClusterApplication.Name] == nil {
-			deployClusterApplicationMap[deployClusterApplication.Name] = make([]DeployClusterApplication, 0)
+			deployClusterApplicationMap[deployClusterApplication.Name] = make([]backend.DeployClusterApplication, 0)
 		}
 		deployClusterApplicationMap[deployClusterApplication.Name] = append(deployClusterApplicationMap[deployClusterApplication.Name], deployClusterApplication)
 	}
@@ -185,14 +153,10 @@ func (c *DataController) Get() {
 			glusterfsEndpoint := ""
 			glusterfsPathList := ""
 			for _, environment := range deployClusterApplication.EnvironmentSlice {
-				environmentJsonMap, _ := environment.(map[string]interface{})
-				name, _ := environmentJsonMap["name"].(string)
-				value, _ := environmentJsonMap["value"].(string)
-
-				if name == "GLUSTERFS_ENDPOINTS" {
-					glusterfsEndpoint = value
-				} else if name == "GLUSTERFS_PATH_LIST" {
-					glusterfsPathList = value
+				if environment.Name == "GLUSTERFS_ENDPOINTS" {
+					glusterfsEndpoint = environment.Value
+				} else if environment.Name == "GLUSTERFS_PATH_LIST" {
+					glusterfsPathList = environment.Value
 				}
 			}
 
@@ -18,9 +18,9 @@ import (
 	"fmt"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strings"
 )
@@ -148,23 +148,9 @@ func (c *ListController) Get() {
 	user, _ := c.GetSession("user").(*rbac.User)
 	c.Data["dashboardTabMenu"] = identity.GetDashboardTabMenu(user, "healthcheck")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
 	allErrorMessageSlice := make([]string, 0)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/healthchecks/"
-
-	cloudoneJsonMap := make(map[string]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &cloudoneJsonMap, tokenHeaderMap)
+	cloudoneJsonMap, err := backend.NewCloudoneClient(c.Ctx).GetHealthCheck()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -175,12 +161,7 @@ func (c *ListController) Get() {
 		allErrorMessageSlice = append(allErrorMessageSlice, err.Error())
 	}
 
-	url = cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/healthchecks/"
-
-	cloudoneAnalysisJsonMap := make(map[string]interface{}, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &cloudoneAnalysisJsonMap, tokenHeaderMap)
+	cloudoneAnalysisJsonMap, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetHealthCheck()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
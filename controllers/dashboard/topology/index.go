@@ -17,43 +17,13 @@ package topology
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strconv"
 )
 
-type ReplicationControllerAndRelatedPod struct {
-	Name               string
-	Namespace          string
-	ReplicaAmount      int
-	AliveReplicaAmount int
-	Selector           map[string]string
-	Label              map[string]string
-	PodSlice           []Pod
-}
-
-type Pod struct {
-	Name           string
-	Namespace      string
-	HostIP         string
-	PodIP          string
-	ContainerSlice []PodContainer
-}
-
-type PodContainer struct {
-	Name      string
-	Image     string
-	PortSlice []PodContainerPort
-}
-
-type PodContainerPort struct {
-	Name          string
-	ContainerPort int
-	Protocol      string
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -87,18 +57,13 @@ type DataController struct {
 }
 
 func (c *DataController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	scope := c.GetString("scope")
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
 	namespaceSlice := make([]string, 0)
 	if scope == allKeyword {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/namespaces/"
-		_, err := restclient.RequestGetWithStructure(url, &namespaceSlice, tokenHeaderMap)
+		allNamespaceSlice, err := cloudoneClient.GetNamespaceNameSlice()
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -109,6 +74,8 @@ func (c *DataController) Get() {
 			c.ServeJSON()
 			return
 		}
+
+		namespaceSlice = allNamespaceSlice
 	} else {
 		namespace, _ := c.GetSession("namespace").(string)
 		namespaceSlice = append(namespaceSlice, namespace)
@@ -134,11 +101,7 @@ func (c *DataController) Get() {
 
 	leafAmount := 0
 	for _, namespace := range namespaceSlice {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/replicationcontrollers/" + namespace
-
-		replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
-
-		_, err := restclient.RequestGetWithStructure(url, &replicationControllerAndRelatedPodSlice, tokenHeaderMap)
+		replicationControllerAndRelatedPodSlice, err := cloudoneClient.GetReplicationControllerAndRelatedPodSlice(namespace)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -17,31 +17,25 @@ package autoscaler
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type DeleteController struct {
 	beego.Controller
 }
 
-func (c *DeleteController) Get() {
+func (c *DeleteController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace := c.GetString("namespace")
 	kind := c.GetString("kind")
 	name := c.GetString("name")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/" + namespace + "/" + kind + "/" + name
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+	err := identity.AuthorizeNamespace(c.Ctx, namespace)
+	if err == nil {
+		err = backend.NewCloudoneClient(c.Ctx).DeleteAutoScaler(namespace, kind, name)
+	}
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -49,7 +43,7 @@ func (c *DeleteController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Auto scaler for " + kind + " " + name + " is deleted")
 	}
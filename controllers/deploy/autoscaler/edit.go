@@ -17,8 +17,8 @@ package autoscaler
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"time"
 )
 
@@ -50,18 +50,7 @@ func (c *EditController) Get() {
 	} else {
 		namespace, _ := c.GetSession("namespace").(string)
 
-		cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-		cloudoneHost := beego.AppConfig.String("cloudoneHost")
-		cloudonePort := beego.AppConfig.String("cloudonePort")
-
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/autoscalers/" + namespace + "/" + kind + "/" + name
-
-		replicationControllerAutoScaler := ReplicationControllerAutoScaler{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &replicationControllerAutoScaler, tokenHeaderMap)
+		replicationControllerAutoScaler, err := backend.NewCloudoneClient(c.Ctx).GetAutoScaler(namespace, kind, name)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -69,7 +58,7 @@ func (c *EditController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			c.Data["maximumReplica"] = replicationControllerAutoScaler.MaximumReplica
 			c.Data["minimumReplica"] = replicationControllerAutoScaler.MinimumReplica
@@ -138,7 +127,7 @@ func (c *EditController) Get() {
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	indicatorSlice := make([]Indicator, 0)
+	indicatorSlice := make([]backend.Indicator, 0)
 	cpu := c.GetString("cpu")
 	if cpu == "on" {
 		cpuAboveAllOrOneText := c.GetString("cpuAboveAllOrOne")
@@ -159,9 +148,9 @@ func (c *EditController) Post() {
 		}
 		cpuBelowPercentageOfData, _ := c.GetFloat("cpuBelowPercentageOfData")
 		cpuBelowThreshold, _ := c.GetInt64("cpuBelowThreshold")
-		indicatorSlice = append(indicatorSlice, Indicator{"cpu",
-			cpuAboveAllOrOne, cpuAbovePercentageOfData / 100.0, cpuAboveThreshold * 1000000,
-			cpuBelowAllOrOne, cpuBelowPercentageOfData / 100.0, cpuBelowThreshold * 1000000})
+		indicatorSlice = append(indicatorSlice, backend.Indicator{Type: "cpu",
+			AboveAllOrOne: cpuAboveAllOrOne, AbovePercentageOfData: cpuAbovePercentageOfData / 100.0, AboveThreshold: cpuAboveThreshold * 1000000,
+			BelowAllOrOne: cpuBelowAllOrOne, BelowPercentageOfData: cpuBelowPercentageOfData / 100.0, BelowThreshold: cpuBelowThreshold * 1000000})
 	}
 	memory := c.GetString("memory")
 	if memory == "on" {
@@ -183,15 +172,11 @@ func (c *EditController) Post() {
 		}
 		memoryBelowPercentageOfData, _ := c.GetFloat("memoryBelowPercentageOfData")
 		memoryBelowThreshold, _ := c.GetInt64("memoryBelowThreshold")
-		indicatorSlice = append(indicatorSlice, Indicator{"memory",
-			memoryAboveAllOrOne, memoryAbovePercentageOfData / 100.0, memoryAboveThreshold * 1024 * 1024,
-			memoryBelowAllOrOne, memoryBelowPercentageOfData / 100.0, memoryBelowThreshold * 1024 * 1024})
+		indicatorSlice = append(indicatorSlice, backend.Indicator{Type: "memory",
+			AboveAllOrOne: memoryAboveAllOrOne, AbovePercentageOfData: memoryAbovePercentageOfData / 100.0, AboveThreshold: memoryAboveThreshold * 1024 * 1024,
+			BelowAllOrOne: memoryBelowAllOrOne, BelowPercentageOfData: memoryBelowPercentageOfData / 100.0, BelowThreshold: memoryBelowThreshold * 1024 * 1024})
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	kind := c.GetString("kind")
@@ -200,28 +185,21 @@ func (c *EditController) Post() {
 	maximumReplica, _ := c.GetInt("maximumReplica")
 	minimumReplica, _ := c.GetInt("minimumReplica")
 
-	replicationControllerAutoScaler := ReplicationControllerAutoScaler{
-		true,
-		time.Duration(coolDownDuration) * time.Second,
-		0,
-		"",
-		"",
-		namespace,
-		kind,
-		name,
-		maximumReplica,
-		minimumReplica,
-		indicatorSlice,
-		"",
-		"",
+	replicationControllerAutoScaler := backend.ReplicationControllerAutoScaler{
+		Check:                 true,
+		CoolDownDuration:      time.Duration(coolDownDuration) * time.Second,
+		RemainingCoolDown:     0,
+		KubeApiServerEndPoint: "",
+		KubeApiServerToken:    "",
+		Namespace:             namespace,
+		Kind:                  kind,
+		Name:                  name,
+		MaximumReplica:        maximumReplica,
+		MinimumReplica:        minimumReplica,
+		IndicatorSlice:        indicatorSlice,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPutWithStructure(url, replicationControllerAutoScaler, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).UpdateAutoScaler(replicationControllerAutoScaler)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -229,7 +207,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Auto scaler for " + kind + " " + name + " is edited")
 	}
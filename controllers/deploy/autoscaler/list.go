@@ -17,11 +17,10 @@ package autoscaler
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
-	"time"
 )
 
 type ListController struct {
@@ -29,31 +28,11 @@ type ListController struct {
 }
 
 type ReplicationControllerAutoScaler struct {
-	Check                              bool
-	CoolDownDuration                   time.Duration
-	RemainingCoolDown                  time.Duration
-	KubeApiServerEndPoint              string
-	KubeApiServerToken                 string
-	Namespace                          string
-	Kind                               string
-	Name                               string
-	MaximumReplica                     int
-	MinimumReplica                     int
-	IndicatorSlice                     []Indicator
+	backend.ReplicationControllerAutoScaler
 	HiddenTagGuiDeployAutoScalerEdit   string
 	HiddenTagGuiDeployAutoScalerDelete string
 }
 
-type Indicator struct {
-	Type                  string
-	AboveAllOrOne         bool
-	AbovePercentageOfData float64
-	AboveThreshold        int64
-	BelowAllOrOne         bool
-	BelowPercentageOfData float64
-	BelowThreshold        int64
-}
-
 type ByReplicationControllerAutoScaler []ReplicationControllerAutoScaler
 
 func (b ByReplicationControllerAutoScaler) Len() int           { return len(b) }
@@ -73,18 +52,7 @@ func (c *ListController) Get() {
 	hasGuiDeployAutoScalerEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/autoscaler/edit")
 	hasGuiDeployAutoScalerDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/autoscaler/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/"
-
-	replicationControllerAutoScalerSlice := make([]ReplicationControllerAutoScaler, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerAutoScalerSlice, tokenHeaderMap)
+	backendReplicationControllerAutoScalerSlice, err := backend.NewCloudoneClient(c.Ctx).GetAutoScalerSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -92,10 +60,15 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		namespace, _ := c.GetSession("namespace").(string)
 
+		replicationControllerAutoScalerSlice := make([]ReplicationControllerAutoScaler, 0)
+		for _, backendReplicationControllerAutoScaler := range backendReplicationControllerAutoScalerSlice {
+			replicationControllerAutoScalerSlice = append(replicationControllerAutoScalerSlice, ReplicationControllerAutoScaler{ReplicationControllerAutoScaler: backendReplicationControllerAutoScaler})
+		}
+
 		filteredReplicationControllerAutoScalerSlice := make([]ReplicationControllerAutoScaler, 0)
 
 		for i := 0; i < len(replicationControllerAutoScalerSlice); i++ {
@@ -17,9 +17,9 @@ package clone
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type SelectController struct {
@@ -37,17 +37,7 @@ func (c *SelectController) Get() {
 
 	currentNamespace, _ := c.GetSession("namespace").(string)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/namespaces/"
-
-	nameSlice := make([]string, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &nameSlice, tokenHeaderMap)
+	nameSlice, err := backend.NewCloudoneClient(c.Ctx).GetNamespaceNameSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -55,17 +45,12 @@ func (c *SelectController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.OutputMessage(c.Data)
 		return
 	}
 
-	namespaceSlice := make([]string, 0)
-	for _, name := range nameSlice {
-		if user.HasResource(identity.GetConponentName(), "/namespaces/"+name) {
-			namespaceSlice = append(namespaceSlice, name)
-		}
-	}
+	namespaceSlice := identity.GetPermittedNamespaceNameSlice(user, nameSlice)
 
 	c.Data["currentNamespace"] = currentNamespace
 	c.Data["namespaceSlice"] = namespaceSlice
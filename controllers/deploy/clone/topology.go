@@ -17,9 +17,9 @@ package clone
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strings"
 	"time"
@@ -28,8 +28,8 @@ import (
 type Clone struct {
 	Name                           string
 	DeployClusterApplication       DeployClusterApplication
-	DeployInformation              DeployInformation
-	RegionSlice                    []Region
+	DeployInformation              backend.DeployInformation
+	RegionSlice                    []backend.Region
 	Order                          int
 	CreatedTime                    time.Time
 	DeployClusterApplicationHidden string
@@ -43,104 +43,17 @@ func (b ByClone) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b ByClone) Less(i, j int) bool { return b[i].CreatedTime.Before(b[j].CreatedTime) }
 
 type DeployClusterApplication struct {
-	Name                              string
-	Namespace                         string
-	Size                              int
-	EnvironmentSlice                  []interface{}
-	ReplicationControllerExtraJsonMap map[string]interface{}
-	ServiceName                       string
-	ReplicationControllerNameSlice    []string
-	CreatedTime                       time.Time
-	EnvironmentMap                    map[string]string
+	backend.DeployClusterApplication
+	// The following is used for GUI
+	EnvironmentMap map[string]string
 }
 
-type Region struct {
-	Name           string
-	LocationTagged bool
-	ZoneSlice      []Zone
-}
-
-type Zone struct {
-	Name           string
-	LocationTagged bool
-	NodeSlice      []Node
-}
-
-type Node struct {
-	Name     string
-	Address  string
-	Capacity Capacity
-}
-
-type Capacity struct {
-	Cpu    string
-	Memory string
-}
-
-type DeployInformation struct {
-	Namespace                 string
-	ImageInformationName      string
-	CurrentVersion            string
-	CurrentVersionDescription string
-	Description               string
-	ReplicaAmount             int
-	ContainerPortSlice        []DeployContainerPort
-	EnvironmentSlice          []ReplicationControllerContainerEnvironment
-	ResourceMap               map[string]interface{}
-	ExtraJsonMap              map[string]interface{}
-	CreatedTime               time.Time
-}
-
-type DeployContainerPort struct {
-	Name          string
-	ContainerPort int
-	NodePort      int
-}
-
-type ReplicationControllerContainerEnvironment struct {
-	Name  string
-	Value string
-}
-
-type Topology struct {
-	Name            string
-	SourceNamespace string
-	CreatedUser     string
-	CreatedDate     time.Time
-	Description     string
-	LaunchSlice     []Launch
-}
-
-type Launch struct {
-	Order                    int
-	LaunchApplication        *LaunchApplication
-	LaunchClusterApplication *LaunchClusterApplication
-}
-
-type ByLaunch []Launch
+type ByLaunch []backend.Launch
 
 func (b ByLaunch) Len() int           { return len(b) }
 func (b ByLaunch) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b ByLaunch) Less(i, j int) bool { return b[i].Order < b[j].Order }
 
-type LaunchApplication struct {
-	ImageInformationName string
-	Version              string
-	Description          string
-	ReplicaAmount        int
-	PortSlice            []DeployContainerPort
-	EnvironmentSlice     []ReplicationControllerContainerEnvironment
-	ResourceMap          map[string]interface{}
-	ExtraJsonMap         map[string]interface{}
-}
-
-type LaunchClusterApplication struct {
-	Name                              string
-	Size                              int
-	EnvironmentSlice                  []interface{}
-	ReplicationControllerExtraJsonMap map[string]interface{}
-}
-
 type TopologyController struct {
 	beego.Controller
 }
@@ -152,10 +65,6 @@ func (c *TopologyController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	currentNamespace, _ := c.GetSession("namespace").(string)
 
 	action := c.GetString("action")
@@ -173,13 +82,15 @@ func (c *TopologyController) Get() {
 	namespace := c.GetString("namespace")
 	c.Data["sourceNamespace"] = namespace
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/nodes/topology"
-
-	regionSlice := make([]Region, 0)
+	if err := identity.AuthorizeNamespace(c.Ctx, namespace); err != nil {
+		guimessage.AddError(err)
+		guimessage.OutputMessage(c.Data)
+		return
+	}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &regionSlice, tokenHeaderMap)
+	filteredRegionSlice, err := cloudoneClient.GetLocationTaggedRegionSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -187,25 +98,13 @@ func (c *TopologyController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.OutputMessage(c.Data)
 		return
 	}
 
-	filteredRegionSlice := make([]Region, 0)
-	for _, region := range regionSlice {
-		if region.LocationTagged {
-			filteredRegionSlice = append(filteredRegionSlice, region)
-		}
-	}
-
 	// Third-party service
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/" + namespace
-
-	deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &deployClusterApplicationSlice, tokenHeaderMap)
+	backendDeployClusterApplicationSlice, err := cloudoneClient.GetDeployClusterApplicationSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -213,48 +112,39 @@ func (c *TopologyController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.OutputMessage(c.Data)
 		return
 	}
 
-	for i, deployClusterApplication := range deployClusterApplicationSlice {
+	deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
+	for _, backendDeployClusterApplication := range backendDeployClusterApplicationSlice {
+		deployClusterApplication := DeployClusterApplication{DeployClusterApplication: backendDeployClusterApplication}
 		if deployClusterApplication.EnvironmentSlice != nil {
 			environmentMap := make(map[string]string)
 			for _, environment := range deployClusterApplication.EnvironmentSlice {
-				environmentJsonMap, ok := environment.(map[string]interface{})
-				if ok {
-					name, nameOk := environmentJsonMap["name"].(string)
-					value, valueOk := environmentJsonMap["value"].(string)
-
-					if nameOk && valueOk {
-						// Try to set the known common parameter
-						if name == "SERVICE_NAME" {
-							value = deployClusterApplication.Name
-						}
-						if name == "NAMESPACE" {
-							value = currentNamespace
-						}
-						if name == "GLUSTERFS_PATH_LIST" {
-							value = ""
-						}
-
-						environmentMap[name] = value
-					}
+				value := environment.Value
+				// Try to set the known common parameter
+				if environment.Name == "SERVICE_NAME" {
+					value = deployClusterApplication.Name
+				}
+				if environment.Name == "NAMESPACE" {
+					value = currentNamespace
 				}
+				if environment.Name == "GLUSTERFS_PATH_LIST" {
+					value = ""
+				}
+
+				environmentMap[environment.Name] = value
 			}
 
-			deployClusterApplicationSlice[i].EnvironmentMap = environmentMap
+			deployClusterApplication.EnvironmentMap = environmentMap
 		}
+		deployClusterApplicationSlice = append(deployClusterApplicationSlice, deployClusterApplication)
 	}
 
 	// Application
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/" + namespace
-
-	deployInformationSlice := make([]DeployInformation, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := cloudoneClient.GetDeployInformationSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -262,7 +152,7 @@ func (c *TopologyController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.OutputMessage(c.Data)
 		return
 	}
@@ -286,7 +176,7 @@ func (c *TopologyController) Get() {
 		clone := Clone{
 			deployClusterApplication.Name,
 			deployClusterApplication,
-			DeployInformation{},
+			backend.DeployInformation{},
 			filteredRegionSlice,
 			0,
 			deployClusterApplication.CreatedTime,
@@ -327,10 +217,6 @@ func (c *TopologyController) Get() {
 func (c *TopologyController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	action := c.GetString("action")
 
 	// Generate topology order
@@ -356,15 +242,17 @@ func (c *TopologyController) Post() {
 
 	sourceNamespace := c.GetString("sourceNamespace")
 
-	// Application
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/" + sourceNamespace
-
-	deployInformationSlice := make([]DeployInformation, 0)
+	if err := identity.AuthorizeNamespace(c.Ctx, sourceNamespace); err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/deploy/clone/select")
+		return
+	}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	// Application
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := cloudoneClient.GetDeployInformationSlice(sourceNamespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -372,13 +260,13 @@ func (c *TopologyController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/deploy/clone/select")
 		return
 	}
 
-	launchSlice := make([]Launch, 0)
+	launchSlice := make([]backend.Launch, 0)
 	for _, cloneName := range cloneNameSlice {
 		cloneOrder, _ := c.GetInt("cloneOrder" + cloneName)
 
@@ -430,34 +318,31 @@ func (c *TopologyController) Post() {
 
 		// Cluster or application
 		if len(clusterName) > 0 {
-			environmentSlice := make([]interface{}, 0)
+			environmentSlice := make([]backend.ClusterEnvironment, 0)
 			for key, value := range clusterEnvironmentMap {
-				environmentJsonMap := make(map[string]interface{})
-				environmentJsonMap["name"] = key
-				environmentJsonMap["value"] = value
-				environmentSlice = append(environmentSlice, environmentJsonMap)
+				environmentSlice = append(environmentSlice, backend.ClusterEnvironment{Name: key, Value: value})
 			}
 
-			clusterLaunch := &LaunchClusterApplication{
-				clusterName,
-				clusterSize,
-				environmentSlice,
-				extraJsonMap,
+			clusterLaunch := &backend.LaunchClusterApplication{
+				Name:                              clusterName,
+				Size:                              clusterSize,
+				EnvironmentSlice:                  environmentSlice,
+				ReplicationControllerExtraJsonMap: extraJsonMap,
 			}
 
-			launch := Launch{
-				cloneOrder,
-				nil,
-				clusterLaunch,
+			launch := backend.Launch{
+				Order:                    cloneOrder,
+				LaunchApplication:        nil,
+				LaunchClusterApplication: clusterLaunch,
 			}
 
 			launchSlice = append(launchSlice, launch)
 		} else if len(applicationImageInformationName) > 0 {
 			for _, deployInformation := range deployInformationSlice {
 				if deployInformation.ImageInformationName == applicationImageInformationName {
-					environmentSlice := make([]ReplicationControllerContainerEnvironment, 0)
+					environmentSlice := make([]backend.ReplicationControllerContainerEnvironment, 0)
 					for key, value := range applicationEnvironmentMap {
-						environmentSlice = append(environmentSlice, ReplicationControllerContainerEnvironment{key, value})
+						environmentSlice = append(environmentSlice, backend.ReplicationControllerContainerEnvironment{Name: key, Value: value})
 					}
 
 					// Change the assigned Node Port to auto generated
@@ -467,21 +352,22 @@ func (c *TopologyController) Post() {
 						}
 					}
 
-					launchApplication := &LaunchApplication{
-						applicationImageInformationName,
-						applicationVersion,
-						applicationDescription,
-						applicationReplicaAmount,
-						deployInformation.ContainerPortSlice,
-						environmentSlice,
-						deployInformation.ResourceMap,
-						extraJsonMap,
+					launchApplication := &backend.DeployCreateInput{
+						ImageInformationName:  applicationImageInformationName,
+						Version:               applicationVersion,
+						Description:           applicationDescription,
+						ReplicaAmount:         applicationReplicaAmount,
+						PortSlice:             deployInformation.ContainerPortSlice,
+						EnvironmentSlice:      environmentSlice,
+						ResourceMap:           deployInformation.ResourceMap,
+						ExtraJsonMap:          extraJsonMap,
+						AutoUpdateForNewBuild: false,
 					}
 
-					launch := Launch{
-						cloneOrder,
-						launchApplication,
-						nil,
+					launch := backend.Launch{
+						Order:                    cloneOrder,
+						LaunchApplication:        launchApplication,
+						LaunchClusterApplication: nil,
 					}
 
 					launchSlice = append(launchSlice, launch)
@@ -498,44 +384,18 @@ func (c *TopologyController) Post() {
 	if action == "clone" {
 		namespace, _ := c.GetSession("namespace").(string)
 
-		for _, launch := range launchSlice {
-			if launch.LaunchApplication != nil {
-				url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/deploys/create/" + namespace
+		err = cloudoneClient.Launch(namespace, launchSlice)
 
-				_, err = restclient.RequestPostWithStructure(url, launch.LaunchApplication, nil, tokenHeaderMap)
-
-				if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
-					return
-				}
-
-				if err != nil {
-					// Error
-					guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
-					guimessage.RedirectMessage(c)
-					c.Ctx.Redirect(302, "/gui/deploy/clone/select")
-					return
-				}
-			}
-			if launch.LaunchClusterApplication != nil {
-				url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-					"/api/v1/clusterapplications/launch/" + namespace + "/" + launch.LaunchClusterApplication.Name
-
-				jsonMap := make(map[string]interface{})
-
-				_, err = restclient.RequestPostWithStructure(url, launch.LaunchClusterApplication, &jsonMap, tokenHeaderMap)
-
-				if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
-					return
-				}
+		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+			return
+		}
 
-				if err != nil {
-					// Error
-					guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
-					guimessage.RedirectMessage(c)
-					c.Ctx.Redirect(302, "/gui/deploy/clone/select")
-					return
-				}
-			}
+		if err != nil {
+			// Error
+			guimessage.AddError(err)
+			guimessage.RedirectMessage(c)
+			c.Ctx.Redirect(302, "/gui/deploy/clone/select")
+			return
 		}
 
 		guimessage.AddSuccess("Clone the namespace " + sourceNamespace + " to the namespace " + namespace)
@@ -549,18 +409,16 @@ func (c *TopologyController) Post() {
 			createdUserName = user.Name
 		}
 
-		topology := Topology{
-			templateName,
-			sourceNamespace,
-			createdUserName,
-			time.Now(),
-			templateDescription,
-			launchSlice,
+		topology := backend.Topology{
+			Name:            templateName,
+			SourceNamespace: sourceNamespace,
+			CreatedUser:     createdUserName,
+			CreatedDate:     time.Now(),
+			Description:     templateDescription,
+			LaunchSlice:     launchSlice,
 		}
 
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/topology/"
-
-		_, err = restclient.RequestPostWithStructure(url, topology, nil, tokenHeaderMap)
+		err = cloudoneClient.CreateTopology(topology)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -568,7 +426,7 @@ func (c *TopologyController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			guimessage.RedirectMessage(c)
 			c.Ctx.Redirect(302, "/gui/deploy/clone/select")
 			return
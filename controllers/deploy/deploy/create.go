@@ -17,8 +17,9 @@ package deploy
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,75 +29,6 @@ type CreateController struct {
 	beego.Controller
 }
 
-type DeployCreateInput struct {
-	ImageInformationName  string
-	Version               string
-	Description           string
-	ReplicaAmount         int
-	PortSlice             []DeployContainerPort
-	EnvironmentSlice      []ReplicationControllerContainerEnvironment
-	ResourceMap           map[string]interface{}
-	ExtraJsonMap          map[string]interface{}
-	AutoUpdateForNewBuild bool
-}
-
-type DeployContainerPort struct {
-	Name          string
-	ContainerPort int
-	NodePort      int
-	Protocol      string
-}
-
-type ReplicationControllerContainerPort struct {
-	Name          string
-	ContainerPort int
-}
-
-type ReplicationControllerContainerEnvironment struct {
-	Name  string
-	Value string
-}
-
-type ImageRecord struct {
-	ImageInformation string
-	Version          string
-	Path             string
-	VersionInfo      map[string]string
-	Environment      map[string]string
-	Description      string
-	CreatedTime      string
-	Failure          bool
-}
-
-type ByImageRecord []ImageRecord
-
-func (b ByImageRecord) Len() int           { return len(b) }
-func (b ByImageRecord) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
-func (b ByImageRecord) Less(i, j int) bool { return b[i].Version > b[j].Version } // Use > to list from latest to oldest
-
-type Region struct {
-	Name           string
-	LocationTagged bool
-	ZoneSlice      []Zone
-}
-
-type Zone struct {
-	Name           string
-	LocationTagged bool
-	NodeSlice      []Node
-}
-
-type Node struct {
-	Name     string
-	Address  string
-	Capacity Capacity
-}
-
-type Capacity struct {
-	Cpu    string
-	Memory string
-}
-
 func (c *CreateController) Get() {
 	c.TplName = "deploy/deploy/create.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
@@ -104,20 +36,11 @@ func (c *CreateController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imagerecords/" + name
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	imageRecordSlice := make([]ImageRecord, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &imageRecordSlice, tokenHeaderMap)
+	imageRecordSlice, err := cloudoneClient.GetImageRecordSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -125,14 +48,9 @@ func (c *CreateController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
-
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/nodes/topology"
-
-		regionSlice := make([]Region, 0)
-
-		_, err = restclient.RequestGetWithStructure(url, &regionSlice, tokenHeaderMap)
+		filteredRegionSlice, err := cloudoneClient.GetLocationTaggedRegionSlice()
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -140,20 +58,13 @@ func (c *CreateController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
-			filteredRegionSlice := make([]Region, 0)
-			for _, region := range regionSlice {
-				if region.LocationTagged {
-					filteredRegionSlice = append(filteredRegionSlice, region)
-				}
-			}
-
 			namespace, _ := c.GetSession("namespace").(string)
 
 			c.Data["regionSlice"] = filteredRegionSlice
 
-			filteredImageRecordSlice := make([]ImageRecord, 0)
+			filteredImageRecordSlice := make([]backend.ImageRecord, 0)
 			for _, imageRecord := range imageRecordSlice {
 				if imageRecord.Failure == false {
 					if imageRecord.Environment != nil {
@@ -172,7 +83,7 @@ func (c *CreateController) Get() {
 				}
 			}
 
-			sort.Sort(ByImageRecord(filteredImageRecordSlice))
+			sort.Sort(backend.ByImageRecord(filteredImageRecordSlice))
 
 			c.Data["imageInformationName"] = name
 			c.Data["imageRecordSlice"] = filteredImageRecordSlice
@@ -185,10 +96,6 @@ func (c *CreateController) Get() {
 func (c *CreateController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespaces, _ := c.GetSession("namespace").(string)
 
 	imageInformationName := c.GetString("imageInformationName")
@@ -261,17 +168,17 @@ func (c *CreateController) Post() {
 		}
 	}
 
-	environmentSlice := make([]ReplicationControllerContainerEnvironment, 0)
+	environmentSlice := make([]backend.ReplicationControllerContainerEnvironment, 0)
 	length := len(version) + 1 // + 1 for _
 	for _, key := range keySlice {
 		value := c.GetString(key)
 		if len(value) > 0 {
 			environmentSlice = append(environmentSlice,
-				ReplicationControllerContainerEnvironment{key[length:], value})
+				backend.ReplicationControllerContainerEnvironment{Name: key[length:], Value: value})
 		}
 	}
 
-	deployContainerPortSlice := make([]DeployContainerPort, 0)
+	deployContainerPortSlice := make([]backend.DeployContainerPort, 0)
 	i := 0
 	for index, containerPort := range indexContainerPortMap {
 		nodePort := -1
@@ -284,7 +191,7 @@ func (c *CreateController) Post() {
 			}
 		}
 		protocol := c.GetString("protocol" + index)
-		deployContainerPortSlice = append(deployContainerPortSlice, DeployContainerPort{portName + strconv.Itoa(i), containerPort, nodePort, protocol})
+		deployContainerPortSlice = append(deployContainerPortSlice, backend.DeployContainerPort{Name: portName + strconv.Itoa(i), ContainerPort: containerPort, NodePort: nodePort, Protocol: protocol})
 		i++
 	}
 
@@ -325,23 +232,19 @@ func (c *CreateController) Post() {
 		extraJsonMap = nil
 	}
 
-	deployCreateInput := DeployCreateInput{
-		imageInformationName,
-		version,
-		description,
-		replicaAmount,
-		deployContainerPortSlice,
-		environmentSlice,
-		resourceMap,
-		extraJsonMap,
-		autoUpdateForNewBuild,
+	deployCreateInput := backend.DeployCreateInput{
+		ImageInformationName:  imageInformationName,
+		Version:               version,
+		Description:           description,
+		ReplicaAmount:         replicaAmount,
+		PortSlice:             deployContainerPortSlice,
+		EnvironmentSlice:      environmentSlice,
+		ResourceMap:           resourceMap,
+		ExtraJsonMap:          extraJsonMap,
+		AutoUpdateForNewBuild: autoUpdateForNewBuild,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/deploys/create/" + namespaces
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPostWithStructure(url, deployCreateInput, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).CreateDeploy(namespaces, deployCreateInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -349,9 +252,12 @@ func (c *CreateController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Create deploy " + imageInformationName + " version " + version + " success")
+		if err := deployrevision.Record(c.Ctx, namespaces, imageInformationName, deployrevision.ActionCreate); err != nil {
+			guimessage.AddWarning("Fail to record the revision with error " + err.Error())
+		}
 	}
 
 	c.Ctx.Redirect(302, "/gui/deploy/deploy/list")
@@ -17,9 +17,9 @@ package deploy
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,16 +28,12 @@ type ListController struct {
 }
 
 type DeployInformation struct {
-	Namespace                      string
-	ImageInformationName           string
-	CurrentVersion                 string
-	CurrentVersionDescription      string
-	Description                    string
-	ReplicaAmount                  int
-	AutoUpdateForNewBuild          bool
-	HiddenTagGuiDeployDeployUpdate string
-	HiddenTagGuiDeployDeployResize string
-	HiddenTagGuiDeployDeployDelete string
+	backend.DeployInformation
+	HiddenTagGuiDeployDeployUpdate   string
+	HiddenTagGuiDeployDeployRevision string
+	HiddenTagGuiDeployDeployCanary   string
+	HiddenTagGuiDeployDeployResize   string
+	HiddenTagGuiDeployDeployDelete   string
 }
 
 type ByDeployInformation []DeployInformation
@@ -59,23 +55,14 @@ func (c *ListController) Get() {
 	user, _ := c.GetSession("user").(*rbac.User)
 	// Tag won't work in loop so need to be placed in data
 	hiddenTagGuiDeployDeployUpdate := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploy/update")
+	hiddenTagGuiDeployDeployRevision := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploy/revision")
+	hiddenTagGuiDeployDeployCanary := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploycanary/create")
 	hiddenTagGuiDeployDeployResize := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploy/resize")
 	hiddenTagGuiDeployDeployDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploy/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/" + namespace
-
-	deployInformationSlice := make([]DeployInformation, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := backend.NewCloudoneClient(c.Ctx).GetDeployInformationSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -83,16 +70,27 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		// Only show those belonging to this namespace
 		filteredDeployInformationSlice := make([]DeployInformation, 0)
-		for _, deployInformation := range deployInformationSlice {
+		for _, backendDeployInformation := range deployInformationSlice {
+			deployInformation := DeployInformation{DeployInformation: backendDeployInformation}
 			if hiddenTagGuiDeployDeployUpdate {
 				deployInformation.HiddenTagGuiDeployDeployUpdate = "<div class='btn-group'>"
 			} else {
 				deployInformation.HiddenTagGuiDeployDeployUpdate = "<div hidden>"
 			}
+			if hiddenTagGuiDeployDeployRevision {
+				deployInformation.HiddenTagGuiDeployDeployRevision = "<div class='btn-group'>"
+			} else {
+				deployInformation.HiddenTagGuiDeployDeployRevision = "<div hidden>"
+			}
+			if hiddenTagGuiDeployDeployCanary {
+				deployInformation.HiddenTagGuiDeployDeployCanary = "<div class='btn-group'>"
+			} else {
+				deployInformation.HiddenTagGuiDeployDeployCanary = "<div hidden>"
+			}
 			if hiddenTagGuiDeployDeployResize {
 				deployInformation.HiddenTagGuiDeployDeployResize = "<div class='btn-group'>"
 			} else {
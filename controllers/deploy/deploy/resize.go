@@ -17,9 +17,8 @@ package deploy
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
-	"strconv"
 )
 
 type ResizeController struct {
@@ -44,21 +43,12 @@ func (c *ResizeController) Get() {
 func (c *ResizeController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	name := c.GetString("name")
 	size, _ := c.GetInt("size")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/resize/" + namespace + "/" + name + "?size=" + strconv.Itoa(size)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPut(url, make(map[string]interface{}), tokenHeaderMap, false)
+	err := backend.NewCloudoneClient(c.Ctx).ResizeDeploy(namespace, name, size)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -66,7 +56,7 @@ func (c *ResizeController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Application " + name + " is resized")
 	}
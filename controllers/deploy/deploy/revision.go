@@ -0,0 +1,85 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"encoding/json"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_utility/rbac"
+)
+
+type RevisionController struct {
+	beego.Controller
+}
+
+type DeployRevision struct {
+	deployrevision.DeployRevision
+	Current                          bool
+	PortText                         string
+	EnvironmentText                  string
+	ResourceText                     string
+	HiddenTagGuiDeployDeployRollback string
+}
+
+func (c *RevisionController) Get() {
+	c.TplName = "deploy/deploy/revision.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+	// Authorization for Button
+	user, _ := c.GetSession("user").(*rbac.User)
+	// Tag won't work in loop so need to be placed in data
+	hiddenTagGuiDeployDeployRollback := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploy/rollback")
+
+	namespace, _ := c.GetSession("namespace").(string)
+
+	name := c.GetString("name")
+	c.Data["name"] = name
+
+	deployRevisionSlice, err := deployrevision.GetDeployRevisionSlice(backend.GetClusterName(c.Ctx), namespace, name)
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		revisionSlice := make([]DeployRevision, 0)
+		for i, deployRevision := range deployRevisionSlice {
+			revision := DeployRevision{
+				DeployRevision:  deployRevision,
+				Current:         i == 0,
+				PortText:        deployrevision.GetPortText(deployRevision.DeployCreateInput.PortSlice),
+				EnvironmentText: deployrevision.GetEnvironmentText(deployRevision.DeployCreateInput.EnvironmentSlice),
+			}
+			if len(deployRevision.DeployCreateInput.ResourceMap) > 0 {
+				byteSlice, _ := json.Marshal(deployRevision.DeployCreateInput.ResourceMap)
+				revision.ResourceText = string(byteSlice)
+			}
+			// The latest revision is the current one so it doesn't need to rollback
+			if hiddenTagGuiDeployDeployRollback && revision.Current == false {
+				revision.HiddenTagGuiDeployDeployRollback = "<div class='btn-group'>"
+			} else {
+				revision.HiddenTagGuiDeployDeployRollback = "<div hidden>"
+			}
+			revisionSlice = append(revisionSlice, revision)
+		}
+		c.Data["deployRevisionSlice"] = revisionSlice
+	}
+
+	guimessage.OutputMessage(c.Data)
+}
@@ -0,0 +1,100 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"encoding/json"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"strconv"
+)
+
+type RollbackController struct {
+	beego.Controller
+}
+
+// Get shows the revision and how it will be applied for the user to confirm
+func (c *RollbackController) Get() {
+	c.TplName = "deploy/deploy/rollback.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+
+	namespace, _ := c.GetSession("namespace").(string)
+
+	name := c.GetString("name")
+	revision, _ := c.GetInt("revision")
+
+	deployRevision, rollbackMode, err := deployrevision.GetRollbackPlan(c.Ctx, namespace, name, revision)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		// Redirect to list
+		c.Ctx.Redirect(302, "/gui/deploy/deploy/revision?name="+name)
+		return
+	}
+
+	c.Data["name"] = name
+	c.Data["deployRevision"] = deployRevision
+	c.Data["portText"] = deployrevision.GetPortText(deployRevision.DeployCreateInput.PortSlice)
+	c.Data["environmentText"] = deployrevision.GetEnvironmentText(deployRevision.DeployCreateInput.EnvironmentSlice)
+	if len(deployRevision.DeployCreateInput.ResourceMap) > 0 {
+		byteSlice, _ := json.Marshal(deployRevision.DeployCreateInput.ResourceMap)
+		c.Data["resourceText"] = string(byteSlice)
+	}
+	c.Data["recreate"] = rollbackMode == deployrevision.RollbackModeRecreate
+	c.Data["rollbackModeDescription"] = deployrevision.GetRollbackModeDescription(rollbackMode)
+
+	guimessage.OutputMessage(c.Data)
+}
+
+func (c *RollbackController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	namespace, _ := c.GetSession("namespace").(string)
+
+	name := c.GetString("name")
+	revision, _ := c.GetInt("revision")
+
+	_, err := deployrevision.Rollback(c.Ctx, namespace, name, revision)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		if deployrevision.IsRestoreError(err) {
+			guimessage.AddWarning("Deploy " + name + " may be deleted without being created again. Check the application list. Its state before the rollback is kept as the latest revision.")
+		}
+	} else {
+		guimessage.AddSuccess("Rollback deploy " + name + " to revision " + strconv.Itoa(revision) + " success")
+	}
+
+	// Redirect to list
+	c.Ctx.Redirect(302, "/gui/deploy/deploy/revision?name="+name)
+
+	guimessage.RedirectMessage(c)
+}
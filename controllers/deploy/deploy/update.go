@@ -17,8 +17,9 @@ package deploy
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strings"
 )
@@ -27,37 +28,6 @@ type UpdateController struct {
 	beego.Controller
 }
 
-type DeployUpdateInput struct {
-	ImageInformationName string
-	Version              string
-	Description          string
-	EnvironmentSlice     []ReplicationControllerContainerEnvironment
-}
-
-type ReplicationController struct {
-	Name           string
-	ReplicaAmount  int
-	Selector       ReplicationControllerSelector
-	Label          ReplicationControllerLabel
-	ContainerSlice []ReplicationControllerContainer
-}
-
-type ReplicationControllerSelector struct {
-	Name    string
-	Version string
-}
-
-type ReplicationControllerLabel struct {
-	Name string
-}
-
-type ReplicationControllerContainer struct {
-	Name             string
-	Image            string
-	PortSlice        []ReplicationControllerContainerPort
-	EnvironmentSlice []ReplicationControllerContainerEnvironment
-}
-
 func (c *UpdateController) Get() {
 	c.TplName = "deploy/deploy/update.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
@@ -65,10 +35,6 @@ func (c *UpdateController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	name := c.GetString("name")
@@ -76,15 +42,10 @@ func (c *UpdateController) Get() {
 
 	replicationControllerName := name + oldVersion
 
-	// Retrieve the current environment setting
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace + "/" + replicationControllerName
-
-	replicationController := ReplicationController{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &replicationController, tokenHeaderMap)
+	// Retrieve the current environment setting
+	replicationController, err := cloudoneClient.GetReplicationController(namespace, replicationControllerName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -92,7 +53,7 @@ func (c *UpdateController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		// Redirect to list
 		c.Ctx.Redirect(302, "/gui/deploy/deploy/list")
@@ -102,12 +63,7 @@ func (c *UpdateController) Get() {
 	// Application only uses single container
 	environmentSlice := replicationController.ContainerSlice[0].EnvironmentSlice
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imagerecords/" + name
-
-	imageRecordSlice := make([]ImageRecord, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &imageRecordSlice, tokenHeaderMap)
+	imageRecordSlice, err := cloudoneClient.GetImageRecordSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -115,9 +71,9 @@ func (c *UpdateController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
-		filteredImageRecordSlice := make([]ImageRecord, 0)
+		filteredImageRecordSlice := make([]backend.ImageRecord, 0)
 		for _, imageRecord := range imageRecordSlice {
 			if imageRecord.Version != oldVersion && imageRecord.Failure == false {
 				for key, _ := range imageRecord.Environment {
@@ -134,7 +90,7 @@ func (c *UpdateController) Get() {
 			}
 		}
 
-		sort.Sort(ByImageRecord(filteredImageRecordSlice))
+		sort.Sort(backend.ByImageRecord(filteredImageRecordSlice))
 
 		c.Data["name"] = name
 		c.Data["imageRecordSlice"] = filteredImageRecordSlice
@@ -146,10 +102,6 @@ func (c *UpdateController) Get() {
 func (c *UpdateController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespaces, _ := c.GetSession("namespace").(string)
 
 	imageInformationName := c.GetString("name")
@@ -167,24 +119,23 @@ func (c *UpdateController) Post() {
 		}
 	}
 
-	environmentSlice := make([]ReplicationControllerContainerEnvironment, 0)
+	environmentSlice := make([]backend.ReplicationControllerContainerEnvironment, 0)
 	length := len(version) + 1 // + 1 for _
 	for _, key := range keySlice {
 		value := c.GetString(key)
 		if len(value) > 0 {
 			environmentSlice = append(environmentSlice,
-				ReplicationControllerContainerEnvironment{key[length:], value})
+				backend.ReplicationControllerContainerEnvironment{Name: key[length:], Value: value})
 		}
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/update/" + namespaces
-
-	deployUpdateInput := DeployUpdateInput{imageInformationName, version, description, environmentSlice}
+	deployUpdateInput := backend.DeployUpdateInput{ImageInformationName: imageInformationName, Version: version, Description: description, EnvironmentSlice: environmentSlice}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	if err := deployrevision.RecordBaseline(c.Ctx, namespaces, imageInformationName); err != nil {
+		guimessage.AddWarning("Fail to record the revision before update with error " + err.Error())
+	}
 
-	_, err := restclient.RequestPutWithStructure(url, deployUpdateInput, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).UpdateDeploy(namespaces, deployUpdateInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -192,9 +143,12 @@ func (c *UpdateController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Update deploy " + imageInformationName + " to version " + version + " success")
+		if err := deployrevision.Record(c.Ctx, namespaces, imageInformationName, deployrevision.ActionUpdate); err != nil {
+			guimessage.AddWarning("Fail to record the revision with error " + err.Error())
+		}
 	}
 
 	// Redirect to list
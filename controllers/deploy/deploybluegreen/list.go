@@ -17,9 +17,10 @@ package deploybluegreen
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/bluegreenpromotion"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strconv"
 )
@@ -29,14 +30,13 @@ type ListController struct {
 }
 
 type DeployBlueGreen struct {
-	ImageInformation                        string
-	Namespace                               string
-	NodePort                                int
-	Description                             string
-	SessionAffinity                         string
-	NodePortDisplay                         string
-	HiddenTagGuiDeployDeployBlueGreenSelect string
-	HiddenTagGuiDeployDeployBlueGreenDelete string
+	backend.DeployBlueGreen
+	NodePortDisplay                            string
+	PromotionDisplay                           string
+	HiddenTagGuiDeployDeployBlueGreenSelect    string
+	HiddenTagGuiDeployDeployBlueGreenPromote   string
+	HiddenTagGuiDeployDeployBlueGreenPromotion string
+	HiddenTagGuiDeployDeployBlueGreenDelete    string
 }
 
 type ByDeployBlueGreen []DeployBlueGreen
@@ -58,29 +58,37 @@ func (c *ListController) Get() {
 	user, _ := c.GetSession("user").(*rbac.User)
 	// Tag won't work in loop so need to be placed in data
 	hasGuiDeployDeployBlueGreenSelect := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploybluegreen/select")
+	hasGuiDeployDeployBlueGreenPromote := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploybluegreen/promote")
+	hasGuiDeployDeployBlueGreenPromotion := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploybluegreen/promotion")
 	hasGuiDeployDeployBlueGreenDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploybluegreen/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploybluegreens/"
-
-	deployBlueGreenSlice := make([]DeployBlueGreen, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployBlueGreenSlice, tokenHeaderMap)
+	backendDeployBlueGreenSlice, err := backend.NewCloudoneClient(c.Ctx).GetDeployBlueGreenSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
+	// The last promotion of each blue green deployment
+	promotionMap := make(map[string]bluegreenpromotion.Promotion)
+	if err == nil {
+		promotionSlice, promotionError := bluegreenpromotion.GetPromotionSlice(backend.GetClusterName(c.Ctx))
+		if promotionError != nil {
+			guimessage.AddError(promotionError)
+		}
+		for _, promotion := range promotionSlice {
+			promotionMap[promotion.ImageInformationName] = promotion
+		}
+	}
+
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		deployBlueGreenSlice := make([]DeployBlueGreen, 0)
+		for _, backendDeployBlueGreen := range backendDeployBlueGreenSlice {
+			deployBlueGreenSlice = append(deployBlueGreenSlice, DeployBlueGreen{DeployBlueGreen: backendDeployBlueGreen})
+		}
+
 		for i := 0; i < len(deployBlueGreenSlice); i++ {
 			if deployBlueGreenSlice[i].NodePort == 0 {
 				deployBlueGreenSlice[i].NodePortDisplay = "Auto-generated"
@@ -93,6 +101,20 @@ func (c *ListController) Get() {
 			} else {
 				deployBlueGreenSlice[i].HiddenTagGuiDeployDeployBlueGreenSelect = "<div hidden>"
 			}
+			promotion, promoted := promotionMap[deployBlueGreenSlice[i].ImageInformation]
+			if promoted {
+				deployBlueGreenSlice[i].PromotionDisplay = promotion.Version + " to " + promotion.IdleNamespace + " " + promotion.State
+			}
+			if hasGuiDeployDeployBlueGreenPromote && (promoted == false || promotion.IsActive() == false) {
+				deployBlueGreenSlice[i].HiddenTagGuiDeployDeployBlueGreenPromote = "<div class='btn-group'>"
+			} else {
+				deployBlueGreenSlice[i].HiddenTagGuiDeployDeployBlueGreenPromote = "<div hidden>"
+			}
+			if hasGuiDeployDeployBlueGreenPromotion && promoted {
+				deployBlueGreenSlice[i].HiddenTagGuiDeployDeployBlueGreenPromotion = "<div class='btn-group'>"
+			} else {
+				deployBlueGreenSlice[i].HiddenTagGuiDeployDeployBlueGreenPromotion = "<div hidden>"
+			}
 			if hasGuiDeployDeployBlueGreenDelete {
 				deployBlueGreenSlice[i].HiddenTagGuiDeployDeployBlueGreenDelete = "<div class='btn-group'>"
 			} else {
@@ -0,0 +1,176 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploybluegreen
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/bluegreenpromotion"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"sort"
+	"strconv"
+)
+
+const (
+	defaultProbeTimeoutInSecond = 3
+	defaultSuccessThreshold     = 3
+	defaultFailureThreshold     = 3
+	defaultSoakInSecond         = 300
+	defaultReadyTimeoutInSecond = 300
+)
+
+type PromoteController struct {
+	beego.Controller
+}
+
+func (c *PromoteController) Get() {
+	c.TplName = "deploy/deploybluegreen/promote.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+
+	imageInformation := c.GetString("imageInformation")
+
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
+
+	deployBlueGreen, err := cloudoneClient.GetDeployBlueGreen(imageInformation)
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+	if err == nil && deployBlueGreen == nil {
+		guimessage.AddDanger("Blue green deployment " + imageInformation + " doesn't exist")
+		c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/list")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	var namespaceSlice []string
+	if err == nil {
+		namespaceSlice, err = cloudoneClient.GetDeployBlueGreenDeployableNamespaceSlice(imageInformation)
+	}
+	var imageRecordSlice []backend.ImageRecord
+	if err == nil {
+		imageRecordSlice, err = cloudoneClient.GetImageRecordSlice(imageInformation)
+	}
+	var deployInformationSlice []backend.DeployInformation
+	if err == nil {
+		deployInformationSlice, err = cloudoneClient.GetDeployInformationSlice(deployBlueGreen.Namespace)
+	}
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/list")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	idleNamespaceSlice := make([]string, 0)
+	for _, namespace := range namespaceSlice {
+		if namespace != deployBlueGreen.Namespace {
+			idleNamespaceSlice = append(idleNamespaceSlice, namespace)
+		}
+	}
+	if len(idleNamespaceSlice) == 0 {
+		guimessage.AddDanger(imageInformation + " is only deployed in the selected namespace " + deployBlueGreen.Namespace + " so there is no idle namespace to promote to")
+		c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/list")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	filteredImageRecordSlice := make([]backend.ImageRecord, 0)
+	for _, imageRecord := range imageRecordSlice {
+		if imageRecord.Failure == false {
+			filteredImageRecordSlice = append(filteredImageRecordSlice, imageRecord)
+		}
+	}
+	sort.Sort(backend.ByImageRecord(filteredImageRecordSlice))
+
+	// Probe the first container port of the selected namespace by default
+	probe := "http :80 /"
+	for _, deployInformation := range deployInformationSlice {
+		if deployInformation.ImageInformationName == imageInformation && len(deployInformation.ContainerPortSlice) > 0 {
+			probe = "tcp :" + strconv.Itoa(deployInformation.ContainerPortSlice[0].ContainerPort)
+		}
+	}
+
+	c.Data["imageInformation"] = imageInformation
+	c.Data["activeNamespace"] = deployBlueGreen.Namespace
+	c.Data["idleNamespaceSlice"] = idleNamespaceSlice
+	c.Data["imageRecordSlice"] = filteredImageRecordSlice
+	c.Data["probe"] = probe
+	c.Data["probeTimeoutInSecond"] = defaultProbeTimeoutInSecond
+	c.Data["successThreshold"] = defaultSuccessThreshold
+	c.Data["failureThreshold"] = defaultFailureThreshold
+	c.Data["soakInSecond"] = defaultSoakInSecond
+	c.Data["readyTimeoutInSecond"] = defaultReadyTimeoutInSecond
+
+	guimessage.OutputMessage(c.Data)
+}
+
+func (c *PromoteController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	imageInformation := c.GetString("imageInformation")
+	version := c.GetString("version")
+	idleNamespace := c.GetString("idleNamespace")
+	probeTimeoutInSecond, _ := c.GetInt("probeTimeoutInSecond")
+	successThreshold, _ := c.GetInt("successThreshold")
+	failureThreshold, _ := c.GetInt("failureThreshold")
+	soakInSecond, _ := c.GetInt("soakInSecond")
+	readyTimeoutInSecond, _ := c.GetInt("readyTimeoutInSecond")
+
+	err := identity.AuthorizeNamespace(c.Ctx, idleNamespace)
+	var probeSlice []bluegreenpromotion.Probe
+	if err == nil {
+		probeSlice, err = bluegreenpromotion.ParseProbeSlice(c.GetString("probe"))
+	}
+	if err == nil {
+		err = bluegreenpromotion.Start(c.Ctx, bluegreenpromotion.StartInput{
+			ImageInformationName: imageInformation,
+			Version:              version,
+			IdleNamespace:        idleNamespace,
+			Gate: bluegreenpromotion.Gate{
+				ProbeSlice:           probeSlice,
+				ProbeTimeoutInSecond: probeTimeoutInSecond,
+				SuccessThreshold:     successThreshold,
+				FailureThreshold:     failureThreshold,
+				SoakInSecond:         soakInSecond,
+				ReadyTimeoutInSecond: readyTimeoutInSecond,
+			},
+		})
+	}
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/list")
+	} else {
+		guimessage.AddSuccess("Promotion of " + imageInformation + " version " + version + " to namespace " + idleNamespace + " is started")
+		c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/promotion?imageInformation="+imageInformation)
+	}
+
+	guimessage.RedirectMessage(c)
+}
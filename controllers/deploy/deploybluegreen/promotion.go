@@ -0,0 +1,209 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploybluegreen
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/bluegreenpromotion"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/shutdown"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"golang.org/x/net/websocket"
+	"time"
+)
+
+const (
+	promotionTicketTargetPrefix = "bluegreenpromotion/"
+	// The promotion is read from the session store in the interval since the runner may be on another replica
+	promotionPollInterval = time.Second
+)
+
+type PromotionController struct {
+	beego.Controller
+}
+
+func (c *PromotionController) Get() {
+	c.TplName = "deploy/deploybluegreen/promotion.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+	// Authorization for Button
+	user, _ := c.GetSession("user").(*rbac.User)
+
+	imageInformation := c.GetString("imageInformation")
+
+	promotion, err := bluegreenpromotion.GetPromotion(backend.GetClusterName(c.Ctx), imageInformation)
+	if err != nil || promotion == nil {
+		if err != nil {
+			// Error
+			guimessage.AddError(err)
+		} else {
+			guimessage.AddInfo("Blue green deployment " + imageInformation + " is never promoted")
+		}
+		c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/list")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	cloudoneGUIHost, cloudoneGUIPort := dashboard.GetServerHostAndPortFromUserRequest(c.Ctx.Input)
+
+	ticket, err := identity.CreateWebSocketTicket(c.Ctx, promotionTicketTargetPrefix+imageInformation)
+	if err != nil {
+		guimessage.AddError(err)
+	}
+
+	hiddenTagGuiDeployDeployBlueGreenPromotionAbort := "<div hidden>"
+	if promotion.IsActive() && user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploybluegreen/abort") {
+		hiddenTagGuiDeployDeployBlueGreenPromotionAbort = "<div class='btn-group'>"
+	}
+	hiddenTagGuiDeployDeployBlueGreenPromotionRevert := "<div hidden>"
+	if promotion.State == bluegreenpromotion.StateSucceeded && user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploybluegreen/revert") {
+		hiddenTagGuiDeployDeployBlueGreenPromotionRevert = "<div class='btn-group'>"
+	}
+
+	c.Data["cloudoneGUIHost"] = cloudoneGUIHost
+	c.Data["cloudoneGUIPort"] = cloudoneGUIPort
+	c.Data["ticket"] = ticket
+	c.Data["imageInformation"] = imageInformation
+	c.Data["promotion"] = promotion
+	c.Data["stepText"] = promotion.GetStepText()
+	c.Data["probeText"] = bluegreenpromotion.GetProbeText(promotion.Gate.ProbeSlice)
+	c.Data["pendingAction"] = bluegreenpromotion.GetPendingAction(promotion)
+	c.Data["hiddenTagGuiDeployDeployBlueGreenPromotionAbort"] = hiddenTagGuiDeployDeployBlueGreenPromotionAbort
+	c.Data["hiddenTagGuiDeployDeployBlueGreenPromotionRevert"] = hiddenTagGuiDeployDeployBlueGreenPromotionRevert
+
+	guimessage.OutputMessage(c.Data)
+}
+
+type PromotionWebSocketController struct {
+	beego.Controller
+}
+
+func (c *PromotionWebSocketController) Get() {
+	imageInformation := c.GetString("imageInformation")
+	_, cluster, err := identity.ExchangeWebSocketTicket(c.Ctx, c.GetString("ticket"), promotionTicketTargetPrefix+imageInformation)
+	if err != nil {
+		c.Ctx.Output.SetStatus(403)
+		c.Ctx.Output.Body([]byte(err.Error()))
+		return
+	}
+
+	// The connection is hijacked so nothing is rendered after it
+	c.EnableRender = false
+
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer metrics.StartWebSocketSession(metrics.WebSocketKindPromotion)()
+		endWebSocketSession, err := shutdown.StartWebSocketSession(ws, metrics.WebSocketKindPromotion, false)
+		if err != nil {
+			ws.Write([]byte(err.Error() + "\n"))
+			ws.Close()
+			return
+		}
+		defer endWebSocketSession()
+		streamPromotion(ws, cluster, imageInformation)
+	}}
+	server.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
+}
+
+// streamPromotion writes the log lines of the promotion as they are added until the promotion ends
+func streamPromotion(ws *websocket.Conn, cluster string, imageInformation string) {
+	defer ws.Close()
+
+	lastSequence := 0
+	for {
+		promotion, err := bluegreenpromotion.GetPromotion(cluster, imageInformation)
+		if err != nil {
+			ws.Write([]byte("Fail to get the promotion with error " + err.Error() + "\n"))
+			return
+		}
+		if promotion == nil {
+			ws.Write([]byte("The promotion doesn't exist\n"))
+			return
+		}
+
+		for _, logLine := range promotion.LogSlice {
+			if logLine.Sequence <= lastSequence {
+				continue
+			}
+			_, err := ws.Write([]byte(logLine.Time.Format(time.RFC3339) + " [" + logLine.Step + "] " + logLine.Text + "\n"))
+			if err != nil {
+				// The page is closed
+				return
+			}
+			lastSequence = logLine.Sequence
+		}
+
+		if promotion.IsActive() == false {
+			ws.Write([]byte("The promotion is " + promotion.State + "\n"))
+			return
+		}
+
+		time.Sleep(promotionPollInterval)
+	}
+}
+
+type PromotionAbortController struct {
+	beego.Controller
+}
+
+func (c *PromotionAbortController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	imageInformation := c.GetString("imageInformation")
+
+	err := bluegreenpromotion.Request(c.Ctx, imageInformation, bluegreenpromotion.ActionAbort)
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Promotion of " + imageInformation + " will be aborted in the next check")
+	}
+
+	c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/promotion?imageInformation="+imageInformation)
+
+	guimessage.RedirectMessage(c)
+}
+
+type PromotionRevertController struct {
+	beego.Controller
+}
+
+func (c *PromotionRevertController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	imageInformation := c.GetString("imageInformation")
+
+	err := bluegreenpromotion.Revert(c.Ctx, imageInformation)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Promotion of " + imageInformation + " is reverted")
+	}
+
+	c.Ctx.Redirect(302, "/gui/deploy/deploybluegreen/promotion?imageInformation="+imageInformation)
+
+	guimessage.RedirectMessage(c)
+}
@@ -17,8 +17,8 @@ package deploybluegreen
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type SelectController struct {
@@ -35,18 +35,9 @@ func (c *SelectController) Get() {
 	imageInformation := c.GetString("imageInformation")
 	source := c.GetString("source")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploybluegreens/" + imageInformation
-
-	deployBlueGreen := DeployBlueGreen{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	statusCode, _, _, err := restclient.RequestWithStructure("GET", url, nil, &deployBlueGreen, tokenHeaderMap)
+	deployBlueGreen, err := cloudoneClient.GetDeployBlueGreen(imageInformation)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -73,7 +64,7 @@ func (c *SelectController) Get() {
 
 		return
 
-	} else if statusCode == 404 {
+	} else if deployBlueGreen == nil {
 		// Not existing, create
 		c.Data["actionButtonValue"] = "Create"
 		c.Data["pageHeader"] = "Create Blue Green Deployment"
@@ -103,14 +94,7 @@ func (c *SelectController) Get() {
 		}
 	}
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploybluegreens/deployable/" + imageInformation
-
-	namespaceSlice := make([]string, 0)
-
-	tokenHeaderMap, _ = c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestGetWithStructure(url, &namespaceSlice, tokenHeaderMap)
+	namespaceSlice, err := cloudoneClient.GetDeployBlueGreenDeployableNamespaceSlice(imageInformation)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -152,32 +136,24 @@ func (c *SelectController) Get() {
 func (c *SelectController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	imageInformation := c.GetString("imageInformation")
 	namespace := c.GetString("namespace")
 	nodePort, _ := c.GetInt("nodePort")
 	description := c.GetString("description")
 	sessionAffinity := c.GetString("sessionAffinity")
 
-	deployBlueGreen := DeployBlueGreen{
-		imageInformation,
-		namespace,
-		nodePort,
-		description,
-		sessionAffinity,
-		"",
-		"",
-		"",
+	deployBlueGreen := backend.DeployBlueGreen{
+		ImageInformation: imageInformation,
+		Namespace:        namespace,
+		NodePort:         nodePort,
+		Description:      description,
+		SessionAffinity:  sessionAffinity,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/deploybluegreens/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPutWithStructure(url, deployBlueGreen, nil, tokenHeaderMap)
+	err := identity.AuthorizeNamespace(c.Ctx, namespace)
+	if err == nil {
+		err = backend.NewCloudoneClient(c.Ctx).UpdateDeployBlueGreen(deployBlueGreen)
+	}
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -185,7 +161,7 @@ func (c *SelectController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Create blue green deployment " + imageInformation + " success")
 	}
@@ -0,0 +1,100 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploycanary
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/canary"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+)
+
+type PauseController struct {
+	beego.Controller
+}
+
+type ResumeController struct {
+	beego.Controller
+}
+
+type PromoteController struct {
+	beego.Controller
+}
+
+type AbortController struct {
+	beego.Controller
+}
+
+type DeleteController struct {
+	beego.Controller
+}
+
+// request asks the runner to take the action. The result is shown in the list after the next check.
+func request(c *beego.Controller, action string, text string) {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	namespace, _ := c.GetSession("namespace").(string)
+
+	name := c.GetString("name")
+
+	err := canary.Request(c.Ctx, namespace, name, action)
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Canary of deploy " + name + " will be " + text + " in the next check")
+	}
+
+	// Redirect to list
+	c.Ctx.Redirect(302, "/gui/deploy/deploycanary/list")
+
+	guimessage.RedirectMessage(c)
+}
+
+func (c *PauseController) Post() {
+	request(&c.Controller, canary.ActionPause, "paused")
+}
+
+func (c *ResumeController) Post() {
+	request(&c.Controller, canary.ActionResume, "resumed")
+}
+
+func (c *PromoteController) Post() {
+	request(&c.Controller, canary.ActionPromote, "promoted")
+}
+
+func (c *AbortController) Post() {
+	request(&c.Controller, canary.ActionAbort, "aborted and rolled back")
+}
+
+func (c *DeleteController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	namespace, _ := c.GetSession("namespace").(string)
+
+	name := c.GetString("name")
+
+	err := canary.Delete(c.Ctx, namespace, name)
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Canary of deploy " + name + " is deleted")
+	}
+
+	// Redirect to list
+	c.Ctx.Redirect(302, "/gui/deploy/deploycanary/list")
+
+	guimessage.RedirectMessage(c)
+}
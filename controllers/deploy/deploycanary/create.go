@@ -0,0 +1,133 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploycanary
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/canary"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"sort"
+)
+
+const (
+	defaultStepText      = "5,25,50,100"
+	defaultPauseInSecond = 300
+	// Any restart or warning event of the canary rolls it back
+	defaultRestartMaximum = 0
+	defaultEventMaximum   = 0
+)
+
+type CreateController struct {
+	beego.Controller
+}
+
+func (c *CreateController) Get() {
+	c.TplName = "deploy/deploycanary/create.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+
+	name := c.GetString("name")
+	currentVersion := c.GetString("currentVersion")
+
+	imageRecordSlice, err := backend.NewCloudoneClient(c.Ctx).GetImageRecordSlice(name)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		// Redirect to list
+		c.Ctx.Redirect(302, "/gui/deploy/deploy/list")
+		return
+	}
+
+	filteredImageRecordSlice := make([]backend.ImageRecord, 0)
+	for _, imageRecord := range imageRecordSlice {
+		if imageRecord.Version != currentVersion && imageRecord.Failure == false {
+			filteredImageRecordSlice = append(filteredImageRecordSlice, imageRecord)
+		}
+	}
+	sort.Sort(backend.ByImageRecord(filteredImageRecordSlice))
+
+	c.Data["name"] = name
+	c.Data["currentVersion"] = currentVersion
+	c.Data["imageRecordSlice"] = filteredImageRecordSlice
+	c.Data["step"] = defaultStepText
+	c.Data["pauseInSecond"] = defaultPauseInSecond
+	c.Data["restartMaximum"] = defaultRestartMaximum
+	c.Data["eventMaximum"] = defaultEventMaximum
+
+	guimessage.OutputMessage(c.Data)
+}
+
+func (c *CreateController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	namespace, _ := c.GetSession("namespace").(string)
+
+	name := c.GetString("name")
+	version := c.GetString("version")
+	pauseInSecond, _ := c.GetInt("pauseInSecond")
+	cpuUsageMaximumInMillisecond, _ := c.GetInt64("cpuUsageMaximumInMillisecond")
+	memoryUsageMaximumInMB, _ := c.GetInt64("memoryUsageMaximumInMB")
+	// Empty disables the check
+	restartMaximum, err := c.GetInt("restartMaximum")
+	if err != nil {
+		restartMaximum = -1
+	}
+	eventMaximum, err := c.GetInt("eventMaximum")
+	if err != nil {
+		eventMaximum = -1
+	}
+
+	stepPercentageSlice, err := canary.ParseStepPercentageSlice(c.GetString("step"))
+	if err == nil {
+		err = canary.Create(c.Ctx, namespace, canary.CreateInput{
+			ImageInformationName: name,
+			Version:              version,
+			StepPercentageSlice:  stepPercentageSlice,
+			PauseInSecond:        pauseInSecond,
+			Threshold: canary.Threshold{
+				CpuUsageMaximumInMillisecond: cpuUsageMaximumInMillisecond,
+				MemoryUsageMaximumInMB:       memoryUsageMaximumInMB,
+				RestartMaximum:               restartMaximum,
+				EventMaximum:                 eventMaximum,
+			},
+		})
+	}
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Canary of deploy " + name + " version " + version + " is started")
+	}
+
+	// Redirect to list
+	c.Ctx.Redirect(302, "/gui/deploy/deploycanary/list")
+
+	guimessage.RedirectMessage(c)
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploycanary
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/canary"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_utility/rbac"
+)
+
+type ListController struct {
+	beego.Controller
+}
+
+type Canary struct {
+	canary.Canary
+	Active                                bool
+	Percentage                            int
+	StepText                              string
+	PendingAction                         string
+	HiddenTagGuiDeployDeployCanaryPause   string
+	HiddenTagGuiDeployDeployCanaryResume  string
+	HiddenTagGuiDeployDeployCanaryPromote string
+	HiddenTagGuiDeployDeployCanaryAbort   string
+	HiddenTagGuiDeployDeployCanaryDelete  string
+}
+
+func getHiddenTag(visible bool) string {
+	if visible {
+		return "<div class='btn-group'>"
+	}
+	return "<div hidden>"
+}
+
+func (c *ListController) Get() {
+	c.TplName = "deploy/deploycanary/list.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+	// Authorization for Button
+	user, _ := c.GetSession("user").(*rbac.User)
+	// Tag won't work in loop so need to be placed in data
+	hiddenTagGuiDeployDeployCanaryPause := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploycanary/pause")
+	hiddenTagGuiDeployDeployCanaryResume := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploycanary/resume")
+	hiddenTagGuiDeployDeployCanaryPromote := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploycanary/promote")
+	hiddenTagGuiDeployDeployCanaryAbort := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploycanary/abort")
+	hiddenTagGuiDeployDeployCanaryDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploycanary/delete")
+
+	namespace, _ := c.GetSession("namespace").(string)
+
+	canarySlice, err := canary.GetCanarySlice(backend.GetClusterName(c.Ctx), namespace)
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		displayCanarySlice := make([]Canary, 0)
+		for _, backendCanary := range canarySlice {
+			displayCanary := Canary{
+				Canary:        backendCanary,
+				Active:        backendCanary.IsActive(),
+				Percentage:    backendCanary.GetPercentage(),
+				StepText:      backendCanary.GetStepText(),
+				PendingAction: canary.GetPendingAction(&backendCanary),
+			}
+			displayCanary.HiddenTagGuiDeployDeployCanaryPause = getHiddenTag(hiddenTagGuiDeployDeployCanaryPause && backendCanary.State == canary.StateRunning)
+			displayCanary.HiddenTagGuiDeployDeployCanaryResume = getHiddenTag(hiddenTagGuiDeployDeployCanaryResume && (backendCanary.State == canary.StatePaused || backendCanary.State == canary.StateHalted))
+			displayCanary.HiddenTagGuiDeployDeployCanaryPromote = getHiddenTag(hiddenTagGuiDeployDeployCanaryPromote && displayCanary.Active)
+			displayCanary.HiddenTagGuiDeployDeployCanaryAbort = getHiddenTag(hiddenTagGuiDeployDeployCanaryAbort && displayCanary.Active)
+			displayCanary.HiddenTagGuiDeployDeployCanaryDelete = getHiddenTag(hiddenTagGuiDeployDeployCanaryDelete && displayCanary.Active == false)
+			displayCanarySlice = append(displayCanarySlice, displayCanary)
+		}
+		c.Data["canarySlice"] = displayCanarySlice
+	}
+
+	guimessage.OutputMessage(c.Data)
+}
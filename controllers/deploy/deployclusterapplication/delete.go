@@ -17,31 +17,22 @@ package deployclusterapplication
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type DeleteController struct {
 	beego.Controller
 }
 
-func (c *DeleteController) Get() {
+func (c *DeleteController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace := c.GetSession("namespace").(string)
 
 	clusterApplicationName := c.GetString("clusterApplicationName")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/" + namespace + "/" + clusterApplicationName
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+	err := backend.NewCloudoneClient(c.Ctx).DeleteDeployClusterApplication(namespace, clusterApplicationName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -49,7 +40,7 @@ func (c *DeleteController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Deploy cluster application " + clusterApplicationName + " is deleted")
 	}
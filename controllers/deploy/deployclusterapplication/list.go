@@ -17,9 +17,9 @@ package deployclusterapplication
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,10 +28,7 @@ type ListController struct {
 }
 
 type DeployClusterApplication struct {
-	Name                                             string
-	Size                                             int
-	ServiceName                                      string
-	ReplicationControllerNameSlice                   []string
+	backend.DeployClusterApplication
 	HiddenTagGuiDeployDeployClusterApplicationSize   string
 	HiddenTagGuiDeployDeployClusterApplicationDelete string
 }
@@ -50,24 +47,14 @@ func (c *ListController) Get() {
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 	// Authorization for Button
 	user, _ := c.GetSession("user").(*rbac.User)
+	identity.SetPrivilegeHiddenTag(c.Data, "hiddenTagGuiDeployDeployManifestImport", user, "GET", "/gui/deploy/deploymanifest/import")
 	// Tag won't work in loop so need to be placed in data
 	hasGuiDeployDeployClusterApplicationSize := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deployclusterapplication/size")
 	hasGuiDeployDeployClusterApplicationDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deployclusterapplication/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/" + namespace
-
-	deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployClusterApplicationSlice, tokenHeaderMap)
+	backendDeployClusterApplicationSlice, err := backend.NewCloudoneClient(c.Ctx).GetDeployClusterApplicationSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -75,8 +62,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
+		for _, backendDeployClusterApplication := range backendDeployClusterApplicationSlice {
+			deployClusterApplicationSlice = append(deployClusterApplicationSlice, DeployClusterApplication{DeployClusterApplication: backendDeployClusterApplication})
+		}
+
 		for i := 0; i < len(deployClusterApplicationSlice); i++ {
 			if hasGuiDeployDeployClusterApplicationSize {
 				deployClusterApplicationSlice[i].HiddenTagGuiDeployDeployClusterApplicationSize = "<div class='btn-group'>"
@@ -17,54 +17,10 @@ package deployclusterapplication
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
-type Cluster struct {
-	Name                      string
-	Description               string
-	ReplicationControllerJson string
-	ServiceJson               string
-	Environment               map[string]string
-	ScriptType                string
-	ScriptContent             string
-}
-
-type ReplicationController struct {
-	Name           string
-	ReplicaAmount  int
-	Selector       ReplicationControllerSelector
-	Label          ReplicationControllerLabel
-	ContainerSlice []ReplicationControllerContainer
-}
-
-type ReplicationControllerSelector struct {
-	Name    string
-	Version string
-}
-
-type ReplicationControllerLabel struct {
-	Name string
-}
-
-type ReplicationControllerContainer struct {
-	Name             string
-	Image            string
-	PortSlice        []ReplicationControllerContainerPort
-	EnvironmentSlice []ReplicationControllerContainerEnvironment
-}
-
-type ReplicationControllerContainerPort struct {
-	Name          string
-	ContainerPort int
-}
-
-type ReplicationControllerContainerEnvironment struct {
-	Name  string
-	Value string
-}
-
 type SizeController struct {
 	beego.Controller
 }
@@ -76,22 +32,14 @@ func (c *SizeController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	name := c.GetString("name")
 	size := c.GetString("size")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/" + name
-	cluster := Cluster{}
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &cluster, tokenHeaderMap)
+	cluster, err := cloudoneClient.GetClusterApplication(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -110,11 +58,7 @@ func (c *SizeController) Get() {
 	c.Data["size"] = size
 
 	// Get configured environment from any one of replication controller belonging to this cluster application
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/" + namespace + "/" + name
-	deployClusterApplication := DeployClusterApplication{}
-
-	_, err = restclient.RequestGetWithStructure(url, &deployClusterApplication, tokenHeaderMap)
+	deployClusterApplication, err := cloudoneClient.GetDeployClusterApplication(namespace, name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -140,12 +84,7 @@ func (c *SizeController) Get() {
 
 	replicationControllerName := deployClusterApplication.ReplicationControllerNameSlice[0]
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace + "/" + replicationControllerName
-
-	replicationController := ReplicationController{}
-
-	_, err = restclient.RequestGetWithStructure(url, &replicationController, tokenHeaderMap)
+	replicationController, err := cloudoneClient.GetReplicationController(namespace, replicationControllerName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -173,14 +112,10 @@ func (c *SizeController) Get() {
 func (c *SizeController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	name := c.GetString("name")
-	size := c.GetString("size")
+	size, _ := c.GetInt("size")
 
 	keySlice := make([]string, 0)
 	inputMap := c.Input()
@@ -190,23 +125,15 @@ func (c *SizeController) Post() {
 		}
 	}
 
-	environmentSlice := make([]interface{}, 0)
+	environmentSlice := make([]backend.ClusterEnvironment, 0)
 	for _, key := range keySlice {
 		value := c.GetString(key)
 		if len(value) > 0 {
-			environmentMap := make(map[string]string)
-			environmentMap["name"] = key
-			environmentMap["value"] = value
-			environmentSlice = append(environmentSlice, environmentMap)
+			environmentSlice = append(environmentSlice, backend.ClusterEnvironment{Name: key, Value: value})
 		}
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/size/" + namespace + "/" + name + "?size=" + size
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPut(url, environmentSlice, tokenHeaderMap, true)
+	err := backend.NewCloudoneClient(c.Ctx).ResizeDeployClusterApplication(namespace, name, size, environmentSlice)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -214,7 +141,7 @@ func (c *SizeController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Cluster application " + name + " is resized")
 	}
@@ -0,0 +1,80 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymanifest
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/manifest"
+	"strings"
+)
+
+type ApplyController struct {
+	beego.Controller
+}
+
+func (c *ApplyController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	namespace, _ := c.GetSession("namespace").(string)
+	userName, _ := c.GetSession("username").(string)
+	text := getManifest(&c.Controller)
+
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
+
+	// Validate again since the namespace or the objects in it could be changed after the preview
+	plan, err := manifest.Preview(cloudoneClient, namespace, text)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		c.Ctx.Redirect(302, "/gui/deploy/deploymanifest/import")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	if plan.IsValid() == false {
+		for _, problem := range plan.ProblemSlice {
+			guimessage.AddDanger(problem)
+		}
+		c.Ctx.Redirect(302, "/gui/deploy/deploymanifest/import")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	launchedSlice, err := manifest.Apply(cloudoneClient, plan, userName)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if len(launchedSlice) > 0 {
+		guimessage.AddSuccess("Third-party service " + strings.Join(launchedSlice, ", ") + " is imported to namespace " + namespace)
+	}
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	}
+
+	c.Ctx.Redirect(302, "/gui/deploy/deployclusterapplication/list")
+
+	guimessage.RedirectMessage(c)
+}
@@ -0,0 +1,104 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploymanifest
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/manifest"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"io/ioutil"
+)
+
+type ImportController struct {
+	beego.Controller
+}
+
+// getManifest prefers the uploaded file to the text
+func getManifest(c *beego.Controller) string {
+	text := c.GetString("manifest")
+
+	file, _, err := c.GetFile("fileManifest")
+	if err == nil {
+		byteSlice, err := ioutil.ReadAll(file)
+		if err == nil && len(byteSlice) > 0 {
+			text = string(byteSlice)
+		}
+	}
+	if file != nil {
+		file.Close()
+	}
+
+	return text
+}
+
+func (c *ImportController) Get() {
+	c.TplName = "deploy/deploymanifest/import.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+
+	namespace, _ := c.GetSession("namespace").(string)
+	c.Data["namespace"] = namespace
+
+	guimessage.OutputMessage(c.Data)
+}
+
+// Post validates the manifest and shows what will be created without changing anything
+func (c *ImportController) Post() {
+	c.TplName = "deploy/deploymanifest/preview.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+	// Authorization for Button
+	user, _ := c.GetSession("user").(*rbac.User)
+	identity.SetPrivilegeHiddenTag(c.Data, "hiddenTagGuiDeployDeployManifestApply", user, "GET", "/gui/deploy/deploymanifest/apply")
+
+	namespace, _ := c.GetSession("namespace").(string)
+	text := getManifest(&c.Controller)
+
+	plan, err := manifest.Preview(backend.NewCloudoneClient(c.Ctx), namespace, text)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		c.Ctx.Redirect(302, "/gui/deploy/deploymanifest/import")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	if plan.IsValid() {
+		c.Data["hiddenTagApply"] = ""
+	} else {
+		c.Data["hiddenTagApply"] = "hidden"
+		for _, problem := range plan.ProblemSlice {
+			guimessage.AddDanger(problem)
+		}
+	}
+
+	c.Data["namespace"] = namespace
+	c.Data["manifest"] = text
+	c.Data["applicationSlice"] = plan.ApplicationSlice
+
+	guimessage.OutputMessage(c.Data)
+}
@@ -0,0 +1,72 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/auditsearch"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"strconv"
+	"time"
+)
+
+type ExportController struct {
+	beego.Controller
+}
+
+func (c *ExportController) Get() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	format := c.GetString("format")
+
+	contentType, err := auditsearch.GetContentType(format)
+	if err != nil {
+		guimessage.AddError(err)
+		c.Ctx.Redirect(302, "/gui/event/audit/list")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	criteria, err := auditsearch.ParseCriteria(c.GetString)
+	if err != nil {
+		guimessage.AddError(err)
+		c.Ctx.Redirect(302, "/gui/event/audit/list")
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	auditLogSlice, truncated, err := auditsearch.Search(backend.NewCloudoneAnalysisClient(c.Ctx), criteria)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		c.Ctx.Redirect(302, "/gui/event/audit/list?"+criteria.Query().Encode())
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	// The file is written directly instead of the template
+	c.EnableRender = false
+	c.Ctx.Output.Header("Content-Type", contentType)
+	c.Ctx.Output.Header("X-Audit-Log-Truncated", strconv.FormatBool(truncated))
+	c.Ctx.Output.Header("Content-Disposition", "attachment; filename=auditlog_"+time.Now().Format("20060102150405")+"."+format)
+	auditsearch.Export(c.Ctx.ResponseWriter, format, auditLogSlice)
+}
@@ -17,34 +17,17 @@ package audit
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/auditsearch"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strconv"
-	"time"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type AuditLog struct {
-	Component         string
-	Kind              string
-	Path              string
-	UserName          string
-	RemoteAddress     string
-	RemoteHost        string
-	CreatedTime       time.Time
-	QueryParameterMap map[string][]string
-	PathParameterMap  map[string]string
-	RequestMethod     string
-	RequestURI        string
-	RequestBody       string
-	RequestHeader     map[string][]string
-	Description       string
-}
-
 const (
 	amountPerPage = 10
 )
@@ -54,32 +37,81 @@ type UserData struct {
 	Selected string
 }
 
+type SortLink struct {
+	URL   string
+	Arrow string
+}
+
 func (c *ListController) Get() {
 	c.TplName = "event/audit/list.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
-
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
+	// Authorization for Button
+	user, _ := c.GetSession("user").(*rbac.User)
+	identity.SetPrivilegeHiddenTag(c.Data, "hiddenTagGuiEventAuditExport", user, "GET", "/gui/event/audit/export")
+	identity.SetPrivilegeHiddenTag(c.Data, "hiddenTagGuiEventAuditVerify", user, "GET", "/gui/event/audit/verify")
 
 	offset, _ := c.GetInt("offset")
-	userName := c.GetString("userName")
 
-	if userName == "All" {
-		userName = ""
+	criteria, err := auditsearch.ParseCriteria(c.GetString)
+	if err != nil {
+		guimessage.AddError(err)
+		criteria, _ = auditsearch.ParseCriteria(func(key string, def ...string) string { return "" })
 	}
 
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/auditlogs/" + userName + "?size=" + strconv.Itoa(amountPerPage) + "&offset=" + strconv.Itoa(offset)
+	c.Data["userName"] = criteria.UserName
+	c.Data["component"] = criteria.Component
+	c.Data["pathPrefix"] = criteria.PathPrefix
+	c.Data["method"] = criteria.Method
+	c.Data["remoteAddress"] = criteria.RemoteAddress
+	c.Data["requestID"] = criteria.RequestID
+	if criteria.From != nil {
+		c.Data["from"] = criteria.From.Local().Format(auditsearch.GUIWidgetTimePickerFormat)
+	}
+	if criteria.To != nil {
+		c.Data["to"] = criteria.To.Local().Format(auditsearch.GUIWidgetTimePickerFormat)
+	}
 
-	auditLogSlice := make([]AuditLog, 0)
+	c.Data["exportUrlCSV"] = "/gui/event/audit/export?format=" + auditsearch.FormatCSV + "&" + criteria.Query().Encode()
+	c.Data["exportUrlNDJSON"] = "/gui/event/audit/export?format=" + auditsearch.FormatNDJSON + "&" + criteria.Query().Encode()
+
+	// Clicking the sorted column again reverses the order
+	sortLinkMap := make(map[string]SortLink)
+	for _, sortBy := range []string{auditsearch.SortByCreatedTime, auditsearch.SortByUserName, auditsearch.SortByComponent, auditsearch.SortByPath, auditsearch.SortByMethod, auditsearch.SortByRemoteAddress} {
+		sortQuery := criteria.Query()
+		sortQuery.Set("sortBy", sortBy)
+		sortQuery.Set("order", "desc")
+		arrow := ""
+		if criteria.SortBy == sortBy {
+			if criteria.Descending {
+				sortQuery.Set("order", "asc")
+				arrow = "&darr;"
+			} else {
+				arrow = "&uarr;"
+			}
+		}
+		sortLinkMap[sortBy] = SortLink{"/gui/event/audit/list?" + sortQuery.Encode(), arrow}
+	}
+	c.Data["sortLinkMap"] = sortLinkMap
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneAnalysisClient := backend.NewCloudoneAnalysisClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &auditLogSlice, tokenHeaderMap)
+	var auditLogSlice []backend.AuditLog
+	if criteria.IsPagedByBackend() {
+		auditLogSlice, err = cloudoneAnalysisClient.GetAuditLogSlice(criteria.UserName, amountPerPage, offset)
+	} else {
+		var truncated bool
+		auditLogSlice, truncated, err = auditsearch.Search(cloudoneAnalysisClient, criteria)
+		if err == nil {
+			if truncated {
+				guimessage.AddWarning("Only the latest " + strconv.Itoa(auditsearch.GetScanMaximum()) + " audit logs are searched so the result is truncated. Select the user or the time from to search the older ones.")
+			}
+			c.Data["totalAmount"] = len(auditLogSlice)
+			auditLogSlice = auditsearch.Page(auditLogSlice, offset, amountPerPage)
+		}
+	}
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -87,7 +119,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		for i := 0; i < len(auditLogSlice); i++ {
 			auditLogSlice[i].CreatedTime = auditLogSlice[i].CreatedTime.Local()
@@ -116,31 +148,22 @@ func (c *ListController) Get() {
 		nextTo := nextOffset + amountPerPage
 		c.Data["nextLabel"] = strconv.Itoa(nextFrom) + "~" + strconv.Itoa(nextTo)
 
+		query := criteria.Query()
 		c.Data["acknowledgeActive"] = "active"
-		c.Data["paginationUrlPrevious"] = "/gui/event/audit/list?offset=" + strconv.Itoa(previousOffset) + "&userName=" + userName
-		c.Data["paginationUrlNext"] = "/gui/event/audit/list?offset=" + strconv.Itoa(nextOffset) + "&userName=" + userName
-
+		c.Data["paginationUrlPrevious"] = "/gui/event/audit/list?offset=" + strconv.Itoa(previousOffset) + "&" + query.Encode()
+		c.Data["paginationUrlNext"] = "/gui/event/audit/list?offset=" + strconv.Itoa(nextOffset) + "&" + query.Encode()
 		c.Data["auditLogSlice"] = auditLogSlice
 
 		// Get user slice to select
-		cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-		cloudoneHost := beego.AppConfig.String("cloudoneHost")
-		cloudonePort := beego.AppConfig.String("cloudonePort")
-
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/authorizations/users"
-
-		userSlice := make([]rbac.User, 0)
-
-		_, err = restclient.RequestGetWithStructure(url, &userSlice, tokenHeaderMap)
+		userSlice, err := backend.NewCloudoneClient(c.Ctx).GetUserSlice()
 
 		if err != nil {
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			userDataSlice := make([]UserData, 0)
 			userDataSlice = append(userDataSlice, UserData{"All", ""})
 			for _, user := range userSlice {
-				if user.Name == userName {
+				if user.Name == criteria.UserName {
 					userDataSlice = append(userDataSlice, UserData{user.Name, "selected"})
 				} else {
 					userDataSlice = append(userDataSlice, UserData{user.Name, ""})
@@ -0,0 +1,116 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/auditchain"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/auditsearch"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"strconv"
+)
+
+type VerifyController struct {
+	beego.Controller
+}
+
+func (c *VerifyController) Get() {
+	c.TplName = "event/audit/verify.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+
+	key := identity.GetAuditLogChainKey()
+	if len(key) == 0 {
+		guimessage.AddWarning("The audit logs are not signed since auditLogChainKey is not configured")
+		guimessage.OutputMessage(c.Data)
+		return
+	}
+
+	// The whole chain is verified so only the component is filtered
+	criteria, _ := auditsearch.ParseCriteria(func(key string, def ...string) string {
+		if key == "component" {
+			return identity.GetConponentName()
+		}
+		return ""
+	})
+
+	auditLogSlice, truncated, err := auditsearch.Search(backend.NewCloudoneAnalysisClient(c.Ctx), criteria)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	var chainHeadSlice []auditchain.ChainHead
+	if err == nil {
+		chainHeadSlice, err = identity.GetAuditLogChainHeadSlice(backend.GetClusterName(c.Ctx))
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		if truncated {
+			guimessage.AddWarning("Only the latest audit logs are verified so the beginning of the older chains may be reported as missing")
+			chainHeadSlice = getChainHeadSliceInRange(chainHeadSlice, auditLogSlice)
+		}
+		if statistic := identity.GetAuditLogStatistic(); statistic.Queued+statistic.SpoolEntry > 0 {
+			guimessage.AddWarning("Some audit logs are waiting to be delivered so the newest sequences may be reported as missing until they are delivered")
+		}
+
+		report := auditchain.Verify(key, auditLogSlice, chainHeadSlice)
+		for i := range report.ChainReportSlice {
+			for j := range report.ChainReportSlice[i].ProblemRecordSlice {
+				report.ChainReportSlice[i].ProblemRecordSlice[j].CreatedTime = report.ChainReportSlice[i].ProblemRecordSlice[j].CreatedTime.Local()
+			}
+		}
+
+		if report.UnchainedSinceSignedAmount > 0 {
+			guimessage.AddDanger(strconv.Itoa(report.UnchainedSinceSignedAmount) + " audit logs are not signed although they are created after the signing starts")
+		}
+		if report.IsVerified() {
+			guimessage.AddSuccess("All audit log chains are verified")
+		} else {
+			guimessage.AddDanger("Some audit logs are missing or modified")
+		}
+
+		c.Data["report"] = report
+	}
+
+	guimessage.OutputMessage(c.Data)
+}
+
+// getChainHeadSliceInRange drops the heads of the chains older than the verified audit logs so they are not reported as deleted
+func getChainHeadSliceInRange(chainHeadSlice []auditchain.ChainHead, auditLogSlice []backend.AuditLog) []auditchain.ChainHead {
+	if len(auditLogSlice) == 0 {
+		return chainHeadSlice
+	}
+	oldestTime := auditLogSlice[0].CreatedTime
+	for _, auditLog := range auditLogSlice {
+		if auditLog.CreatedTime.Before(oldestTime) {
+			oldestTime = auditLog.CreatedTime
+		}
+	}
+	inRangeChainHeadSlice := make([]auditchain.ChainHead, 0)
+	for _, chainHead := range chainHeadSlice {
+		if chainHead.UpdatedTime.Before(oldestTime) == false {
+			inRangeChainHeadSlice = append(inRangeChainHeadSlice, chainHead)
+		}
+	}
+	return inRangeChainHeadSlice
+}
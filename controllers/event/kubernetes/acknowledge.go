@@ -17,33 +17,25 @@ package kubernetes
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type AcknowledgeController struct {
 	beego.Controller
 }
 
-func (c *AcknowledgeController) Get() {
+func (c *AcknowledgeController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
 	namespace := c.GetString("namespace")
 	id := c.GetString("id")
 	acknowledge := c.GetString("acknowledge")
 
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/historicalevents/" + namespace + "/" + id + "?acknowledge=" + acknowledge
-
-	jsonMapSlice := make([]map[string]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPutWithStructure(url, nil, &jsonMapSlice, tokenHeaderMap)
+	err := identity.AuthorizeNamespace(c.Ctx, namespace)
+	if err == nil {
+		err = backend.NewCloudoneAnalysisClient(c.Ctx).AcknowledgeKubernetesEvent(namespace, id, acknowledge == "true")
+	}
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -51,7 +43,7 @@ func (c *AcknowledgeController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Acknowledged event")
 	}
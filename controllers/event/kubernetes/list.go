@@ -17,11 +17,10 @@ package kubernetes
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strconv"
-	"time"
 )
 
 type ListController struct {
@@ -29,17 +28,7 @@ type ListController struct {
 }
 
 type KubernetesEvent struct {
-	Namespace                              string
-	Name                                   string
-	Kind                                   string
-	Source                                 map[string]interface{}
-	Id                                     string
-	FirstTimestamp                         time.Time
-	LastTimestamp                          time.Time
-	Count                                  int
-	Message                                string
-	Reason                                 string
-	Acknowledge                            bool
+	backend.KubernetesEvent
 	Action                                 string
 	Button                                 string
 	HiddenTagGuiEventKubernetesAcknowledge string
@@ -60,10 +49,6 @@ func (c *ListController) Get() {
 	// Tag won't work in loop so need to be placed in data
 	hasGuiEventKubernetesAcknowledge := user.HasPermission(identity.GetConponentName(), "GET", "/gui/event/kubernetes/acknowledge")
 
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
 	acknowledge := c.GetString("acknowledge")
 	if acknowledge == "" {
 		acknowledge = "false"
@@ -71,14 +56,7 @@ func (c *ListController) Get() {
 
 	offset, _ := c.GetInt("offset")
 
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/historicalevents?acknowledge=" + acknowledge + "&size=" + strconv.Itoa(amountPerPage) + "&offset=" + strconv.Itoa(offset)
-
-	jsonMapSlice := make([]map[string]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &jsonMapSlice, tokenHeaderMap)
+	backendKubernetesEventSlice, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetKubernetesEventSlice(acknowledge == "true", amountPerPage, offset)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -86,7 +64,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		var action string
 		var button string
@@ -99,45 +77,11 @@ func (c *ListController) Get() {
 		}
 
 		kubernetesEventSlice := make([]KubernetesEvent, 0)
-		for _, jsonMap := range jsonMapSlice {
-			sourceJsonMap, _ := jsonMap["_source"].(map[string]interface{})
-
-			namespace, _ := sourceJsonMap["metadata"].(map[string]interface{})["namespace"].(string)
-			name, _ := sourceJsonMap["involvedObject"].(map[string]interface{})["name"].(string)
-			kind, _ := sourceJsonMap["involvedObject"].(map[string]interface{})["kind"].(string)
-			source, _ := sourceJsonMap["source"].(map[string]interface{})
-			id, _ := jsonMap["_id"].(string)
-			firstTimestamp, _ := sourceJsonMap["firstTimestamp"].(string)
-			lastTimestamp, _ := sourceJsonMap["lastTimestamp"].(string)
-			count, _ := sourceJsonMap["count"].(float64)
-			message, _ := sourceJsonMap["message"].(string)
-			reason, _ := sourceJsonMap["reason"].(string)
-			acknowledge, _ := sourceJsonMap["searchMetaData"].(map[string]interface{})["acknowledge"].(bool)
-
-			firstTime, err := time.Parse(time.RFC3339, firstTimestamp)
-			if err == nil {
-				firstTime = firstTime.Local()
-			}
-			lastTime, err := time.Parse(time.RFC3339, lastTimestamp)
-			if err == nil {
-				lastTime = lastTime.Local()
-			}
-
+		for _, backendKubernetesEvent := range backendKubernetesEventSlice {
 			kubernetesEvent := KubernetesEvent{
-				namespace,
-				name,
-				kind,
-				source,
-				id,
-				firstTime,
-				lastTime,
-				int(count),
-				message,
-				reason,
-				acknowledge,
-				action,
-				button,
-				"",
+				KubernetesEvent: backendKubernetesEvent,
+				Action:          action,
+				Button:          button,
 			}
 
 			kubernetesEventSlice = append(kubernetesEventSlice, kubernetesEvent)
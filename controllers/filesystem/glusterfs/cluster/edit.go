@@ -17,8 +17,8 @@ package cluster
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strings"
 	"time"
 )
@@ -45,18 +45,7 @@ func (c *EditController) Get() {
 		c.Data["sshSessionTimeoutInMilliSecond"] = 10000
 		c.Data["sshPort"] = 22
 	} else {
-		cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-		cloudoneHost := beego.AppConfig.String("cloudoneHost")
-		cloudonePort := beego.AppConfig.String("cloudonePort")
-
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/glusterfs/clusters/" + clusterName
-
-		glusterfsCluster := GlusterfsCluster{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &glusterfsCluster, tokenHeaderMap)
+		glusterfsCluster, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsCluster(clusterName)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -64,7 +53,7 @@ func (c *EditController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			guimessage.OutputMessage(c.Data)
 			return
 		}
@@ -98,24 +87,9 @@ func (c *EditController) Get() {
 	guimessage.OutputMessage(c.Data)
 }
 
-type GlusterfsClusterInput struct {
-	Name                           string
-	HostSlice                      []string
-	Path                           string
-	SSHDialTimeoutInMilliSecond    int
-	SSHSessionTimeoutInMilliSecond int
-	SSHPort                        int
-	SSHUser                        string
-	SSHPassword                    string
-}
-
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 	hostList := c.GetString("hostList")
 	path := c.GetString("path")
@@ -135,23 +109,18 @@ func (c *EditController) Post() {
 		}
 	}
 
-	glusterfsClusterInput := GlusterfsClusterInput{
-		name,
-		hostSlice,
-		path,
-		sshDialTimeoutInMilliSecond,
-		sshSessionTimeoutInMilliSecond,
-		sshPort,
-		sshUser,
-		sshPassword}
+	glusterfsClusterInput := backend.GlusterfsClusterInput{
+		Name:                           name,
+		HostSlice:                      hostSlice,
+		Path:                           path,
+		SSHDialTimeoutInMilliSecond:    sshDialTimeoutInMilliSecond,
+		SSHSessionTimeoutInMilliSecond: sshSessionTimeoutInMilliSecond,
+		SSHPort:                        sshPort,
+		SSHUser:                        sshUser,
+		SSHPassword:                    sshPassword}
 
 	if createOrUpdate == "create" {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/glusterfs/clusters/"
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestPostWithStructure(url, glusterfsClusterInput, nil, tokenHeaderMap)
+		err := backend.NewCloudoneClient(c.Ctx).CreateGlusterfsCluster(glusterfsClusterInput)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -159,17 +128,12 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("Glusterfs cluster " + name + " is created")
 		}
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/glusterfs/clusters/" + name
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestPutWithStructure(url, glusterfsClusterInput, nil, tokenHeaderMap)
+		err := backend.NewCloudoneClient(c.Ctx).UpdateGlusterfsCluster(glusterfsClusterInput)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -177,7 +141,7 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("Glusterfs cluster " + name + " is updated")
 		}
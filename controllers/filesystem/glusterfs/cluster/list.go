@@ -17,11 +17,10 @@ package cluster
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
-	"time"
 )
 
 type ListController struct {
@@ -29,14 +28,7 @@ type ListController struct {
 }
 
 type GlusterfsCluster struct {
-	Name                                         string
-	HostSlice                                    []string
-	Path                                         string
-	SSHDialTimeout                               time.Duration
-	SSHSessionTimeout                            time.Duration
-	SSHPort                                      int
-	SSHUser                                      string
-	SSHPassword                                  string
+	backend.GlusterfsCluster
 	HiddenTagGuiFileSystemGlusterfsVolumeList    string
 	HiddenTagGuiFileSystemGlusterfsClusterEdit   string
 	HiddenTagGuiFileSystemGlusterfsClusterDelete string
@@ -62,18 +54,7 @@ func (c *ListController) Get() {
 	hasGuiFileSystemGlusterfsClusterEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/filesystem/glusterfs/cluster/edit")
 	hasGuiFileSystemGlusterfsClusterDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/filesystem/glusterfs/cluster/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/"
-
-	glusterfsClusterSlice := make([]GlusterfsCluster, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &glusterfsClusterSlice, tokenHeaderMap)
+	backendGlusterfsClusterSlice, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsClusterSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -81,8 +62,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		glusterfsClusterSlice := make([]GlusterfsCluster, 0)
+		for _, backendGlusterfsCluster := range backendGlusterfsClusterSlice {
+			glusterfsClusterSlice = append(glusterfsClusterSlice, GlusterfsCluster{GlusterfsCluster: backendGlusterfsCluster})
+		}
+
 		for i := 0; i < len(glusterfsClusterSlice); i++ {
 			if hasGuiFileSystemGlusterfsVolumeList {
 				glusterfsClusterSlice[i].HiddenTagGuiFileSystemGlusterfsVolumeList = "<div class='btn-group'>"
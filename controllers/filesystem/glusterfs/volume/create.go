@@ -16,26 +16,12 @@ package volume
 
 import (
 	"github.com/astaxie/beego"
-	"github.com/cloudawan/cloudone_gui/controllers/filesystem/glusterfs/cluster"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strings"
 )
 
-type GlusterfsVolumeCreateParameter struct {
-	ClusterName  string
-	VolumeName   string
-	Stripe       int
-	Replica      int
-	Arbiter      int
-	Disperse     int
-	DisperseData int
-	Redundancy   int
-	Transport    string
-	HostSlice    []string
-}
-
 type CreateController struct {
 	beego.Controller
 }
@@ -47,20 +33,9 @@ func (c *CreateController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	clusterName := c.GetString("clusterName")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName
-
-	glusterfsCluster := cluster.GlusterfsCluster{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &glusterfsCluster, tokenHeaderMap)
+	glusterfsCluster, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsCluster(clusterName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -68,7 +43,7 @@ func (c *CreateController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		hostList := ""
 		length := len(glusterfsCluster.HostSlice)
@@ -91,10 +66,6 @@ func (c *CreateController) Get() {
 func (c *CreateController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	clusterName := c.GetString("clusterName")
 	hostList := c.GetString("hostList")
 
@@ -117,25 +88,20 @@ func (c *CreateController) Post() {
 		}
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName + "/volumes/"
-
-	glusterfsVolumeCreateParameter := GlusterfsVolumeCreateParameter{
-		clusterName,
-		name,
-		stripe,
-		replica,
-		arbiter,
-		disperse,
-		disperseData,
-		redundancy,
-		transport,
-		hostSlice,
+	glusterfsVolumeCreateParameter := backend.GlusterfsVolumeCreateParameter{
+		ClusterName:  clusterName,
+		VolumeName:   name,
+		Stripe:       stripe,
+		Replica:      replica,
+		Arbiter:      arbiter,
+		Disperse:     disperse,
+		DisperseData: disperseData,
+		Redundancy:   redundancy,
+		Transport:    transport,
+		HostSlice:    hostSlice,
 	}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPostWithStructure(url, glusterfsVolumeCreateParameter, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).CreateGlusterfsVolume(glusterfsVolumeCreateParameter)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -143,7 +109,7 @@ func (c *CreateController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Glusterfs volume " + name + " is created and started")
 	}
@@ -17,9 +17,9 @@ package volume
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type ListController struct {
@@ -27,15 +27,7 @@ type ListController struct {
 }
 
 type GlusterfsVolume struct {
-	VolumeName                                  string
-	Type                                        string
-	VolumeID                                    string
-	Status                                      string
-	NumberOfBricks                              string
-	TransportType                               string
-	Bricks                                      []string
-	Size                                        int
-	ClusterName                                 string
+	backend.GlusterfsVolume
 	HiddenTagGuiFileSystemGlusterfsVolumeReset  string
 	HiddenTagGuiFileSystemGlusterfsVolumeDelete string
 }
@@ -54,20 +46,9 @@ func (c *ListController) Get() {
 	hasHiddenTagGuiFileSystemGlusterfsVolumeReset := user.HasPermission(identity.GetConponentName(), "GET", "/gui/filesystem/glusterfs/volume/reset")
 	hasHiddenTagGuiFileSystemGlusterfsVolumeDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/filesystem/glusterfs/volume/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	clusterName := c.GetString("clusterName")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName + "/volumes/"
-
-	glusterfsVolumeSlice := make([]GlusterfsVolume, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &glusterfsVolumeSlice, tokenHeaderMap)
+	backendGlusterfsVolumeSlice, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsVolumeSlice(clusterName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -75,8 +56,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		glusterfsVolumeSlice := make([]GlusterfsVolume, 0)
+		for _, backendGlusterfsVolume := range backendGlusterfsVolumeSlice {
+			glusterfsVolumeSlice = append(glusterfsVolumeSlice, GlusterfsVolume{GlusterfsVolume: backendGlusterfsVolume})
+		}
+
 		for i := 0; i < len(glusterfsVolumeSlice); i++ {
 			glusterfsVolumeSlice[i].ClusterName = clusterName
 
@@ -17,32 +17,21 @@ package volume
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type ResetController struct {
 	beego.Controller
 }
 
-func (c *ResetController) Get() {
+func (c *ResetController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	clusterName := c.GetString("clusterName")
 	glusterfsVolume := c.GetString("glusterfsVolume")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName + "/volumes/" + glusterfsVolume
-
-	jsonMap := make(map[string]interface{})
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPut(url, jsonMap, tokenHeaderMap, true)
+	err := backend.NewCloudoneClient(c.Ctx).ResetGlusterfsVolume(clusterName, glusterfsVolume)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -50,7 +39,7 @@ func (c *ResetController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Glusterfs volume " + glusterfsVolume + " is reset")
 	}
@@ -0,0 +1,100 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/json"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/auditchain"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"os"
+	"sync"
+	"time"
+)
+
+const auditLogChainHeadKeyPrefix = "auditchainhead/"
+
+var auditLogChainStartTime = time.Now()
+var auditLogChainMap = make(map[string]*auditchain.Chain)
+var auditLogChainLock = sync.Mutex{}
+
+// getAuditLogChain returns nil if auditLogChainKey is not configured.
+// Each cluster has its own chain since the audit logs are stored in the cloudone_analysis of the cluster.
+func getAuditLogChain(cluster string) *auditchain.Chain {
+	key := GetAuditLogChainKey()
+	if len(key) == 0 {
+		return nil
+	}
+
+	auditLogChainLock.Lock()
+	defer auditLogChainLock.Unlock()
+
+	chain, ok := auditLogChainMap[cluster]
+	if ok == false {
+		// The chain id tells which GUI process, which cluster and since when so the chains of the instances are verified separately
+		hostname, _ := os.Hostname()
+		chainID := hostname + "-" + cluster + "-" + auditLogChainStartTime.Format("20060102150405")
+		chain = auditchain.NewChain(key, chainID, func(chainHead auditchain.ChainHead) {
+			recordAuditLogChainHead(cluster, chainHead)
+		})
+		auditLogChainMap[cluster] = chain
+	}
+	return chain
+}
+
+func GetAuditLogChainKey() []byte {
+	return []byte(beego.AppConfig.String("auditLogChainKey"))
+}
+
+func getAuditLogChainHeadKeyPrefix(cluster string) string {
+	return auditLogChainHeadKeyPrefix + cluster + "/"
+}
+
+// recordAuditLogChainHead keeps the head in the session store which never expires so the deletion in cloudone_analysis is detected
+func recordAuditLogChainHead(cluster string, chainHead auditchain.ChainHead) {
+	byteSlice, err := json.Marshal(chainHead)
+	if err == nil {
+		err = sessionstore.GetStore().Set(getAuditLogChainHeadKeyPrefix(cluster)+chainHead.ChainID, byteSlice, 0)
+	}
+	if err != nil {
+		beego.Error("Fail to record the audit log chain head", chainHead.ChainID, chainHead.LastSequence, err)
+	}
+}
+
+// GetAuditLogChainHeadSlice returns the heads of all chains of the GUI replicas signing the audit logs of the cluster
+func GetAuditLogChainHeadSlice(cluster string) ([]auditchain.ChainHead, error) {
+	store := sessionstore.GetStore()
+	keySlice, err := store.List(getAuditLogChainHeadKeyPrefix(cluster))
+	if err != nil {
+		return nil, err
+	}
+
+	chainHeadSlice := make([]auditchain.ChainHead, 0)
+	for _, key := range keySlice {
+		byteSlice, err := store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		if byteSlice == nil {
+			continue
+		}
+		chainHead := auditchain.ChainHead{}
+		if err := json.Unmarshal(byteSlice, &chainHead); err != nil {
+			return nil, err
+		}
+		chainHeadSlice = append(chainHeadSlice, chainHead)
+	}
+	return chainHeadSlice, nil
+}
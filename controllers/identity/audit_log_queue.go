@@ -0,0 +1,451 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_utility/audit"
+	"github.com/cloudawan/cloudone_utility/restclient"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultAuditLogQueueSize                    = 10000
+	defaultAuditLogBatchSize                    = 100
+	defaultAuditLogSpoolEntryMaximum            = 100000
+	defaultAuditLogRetryMaximumIntervalInSecond = 60
+	auditLogRetryInitialInterval                = time.Second
+	auditLogFlushInterval                       = time.Second
+	auditLogSpoolFileExtension                  = ".json"
+)
+
+// queuedAuditLog keeps the token and the cluster of the request since cloudone_analysis of the cluster requires it.
+// The token is kept only in memory so the spooled audit logs are replayed with the service account.
+type queuedAuditLog struct {
+	AuditLog       *audit.AuditLog
+	TokenHeaderMap map[string]string `json:"-"`
+	Cluster        string
+}
+
+var errAuditLogWithoutToken = errors.New("The service account identityServiceAccountUsername is required to replay the spooled audit logs")
+
+// AuditLogStatistic counts the audit logs since the GUI starts except Queued and SpoolEntry which are the current amount
+type AuditLogStatistic struct {
+	Queued     int
+	SpoolEntry int
+	Delivered  int64
+	Spooled    int64
+	Retried    int64
+	Dropped    int64
+}
+
+// auditLogQueue delivers the audit logs in the background. The batch failing to deliver is spooled to the disk
+// and replayed with backoff so the audit logs survive the outage of cloudone_analysis and the restart of the GUI.
+// The audit logs overflowing the channel are buffered in memory and spooled in batches by the same goroutine
+// so the spool files are only written and removed by it.
+type auditLogQueue struct {
+	channel              chan *queuedAuditLog
+	batchSize            int
+	spoolDirectory       string
+	spoolEntryMaximum    int
+	retryMaximumInterval time.Duration
+	overflowSlice        []*queuedAuditLog
+	overflowMaximum      int
+	overflowLock         sync.Mutex
+	spoolEntry           int64
+	lastSpoolFileTime    int64
+	delivered            int64
+	spooled              int64
+	retried              int64
+	dropped              int64
+	stopChannel          chan struct{}
+	stoppedChannel       chan struct{}
+}
+
+var auditLogQueueOnce sync.Once
+var defaultAuditLogQueue *auditLogQueue
+
+func getAuditLogQueue() *auditLogQueue {
+	auditLogQueueOnce.Do(func() {
+		queueSize := beego.AppConfig.DefaultInt("auditLogQueueSize", defaultAuditLogQueueSize)
+		batchSize := beego.AppConfig.DefaultInt("auditLogBatchSize", defaultAuditLogBatchSize)
+		if batchSize <= 0 {
+			batchSize = defaultAuditLogBatchSize
+		}
+
+		defaultAuditLogQueue = &auditLogQueue{
+			channel:              make(chan *queuedAuditLog, queueSize),
+			batchSize:            batchSize,
+			spoolDirectory:       beego.AppConfig.String("auditLogSpoolDirectory"),
+			spoolEntryMaximum:    beego.AppConfig.DefaultInt("auditLogSpoolEntryMaximum", defaultAuditLogSpoolEntryMaximum),
+			retryMaximumInterval: time.Duration(beego.AppConfig.DefaultInt("auditLogRetryMaximumIntervalInSecond", defaultAuditLogRetryMaximumIntervalInSecond)) * time.Second,
+			overflowSlice:        make([]*queuedAuditLog, 0),
+			overflowMaximum:      queueSize,
+			stopChannel:          make(chan struct{}),
+			stoppedChannel:       make(chan struct{}),
+		}
+
+		if defaultAuditLogQueue.spoolDirectory != "" {
+			err := os.MkdirAll(defaultAuditLogQueue.spoolDirectory, 0700)
+			if err != nil {
+				beego.Error("Fail to create the audit log spool directory", defaultAuditLogQueue.spoolDirectory, err)
+				defaultAuditLogQueue.spoolDirectory = ""
+			}
+		}
+		if defaultAuditLogQueue.spoolDirectory != "" {
+			defaultAuditLogQueue.countSpoolEntry()
+			if beego.AppConfig.String("identityServiceAccountUsername") == "" {
+				beego.Warning("The spooled audit logs are not replayed without the service account identityServiceAccountUsername")
+			}
+		}
+
+		go defaultAuditLogQueue.run()
+	})
+	return defaultAuditLogQueue
+}
+
+// enqueueAuditLog never blocks the request
+func enqueueAuditLog(auditLog *audit.AuditLog, tokenHeaderMap map[string]string, cluster string) {
+	getAuditLogQueue().enqueue(&queuedAuditLog{auditLog, tokenHeaderMap, cluster})
+}
+
+// enqueue buffers the audit log in memory when the channel is full. It is dropped only when the buffer is full too.
+func (queue *auditLogQueue) enqueue(entry *queuedAuditLog) {
+	select {
+	case queue.channel <- entry:
+	default:
+		queue.overflowLock.Lock()
+		defer queue.overflowLock.Unlock()
+		if len(queue.overflowSlice) < queue.overflowMaximum {
+			queue.overflowSlice = append(queue.overflowSlice, entry)
+		} else {
+			atomic.AddInt64(&queue.dropped, 1)
+		}
+	}
+}
+
+func (queue *auditLogQueue) getOverflowAmount() int {
+	queue.overflowLock.Lock()
+	defer queue.overflowLock.Unlock()
+	return len(queue.overflowSlice)
+}
+
+// flushOverflow spools the audit logs overflowing the channel in batches
+func (queue *auditLogQueue) flushOverflow() {
+	queue.overflowLock.Lock()
+	overflowSlice := queue.overflowSlice
+	queue.overflowSlice = make([]*queuedAuditLog, 0)
+	queue.overflowLock.Unlock()
+
+	for len(overflowSlice) > 0 {
+		size := queue.batchSize
+		if size > len(overflowSlice) {
+			size = len(overflowSlice)
+		}
+		queue.spool(overflowSlice[:size])
+		overflowSlice = overflowSlice[size:]
+	}
+}
+
+// GetAuditLogStatistic is used to monitor the delivery of the audit logs
+func GetAuditLogStatistic() AuditLogStatistic {
+	queue := getAuditLogQueue()
+	return AuditLogStatistic{
+		len(queue.channel) + queue.getOverflowAmount(),
+		int(atomic.LoadInt64(&queue.spoolEntry)),
+		atomic.LoadInt64(&queue.delivered),
+		atomic.LoadInt64(&queue.spooled),
+		atomic.LoadInt64(&queue.retried),
+		atomic.LoadInt64(&queue.dropped),
+	}
+}
+
+// StopAuditLogQueue spools the audit logs still in memory so they are delivered after the GUI starts again
+func StopAuditLogQueue() {
+	queue := getAuditLogQueue()
+	close(queue.stopChannel)
+	<-queue.stoppedChannel
+}
+
+func (queue *auditLogQueue) run() {
+	defer close(queue.stoppedChannel)
+
+	ticker := time.NewTicker(auditLogFlushInterval)
+	defer ticker.Stop()
+
+	retryInterval := time.Duration(0)
+	retryTime := time.Time{}
+	backoff := func(failed bool) {
+		if failed {
+			if retryInterval == 0 {
+				retryInterval = auditLogRetryInitialInterval
+			} else if retryInterval*2 <= queue.retryMaximumInterval {
+				retryInterval *= 2
+			} else {
+				retryInterval = queue.retryMaximumInterval
+			}
+			retryTime = time.Now().Add(retryInterval)
+		} else {
+			retryInterval = 0
+		}
+	}
+
+	batch := make([]*queuedAuditLog, 0, queue.batchSize)
+	for {
+		replay := false
+		select {
+		case entry := <-queue.channel:
+			batch = append(batch, entry)
+			if len(batch) < queue.batchSize {
+				continue
+			}
+		case <-ticker.C:
+			replay = true
+		case <-queue.stopChannel:
+			for {
+				select {
+				case entry := <-queue.channel:
+					batch = append(batch, entry)
+				default:
+					queue.spool(batch)
+					queue.flushOverflow()
+					return
+				}
+			}
+		}
+
+		if len(batch) > 0 {
+			if time.Now().Before(retryTime) {
+				// Don't wait for cloudone_analysis in memory while backing off
+				queue.spool(batch)
+			} else {
+				remainingBatch := queue.deliver(batch)
+				if len(remainingBatch) > 0 {
+					queue.spool(remainingBatch)
+				}
+				backoff(len(remainingBatch) > 0)
+			}
+			batch = make([]*queuedAuditLog, 0, queue.batchSize)
+		}
+
+		if replay {
+			queue.flushOverflow()
+		}
+
+		if replay && time.Now().After(retryTime) {
+			backoff(queue.replay() == false)
+		}
+	}
+}
+
+// deliver returns the audit logs not delivered since cloudone_analysis is unavailable
+func (queue *auditLogQueue) deliver(batch []*queuedAuditLog) []*queuedAuditLog {
+	for i, entry := range batch {
+		err := queue.send(entry)
+		if err != nil {
+			return batch[i:]
+		}
+	}
+	return nil
+}
+
+// send only returns the error worth retrying
+func (queue *auditLogQueue) send(entry *queuedAuditLog) error {
+	cluster := entry.Cluster
+	if cluster == "" {
+		// Spooled before the cluster profiles are configured
+		cluster = backend.GetDefaultClusterName()
+	}
+
+	err := errAuditLogWithoutToken
+	if entry.TokenHeaderMap != nil {
+		err = backend.NewCloudoneAnalysisClientWithTokenHeaderMap(entry.TokenHeaderMap).WithCluster(cluster).CreateAuditLog(entry.AuditLog)
+	}
+	if (entry.TokenHeaderMap == nil || IsTokenInvalid(err)) && beego.AppConfig.String("identityServiceAccountUsername") != "" {
+		// The spooled audit log has no token and the token of the user may expire while the audit log waits
+		tokenHeaderMap, serviceAccountError := getServiceAccountTokenHeaderMap(cluster)
+		if serviceAccountError != nil {
+			return serviceAccountError
+		}
+		err = backend.NewCloudoneAnalysisClientWithTokenHeaderMap(tokenHeaderMap).WithCluster(cluster).CreateAuditLog(entry.AuditLog)
+		if IsTokenInvalid(err) {
+			removeServiceAccountToken(cluster)
+		}
+	}
+
+	if err == nil {
+		atomic.AddInt64(&queue.delivered, 1)
+		return nil
+	}
+
+	if isAuditLogRejected(err) {
+		// Rejected by cloudone_analysis so retrying won't help
+		atomic.AddInt64(&queue.dropped, 1)
+		beego.Error("Audit log is rejected by cloudone_analysis", entry.AuditLog.UserName, entry.AuditLog.RequestURI, err)
+		return nil
+	}
+
+	return err
+}
+
+// isAuditLogRejected is true only for the client error which fails again when retried.
+// The server error, request timeout and too many requests are retried with backoff.
+func isAuditLogRejected(err error) bool {
+	requestError, ok := err.(restclient.RequestError)
+	if ok == false || requestError.ResponseData == nil {
+		return false
+	}
+	switch {
+	case requestError.StatusCode == http.StatusRequestTimeout:
+		return false
+	case requestError.StatusCode == http.StatusTooManyRequests:
+		return false
+	case requestError.StatusCode >= 400 && requestError.StatusCode < 500:
+		return true
+	default:
+		return false
+	}
+}
+
+func (queue *auditLogQueue) getSpoolFileNameSlice() []string {
+	if queue.spoolDirectory == "" {
+		return nil
+	}
+
+	fileInfoSlice, err := ioutil.ReadDir(queue.spoolDirectory)
+	if err != nil {
+		beego.Error("Fail to read the audit log spool directory", queue.spoolDirectory, err)
+		return nil
+	}
+
+	fileNameSlice := make([]string, 0)
+	for _, fileInfo := range fileInfoSlice {
+		if fileInfo.IsDir() == false && strings.HasSuffix(fileInfo.Name(), auditLogSpoolFileExtension) {
+			fileNameSlice = append(fileNameSlice, fileInfo.Name())
+		}
+	}
+	// The file name is the created time so the older is replayed first
+	sort.Strings(fileNameSlice)
+	return fileNameSlice
+}
+
+// spool writes the batch to a new file. The oldest files are dropped when the amount of the spooled audit logs
+// exceeds the maximum.
+func (queue *auditLogQueue) spool(batch []*queuedAuditLog) {
+	if len(batch) == 0 {
+		return
+	}
+	if queue.spoolDirectory == "" {
+		atomic.AddInt64(&queue.dropped, int64(len(batch)))
+		return
+	}
+
+	byteSlice, err := json.Marshal(batch)
+	if err == nil {
+		// The file name is unique even if the batches are spooled in the same tick of the clock
+		spoolFileTime := time.Now().UnixNano()
+		if spoolFileTime <= queue.lastSpoolFileTime {
+			spoolFileTime = queue.lastSpoolFileTime + 1
+		}
+		queue.lastSpoolFileTime = spoolFileTime
+		fileName := fmt.Sprintf("%020d%s", spoolFileTime, auditLogSpoolFileExtension)
+		err = ioutil.WriteFile(filepath.Join(queue.spoolDirectory, fileName), byteSlice, 0600)
+	}
+	if err != nil {
+		beego.Error("Fail to spool the audit logs", err)
+		atomic.AddInt64(&queue.dropped, int64(len(batch)))
+		return
+	}
+	atomic.AddInt64(&queue.spooled, int64(len(batch)))
+	atomic.AddInt64(&queue.spoolEntry, int64(len(batch)))
+
+	fileNameSlice := queue.getSpoolFileNameSlice()
+	for i := 0; i < len(fileNameSlice)-1 && atomic.LoadInt64(&queue.spoolEntry) > int64(queue.spoolEntryMaximum); i++ {
+		droppedBatch, _ := queue.readSpoolFile(fileNameSlice[i])
+		os.Remove(filepath.Join(queue.spoolDirectory, fileNameSlice[i]))
+		atomic.AddInt64(&queue.dropped, int64(len(droppedBatch)))
+		atomic.AddInt64(&queue.spoolEntry, -int64(len(droppedBatch)))
+	}
+}
+
+// countSpoolEntry counts the audit logs spooled before the GUI starts
+func (queue *auditLogQueue) countSpoolEntry() {
+	amount := 0
+	for _, fileName := range queue.getSpoolFileNameSlice() {
+		batch, err := queue.readSpoolFile(fileName)
+		if err != nil {
+			beego.Error("Fail to read the audit log spool file", fileName, err)
+			continue
+		}
+		amount += len(batch)
+	}
+	atomic.StoreInt64(&queue.spoolEntry, int64(amount))
+}
+
+func (queue *auditLogQueue) readSpoolFile(fileName string) ([]*queuedAuditLog, error) {
+	byteSlice, err := ioutil.ReadFile(filepath.Join(queue.spoolDirectory, fileName))
+	if err != nil {
+		return nil, err
+	}
+	batch := make([]*queuedAuditLog, 0)
+	err = json.Unmarshal(byteSlice, &batch)
+	if err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+// replay delivers the spooled files from the oldest and returns false when cloudone_analysis is still unavailable
+func (queue *auditLogQueue) replay() bool {
+	for _, fileName := range queue.getSpoolFileNameSlice() {
+		batch, err := queue.readSpoolFile(fileName)
+		if err != nil {
+			beego.Error("Fail to read the audit log spool file", fileName, err)
+			os.Remove(filepath.Join(queue.spoolDirectory, fileName))
+			continue
+		}
+
+		atomic.AddInt64(&queue.retried, int64(len(batch)))
+		remainingBatch := queue.deliver(batch)
+		atomic.AddInt64(&queue.spoolEntry, -int64(len(batch)-len(remainingBatch)))
+
+		if len(remainingBatch) > 0 {
+			// Keep the file name so the order is kept
+			byteSlice, err := json.Marshal(remainingBatch)
+			if err == nil {
+				err = ioutil.WriteFile(filepath.Join(queue.spoolDirectory, fileName), byteSlice, 0600)
+			}
+			if err != nil {
+				beego.Error("Fail to rewrite the audit log spool file", fileName, err)
+			}
+			return false
+		}
+
+		os.Remove(filepath.Join(queue.spoolDirectory, fileName))
+	}
+	return true
+}
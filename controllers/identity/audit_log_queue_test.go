@@ -0,0 +1,137 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"errors"
+	"github.com/cloudawan/cloudone_utility/audit"
+	"github.com/cloudawan/cloudone_utility/restclient"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIsAuditLogRejected(t *testing.T) {
+	Convey("Subject: Only the audit log rejected by the client error is dropped\n", t, func() {
+		testCaseSlice := []struct {
+			err      error
+			rejected bool
+		}{
+			{restclient.RequestError{StatusCode: 400, ResponseData: "invalid"}, true},
+			{restclient.RequestError{StatusCode: 403, ResponseData: "forbidden"}, true},
+			{restclient.RequestError{StatusCode: 404, ResponseData: "not found"}, true},
+			{restclient.RequestError{StatusCode: 408, ResponseData: "timeout"}, false},
+			{restclient.RequestError{StatusCode: 429, ResponseData: "too many requests"}, false},
+			{restclient.RequestError{StatusCode: 500, ResponseData: "internal error"}, false},
+			{restclient.RequestError{StatusCode: 502, ResponseData: "bad gateway"}, false},
+			{restclient.RequestError{StatusCode: 503, ResponseData: "unavailable"}, false},
+			{restclient.RequestError{StatusCode: 400, ResponseData: nil}, false},
+			{errors.New("connection refused"), false},
+		}
+		for _, testCase := range testCaseSlice {
+			So(isAuditLogRejected(testCase.err), ShouldEqual, testCase.rejected)
+		}
+	})
+}
+
+func newTestAuditLogQueue(channelSize int, spoolEntryMaximum int) (*auditLogQueue, string) {
+	spoolDirectory, err := ioutil.TempDir("", "auditlog")
+	So(err, ShouldBeNil)
+	return &auditLogQueue{
+		channel:           make(chan *queuedAuditLog, channelSize),
+		batchSize:         2,
+		spoolDirectory:    spoolDirectory,
+		spoolEntryMaximum: spoolEntryMaximum,
+		overflowSlice:     make([]*queuedAuditLog, 0),
+		overflowMaximum:   3,
+	}, spoolDirectory
+}
+
+func newTestQueuedAuditLog(userName string) *queuedAuditLog {
+	return &queuedAuditLog{&audit.AuditLog{UserName: userName}, map[string]string{"token": "token-of-" + userName}, "default"}
+}
+
+func TestAuditLogQueueSpool(t *testing.T) {
+	Convey("Subject: The audit logs overflowing the queue are spooled in batches without the token\n", t, func() {
+		queue, spoolDirectory := newTestAuditLogQueue(1, 100)
+		defer os.RemoveAll(spoolDirectory)
+
+		for _, userName := range []string{"queued", "overflow1", "overflow2", "overflow3", "dropped"} {
+			queue.enqueue(newTestQueuedAuditLog(userName))
+		}
+		So(len(queue.channel), ShouldEqual, 1)
+		So(queue.getOverflowAmount(), ShouldEqual, 3)
+		So(queue.dropped, ShouldEqual, 1)
+		So(queue.getSpoolFileNameSlice(), ShouldBeEmpty)
+
+		queue.flushOverflow()
+		So(queue.getOverflowAmount(), ShouldEqual, 0)
+		fileNameSlice := queue.getSpoolFileNameSlice()
+		So(fileNameSlice, ShouldHaveLength, 2)
+		So(queue.spoolEntry, ShouldEqual, 3)
+		So(queue.spooled, ShouldEqual, 3)
+
+		userNameSlice := make([]string, 0)
+		for _, fileName := range fileNameSlice {
+			byteSlice, err := ioutil.ReadFile(filepath.Join(spoolDirectory, fileName))
+			So(err, ShouldBeNil)
+			So(string(byteSlice), ShouldNotContainSubstring, "token-of-")
+
+			batch, err := queue.readSpoolFile(fileName)
+			So(err, ShouldBeNil)
+			for _, entry := range batch {
+				So(entry.TokenHeaderMap, ShouldBeNil)
+				So(entry.Cluster, ShouldEqual, "default")
+				userNameSlice = append(userNameSlice, entry.AuditLog.UserName)
+			}
+		}
+		So(userNameSlice, ShouldResemble, []string{"overflow1", "overflow2", "overflow3"})
+
+		Convey("The amount is counted again when the GUI starts", func() {
+			queue.spoolEntry = 0
+			queue.countSpoolEntry()
+			So(queue.spoolEntry, ShouldEqual, 3)
+		})
+	})
+
+	Convey("Subject: The oldest spooled audit logs are dropped beyond the maximum amount of the entries\n", t, func() {
+		queue, spoolDirectory := newTestAuditLogQueue(1, 5)
+		defer os.RemoveAll(spoolDirectory)
+
+		queue.spool([]*queuedAuditLog{newTestQueuedAuditLog("oldest1"), newTestQueuedAuditLog("oldest2")})
+		queue.spool([]*queuedAuditLog{newTestQueuedAuditLog("older1"), newTestQueuedAuditLog("older2")})
+		So(queue.spoolEntry, ShouldEqual, 4)
+		So(queue.dropped, ShouldEqual, 0)
+
+		queue.spool([]*queuedAuditLog{newTestQueuedAuditLog("newest1"), newTestQueuedAuditLog("newest2")})
+		So(queue.spoolEntry, ShouldEqual, 4)
+		So(queue.dropped, ShouldEqual, 2)
+
+		fileNameSlice := queue.getSpoolFileNameSlice()
+		So(fileNameSlice, ShouldHaveLength, 2)
+		batch, err := queue.readSpoolFile(fileNameSlice[0])
+		So(err, ShouldBeNil)
+		So(batch[0].AuditLog.UserName, ShouldEqual, "older1")
+
+		Convey("The spool is not written without the directory", func() {
+			queue.spoolDirectory = ""
+			queue.spool([]*queuedAuditLog{newTestQueuedAuditLog("dropped")})
+			So(queue.dropped, ShouldEqual, 3)
+		})
+	})
+}
@@ -0,0 +1,41 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticator
+
+// Identity is the user verified by the external identity provider
+type Identity struct {
+	Name       string
+	Email      string
+	GroupSlice []string
+}
+
+// Authenticator is the external identity provider used to login GUI instead of the user of cloudone
+type Authenticator interface {
+	GetName() string
+}
+
+// PasswordAuthenticator verifies the username and password posted by the login page such as LDAP
+type PasswordAuthenticator interface {
+	Authenticator
+	Authenticate(username string, password string) (*Identity, error)
+}
+
+// RedirectAuthenticator redirects the browser to the identity provider and verifies the returned code such as OIDC.
+// The nonce and code verifier are generated per login and kept in the browser session until the callback.
+type RedirectAuthenticator interface {
+	Authenticator
+	GetAuthorizationURL(state string, nonce string, codeVerifier string) (string, error)
+	Exchange(code string, nonce string, codeVerifier string) (*Identity, error)
+}
@@ -0,0 +1,162 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticator
+
+import (
+	"crypto/tls"
+	"errors"
+	"github.com/go-ldap/ldap/v3"
+	"net"
+	"strings"
+	"time"
+)
+
+type LDAPConfig struct {
+	URL                string
+	InsecureSkipVerify bool
+	Timeout            time.Duration
+	// The account used to search the user. Anonymous search is used if it is empty.
+	BindDN       string
+	BindPassword string
+	UserBaseDN   string
+	// %s is replaced with the escaped username such as (uid=%s)
+	UserFilter string
+	// The attribute of the user entry listing its groups such as memberOf
+	GroupAttribute string
+	// If GroupBaseDN is set, groups are also searched with GroupFilter where %s is replaced with the user DN such as (member=%s)
+	GroupBaseDN        string
+	GroupFilter        string
+	GroupNameAttribute string
+	EmailAttribute     string
+}
+
+type LDAPAuthenticator struct {
+	config LDAPConfig
+}
+
+func NewLDAPAuthenticator(config LDAPConfig) *LDAPAuthenticator {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.UserFilter == "" {
+		config.UserFilter = "(uid=%s)"
+	}
+	if config.GroupAttribute == "" {
+		config.GroupAttribute = "memberOf"
+	}
+	if config.GroupFilter == "" {
+		config.GroupFilter = "(member=%s)"
+	}
+	if config.GroupNameAttribute == "" {
+		config.GroupNameAttribute = "cn"
+	}
+	if config.EmailAttribute == "" {
+		config.EmailAttribute = "mail"
+	}
+	return &LDAPAuthenticator{config}
+}
+
+func (ldapAuthenticator *LDAPAuthenticator) GetName() string {
+	return "ldap"
+}
+
+// Authenticate searches the user entry with the bind account and then binds as the user to verify the password
+func (ldapAuthenticator *LDAPAuthenticator) Authenticate(username string, password string) (*Identity, error) {
+	config := ldapAuthenticator.config
+
+	// The empty password is the unauthenticated bind which always succeeds in LDAP
+	if username == "" || password == "" {
+		return nil, errors.New("Username and password can't be empty")
+	}
+
+	connection, err := ldap.DialURL(config.URL,
+		ldap.DialWithDialer(&net.Dialer{Timeout: config.Timeout}),
+		ldap.DialWithTLSConfig(&tls.Config{InsecureSkipVerify: config.InsecureSkipVerify}))
+	if err != nil {
+		return nil, err
+	}
+	defer connection.Close()
+	connection.SetTimeout(config.Timeout)
+
+	if config.BindDN != "" {
+		err := connection.Bind(config.BindDN, config.BindPassword)
+		if err != nil {
+			return nil, errors.New("Fail to bind the search account with error " + err.Error())
+		}
+	}
+
+	userFilter := strings.Replace(config.UserFilter, "%s", ldap.EscapeFilter(username), -1)
+	userEntrySlice, err := ldapAuthenticator.search(connection, config.UserBaseDN, userFilter, []string{config.GroupAttribute, config.EmailAttribute})
+	if err != nil {
+		return nil, err
+	}
+	if len(userEntrySlice) != 1 {
+		return nil, errors.New("Invalid username or password")
+	}
+	userEntry := userEntrySlice[0]
+
+	err = connection.Bind(userEntry.DN, password)
+	if err != nil {
+		return nil, errors.New("Invalid username or password")
+	}
+
+	groupSlice := make([]string, 0)
+	for _, groupDN := range userEntry.GetEqualFoldAttributeValues(config.GroupAttribute) {
+		groupSlice = append(groupSlice, getGroupNameFromDN(groupDN))
+	}
+
+	if config.GroupBaseDN != "" {
+		groupFilter := strings.Replace(config.GroupFilter, "%s", ldap.EscapeFilter(userEntry.DN), -1)
+		groupEntrySlice, err := ldapAuthenticator.search(connection, config.GroupBaseDN, groupFilter, []string{config.GroupNameAttribute})
+		if err != nil {
+			return nil, err
+		}
+		for _, groupEntry := range groupEntrySlice {
+			groupSlice = append(groupSlice, groupEntry.GetEqualFoldAttributeValues(config.GroupNameAttribute)...)
+		}
+	}
+
+	email := userEntry.GetEqualFoldAttributeValue(config.EmailAttribute)
+
+	return &Identity{username, email, groupSlice}, nil
+}
+
+func (ldapAuthenticator *LDAPAuthenticator) search(connection *ldap.Conn, baseDN string, filter string, attributeSlice []string) ([]*ldap.Entry, error) {
+	searchRequest := ldap.NewSearchRequest(
+		baseDN,
+		ldap.ScopeWholeSubtree,
+		ldap.NeverDerefAliases,
+		0,
+		int(ldapAuthenticator.config.Timeout/time.Second),
+		false,
+		filter,
+		attributeSlice,
+		nil,
+	)
+	searchResult, err := connection.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+	return searchResult.Entries, nil
+}
+
+// getGroupNameFromDN returns the value of the first RDN such as admin in cn=admin,ou=groups,dc=example,dc=com
+func getGroupNameFromDN(dn string) string {
+	parsedDN, err := ldap.ParseDN(dn)
+	if err != nil || len(parsedDN.RDNs) == 0 || len(parsedDN.RDNs[0].Attributes) == 0 {
+		return dn
+	}
+	return parsedDN.RDNs[0].Attributes[0].Value
+}
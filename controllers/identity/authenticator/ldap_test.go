@@ -0,0 +1,268 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticator
+
+import (
+	"github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type ldapEntry struct {
+	DN           string
+	AttributeMap map[string][]string
+}
+
+// ldapStandIn is an in-process LDAP server supporting bind, search and unbind.
+// It decodes the messages sent by go-ldap with the same BER library.
+type ldapStandIn struct {
+	listener    net.Listener
+	passwordMap map[string]string
+	entrySlice  []ldapEntry
+}
+
+func newLDAPStandIn(passwordMap map[string]string, entrySlice []ldapEntry) *ldapStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		panic(err)
+	}
+	standIn := &ldapStandIn{listener, passwordMap, entrySlice}
+	go standIn.serve()
+	return standIn
+}
+
+func (standIn *ldapStandIn) url() string {
+	return "ldap://" + standIn.listener.Addr().String()
+}
+
+func (standIn *ldapStandIn) close() {
+	standIn.listener.Close()
+}
+
+func (standIn *ldapStandIn) serve() {
+	for {
+		conn, err := standIn.listener.Accept()
+		if err != nil {
+			return
+		}
+		go standIn.handle(conn)
+	}
+}
+
+func getBerString(packet *ber.Packet) string {
+	if packet.Data == nil {
+		return ""
+	}
+	return packet.Data.String()
+}
+
+func newLDAPResult(tag ber.Tag, resultCode int, message string) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "")
+	result.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, resultCode, ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", ""))
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, message, ""))
+	return result
+}
+
+func newLDAPSearchResultEntry(entry ldapEntry) *ber.Packet {
+	result := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "")
+	result.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, entry.DN, ""))
+	attributeSequence := ber.NewSequence("")
+	for name, valueSlice := range entry.AttributeMap {
+		attribute := ber.NewSequence("")
+		attribute.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, name, ""))
+		valueSet := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSet, nil, "")
+		for _, value := range valueSlice {
+			valueSet.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, value, ""))
+		}
+		attribute.AppendChild(valueSet)
+		attributeSequence.AppendChild(attribute)
+	}
+	result.AppendChild(attributeSequence)
+	return result
+}
+
+func (standIn *ldapStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+	for {
+		message, err := ber.ReadPacket(conn)
+		if err != nil || len(message.Children) < 2 {
+			return
+		}
+		messageID := message.Children[0].Value
+		operation := message.Children[1]
+		reply := func(response *ber.Packet) {
+			envelope := ber.NewSequence("")
+			envelope.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, messageID, ""))
+			envelope.AppendChild(response)
+			conn.Write(envelope.Bytes())
+		}
+
+		switch operation.Tag {
+		case ldap.ApplicationBindRequest:
+			dn := getBerString(operation.Children[1])
+			password := getBerString(operation.Children[2])
+			expectedPassword, ok := standIn.passwordMap[dn]
+			if ok && password != "" && password == expectedPassword {
+				reply(newLDAPResult(ldap.ApplicationBindResponse, ldap.LDAPResultSuccess, ""))
+			} else {
+				reply(newLDAPResult(ldap.ApplicationBindResponse, ldap.LDAPResultInvalidCredentials, "Invalid credentials"))
+			}
+		case ldap.ApplicationSearchRequest:
+			baseDN := strings.ToLower(getBerString(operation.Children[0]))
+			filter := operation.Children[6]
+			for _, entry := range standIn.entrySlice {
+				if strings.HasSuffix(strings.ToLower(entry.DN), baseDN) && matchLDAPFilter(filter, entry) {
+					reply(newLDAPSearchResultEntry(entry))
+				}
+			}
+			reply(newLDAPResult(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess, ""))
+		case ldap.ApplicationUnbindRequest:
+			return
+		}
+	}
+}
+
+func matchLDAPFilter(filter *ber.Packet, entry ldapEntry) bool {
+	switch filter.Tag {
+	case ldap.FilterAnd:
+		for _, child := range filter.Children {
+			if matchLDAPFilter(child, entry) == false {
+				return false
+			}
+		}
+		return true
+	case ldap.FilterOr:
+		for _, child := range filter.Children {
+			if matchLDAPFilter(child, entry) {
+				return true
+			}
+		}
+		return false
+	case ldap.FilterNot:
+		return matchLDAPFilter(filter.Children[0], entry) == false
+	case ldap.FilterPresent:
+		_, ok := entry.AttributeMap[strings.ToLower(getBerString(filter))]
+		return ok
+	case ldap.FilterEqualityMatch:
+		for _, value := range entry.AttributeMap[strings.ToLower(getBerString(filter.Children[0]))] {
+			if strings.EqualFold(value, getBerString(filter.Children[1])) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+func newTestLDAPStandIn() *ldapStandIn {
+	passwordMap := map[string]string{
+		"cn=search,dc=example,dc=com":           "searchPassword",
+		"uid=alice,ou=people,dc=example,dc=com": "alicePassword",
+		"uid=bob,ou=people,dc=example,dc=com":   "bobPassword",
+	}
+	entrySlice := []ldapEntry{
+		ldapEntry{"uid=alice,ou=people,dc=example,dc=com", map[string][]string{
+			"objectclass": []string{"person"},
+			"uid":         []string{"alice"},
+			"mail":        []string{"alice@example.com"},
+			"memberof":    []string{"cn=developers,ou=groups,dc=example,dc=com"},
+		}},
+		ldapEntry{"uid=bob,ou=people,dc=example,dc=com", map[string][]string{
+			"objectclass": []string{"person"},
+			"uid":         []string{"bob"},
+		}},
+		ldapEntry{"cn=operators,ou=groups,dc=example,dc=com", map[string][]string{
+			"cn":     []string{"operators"},
+			"member": []string{"uid=alice,ou=people,dc=example,dc=com", "uid=bob,ou=people,dc=example,dc=com"},
+		}},
+	}
+	return newLDAPStandIn(passwordMap, entrySlice)
+}
+
+func TestLDAPAuthenticator(t *testing.T) {
+	standIn := newTestLDAPStandIn()
+	defer standIn.close()
+
+	ldapAuthenticator := NewLDAPAuthenticator(LDAPConfig{
+		URL:          standIn.url(),
+		Timeout:      5 * time.Second,
+		BindDN:       "cn=search,dc=example,dc=com",
+		BindPassword: "searchPassword",
+		UserBaseDN:   "ou=people,dc=example,dc=com",
+		UserFilter:   "(&(objectClass=person)(uid=%s))",
+		GroupBaseDN:  "ou=groups,dc=example,dc=com",
+	})
+
+	Convey("Subject: LDAP authenticator\n", t, func() {
+		Convey("The user with correct password gets the groups from memberOf and group search", func() {
+			identity, err := ldapAuthenticator.Authenticate("alice", "alicePassword")
+			So(err, ShouldBeNil)
+			So(identity.Name, ShouldEqual, "alice")
+			So(identity.Email, ShouldEqual, "alice@example.com")
+			So(identity.GroupSlice, ShouldResemble, []string{"developers", "operators"})
+		})
+		Convey("The wrong password is rejected", func() {
+			_, err := ldapAuthenticator.Authenticate("alice", "bobPassword")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The empty password is rejected without the unauthenticated bind", func() {
+			_, err := ldapAuthenticator.Authenticate("alice", "")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The unknown user is rejected", func() {
+			_, err := ldapAuthenticator.Authenticate("carol", "carolPassword")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The wildcard in username is escaped instead of matching all users", func() {
+			_, err := ldapAuthenticator.Authenticate("*", "alicePassword")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The wrong search account fails", func() {
+			wrongAuthenticator := NewLDAPAuthenticator(LDAPConfig{
+				URL:          standIn.url(),
+				BindDN:       "cn=search,dc=example,dc=com",
+				BindPassword: "wrong",
+				UserBaseDN:   "ou=people,dc=example,dc=com",
+			})
+			_, err := wrongAuthenticator.Authenticate("alice", "alicePassword")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestLDAPGroupName(t *testing.T) {
+	Convey("Subject: LDAP group name and filter escaping\n", t, func() {
+		testCaseSlice := []struct {
+			dn        string
+			groupName string
+		}{
+			{"cn=developers,ou=groups,dc=example,dc=com", "developers"},
+			{"CN=Domain Admins,OU=Groups,DC=example,DC=com", "Domain Admins"},
+			{"cn=a\\,b,ou=groups,dc=example,dc=com", "a,b"},
+			{"developers", "developers"},
+		}
+		for _, testCase := range testCaseSlice {
+			So(getGroupNameFromDN(testCase.dn), ShouldEqual, testCase.groupName)
+		}
+		So(ldap.EscapeFilter("*)(uid=*"), ShouldEqual, "\\2a\\29\\28uid=\\2a")
+	})
+}
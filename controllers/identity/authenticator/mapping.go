@@ -0,0 +1,105 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticator
+
+import (
+	"errors"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"strings"
+)
+
+// GroupMapping maps the group of the external identity provider to the role and the namespace of cloudone.
+// The text format is group1:value1,value2;group2:value3
+type GroupMapping map[string][]string
+
+func ParseGroupMapping(text string) (GroupMapping, error) {
+	groupMapping := make(GroupMapping)
+	for _, item := range strings.Split(text, ";") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		splitSlice := strings.SplitN(item, ":", 2)
+		group := strings.TrimSpace(splitSlice[0])
+		if len(splitSlice) != 2 || group == "" {
+			return nil, errors.New("Group mapping " + item + " is not in the format group:value1,value2")
+		}
+		for _, value := range strings.Split(splitSlice[1], ",") {
+			value = strings.TrimSpace(value)
+			if value != "" {
+				groupMapping[group] = append(groupMapping[group], value)
+			}
+		}
+	}
+	return groupMapping, nil
+}
+
+// GetValueSlice returns the distinct values mapped from the groups in order
+func (groupMapping GroupMapping) GetValueSlice(groupSlice []string) []string {
+	valueSlice := make([]string, 0)
+	existingMap := make(map[string]bool)
+	for _, group := range groupSlice {
+		for _, value := range groupMapping[group] {
+			if existingMap[value] == false {
+				existingMap[value] = true
+				valueSlice = append(valueSlice, value)
+			}
+		}
+	}
+	return valueSlice
+}
+
+// CreateUser creates the session user from the identity with the roles and namespaces mapped from its groups.
+// The user without any mapped role is rejected so that the identity provider can't grant access by default.
+func CreateUser(identity *Identity, componentName string, roleMapping GroupMapping, namespaceMapping GroupMapping, roleSlice []rbac.Role) (*rbac.User, error) {
+	roleMap := make(map[string]rbac.Role)
+	for _, role := range roleSlice {
+		roleMap[role.Name] = role
+	}
+
+	userRoleSlice := make([]*rbac.Role, 0)
+	for _, roleName := range roleMapping.GetValueSlice(identity.GroupSlice) {
+		role, ok := roleMap[roleName]
+		if ok == false {
+			return nil, errors.New("Role " + roleName + " mapped for user " + identity.Name + " doesn't exist")
+		}
+		userRoleSlice = append(userRoleSlice, &role)
+	}
+	if len(userRoleSlice) == 0 {
+		return nil, errors.New("User " + identity.Name + " has no role mapped from groups " + strings.Join(identity.GroupSlice, ","))
+	}
+
+	resourceSlice := make([]*rbac.Resource, 0)
+	for _, namespace := range namespaceMapping.GetValueSlice(identity.GroupSlice) {
+		resourceSlice = append(resourceSlice, &rbac.Resource{
+			Name:      namespace,
+			Component: componentName,
+			Path:      "/namespaces/" + namespace,
+		})
+	}
+
+	metaDataMap := make(map[string]string)
+	if identity.Email != "" {
+		metaDataMap["email"] = identity.Email
+	}
+
+	return &rbac.User{
+		Name:          identity.Name,
+		RoleSlice:     userRoleSlice,
+		ResourceSlice: resourceSlice,
+		Description:   "External user",
+		MetaDataMap:   metaDataMap,
+	}, nil
+}
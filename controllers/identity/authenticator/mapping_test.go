@@ -0,0 +1,70 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticator
+
+import (
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGroupMapping(t *testing.T) {
+	roleSlice := []rbac.Role{
+		rbac.Role{Name: "developer"},
+		rbac.Role{Name: "operator"},
+		rbac.Role{Name: "admin"},
+	}
+
+	Convey("Subject: Group mapping\n", t, func() {
+		Convey("The mapping text is parsed", func() {
+			groupMapping, err := ParseGroupMapping(" developers : developer ; operators:operator,developer;")
+			So(err, ShouldBeNil)
+			So(groupMapping["developers"], ShouldResemble, []string{"developer"})
+			So(groupMapping["operators"], ShouldResemble, []string{"operator", "developer"})
+		})
+		Convey("The invalid mapping text is rejected", func() {
+			_, err := ParseGroupMapping("developers")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The user gets the distinct roles and namespaces of its groups", func() {
+			roleMapping, _ := ParseGroupMapping("developers:developer;operators:operator,developer;admins:admin")
+			namespaceMapping, _ := ParseGroupMapping("developers:dev;operators:dev,prod")
+			identity := &Identity{"alice", "alice@example.com", []string{"developers", "operators", "others"}}
+
+			user, err := CreateUser(identity, "cloudone_gui", roleMapping, namespaceMapping, roleSlice)
+			So(err, ShouldBeNil)
+			So(user.Name, ShouldEqual, "alice")
+			So(user.MetaDataMap["email"], ShouldEqual, "alice@example.com")
+			So(len(user.RoleSlice), ShouldEqual, 2)
+			So(user.RoleSlice[0].Name, ShouldEqual, "developer")
+			So(user.RoleSlice[1].Name, ShouldEqual, "operator")
+			So(len(user.ResourceSlice), ShouldEqual, 2)
+			So(user.ResourceSlice[0].Path, ShouldEqual, "/namespaces/dev")
+			So(user.ResourceSlice[1].Path, ShouldEqual, "/namespaces/prod")
+			So(user.ResourceSlice[1].Component, ShouldEqual, "cloudone_gui")
+		})
+		Convey("The user without any mapped role is rejected", func() {
+			roleMapping, _ := ParseGroupMapping("admins:admin")
+			_, err := CreateUser(&Identity{"bob", "", []string{"developers"}}, "cloudone_gui", roleMapping, GroupMapping{}, roleSlice)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The role which doesn't exist in cloudone is rejected", func() {
+			roleMapping, _ := ParseGroupMapping("developers:missing")
+			_, err := CreateUser(&Identity{"bob", "", []string{"developers"}}, "cloudone_gui", roleMapping, GroupMapping{}, roleSlice)
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
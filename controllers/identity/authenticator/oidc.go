@@ -0,0 +1,313 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticator
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type OIDCConfig struct {
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// The GUI callback url registered in the identity provider
+	RedirectURL string
+	ScopeSlice  []string
+	Timeout     time.Duration
+	// The claim in userinfo used as the user name such as preferred_username
+	UserNameClaim string
+	GroupClaim    string
+}
+
+type oidcProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+type oidcTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	IDToken     string `json:"id_token"`
+}
+
+type oidcIDTokenClaim struct {
+	Issuer    string      `json:"iss"`
+	Subject   string      `json:"sub"`
+	Audience  interface{} `json:"aud"`
+	ExpiresAt int64       `json:"exp"`
+	Nonce     string      `json:"nonce"`
+}
+
+// OIDCAuthenticator uses the authorization code flow with PKCE.
+// The identity is read from the userinfo endpoint with the access token which is got from the token endpoint directly by this confidential client.
+// The ID token is got from the same TLS connection so its signature is not verified but its issuer, audience, expiry and nonce are.
+type OIDCAuthenticator struct {
+	config       OIDCConfig
+	httpClient   *http.Client
+	metadataLock sync.Mutex
+	metadata     *oidcProviderMetadata
+}
+
+func NewOIDCAuthenticator(config OIDCConfig) *OIDCAuthenticator {
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if len(config.ScopeSlice) == 0 {
+		config.ScopeSlice = []string{"openid", "profile", "email", "groups"}
+	}
+	if config.UserNameClaim == "" {
+		config.UserNameClaim = "preferred_username"
+	}
+	if config.GroupClaim == "" {
+		config.GroupClaim = "groups"
+	}
+	return &OIDCAuthenticator{
+		config:     config,
+		httpClient: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+func (oidcAuthenticator *OIDCAuthenticator) GetName() string {
+	return "oidc"
+}
+
+// getMetadata discovers the endpoints once and caches them
+func (oidcAuthenticator *OIDCAuthenticator) getMetadata() (*oidcProviderMetadata, error) {
+	oidcAuthenticator.metadataLock.Lock()
+	defer oidcAuthenticator.metadataLock.Unlock()
+
+	if oidcAuthenticator.metadata != nil {
+		return oidcAuthenticator.metadata, nil
+	}
+
+	issuerURL := strings.TrimSuffix(oidcAuthenticator.config.IssuerURL, "/")
+	metadata := &oidcProviderMetadata{}
+	err := oidcAuthenticator.getJson(issuerURL+"/.well-known/openid-configuration", "", metadata)
+	if err != nil {
+		return nil, errors.New("Fail to discover OIDC provider with error " + err.Error())
+	}
+	if strings.TrimSuffix(metadata.Issuer, "/") != issuerURL {
+		return nil, errors.New("OIDC issuer " + metadata.Issuer + " doesn't match the configured " + oidcAuthenticator.config.IssuerURL)
+	}
+	if metadata.AuthorizationEndpoint == "" || metadata.TokenEndpoint == "" || metadata.UserinfoEndpoint == "" {
+		return nil, errors.New("OIDC provider doesn't have authorization, token or userinfo endpoint")
+	}
+
+	oidcAuthenticator.metadata = metadata
+	return metadata, nil
+}
+
+// NewPKCECodeVerifier returns the RFC 7636 code verifier with 256 bits of randomness
+func NewPKCECodeVerifier() string {
+	byteSlice := make([]byte, 32)
+	io.ReadFull(rand.Reader, byteSlice)
+	return base64.RawURLEncoding.EncodeToString(byteSlice)
+}
+
+func getPKCECodeChallenge(codeVerifier string) string {
+	sum := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func (oidcAuthenticator *OIDCAuthenticator) GetAuthorizationURL(state string, nonce string, codeVerifier string) (string, error) {
+	metadata, err := oidcAuthenticator.getMetadata()
+	if err != nil {
+		return "", err
+	}
+
+	valueMap := url.Values{}
+	valueMap.Set("response_type", "code")
+	valueMap.Set("client_id", oidcAuthenticator.config.ClientID)
+	valueMap.Set("redirect_uri", oidcAuthenticator.config.RedirectURL)
+	valueMap.Set("scope", strings.Join(oidcAuthenticator.config.ScopeSlice, " "))
+	valueMap.Set("state", state)
+	valueMap.Set("nonce", nonce)
+	valueMap.Set("code_challenge", getPKCECodeChallenge(codeVerifier))
+	valueMap.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return metadata.AuthorizationEndpoint + separator + valueMap.Encode(), nil
+}
+
+// Exchange redeems the authorization code with the code verifier, verifies the nonce in the ID token and reads the identity from userinfo
+func (oidcAuthenticator *OIDCAuthenticator) Exchange(code string, nonce string, codeVerifier string) (*Identity, error) {
+	if code == "" {
+		return nil, errors.New("Authorization code can't be empty")
+	}
+	if nonce == "" || codeVerifier == "" {
+		return nil, errors.New("Nonce and code verifier can't be empty")
+	}
+
+	metadata, err := oidcAuthenticator.getMetadata()
+	if err != nil {
+		return nil, err
+	}
+
+	valueMap := url.Values{}
+	valueMap.Set("grant_type", "authorization_code")
+	valueMap.Set("code", code)
+	valueMap.Set("redirect_uri", oidcAuthenticator.config.RedirectURL)
+	valueMap.Set("code_verifier", codeVerifier)
+
+	request, err := http.NewRequest("POST", metadata.TokenEndpoint, strings.NewReader(valueMap.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(url.QueryEscape(oidcAuthenticator.config.ClientID), url.QueryEscape(oidcAuthenticator.config.ClientSecret))
+
+	tokenResponse := oidcTokenResponse{}
+	err = oidcAuthenticator.doJson(request, &tokenResponse)
+	if err != nil {
+		return nil, errors.New("Fail to exchange authorization code with error " + err.Error())
+	}
+	if tokenResponse.AccessToken == "" {
+		return nil, errors.New("OIDC provider returns no access token")
+	}
+
+	idTokenClaim, err := oidcAuthenticator.verifyIDToken(metadata, tokenResponse.IDToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	claimMap := make(map[string]interface{})
+	err = oidcAuthenticator.getJson(metadata.UserinfoEndpoint, tokenResponse.AccessToken, &claimMap)
+	if err != nil {
+		return nil, errors.New("Fail to get userinfo with error " + err.Error())
+	}
+
+	// The userinfo must be about the same subject as the ID token
+	subject, _ := claimMap["sub"].(string)
+	if subject != idTokenClaim.Subject {
+		return nil, errors.New("Userinfo subject " + subject + " doesn't match the ID token subject " + idTokenClaim.Subject)
+	}
+
+	name, _ := claimMap[oidcAuthenticator.config.UserNameClaim].(string)
+	if name == "" {
+		name, _ = claimMap["sub"].(string)
+	}
+	if name == "" {
+		return nil, errors.New("Userinfo has no claim " + oidcAuthenticator.config.UserNameClaim + " or sub")
+	}
+	email, _ := claimMap["email"].(string)
+
+	groupSlice := make([]string, 0)
+	switch groupClaim := claimMap[oidcAuthenticator.config.GroupClaim].(type) {
+	case []interface{}:
+		for _, group := range groupClaim {
+			text, ok := group.(string)
+			if ok {
+				groupSlice = append(groupSlice, text)
+			}
+		}
+	case string:
+		groupSlice = append(groupSlice, groupClaim)
+	}
+
+	return &Identity{name, email, groupSlice}, nil
+}
+
+func (oidcAuthenticator *OIDCAuthenticator) verifyIDToken(metadata *oidcProviderMetadata, idToken string, nonce string) (*oidcIDTokenClaim, error) {
+	if idToken == "" {
+		return nil, errors.New("OIDC provider returns no ID token")
+	}
+	partSlice := strings.Split(idToken, ".")
+	if len(partSlice) != 3 {
+		return nil, errors.New("ID token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(partSlice[1], "="))
+	if err != nil {
+		return nil, errors.New("Fail to decode ID token with error " + err.Error())
+	}
+	idTokenClaim := &oidcIDTokenClaim{}
+	err = json.Unmarshal(payload, idTokenClaim)
+	if err != nil {
+		return nil, errors.New("Fail to parse ID token with error " + err.Error())
+	}
+
+	if idTokenClaim.Issuer != metadata.Issuer {
+		return nil, errors.New("ID token issuer " + idTokenClaim.Issuer + " doesn't match " + metadata.Issuer)
+	}
+	audienceMatched := false
+	switch audience := idTokenClaim.Audience.(type) {
+	case string:
+		audienceMatched = audience == oidcAuthenticator.config.ClientID
+	case []interface{}:
+		for _, value := range audience {
+			if value == oidcAuthenticator.config.ClientID {
+				audienceMatched = true
+			}
+		}
+	}
+	if audienceMatched == false {
+		return nil, errors.New("ID token is not issued to client " + oidcAuthenticator.config.ClientID)
+	}
+	if time.Now().Unix() >= idTokenClaim.ExpiresAt {
+		return nil, errors.New("ID token is expired")
+	}
+	// The nonce binds the ID token to the authorization request of this browser session to prevent the replay
+	if idTokenClaim.Nonce != nonce {
+		return nil, errors.New("ID token nonce doesn't match the login request")
+	}
+
+	return idTokenClaim, nil
+}
+
+func (oidcAuthenticator *OIDCAuthenticator) getJson(url string, accessToken string, returnedStructure interface{}) error {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		request.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	return oidcAuthenticator.doJson(request, returnedStructure)
+}
+
+func (oidcAuthenticator *OIDCAuthenticator) doJson(request *http.Request, returnedStructure interface{}) error {
+	response, err := oidcAuthenticator.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	if response.StatusCode != http.StatusOK {
+		return errors.New("Status code " + strconv.Itoa(response.StatusCode) + " " + string(body))
+	}
+	return json.Unmarshal(body, returnedStructure)
+}
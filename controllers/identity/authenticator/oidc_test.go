@@ -0,0 +1,207 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package authenticator
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type oidcStandInGrant struct {
+	codeChallenge string
+	nonce         string
+}
+
+func newUnsignedIDToken(claimMap map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "none"})
+	payload, _ := json.Marshal(claimMap)
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + ".signature"
+}
+
+// newOIDCStandIn is an in-process OIDC provider issuing the code valid-code for the client gui.
+// The authorization endpoint returns the code directly instead of redirecting and binds it to the code challenge and nonce.
+func newOIDCStandIn() *httptest.Server {
+	var server *httptest.Server
+	lock := sync.Mutex{}
+	grantMap := make(map[string]oidcStandInGrant)
+	serveMux := http.NewServeMux()
+	serveMux.HandleFunc("/authorize", func(responseWriter http.ResponseWriter, request *http.Request) {
+		query := request.URL.Query()
+		if query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" || query.Get("nonce") == "" {
+			responseWriter.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		lock.Lock()
+		grantMap["valid-code"] = oidcStandInGrant{query.Get("code_challenge"), query.Get("nonce")}
+		lock.Unlock()
+		json.NewEncoder(responseWriter).Encode(map[string]string{"code": "valid-code", "state": query.Get("state")})
+	})
+	serveMux.HandleFunc("/.well-known/openid-configuration", func(responseWriter http.ResponseWriter, request *http.Request) {
+		json.NewEncoder(responseWriter).Encode(map[string]string{
+			"issuer":                 server.URL,
+			"authorization_endpoint": server.URL + "/authorize",
+			"token_endpoint":         server.URL + "/token",
+			"userinfo_endpoint":      server.URL + "/userinfo",
+		})
+	})
+	serveMux.HandleFunc("/token", func(responseWriter http.ResponseWriter, request *http.Request) {
+		clientID, clientSecret, ok := request.BasicAuth()
+		if ok == false || clientID != "gui" || clientSecret != "secret" {
+			responseWriter.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		lock.Lock()
+		grant, ok := grantMap[request.PostFormValue("code")]
+		lock.Unlock()
+		sum := sha256.Sum256([]byte(request.PostFormValue("code_verifier")))
+		if ok == false || request.PostFormValue("grant_type") != "authorization_code" ||
+			request.PostFormValue("redirect_uri") != "https://gui/callback" ||
+			base64.RawURLEncoding.EncodeToString(sum[:]) != grant.codeChallenge {
+			responseWriter.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(responseWriter).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(responseWriter).Encode(map[string]string{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token": newUnsignedIDToken(map[string]interface{}{
+				"iss":   server.URL,
+				"sub":   "1234",
+				"aud":   "gui",
+				"exp":   time.Now().Add(time.Minute).Unix(),
+				"nonce": grant.nonce,
+			}),
+		})
+	})
+	serveMux.HandleFunc("/userinfo", func(responseWriter http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("Authorization") != "Bearer access-token" {
+			responseWriter.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(responseWriter).Encode(map[string]interface{}{
+			"sub":                "1234",
+			"preferred_username": "alice",
+			"email":              "alice@example.com",
+			"groups":             []string{"developers", "operators"},
+		})
+	})
+	server = httptest.NewServer(serveMux)
+	return server
+}
+
+func TestOIDCAuthenticator(t *testing.T) {
+	server := newOIDCStandIn()
+	defer server.Close()
+
+	oidcAuthenticator := NewOIDCAuthenticator(OIDCConfig{
+		IssuerURL:    server.URL,
+		ClientID:     "gui",
+		ClientSecret: "secret",
+		RedirectURL:  "https://gui/callback",
+	})
+
+	// authorize visits the authorization url as the browser does and returns the issued code
+	authorize := func(nonce string, codeVerifier string) string {
+		authorizationURL, err := oidcAuthenticator.GetAuthorizationURL("state-1", nonce, codeVerifier)
+		So(err, ShouldBeNil)
+		response, err := http.Get(authorizationURL)
+		So(err, ShouldBeNil)
+		defer response.Body.Close()
+		So(response.StatusCode, ShouldEqual, http.StatusOK)
+		resultMap := make(map[string]string)
+		json.NewDecoder(response.Body).Decode(&resultMap)
+		return resultMap["code"]
+	}
+
+	Convey("Subject: OIDC authenticator\n", t, func() {
+		Convey("The authorization url is from the discovery with the state, nonce and S256 code challenge", func() {
+			codeVerifier := NewPKCECodeVerifier()
+			So(len(codeVerifier), ShouldEqual, 43)
+			authorizationURL, err := oidcAuthenticator.GetAuthorizationURL("state-1", "nonce-1", codeVerifier)
+			So(err, ShouldBeNil)
+			So(strings.HasPrefix(authorizationURL, server.URL+"/authorize?"), ShouldBeTrue)
+			parsedURL, _ := url.Parse(authorizationURL)
+			So(parsedURL.Query().Get("state"), ShouldEqual, "state-1")
+			So(parsedURL.Query().Get("nonce"), ShouldEqual, "nonce-1")
+			So(parsedURL.Query().Get("code_challenge"), ShouldEqual, getPKCECodeChallenge(codeVerifier))
+			So(parsedURL.Query().Get("code_challenge_method"), ShouldEqual, "S256")
+			So(parsedURL.Query().Get("client_id"), ShouldEqual, "gui")
+			So(parsedURL.Query().Get("response_type"), ShouldEqual, "code")
+			So(parsedURL.Query().Get("redirect_uri"), ShouldEqual, "https://gui/callback")
+		})
+		Convey("The RFC 7636 example verifier has the expected challenge", func() {
+			So(getPKCECodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"), ShouldEqual, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+		})
+		Convey("The valid code is exchanged for the identity with groups", func() {
+			codeVerifier := NewPKCECodeVerifier()
+			code := authorize("nonce-1", codeVerifier)
+			identity, err := oidcAuthenticator.Exchange(code, "nonce-1", codeVerifier)
+			So(err, ShouldBeNil)
+			So(identity.Name, ShouldEqual, "alice")
+			So(identity.Email, ShouldEqual, "alice@example.com")
+			So(identity.GroupSlice, ShouldResemble, []string{"developers", "operators"})
+		})
+		Convey("The invalid code is rejected", func() {
+			_, err := oidcAuthenticator.Exchange("invalid-code", "nonce-1", NewPKCECodeVerifier())
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The code redeemed with another code verifier is rejected", func() {
+			code := authorize("nonce-1", NewPKCECodeVerifier())
+			_, err := oidcAuthenticator.Exchange(code, "nonce-1", NewPKCECodeVerifier())
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The ID token with another nonce is rejected", func() {
+			codeVerifier := NewPKCECodeVerifier()
+			code := authorize("nonce-1", codeVerifier)
+			_, err := oidcAuthenticator.Exchange(code, "nonce-2", codeVerifier)
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldContainSubstring, "nonce")
+		})
+		Convey("The empty nonce or code verifier is rejected", func() {
+			_, err := oidcAuthenticator.Exchange("valid-code", "", "")
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The wrong client secret is rejected", func() {
+			wrongAuthenticator := NewOIDCAuthenticator(OIDCConfig{
+				IssuerURL:    server.URL,
+				ClientID:     "gui",
+				ClientSecret: "wrong",
+				RedirectURL:  "https://gui/callback",
+			})
+			codeVerifier := NewPKCECodeVerifier()
+			code := authorize("nonce-1", codeVerifier)
+			_, err := wrongAuthenticator.Exchange(code, "nonce-1", codeVerifier)
+			So(err, ShouldNotBeNil)
+		})
+		Convey("The provider with a different issuer is rejected", func() {
+			wrongAuthenticator := NewOIDCAuthenticator(OIDCConfig{
+				IssuerURL: server.URL + "/other",
+				ClientID:  "gui",
+			})
+			_, err := wrongAuthenticator.GetAuthorizationURL("state-1", "nonce-1", NewPKCECodeVerifier())
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"errors"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_utility/rbac"
+)
+
+const (
+	sessionNameClusterLoginMap = "clusterLoginMap"
+)
+
+// ClusterLogin is the login of the user to a cluster kept in the session so the user switches back without login again.
+// The token and the roles are issued by the cloudone of the cluster so every cluster needs its own login.
+type ClusterLogin struct {
+	IdentityUser     *rbac.User
+	NamespaceRoleMap map[string][]*rbac.Role
+	TokenHeaderMap   map[string]string
+	Namespace        string
+}
+
+func getClusterLoginMap(sessionUtility guimessagedisplay.SessionUtility) map[string]*ClusterLogin {
+	clusterLoginMap, ok := sessionUtility.GetSession(sessionNameClusterLoginMap).(map[string]*ClusterLogin)
+	if ok == false {
+		clusterLoginMap = make(map[string]*ClusterLogin)
+	}
+	return clusterLoginMap
+}
+
+func getSessionCluster(sessionUtility guimessagedisplay.SessionUtility) string {
+	cluster, _ := sessionUtility.GetSession(backend.SessionNameCluster).(string)
+	if cluster == "" {
+		return backend.GetDefaultClusterName()
+	}
+	return cluster
+}
+
+// saveClusterLogin keeps the login of the current cluster in the session
+func saveClusterLogin(sessionUtility guimessagedisplay.SessionUtility) {
+	identityUser, ok := sessionUtility.GetSession("identityUser").(*rbac.User)
+	if ok == false {
+		return
+	}
+	namespaceRoleMap, _ := sessionUtility.GetSession("namespaceRoleMap").(map[string][]*rbac.Role)
+	tokenHeaderMap, _ := sessionUtility.GetSession("tokenHeaderMap").(map[string]string)
+	namespace, _ := sessionUtility.GetSession("namespace").(string)
+
+	clusterLoginMap := getClusterLoginMap(sessionUtility)
+	clusterLoginMap[getSessionCluster(sessionUtility)] = &ClusterLogin{
+		identityUser,
+		namespaceRoleMap,
+		tokenHeaderMap,
+		namespace,
+	}
+	sessionUtility.SetSession(sessionNameClusterLoginMap, clusterLoginMap)
+}
+
+// prepareClusterLogin is called before the session is replaced by the new login.
+// The logins of the other clusters are kept only when the same user logins so one session never mixes the users.
+func prepareClusterLogin(sessionUtility guimessagedisplay.SessionUtility, userName string) {
+	identityUser, ok := sessionUtility.GetSession("identityUser").(*rbac.User)
+	if ok && identityUser.Name == userName {
+		saveClusterLogin(sessionUtility)
+	} else {
+		sessionUtility.DelSession(sessionNameClusterLoginMap)
+	}
+}
+
+// removeClusterLogin forgets the login of the current cluster such as when its token expires
+func removeClusterLogin(sessionUtility guimessagedisplay.SessionUtility) {
+	clusterLoginMap := getClusterLoginMap(sessionUtility)
+	delete(clusterLoginMap, getSessionCluster(sessionUtility))
+	sessionUtility.SetSession(sessionNameClusterLoginMap, clusterLoginMap)
+}
+
+// IsClusterLogined tells if the session could switch to the cluster without login
+func IsClusterLogined(sessionUtility guimessagedisplay.SessionUtility, cluster string) bool {
+	_, ok := getClusterLoginMap(sessionUtility)[cluster]
+	return ok
+}
+
+// GetLoginedClusterNameSlice returns the clusters the session could switch to in the order of the profiles
+func GetLoginedClusterNameSlice(sessionUtility guimessagedisplay.SessionUtility) []string {
+	clusterLoginMap := getClusterLoginMap(sessionUtility)
+	nameSlice := make([]string, 0)
+	for _, name := range backend.GetClusterNameSlice() {
+		if _, ok := clusterLoginMap[name]; ok {
+			nameSlice = append(nameSlice, name)
+		}
+	}
+	return nameSlice
+}
+
+// SwitchCluster restores the login of the cluster into the session so all backend calls afterward go to the cluster
+func SwitchCluster(sessionUtility guimessagedisplay.SessionUtility, cluster string) error {
+	if backend.IsClusterExisting(cluster) == false {
+		return errors.New("Cluster " + cluster + " doesn't exist")
+	}
+
+	saveClusterLogin(sessionUtility)
+
+	clusterLogin, ok := getClusterLoginMap(sessionUtility)[cluster]
+	if ok == false {
+		return errors.New("User is not logined to cluster " + cluster)
+	}
+
+	sessionUtility.SetSession(backend.SessionNameCluster, cluster)
+	sessionUtility.SetSession("identityUser", clusterLogin.IdentityUser)
+	sessionUtility.SetSession("namespaceRoleMap", clusterLogin.NamespaceRoleMap)
+	sessionUtility.SetSession("username", clusterLogin.IdentityUser.Name)
+	sessionUtility.SetSession("tokenHeaderMap", clusterLogin.TokenHeaderMap)
+
+	return SetNamespace(sessionUtility, clusterLogin.Namespace)
+}
@@ -0,0 +1,107 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"strings"
+)
+
+const (
+	csrfTokenSessionName = "csrfToken"
+	// CSRFTokenFormName is the form field of the CSRF token in the mutating form
+	CSRFTokenFormName = "_csrf"
+	// CSRFTokenHeaderName is the header of the CSRF token in the mutating ajax request
+	CSRFTokenHeaderName = "X-CSRF-Token"
+)
+
+func createRandomToken() (string, error) {
+	byteSlice := make([]byte, 32)
+	_, err := rand.Read(byteSlice)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(byteSlice), nil
+}
+
+// RenewCSRFToken replaces the CSRF token in session. It is called when the user logins.
+func RenewCSRFToken(ctx *context.Context) error {
+	token, err := createRandomToken()
+	if err != nil {
+		return err
+	}
+	ctx.Output.Session(csrfTokenSessionName, token)
+	ctx.Input.SetData("csrfToken", token)
+	return nil
+}
+
+func isSafeMethod(method string) bool {
+	return method == "GET" || method == "HEAD" || method == "OPTIONS"
+}
+
+// FilterCSRF rejects the mutating request without the CSRF token of the session.
+// The token is passed to every template as csrfToken. The login form is checked with the token of
+// the session started before login and the token is renewed when the user logins.
+func FilterCSRF(ctx *context.Context) {
+	// The personal access token is sent in the header by the automation instead of the cookie
+	if isPersonalAccessTokenRequest(ctx) {
+		return
+	}
+
+	token, _ := ctx.Input.Session(csrfTokenSessionName).(string)
+	if token == "" {
+		var err error
+		token, err = createRandomToken()
+		if err != nil {
+			ctx.Abort(500, "Fail to create CSRF token")
+			return
+		}
+		ctx.Output.Session(csrfTokenSessionName, token)
+	}
+	ctx.Input.SetData("csrfToken", token)
+
+	if isSafeMethod(ctx.Input.Method()) {
+		return
+	}
+
+	requestToken := ctx.Input.Header(CSRFTokenHeaderName)
+	if requestToken == "" {
+		requestToken = ctx.Request.FormValue(CSRFTokenFormName)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) == 1 {
+		return
+	}
+
+	if strings.HasPrefix(ctx.Input.URL(), "/guirestapi/") {
+		ctx.Output.SetStatus(403)
+		ctx.Output.JSON(guimessagedisplay.NewGUIErrorMessage("Invalid CSRF token").SetResource(ctx.Input.URL()).SetRequestID(tracing.GetRequestIDFromContext(ctx)), false, false)
+		return
+	}
+
+	if guiMessage := guimessagedisplay.GetGUIMessageFromContext(ctx); guiMessage != nil {
+		guiMessage.AddDanger("The form is expired or not submitted from this site. Please try again.")
+	}
+	referer := ctx.Input.Refer()
+	if referer == "" || strings.HasPrefix(referer, ctx.Input.Site()) == false {
+		referer = "/gui/dashboard/topology/"
+	}
+	ctx.Redirect(302, referer)
+}
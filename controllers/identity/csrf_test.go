@@ -0,0 +1,141 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/astaxie/beego/context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testCSRFToken = "token-of-the-session"
+
+// testSession keeps the values of the session in memory for the filters called without beego
+type testSession struct {
+	id       string
+	valueMap map[interface{}]interface{}
+}
+
+func newTestSession(id string) *testSession {
+	return &testSession{id, make(map[interface{}]interface{})}
+}
+
+func (session *testSession) Set(key, value interface{}) error {
+	session.valueMap[key] = value
+	return nil
+}
+
+func (session *testSession) Get(key interface{}) interface{} {
+	return session.valueMap[key]
+}
+
+func (session *testSession) Delete(key interface{}) error {
+	delete(session.valueMap, key)
+	return nil
+}
+
+func (session *testSession) SessionID() string {
+	return session.id
+}
+
+func (session *testSession) SessionRelease(w http.ResponseWriter) {
+}
+
+func (session *testSession) Flush() error {
+	session.valueMap = make(map[interface{}]interface{})
+	return nil
+}
+
+func newTestContext(method string, path string, form url.Values, headerMap map[string]string, session *testSession) (*context.Context, *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, "http://gui.example"+path, strings.NewReader(form.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	for key, value := range headerMap {
+		request.Header.Set(key, value)
+	}
+	recorder := httptest.NewRecorder()
+	ctx := context.NewContext()
+	ctx.Reset(recorder, request)
+	ctx.Input.CruSession = session
+	return ctx, recorder
+}
+
+func TestFilterCSRF(t *testing.T) {
+	Convey("Subject: The mutating request without the CSRF token of the session is rejected\n", t, func() {
+		testCaseSlice := []struct {
+			description  string
+			method       string
+			path         string
+			form         url.Values
+			headerMap    map[string]string
+			sessionToken string
+			status       int
+			location     string
+		}{
+			{"The GET without the token", "GET", "/gui/system/namespace/list", url.Values{}, nil, testCSRFToken, 200, ""},
+			{"The HEAD without the token", "HEAD", "/gui/system/namespace/list", url.Values{}, nil, testCSRFToken, 200, ""},
+			{"The form with the token", "POST", "/gui/system/namespace/edit", url.Values{CSRFTokenFormName: {testCSRFToken}}, nil, testCSRFToken, 200, ""},
+			{"The ajax request with the token in the header", "DELETE", "/guirestapi/v1/namespaces/demo", url.Values{}, map[string]string{CSRFTokenHeaderName: testCSRFToken}, testCSRFToken, 200, ""},
+			{"The login with the token of the session before login", "POST", loginPageURL, url.Values{CSRFTokenFormName: {testCSRFToken}}, nil, testCSRFToken, 200, ""},
+			{"The login without the token", "POST", loginPageURL, url.Values{}, nil, testCSRFToken, 302, "/gui/dashboard/topology/"},
+			{"The login without the session", "POST", loginPageURL, url.Values{CSRFTokenFormName: {testCSRFToken}}, nil, "", 302, "/gui/dashboard/topology/"},
+			{"The form without the token", "POST", "/gui/system/namespace/edit", url.Values{}, nil, testCSRFToken, 302, "/gui/dashboard/topology/"},
+			{"The form with the wrong token", "POST", "/gui/system/namespace/edit", url.Values{CSRFTokenFormName: {"wrong"}}, nil, testCSRFToken, 302, "/gui/dashboard/topology/"},
+			{"The form with the token prefix", "POST", "/gui/system/namespace/edit", url.Values{CSRFTokenFormName: {testCSRFToken[:5]}}, nil, testCSRFToken, 302, "/gui/dashboard/topology/"},
+			{"The form without the session token", "POST", "/gui/system/namespace/edit", url.Values{CSRFTokenFormName: {""}}, nil, "", 302, "/gui/dashboard/topology/"},
+			{"The form rejected back to the page of this site", "POST", "/gui/system/namespace/edit", url.Values{}, map[string]string{"Referer": "http://gui.example/gui/system/namespace/list"}, testCSRFToken, 302, "http://gui.example/gui/system/namespace/list"},
+			{"The form rejected from the other site", "POST", "/gui/system/namespace/edit", url.Values{}, map[string]string{"Referer": "http://attacker.example/"}, testCSRFToken, 302, "/gui/dashboard/topology/"},
+			{"The ajax request with the wrong token", "PUT", "/guirestapi/v1/namespaces/demo", url.Values{}, map[string]string{CSRFTokenHeaderName: "wrong"}, testCSRFToken, 403, ""},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				session := newTestSession("session")
+				if testCase.sessionToken != "" {
+					session.Set(csrfTokenSessionName, testCase.sessionToken)
+				}
+				ctx, recorder := newTestContext(testCase.method, testCase.path, testCase.form, testCase.headerMap, session)
+				FilterCSRF(ctx)
+
+				So(recorder.Code, ShouldEqual, testCase.status)
+				So(recorder.Header().Get("Location"), ShouldEqual, testCase.location)
+				So(ctx.ResponseWriter.Started, ShouldEqual, testCase.status != 200)
+				// The token is created for the session without it and passed to the template
+				So(session.Get(csrfTokenSessionName), ShouldNotBeEmpty)
+				So(ctx.Input.GetData("csrfToken"), ShouldEqual, session.Get(csrfTokenSessionName))
+			})
+		}
+
+		Convey("The personal access token request is not checked", func() {
+			ctx, recorder := newTestContext("POST", "/guirestapi/v1/namespaces", url.Values{}, nil, newTestSession("session"))
+			ctx.Input.SetData(personalAccessTokenDataName, true)
+			FilterCSRF(ctx)
+			So(recorder.Code, ShouldEqual, 200)
+			So(ctx.ResponseWriter.Started, ShouldBeFalse)
+		})
+
+		Convey("The token is renewed when the user logins", func() {
+			session := newTestSession("session")
+			session.Set(csrfTokenSessionName, testCSRFToken)
+			ctx, _ := newTestContext("POST", loginPageURL, url.Values{}, nil, session)
+			So(RenewCSRFToken(ctx), ShouldBeNil)
+			So(session.Get(csrfTokenSessionName), ShouldNotEqual, testCSRFToken)
+			So(len(session.Get(csrfTokenSessionName).(string)), ShouldEqual, 64)
+		})
+	})
+}
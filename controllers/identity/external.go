@@ -0,0 +1,249 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity/authenticator"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/random"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	identityProviderCloudone = "cloudone"
+	identityProviderLDAP     = "ldap"
+	identityProviderOIDC     = "oidc"
+)
+
+var authenticatorOnce sync.Once
+var passwordAuthenticator authenticator.PasswordAuthenticator
+var redirectAuthenticator authenticator.RedirectAuthenticator
+
+func createAuthenticator() {
+	timeout := time.Duration(beego.AppConfig.DefaultInt("identityProviderTimeoutInSecond", 10)) * time.Second
+
+	switch beego.AppConfig.DefaultString("identityProvider", identityProviderCloudone) {
+	case identityProviderLDAP:
+		passwordAuthenticator = authenticator.NewLDAPAuthenticator(authenticator.LDAPConfig{
+			URL:                beego.AppConfig.String("ldapURL"),
+			InsecureSkipVerify: beego.AppConfig.DefaultBool("ldapInsecureSkipVerify", false),
+			Timeout:            timeout,
+			BindDN:             beego.AppConfig.String("ldapBindDN"),
+			BindPassword:       beego.AppConfig.String("ldapBindPassword"),
+			UserBaseDN:         beego.AppConfig.String("ldapUserBaseDN"),
+			UserFilter:         beego.AppConfig.String("ldapUserFilter"),
+			GroupAttribute:     beego.AppConfig.String("ldapGroupAttribute"),
+			GroupBaseDN:        beego.AppConfig.String("ldapGroupBaseDN"),
+			GroupFilter:        beego.AppConfig.String("ldapGroupFilter"),
+			GroupNameAttribute: beego.AppConfig.String("ldapGroupNameAttribute"),
+		})
+	case identityProviderOIDC:
+		scopeSlice := make([]string, 0)
+		for _, scope := range strings.Split(beego.AppConfig.String("oidcScope"), " ") {
+			if scope != "" {
+				scopeSlice = append(scopeSlice, scope)
+			}
+		}
+		redirectAuthenticator = authenticator.NewOIDCAuthenticator(authenticator.OIDCConfig{
+			IssuerURL:     beego.AppConfig.String("oidcIssuerURL"),
+			ClientID:      beego.AppConfig.String("oidcClientID"),
+			ClientSecret:  beego.AppConfig.String("oidcClientSecret"),
+			RedirectURL:   beego.AppConfig.String("oidcRedirectURL"),
+			ScopeSlice:    scopeSlice,
+			Timeout:       timeout,
+			UserNameClaim: beego.AppConfig.String("oidcUserNameClaim"),
+			GroupClaim:    beego.AppConfig.String("oidcGroupClaim"),
+		})
+	}
+}
+
+func getPasswordAuthenticator() (authenticator.PasswordAuthenticator, error) {
+	authenticatorOnce.Do(createAuthenticator)
+	if passwordAuthenticator == nil {
+		return nil, errors.New("No password identity provider is configured")
+	}
+	return passwordAuthenticator, nil
+}
+
+func getRedirectAuthenticator() (authenticator.RedirectAuthenticator, error) {
+	authenticatorOnce.Do(createAuthenticator)
+	if redirectAuthenticator == nil {
+		return nil, errors.New("No redirect identity provider is configured")
+	}
+	return redirectAuthenticator, nil
+}
+
+func IsRedirectAuthenticatorEnabled() bool {
+	return beego.AppConfig.DefaultString("identityProvider", identityProviderCloudone) == identityProviderOIDC
+}
+
+// loginExternalUser logins the user verified by the external identity provider.
+// The backend is accessed with the token of the service account while the GUI authorizes with the roles mapped from the groups.
+func loginExternalUser(c *beego.Controller, cluster string, externalIdentity *authenticator.Identity) {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	roleMapping, err := authenticator.ParseGroupMapping(beego.AppConfig.String("identityGroupRoleMapping"))
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	namespaceMapping, err := authenticator.ParseGroupMapping(beego.AppConfig.String("identityGroupNamespaceMapping"))
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	headerMap, err := createServiceAccountTokenHeaderMap(cluster)
+	if err != nil {
+		guimessage.AddDanger("Fail to login the service account. " + guimessagedisplay.GetErrorMessage(err))
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	roleSlice, err := backend.NewCloudoneClientWithTokenHeaderMap(headerMap).WithCluster(cluster).GetRoleSlice()
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	user, err := authenticator.CreateUser(externalIdentity, componentName, roleMapping, namespaceMapping, roleSlice)
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	loginUser(c, cluster, user, headerMap)
+}
+
+type OIDCLoginController struct {
+	beego.Controller
+}
+
+func (c *OIDCLoginController) Get() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	redirectAuthenticator, err := getRedirectAuthenticator()
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	timeZoneOffset, err := c.GetInt("timeZoneOffset")
+	if err == nil {
+		c.SetSession("timeZoneOffset", timeZoneOffset)
+	}
+
+	cluster := c.GetString("cluster")
+	if cluster == "" {
+		cluster = backend.GetDefaultClusterName()
+	}
+	if backend.IsClusterExisting(cluster) == false {
+		guimessage.AddDanger("Cluster " + cluster + " doesn't exist")
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+	// The callback from the identity provider logins to the cluster selected here
+	c.SetSession("oidcCluster", cluster)
+
+	// State binds the callback to this browser session to prevent the login CSRF
+	state := random.UUID()
+	c.SetSession("oidcState", state)
+	// Nonce binds the returned ID token and the code verifier binds the code to this browser session
+	nonce := random.UUID()
+	c.SetSession("oidcNonce", nonce)
+	codeVerifier := authenticator.NewPKCECodeVerifier()
+	c.SetSession("oidcCodeVerifier", codeVerifier)
+
+	authorizationURL, err := redirectAuthenticator.GetAuthorizationURL(state, nonce, codeVerifier)
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	c.Ctx.Redirect(302, authorizationURL)
+}
+
+type OIDCCallbackController struct {
+	beego.Controller
+}
+
+func (c *OIDCCallbackController) Get() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	state, _ := c.GetSession("oidcState").(string)
+	c.DelSession("oidcState")
+	nonce, _ := c.GetSession("oidcNonce").(string)
+	c.DelSession("oidcNonce")
+	codeVerifier, _ := c.GetSession("oidcCodeVerifier").(string)
+	c.DelSession("oidcCodeVerifier")
+	cluster, _ := c.GetSession("oidcCluster").(string)
+	c.DelSession("oidcCluster")
+	if cluster == "" {
+		cluster = backend.GetDefaultClusterName()
+	}
+
+	errorMessage := c.GetString("error")
+	if errorMessage != "" {
+		guimessage.AddDanger("Identity provider returns error " + errorMessage + " " + c.GetString("error_description"))
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	if state == "" || c.GetString("state") != state {
+		guimessage.AddDanger("Invalid login state. Please login again.")
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	redirectAuthenticator, err := getRedirectAuthenticator()
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	externalIdentity, err := redirectAuthenticator.Exchange(c.GetString("code"), nonce, codeVerifier)
+	if err != nil {
+		guimessage.AddDanger("Fail to login with " + redirectAuthenticator.GetName() + ". " + err.Error())
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	loginExternalUser(&c.Controller, cluster, externalIdentity)
+}
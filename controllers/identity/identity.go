@@ -16,22 +16,28 @@ package identity
 
 import (
 	"fmt"
-	"github.com/astaxie/beego"
 	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
 	"github.com/cloudawan/cloudone_utility/audit"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"net/url"
+	"strings"
 )
 
 const (
-	loginPageURL  = "/gui/login"
-	logoutPageURL = "/gui/logout"
+	loginPageURL        = "/gui/login"
+	logoutPageURL       = "/gui/logout"
+	oidcLoginPageURL    = "/gui/login/oidc"
+	oidcCallbackPageURL = "/gui/login/oidc/callback"
 )
 
 func FilterUser(ctx *context.Context) {
 	if (ctx.Input.IsGet() || ctx.Input.IsPost()) && (ctx.Input.URL() == loginPageURL || ctx.Input.URL() == logoutPageURL) {
 		// Don't redirect itself to prevent the circle
+	} else if ctx.Input.IsGet() && (ctx.Input.URL() == oidcLoginPageURL || ctx.Input.URL() == oidcCallbackPageURL) {
+		// The login with the external identity provider
 	} else {
 		user, ok := ctx.Input.Session("user").(*rbac.User)
 
@@ -42,40 +48,44 @@ func FilterUser(ctx *context.Context) {
 			ctx.Redirect(302, loginPageURL)
 		} else {
 			// Authorize
-			if user.HasPermission(componentName, ctx.Input.Method(), ctx.Input.URL()) == false {
+			if IsAuthorized(user, ctx.Input.Method(), ctx.Input.URL()) == false {
 				if guiMessage := guimessagedisplay.GetGUIMessageFromContext(ctx); guiMessage != nil {
 					guiMessage.AddDanger("User is not authorized to this page. Please use another user with priviledge.")
 				}
 				ctx.Redirect(302, loginPageURL)
+			} else if namespace, _ := ctx.Input.Session("namespace").(string); namespace != "" && IsNamespacePermitted(user, namespace) == false {
+				// GUI doesn't place the namespace in url so the namespace in session is checked
+				if guiMessage := guimessagedisplay.GetGUIMessageFromContext(ctx); guiMessage != nil {
+					guiMessage.AddDanger("User is not authorized to the namespace " + namespace + ". Please login again.")
+				}
+				ctx.Redirect(302, loginPageURL)
 			}
 
-			// Resource check is in another place since GUI doesn't place the resource name in url
+			if isPersonalAccessTokenRequest(ctx) == false {
+				touchSession(ctx)
+			}
+
+			if span := tracing.GetSpan(ctx.Request.Context()); span != nil {
+				span.SetAttribute("enduser.id", user.Name)
+			}
 
 			// Audit log
-			go func() {
-				sendAuditLog(ctx, user.Name, true)
-			}()
+			sendAuditLog(ctx, user.Name, true)
 		}
 	}
 }
 
-func sendAuditLog(ctx *context.Context, userName string, saveParameter bool) (returnedError error) {
-	defer func() {
-		if err := recover(); err != nil {
-			returnedError = err.(error)
-		}
-	}()
-
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
+// sendAuditLog creates the audit log from the request and queues it to be delivered in the background
+func sendAuditLog(ctx *context.Context, userName string, saveParameter bool) {
 	tokenHeaderMap, tokenHeaderMapOK := ctx.Input.Session("tokenHeaderMap").(map[string]string)
 	requestURI := ctx.Input.URI()
 	method := ctx.Input.Method()
 	path := ctx.Input.URL()
 	remoteAddress := ctx.Request.RemoteAddr
-	queryParameterMap := ctx.Request.Form
+	queryParameterMap := removeSecretParameter(ctx.Request.Form)
+	if ctx.Request.URL.RawQuery != "" {
+		requestURI = path + "?" + url.Values(removeSecretParameter(ctx.Request.URL.Query())).Encode()
+	}
 
 	proxySlice := ctx.Input.Proxy()
 	if proxySlice != nil && len(proxySlice) > 0 {
@@ -89,17 +99,70 @@ func sendAuditLog(ctx *context.Context, userName string, saveParameter bool) (re
 		queryParameterMap = nil
 	}
 
-	// Header is not used since the header has no useful information for now
+	// Only the request id is kept from the header to connect the audit log with the request and the backend calls.
 	// Body is not used since the backend component will record again.
 	// Path is not used since the backend component will record again.
-	auditLog := audit.CreateAuditLog(componentName, path, userName, remoteAddress, queryParameterMap, nil, method, requestURI, "", nil)
+	var requestHeader map[string][]string
+	if requestID := tracing.GetRequestIDFromContext(ctx); requestID != "" {
+		requestHeader = map[string][]string{tracing.HeaderRequestID: []string{requestID}}
+	}
+	auditLog := audit.CreateAuditLog(componentName, path, userName, remoteAddress, queryParameterMap, nil, method, requestURI, "", requestHeader)
 
 	if tokenHeaderMapOK {
-		url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort + "/api/v1/auditlogs"
+		cluster := backend.GetClusterName(ctx)
+		// Signed only when it is sent so the missing sequence means the audit log is lost or deleted
+		if chain := getAuditLogChain(cluster); chain != nil {
+			chain.Sign(auditLog)
+		}
+		enqueueAuditLog(auditLog, tokenHeaderMap, cluster)
+	}
+}
+
+// secretParameterKeywordSlice lists the lower case keywords in the name of the form field never kept in the audit log
+var secretParameterKeywordSlice = []string{"password", "secret", "apikey", "privatekey"}
 
-		restclient.RequestPost(url, auditLog, tokenHeaderMap, false)
-		// err is logged in analysis so don't need to here
+// secretParameterNameSlice lists the lower case names of the form field never kept in the audit log
+var secretParameterNameSlice = []string{CSRFTokenFormName, "token", "accesstoken"}
+
+// removeSecretParameter returns a copy of the parameters without the secret fields such as the CSRF token and passwords
+func removeSecretParameter(parameterMap map[string][]string) map[string][]string {
+	if parameterMap == nil {
+		return nil
 	}
+	copiedParameterMap := make(map[string][]string)
+	for key, valueSlice := range parameterMap {
+		isSecret := false
+		lowerCaseKey := strings.ToLower(key)
+		for _, keyword := range secretParameterKeywordSlice {
+			if strings.Contains(lowerCaseKey, keyword) {
+				isSecret = true
+				break
+			}
+		}
+		for _, name := range secretParameterNameSlice {
+			if lowerCaseKey == name {
+				isSecret = true
+				break
+			}
+		}
+		if isSecret == false {
+			copiedParameterMap[key] = append([]string(nil), valueSlice...)
+		}
+	}
+	return copiedParameterMap
+}
 
-	return nil
+// SendAuditLogForUser records the change made in the background on behalf of the user such as a step of a workflow driven by a runner
+func SendAuditLogForUser(cluster string, userName string, tokenHeaderMap map[string]string, method string, path string, parameterMap map[string][]string) {
+	requestURI := path
+	if len(parameterMap) > 0 {
+		requestURI += "?" + url.Values(removeSecretParameter(parameterMap)).Encode()
+	}
+	// No remote address since no request is made
+	auditLog := audit.CreateAuditLog(componentName, path, userName, "", removeSecretParameter(parameterMap), nil, method, requestURI, "", nil)
+
+	if chain := getAuditLogChain(cluster); chain != nil {
+		chain.Sign(auditLog)
+	}
+	enqueueAuditLog(auditLog, tokenHeaderMap, cluster)
 }
@@ -0,0 +1,116 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/json"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"github.com/cloudawan/cloudone_utility/audit"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSendAuditLogRequestID(t *testing.T) {
+	auditLogChannel := make(chan audit.AuditLog, 10)
+	server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		auditLog := audit.AuditLog{}
+		if request.Method == "POST" && request.URL.Path == "/api/v1/auditlogs" && json.NewDecoder(request.Body).Decode(&auditLog) == nil {
+			auditLogChannel <- auditLog
+		}
+		responseWriter.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	beego.AppConfig.Set("cloudoneAnalysisProtocol", "http")
+	beego.AppConfig.Set("cloudoneAnalysisHost", host)
+	beego.AppConfig.Set("cloudoneAnalysisPort", port)
+
+	Convey("Subject: The audit log records the request id of the request\n", t, func() {
+		session := newTestSession("session")
+		session.Set("tokenHeaderMap", map[string]string{"token": "token-of-alice"})
+		ctx, _ := newTestContext("POST", "/gui/system/namespace/edit", url.Values{"name": {"demo"}}, map[string]string{tracing.HeaderRequestID: "request-1"}, session)
+		tracing.FilterRequestID(ctx)
+
+		sendAuditLog(ctx, "alice", true)
+
+		select {
+		case auditLog := <-auditLogChannel:
+			So(auditLog.UserName, ShouldEqual, "alice")
+			So(auditLog.RequestHeader, ShouldResemble, map[string][]string{tracing.HeaderRequestID: {"request-1"}})
+		case <-time.After(10 * time.Second):
+			So("The audit log is not delivered", ShouldBeEmpty)
+		}
+	})
+}
+
+func TestRemoveSecretParameter(t *testing.T) {
+	Convey("Subject: Secret parameters are removed from the audit log\n", t, func() {
+		testCaseSlice := []struct {
+			name string
+			kept bool
+		}{
+			{CSRFTokenFormName, false},
+			{"password", false},
+			{"passwordConfirm", false},
+			{"sshPassword", false},
+			{"apiKey", false},
+			{"apiSecret", false},
+			{"githubWebhookSecret", false},
+			{"token", false},
+			{"tokenName", true},
+			{"name", true},
+			{"replicaAmount", true},
+		}
+		for _, testCase := range testCaseSlice {
+			parameterMap := map[string][]string{testCase.name: []string{"value"}}
+			_, ok := removeSecretParameter(parameterMap)[testCase.name]
+			So(ok, ShouldEqual, testCase.kept)
+			// The form of the request is not changed
+			So(parameterMap[testCase.name], ShouldResemble, []string{"value"})
+		}
+		So(removeSecretParameter(nil), ShouldBeNil)
+	})
+}
+
+func TestIsSessionTouchSkipped(t *testing.T) {
+	Convey("Subject: The session is written at most once per interval\n", t, func() {
+		now := time.Now()
+		sessionTouchMap = map[string]sessionTouch{"session": sessionTouch{now, "10.0.0.1", "default"}}
+		testCaseSlice := []struct {
+			touch   sessionTouch
+			skipped bool
+		}{
+			{sessionTouch{now.Add(time.Second), "10.0.0.1", "default"}, true},
+			{sessionTouch{now.Add(activeSessionTouchInterval - time.Second), "10.0.0.1", "default"}, true},
+			{sessionTouch{now.Add(time.Second), "10.0.0.2", "default"}, false},
+			{sessionTouch{now.Add(time.Second), "10.0.0.1", "staging"}, false},
+			{sessionTouch{now.Add(activeSessionTouchInterval), "10.0.0.1", "default"}, false},
+		}
+		for _, testCase := range testCaseSlice {
+			sessionTouchMap["session"] = sessionTouch{now, "10.0.0.1", "default"}
+			So(isSessionTouchSkipped("session", testCase.touch), ShouldEqual, testCase.skipped)
+		}
+		So(isSessionTouchSkipped("another", sessionTouch{now, "10.0.0.1", "default"}), ShouldBeFalse)
+	})
+}
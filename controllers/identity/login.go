@@ -16,12 +16,13 @@ package identity
 
 import (
 	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"math"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -36,27 +37,36 @@ func (c *LoginController) Get() {
 	c.TplName = "identity/login.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	guimessage.OutputMessage(c.Data)
-}
+	c.Data["oidcEnabled"] = IsRedirectAuthenticatorEnabled()
+	c.Data["demoMode"] = beego.AppConfig.DefaultBool("demoMode", false)
 
-type UserData struct {
-	Username string
-	Password string
-}
+	// The cluster is preselected when the user switches to the cluster not logined yet
+	cluster := c.GetString("cluster")
+	if backend.IsClusterExisting(cluster) == false {
+		cluster = backend.GetClusterName(c.Ctx)
+	}
+	c.Data["clusterNameSlice"] = backend.GetClusterNameSlice()
+	c.Data["selectedCluster"] = cluster
+	c.Data["multipleCluster"] = backend.HasMultipleCluster()
 
-type TokenData struct {
-	Token string
+	guimessage.OutputMessage(c.Data)
 }
 
 func (c *LoginController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	username := c.GetString("username")
 	password := c.GetString("password")
+	cluster := c.GetString("cluster")
+	if cluster == "" {
+		cluster = backend.GetDefaultClusterName()
+	}
+	if backend.IsClusterExisting(cluster) == false {
+		guimessage.AddDanger("Cluster " + cluster + " doesn't exist")
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
 	timeZoneOffset, err := c.GetInt("timeZoneOffset")
 	if err != nil {
 		guimessage.AddDanger("Fail to get browser time zone offset. Use UTC instead")
@@ -71,42 +81,94 @@ func (c *LoginController) Post() {
 		c.SetSession("timeZoneOffset", timeZoneOffset)
 	}
 
-	// User
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/tokens/"
-	userData := UserData{username, password}
-	tokenData := TokenData{}
-	_, err = restclient.RequestPostWithStructure(url, userData, &tokenData, nil)
+	provider := beego.AppConfig.DefaultString("identityProvider", identityProviderCloudone)
+	if provider == identityProviderLDAP {
+		passwordAuthenticator, err := getPasswordAuthenticator()
+		if err != nil {
+			guimessage.AddError(err)
+			guimessage.RedirectMessage(c)
+			c.Ctx.Redirect(302, "/gui/login/")
+			return
+		}
+
+		externalIdentity, err := passwordAuthenticator.Authenticate(username, password)
+		if err != nil {
+			guimessage.AddDanger("Fail to login with " + passwordAuthenticator.GetName() + ". " + err.Error())
+			guimessage.RedirectMessage(c)
+			c.Ctx.Redirect(302, "/gui/login/")
+			return
+		}
+
+		loginExternalUser(&c.Controller, cluster, externalIdentity)
+		return
+	}
+
+	// User of cloudone
+	token, err := backend.NewCloudoneClientWithTokenHeaderMap(nil).WithCluster(cluster).CreateToken(backend.UserData{Username: username, Password: password})
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/login/")
 		return
 	}
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/tokens/" + tokenData.Token + "/components/" + componentName
-	user := &rbac.User{}
-	_, err = restclient.RequestGetWithStructure(url, &user, nil)
+	// Token is used to submit to other componentes to authorize
+	headerMap := make(map[string]string)
+	headerMap["token"] = token
+	cloudoneClient := backend.NewCloudoneClientWithTokenHeaderMap(headerMap).WithCluster(cluster)
+
+	user, err := cloudoneClient.GetUserFromToken(token, componentName)
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/login/")
 		return
 	}
 
+	loginUser(&c.Controller, cluster, user, headerMap)
+}
+
+// loginUser sets the session for the user authorized in the cluster and redirects to the dashboard
+func loginUser(c *beego.Controller, cluster string, user *rbac.User, headerMap map[string]string) {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	cloudoneClient := backend.NewCloudoneClientWithTokenHeaderMap(headerMap).WithCluster(cluster)
+
+	// Roles granted only in the namespace
+	namespaceRoleMap, err := getNamespaceRoleMap(cloudoneClient, user)
+
+	if IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	// Renew the CSRF token for the new login
+	err = RenewCSRFToken(c.Ctx)
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	// The logins of the other clusters are kept for switching
+	prepareClusterLogin(c, user.Name)
+
 	// Set session
-	// User is used by this component to authorize
-	c.SetSession("user", user)
+	// Identity user is the user as logined and the user in session is the one for the selected namespace
+	c.SetSession(backend.SessionNameCluster, cluster)
+	c.SetSession("identityUser", user)
+	c.SetSession("namespaceRoleMap", namespaceRoleMap)
 	c.SetSession("username", user.Name)
-	// Token is used to submit to other componentes to authorize
-	headerMap := make(map[string]string)
-	headerMap["token"] = tokenData.Token
 	c.SetSession("tokenHeaderMap", headerMap)
-	// Layout menu is used to display common layout menu
-	layoutMenu := GetLayoutMenu(user)
-	c.SetSession("layoutMenu", layoutMenu)
+	c.SetSession("loginTime", time.Now())
 
 	// Namespace
 	namespace := beego.AppConfig.String("namespace")
@@ -127,25 +189,21 @@ func (c *LoginController) Post() {
 	if metaDataMap == nil {
 		metaDataMap = make(map[string]string)
 	}
-	// If loginNamespace is set, use it
+	// If loginNamespace is set and still permitted, use it
 	loginNamespace := metaDataMap["loginNamespace"]
-	if len(loginNamespace) > 0 {
+	if len(loginNamespace) > 0 && IsNamespacePermitted(user, loginNamespace) {
 		namespace = loginNamespace
 	}
 
 	// Check if the namespace existing
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/namespaces/"
-
-	nameSlice := make([]string, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &nameSlice, headerMap)
+	nameSlice, err := cloudoneClient.GetNamespaceNameSlice()
 
 	if IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/login/")
 		return
@@ -166,14 +224,23 @@ func (c *LoginController) Post() {
 	}
 
 	// Set namespace
-	c.SetSession("namespace", namespace)
+	err = SetNamespace(c, namespace)
+	if err != nil {
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/login/")
+		return
+	}
+
+	saveClusterLogin(c)
+
+	// Listed for the administrator to revoke
+	registerSession(c.Ctx, time.Now())
 
 	// Send audit log since this page will pass filter
-	go func() {
-		sendAuditLog(c.Ctx, user.Name, false)
-	}()
+	sendAuditLog(c.Ctx, user.Name, false)
 
-	guimessage.AddSuccess("User " + username + " login")
+	guimessage.AddSuccess("User " + user.Name + " login to cluster " + cluster)
 
 	c.Ctx.Redirect(302, "/gui/dashboard/topology/")
 
@@ -16,6 +16,7 @@ package identity
 
 import (
 	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
 )
@@ -34,10 +35,16 @@ func (c *LogoutController) Get() {
 		sendAuditLog(c.Ctx, user.Name, true)
 	}
 
+	unregisterSession(c.Ctx)
+
 	c.DelSession("user")
+	c.DelSession("identityUser")
+	c.DelSession("namespaceRoleMap")
 	c.DelSession("username")
 	c.DelSession("tokenHeaderMap")
 	c.DelSession("layoutMenu")
+	c.DelSession(backend.SessionNameCluster)
+	c.DelSession(sessionNameClusterLoginMap)
 
 	c.DestroySession()
 
@@ -0,0 +1,69 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func newIdentityDesc(name string, help string) *prometheus.Desc {
+	return prometheus.NewDesc(prometheus.BuildFQName("cloudone_gui", "", name), help, nil, nil)
+}
+
+var (
+	sessionsDesc             = newIdentityDesc("sessions", "The amount of the active GUI sessions.")
+	auditLogQueueDepthDesc   = newIdentityDesc("audit_log_queue_depth", "The amount of the audit logs in memory waiting to be delivered.")
+	auditLogSpoolEntriesDesc = newIdentityDesc("audit_log_spool_entries", "The amount of the spooled audit logs waiting to be replayed.")
+	auditLogsDeliveredDesc   = newIdentityDesc("audit_logs_delivered_total", "The amount of the audit logs delivered to cloudone_analysis.")
+	auditLogsSpooledDesc     = newIdentityDesc("audit_logs_spooled_total", "The amount of the audit logs spooled to the disk.")
+	auditLogsRetriedDesc     = newIdentityDesc("audit_logs_retried_total", "The amount of the audit logs replayed from the spool files.")
+	auditLogsDroppedDesc     = newIdentityDesc("audit_logs_dropped_total", "The amount of the audit logs dropped.")
+)
+
+// identityCollector takes one snapshot of the sessions and the audit log statistic for each scrape
+// since listing the sessions reads the session store.
+type identityCollector struct{}
+
+func (collector identityCollector) Describe(channel chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{
+		sessionsDesc,
+		auditLogQueueDepthDesc,
+		auditLogSpoolEntriesDesc,
+		auditLogsDeliveredDesc,
+		auditLogsSpooledDesc,
+		auditLogsRetriedDesc,
+		auditLogsDroppedDesc,
+	} {
+		channel <- desc
+	}
+}
+
+func (collector identityCollector) Collect(channel chan<- prometheus.Metric) {
+	sessionAmount := len(GetActiveSessionSlice())
+	statistic := GetAuditLogStatistic()
+
+	channel <- prometheus.MustNewConstMetric(sessionsDesc, prometheus.GaugeValue, float64(sessionAmount))
+	channel <- prometheus.MustNewConstMetric(auditLogQueueDepthDesc, prometheus.GaugeValue, float64(statistic.Queued))
+	channel <- prometheus.MustNewConstMetric(auditLogSpoolEntriesDesc, prometheus.GaugeValue, float64(statistic.SpoolEntry))
+	channel <- prometheus.MustNewConstMetric(auditLogsDeliveredDesc, prometheus.CounterValue, float64(statistic.Delivered))
+	channel <- prometheus.MustNewConstMetric(auditLogsSpooledDesc, prometheus.CounterValue, float64(statistic.Spooled))
+	channel <- prometheus.MustNewConstMetric(auditLogsRetriedDesc, prometheus.CounterValue, float64(statistic.Retried))
+	channel <- prometheus.MustNewConstMetric(auditLogsDroppedDesc, prometheus.CounterValue, float64(statistic.Dropped))
+}
+
+func init() {
+	metrics.MustRegister(identityCollector{})
+}
@@ -0,0 +1,157 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"errors"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/identity/authenticator"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"sort"
+	"strings"
+)
+
+// NamespaceRoleMetaDataKey is the key in the user meta data for the roles granted only in the namespace.
+// The text format is namespace1:role1,role2;namespace2:role3
+const NamespaceRoleMetaDataKey = "namespaceRole"
+
+func ParseNamespaceRole(text string) (map[string][]string, error) {
+	return authenticator.ParseGroupMapping(text)
+}
+
+func FormatNamespaceRole(namespaceRoleMap map[string][]string) string {
+	namespaceSlice := make([]string, 0)
+	for namespace, roleNameSlice := range namespaceRoleMap {
+		if len(roleNameSlice) > 0 {
+			namespaceSlice = append(namespaceSlice, namespace)
+		}
+	}
+	sort.Strings(namespaceSlice)
+
+	itemSlice := make([]string, 0)
+	for _, namespace := range namespaceSlice {
+		itemSlice = append(itemSlice, namespace+":"+strings.Join(namespaceRoleMap[namespace], ","))
+	}
+	return strings.Join(itemSlice, ";")
+}
+
+func IsNamespacePermitted(user *rbac.User, namespace string) bool {
+	if user == nil || namespace == "" {
+		return false
+	}
+	return user.HasResource(componentName, "/namespaces/"+namespace)
+}
+
+func GetPermittedNamespaceNameSlice(user *rbac.User, nameSlice []string) []string {
+	permittedNameSlice := make([]string, 0)
+	for _, name := range nameSlice {
+		if IsNamespacePermitted(user, name) {
+			permittedNameSlice = append(permittedNameSlice, name)
+		}
+	}
+	return permittedNameSlice
+}
+
+// getNamespaceRoleMap resolves the roles granted only in the namespace from the user meta data
+func getNamespaceRoleMap(cloudoneClient *backend.Client, user *rbac.User) (map[string][]*rbac.Role, error) {
+	namespaceRoleMap := make(map[string][]*rbac.Role)
+
+	namespaceRoleNameMap, err := ParseNamespaceRole(user.MetaDataMap[NamespaceRoleMetaDataKey])
+	if err != nil {
+		return nil, err
+	}
+	if len(namespaceRoleNameMap) == 0 {
+		return namespaceRoleMap, nil
+	}
+
+	roleSlice, err := cloudoneClient.GetRoleSlice()
+	if err != nil {
+		return nil, err
+	}
+
+	roleMap := make(map[string]*rbac.Role)
+	for i := range roleSlice {
+		roleMap[roleSlice[i].Name] = &roleSlice[i]
+	}
+
+	for namespace, roleNameSlice := range namespaceRoleNameMap {
+		for _, roleName := range roleNameSlice {
+			role, ok := roleMap[roleName]
+			if ok == false {
+				return nil, errors.New("Role " + roleName + " granted in namespace " + namespace + " doesn't exist")
+			}
+			namespaceRoleMap[namespace] = append(namespaceRoleMap[namespace], role)
+		}
+	}
+
+	return namespaceRoleMap, nil
+}
+
+// getNamespaceUser returns the login user plus the roles granted in the namespace
+func getNamespaceUser(identityUser *rbac.User, namespaceRoleMap map[string][]*rbac.Role, namespace string) *rbac.User {
+	user := *identityUser
+	user.RoleSlice = make([]*rbac.Role, 0)
+	user.RoleSlice = append(user.RoleSlice, identityUser.RoleSlice...)
+	user.RoleSlice = append(user.RoleSlice, namespaceRoleMap[namespace]...)
+	return &user
+}
+
+// SetNamespace switches the namespace in session. The session user is replaced with the login user
+// plus the roles granted in the namespace so every permission check afterward applies to the namespace.
+func SetNamespace(sessionUtility guimessagedisplay.SessionUtility, namespace string) error {
+	identityUser, ok := sessionUtility.GetSession("identityUser").(*rbac.User)
+	if ok == false {
+		return errors.New("User is not logined")
+	}
+
+	if IsNamespacePermitted(identityUser, namespace) == false {
+		return errors.New("User " + identityUser.Name + " is not authorized to namespace " + namespace)
+	}
+
+	namespaceRoleMap, _ := sessionUtility.GetSession("namespaceRoleMap").(map[string][]*rbac.Role)
+
+	user := getNamespaceUser(identityUser, namespaceRoleMap, namespace)
+
+	sessionUtility.SetSession("user", user)
+	sessionUtility.SetSession("namespace", namespace)
+	// Layout menu is used to display common layout menu
+	sessionUtility.SetSession("layoutMenu", GetLayoutMenu(user))
+
+	return nil
+}
+
+// AuthorizeNamespace checks the namespace given in the request instead of the one in session such as the lists of all namespaces.
+// FilterUser only authorizes the request with the roles of the namespace in session so the request is authorized
+// again with the roles granted in the given namespace.
+func AuthorizeNamespace(ctx *context.Context, namespace string) error {
+	identityUser, ok := ctx.Input.Session("identityUser").(*rbac.User)
+	if ok == false {
+		return errors.New("User is not logined")
+	}
+
+	if IsNamespacePermitted(identityUser, namespace) == false {
+		return errors.New("User " + identityUser.Name + " is not authorized to namespace " + namespace)
+	}
+
+	namespaceRoleMap, _ := ctx.Input.Session("namespaceRoleMap").(map[string][]*rbac.Role)
+
+	if IsAuthorized(getNamespaceUser(identityUser, namespaceRoleMap, namespace), ctx.Input.Method(), ctx.Input.URL()) == false {
+		return errors.New("User " + identityUser.Name + " is not authorized to " + ctx.Input.Method() + " " + ctx.Input.URL() + " in namespace " + namespace)
+	}
+
+	return nil
+}
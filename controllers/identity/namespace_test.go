@@ -0,0 +1,61 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestAuthorizeNamespace(t *testing.T) {
+	Convey("Subject: The namespace in the parameters is authorized with the roles granted in it\n", t, func() {
+		deleteRole := &rbac.Role{
+			Name:            "service_deleter",
+			PermissionSlice: []*rbac.Permission{&rbac.Permission{Name: "delete", Component: componentName, Method: "GET", Path: "/gui/inventory/service/delete"}},
+		}
+		identityUser := &rbac.User{
+			Name: "developer",
+			ResourceSlice: []*rbac.Resource{
+				&rbac.Resource{Name: "default", Component: componentName, Path: "/namespaces/default"},
+				&rbac.Resource{Name: "staging", Component: componentName, Path: "/namespaces/staging"},
+			},
+		}
+		namespaceRoleMap := map[string][]*rbac.Role{"staging": []*rbac.Role{deleteRole}}
+
+		testCaseSlice := []struct {
+			namespace  string
+			authorized bool
+		}{
+			{"staging", true},
+			{"default", false},
+			{"production", false},
+			{"", false},
+		}
+		for _, testCase := range testCaseSlice {
+			session := newTestSession("namespace")
+			session.Set("identityUser", identityUser)
+			session.Set("namespaceRoleMap", namespaceRoleMap)
+			ctx, _ := newTestContext("GET", "/gui/inventory/service/delete?namespace="+testCase.namespace, nil, nil, session)
+			err := AuthorizeNamespace(ctx, testCase.namespace)
+			So(err == nil, ShouldEqual, testCase.authorized)
+		}
+
+		ctx, _ := newTestContext("GET", "/gui/inventory/service/delete?namespace=staging", nil, nil, newTestSession("anonymous"))
+		So(AuthorizeNamespace(ctx, "staging"), ShouldNotBeNil)
+		So(identityUser.RoleSlice, ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,318 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/cloudawan/cloudone_utility/rbac"
+)
+
+// Page is a GUI page or an action on a page. The layout menu, the tab menus, the role editor and
+// FilterUser are all generated from the page tree so a new page is only added here.
+type Page struct {
+	// Name is the check box name in the role editor and the permission name
+	Name string
+	// DisplayName is the label in the role editor
+	DisplayName string
+	// Path is the RBAC path. The permission on it grants all the pages under it.
+	Path string
+	// MenuName is the label in the layout menu or the tab menu. Empty means not in the menu.
+	MenuName string
+	// MenuURL is the link in the layout menu or the tab menu
+	MenuURL string
+	// TabName is the key of the tab in the tab menu. Empty means not a tab.
+	TabName   string
+	PageSlice []*Page
+}
+
+var pageSlice = []*Page{
+	&Page{"dashboard", "Dashboard", "/gui/dashboard", "Dashboard", "", "", []*Page{
+		&Page{"dashboardTopology", "Topology", "/gui/dashboard/topology", "Topology", "/gui/dashboard/topology/", "topology", nil},
+		&Page{"dashboardHealthCheck", "Health Check", "/gui/dashboard/healthcheck", "Health Check", "/gui/dashboard/healthcheck/list", "healthcheck", nil},
+		&Page{"dashboardBlueGreen", "Blue Green", "/gui/dashboard/bluegreen", "BlueGreen", "/gui/dashboard/bluegreen/", "bluegreen", nil},
+		&Page{"dashboardAppService", "App Service", "/gui/dashboard/appservice", "AppService", "/gui/dashboard/appservice/", "appservice", nil},
+		&Page{"dashboardDeploy", "Deployment", "/gui/dashboard/deploy", "Deployment", "/gui/dashboard/deploy/", "deploy", nil},
+	}},
+	&Page{"repository", "Repository", "/gui/repository", "Repository", "", "", []*Page{
+		&Page{"repositoryImageInformation", "Applications", "/gui/repository/imageinformation", "Applications", "/gui/repository/imageinformation/list", "", []*Page{
+			&Page{"repositoryImageInformationList", "View", "/gui/repository/imageinformation/list", "", "", "", nil},
+			&Page{"repositoryImageInformationCreate", "Create", "/gui/repository/imageinformation/create", "", "", "", nil},
+			&Page{"repositoryImageInformationUpgrade", "Upgrade", "/gui/repository/imageinformation/upgrade", "", "", "", nil},
+			&Page{"repositoryImageInformationLog", "Log", "/gui/repository/imageinformation/log", "", "", "", nil},
+			&Page{"repositoryImageInformationDelete", "Delete", "/gui/repository/imageinformation/delete", "", "", "", nil},
+		}},
+		&Page{"repositoryImageRecord", "Record", "/gui/repository/imagerecord", "", "", "", []*Page{
+			&Page{"repositoryImageRecordList", "View", "/gui/repository/imagerecord/list", "", "", "", nil},
+			&Page{"repositoryImageRecordLog", "Log", "/gui/repository/imagerecord/log", "", "", "", nil},
+			&Page{"repositoryImageRecordDelete", "Delete", "/gui/repository/imagerecord/delete", "", "", "", nil},
+		}},
+		&Page{"repositoryThirdPartyService", "Third-party Service", "/gui/repository/thirdparty", "Third-party Services", "/gui/repository/thirdparty/list", "", []*Page{
+			&Page{"repositoryThirdPartyServiceList", "View", "/gui/repository/thirdparty/list", "", "", "", nil},
+			&Page{"repositoryThirdPartyServiceCreate", "Create/Update", "/gui/repository/thirdparty/edit", "", "", "", nil},
+			&Page{"repositoryThirdPartyServiceLaunch", "Launch", "/gui/repository/thirdparty/launch", "", "", "", nil},
+			&Page{"repositoryThirdPartyServiceDelete", "Delete", "/gui/repository/thirdparty/delete", "", "", "", nil},
+		}},
+		&Page{"repositoryTopologyTemplate", "Topology Template", "/gui/repository/topologytemplate", "Topology template", "/gui/repository/topologytemplate/list", "", []*Page{
+			&Page{"repositoryTopologyTemplateList", "View", "/gui/repository/topologytemplate/list", "", "", "", nil},
+			&Page{"repositoryTopologyTemplateClone", "Clone", "/gui/repository/topologytemplate/clone", "", "", "", nil},
+			&Page{"repositoryTopologyTemplateDelete", "Delete", "/gui/repository/topologytemplate/delete", "", "", "", nil},
+		}},
+	}},
+	&Page{"deploy", "Deploy", "/gui/deploy", "Deploy", "", "", []*Page{
+		&Page{"deployDeploy", "Applications", "/gui/deploy/deploy", "Applications", "/gui/deploy/deploy/list", "", []*Page{
+			&Page{"deployDeployList", "View", "/gui/deploy/deploy/list", "", "", "", nil},
+			&Page{"deployDeployCreate", "Create", "/gui/deploy/deploy/create", "", "", "", nil},
+			&Page{"deployDeployUpdate", "Update/RollBack", "/gui/deploy/deploy/update", "", "", "", nil},
+			&Page{"deployDeployResize", "Resize", "/gui/deploy/deploy/resize", "", "", "", nil},
+			&Page{"deployDeployRevision", "Revisions", "/gui/deploy/deploy/revision", "", "", "", nil},
+			&Page{"deployDeployRollback", "Rollback to Revision", "/gui/deploy/deploy/rollback", "", "", "", nil},
+			&Page{"deployDeployDelete", "Delete", "/gui/deploy/deploy/delete", "", "", "", nil},
+		}},
+		&Page{"deployDeployBlueGreen", "Blue Green Deployments", "/gui/deploy/deploybluegreen", "Blue Green Deployments", "/gui/deploy/deploybluegreen/list", "", []*Page{
+			&Page{"deployDeployBlueGreenList", "View", "/gui/deploy/deploybluegreen/list", "", "", "", nil},
+			&Page{"deployDeployBlueGreenSelect", "Select", "/gui/deploy/deploybluegreen/select", "", "", "", nil},
+			&Page{"deployDeployBlueGreenPromote", "Promote", "/gui/deploy/deploybluegreen/promote", "", "", "", nil},
+			&Page{"deployDeployBlueGreenPromotion", "View Promotion", "/gui/deploy/deploybluegreen/promotion", "", "", "", nil},
+			&Page{"deployDeployBlueGreenAbort", "Abort Promotion", "/gui/deploy/deploybluegreen/abort", "", "", "", nil},
+			&Page{"deployDeployBlueGreenRevert", "Revert Promotion", "/gui/deploy/deploybluegreen/revert", "", "", "", nil},
+			&Page{"deployDeployBlueGreenDelete", "Delete", "/gui/deploy/deploybluegreen/delete", "", "", "", nil},
+		}},
+		&Page{"deployDeployCanary", "Canary Deployments", "/gui/deploy/deploycanary", "Canary Deployments", "/gui/deploy/deploycanary/list", "", []*Page{
+			&Page{"deployDeployCanaryList", "View", "/gui/deploy/deploycanary/list", "", "", "", nil},
+			&Page{"deployDeployCanaryCreate", "Create", "/gui/deploy/deploycanary/create", "", "", "", nil},
+			&Page{"deployDeployCanaryPause", "Pause", "/gui/deploy/deploycanary/pause", "", "", "", nil},
+			&Page{"deployDeployCanaryResume", "Resume", "/gui/deploy/deploycanary/resume", "", "", "", nil},
+			&Page{"deployDeployCanaryPromote", "Promote", "/gui/deploy/deploycanary/promote", "", "", "", nil},
+			&Page{"deployDeployCanaryAbort", "Abort", "/gui/deploy/deploycanary/abort", "", "", "", nil},
+			&Page{"deployDeployCanaryDelete", "Delete", "/gui/deploy/deploycanary/delete", "", "", "", nil},
+		}},
+		&Page{"deployAutoScaler", "AutoScalers", "/gui/deploy/autoscaler", "Autoscalers", "/gui/deploy/autoscaler/list", "", []*Page{
+			&Page{"deployAutoScalerList", "View", "/gui/deploy/autoscaler/list", "", "", "", nil},
+			&Page{"deployAutoScalerCreate", "Create/Update", "/gui/deploy/autoscaler/edit", "", "", "", nil},
+			&Page{"deployAutoScalerDelete", "Delete", "/gui/deploy/autoscaler/delete", "", "", "", nil},
+		}},
+		&Page{"deployDeployClusterApplication", "Third-Party Services", "/gui/deploy/deployclusterapplication", "Third-party Services", "/gui/deploy/deployclusterapplication/list", "", []*Page{
+			&Page{"deployDeployClusterApplicationList", "View", "/gui/deploy/deployclusterapplication/list", "", "", "", nil},
+			&Page{"deployDeployClusterApplicationSize", "Resize", "/gui/deploy/deployclusterapplication/size", "", "", "", nil},
+			&Page{"deployDeployClusterApplicationDelete", "Delete", "/gui/deploy/deployclusterapplication/delete", "", "", "", nil},
+		}},
+		&Page{"deployDeployManifest", "Import Manifests", "/gui/deploy/deploymanifest", "Import Manifests", "/gui/deploy/deploymanifest/import", "", []*Page{
+			&Page{"deployDeployManifestImport", "Preview", "/gui/deploy/deploymanifest/import", "", "", "", nil},
+			&Page{"deployDeployManifestApply", "Apply", "/gui/deploy/deploymanifest/apply", "", "", "", nil},
+		}},
+		&Page{"deployClone", "Clone Topology", "/gui/deploy/clone", "Clone Topology", "/gui/deploy/clone/select", "", nil},
+	}},
+	&Page{"inventory", "Inventory", "/gui/inventory", "Inventory", "", "", []*Page{
+		&Page{"inventoryReplicationController", "Replication Controllers", "/gui/inventory/replicationcontroller", "Replications", "/gui/inventory/replicationcontroller/list", "", []*Page{
+			&Page{"inventoryReplicationControllerList", "View", "/gui/inventory/replicationcontroller/list", "", "", "", nil},
+			&Page{"inventoryReplicationControllerCreate", "Create", "/gui/inventory/replicationcontroller/edit", "", "", "", nil},
+			&Page{"inventoryReplicationControllerSize", "Resize", "/gui/inventory/replicationcontroller/size", "", "", "", nil},
+			&Page{"inventoryReplicationControllerDelete", "Delete", "/gui/inventory/replicationcontroller/delete", "", "", "", nil},
+			&Page{"inventoryReplicationControllerPodLog", "Pod Log", "/gui/inventory/replicationcontroller/pod/log", "", "", "", nil},
+			&Page{"inventoryReplicationControllerPodDelete", "Pod Delete", "/gui/inventory/replicationcontroller/pod/delete", "", "", "", nil},
+			&Page{"inventoryReplicationControllerDockerTerminal", "Terminal", "/gui/inventory/replicationcontroller/dockerterminal", "", "", "", nil},
+		}},
+		&Page{"inventoryService", "Services", "/gui/inventory/service", "Services", "/gui/inventory/service/list", "", []*Page{
+			&Page{"inventoryServiceList", "View", "/gui/inventory/service/list", "", "", "", nil},
+			&Page{"inventoryServiceCreate", "Create", "/gui/inventory/service/edit", "", "", "", nil},
+			&Page{"inventoryServiceDelete", "Delete", "/gui/inventory/service/delete", "", "", "", nil},
+		}},
+	}},
+	&Page{"filesystem", "Filesystem", "/gui/filesystem", "Filesystem", "", "", []*Page{
+		&Page{"filesystemGlusterfs", "Glusterfs", "/gui/filesystem/glusterfs", "Glusterfs", "/gui/filesystem/glusterfs/cluster/list", "", []*Page{
+			&Page{"filesystemGlusterfsCluster", "Cluster", "/gui/filesystem/glusterfs/cluster", "", "", "", []*Page{
+				&Page{"filesystemGlusterfsClusterList", "View", "/gui/filesystem/glusterfs/cluster/list", "", "", "", nil},
+				&Page{"filesystemGlusterfsClusterCreate", "Create/Update", "/gui/filesystem/glusterfs/cluster/edit", "", "", "", nil},
+				&Page{"filesystemGlusterfsClusterDelete", "Delete", "/gui/filesystem/glusterfs/cluster/delete", "", "", "", nil},
+			}},
+			&Page{"filesystemGlusterfsVolume", "Volumes", "/gui/filesystem/glusterfs/volume", "", "", "", []*Page{
+				&Page{"filesystemGlusterfsVolumeList", "View", "/gui/filesystem/glusterfs/volume/list", "", "", "", nil},
+				&Page{"filesystemGlusterfsVolumeCreate", "Create", "/gui/filesystem/glusterfs/volume/create", "", "", "", nil},
+				&Page{"filesystemGlusterfsVolumeReset", "Reset", "/gui/filesystem/glusterfs/volume/reset", "", "", "", nil},
+				&Page{"filesystemGlusterfsVolumeDelete", "Delete", "/gui/filesystem/glusterfs/volume/delete", "", "", "", nil},
+			}},
+		}},
+	}},
+	&Page{"monitor", "Monitor", "/gui/monitor", "Monitor", "", "", []*Page{
+		&Page{"monitorNode", "Nodes", "/gui/monitor/node", "Nodes", "/gui/monitor/node", "", nil},
+		&Page{"monitorContainer", "Containers", "/gui/monitor/container", "Containers", "/gui/monitor/container", "", nil},
+		&Page{"monitorHistoricalContainer", "Historical Containers", "/gui/monitor/historicalcontainer", "", "", "", nil},
+	}},
+	&Page{"event", "Event", "/gui/event", "Event", "", "", []*Page{
+		&Page{"eventAudit", "Audit Logs", "/gui/event/audit", "Audit Logs", "/gui/event/audit/list", "", []*Page{
+			&Page{"eventAuditList", "View", "/gui/event/audit/list", "", "", "", nil},
+			&Page{"eventAuditExport", "Export", "/gui/event/audit/export", "", "", "", nil},
+			&Page{"eventAuditVerify", "Verify", "/gui/event/audit/verify", "", "", "", nil},
+		}},
+		&Page{"eventKubernetes", "Kubernetes", "/gui/event/kubernetes", "Kubernetes Events", "/gui/event/kubernetes/list", "", []*Page{
+			&Page{"eventKubernetesList", "View", "/gui/event/kubernetes/list", "", "", "", nil},
+			&Page{"eventKubernetesAcknowledge", "Acknowledge", "/gui/event/kubernetes/acknowledge", "", "", "", nil},
+		}},
+	}},
+	&Page{"notification", "Notification", "/gui/notification", "Notification", "", "", []*Page{
+		&Page{"notificationNotifier", "Notifiers", "/gui/notification/notifier", "Notifiers", "/gui/notification/notifier/list", "", []*Page{
+			&Page{"notificationNotifierList", "View", "/gui/notification/notifier/list", "", "", "", nil},
+			&Page{"notificationNotifierCreate", "Create/Update", "/gui/notification/notifier/edit", "", "", "", nil},
+			&Page{"notificationNotifierDelete", "Delete", "/gui/notification/notifier/delete", "", "", "", nil},
+		}},
+	}},
+	&Page{"system", "System", "/gui/system", "System", "", "", []*Page{
+		&Page{"systemAbout", "About", "/gui/system/about", "About", "/gui/system/about", "", nil},
+		&Page{"systemCache", "Backend Cache", "/gui/system/cache", "Backend Cache", "/gui/system/cache/list", "", []*Page{
+			&Page{"systemCacheList", "View", "/gui/system/cache/list", "", "", "", nil},
+			&Page{"systemCacheFlush", "Flush", "/gui/system/cache/flush", "", "", "", nil},
+		}},
+		&Page{"systemCluster", "Clusters", "/gui/system/cluster", "", "", "", []*Page{
+			&Page{"systemClusterSelect", "Select", "/gui/system/cluster/select", "", "", "", nil},
+		}},
+		&Page{"systemNamespace", "Namespaces", "/gui/system/namespace", "Namepaces", "/gui/system/namespace/list", "", []*Page{
+			&Page{"systemNamespaceList", "View", "/gui/system/namespace/list", "", "", "", nil},
+			&Page{"systemNamespaceCreate", "Create", "/gui/system/namespace/edit", "", "", "", nil},
+			&Page{"systemNamespaceSelect", "Select", "/gui/system/namespace/select", "", "", "", nil},
+			&Page{"systemNamespaceBookmark", "Bookmark", "/gui/system/namespace/bookmark", "", "", "", nil},
+			&Page{"systemNamespaceDelete", "Delete", "/gui/system/namespace/delete", "", "", "", nil},
+		}},
+		&Page{"systemNotification", "Notifications", "/gui/system/notification", "Notification", "", "", []*Page{
+			&Page{"systemNotificationEmailServer", "Email Servers", "/gui/system/notification/emailserver", "Email Server", "/gui/system/notification/emailserver/list", "emailserver", []*Page{
+				&Page{"systemNotificationEmailServerList", "View", "/gui/system/notification/emailserver/list", "", "", "", nil},
+				&Page{"systemNotificationEmailServerCreate", "Create", "/gui/system/notification/emailserver/create", "", "", "", nil},
+				&Page{"systemNotificationEmailServerDelete", "Delete", "/gui/system/notification/emailserver/delete", "", "", "", nil},
+			}},
+			&Page{"systemNotificationSMS", "SMS", "/gui/system/notification/sms", "SMS", "/gui/system/notification/sms/list", "sms", []*Page{
+				&Page{"systemNotificationSMSList", "View", "/gui/system/notification/sms/list", "", "", "", nil},
+				&Page{"systemNotificationSMSCreate", "Create", "/gui/system/notification/sms/create", "", "", "", nil},
+				&Page{"systemNotificationSMSDelete", "Delete", "/gui/system/notification/sms/delete", "", "", "", nil},
+			}},
+		}},
+		&Page{"systemHost", "Host", "/gui/system/host", "Host Credential", "/gui/system/host/credential/list", "", []*Page{
+			&Page{"systemHostCredential", "Credential", "/gui/system/host/credential", "", "", "", []*Page{
+				&Page{"systemHostCredentialList", "View", "/gui/system/host/credential/list", "", "", "", nil},
+				&Page{"systemHostCredentialCreate", "Create/Update", "/gui/system/host/credential/edit", "", "", "", nil},
+				&Page{"systemHostCredentialDelete", "Delete", "/gui/system/host/credential/delete", "", "", "", nil},
+			}},
+		}},
+		&Page{"systemRBAC", "RBAC", "/gui/system/rbac", "RBAC", "", "", []*Page{
+			&Page{"systemRBACUser", "User", "/gui/system/rbac/user", "User", "/gui/system/rbac/user/list", "user", []*Page{
+				&Page{"systemRBACUserList", "View", "/gui/system/rbac/user/list", "", "", "", nil},
+				&Page{"systemRBACUserCreate", "Create/Update", "/gui/system/rbac/user/edit", "", "", "", nil},
+				&Page{"systemRBACUserDelete", "Delete", "/gui/system/rbac/user/delete", "", "", "", nil},
+				&Page{"systemRBACUserToken", "Personal Access Token", "/gui/system/rbac/user/token", "", "", "", []*Page{
+					&Page{"systemRBACUserTokenList", "View", "/gui/system/rbac/user/token/list", "", "", "", nil},
+					&Page{"systemRBACUserTokenCreate", "Create", "/gui/system/rbac/user/token/edit", "", "", "", nil},
+					&Page{"systemRBACUserTokenDelete", "Revoke", "/gui/system/rbac/user/token/delete", "", "", "", nil},
+				}},
+			}},
+			&Page{"systemRBACRole", "Role", "/gui/system/rbac/role", "Role", "/gui/system/rbac/role/list", "role", []*Page{
+				&Page{"systemRBACRoleList", "View", "/gui/system/rbac/role/list", "", "", "", nil},
+				&Page{"systemRBACRoleCreate", "Create/Update", "/gui/system/rbac/role/edit", "", "", "", nil},
+				&Page{"systemRBACRoleDelete", "Delete", "/gui/system/rbac/role/delete", "", "", "", nil},
+			}},
+			&Page{"systemRBACSession", "Session", "/gui/system/rbac/session", "Session", "/gui/system/rbac/session/list", "session", []*Page{
+				&Page{"systemRBACSessionList", "View", "/gui/system/rbac/session/list", "", "", "", nil},
+				&Page{"systemRBACSessionDelete", "Terminate", "/gui/system/rbac/session/delete", "", "", "", nil},
+			}},
+		}},
+		&Page{"systemPrivateRegistry", "Private Registry", "/gui/system/privateregistry", "Private Registry", "/gui/system/privateregistry/server/list", "", []*Page{
+			&Page{"systemPrivateRegistryServer", "Server", "/gui/system/privateregistry/server", "", "", "", []*Page{
+				&Page{"systemPrivateRegistryServerList", "View", "/gui/system/privateregistry/server/list", "", "", "", nil},
+				&Page{"systemPrivateRegistryServerCreate", "Create/Update", "/gui/system/privateregistry/server/edit", "", "", "", nil},
+				&Page{"systemPrivateRegistryServerDelete", "Delete", "/gui/system/privateregistry/server/delete", "", "", "", nil},
+			}},
+			&Page{"systemPrivateRegistryRepository", "Repository", "/gui/system/privateregistry/repository", "", "", "", []*Page{
+				&Page{"systemPrivateRegistryRepositoryList", "View", "/gui/system/privateregistry/repository/list", "", "", "", nil},
+				&Page{"systemPrivateRegistryRepositoryDelete", "Delete", "/gui/system/privateregistry/repository/delete", "", "", "", nil},
+			}},
+			&Page{"systemPrivateRegistryImage", "Image", "/gui/system/privateregistry/image", "", "", "", []*Page{
+				&Page{"systemPrivateRegistryImageList", "View", "/gui/system/privateregistry/image/list", "", "", "", nil},
+				&Page{"systemPrivateRegistryImageDelete", "Delete", "/gui/system/privateregistry/image/delete", "", "", "", nil},
+			}},
+		}},
+		&Page{"systemSLB", "SLB", "/gui/system/slb", "SLB Daemon", "/gui/system/slb/daemon/list", "", []*Page{
+			&Page{"systemSLBDaemon", "SLB Daemon", "/gui/system/slb/daemon", "", "", "", []*Page{
+				&Page{"systemSLBDaemonList", "View", "/gui/system/slb/daemon/list", "", "", "", nil},
+				&Page{"systemSLBDaemonCreate", "Create/Update", "/gui/system/slb/daemon/edit", "", "", "", nil},
+				&Page{"systemSLBDaemonConfigure", "Configure", "/gui/system/slb/daemon/configure", "", "", "", nil},
+				&Page{"systemSLBDaemonDelete", "Delete", "/gui/system/slb/daemon/delete", "", "", "", nil},
+			}},
+		}},
+		&Page{"systemUpgrade", "Upgrade", "/gui/system/upgrade", "Upgrade", "/gui/system/upgrade", "", nil},
+	}},
+}
+
+var pagePathMap = createPagePathMap(make(map[string]*Page), pageSlice)
+
+func createPagePathMap(pathMap map[string]*Page, pageSlice []*Page) map[string]*Page {
+	for _, page := range pageSlice {
+		pathMap[page.Path] = page
+		createPagePathMap(pathMap, page.PageSlice)
+	}
+	return pathMap
+}
+
+// GetPageSlice returns the top level pages of the page tree
+func GetPageSlice() []*Page {
+	return pageSlice
+}
+
+// GetPagePermissionSlice returns the permissions granting the page and all the pages under it.
+// The page serves the form with GET and submits it with POST so both methods are granted.
+func GetPagePermissionSlice(page *Page) []*rbac.Permission {
+	return []*rbac.Permission{
+		&rbac.Permission{Name: page.Name, Component: componentName, Method: "GET", Path: page.Path},
+		&rbac.Permission{Name: page.Name + "-POST", Component: componentName, Method: "POST", Path: page.Path},
+	}
+}
+
+// IsAuthorized checks whether the user could access the path with the method of the request
+func IsAuthorized(user *rbac.User, method string, path string) bool {
+	return user.HasPermission(componentName, method, path)
+}
+
+func (page *Page) getPermittedTabSlice(user *rbac.User) []*Page {
+	tabSlice := make([]*Page, 0)
+	for _, childPage := range page.PageSlice {
+		if childPage.TabName != "" && user.HasPermission(componentName, "GET", childPage.MenuURL) {
+			tabSlice = append(tabSlice, childPage)
+		}
+	}
+	return tabSlice
+}
+
+func (page *Page) hasTab() bool {
+	for _, childPage := range page.PageSlice {
+		if childPage.TabName != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// getPermittedMenuURL returns the link of the menu or empty if the user is not permitted.
+// The menu with tabs links to the first tab the user is permitted.
+func (page *Page) getPermittedMenuURL(user *rbac.User) string {
+	if page.hasTab() {
+		tabSlice := page.getPermittedTabSlice(user)
+		if len(tabSlice) > 0 {
+			return tabSlice[0].MenuURL
+		} else {
+			return ""
+		}
+	}
+
+	if page.MenuURL != "" && user.HasPermission(componentName, "GET", page.MenuURL) {
+		return page.MenuURL
+	} else {
+		return ""
+	}
+}
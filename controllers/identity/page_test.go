@@ -0,0 +1,53 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIsAuthorized(t *testing.T) {
+	Convey("Subject: The request is authorized with its own method\n", t, func() {
+		namespacePage := &Page{Name: "systemNamespace", Path: "/gui/system/namespace"}
+		editorUser := &rbac.User{
+			Name:      "editor",
+			RoleSlice: []*rbac.Role{&rbac.Role{Name: "namespace", PermissionSlice: GetPagePermissionSlice(namespacePage)}},
+		}
+		readOnlyUser := &rbac.User{
+			Name:      "reader",
+			RoleSlice: []*rbac.Role{&rbac.Role{Name: "read_only", PermissionSlice: []*rbac.Permission{&rbac.Permission{Name: "read", Component: componentName, Method: "GET", Path: "/gui/system/namespace"}}}},
+		}
+
+		testCaseSlice := []struct {
+			user       *rbac.User
+			method     string
+			path       string
+			authorized bool
+		}{
+			{editorUser, "GET", "/gui/system/namespace/list", true},
+			{editorUser, "POST", "/gui/system/namespace/delete", true},
+			{editorUser, "DELETE", "/gui/system/namespace/delete", false},
+			{editorUser, "POST", "/gui/system/host/credential/delete", false},
+			{readOnlyUser, "GET", "/gui/system/namespace/list", true},
+			{readOnlyUser, "POST", "/gui/system/namespace/delete", false},
+		}
+		for _, testCase := range testCaseSlice {
+			So(IsAuthorized(testCase.user, testCase.method, testCase.path), ShouldEqual, testCase.authorized)
+		}
+	})
+}
@@ -0,0 +1,616 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/random"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// PersonalAccessTokenMetaDataKey is the key in the user meta data for the personal access tokens in json
+	PersonalAccessTokenMetaDataKey = "personalAccessToken"
+	// PersonalAccessTokenScopeAPI allows /api/v1
+	PersonalAccessTokenScopeAPI = "api"
+	// PersonalAccessTokenScopeGUIRestAPIRead allows GET of /guirestapi/v1
+	PersonalAccessTokenScopeGUIRestAPIRead = "guirestapi:read"
+	// PersonalAccessTokenScopeGUIRestAPIWrite allows all methods of /guirestapi/v1
+	PersonalAccessTokenScopeGUIRestAPIWrite = "guirestapi:write"
+
+	personalAccessTokenPrefix                  = "cpat."
+	defaultPersonalAccessTokenCacheTTLInSecond = 60
+	personalAccessTokenLastUsedUpdateInterval  = time.Minute
+	personalAccessTokenUsageKeyPrefix          = "personalaccesstokenusage/"
+	// The namespace of /guirestapi is selected with this header instead of the session
+	personalAccessTokenNamespaceHeaderName = "Namespace"
+	// The cluster of the owner is selected with this header and the default cluster is used without it
+	personalAccessTokenClusterHeaderName = "Cluster"
+	personalAccessTokenDataName          = "personalAccessToken"
+)
+
+// PersonalAccessToken is used by the automation to access the REST APIs as the user.
+// Only the hash of the secret is stored so the token is shown once when created.
+type PersonalAccessToken struct {
+	ID           string
+	Name         string
+	HashedSecret string
+	ScopeSlice   []string
+	CreatedTime  time.Time
+	ExpiredTime  *time.Time
+	// Filled by LoadPersonalAccessTokenUsage from the session store. The older version recorded them in the meta data.
+	LastUsedTime *time.Time
+	LastUsedIP   string
+}
+
+func GetPersonalAccessTokenScopeSlice() []string {
+	return []string{
+		PersonalAccessTokenScopeAPI,
+		PersonalAccessTokenScopeGUIRestAPIRead,
+		PersonalAccessTokenScopeGUIRestAPIWrite,
+	}
+}
+
+func (personalAccessToken *PersonalAccessToken) IsExpired() bool {
+	return personalAccessToken.ExpiredTime != nil && time.Now().After(*personalAccessToken.ExpiredTime)
+}
+
+func (personalAccessToken *PersonalAccessToken) IsInScope(method string, path string) bool {
+	for _, scope := range personalAccessToken.ScopeSlice {
+		switch scope {
+		case PersonalAccessTokenScopeAPI:
+			if strings.HasPrefix(path, "/api/v1/") {
+				return true
+			}
+		case PersonalAccessTokenScopeGUIRestAPIRead:
+			if strings.HasPrefix(path, "/guirestapi/v1/") && isSafeMethod(method) {
+				return true
+			}
+		case PersonalAccessTokenScopeGUIRestAPIWrite:
+			if strings.HasPrefix(path, "/guirestapi/v1/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
+
+func ParsePersonalAccessTokenSlice(user *rbac.User) ([]*PersonalAccessToken, error) {
+	personalAccessTokenSlice := make([]*PersonalAccessToken, 0)
+	text := user.MetaDataMap[PersonalAccessTokenMetaDataKey]
+	if text == "" {
+		return personalAccessTokenSlice, nil
+	}
+	err := json.Unmarshal([]byte(text), &personalAccessTokenSlice)
+	if err != nil {
+		return nil, errors.New("Fail to parse the personal access tokens of user " + user.Name + " with error " + err.Error())
+	}
+	return personalAccessTokenSlice, nil
+}
+
+// updatePersonalAccessTokenSlice replaces the whole meta data so the other keys are kept from the user
+func updatePersonalAccessTokenSlice(cloudoneClient *backend.Client, user *rbac.User, personalAccessTokenSlice []*PersonalAccessToken) error {
+	byteSlice, err := json.Marshal(personalAccessTokenSlice)
+	if err != nil {
+		return err
+	}
+
+	metaDataMap := make(map[string]string)
+	for key, value := range user.MetaDataMap {
+		metaDataMap[key] = value
+	}
+	if len(personalAccessTokenSlice) > 0 {
+		metaDataMap[PersonalAccessTokenMetaDataKey] = string(byteSlice)
+	} else {
+		delete(metaDataMap, PersonalAccessTokenMetaDataKey)
+	}
+
+	return cloudoneClient.UpdateUserMetaData(user.Name, metaDataMap)
+}
+
+func hashPersonalAccessTokenSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreatePersonalAccessToken returns the token which is cpat.<base64 user name>.<secret>
+func CreatePersonalAccessToken(cloudoneClient *backend.Client, userName string, name string, scopeSlice []string, expiredTime *time.Time) (string, error) {
+	if name == "" {
+		return "", errors.New("Name of the personal access token is required")
+	}
+	if len(scopeSlice) == 0 {
+		return "", errors.New("At least one scope of the personal access token is required")
+	}
+	for _, scope := range scopeSlice {
+		found := false
+		for _, supportedScope := range GetPersonalAccessTokenScopeSlice() {
+			if scope == supportedScope {
+				found = true
+			}
+		}
+		if found == false {
+			return "", errors.New("Scope " + scope + " is not supported")
+		}
+	}
+
+	user, err := cloudoneClient.GetUser(userName)
+	if err != nil {
+		return "", err
+	}
+
+	personalAccessTokenSlice, err := ParsePersonalAccessTokenSlice(user)
+	if err != nil {
+		return "", err
+	}
+	for _, personalAccessToken := range personalAccessTokenSlice {
+		if personalAccessToken.Name == name {
+			return "", errors.New("Personal access token " + name + " already exists")
+		}
+	}
+
+	secret, err := createRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	personalAccessToken := &PersonalAccessToken{
+		random.UUID(),
+		name,
+		hashPersonalAccessTokenSecret(secret),
+		scopeSlice,
+		time.Now(),
+		expiredTime,
+		nil,
+		"",
+	}
+	personalAccessTokenSlice = append(personalAccessTokenSlice, personalAccessToken)
+
+	err = updatePersonalAccessTokenSlice(cloudoneClient, user, personalAccessTokenSlice)
+	if err != nil {
+		return "", err
+	}
+
+	return personalAccessTokenPrefix + base64.RawURLEncoding.EncodeToString([]byte(userName)) + "." + secret, nil
+}
+
+func RevokePersonalAccessToken(cloudoneClient *backend.Client, userName string, id string) error {
+	user, err := cloudoneClient.GetUser(userName)
+	if err != nil {
+		return err
+	}
+
+	personalAccessTokenSlice, err := ParsePersonalAccessTokenSlice(user)
+	if err != nil {
+		return err
+	}
+
+	remainingPersonalAccessTokenSlice := make([]*PersonalAccessToken, 0)
+	for _, personalAccessToken := range personalAccessTokenSlice {
+		if personalAccessToken.ID != id {
+			remainingPersonalAccessTokenSlice = append(remainingPersonalAccessTokenSlice, personalAccessToken)
+		}
+	}
+	if len(remainingPersonalAccessTokenSlice) == len(personalAccessTokenSlice) {
+		return errors.New("Personal access token " + id + " doesn't exist")
+	}
+
+	err = updatePersonalAccessTokenSlice(cloudoneClient, user, remainingPersonalAccessTokenSlice)
+	if err != nil {
+		return err
+	}
+
+	// The other instances stop accepting it after the cache expires
+	removeCachedPersonalAccessTokenOfUser(userName)
+
+	if err := sessionstore.GetStore().Delete(getPersonalAccessTokenUsageKey(id)); err != nil {
+		beego.Error("Fail to delete the usage of the personal access token", id, err)
+	}
+
+	return nil
+}
+
+type cachedPersonalAccessToken struct {
+	user                *rbac.User
+	namespaceRoleMap    map[string][]*rbac.Role
+	personalAccessToken *PersonalAccessToken
+	expiredTime         time.Time
+}
+
+var personalAccessTokenCacheMap = make(map[string]cachedPersonalAccessToken)
+var personalAccessTokenLastUsedMap = make(map[string]time.Time)
+var personalAccessTokenCacheLock = sync.Mutex{}
+
+func getCachedPersonalAccessToken(hashedToken string) *cachedPersonalAccessToken {
+	personalAccessTokenCacheLock.Lock()
+	defer personalAccessTokenCacheLock.Unlock()
+
+	cached, ok := personalAccessTokenCacheMap[hashedToken]
+	if ok == false {
+		return nil
+	}
+	if time.Now().After(cached.expiredTime) {
+		delete(personalAccessTokenCacheMap, hashedToken)
+		return nil
+	}
+	return &cached
+}
+
+func cachePersonalAccessToken(hashedToken string, cached cachedPersonalAccessToken) {
+	ttl := time.Duration(beego.AppConfig.DefaultInt("personalAccessTokenCacheTTLInSecond", defaultPersonalAccessTokenCacheTTLInSecond)) * time.Second
+	if ttl <= 0 {
+		return
+	}
+
+	personalAccessTokenCacheLock.Lock()
+	defer personalAccessTokenCacheLock.Unlock()
+
+	now := time.Now()
+	// Clean the expired tokens so the map doesn't grow with the tokens never used again
+	for key, existing := range personalAccessTokenCacheMap {
+		if now.After(existing.expiredTime) {
+			delete(personalAccessTokenCacheMap, key)
+		}
+	}
+
+	cached.expiredTime = now.Add(ttl)
+	personalAccessTokenCacheMap[hashedToken] = cached
+}
+
+func removeCachedPersonalAccessTokenOfUser(userName string) {
+	personalAccessTokenCacheLock.Lock()
+	defer personalAccessTokenCacheLock.Unlock()
+
+	for key, cached := range personalAccessTokenCacheMap {
+		if cached.user.Name == userName {
+			delete(personalAccessTokenCacheMap, key)
+		}
+	}
+}
+
+// wrapServiceAccountError keeps the backend outage recognizable by backend.IsUnavailable
+func wrapServiceAccountError(err error) error {
+	message := "Fail to login the service account with error " + err.Error()
+	if backend.IsUnavailable(err) {
+		return backend.UnavailableError{Message: message}
+	}
+	return errors.New(message)
+}
+
+// loadPersonalAccessToken resolves the owner and the roles of the token with the service account of the cluster
+func loadPersonalAccessToken(cluster string, token string) (*cachedPersonalAccessToken, error) {
+	splitSlice := strings.Split(strings.TrimPrefix(token, personalAccessTokenPrefix), ".")
+	if len(splitSlice) != 2 {
+		return nil, errors.New("Personal access token is malformed")
+	}
+	userNameByteSlice, err := base64.RawURLEncoding.DecodeString(splitSlice[0])
+	if err != nil {
+		return nil, errors.New("Personal access token is malformed")
+	}
+	userName := string(userNameByteSlice)
+	hashedSecret := hashPersonalAccessTokenSecret(splitSlice[1])
+
+	tokenHeaderMap, err := getServiceAccountTokenHeaderMap(cluster)
+	if err != nil {
+		return nil, wrapServiceAccountError(err)
+	}
+	cloudoneClient := backend.NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap).WithCluster(cluster)
+
+	user, err := cloudoneClient.GetUser(userName)
+	if IsTokenInvalid(err) {
+		removeServiceAccountToken(cluster)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Disabled {
+		return nil, errors.New("User " + userName + " is disabled")
+	}
+	if user.ExpiredTime != nil && time.Now().After(*user.ExpiredTime) {
+		return nil, errors.New("User " + userName + " is expired")
+	}
+
+	personalAccessTokenSlice, err := ParsePersonalAccessTokenSlice(user)
+	if err != nil {
+		return nil, err
+	}
+	var matchedPersonalAccessToken *PersonalAccessToken = nil
+	for _, personalAccessToken := range personalAccessTokenSlice {
+		if subtle.ConstantTimeCompare([]byte(personalAccessToken.HashedSecret), []byte(hashedSecret)) == 1 {
+			matchedPersonalAccessToken = personalAccessToken
+		}
+	}
+	if matchedPersonalAccessToken == nil {
+		return nil, errors.New("Personal access token doesn't exist")
+	}
+
+	// The user only keeps the role names so the permissions are resolved from the roles
+	roleSlice, err := cloudoneClient.GetRoleSlice()
+	if err != nil {
+		return nil, err
+	}
+	roleMap := make(map[string]*rbac.Role)
+	for i := range roleSlice {
+		roleMap[roleSlice[i].Name] = &roleSlice[i]
+	}
+	userRoleSlice := make([]*rbac.Role, 0)
+	for _, role := range user.RoleSlice {
+		if existingRole, ok := roleMap[role.Name]; ok {
+			userRoleSlice = append(userRoleSlice, existingRole)
+		}
+	}
+	user.RoleSlice = userRoleSlice
+
+	namespaceRoleMap, err := getNamespaceRoleMap(cloudoneClient, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedPersonalAccessToken{user, namespaceRoleMap, matchedPersonalAccessToken, time.Time{}}, nil
+}
+
+// authenticatePersonalAccessToken verifies the token of the user in the cluster, its expiration and its scope and records where it is used
+func authenticatePersonalAccessToken(cluster string, token string, method string, path string, ip string) (*cachedPersonalAccessToken, map[string]string, error) {
+	if backend.IsClusterExisting(cluster) == false {
+		return nil, nil, errors.New("Cluster " + cluster + " doesn't exist")
+	}
+
+	// The same user name could exist in different clusters
+	cacheKey := cluster + "/" + hashPersonalAccessTokenSecret(token)
+
+	cached := getCachedPersonalAccessToken(cacheKey)
+	if cached == nil {
+		var err error
+		cached, err = loadPersonalAccessToken(cluster, token)
+		if err != nil {
+			return nil, nil, err
+		}
+		cachePersonalAccessToken(cacheKey, *cached)
+	}
+
+	if cached.personalAccessToken.IsExpired() {
+		return nil, nil, errors.New("Personal access token " + cached.personalAccessToken.Name + " is expired")
+	}
+	if cached.personalAccessToken.IsInScope(method, path) == false {
+		return nil, nil, errors.New("Personal access token " + cached.personalAccessToken.Name + " is not in the scope of " + method + " " + path)
+	}
+
+	tokenHeaderMap, err := getServiceAccountTokenHeaderMap(cluster)
+	if err != nil {
+		return nil, nil, wrapServiceAccountError(err)
+	}
+
+	recordPersonalAccessTokenUsage(cached.personalAccessToken.ID, ip)
+
+	return cached, tokenHeaderMap, nil
+}
+
+// AuthenticatePersonalAccessToken returns the owner in the cluster with the global roles and the token header of the service account to relay to the backend
+func AuthenticatePersonalAccessToken(cluster string, token string, method string, path string, ip string) (*rbac.User, map[string]string, error) {
+	cached, tokenHeaderMap, err := authenticatePersonalAccessToken(cluster, token, method, path, ip)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cached.user, tokenHeaderMap, nil
+}
+
+type personalAccessTokenUsage struct {
+	Time time.Time
+	IP   string
+}
+
+func getPersonalAccessTokenUsageKey(id string) string {
+	return personalAccessTokenUsageKeyPrefix + id
+}
+
+// recordPersonalAccessTokenUsage writes the last used time and ip to the session store at most once per interval for each token.
+// They are not kept in the meta data of the user so the usage never overwrites the tokens created or revoked at the same time.
+func recordPersonalAccessTokenUsage(id string, ip string) {
+	personalAccessTokenCacheLock.Lock()
+	now := time.Now()
+	lastRecordedTime, ok := personalAccessTokenLastUsedMap[id]
+	if ok && now.Sub(lastRecordedTime) < personalAccessTokenLastUsedUpdateInterval {
+		personalAccessTokenCacheLock.Unlock()
+		return
+	}
+	personalAccessTokenLastUsedMap[id] = now
+	personalAccessTokenCacheLock.Unlock()
+
+	go func() {
+		byteSlice, err := json.Marshal(personalAccessTokenUsage{now, ip})
+		if err == nil {
+			err = sessionstore.GetStore().Set(getPersonalAccessTokenUsageKey(id), byteSlice, 0)
+		}
+		if err != nil {
+			beego.Error("Fail to record the usage of the personal access token", id, err)
+		}
+	}()
+}
+
+// LoadPersonalAccessTokenUsage fills the last used time and ip recorded in the session store
+func LoadPersonalAccessTokenUsage(personalAccessTokenSlice []*PersonalAccessToken) {
+	store := sessionstore.GetStore()
+	for _, personalAccessToken := range personalAccessTokenSlice {
+		byteSlice, err := store.Get(getPersonalAccessTokenUsageKey(personalAccessToken.ID))
+		if err != nil {
+			beego.Error("Fail to get the usage of the personal access token", personalAccessToken.ID, err)
+			continue
+		}
+		if byteSlice == nil {
+			continue
+		}
+		usage := personalAccessTokenUsage{}
+		if err := json.Unmarshal(byteSlice, &usage); err != nil {
+			continue
+		}
+		// The usage recorded in the meta data by the older version is kept if it is newer
+		if personalAccessToken.LastUsedTime == nil || usage.Time.After(*personalAccessToken.LastUsedTime) {
+			personalAccessToken.LastUsedTime = &usage.Time
+			personalAccessToken.LastUsedIP = usage.IP
+		}
+	}
+}
+
+func isPersonalAccessTokenRequest(ctx *context.Context) bool {
+	isPersonalAccessTokenRequest, _ := ctx.Input.GetData(personalAccessTokenDataName).(bool)
+	return isPersonalAccessTokenRequest
+}
+
+func outputPersonalAccessTokenError(ctx *context.Context, statusCode int, errorMessage string) {
+	ctx.Output.SetStatus(statusCode)
+	ctx.Output.JSON(guimessagedisplay.NewGUIErrorMessage(errorMessage).SetResource(ctx.Input.URL()).SetRequestID(tracing.GetRequestIDFromContext(ctx)), false, false)
+}
+
+// personalAccessTokenSession keeps the login of the request with the personal access token only in memory.
+// It is dropped when the request ends so it is never saved to the session store or sent as a cookie.
+type personalAccessTokenSession struct {
+	lock     sync.RWMutex
+	valueMap map[interface{}]interface{}
+}
+
+func (session *personalAccessTokenSession) Set(key, value interface{}) error {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+	session.valueMap[key] = value
+	return nil
+}
+
+func (session *personalAccessTokenSession) Get(key interface{}) interface{} {
+	session.lock.RLock()
+	defer session.lock.RUnlock()
+	return session.valueMap[key]
+}
+
+func (session *personalAccessTokenSession) Delete(key interface{}) error {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+	delete(session.valueMap, key)
+	return nil
+}
+
+func (session *personalAccessTokenSession) SessionID() string {
+	return ""
+}
+
+func (session *personalAccessTokenSession) SessionRelease(w http.ResponseWriter) {
+}
+
+func (session *personalAccessTokenSession) Flush() error {
+	session.lock.Lock()
+	defer session.lock.Unlock()
+	session.valueMap = make(map[interface{}]interface{})
+	return nil
+}
+
+// usePersonalAccessTokenSession replaces the session of the request with the one in memory. The session of the
+// cookie sent by the client is left untouched while the session beego started for the request without it is removed.
+func usePersonalAccessTokenSession(ctx *context.Context) {
+	if ctx.Input.CruSession != nil {
+		sessionID := ctx.Input.CruSession.SessionID()
+		sessionCookiePrefix := beego.BConfig.WebConfig.Session.SessionName + "=" + url.QueryEscape(sessionID) + ";"
+		cookieSlice := make([]string, 0)
+		for _, cookie := range ctx.ResponseWriter.Header()["Set-Cookie"] {
+			if strings.HasPrefix(cookie, sessionCookiePrefix) == false {
+				cookieSlice = append(cookieSlice, cookie)
+			}
+		}
+		if len(cookieSlice) < len(ctx.ResponseWriter.Header()["Set-Cookie"]) {
+			// The cookie is set only for the new session
+			beego.GlobalSessions.GetProvider().SessionDestroy(sessionID)
+			ctx.ResponseWriter.Header()["Set-Cookie"] = cookieSlice
+		}
+	}
+	ctx.Input.CruSession = &personalAccessTokenSession{valueMap: make(map[interface{}]interface{})}
+}
+
+// FilterPersonalAccessToken logins the request of /guirestapi with the personal access token in the header Token.
+// The session of the request is replaced with the one in memory and populated like the login so FilterUser
+// and the controllers work without change.
+func FilterPersonalAccessToken(ctx *context.Context) {
+	token := ctx.Input.Header("Token")
+	if IsPersonalAccessToken(token) == false {
+		return
+	}
+
+	usePersonalAccessTokenSession(ctx)
+
+	cluster := GetPersonalAccessTokenCluster(ctx)
+	cached, tokenHeaderMap, err := authenticatePersonalAccessToken(cluster, token, ctx.Input.Method(), ctx.Input.URL(), ctx.Input.IP())
+	if backend.IsUnavailable(err) {
+		outputPersonalAccessTokenError(ctx, 503, "Service unavailable. "+err.Error())
+		return
+	}
+	if err != nil {
+		outputPersonalAccessTokenError(ctx, 401, "Unauthorized. "+err.Error())
+		return
+	}
+
+	namespace := ctx.Input.Header(personalAccessTokenNamespaceHeaderName)
+	if namespace == "" {
+		namespace = cached.user.MetaDataMap["loginNamespace"]
+	}
+	if namespace == "" {
+		namespace = beego.AppConfig.String("namespace")
+	}
+	if IsNamespacePermitted(cached.user, namespace) == false {
+		outputPersonalAccessTokenError(ctx, 403, "Forbidden. User "+cached.user.Name+" is not authorized to namespace "+namespace)
+		return
+	}
+
+	user := getNamespaceUser(cached.user, cached.namespaceRoleMap, namespace)
+
+	if IsAuthorized(user, ctx.Input.Method(), ctx.Input.URL()) == false {
+		outputPersonalAccessTokenError(ctx, 403, "Forbidden. User "+user.Name+" is not authorized to "+ctx.Input.Method()+" "+ctx.Input.URL())
+		return
+	}
+
+	ctx.Input.SetData(personalAccessTokenDataName, true)
+	ctx.Output.Session("identityUser", cached.user)
+	ctx.Output.Session("namespaceRoleMap", cached.namespaceRoleMap)
+	ctx.Output.Session("user", user)
+	ctx.Output.Session("namespace", namespace)
+	ctx.Output.Session("username", user.Name)
+	ctx.Output.Session("tokenHeaderMap", tokenHeaderMap)
+	ctx.Output.Session(backend.SessionNameCluster, cluster)
+}
+
+// GetPersonalAccessTokenCluster returns the cluster in the header Cluster or the default one
+func GetPersonalAccessTokenCluster(ctx *context.Context) string {
+	cluster := strings.ToLower(ctx.Input.Header(personalAccessTokenClusterHeaderName))
+	if cluster == "" {
+		return backend.GetDefaultClusterName()
+	}
+	return cluster
+}
@@ -0,0 +1,264 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/astaxie/beego/session"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/fakebackend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestPersonalAccessTokenUsage(t *testing.T) {
+	sessionstore.GetStore().Delete(getPersonalAccessTokenUsageKey("usage-1"))
+	recordPersonalAccessTokenUsage("usage-1", "10.0.0.1")
+
+	Convey("Subject: Usage of the personal access token is kept in the session store\n", t, func() {
+		personalAccessTokenSlice := []*PersonalAccessToken{&PersonalAccessToken{ID: "usage-1"}}
+		for i := 0; i < 100 && personalAccessTokenSlice[0].LastUsedTime == nil; i++ {
+			time.Sleep(10 * time.Millisecond)
+			LoadPersonalAccessTokenUsage(personalAccessTokenSlice)
+		}
+		So(personalAccessTokenSlice[0].LastUsedTime, ShouldNotBeNil)
+		So(personalAccessTokenSlice[0].LastUsedIP, ShouldEqual, "10.0.0.1")
+
+		Convey("The usage in the interval is not recorded again", func() {
+			recordPersonalAccessTokenUsage("usage-1", "10.0.0.2")
+			time.Sleep(20 * time.Millisecond)
+			LoadPersonalAccessTokenUsage(personalAccessTokenSlice)
+			So(personalAccessTokenSlice[0].LastUsedIP, ShouldEqual, "10.0.0.1")
+		})
+		Convey("The newer usage in the meta data of the older version is kept", func() {
+			future := time.Now().Add(time.Hour)
+			personalAccessTokenSlice := []*PersonalAccessToken{&PersonalAccessToken{ID: "usage-1", LastUsedTime: &future, LastUsedIP: "10.0.0.3"}}
+			LoadPersonalAccessTokenUsage(personalAccessTokenSlice)
+			So(personalAccessTokenSlice[0].LastUsedIP, ShouldEqual, "10.0.0.3")
+		})
+	})
+}
+
+func TestHashPersonalAccessTokenSecret(t *testing.T) {
+	Convey("Subject: Only the hash of the secret is stored\n", t, func() {
+		hashSlice := make([]string, 0)
+		for _, secret := range []string{"secret", "secret2", "Secret", ""} {
+			hash := hashPersonalAccessTokenSecret(secret)
+			So(hash, ShouldEqual, hashPersonalAccessTokenSecret(secret))
+			So(hash, ShouldHaveLength, 64)
+			So(hashSlice, ShouldNotContain, hash)
+			hashSlice = append(hashSlice, hash)
+		}
+	})
+}
+
+func TestPersonalAccessTokenScope(t *testing.T) {
+	Convey("Subject: The personal access token is used only in its scope before it expires\n", t, func() {
+		testCaseSlice := []struct {
+			scopeSlice []string
+			method     string
+			path       string
+			inScope    bool
+		}{
+			{[]string{PersonalAccessTokenScopeAPI}, "DELETE", "/api/v1/namespaces/demo", true},
+			{[]string{PersonalAccessTokenScopeAPI}, "GET", "/guirestapi/v1/namespaces", false},
+			{[]string{PersonalAccessTokenScopeGUIRestAPIRead}, "GET", "/guirestapi/v1/namespaces", true},
+			{[]string{PersonalAccessTokenScopeGUIRestAPIRead}, "POST", "/guirestapi/v1/namespaces", false},
+			{[]string{PersonalAccessTokenScopeGUIRestAPIWrite}, "POST", "/guirestapi/v1/namespaces", true},
+			{[]string{PersonalAccessTokenScopeGUIRestAPIWrite}, "POST", "/gui/system/namespace/edit", false},
+			{[]string{PersonalAccessTokenScopeAPI, PersonalAccessTokenScopeGUIRestAPIRead}, "GET", "/guirestapi/v1/namespaces", true},
+			{[]string{}, "GET", "/api/v1/namespaces", false},
+		}
+		for _, testCase := range testCaseSlice {
+			personalAccessToken := &PersonalAccessToken{ScopeSlice: testCase.scopeSlice}
+			So(personalAccessToken.IsInScope(testCase.method, testCase.path), ShouldEqual, testCase.inScope)
+		}
+
+		past := time.Now().Add(-time.Second)
+		future := time.Now().Add(time.Hour)
+		So((&PersonalAccessToken{ExpiredTime: &past}).IsExpired(), ShouldBeTrue)
+		So((&PersonalAccessToken{ExpiredTime: &future}).IsExpired(), ShouldBeFalse)
+		So((&PersonalAccessToken{}).IsExpired(), ShouldBeFalse)
+	})
+}
+
+// useFakeBackend points the default cluster to the fake backend and logins the service account to it
+func useFakeBackend() (*httptest.Server, *backend.Client) {
+	server := httptest.NewServer(fakebackend.New())
+	host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+	for key, value := range map[string]string{
+		"cloudoneProtocol":               "http",
+		"cloudoneHost":                   host,
+		"cloudonePort":                   port,
+		"identityServiceAccountUsername": fakebackend.DemoUserName,
+		"identityServiceAccountPassword": fakebackend.DemoPassword,
+	} {
+		beego.AppConfig.Set(key, value)
+	}
+	removeServiceAccountToken(backend.GetDefaultClusterName())
+	tokenHeaderMap, err := getServiceAccountTokenHeaderMap(backend.GetDefaultClusterName())
+	So(err, ShouldBeNil)
+	return server, backend.NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap)
+}
+
+func TestPersonalAccessTokenRevocation(t *testing.T) {
+	Convey("Subject: The personal access token is hashed and stops working when revoked\n", t, func() {
+		server, cloudoneClient := useFakeBackend()
+		defer server.Close()
+		cluster := backend.GetDefaultClusterName()
+
+		token, err := CreatePersonalAccessToken(cloudoneClient, fakebackend.DemoUserName, "ci", []string{PersonalAccessTokenScopeAPI}, nil)
+		So(err, ShouldBeNil)
+		So(IsPersonalAccessToken(token), ShouldBeTrue)
+		secret := token[strings.LastIndex(token, ".")+1:]
+
+		user, err := cloudoneClient.GetUser(fakebackend.DemoUserName)
+		So(err, ShouldBeNil)
+		So(user.MetaDataMap[PersonalAccessTokenMetaDataKey], ShouldNotContainSubstring, secret)
+		personalAccessTokenSlice, err := ParsePersonalAccessTokenSlice(user)
+		So(err, ShouldBeNil)
+		So(len(personalAccessTokenSlice), ShouldEqual, 1)
+		So(personalAccessTokenSlice[0].HashedSecret, ShouldEqual, hashPersonalAccessTokenSecret(secret))
+		id := personalAccessTokenSlice[0].ID
+
+		testCaseSlice := []struct {
+			description   string
+			token         string
+			method        string
+			path          string
+			authenticated bool
+		}{
+			{"The token in the scope", token, "GET", "/api/v1/namespaces", true},
+			{"The token out of the scope", token, "GET", "/guirestapi/v1/namespaces", false},
+			{"The token with the wrong secret", token[:len(token)-1] + "x", "GET", "/api/v1/namespaces", false},
+			{"The token of another user", strings.Replace(token, token[len("cpat."):strings.LastIndex(token, ".")], "b3RoZXI", 1), "GET", "/api/v1/namespaces", false},
+			{"The malformed token", "cpat.malformed", "GET", "/api/v1/namespaces", false},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				authenticatedUser, _, err := AuthenticatePersonalAccessToken(cluster, testCase.token, testCase.method, testCase.path, "10.0.0.1")
+				So(err == nil, ShouldEqual, testCase.authenticated)
+				if testCase.authenticated {
+					So(authenticatedUser.Name, ShouldEqual, fakebackend.DemoUserName)
+				}
+			})
+		}
+
+		Convey("The token with the same name is not created", func() {
+			_, err := CreatePersonalAccessToken(cloudoneClient, fakebackend.DemoUserName, "ci", []string{PersonalAccessTokenScopeAPI}, nil)
+			So(err, ShouldNotBeNil)
+		})
+
+		Convey("The revoked token is rejected even if it is cached", func() {
+			_, _, err := AuthenticatePersonalAccessToken(cluster, token, "GET", "/api/v1/namespaces", "10.0.0.1")
+			So(err, ShouldBeNil)
+
+			So(RevokePersonalAccessToken(cloudoneClient, fakebackend.DemoUserName, id), ShouldBeNil)
+			_, _, err = AuthenticatePersonalAccessToken(cluster, token, "GET", "/api/v1/namespaces", "10.0.0.1")
+			So(err, ShouldNotBeNil)
+
+			user, err := cloudoneClient.GetUser(fakebackend.DemoUserName)
+			So(err, ShouldBeNil)
+			_, ok := user.MetaDataMap[PersonalAccessTokenMetaDataKey]
+			So(ok, ShouldBeFalse)
+			value, err := sessionstore.GetStore().Get(getPersonalAccessTokenUsageKey(id))
+			So(err, ShouldBeNil)
+			So(value, ShouldBeNil)
+
+			So(RevokePersonalAccessToken(cloudoneClient, fakebackend.DemoUserName, id), ShouldNotBeNil)
+		})
+	})
+}
+
+func TestCreatePersonalAccessTokenValidation(t *testing.T) {
+	Convey("Subject: The personal access token needs the name and the supported scopes\n", t, func() {
+		testCaseSlice := []struct {
+			name       string
+			scopeSlice []string
+		}{
+			{"", []string{PersonalAccessTokenScopeAPI}},
+			{"ci", []string{}},
+			{"ci", []string{"admin"}},
+		}
+		for _, testCase := range testCaseSlice {
+			// The validation fails before the backend is used
+			_, err := CreatePersonalAccessToken(nil, fakebackend.DemoUserName, testCase.name, testCase.scopeSlice, nil)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestPersonalAccessTokenSession(t *testing.T) {
+	Convey("Subject: The request with the personal access token never touches the cookie session\n", t, func() {
+		manager, err := session.NewManager("memory", &session.ManagerConfig{CookieName: beego.BConfig.WebConfig.Session.SessionName, EnableSetCookie: true, Gclifetime: 3600})
+		So(err, ShouldBeNil)
+		originalGlobalSessions := beego.GlobalSessions
+		beego.GlobalSessions = manager
+		defer func() {
+			beego.GlobalSessions = originalGlobalSessions
+		}()
+
+		startSession := func(cookieSlice []*http.Cookie) (*context.Context, *httptest.ResponseRecorder) {
+			request := httptest.NewRequest("GET", "http://gui.example/guirestapi/v1/namespaces", nil)
+			for _, cookie := range cookieSlice {
+				request.AddCookie(cookie)
+			}
+			recorder := httptest.NewRecorder()
+			ctx := context.NewContext()
+			ctx.Reset(recorder, request)
+			ctx.Input.CruSession, err = manager.SessionStart(recorder, request)
+			So(err, ShouldBeNil)
+			return ctx, recorder
+		}
+
+		Convey("The cookie session is kept as it is", func() {
+			loginCtx, loginRecorder := startSession(nil)
+			loginCtx.Input.CruSession.Set("username", "operator")
+			loginCtx.Input.CruSession.SessionRelease(loginRecorder)
+			sessionID := loginCtx.Input.CruSession.SessionID()
+
+			ctx, recorder := startSession(loginRecorder.Result().Cookies())
+			So(ctx.Input.CruSession.SessionID(), ShouldEqual, sessionID)
+			usePersonalAccessTokenSession(ctx)
+			ctx.Input.CruSession.Set("username", "automation")
+			ctx.Input.CruSession.SessionRelease(recorder)
+
+			So(manager.GetProvider().SessionExist(sessionID), ShouldBeTrue)
+			cookieSession, err := manager.GetProvider().SessionRead(sessionID)
+			So(err, ShouldBeNil)
+			So(cookieSession.Get("username"), ShouldEqual, "operator")
+			So(recorder.Header().Get("Set-Cookie"), ShouldBeEmpty)
+		})
+
+		Convey("The session started for the request without cookie is removed", func() {
+			ctx, recorder := startSession(nil)
+			sessionID := ctx.Input.CruSession.SessionID()
+			So(recorder.Header().Get("Set-Cookie"), ShouldNotBeEmpty)
+			usePersonalAccessTokenSession(ctx)
+
+			So(ctx.Input.CruSession.SessionID(), ShouldNotEqual, sessionID)
+			So(manager.GetProvider().SessionExist(sessionID), ShouldBeFalse)
+			So(recorder.Header().Get("Set-Cookie"), ShouldBeEmpty)
+		})
+	})
+}
@@ -0,0 +1,78 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"sync"
+	"time"
+)
+
+const (
+	defaultServiceAccountTokenTTLInSecond = 300
+)
+
+type serviceAccountToken struct {
+	tokenHeaderMap map[string]string
+	expiredTime    time.Time
+}
+
+// The service account logins each cluster separately
+var serviceAccountTokenMap = make(map[string]serviceAccountToken)
+var serviceAccountTokenLock = sync.Mutex{}
+
+// createServiceAccountTokenHeaderMap logins the service account used to access the backend of the cluster for the users not in the backend
+func createServiceAccountTokenHeaderMap(cluster string) (map[string]string, error) {
+	userData := backend.UserData{
+		Username: beego.AppConfig.String("identityServiceAccountUsername"),
+		Password: beego.AppConfig.String("identityServiceAccountPassword"),
+	}
+	token, err := backend.NewCloudoneClientWithTokenHeaderMap(nil).WithCluster(cluster).CreateToken(userData)
+	if err != nil {
+		return nil, err
+	}
+
+	headerMap := make(map[string]string)
+	headerMap["token"] = token
+	return headerMap, nil
+}
+
+// getServiceAccountTokenHeaderMap reuses the token of the service account for the requests without session
+func getServiceAccountTokenHeaderMap(cluster string) (map[string]string, error) {
+	serviceAccountTokenLock.Lock()
+	defer serviceAccountTokenLock.Unlock()
+
+	if token, ok := serviceAccountTokenMap[cluster]; ok && time.Now().Before(token.expiredTime) {
+		return token.tokenHeaderMap, nil
+	}
+
+	headerMap, err := createServiceAccountTokenHeaderMap(cluster)
+	if err != nil {
+		return nil, err
+	}
+
+	ttl := time.Duration(beego.AppConfig.DefaultInt("identityServiceAccountTokenTTLInSecond", defaultServiceAccountTokenTTLInSecond)) * time.Second
+	serviceAccountTokenMap[cluster] = serviceAccountToken{headerMap, time.Now().Add(ttl)}
+	return headerMap, nil
+}
+
+// removeServiceAccountToken is used when the backend rejects the cached token
+func removeServiceAccountToken(cluster string) {
+	serviceAccountTokenLock.Lock()
+	defer serviceAccountTokenLock.Unlock()
+
+	delete(serviceAccountTokenMap, cluster)
+}
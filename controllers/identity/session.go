@@ -0,0 +1,292 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/random"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ActiveSession is the GUI login shown to the administrator.
+// ID is not the session id so the listing can't be used to take over the session.
+type ActiveSession struct {
+	ID               string
+	UserName         string
+	IP               string
+	Cluster          string
+	LoginTime        time.Time
+	LastActivityTime time.Time
+	RoleNameSlice    []string
+	sessionID        string
+}
+
+// IsSession is used to mark the session of the request itself
+func (activeSession *ActiveSession) IsSession(sessionID string) bool {
+	return activeSession.sessionID == sessionID
+}
+
+type ByActiveSession []ActiveSession
+
+func (b ByActiveSession) Len() int      { return len(b) }
+func (b ByActiveSession) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b ByActiveSession) Less(i, j int) bool {
+	if b[i].UserName != b[j].UserName {
+		return b[i].UserName < b[j].UserName
+	}
+	return b[i].LoginTime.Before(b[j].LoginTime)
+}
+
+const (
+	activeSessionKeyPrefix     = "activesession/"
+	activeSessionTouchInterval = 30 * time.Second
+)
+
+// sessionTouch is the last activity written to the session store by this replica
+type sessionTouch struct {
+	time    time.Time
+	ip      string
+	cluster string
+}
+
+var sessionTouchMap = make(map[string]sessionTouch)
+var sessionTouchLock = sync.Mutex{}
+
+func init() {
+	// The session store shared by the replicas decodes these types kept in the session
+	gob.Register(&rbac.User{})
+	gob.Register(map[string][]*rbac.Role{})
+	gob.Register(map[string]*ClusterLogin{})
+	gob.Register(time.Time{})
+}
+
+// storedActiveSession keeps the session id which is hidden from the listing
+type storedActiveSession struct {
+	ActiveSession
+	SessionID string
+}
+
+// The sessions are listed in the session store so every replica sees the logins of the others
+func saveActiveSession(activeSession *ActiveSession) {
+	byteSlice, err := json.Marshal(storedActiveSession{*activeSession, activeSession.sessionID})
+	if err != nil {
+		beego.Error("Fail to encode the active session", activeSession.UserName, err)
+		return
+	}
+	err = sessionstore.GetStore().Set(activeSessionKeyPrefix+activeSession.sessionID, byteSlice, sessionstore.GetSessionLifetime())
+	if err != nil {
+		beego.Error("Fail to save the active session", activeSession.UserName, err)
+	}
+}
+
+func getActiveSession(sessionID string) *ActiveSession {
+	byteSlice, err := sessionstore.GetStore().Get(activeSessionKeyPrefix + sessionID)
+	if err != nil {
+		beego.Error("Fail to get the active session", err)
+		return nil
+	}
+	if byteSlice == nil {
+		return nil
+	}
+	stored := storedActiveSession{}
+	if err := json.Unmarshal(byteSlice, &stored); err != nil {
+		beego.Error("Fail to decode the active session", err)
+		return nil
+	}
+	activeSession := stored.ActiveSession
+	activeSession.sessionID = stored.SessionID
+	return &activeSession
+}
+
+// getRoleNameSlice includes the roles granted in the namespaces so the session is found when any of them changes
+func getRoleNameSlice(user *rbac.User, namespaceRoleMap map[string][]*rbac.Role) []string {
+	roleNameSlice := make([]string, 0)
+	for _, role := range user.RoleSlice {
+		roleNameSlice = append(roleNameSlice, role.Name)
+	}
+	for _, roleSlice := range namespaceRoleMap {
+		for _, role := range roleSlice {
+			roleNameSlice = append(roleNameSlice, role.Name)
+		}
+	}
+	return roleNameSlice
+}
+
+// registerSession records the login. It is also used for the session created before the GUI restarts.
+func registerSession(ctx *context.Context, loginTime time.Time) {
+	if ctx.Input.CruSession == nil {
+		return
+	}
+	identityUser, ok := ctx.Input.Session("identityUser").(*rbac.User)
+	if ok == false {
+		return
+	}
+	namespaceRoleMap, _ := ctx.Input.Session("namespaceRoleMap").(map[string][]*rbac.Role)
+
+	saveActiveSession(&ActiveSession{
+		random.UUID(),
+		identityUser.Name,
+		ctx.Input.IP(),
+		backend.GetClusterName(ctx),
+		loginTime,
+		time.Now(),
+		getRoleNameSlice(identityUser, namespaceRoleMap),
+		ctx.Input.CruSession.SessionID(),
+	})
+}
+
+// touchSession updates the last activity of the session used by the request
+func touchSession(ctx *context.Context) {
+	if ctx.Input.CruSession == nil {
+		return
+	}
+
+	sessionID := ctx.Input.CruSession.SessionID()
+	touch := sessionTouch{time.Now(), ctx.Input.IP(), backend.GetClusterName(ctx)}
+	if isSessionTouchSkipped(sessionID, touch) {
+		return
+	}
+
+	activeSession := getActiveSession(sessionID)
+	if activeSession != nil {
+		activeSession.LastActivityTime = touch.time
+		activeSession.IP = touch.ip
+		activeSession.Cluster = touch.cluster
+		saveActiveSession(activeSession)
+	} else {
+		loginTime, ok := ctx.Input.Session("loginTime").(time.Time)
+		if ok == false {
+			loginTime = time.Now()
+		}
+		registerSession(ctx, loginTime)
+	}
+}
+
+// isSessionTouchSkipped writes the session at most once per interval unless the ip or the cluster changes
+// since every write reads the session store and grants a new lease on etcd.
+func isSessionTouchSkipped(sessionID string, touch sessionTouch) bool {
+	sessionTouchLock.Lock()
+	defer sessionTouchLock.Unlock()
+
+	lastTouch, ok := sessionTouchMap[sessionID]
+	if ok && touch.time.Sub(lastTouch.time) < activeSessionTouchInterval && touch.ip == lastTouch.ip && touch.cluster == lastTouch.cluster {
+		return true
+	}
+	// The sessions expired or logged out on the other replicas are never unregistered here
+	for key, value := range sessionTouchMap {
+		if touch.time.Sub(value.time) > sessionstore.GetSessionLifetime() {
+			delete(sessionTouchMap, key)
+		}
+	}
+	sessionTouchMap[sessionID] = touch
+	return false
+}
+
+func unregisterSession(ctx *context.Context) {
+	if ctx.Input.CruSession == nil {
+		return
+	}
+
+	sessionTouchLock.Lock()
+	delete(sessionTouchMap, ctx.Input.CruSession.SessionID())
+	sessionTouchLock.Unlock()
+
+	if err := sessionstore.GetStore().Delete(activeSessionKeyPrefix + ctx.Input.CruSession.SessionID()); err != nil {
+		beego.Error("Fail to delete the active session", err)
+	}
+}
+
+// getActiveSessionSlice includes the session id which is not exported
+func getActiveSessionSlice() []ActiveSession {
+	keySlice, err := sessionstore.GetStore().List(activeSessionKeyPrefix)
+	if err != nil {
+		beego.Error("Fail to list the active sessions", err)
+		return make([]ActiveSession, 0)
+	}
+
+	activeSessionSlice := make([]ActiveSession, 0)
+	for _, key := range keySlice {
+		activeSession := getActiveSession(strings.TrimPrefix(key, activeSessionKeyPrefix))
+		if activeSession == nil {
+			continue
+		}
+		// The session is removed by the garbage collection of the provider after it expires
+		if beego.GlobalSessions.GetProvider().SessionExist(activeSession.sessionID) == false {
+			sessionstore.GetStore().Delete(key)
+			continue
+		}
+		activeSessionSlice = append(activeSessionSlice, *activeSession)
+	}
+	return activeSessionSlice
+}
+
+// GetActiveSessionSlice returns the sessions still kept by the session provider
+func GetActiveSessionSlice() []ActiveSession {
+	activeSessionSlice := getActiveSessionSlice()
+	sort.Sort(ByActiveSession(activeSessionSlice))
+	return activeSessionSlice
+}
+
+func destroySession(sessionID string) error {
+	sessionstore.GetStore().Delete(activeSessionKeyPrefix + sessionID)
+	return beego.GlobalSessions.GetProvider().SessionDestroy(sessionID)
+}
+
+// RevokeSession logouts the session with the ID of ActiveSession
+func RevokeSession(id string) error {
+	for _, activeSession := range getActiveSessionSlice() {
+		if activeSession.ID == id {
+			return destroySession(activeSession.sessionID)
+		}
+	}
+	return errors.New("Session " + id + " doesn't exist")
+}
+
+// RevokeUserSession logouts all sessions of the user and returns the amount
+func RevokeUserSession(userName string) int {
+	amount := 0
+	for _, activeSession := range getActiveSessionSlice() {
+		if activeSession.UserName == userName {
+			destroySession(activeSession.sessionID)
+			amount++
+		}
+	}
+	return amount
+}
+
+// RevokeRoleSession logouts all sessions with the role and returns the amount since the role is copied into the session when login
+func RevokeRoleSession(roleName string) int {
+	amount := 0
+	for _, activeSession := range getActiveSessionSlice() {
+		for _, activeRoleName := range activeSession.RoleNameSlice {
+			if activeRoleName == roleName {
+				destroySession(activeSession.sessionID)
+				amount++
+				break
+			}
+		}
+	}
+	return amount
+}
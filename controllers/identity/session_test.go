@@ -0,0 +1,99 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/astaxie/beego/session"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestRevokeSession(t *testing.T) {
+	Convey("Subject: The sessions of the changed user or role are revoked\n", t, func() {
+		manager, err := session.NewManager("memory", &session.ManagerConfig{CookieName: beego.BConfig.WebConfig.Session.SessionName, Gclifetime: 3600})
+		So(err, ShouldBeNil)
+		originalGlobalSessions := beego.GlobalSessions
+		beego.GlobalSessions = manager
+		defer func() {
+			beego.GlobalSessions = originalGlobalSessions
+		}()
+
+		// login starts the session of the user and registers it like the login page
+		login := func(userName string, roleName string, namespaceRoleName string) string {
+			request := httptest.NewRequest("POST", "http://gui.example/gui/login", nil)
+			recorder := httptest.NewRecorder()
+			ctx := context.NewContext()
+			ctx.Reset(recorder, request)
+			ctx.Input.CruSession, err = manager.SessionStart(recorder, request)
+			So(err, ShouldBeNil)
+			ctx.Input.CruSession.Set("identityUser", &rbac.User{Name: userName, RoleSlice: []*rbac.Role{&rbac.Role{Name: roleName}}})
+			ctx.Input.CruSession.Set("namespaceRoleMap", map[string][]*rbac.Role{"staging": []*rbac.Role{&rbac.Role{Name: namespaceRoleName}}})
+			registerSession(ctx, time.Now())
+			return ctx.Input.CruSession.SessionID()
+		}
+
+		isActive := func(sessionID string) bool {
+			for _, activeSession := range GetActiveSessionSlice() {
+				if activeSession.IsSession(sessionID) {
+					return true
+				}
+			}
+			return false
+		}
+
+		Convey("The sessions of the user are revoked", func() {
+			firstSessionID := login("revoke_user", "revoke_developer", "revoke_deployer")
+			secondSessionID := login("revoke_user", "revoke_developer", "revoke_deployer")
+			otherSessionID := login("revoke_other_user", "revoke_developer", "revoke_deployer")
+
+			So(RevokeUserSession("revoke_user"), ShouldEqual, 2)
+			for _, sessionID := range []string{firstSessionID, secondSessionID} {
+				So(manager.GetProvider().SessionExist(sessionID), ShouldBeFalse)
+				So(isActive(sessionID), ShouldBeFalse)
+			}
+			So(manager.GetProvider().SessionExist(otherSessionID), ShouldBeTrue)
+			So(isActive(otherSessionID), ShouldBeTrue)
+			So(RevokeUserSession("revoke_user"), ShouldEqual, 0)
+			So(RevokeUserSession("revoke_other_user"), ShouldEqual, 1)
+		})
+
+		Convey("The sessions with the role are revoked including the role granted in the namespace", func() {
+			developerSessionID := login("revoke_developer_user", "revoke_developer", "revoke_viewer")
+			deployerSessionID := login("revoke_deployer_user", "revoke_operator", "revoke_deployer")
+			otherSessionID := login("revoke_operator_user", "revoke_operator", "revoke_viewer")
+
+			So(RevokeRoleSession("revoke_developer"), ShouldEqual, 1)
+			So(RevokeRoleSession("revoke_deployer"), ShouldEqual, 1)
+			So(manager.GetProvider().SessionExist(developerSessionID), ShouldBeFalse)
+			So(manager.GetProvider().SessionExist(deployerSessionID), ShouldBeFalse)
+			So(manager.GetProvider().SessionExist(otherSessionID), ShouldBeTrue)
+			So(RevokeRoleSession("revoke_viewer"), ShouldEqual, 1)
+			So(isActive(otherSessionID), ShouldBeFalse)
+		})
+
+		Convey("The session expired in the provider is not listed", func() {
+			sessionID := login("revoke_expired_user", "revoke_developer", "revoke_viewer")
+			So(manager.GetProvider().SessionDestroy(sessionID), ShouldBeNil)
+			So(isActive(sessionID), ShouldBeFalse)
+			So(RevokeUserSession("revoke_expired_user"), ShouldEqual, 0)
+		})
+	})
+}
@@ -52,7 +52,9 @@ func IsTokenInvalidAndRedirect(c guimessagedisplay.SessionUtility, ctx *context.
 		guimessage.AddDanger("User token is expired. Please login agin.")
 		guimessage.RedirectMessage(c)
 
+		removeClusterLogin(c)
 		c.DelSession("user")
+		c.DelSession("identityUser")
 		c.DelSession("tokenHeaderMap")
 
 		ctx.Redirect(302, "/gui/login/")
@@ -0,0 +1,108 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"time"
+)
+
+const (
+	defaultWebSocketTicketTTLInSecond = 30
+	websocketTicketKeyPrefix          = "websocketticket/"
+)
+
+// websocketTicket is a single use credential for the websocket. The browser can't set the header of
+// the websocket so the page gets the ticket instead of the token and the server exchanges it back.
+// It is kept in the session store since the websocket may connect to another replica.
+type websocketTicket struct {
+	SessionID      string
+	Target         string
+	TokenHeaderMap map[string]string
+	Cluster        string
+}
+
+func getWebSocketTicketTTL() time.Duration {
+	return time.Duration(beego.AppConfig.DefaultInt("websocketTicketTTLInSecond", defaultWebSocketTicketTTLInSecond)) * time.Second
+}
+
+// CreateWebSocketTicket issues the ticket bound to the session and the target
+func CreateWebSocketTicket(ctx *context.Context, target string) (string, error) {
+	if ctx.Input.CruSession == nil {
+		return "", errors.New("Session doesn't exist")
+	}
+
+	tokenHeaderMap, _ := ctx.Input.Session("tokenHeaderMap").(map[string]string)
+	if tokenHeaderMap == nil {
+		return "", errors.New("User is not logined")
+	}
+
+	ticket, err := createRandomToken()
+	if err != nil {
+		return "", err
+	}
+
+	byteSlice, err := json.Marshal(websocketTicket{
+		ctx.Input.CruSession.SessionID(),
+		target,
+		tokenHeaderMap,
+		backend.GetClusterName(ctx),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The ticket never used expires in the store
+	err = sessionstore.GetStore().Set(websocketTicketKeyPrefix+ticket, byteSlice, getWebSocketTicketTTL())
+	if err != nil {
+		return "", err
+	}
+
+	return ticket, nil
+}
+
+// ExchangeWebSocketTicket consumes the ticket and returns the token header map and the cluster of the session issuing it
+func ExchangeWebSocketTicket(ctx *context.Context, ticket string, target string) (map[string]string, string, error) {
+	if ticket == "" {
+		return nil, "", errors.New("Ticket doesn't exist or is used")
+	}
+
+	// Single use
+	byteSlice, err := sessionstore.GetStore().Take(websocketTicketKeyPrefix + ticket)
+	if err != nil {
+		return nil, "", err
+	}
+	if byteSlice == nil {
+		return nil, "", errors.New("Ticket doesn't exist, is used or is expired")
+	}
+
+	existingTicket := websocketTicket{}
+	if err := json.Unmarshal(byteSlice, &existingTicket); err != nil {
+		return nil, "", err
+	}
+	if ctx.Input.CruSession == nil || ctx.Input.CruSession.SessionID() != existingTicket.SessionID {
+		return nil, "", errors.New("Ticket is not issued to this session")
+	}
+	if existingTicket.Target != target {
+		return nil, "", errors.New("Ticket is not issued to this target")
+	}
+
+	return existingTicket.TokenHeaderMap, existingTicket.Cluster, nil
+}
@@ -0,0 +1,113 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testTokenHeaderMap = map[string]string{"token": "token-of-alice"}
+
+func newTestWebSocketContext(sessionID string) *context.Context {
+	session := newTestSession(sessionID)
+	session.Set("tokenHeaderMap", testTokenHeaderMap)
+	ctx, _ := newTestContext("GET", "/gui/websocket", url.Values{}, nil, session)
+	return ctx
+}
+
+func TestWebSocketTicket(t *testing.T) {
+	Convey("Subject: The websocket ticket is used once by the session issuing it\n", t, func() {
+		// Each case runs with a new ticket since the subject runs again for each
+		ctx := newTestWebSocketContext("session")
+		ticket, err := CreateWebSocketTicket(ctx, "terminal")
+		So(err, ShouldBeNil)
+		So(ticket, ShouldNotBeEmpty)
+
+		testCaseSlice := []struct {
+			description string
+			ticket      string
+			sessionID   string
+			target      string
+			exchanged   bool
+		}{
+			{"The empty ticket", "", "session", "terminal", false},
+			{"The unknown ticket", "unknown", "session", "terminal", false},
+			{"The ticket of another target", ticket, "session", "upgrade", false},
+			{"The ticket of another session", ticket, "another", "terminal", false},
+			{"The ticket of the session and the target", ticket, "session", "terminal", true},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				tokenHeaderMap, cluster, err := ExchangeWebSocketTicket(newTestWebSocketContext(testCase.sessionID), testCase.ticket, testCase.target)
+				So(err == nil, ShouldEqual, testCase.exchanged)
+				if testCase.exchanged {
+					So(tokenHeaderMap, ShouldResemble, testTokenHeaderMap)
+					So(cluster, ShouldNotBeEmpty)
+				}
+
+				// The ticket is consumed by the first exchange even if it is rejected
+				_, _, err = ExchangeWebSocketTicket(ctx, testCase.ticket, "terminal")
+				So(err, ShouldNotBeNil)
+			})
+		}
+
+		Convey("Only one of the concurrent exchanges succeeds", func() {
+			waitGroup := sync.WaitGroup{}
+			lock := sync.Mutex{}
+			exchangedAmount := 0
+			for i := 0; i < 20; i++ {
+				waitGroup.Add(1)
+				go func() {
+					defer waitGroup.Done()
+					if _, _, err := ExchangeWebSocketTicket(newTestWebSocketContext("session"), ticket, "terminal"); err == nil {
+						lock.Lock()
+						exchangedAmount++
+						lock.Unlock()
+					}
+				}()
+			}
+			waitGroup.Wait()
+			So(exchangedAmount, ShouldEqual, 1)
+		})
+
+		Convey("The session not logined gets no ticket", func() {
+			ctx, _ := newTestContext("GET", "/gui/websocket", url.Values{}, nil, newTestSession("session"))
+			_, err := CreateWebSocketTicket(ctx, "terminal")
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestWebSocketTicketExpiry(t *testing.T) {
+	beego.AppConfig.Set("websocketTicketTTLInSecond", "1")
+	defer beego.AppConfig.Set("websocketTicketTTLInSecond", "")
+
+	Convey("Subject: The websocket ticket never used expires\n", t, func() {
+		ctx := newTestWebSocketContext("session")
+		ticket, err := CreateWebSocketTicket(ctx, "terminal")
+		So(err, ShouldBeNil)
+
+		time.Sleep(1100 * time.Millisecond)
+		_, _, err = ExchangeWebSocketTicket(ctx, ticket, "terminal")
+		So(err, ShouldNotBeNil)
+	})
+}
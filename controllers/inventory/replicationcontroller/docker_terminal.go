@@ -17,10 +17,12 @@ package replicationcontroller
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/shutdown"
 	"github.com/cloudawan/cloudone_utility/ioutility"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"github.com/cloudawan/cloudone_utility/sshclient"
 	"golang.org/x/net/websocket"
 	"io"
@@ -44,8 +46,10 @@ func (c *TerminalController) Get() {
 	hostIP := c.GetString("hostIP")
 	containerID := c.GetString("containerID")
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-	token, _ := tokenHeaderMap["token"]
+	ticket, err := identity.CreateWebSocketTicket(c.Ctx, getTerminalTicketTarget(hostIP, containerID))
+	if err != nil {
+		guimessage.AddError(err)
+	}
 
 	c.Data["cloudoneGUIHost"] = cloudoneGUIHost
 	c.Data["cloudoneGUIPort"] = cloudoneGUIPort
@@ -53,42 +57,50 @@ func (c *TerminalController) Get() {
 	c.Data["hostIP"] = hostIP
 	c.Data["containerID"] = containerID
 
-	c.Data["token"] = token
+	c.Data["ticket"] = ticket
 
 	guimessage.OutputMessage(c.Data)
 }
 
-type Credential struct {
-	IP  string
-	SSH SSH
-}
-
-type SSH struct {
-	Port     int
-	User     string
-	Password string
-}
-
 type WebSocketController struct {
 	beego.Controller
 }
 
+func getTerminalTicketTarget(hostIP string, containerID string) string {
+	return "dockerterminal/" + hostIP + "/" + containerID
+}
+
 func (c *WebSocketController) Get() {
-	server := websocket.Server{Handler: ProxyServer}
+	hostIP := c.GetString("hostIP")
+	containerID := c.GetString("containerID")
+
+	tokenHeaderMap, cluster, err := identity.ExchangeWebSocketTicket(c.Ctx, c.GetString("ticket"), getTerminalTicketTarget(hostIP, containerID))
+	if err != nil {
+		c.Ctx.Output.SetStatus(403)
+		c.Ctx.Output.Body([]byte(err.Error()))
+		return
+	}
+
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer metrics.StartWebSocketSession(metrics.WebSocketKindTerminal)()
+		endWebSocketSession, err := shutdown.StartWebSocketSession(ws, metrics.WebSocketKindTerminal, true)
+		if err != nil {
+			ws.Write([]byte(err.Error() + "\n"))
+			ws.Close()
+			return
+		}
+		defer endWebSocketSession()
+		ProxyServer(ws, cluster, tokenHeaderMap)
+	}}
 	server.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
 }
 
-func ProxyServer(ws *websocket.Conn) {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
+func ProxyServer(ws *websocket.Conn, cluster string, tokenHeaderMap map[string]string) {
 	parameterMap := ws.Request().URL.Query()
 	widthSlice := parameterMap["width"]
 	heightSlice := parameterMap["height"]
 	hostIPSlice := parameterMap["hostIP"]
 	containerIDSlice := parameterMap["containerID"]
-	tokenSlice := parameterMap["token"]
 
 	if len(widthSlice) != 1 {
 		errorMessage := "Parameter width is incorrect"
@@ -136,22 +148,7 @@ func ProxyServer(ws *websocket.Conn) {
 	// Remove docker protocol prefix docker://
 	containerID = containerID[9:]
 
-	if len(tokenSlice) != 1 {
-		errorMessage := "Parameter token is incorrect"
-		ws.Write([]byte(errorMessage))
-		ws.Close()
-		return
-	}
-	token := tokenSlice[0]
-	headerMap := make(map[string]string)
-	headerMap["token"] = token
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/hosts/credentials/" + hostIP
-
-	credential := Credential{}
-
-	_, err = restclient.RequestGetWithStructure(url, &credential, headerMap)
+	credential, err := backend.NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap).WithCluster(cluster).GetHostCredential(hostIP)
 
 	if identity.IsTokenInvalid(err) {
 		ws.Write([]byte(err.Error()))
@@ -17,8 +17,8 @@ package replicationcontroller
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
@@ -46,41 +46,9 @@ func (c *EditController) Get() {
 	guimessage.OutputMessage(c.Data)
 }
 
-type ReplicationController struct {
-	Name           string
-	ReplicaAmount  int
-	Selector       ReplicationControllerSelector
-	Label          ReplicationControllerLabel
-	ContainerSlice []ReplicationControllerContainer
-}
-
-type ReplicationControllerSelector struct {
-	Name    string
-	Version string
-}
-
-type ReplicationControllerLabel struct {
-	Name string
-}
-
-type ReplicationControllerContainer struct {
-	Name      string
-	Image     string
-	PortSlice []ReplicationControllerContainerPort
-}
-
-type ReplicationControllerContainerPort struct {
-	Name          string
-	ContainerPort int
-}
-
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	selectorName := c.GetString("name")
@@ -93,28 +61,23 @@ func (c *EditController) Post() {
 
 	portName := "generated"
 
-	replicationControllerContainerPortSlice := make([]ReplicationControllerContainerPort, 0)
-	replicationControllerContainerPortSlice = append(replicationControllerContainerPortSlice, ReplicationControllerContainerPort{portName, containerPort})
-	replicationControllerContainerSlice := make([]ReplicationControllerContainer, 0)
-	replicationControllerContainerSlice = append(replicationControllerContainerSlice, ReplicationControllerContainer{name, image, replicationControllerContainerPortSlice})
-	replicationController := ReplicationController{
-		name,
-		replicaAmount,
-		ReplicationControllerSelector{
-			selectorName,
-			version,
+	replicationControllerContainerPortSlice := make([]backend.ReplicationControllerContainerPort, 0)
+	replicationControllerContainerPortSlice = append(replicationControllerContainerPortSlice, backend.ReplicationControllerContainerPort{Name: portName, ContainerPort: containerPort})
+	replicationControllerContainerSlice := make([]backend.ReplicationControllerContainer, 0)
+	replicationControllerContainerSlice = append(replicationControllerContainerSlice, backend.ReplicationControllerContainer{Name: name, Image: image, PortSlice: replicationControllerContainerPortSlice, EnvironmentSlice: nil})
+	replicationController := backend.ReplicationController{
+		Name:          name,
+		ReplicaAmount: replicaAmount,
+		Selector: backend.ReplicationControllerSelector{
+			Name:    selectorName,
+			Version: version,
 		},
-		ReplicationControllerLabel{
-			name,
+		Label: backend.ReplicationControllerLabel{
+			Name: name,
 		},
-		replicationControllerContainerSlice}
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+		ContainerSlice: replicationControllerContainerSlice}
 
-	_, err = restclient.RequestPostWithStructure(url, replicationController, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).CreateReplicationController(namespace, replicationController)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -122,7 +85,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Replication Controller " + name + " is edited")
 	}
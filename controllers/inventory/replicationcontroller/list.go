@@ -17,9 +17,9 @@ package replicationcontroller
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type ListController struct {
@@ -27,13 +27,7 @@ type ListController struct {
 }
 
 type ReplicationControllerAndRelatedPod struct {
-	Name                                                     string
-	Namespace                                                string
-	ReplicaAmount                                            int
-	AliveReplicaAmount                                       int
-	Selector                                                 map[string]string
-	Label                                                    map[string]string
-	PodSlice                                                 []Pod
+	backend.ReplicationControllerAndRelatedPod
 	Display                                                  string
 	HiddenTagGuiInventoryReplicationControllerSize           string
 	HiddenTagGuiInventoryReplicationControllerDelete         string
@@ -42,31 +36,6 @@ type ReplicationControllerAndRelatedPod struct {
 	HiddenTagGuiInventoryReplicationControllerDockerterminal string
 }
 
-type Pod struct {
-	Name           string
-	Namespace      string
-	HostIP         string
-	PodIP          string
-	Phase          string
-	Age            string
-	ContainerSlice []PodContainer
-}
-
-type PodContainer struct {
-	Name         string
-	Image        string
-	ContainerID  string
-	RestartCount int
-	Ready        bool
-	PortSlice    []PodContainerPort
-}
-
-type PodContainerPort struct {
-	Name          string
-	ContainerPort int
-	Protocol      string
-}
-
 var displayMap map[string]string = map[string]string{
 	"kube-dns-v6":           "disabled",
 	"private-registry":      "disabled",
@@ -89,20 +58,9 @@ func (c *ListController) Get() {
 	hasGuiInventoryReplicationControllerPodDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/inventory/replicationcontroller/pod/delete")
 	hasGuiInventoryReplicationControllerDockerterminal := user.HasPermission(identity.GetConponentName(), "GET", "/gui/inventory/replicationcontroller/dockerterminal")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace
-
-	replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerAndRelatedPodSlice, tokenHeaderMap)
+	backendReplicationControllerAndRelatedPodSlice, err := backend.NewCloudoneClient(c.Ctx).GetReplicationControllerAndRelatedPodSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -110,8 +68,14 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
+		for _, backendReplicationControllerAndRelatedPod := range backendReplicationControllerAndRelatedPodSlice {
+			replicationControllerAndRelatedPodSlice = append(replicationControllerAndRelatedPodSlice,
+				ReplicationControllerAndRelatedPod{ReplicationControllerAndRelatedPod: backendReplicationControllerAndRelatedPod})
+		}
+
 		for i := 0; i < len(replicationControllerAndRelatedPodSlice); i++ {
 			replicationControllerAndRelatedPodSlice[i].Display =
 				displayMap[replicationControllerAndRelatedPodSlice[i].Name]
@@ -17,8 +17,8 @@ package replicationcontroller
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type PodLogController struct {
@@ -32,29 +32,22 @@ func (c *PodLogController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace := c.GetString("namespace")
 	pod := c.GetString("pod")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/pods/" + namespace + "/" + pod + "/logs"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	result, err := restclient.RequestGet(url, tokenHeaderMap, true)
+	var jsonMap map[string]interface{}
+	err := identity.AuthorizeNamespace(c.Ctx, namespace)
+	if err == nil {
+		jsonMap, err = backend.NewCloudoneClient(c.Ctx).GetPodLog(namespace, pod)
+	}
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
-	jsonMap, _ := result.(map[string]interface{})
-
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		c.Data["logJsonMap"] = jsonMap
 	}
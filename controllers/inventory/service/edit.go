@@ -17,8 +17,8 @@ package service
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"regexp"
 )
 
@@ -50,10 +50,6 @@ func (c *EditController) Get() {
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	name := c.GetString("name")
@@ -88,21 +84,16 @@ func (c *EditController) Post() {
 		nodePort = -1
 	}
 
-	portSlice := make([]ServicePort, 0)
-	portSlice = append(portSlice, ServicePort{portName, protocol, port, targetPort, nodePort, "", "", ""})
+	portSlice := make([]backend.ServicePort, 0)
+	portSlice = append(portSlice, backend.ServicePort{Name: portName, Protocol: protocol, Port: port, TargetPort: targetPort, NodePort: nodePort})
 	selectorMap := make(map[string]interface{})
 	selectorMap["name"] = selectorName
 	labelMap := make(map[string]interface{})
 	labelMap["name"] = labelName
 
-	service := Service{name, namespace, portSlice, selectorMap, "", labelMap, sessionAffinity, "", ""}
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/services/" + namespace
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	service := backend.Service{Name: name, Namespace: namespace, PortSlice: portSlice, Selector: selectorMap, ClusterIP: "", LabelMap: labelMap, SessionAffinity: sessionAffinity}
 
-	_, err := restclient.RequestPostWithStructure(url, service, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).CreateService(namespace, service)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -110,7 +101,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Service " + name + " is edited")
 	}
@@ -17,9 +17,9 @@ package service
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strconv"
 )
 
@@ -69,22 +69,11 @@ func (c *ListController) Get() {
 	// Tag won't work in loop so need to be placed in data
 	hasGuiInventoryServiceDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/inventory/service/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	serverHost := c.Ctx.Input.Host()
 
 	namespace := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/services/" + namespace
-
-	serviceSlice := make([]Service, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &serviceSlice, tokenHeaderMap)
+	backendServiceSlice, err := backend.NewCloudoneClient(c.Ctx).GetServiceSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -92,8 +81,31 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		serviceSlice := make([]Service, 0)
+		for _, backendService := range backendServiceSlice {
+			portSlice := make([]ServicePort, 0)
+			for _, backendServicePort := range backendService.PortSlice {
+				portSlice = append(portSlice, ServicePort{
+					Name:       backendServicePort.Name,
+					Protocol:   backendServicePort.Protocol,
+					Port:       backendServicePort.Port,
+					TargetPort: backendServicePort.TargetPort,
+					NodePort:   backendServicePort.NodePort,
+				})
+			}
+			serviceSlice = append(serviceSlice, Service{
+				Name:            backendService.Name,
+				Namespace:       backendService.Namespace,
+				PortSlice:       portSlice,
+				Selector:        backendService.Selector,
+				ClusterIP:       backendService.ClusterIP,
+				LabelMap:        backendService.LabelMap,
+				SessionAffinity: backendService.SessionAffinity,
+			})
+		}
+
 		for i := 0; i < len(serviceSlice); i++ {
 			serviceSlice[i].Display = displayMap[serviceSlice[i].Name]
 
@@ -18,12 +18,10 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
-	"net/url"
 	"sort"
-	"strconv"
 	"time"
 )
 
@@ -42,22 +40,12 @@ func (c *IndexController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
 	cloudoneGUIProtocol := beego.AppConfig.String("cloudoneGUIProtocol")
 	cloudoneGUIHost, cloudoneGUIPort := dashboard.GetServerHostAndPortFromUserRequest(c.Ctx.Input)
 
 	namespaces, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/historicalreplicationcontrollers/names/" + namespaces
-
-	jsonMapSlice := make([]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &jsonMapSlice, tokenHeaderMap)
+	nameSlice, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetHistoricalReplicationControllerNameSlice(namespaces)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -65,16 +53,9 @@ func (c *IndexController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
-		nameSlice := make([]string, 0)
-		nameSlice = append(nameSlice, allKeyword)
-		for _, value := range jsonMapSlice {
-			name, ok := value.(string)
-			if ok {
-				nameSlice = append(nameSlice, name)
-			}
-		}
+		nameSlice = append([]string{allKeyword}, nameSlice...)
 
 		c.Data["cloudoneGUIProtocol"] = cloudoneGUIProtocol
 		c.Data["cloudoneGUIHost"] = cloudoneGUIHost
@@ -95,10 +76,6 @@ const (
 
 func (c *DataController) Get() {
 
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
 	namespaces, _ := c.GetSession("namespace").(string)
 	timeZoneOffset, _ := c.GetSession("timeZoneOffset").(int)
 
@@ -131,8 +108,6 @@ func (c *DataController) Get() {
 		from = from.Add(time.Minute * time.Duration(timeZoneOffset))
 	}
 
-	fromInRFC3339Nano := from.Format(time.RFC3339Nano)
-
 	to, err := time.Parse("01/02/2006 15:04 PM", toText)
 	if err != nil {
 		// Error
@@ -155,8 +130,6 @@ func (c *DataController) Get() {
 		to = to.Add(time.Minute * time.Duration(timeZoneOffset))
 	}
 
-	toInRFC3339Nano := to.Format(time.RFC3339Nano)
-
 	// Make sure from is before to
 	if from.Before(to) == false {
 		// Error
@@ -169,58 +142,28 @@ func (c *DataController) Get() {
 
 	allHistoricalReplicationControllerMetricJsonMap := make(map[string]interface{})
 	if replicationControllerName != "" && replicationControllerName != allKeyword {
-
-		encodingUrl, _ := url.Parse(cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-			"/api/v1/historicalreplicationcontrollermetrics/" + namespaces + "/" + replicationControllerName)
-		parameters := url.Values{}
-		parameters.Add("from", fromInRFC3339Nano)
-		parameters.Add("to", toInRFC3339Nano)
-		parameters.Add("aggregationAmount", strconv.Itoa(aggregationAmount))
-		encodingUrl.RawQuery = parameters.Encode()
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		result, err := restclient.RequestGet(encodingUrl.String(), tokenHeaderMap, true)
+		historicalReplicationControllerMetricJsonMap, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetHistoricalReplicationControllerMetric(namespaces, replicationControllerName, from, to, aggregationAmount)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
 		}
 
-		historicalReplicationControllerMetricJsonMap, ok := result.(map[string]interface{})
-		if err != nil || ok == false {
+		if err != nil {
 			// Error
-			errorJsonMap := make(map[string]interface{})
-			errorJsonMap["error"] = err.Error()
-			c.Data["json"] = errorJsonMap
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 			return
 		}
 		allHistoricalReplicationControllerMetricJsonMap[replicationControllerName] = historicalReplicationControllerMetricJsonMap
 	} else {
-		encodingUrl, _ := url.Parse(cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-			"/api/v1/historicalreplicationcontrollermetrics/" + namespaces)
-		parameters := url.Values{}
-		parameters.Add("from", fromInRFC3339Nano)
-		parameters.Add("to", toInRFC3339Nano)
-		parameters.Add("aggregationAmount", strconv.Itoa(aggregationAmount))
-		encodingUrl.RawQuery = parameters.Encode()
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		result, err := restclient.RequestGet(encodingUrl.String(), tokenHeaderMap, true)
+		allHistoricalReplicationControllerMetricJsonMap, err = backend.NewCloudoneAnalysisClient(c.Ctx).GetAllHistoricalReplicationControllerMetric(namespaces, from, to, aggregationAmount)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
 		}
 
-		var ok bool
-		allHistoricalReplicationControllerMetricJsonMap, ok = result.(map[string]interface{})
-		if err != nil || ok == false {
+		if err != nil {
 			// Error
-			errorJsonMap := make(map[string]interface{})
-			errorJsonMap["error"] = err.Error()
-			c.Data["json"] = errorJsonMap
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 			return
 		}
 	}
@@ -239,10 +182,7 @@ func (c *DataController) Get() {
 	// No data to show
 	if len(filterHistoricalReplicationControllerMetricJsonMap) == 0 {
 		// Error
-		errorJsonMap := make(map[string]interface{})
-		errorJsonMap["error"] = "Insufficient data"
-		c.Data["json"] = errorJsonMap
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 0, guimessagedisplay.NewGUIErrorMessage("Insufficient data"))
 		return
 	}
 
@@ -17,25 +17,12 @@ package node
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"time"
 )
 
-type NodeMetric struct {
-	Valid                             bool
-	KubeletHost                       string
-	CpuUsageTotalSlice                []int64
-	MemoryUsageSlice                  []int64
-	DiskIOServiceBytesStatsTotalSlice []int64
-	DiskIOServicedStatsTotalSlice     []int64
-	NetworkRXBytesSlice               []int64
-	NetworkTXBytesSlice               []int64
-	NetworkRXPacketsSlice             []int64
-	NetworkTXPacketsSlice             []int64
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -63,18 +50,7 @@ type DataController struct {
 
 func (c *DataController) Get() {
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/nodemetrics"
-
-	nodeMetricSlice := make([]NodeMetric, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &nodeMetricSlice, tokenHeaderMap)
+	nodeMetricSlice, err := backend.NewCloudoneClient(c.Ctx).GetNodeMetricSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -82,10 +58,7 @@ func (c *DataController) Get() {
 
 	if err != nil {
 		// Error
-		errorJsonMap := make(map[string]interface{})
-		errorJsonMap["error"] = err.Error()
-		c.Data["json"] = errorJsonMap
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 		return
 	}
 
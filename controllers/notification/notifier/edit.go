@@ -18,8 +18,8 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strings"
 	"time"
 )
@@ -29,20 +29,13 @@ type EditController struct {
 }
 
 type EmailServerSMTP struct {
-	Name     string
-	Account  string
-	Password string
-	Host     string
-	Port     int
+	backend.EmailServerSMTP
 	Selected string
 }
 
 type SMSNexmo struct {
-	Name      string
-	Url       string
-	APIKey    string
-	APISecret string
-	Selected  string
+	backend.SMSNexmo
+	Selected string
 }
 
 func (c *EditController) Get() {
@@ -58,38 +51,36 @@ func (c *EditController) Get() {
 	c.Data["cpuHidden"] = "hidden"
 	c.Data["memoryHidden"] = "hidden"
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/emailserversmtp"
-	emailServerSMTPSlice := make([]EmailServerSMTP, 0)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &emailServerSMTPSlice, tokenHeaderMap)
+	backendEmailServerSMTPSlice, err := cloudoneClient.GetEmailServerSMTPSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	}
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/smsnexmo"
-	smsNexmoSlice := make([]SMSNexmo, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &smsNexmoSlice, tokenHeaderMap)
+	backendSMSNexmoSlice, err := cloudoneClient.GetSMSNexmoSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
+	}
+
+	emailServerSMTPSlice := make([]EmailServerSMTP, 0)
+	for _, backendEmailServerSMTP := range backendEmailServerSMTPSlice {
+		emailServerSMTPSlice = append(emailServerSMTPSlice, EmailServerSMTP{EmailServerSMTP: backendEmailServerSMTP})
+	}
+
+	smsNexmoSlice := make([]SMSNexmo, 0)
+	for _, backendSMSNexmo := range backendSMSNexmoSlice {
+		smsNexmoSlice = append(smsNexmoSlice, SMSNexmo{SMSNexmo: backendSMSNexmo})
 	}
 
 	c.Data["emailServerSMTPSlice"] = emailServerSMTPSlice
@@ -114,14 +105,7 @@ func (c *EditController) Get() {
 	} else {
 		namespace, _ := c.GetSession("namespace").(string)
 
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/notifiers/" + namespace + "/" + kind + "/" + name
-
-		replicationControllerNotifier := ReplicationControllerNotifier{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &replicationControllerNotifier, tokenHeaderMap)
+		replicationControllerNotifier, err := cloudoneClient.GetNotifier(namespace, kind, name)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -129,15 +113,15 @@ func (c *EditController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			for _, notifier := range replicationControllerNotifier.NotifierSlice {
 				switch notifier.Kind {
 				case "email":
-					notifierEmail := NotifierEmail{}
+					notifierEmail := backend.NotifierEmail{}
 					err := json.Unmarshal([]byte(notifier.Data), &notifierEmail)
 					if err != nil {
-						guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+						guimessage.AddError(err)
 					} else {
 						receiverAccountList := ""
 						length := len(notifierEmail.ReceiverAccountSlice)
@@ -158,9 +142,9 @@ func (c *EditController) Get() {
 						}
 					}
 				case "smsNexmo":
-					notifierSMSNexmo := NotifierSMSNexmo{}
+					notifierSMSNexmo := backend.NotifierSMSNexmo{}
 					if err != nil {
-						guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+						guimessage.AddError(err)
 					} else {
 						receiverNumberList := ""
 						length := len(notifierSMSNexmo.ReceiverNumberSlice)
@@ -249,7 +233,7 @@ func (c *EditController) Get() {
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	indicatorSlice := make([]Indicator, 0)
+	indicatorSlice := make([]backend.Indicator, 0)
 	cpu := c.GetString("cpu")
 	if cpu == "on" {
 		cpuAboveAllOrOneText := c.GetString("cpuAboveAllOrOne")
@@ -270,9 +254,9 @@ func (c *EditController) Post() {
 		}
 		cpuBelowPercentageOfData, _ := c.GetFloat("cpuBelowPercentageOfData")
 		cpuBelowThreshold, _ := c.GetInt64("cpuBelowThreshold")
-		indicatorSlice = append(indicatorSlice, Indicator{"cpu",
-			cpuAboveAllOrOne, cpuAbovePercentageOfData / 100.0, cpuAboveThreshold * 1000000,
-			cpuBelowAllOrOne, cpuBelowPercentageOfData / 100.0, cpuBelowThreshold * 1000000})
+		indicatorSlice = append(indicatorSlice, backend.Indicator{Type: "cpu",
+			AboveAllOrOne: cpuAboveAllOrOne, AbovePercentageOfData: cpuAbovePercentageOfData / 100.0, AboveThreshold: cpuAboveThreshold * 1000000,
+			BelowAllOrOne: cpuBelowAllOrOne, BelowPercentageOfData: cpuBelowPercentageOfData / 100.0, BelowThreshold: cpuBelowThreshold * 1000000})
 	}
 	memory := c.GetString("memory")
 	if memory == "on" {
@@ -294,15 +278,11 @@ func (c *EditController) Post() {
 		}
 		memoryBelowPercentageOfData, _ := c.GetFloat("memoryBelowPercentageOfData")
 		memoryBelowThreshold, _ := c.GetInt64("memoryBelowThreshold")
-		indicatorSlice = append(indicatorSlice, Indicator{"memory",
-			memoryAboveAllOrOne, memoryAbovePercentageOfData / 100.0, memoryAboveThreshold * 1024 * 1024,
-			memoryBelowAllOrOne, memoryBelowPercentageOfData / 100.0, memoryBelowThreshold * 1024 * 1024})
+		indicatorSlice = append(indicatorSlice, backend.Indicator{Type: "memory",
+			AboveAllOrOne: memoryAboveAllOrOne, AbovePercentageOfData: memoryAbovePercentageOfData / 100.0, AboveThreshold: memoryAboveThreshold * 1024 * 1024,
+			BelowAllOrOne: memoryBelowAllOrOne, BelowPercentageOfData: memoryBelowPercentageOfData / 100.0, BelowThreshold: memoryBelowThreshold * 1024 * 1024})
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	kind := c.GetString("kind")
@@ -328,7 +308,7 @@ func (c *EditController) Post() {
 		return
 	}
 
-	notifierSlice := make([]Notifier, 0)
+	notifierSlice := make([]backend.Notifier, 0)
 	if emailField != "" {
 		emailSlice := make([]string, 0)
 		for _, email := range strings.Split(emailField, ",") {
@@ -337,18 +317,18 @@ func (c *EditController) Post() {
 				emailSlice = append(emailSlice, value)
 			}
 		}
-		notifierEmail := NotifierEmail{
-			emailServerName,
-			emailSlice,
+		notifierEmail := backend.NotifierEmail{
+			Destination:          emailServerName,
+			ReceiverAccountSlice: emailSlice,
 		}
 		byteSlice, err := json.Marshal(notifierEmail)
 		if err != nil {
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			guimessage.OutputMessage(c.Data)
 			return
 		}
 
-		notifierSlice = append(notifierSlice, Notifier{"email", string(byteSlice)})
+		notifierSlice = append(notifierSlice, backend.Notifier{Kind: "email", Data: string(byteSlice)})
 	}
 	if smsNexmoSender != "" && smsNexmoPhoneField != "" {
 		smsNexmoPhoneSlice := make([]string, 0)
@@ -358,42 +338,35 @@ func (c *EditController) Post() {
 				smsNexmoPhoneSlice = append(smsNexmoPhoneSlice, value)
 			}
 		}
-		notifierSMSNexmo := NotifierSMSNexmo{
-			smsNexmoName,
-			smsNexmoSender,
-			smsNexmoPhoneSlice,
+		notifierSMSNexmo := backend.NotifierSMSNexmo{
+			Destination:         smsNexmoName,
+			Sender:              smsNexmoSender,
+			ReceiverNumberSlice: smsNexmoPhoneSlice,
 		}
 		byteSlice, err := json.Marshal(notifierSMSNexmo)
 		if err != nil {
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			guimessage.OutputMessage(c.Data)
 			return
 		}
 
-		notifierSlice = append(notifierSlice, Notifier{"smsNexmo", string(byteSlice)})
+		notifierSlice = append(notifierSlice, backend.Notifier{Kind: "smsNexmo", Data: string(byteSlice)})
 	}
 
-	replicationControllerNotifier := ReplicationControllerNotifier{
-		true,
-		time.Duration(coolDownDuration) * time.Second,
-		0,
-		"",
-		"",
-		namespace,
-		kind,
-		name,
-		notifierSlice,
-		indicatorSlice,
-		"",
-		"",
+	replicationControllerNotifier := backend.ReplicationControllerNotifier{
+		Check:                 true,
+		CoolDownDuration:      time.Duration(coolDownDuration) * time.Second,
+		RemainingCoolDown:     0,
+		KubeApiServerEndPoint: "",
+		KubeApiServerToken:    "",
+		Namespace:             namespace,
+		Kind:                  kind,
+		Name:                  name,
+		NotifierSlice:         notifierSlice,
+		IndicatorSlice:        indicatorSlice,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPutWithStructure(url, replicationControllerNotifier, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).UpdateNotifier(replicationControllerNotifier)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -401,7 +374,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Email notifier for " + kind + " " + name + " is edited")
 	}
@@ -17,11 +17,10 @@ package notifier
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
-	"time"
 )
 
 type ListController struct {
@@ -29,46 +28,11 @@ type ListController struct {
 }
 
 type ReplicationControllerNotifier struct {
-	Check                                  bool
-	CoolDownDuration                       time.Duration
-	RemainingCoolDown                      time.Duration
-	KubeApiServerEndPoint                  string
-	KubeApiServerToken                     string
-	Namespace                              string
-	Kind                                   string
-	Name                                   string
-	NotifierSlice                          []Notifier
-	IndicatorSlice                         []Indicator
+	backend.ReplicationControllerNotifier
 	HiddenTagGuiNotificationNotifierEdit   string
 	HiddenTagGuiNotificationNotifierDelete string
 }
 
-type Notifier struct {
-	Kind string
-	Data string
-}
-
-type NotifierSMSNexmo struct {
-	Destination         string
-	Sender              string
-	ReceiverNumberSlice []string
-}
-
-type NotifierEmail struct {
-	Destination          string
-	ReceiverAccountSlice []string
-}
-
-type Indicator struct {
-	Type                  string
-	AboveAllOrOne         bool
-	AbovePercentageOfData float64
-	AboveThreshold        int64
-	BelowAllOrOne         bool
-	BelowPercentageOfData float64
-	BelowThreshold        int64
-}
-
 type ByReplicationControllerNotifier []ReplicationControllerNotifier
 
 func (b ByReplicationControllerNotifier) Len() int           { return len(b) }
@@ -88,18 +52,7 @@ func (c *ListController) Get() {
 	hasGuiNotificationNotifierEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/notification/notifier/edit")
 	hasGuiNotificationNotifierDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/notification/notifier/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/"
-
-	replicationControllerNotifierSlice := make([]ReplicationControllerNotifier, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerNotifierSlice, tokenHeaderMap)
+	backendReplicationControllerNotifierSlice, err := backend.NewCloudoneClient(c.Ctx).GetNotifierSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -107,10 +60,15 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		namespace, _ := c.GetSession("namespace").(string)
 
+		replicationControllerNotifierSlice := make([]ReplicationControllerNotifier, 0)
+		for _, backendReplicationControllerNotifier := range backendReplicationControllerNotifierSlice {
+			replicationControllerNotifierSlice = append(replicationControllerNotifierSlice, ReplicationControllerNotifier{ReplicationControllerNotifier: backendReplicationControllerNotifier})
+		}
+
 		filteredReplicationControllerNotifierSlice := make([]ReplicationControllerNotifier, 0)
 
 		for i := 0; i < len(replicationControllerNotifierSlice); i++ {
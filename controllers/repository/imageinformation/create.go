@@ -17,9 +17,9 @@ package imageinformation
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/random"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"regexp"
 )
 
@@ -27,12 +27,6 @@ type CreateController struct {
 	beego.Controller
 }
 
-type PrivateRegistry struct {
-	Name string
-	Host string
-	Port int
-}
-
 func (c *CreateController) Get() {
 	c.TplName = "repository/imageinformation/create.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
@@ -40,18 +34,7 @@ func (c *CreateController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/privateregistries/servers/"
-
-	privateRegistrySlice := make([]PrivateRegistry, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &privateRegistrySlice, tokenHeaderMap)
+	privateRegistrySlice, err := backend.NewCloudoneClient(c.Ctx).GetPrivateRegistrySlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -59,7 +42,7 @@ func (c *CreateController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/repository/imageinformation/list")
 		return
@@ -80,10 +63,6 @@ func (c *CreateController) Get() {
 func (c *CreateController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 	kind := c.GetString("kind")
 	description := c.GetString("description")
@@ -128,35 +107,22 @@ func (c *CreateController) Post() {
 		buildParameter["versionFile"] = c.GetString("versionFile")
 	}
 
-	imageInformation := ImageInformation{
-		name,
-		kind,
-		description,
-		"",
-		buildParameter,
-		"",
-		"",
-		"",
-		"",
-		"",
-		"",
+	imageInformation := backend.ImageInformation{
+		Name:           name,
+		Kind:           kind,
+		Description:    description,
+		CurrentVersion: "",
+		BuildParameter: buildParameter,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imageinformations/create/"
-
-	resultJsonMap := make(map[string]interface{})
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPostWithStructure(url, imageInformation, &resultJsonMap, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).CreateImageInformation(imageInformation)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("The build " + name + " is launched asynchronizedly")
 	}
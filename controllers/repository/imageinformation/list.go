@@ -17,10 +17,10 @@ package imageinformation
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strconv"
 )
@@ -30,11 +30,7 @@ type ListController struct {
 }
 
 type ImageInformation struct {
-	Name                                          string
-	Kind                                          string
-	Description                                   string
-	CurrentVersion                                string
-	BuildParameter                                map[string]string
+	backend.ImageInformation
 	HiddenTagGuiRepositoryImageRecordList         string
 	HiddenTagGuiRepositoryImageInformationUpgrade string
 	HiddenTagGuiRepositoryImageInformationLog     string
@@ -66,21 +62,10 @@ func (c *ListController) Get() {
 	hasGuiDeployDeployBlueGreenSelect := user.HasPermission(identity.GetConponentName(), "GET", "/gui/deploy/deploybluegreen/select")
 	hasGuiRepositoryImageInformationDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/repository/imageinformation/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	cloudoneGUIProtocol := beego.AppConfig.String("cloudoneGUIProtocol")
 	cloudoneGUIHost, cloudoneGUIPort := dashboard.GetServerHostAndPortFromUserRequest(c.Ctx.Input)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imageinformations/"
-
-	imageInformationSlice := make([]ImageInformation, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &imageInformationSlice, tokenHeaderMap)
+	backendImageInformationSlice, err := backend.NewCloudoneClient(c.Ctx).GetImageInformationSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -88,8 +73,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		imageInformationSlice := make([]ImageInformation, 0)
+		for _, backendImageInformation := range backendImageInformationSlice {
+			imageInformationSlice = append(imageInformationSlice, ImageInformation{ImageInformation: backendImageInformation})
+		}
+
 		for i := 0; i < len(imageInformationSlice); i++ {
 			if hasGuiRepositoryImageRecordList {
 				imageInformationSlice[i].HiddenTagGuiRepositoryImageRecordList = "<div class='btn-group'>"
@@ -18,6 +18,8 @@ import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/shutdown"
 	"github.com/hpcloud/tail"
 	"golang.org/x/net/websocket"
 	"os"
@@ -66,7 +68,17 @@ type WebSocketController struct {
 }
 
 func (c *WebSocketController) Get() {
-	server := websocket.Server{Handler: ProxyServer}
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer metrics.StartWebSocketSession(metrics.WebSocketKindBuildLog)()
+		endWebSocketSession, err := shutdown.StartWebSocketSession(ws, metrics.WebSocketKindBuildLog, false)
+		if err != nil {
+			ws.Write([]byte(err.Error() + "\n"))
+			ws.Close()
+			return
+		}
+		defer endWebSocketSession()
+		ProxyServer(ws)
+	}}
 	server.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
 }
 
@@ -17,19 +17,14 @@ package imageinformation
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type UpgradeController struct {
 	beego.Controller
 }
 
-type DeployUpgradeInput struct {
-	ImageInformationName string
-	Description          string
-}
-
 func (c *UpgradeController) Get() {
 	c.TplName = "repository/imageinformation/upgrade.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
@@ -46,30 +41,19 @@ func (c *UpgradeController) Get() {
 func (c *UpgradeController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	imageInformationName := c.GetString("name")
 	description := c.GetString("description")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imageinformations/upgrade"
-
-	deployUpgradeInput := DeployUpgradeInput{imageInformationName, description}
-
-	resultJsonMap := make(map[string]interface{})
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	deployUpgradeInput := backend.DeployUpgradeInput{ImageInformationName: imageInformationName, Description: description}
 
-	_, err := restclient.RequestPutWithStructure(url, deployUpgradeInput, &resultJsonMap, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).UpgradeImageInformation(deployUpgradeInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess(imageInformationName + " is launched")
 	}
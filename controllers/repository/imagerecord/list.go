@@ -17,11 +17,10 @@ package imagerecord
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
-	"strings"
 )
 
 type ListController struct {
@@ -29,14 +28,7 @@ type ListController struct {
 }
 
 type ImageRecord struct {
-	ImageInformation                        string
-	Version                                 string
-	Path                                    string
-	VersionInfo                             map[string]string
-	Environment                             map[string]string
-	Description                             string
-	CreatedTime                             string
-	Failure                                 bool
+	backend.ImageRecord
 	FieldFailureStyleColor                  string
 	HiddenTagGuiRepositoryImageRecordLog    string
 	HiddenTagGuiRepositoryImageRecordDelete string
@@ -61,34 +53,22 @@ func (c *ListController) Get() {
 	hasGuiRepositoryImageRecordLog := user.HasPermission(identity.GetConponentName(), "GET", "/gui/repository/imagerecord/log")
 	hasGuiRepositoryImageRecordDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/repository/imagerecord/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imagerecords/" + name
-
-	imageRecordSlice := make([]ImageRecord, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	returnedJsonMap, err := restclient.RequestGetWithStructure(url, &imageRecordSlice, tokenHeaderMap)
+	backendImageRecordSlice, err := backend.NewCloudoneClient(c.Ctx).GetImageRecordSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
-	if err != nil {
-		errorJsonMap, _ := returnedJsonMap.(map[string]interface{})
-		errorMessage, _ := errorJsonMap["ErrorMessage"].(string)
-		if strings.HasPrefix(errorMessage, "100: Key not found") {
+	if err != nil && !backend.IsKeyNotFound(err) {
+		// Error
+		guimessage.AddError(err)
+	}
 
-		} else {
-			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
-		}
+	imageRecordSlice := make([]ImageRecord, 0)
+	for _, backendImageRecord := range backendImageRecordSlice {
+		imageRecordSlice = append(imageRecordSlice, ImageRecord{ImageRecord: backendImageRecord})
 	}
 
 	for i := 0; i < len(imageRecordSlice); i++ {
@@ -17,9 +17,8 @@ package imagerecord
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/build"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type LogController struct {
@@ -36,18 +35,7 @@ func (c *LogController) Get() {
 	imageInformation := c.GetString("imageInformation")
 	version := c.GetString("version")
 
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/buildlogs/" + imageInformation + "/" + version
-
-	buildLog := build.BuildLog{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &buildLog, tokenHeaderMap)
+	buildLog, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetBuildLog(imageInformation, version)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -55,7 +43,7 @@ func (c *LogController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		c.Data["log"] = buildLog.Content
 	}
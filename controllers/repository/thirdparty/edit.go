@@ -4,8 +4,8 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"github.com/ghodss/yaml"
 	"io/ioutil"
 	"regexp"
@@ -22,10 +22,6 @@ func (c *EditController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 	if name == "" {
 		c.Data["actionButtonValue"] = "Create"
@@ -34,20 +30,14 @@ func (c *EditController) Get() {
 
 		guimessage.OutputMessage(c.Data)
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/clusterapplications/" + name
-		cluster := Cluster{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &cluster, tokenHeaderMap)
+		cluster, err := backend.NewCloudoneClient(c.Ctx).GetClusterApplication(name)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
 		}
 
 		if err != nil {
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			// Redirect to list
 			c.Ctx.Redirect(302, "/gui/repository/thirdparty/list")
 
@@ -55,7 +45,7 @@ func (c *EditController) Get() {
 		} else {
 			environmentByteSlice, err := json.MarshalIndent(cluster.Environment, "", "    ")
 			if err != nil {
-				guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+				guimessage.AddError(err)
 				// Redirect to list
 				c.Ctx.Redirect(302, "/gui/repository/thirdparty/list")
 
@@ -87,10 +77,6 @@ func (c *EditController) Get() {
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 	description := c.GetString("description")
 
@@ -180,7 +166,7 @@ func (c *EditController) Post() {
 		if err != nil {
 			// Error
 			guimessage.AddDanger("Replication controller can't be parsed by json or yaml")
-			guimessage.AddDanger(err.Error())
+			guimessage.AddError(err)
 			c.Ctx.Redirect(302, "/gui/repository/thirdparty/list")
 			guimessage.RedirectMessage(c)
 			return
@@ -197,7 +183,7 @@ func (c *EditController) Post() {
 		if err != nil {
 			// Error
 			guimessage.AddDanger("Service can't be parsed by json or yaml")
-			guimessage.AddDanger(err.Error())
+			guimessage.AddError(err)
 			c.Ctx.Redirect(302, "/gui/repository/thirdparty/list")
 			guimessage.RedirectMessage(c)
 			return
@@ -213,29 +199,24 @@ func (c *EditController) Post() {
 		if err != nil {
 			// Error
 			guimessage.AddDanger("Environment can't be parsed by json or yaml")
-			guimessage.AddDanger(err.Error())
+			guimessage.AddError(err)
 			c.Ctx.Redirect(302, "/gui/repository/thirdparty/list")
 			guimessage.RedirectMessage(c)
 			return
 		}
 	}
 
-	cluster := Cluster{
-		name,
-		description,
-		replicationControllerJson,
-		serviceJson,
-		environmentJsonMap,
-		scriptType,
-		scriptContent,
+	cluster := backend.Cluster{
+		Name:                      name,
+		Description:               description,
+		ReplicationControllerJson: replicationControllerJson,
+		ServiceJson:               serviceJson,
+		Environment:               environmentJsonMap,
+		ScriptType:                scriptType,
+		ScriptContent:             scriptContent,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPost(url, cluster, tokenHeaderMap, true)
+	err = backend.NewCloudoneClient(c.Ctx).CreateClusterApplication(cluster)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -243,7 +224,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Third party application " + name + " is edited")
 	}
@@ -17,50 +17,11 @@ package thirdparty
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strings"
 )
 
-type Cluster struct {
-	Name                      string
-	Description               string
-	ReplicationControllerJson string
-	ServiceJson               string
-	Environment               map[string]string
-	ScriptType                string
-	ScriptContent             string
-}
-
-type ClusterLaunch struct {
-	Size                              int
-	EnvironmentSlice                  []interface{}
-	ReplicationControllerExtraJsonMap map[string]interface{}
-}
-
-type Region struct {
-	Name           string
-	LocationTagged bool
-	ZoneSlice      []Zone
-}
-
-type Zone struct {
-	Name           string
-	LocationTagged bool
-	NodeSlice      []Node
-}
-
-type Node struct {
-	Name     string
-	Address  string
-	Capacity Capacity
-}
-
-type Capacity struct {
-	Cpu    string
-	Memory string
-}
-
 type LaunchController struct {
 	beego.Controller
 }
@@ -72,26 +33,18 @@ func (c *LaunchController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/" + name
-	cluster := Cluster{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &cluster, tokenHeaderMap)
+	cluster, err := cloudoneClient.GetClusterApplication(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		// Redirect to list
 		c.Ctx.Redirect(302, "/gui/repository/thirdparty/list")
 
@@ -99,19 +52,14 @@ func (c *LaunchController) Get() {
 		return
 	}
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/nodes/topology"
-
-	regionSlice := make([]Region, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &regionSlice, tokenHeaderMap)
+	filteredRegionSlice, err := cloudoneClient.GetLocationTaggedRegionSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
 	if err != nil {
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		// Redirect to list
 		c.Ctx.Redirect(302, "/gui/repository/thirdparty/list")
 
@@ -119,13 +67,6 @@ func (c *LaunchController) Get() {
 		return
 	}
 
-	filteredRegionSlice := make([]Region, 0)
-	for _, region := range regionSlice {
-		if region.LocationTagged {
-			filteredRegionSlice = append(filteredRegionSlice, region)
-		}
-	}
-
 	if cluster.Environment != nil {
 		namespace, _ := c.GetSession("namespace").(string)
 
@@ -152,10 +93,6 @@ func (c *LaunchController) Get() {
 func (c *LaunchController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 	name := c.GetString("name")
 	size, _ := c.GetInt("size")
@@ -181,14 +118,11 @@ func (c *LaunchController) Post() {
 		}
 	}
 
-	environmentSlice := make([]interface{}, 0)
+	environmentSlice := make([]backend.ClusterEnvironment, 0)
 	for _, key := range keySlice {
 		value := c.GetString(key)
 		if len(value) > 0 {
-			environmentMap := make(map[string]string)
-			environmentMap["name"] = key[len("environment_"):]
-			environmentMap["value"] = value
-			environmentSlice = append(environmentSlice, environmentMap)
+			environmentSlice = append(environmentSlice, backend.ClusterEnvironment{Name: key[len("environment_"):], Value: value})
 		}
 	}
 
@@ -207,18 +141,13 @@ func (c *LaunchController) Post() {
 		extraJsonMap = nil
 	}
 
-	clusterLaunch := ClusterLaunch{
-		size,
-		environmentSlice,
-		extraJsonMap,
+	clusterLaunch := backend.ClusterLaunch{
+		Size:                              size,
+		EnvironmentSlice:                  environmentSlice,
+		ReplicationControllerExtraJsonMap: extraJsonMap,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/launch/" + namespace + "/" + name
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPostWithStructure(url, clusterLaunch, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).LaunchClusterApplication(namespace, name, clusterLaunch)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -226,7 +155,7 @@ func (c *LaunchController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Cluster application " + name + " is launched")
 	}
@@ -17,9 +17,9 @@ package thirdparty
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,8 +28,7 @@ type ListController struct {
 }
 
 type ThirdPartyApplication struct {
-	Name                                   string
-	Description                            string
+	backend.Cluster
 	HiddenTagGuiRepositoryThirdPartyLaunch string
 	HiddenTagGuiRepositoryThirdPartyEdit   string
 	HiddenTagGuiRepositoryThirdPartyDelete string
@@ -55,18 +54,7 @@ func (c *ListController) Get() {
 	hasGuiRepositoryThirdPartyEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/repository/thirdparty/edit")
 	hasGuiRepositoryThirdPartyDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/repository/thirdparty/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/"
-
-	thirdPartyApplicationSlice := make([]ThirdPartyApplication, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &thirdPartyApplicationSlice, tokenHeaderMap)
+	clusterSlice, err := backend.NewCloudoneClient(c.Ctx).GetClusterApplicationSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -74,8 +62,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		thirdPartyApplicationSlice := make([]ThirdPartyApplication, 0)
+		for _, cluster := range clusterSlice {
+			thirdPartyApplicationSlice = append(thirdPartyApplicationSlice, ThirdPartyApplication{Cluster: cluster})
+		}
+
 		for i := 0; i < len(thirdPartyApplicationSlice); i++ {
 			if hasGuiRepositoryThirdPartyLaunch {
 				thirdPartyApplicationSlice[i].HiddenTagGuiRepositoryThirdPartyLaunch = "<div class='btn-group'>"
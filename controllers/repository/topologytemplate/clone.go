@@ -17,8 +17,8 @@ package topologytemplate
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strings"
 )
@@ -34,20 +34,11 @@ func (c *CloneController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	topologyName := c.GetString("name")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/topology/" + topologyName
-
-	topology := Topology{}
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &topology, tokenHeaderMap)
+	backendTopology, err := cloudoneClient.GetTopology(topologyName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -55,12 +46,13 @@ func (c *CloneController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/repository/topologytemplate/list")
 		return
 	}
 
+	topology := newTopology(*backendTopology)
 	c.Data["topology"] = topology
 
 	if topology.LaunchSlice != nil {
@@ -68,7 +60,7 @@ func (c *CloneController) Get() {
 			if topology.LaunchSlice[i].LaunchApplication != nil {
 				topology.LaunchSlice[i].Name = topology.LaunchSlice[i].LaunchApplication.ImageInformationName
 			} else {
-				topology.LaunchSlice[i].LaunchApplication = &LaunchApplication{}
+				topology.LaunchSlice[i].LaunchApplication = &backend.DeployCreateInput{}
 				topology.LaunchSlice[i].HiddenTagLaunchApplication = "hidden"
 				// To avoid client side input validation
 				topology.LaunchSlice[i].LaunchApplication.ReplicaAmount = 1
@@ -76,7 +68,7 @@ func (c *CloneController) Get() {
 			if topology.LaunchSlice[i].LaunchClusterApplication != nil {
 				topology.LaunchSlice[i].Name = topology.LaunchSlice[i].LaunchClusterApplication.Name
 			} else {
-				topology.LaunchSlice[i].LaunchClusterApplication = &LaunchClusterApplication{}
+				topology.LaunchSlice[i].LaunchClusterApplication = &backend.LaunchClusterApplication{}
 				topology.LaunchSlice[i].HiddenTagLaunchClusterApplication = "hidden"
 				// To avoid client side input validation
 				topology.LaunchSlice[i].LaunchClusterApplication.Size = 1
@@ -85,12 +77,7 @@ func (c *CloneController) Get() {
 	}
 
 	// Region
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/nodes/topology"
-
-	regionSlice := make([]Region, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &regionSlice, tokenHeaderMap)
+	filteredRegionSlice, err := cloudoneClient.GetLocationTaggedRegionSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -98,19 +85,12 @@ func (c *CloneController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/repository/topologytemplate/list")
 		return
 	}
 
-	filteredRegionSlice := make([]Region, 0)
-	for _, region := range regionSlice {
-		if region.LocationTagged {
-			filteredRegionSlice = append(filteredRegionSlice, region)
-		}
-	}
-
 	for i := 0; i < len(topology.LaunchSlice); i++ {
 		topology.LaunchSlice[i].RegionSlice = filteredRegionSlice
 	}
@@ -121,20 +101,12 @@ func (c *CloneController) Get() {
 func (c *CloneController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	// Get topology
 	name := c.GetString("name")
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/topology/" + name
-
-	topology := Topology{}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &topology, tokenHeaderMap)
+	topology, err := cloudoneClient.GetTopology(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -142,7 +114,7 @@ func (c *CloneController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.RedirectMessage(c)
 		c.Ctx.Redirect(302, "/gui/repository/topologytemplate/list")
 		return
@@ -169,7 +141,7 @@ func (c *CloneController) Post() {
 		}
 	}
 
-	launchSlice := make([]Launch, 0)
+	launchSlice := make([]backend.Launch, 0)
 	for _, launchName := range launchNameSlice {
 		launchOrder, _ := c.GetInt("launchOrder" + launchName)
 
@@ -221,40 +193,32 @@ func (c *CloneController) Post() {
 
 		// Cluster or application
 		if len(clusterName) > 0 {
-			environmentSlice := make([]interface{}, 0)
+			environmentSlice := make([]backend.ClusterEnvironment, 0)
 			for key, value := range clusterEnvironmentMap {
-				environmentJsonMap := make(map[string]interface{})
-				environmentJsonMap["name"] = key
-				environmentJsonMap["value"] = value
-				environmentSlice = append(environmentSlice, environmentJsonMap)
+				environmentSlice = append(environmentSlice, backend.ClusterEnvironment{Name: key, Value: value})
 			}
 
-			clusterLaunch := &LaunchClusterApplication{
-				clusterName,
-				clusterSize,
-				environmentSlice,
-				extraJsonMap,
+			clusterLaunch := &backend.LaunchClusterApplication{
+				Name:                              clusterName,
+				Size:                              clusterSize,
+				EnvironmentSlice:                  environmentSlice,
+				ReplicationControllerExtraJsonMap: extraJsonMap,
 			}
 
-			launch := Launch{
-				launchOrder,
-				nil,
-				clusterLaunch,
-				"",
-				"",
-				nil,
-				"",
-				"",
+			launch := backend.Launch{
+				Order:                    launchOrder,
+				LaunchApplication:        nil,
+				LaunchClusterApplication: clusterLaunch,
 			}
 
 			launchSlice = append(launchSlice, launch)
 		} else if len(applicationImageInformationName) > 0 {
-			environmentSlice := make([]ReplicationControllerContainerEnvironment, 0)
+			environmentSlice := make([]backend.ReplicationControllerContainerEnvironment, 0)
 			for key, value := range applicationEnvironmentMap {
-				environmentSlice = append(environmentSlice, ReplicationControllerContainerEnvironment{key, value})
+				environmentSlice = append(environmentSlice, backend.ReplicationControllerContainerEnvironment{Name: key, Value: value})
 			}
 
-			oldLaunch := Launch{}
+			oldLaunch := backend.Launch{}
 			for i := 0; i < len(topology.LaunchSlice); i++ {
 				if topology.LaunchSlice[i].LaunchApplication != nil && launchName == topology.LaunchSlice[i].LaunchApplication.ImageInformationName {
 					oldLaunch = topology.LaunchSlice[i]
@@ -268,26 +232,22 @@ func (c *CloneController) Post() {
 				}
 			}
 
-			launchApplication := &LaunchApplication{
-				applicationImageInformationName,
-				applicationVersion,
-				applicationDescription,
-				applicationReplicaAmount,
-				oldLaunch.LaunchApplication.PortSlice,
-				environmentSlice,
-				oldLaunch.LaunchApplication.ResourceMap,
-				extraJsonMap,
+			launchApplication := &backend.DeployCreateInput{
+				ImageInformationName:  applicationImageInformationName,
+				Version:               applicationVersion,
+				Description:           applicationDescription,
+				ReplicaAmount:         applicationReplicaAmount,
+				PortSlice:             oldLaunch.LaunchApplication.PortSlice,
+				EnvironmentSlice:      environmentSlice,
+				ResourceMap:           oldLaunch.LaunchApplication.ResourceMap,
+				ExtraJsonMap:          extraJsonMap,
+				AutoUpdateForNewBuild: false,
 			}
 
-			launch := Launch{
-				launchOrder,
-				launchApplication,
-				nil,
-				"",
-				"",
-				nil,
-				"",
-				"",
+			launch := backend.Launch{
+				Order:                    launchOrder,
+				LaunchApplication:        launchApplication,
+				LaunchClusterApplication: nil,
 			}
 
 			launchSlice = append(launchSlice, launch)
@@ -299,44 +259,18 @@ func (c *CloneController) Post() {
 	// Clone
 	namespace, _ := c.GetSession("namespace").(string)
 
-	for _, launch := range launchSlice {
-		if launch.LaunchApplication != nil {
-			url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-				"/api/v1/deploys/create/" + namespace
+	err = cloudoneClient.Launch(namespace, launchSlice)
 
-			_, err = restclient.RequestPostWithStructure(url, launch.LaunchApplication, nil, tokenHeaderMap)
-
-			if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
-				return
-			}
-
-			if err != nil {
-				// Error
-				guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
-				guimessage.RedirectMessage(c)
-				c.Ctx.Redirect(302, "/gui/repository/topologytemplate/list")
-				return
-			}
-		}
-		if launch.LaunchClusterApplication != nil {
-			url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-				"/api/v1/clusterapplications/launch/" + namespace + "/" + launch.LaunchClusterApplication.Name
-
-			jsonMap := make(map[string]interface{})
-
-			_, err = restclient.RequestPostWithStructure(url, launch.LaunchClusterApplication, &jsonMap, tokenHeaderMap)
-
-			if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
-				return
-			}
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
 
-			if err != nil {
-				guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
-				guimessage.RedirectMessage(c)
-				c.Ctx.Redirect(302, "/gui/repository/topologytemplate/list")
-				return
-			}
-		}
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+		guimessage.RedirectMessage(c)
+		c.Ctx.Redirect(302, "/gui/repository/topologytemplate/list")
+		return
 	}
 
 	guimessage.AddSuccess("Clone from the template " + name + " to the namespace " + namespace)
@@ -17,9 +17,9 @@ package topologytemplate
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strconv"
 	"time"
@@ -42,69 +42,43 @@ type Topology struct {
 }
 
 type Launch struct {
-	Order                    int
-	LaunchApplication        *LaunchApplication
-	LaunchClusterApplication *LaunchClusterApplication
+	backend.Launch
 	// The following is used for GUI
 	Information                       string
 	Name                              string
-	RegionSlice                       []Region
+	RegionSlice                       []backend.Region
 	HiddenTagLaunchApplication        string
 	HiddenTagLaunchClusterApplication string
 }
 
-type ByLaunch []Launch
+type ByLaunch []backend.Launch
 
 func (b ByLaunch) Len() int           { return len(b) }
 func (b ByLaunch) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b ByLaunch) Less(i, j int) bool { return b[i].Order < b[j].Order }
 
-type Region struct {
-	Name           string
-	LocationTagged bool
-	ZoneSlice      []Zone
-}
-
-type Zone struct {
-	Name           string
-	LocationTagged bool
-}
-
-type LaunchApplication struct {
-	ImageInformationName string
-	Version              string
-	Description          string
-	ReplicaAmount        int
-	PortSlice            []DeployContainerPort
-	EnvironmentSlice     []ReplicationControllerContainerEnvironment
-	ResourceMap          map[string]interface{}
-	ExtraJsonMap         map[string]interface{}
-}
-
-type LaunchClusterApplication struct {
-	Name                              string
-	Size                              int
-	EnvironmentSlice                  []interface{}
-	ReplicationControllerExtraJsonMap map[string]interface{}
-}
-
-type DeployContainerPort struct {
-	Name          string
-	ContainerPort int
-	NodePort      int
-}
-
-type ReplicationControllerContainerEnvironment struct {
-	Name  string
-	Value string
-}
-
 type ByTopology []Topology
 
 func (b ByTopology) Len() int           { return len(b) }
 func (b ByTopology) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
 func (b ByTopology) Less(i, j int) bool { return b[i].CreatedDate.Before(b[j].CreatedDate) }
 
+func newTopology(topology backend.Topology) Topology {
+	launchSlice := make([]Launch, 0)
+	for _, launch := range topology.LaunchSlice {
+		launchSlice = append(launchSlice, Launch{Launch: launch})
+	}
+
+	return Topology{
+		Name:            topology.Name,
+		SourceNamespace: topology.SourceNamespace,
+		CreatedUser:     topology.CreatedUser,
+		CreatedDate:     topology.CreatedDate,
+		Description:     topology.Description,
+		LaunchSlice:     launchSlice,
+	}
+}
+
 func (c *ListController) Get() {
 	c.TplName = "repository/topologytemplate/list.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
@@ -117,18 +91,7 @@ func (c *ListController) Get() {
 	hasGuiRepositoryTopologyTemplateClone := user.HasPermission(identity.GetConponentName(), "GET", "/gui/repository/topologytemplate/clone")
 	hasGuiRepositoryTopologyTemplateDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/repository/topologytemplate/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/topology/"
-
-	topologySlice := make([]Topology, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &topologySlice, tokenHeaderMap)
+	backendTopologySlice, err := backend.NewCloudoneClient(c.Ctx).GetTopologySlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -136,8 +99,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		topologySlice := make([]Topology, 0)
+		for _, backendTopology := range backendTopologySlice {
+			topologySlice = append(topologySlice, newTopology(backendTopology))
+		}
+
 		for i := 0; i < len(topologySlice); i++ {
 			if hasGuiRepositoryTopologyTemplateClone {
 				topologySlice[i].HiddenTagGuiRepositoryTopologyTemplateClone = "<div class='btn-group'>"
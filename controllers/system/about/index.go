@@ -16,7 +16,9 @@ package about
 
 import (
 	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
 )
 
 type IndexController struct {
@@ -30,5 +32,8 @@ func (c *IndexController) Get() {
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
+	c.Data["auditLogStatistic"] = identity.GetAuditLogStatistic()
+	c.Data["tracingStatistic"] = tracing.GetExporterStatistic()
+
 	guimessage.OutputMessage(c.Data)
 }
@@ -0,0 +1,39 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+)
+
+type FlushController struct {
+	beego.Controller
+}
+
+func (c *FlushController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Only the cache of this replica is flushed
+	backend.FlushCache()
+
+	guimessage.AddSuccess("The cached backend responses are flushed")
+
+	// Redirect to list
+	c.Ctx.Redirect(302, "/gui/system/cache/list")
+
+	guimessage.RedirectMessage(c)
+}
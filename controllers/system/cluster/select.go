@@ -0,0 +1,52 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"net/url"
+)
+
+type SelectController struct {
+	beego.Controller
+}
+
+func (c *SelectController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	name := c.GetString("name")
+
+	// Each cluster issues its own token so the user logins the cluster first
+	if identity.IsClusterLogined(c, name) == false {
+		guimessage.AddInfo("Please login to cluster " + name)
+		c.Ctx.Redirect(302, "/gui/login?cluster="+url.QueryEscape(name))
+		guimessage.RedirectMessage(c)
+		return
+	}
+
+	err := identity.SwitchCluster(c, name)
+	if err != nil {
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Use cluster " + name)
+	}
+
+	// Redirect to dashboard since the resources differ in each cluster
+	c.Ctx.Redirect(302, "/gui/dashboard/topology/")
+
+	guimessage.RedirectMessage(c)
+}
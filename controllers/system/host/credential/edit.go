@@ -17,8 +17,8 @@ package credential
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
@@ -42,18 +42,7 @@ func (c *EditController) Get() {
 
 		c.Data["sshPort"] = 22
 	} else {
-		cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-		cloudoneHost := beego.AppConfig.String("cloudoneHost")
-		cloudonePort := beego.AppConfig.String("cloudonePort")
-
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/hosts/credentials/" + ip
-
-		credential := Credential{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &credential, tokenHeaderMap)
+		credential, err := backend.NewCloudoneClient(c.Ctx).GetHostCredential(ip)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -61,7 +50,7 @@ func (c *EditController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			guimessage.OutputMessage(c.Data)
 			return
 		}
@@ -89,10 +78,6 @@ func (c *EditController) Get() {
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	createOrUpdate := c.GetString("createOrUpdate")
 	ip := c.GetString("ip")
 	sshPort, _ := c.GetInt("sshPort")
@@ -105,25 +90,20 @@ func (c *EditController) Post() {
 		disabled = true
 	}
 
-	credential := Credential{
-		ip,
-		SSH{
-			sshPort,
-			sshUser,
-			sshPassword,
+	credential := backend.Credential{
+		IP: ip,
+		SSH: backend.SSH{
+			Port:     sshPort,
+			User:     sshUser,
+			Password: sshPassword,
 		},
-		disabled,
-		"",
-		"",
+		Disabled: disabled,
 	}
 
-	if createOrUpdate == "create" {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/hosts/credentials/"
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-		_, err := restclient.RequestPostWithStructure(url, credential, nil, tokenHeaderMap)
+	if createOrUpdate == "create" {
+		err := cloudoneClient.CreateHostCredential(credential)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -131,17 +111,12 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("Host credential " + ip + " is created")
 		}
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/hosts/credentials/" + ip
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestPutWithStructure(url, credential, nil, tokenHeaderMap)
+		err := cloudoneClient.UpdateHostCredential(credential)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -149,7 +124,7 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("Host credential " + ip + " is updated")
 		}
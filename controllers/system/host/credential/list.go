@@ -17,9 +17,9 @@ package credential
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,19 +28,11 @@ type ListController struct {
 }
 
 type Credential struct {
-	IP                                     string
-	SSH                                    SSH
-	Disabled                               bool
+	backend.Credential
 	HiddenTagGuiSystemHostCredentialEdit   string
 	HiddenTagGuiSystemHostCredentialDelete string
 }
 
-type SSH struct {
-	Port     int
-	User     string
-	Password string
-}
-
 type ByCredential []Credential
 
 func (b ByCredential) Len() int           { return len(b) }
@@ -60,18 +52,7 @@ func (c *ListController) Get() {
 	hasGuiSystemHostCredentialEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/host/credential/edit")
 	hasGuiSystemHostCredentialDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/host/credential/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/hosts/credentials/"
-
-	credentialSlice := make([]Credential, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &credentialSlice, tokenHeaderMap)
+	backendCredentialSlice, err := backend.NewCloudoneClient(c.Ctx).GetHostCredentialSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -79,8 +60,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		credentialSlice := make([]Credential, 0)
+		for _, backendCredential := range backendCredentialSlice {
+			credentialSlice = append(credentialSlice, Credential{Credential: backendCredential})
+		}
+
 		for i := 0; i < len(credentialSlice); i++ {
 			if hasGuiSystemHostCredentialEdit {
 				credentialSlice[i].HiddenTagGuiSystemHostCredentialEdit = "<div class='btn-group'>"
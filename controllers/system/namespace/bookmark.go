@@ -17,22 +17,18 @@ package namespace
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type BookmarkController struct {
 	beego.Controller
 }
 
-func (c *BookmarkController) Get() {
+func (c *BookmarkController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 
 	sessionUser, _ := c.GetSession("user").(*rbac.User)
@@ -44,16 +40,19 @@ func (c *BookmarkController) Get() {
 		return
 	}
 
-	userName := sessionUser.Name
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/users/" + userName
+	if identity.IsNamespacePermitted(sessionUser, name) == false {
+		// Error
+		guimessage.AddDanger("User " + sessionUser.Name + " is not authorized to namespace " + name)
+		c.Ctx.Redirect(302, "/gui/system/namespace/list")
+		guimessage.RedirectMessage(c)
+		return
+	}
 
-	user := rbac.User{}
+	userName := sessionUser.Name
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &user, tokenHeaderMap)
+	user, err := cloudoneClient.GetUser(userName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -61,7 +60,7 @@ func (c *BookmarkController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		c.Ctx.Redirect(302, "/gui/system/namespace/list")
 		guimessage.RedirectMessage(c)
 		return
@@ -73,10 +72,7 @@ func (c *BookmarkController) Get() {
 	}
 	metaDataMap["loginNamespace"] = name
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/users/" + userName + "/metadata"
-
-	_, err = restclient.RequestPutWithStructure(url, metaDataMap, nil, tokenHeaderMap)
+	err = cloudoneClient.UpdateUserMetaData(userName, metaDataMap)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -84,7 +80,7 @@ func (c *BookmarkController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		c.Ctx.Redirect(302, "/gui/system/namespace/list")
 		guimessage.RedirectMessage(c)
 		return
@@ -93,6 +89,10 @@ func (c *BookmarkController) Get() {
 	// Set session
 	sessionUser.MetaDataMap = metaDataMap
 	c.SetSession("user", sessionUser)
+	identityUser, _ := c.GetSession("identityUser").(*rbac.User)
+	if identityUser != nil {
+		identityUser.MetaDataMap = metaDataMap
+	}
 
 	guimessage.AddSuccess("Bookmark the namespace " + name + " as the login namespace")
 
@@ -16,13 +16,9 @@ package namespace
 
 import (
 	"github.com/astaxie/beego"
-	"github.com/cloudawan/cloudone_gui/controllers/deploy/autoscaler"
-	"github.com/cloudawan/cloudone_gui/controllers/deploy/deploy"
-	"github.com/cloudawan/cloudone_gui/controllers/deploy/deployclusterapplication"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_gui/controllers/notification/notifier"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"time"
 )
 
@@ -30,24 +26,15 @@ type DeleteController struct {
 	beego.Controller
 }
 
-func (c *DeleteController) Get() {
+func (c *DeleteController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
 	name := c.GetString("name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
 	// Delete deploy
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/" + name
-
-	deployInformationSlice := make([]deploy.DeployInformation, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := cloudoneClient.GetDeployInformationSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -55,30 +42,23 @@ func (c *DeleteController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		for _, deployInformation := range deployInformationSlice {
-			url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-				"/api/v1/deploys/" + name + "/" + deployInformation.ImageInformationName
-
-			_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+			err := cloudoneClient.DeleteDeploy(name, deployInformation.ImageInformationName)
 
 			if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 				return
 			}
 
 			if err != nil {
-				guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+				guimessage.AddError(err)
 			}
 		}
 	}
 
 	// Delete third party service
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/" + name
-
-	deployClusterApplicationSlice := make([]deployclusterapplication.DeployClusterApplication, 0)
-	_, err = restclient.RequestGetWithStructure(url, &deployClusterApplicationSlice, tokenHeaderMap)
+	deployClusterApplicationSlice, err := cloudoneClient.GetDeployClusterApplicationSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -86,29 +66,23 @@ func (c *DeleteController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		for _, deployClusterApplication := range deployClusterApplicationSlice {
-			url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-				"/api/v1/deployclusterapplications/" + name + "/" + deployClusterApplication.Name
-
-			_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+			err := cloudoneClient.DeleteDeployClusterApplication(name, deployClusterApplication.Name)
 
 			if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 				return
 			}
 
 			if err != nil {
-				guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+				guimessage.AddError(err)
 			}
 		}
 	}
 
 	// Delete namespace
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/namespaces/" + name
-
-	_, err = restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+	err = cloudoneClient.DeleteNamespace(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -116,22 +90,21 @@ func (c *DeleteController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Namespace " + name + " is deleted")
 
 		selectedNamespace := c.GetSession("namespace")
 		if selectedNamespace.(string) == name {
-			c.SetSession("namespace", "default")
+			err = identity.SetNamespace(c, "default")
+			if err != nil {
+				guimessage.AddDanger(err.Error() + ". Please select another namespace")
+			}
 		}
 	}
 
 	// Delete autoscaler
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/"
-
-	replicationControllerAutoScalerSlice := make([]autoscaler.ReplicationControllerAutoScaler, 0)
-	_, err = restclient.RequestGetWithStructure(url, &replicationControllerAutoScalerSlice, tokenHeaderMap)
+	replicationControllerAutoScalerSlice, err := cloudoneClient.GetAutoScalerSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -139,32 +112,25 @@ func (c *DeleteController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		for _, replicationControllerAutoScaler := range replicationControllerAutoScalerSlice {
 			if replicationControllerAutoScaler.Namespace == name {
-				url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-					"/api/v1/autoscalers/" + name + "/" + replicationControllerAutoScaler.Kind + "/" + replicationControllerAutoScaler.Name
-
-				_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+				err := cloudoneClient.DeleteAutoScaler(name, replicationControllerAutoScaler.Kind, replicationControllerAutoScaler.Name)
 
 				if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 					return
 				}
 
 				if err != nil {
-					guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+					guimessage.AddError(err)
 				}
 			}
 		}
 	}
 
 	// Delete notifier
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/"
-
-	replicationControllerNotifierSlice := make([]notifier.ReplicationControllerNotifier, 0)
-	_, err = restclient.RequestGetWithStructure(url, &replicationControllerNotifierSlice, tokenHeaderMap)
+	replicationControllerNotifierSlice, err := cloudoneClient.GetNotifierSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -172,21 +138,18 @@ func (c *DeleteController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		for _, replicationControllerNotifier := range replicationControllerNotifierSlice {
 			if replicationControllerNotifier.Namespace == name {
-				url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-					"/api/v1/notifiers/" + name + "/" + replicationControllerNotifier.Kind + "/" + replicationControllerNotifier.Name
-
-				_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+				err := cloudoneClient.DeleteNotifier(name, replicationControllerNotifier.Kind, replicationControllerNotifier.Name)
 
 				if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 					return
 				}
 
 				if err != nil {
-					guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+					guimessage.AddError(err)
 				}
 			}
 		}
@@ -17,9 +17,9 @@ package namespace
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -62,18 +62,7 @@ func (c *ListController) Get() {
 	hasGuiSystemNamespaceBookmark := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/namespace/bookmark")
 	hasGuiSystemNamespaceDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/namespace/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/namespaces"
-
-	nameSlice := make([]string, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &nameSlice, tokenHeaderMap)
+	nameSlice, err := backend.NewCloudoneClient(c.Ctx).GetNamespaceNameSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -81,7 +70,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		selectedNamespace := c.GetSession("namespace")
 
@@ -119,7 +108,7 @@ func (c *ListController) Get() {
 				namespace.HiddenTagGuiSystemNamespaceDelete = "<div hidden>"
 			}
 
-			if user.HasResource(identity.GetConponentName(), "/namespaces/"+namespace.Name) {
+			if identity.IsNamespacePermitted(user, namespace.Name) {
 				namespaceSlice = append(namespaceSlice, namespace)
 			}
 		}
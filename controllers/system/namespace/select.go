@@ -16,6 +16,7 @@ package namespace
 
 import (
 	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
@@ -23,13 +24,17 @@ type SelectController struct {
 	beego.Controller
 }
 
-func (c *SelectController) Get() {
+func (c *SelectController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
 	name := c.GetString("name")
 
-	c.SetSession("namespace", name)
-	guimessage.AddSuccess("Use namespace " + name)
+	err := identity.SetNamespace(c, name)
+	if err != nil {
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Use namespace " + name)
+	}
 
 	// Redirect to list
 	c.Ctx.Redirect(302, "/gui/system/namespace/list")
@@ -17,8 +17,8 @@ package emailserver
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type CreateController struct {
@@ -38,31 +38,21 @@ func (c *CreateController) Get() {
 func (c *CreateController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 	account := c.GetString("account")
 	password := c.GetString("password")
 	host := c.GetString("host")
 	port, _ := c.GetInt("port")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/emailserversmtp/"
-
-	emailServerSMTP := EmailServerSMTP{
-		name,
-		account,
-		password,
-		host,
-		port,
-		"",
+	emailServerSMTP := backend.EmailServerSMTP{
+		Name:     name,
+		Account:  account,
+		Password: password,
+		Host:     host,
+		Port:     port,
 	}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPostWithStructure(url, emailServerSMTP, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).CreateEmailServerSMTP(emailServerSMTP)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -70,7 +60,7 @@ func (c *CreateController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("Email server configuration " + name + " is created")
 	}
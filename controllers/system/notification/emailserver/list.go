@@ -17,9 +17,9 @@ package emailserver
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,11 +28,7 @@ type ListController struct {
 }
 
 type EmailServerSMTP struct {
-	Name                                            string
-	Account                                         string
-	Password                                        string
-	Host                                            string
-	Port                                            int
+	backend.EmailServerSMTP
 	HiddenTagGuiSystemNotificationEmailserverDelete string
 }
 
@@ -56,18 +52,7 @@ func (c *ListController) Get() {
 	// Tag won't work in loop so need to be placed in data
 	hasGuiSystemNotificationEmailserverDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/notification/emailserver/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/emailserversmtp/"
-
-	emailServerSMTPSlice := make([]EmailServerSMTP, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &emailServerSMTPSlice, tokenHeaderMap)
+	backendEmailServerSMTPSlice, err := backend.NewCloudoneClient(c.Ctx).GetEmailServerSMTPSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -75,8 +60,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		emailServerSMTPSlice := make([]EmailServerSMTP, 0)
+		for _, backendEmailServerSMTP := range backendEmailServerSMTPSlice {
+			emailServerSMTPSlice = append(emailServerSMTPSlice, EmailServerSMTP{EmailServerSMTP: backendEmailServerSMTP})
+		}
+
 		for i := 0; i < len(emailServerSMTPSlice); i++ {
 			if hasGuiSystemNotificationEmailserverDelete {
 				emailServerSMTPSlice[i].HiddenTagGuiSystemNotificationEmailserverDelete = "<div class='btn-group'>"
@@ -17,8 +17,8 @@ package sms
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type CreateController struct {
@@ -38,29 +38,19 @@ func (c *CreateController) Get() {
 func (c *CreateController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 	urlPath := c.GetString("url")
 	apiKey := c.GetString("apiKey")
 	apiSecret := c.GetString("apiSecret")
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/smsnexmo/"
-
-	smsNexmo := SMSNexmo{
-		name,
-		urlPath,
-		apiKey,
-		apiSecret,
-		"",
+	smsNexmo := backend.SMSNexmo{
+		Name:      name,
+		Url:       urlPath,
+		APIKey:    apiKey,
+		APISecret: apiSecret,
 	}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPostWithStructure(url, smsNexmo, nil, tokenHeaderMap)
+	err := backend.NewCloudoneClient(c.Ctx).CreateSMSNexmo(smsNexmo)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -68,7 +58,7 @@ func (c *CreateController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("SMS configuration " + name + " is created")
 	}
@@ -17,9 +17,9 @@ package sms
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,10 +28,7 @@ type ListController struct {
 }
 
 type SMSNexmo struct {
-	Name                                    string
-	Url                                     string
-	APIKey                                  string
-	APISecret                               string
+	backend.SMSNexmo
 	HiddenTagGuiSystemNotificationSMSDelete string
 }
 
@@ -55,18 +52,7 @@ func (c *ListController) Get() {
 	// Tag won't work in loop so need to be placed in data
 	hasGuiSystemNotificationSMSDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/notification/sms/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/smsnexmo/"
-
-	smsNexmoSlice := make([]SMSNexmo, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &smsNexmoSlice, tokenHeaderMap)
+	backendSMSNexmoSlice, err := backend.NewCloudoneClient(c.Ctx).GetSMSNexmoSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -74,8 +60,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		smsNexmoSlice := make([]SMSNexmo, 0)
+		for _, backendSMSNexmo := range backendSMSNexmoSlice {
+			smsNexmoSlice = append(smsNexmoSlice, SMSNexmo{SMSNexmo: backendSMSNexmo})
+		}
+
 		for i := 0; i < len(smsNexmoSlice); i++ {
 			if hasGuiSystemNotificationSMSDelete {
 				smsNexmoSlice[i].HiddenTagGuiSystemNotificationSMSDelete = "<div class='btn-group'>"
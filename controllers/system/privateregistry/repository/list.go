@@ -17,9 +17,9 @@ package repository
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -55,18 +55,7 @@ func (c *ListController) Get() {
 	hasGuiSystemPrivateRegistryImageList := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/privateregistry/image/list")
 	hasGuiSystemPrivateRegistryRepositoryDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/privateregistry/repository/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/privateregistries/servers/" + serverName + "/repositories/"
-
-	nameSlice := make([]string, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &nameSlice, tokenHeaderMap)
+	nameSlice, err := backend.NewCloudoneClient(c.Ctx).GetPrivateRegistryRepositoryNameSlice(serverName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -74,7 +63,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 
 		repositorySlice := make([]Repository, 0)
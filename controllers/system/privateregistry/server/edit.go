@@ -17,8 +17,8 @@ package server
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
@@ -39,18 +39,7 @@ func (c *EditController) Get() {
 		c.Data["pageHeader"] = "Create Server Configuration"
 		c.Data["createOrUpdate"] = "create"
 	} else {
-		cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-		cloudoneHost := beego.AppConfig.String("cloudoneHost")
-		cloudonePort := beego.AppConfig.String("cloudonePort")
-
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/privateregistries/servers/" + serverName
-
-		privateRegistry := PrivateRegistry{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &privateRegistry, tokenHeaderMap)
+		privateRegistry, err := backend.NewCloudoneClient(c.Ctx).GetPrivateRegistry(serverName)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -58,7 +47,7 @@ func (c *EditController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			guimessage.OutputMessage(c.Data)
 			return
 		}
@@ -80,31 +69,19 @@ func (c *EditController) Get() {
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	name := c.GetString("name")
 	host := c.GetString("host")
 	port, _ := c.GetInt("port")
 	createOrUpdate := c.GetString("createOrUpdate")
 
-	privateRegistry := PrivateRegistry{
-		name,
-		host,
-		port,
-		"",
-		"",
-		"",
+	privateRegistry := backend.PrivateRegistry{
+		Name: name,
+		Host: host,
+		Port: port,
 	}
 
 	if createOrUpdate == "create" {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/privateregistries/servers/"
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestPostWithStructure(url, privateRegistry, nil, tokenHeaderMap)
+		err := backend.NewCloudoneClient(c.Ctx).CreatePrivateRegistry(privateRegistry)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -112,17 +89,12 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("Private register server configuration " + name + " is created")
 		}
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/privateregistries/servers/" + name
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestPutWithStructure(url, privateRegistry, nil, tokenHeaderMap)
+		err := backend.NewCloudoneClient(c.Ctx).UpdatePrivateRegistry(privateRegistry)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -130,7 +102,7 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("Private register server configuration " + name + " is updated")
 		}
@@ -17,9 +17,9 @@ package server
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,9 +28,7 @@ type ListController struct {
 }
 
 type PrivateRegistry struct {
-	Name                                            string
-	Host                                            string
-	Port                                            int
+	backend.PrivateRegistry
 	HiddenTagGuiSystemPrivateRegistryRepositoryList string
 	HiddenTagGuiSystemPrivateRegistryServerEdit     string
 	HiddenTagGuiSystemPrivateRegistryServerDelete   string
@@ -56,18 +54,7 @@ func (c *ListController) Get() {
 	hasGuiSystemPrivateRegistryServerEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/privateregistry/server/edit")
 	hasGuiSystemPrivateRegistryServerDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/privateregistry/server/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/privateregistries/servers/"
-
-	privateRegistrySlice := make([]PrivateRegistry, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &privateRegistrySlice, tokenHeaderMap)
+	backendPrivateRegistrySlice, err := backend.NewCloudoneClient(c.Ctx).GetPrivateRegistrySlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -75,8 +62,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		privateRegistrySlice := make([]PrivateRegistry, 0)
+		for _, backendPrivateRegistry := range backendPrivateRegistrySlice {
+			privateRegistrySlice = append(privateRegistrySlice, PrivateRegistry{PrivateRegistry: backendPrivateRegistry})
+		}
+
 		for i := 0; i < len(privateRegistrySlice); i++ {
 			if hasGuiSystemPrivateRegistryRepositoryList {
 				privateRegistrySlice[i].HiddenTagGuiSystemPrivateRegistryRepositoryList = "<div class='btn-group'>"
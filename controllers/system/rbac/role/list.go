@@ -17,9 +17,9 @@ package role
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -55,18 +55,7 @@ func (c *ListController) Get() {
 	hasGuiSystemRBACRoleEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/rbac/role/edit")
 	hasGuiSystemRBACRoleDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/rbac/role/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/roles"
-
-	simplifiedRoleSlice := make([]SimplifiedRole, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &simplifiedRoleSlice, tokenHeaderMap)
+	roleSlice, err := backend.NewCloudoneClient(c.Ctx).GetRoleSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -74,19 +63,29 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
-		for i := 0; i < len(simplifiedRoleSlice); i++ {
+		simplifiedRoleSlice := make([]SimplifiedRole, 0)
+		for _, role := range roleSlice {
+			simplifiedRole := SimplifiedRole{
+				role.Name,
+				role.Description,
+				"",
+				"",
+			}
+
 			if hasGuiSystemRBACRoleEdit {
-				simplifiedRoleSlice[i].HiddenTagGuiSystemRBACRoleEdit = "<div class='btn-group'>"
+				simplifiedRole.HiddenTagGuiSystemRBACRoleEdit = "<div class='btn-group'>"
 			} else {
-				simplifiedRoleSlice[i].HiddenTagGuiSystemRBACRoleEdit = "<div hidden>"
+				simplifiedRole.HiddenTagGuiSystemRBACRoleEdit = "<div hidden>"
 			}
 			if hasGuiSystemRBACRoleDelete {
-				simplifiedRoleSlice[i].HiddenTagGuiSystemRBACRoleDelete = "<div class='btn-group'>"
+				simplifiedRole.HiddenTagGuiSystemRBACRoleDelete = "<div class='btn-group'>"
 			} else {
-				simplifiedRoleSlice[i].HiddenTagGuiSystemRBACRoleDelete = "<div hidden>"
+				simplifiedRole.HiddenTagGuiSystemRBACRoleDelete = "<div hidden>"
 			}
+
+			simplifiedRoleSlice = append(simplifiedRoleSlice, simplifiedRole)
 		}
 
 		sort.Sort(BySimplifiedRole(simplifiedRoleSlice))
@@ -0,0 +1,45 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+)
+
+type DeleteController struct {
+	beego.Controller
+}
+
+func (c *DeleteController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	id := c.GetString("id")
+	userName := c.GetString("userName")
+
+	err := identity.RevokeSession(id)
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Session of user " + userName + " is terminated")
+	}
+
+	// Redirect to list
+	c.Ctx.Redirect(302, "/gui/system/rbac/session/list")
+
+	guimessage.RedirectMessage(c)
+}
@@ -0,0 +1,67 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_utility/rbac"
+)
+
+type ListController struct {
+	beego.Controller
+}
+
+type SimplifiedSession struct {
+	ID               string
+	UserName         string
+	IP               string
+	Cluster          string
+	LoginTime        string
+	LastActivityTime string
+	Current          bool
+}
+
+func (c *ListController) Get() {
+	c.TplName = "system/rbac/session/list.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+	// System RBAC tab menu
+	user, _ := c.GetSession("user").(*rbac.User)
+	c.Data["systemRBACTabMenu"] = identity.GetSystemRBACTabMenu(user, "session")
+	// Authorization for Button
+	identity.SetPrivilegeHiddenTag(c.Data, "hiddenTagGuiSystemRBACSessionDelete", user, "GET", "/gui/system/rbac/session/delete")
+
+	currentSessionID := c.CruSession.SessionID()
+
+	simplifiedSessionSlice := make([]SimplifiedSession, 0)
+	for _, activeSession := range identity.GetActiveSessionSlice() {
+		simplifiedSessionSlice = append(simplifiedSessionSlice, SimplifiedSession{
+			activeSession.ID,
+			activeSession.UserName,
+			activeSession.IP,
+			activeSession.Cluster,
+			activeSession.LoginTime.Format("2006-01-02 15:04:05"),
+			activeSession.LastActivityTime.Format("2006-01-02 15:04:05"),
+			activeSession.IsSession(currentSessionID),
+		})
+	}
+	c.Data["simplifiedSessionSlice"] = simplifiedSessionSlice
+
+	guimessage.OutputMessage(c.Data)
+}
@@ -17,10 +17,11 @@ package user
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -38,6 +39,8 @@ type Role struct {
 type Namespace struct {
 	Name string
 	Tag  string
+	// Roles granted only in this namespace
+	RoleSlice []Role
 }
 
 type ByRole []Role
@@ -66,18 +69,9 @@ func (c *EditController) Get() {
 	action := c.GetString("action")
 	name := c.GetString("name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/roles"
-
-	roleSlice := make([]Role, 0)
-
-	_, err := restclient.RequestGetWithStructure(url, &roleSlice, tokenHeaderMap)
+	rbacRoleSlice, err := cloudoneClient.GetRoleSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -85,17 +79,13 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		c.Ctx.Redirect(302, "/gui/system/rbac/user/list")
 		guimessage.RedirectMessage(c)
 		return
 	}
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/namespaces"
-
-	namespaceNameSlice := make([]string, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &namespaceNameSlice, tokenHeaderMap)
+	namespaceNameSlice, err := cloudoneClient.GetNamespaceNameSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -103,21 +93,29 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		c.Ctx.Redirect(302, "/gui/system/rbac/user/list")
 		guimessage.RedirectMessage(c)
 		return
 	}
 
+	roleSlice := make([]Role, 0)
+	for _, rbacRole := range rbacRoleSlice {
+		roleSlice = append(roleSlice, Role{rbacRole.Name, rbacRole.Description, ""})
+	}
+
+	sort.Sort(ByRole(roleSlice))
+
 	loginNamespaceSlice := make([]Namespace, 0)
 	namespaceSlice := make([]Namespace, 0)
-	namespaceSlice = append(namespaceSlice, Namespace{"*", ""})
+	namespaceSlice = append(namespaceSlice, Namespace{"*", "", nil})
 	for _, namespaceName := range namespaceNameSlice {
-		namespaceSlice = append(namespaceSlice, Namespace{namespaceName, ""})
-		loginNamespaceSlice = append(loginNamespaceSlice, Namespace{namespaceName, ""})
+		namespaceRoleSlice := make([]Role, len(roleSlice))
+		copy(namespaceRoleSlice, roleSlice)
+		namespaceSlice = append(namespaceSlice, Namespace{namespaceName, "", namespaceRoleSlice})
+		loginNamespaceSlice = append(loginNamespaceSlice, Namespace{namespaceName, "", nil})
 	}
 
-	sort.Sort(ByRole(roleSlice))
 	sort.Sort(ByNamespace(namespaceSlice))
 
 	c.Data["action"] = action
@@ -129,12 +127,7 @@ func (c *EditController) Get() {
 		c.Data["actionButtonValue"] = "Create"
 		c.Data["pageHeader"] = "Create User"
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/authorizations/users/" + name
-
-		user := rbac.User{}
-
-		_, err = restclient.RequestGetWithStructure(url, &user, tokenHeaderMap)
+		user, err := cloudoneClient.GetUser(name)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -142,7 +135,7 @@ func (c *EditController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			c.Ctx.Redirect(302, "/gui/system/rbac/user/list")
 			guimessage.RedirectMessage(c)
 			return
@@ -178,6 +171,20 @@ func (c *EditController) Get() {
 			metaDataMap = make(map[string]string)
 		}
 
+		namespaceRoleMap, err := identity.ParseNamespaceRole(metaDataMap[identity.NamespaceRoleMetaDataKey])
+		if err != nil {
+			guimessage.AddError(err)
+		}
+		for i := 0; i < len(namespaceSlice); i++ {
+			for _, roleName := range namespaceRoleMap[namespaceSlice[i].Name] {
+				for j := 0; j < len(namespaceSlice[i].RoleSlice); j++ {
+					if namespaceSlice[i].RoleSlice[j].Name == roleName {
+						namespaceSlice[i].RoleSlice[j].Tag = "selected"
+					}
+				}
+			}
+		}
+
 		loginNamespace := metaDataMap["loginNamespace"]
 		if len(loginNamespace) > 0 {
 			for i := 0; i < len(loginNamespaceSlice); i++ {
@@ -231,15 +238,12 @@ func (c *EditController) Post() {
 		expiredTime = &expiredTimeData
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	roleSlice := make([]*rbac.Role, 0)
 	resourceSlice := make([]*rbac.Resource, 0)
 
 	namespaceNameSlice := make([]string, 0)
 	hasNamespaceNameAll := false
+	namespaceRoleMap := make(map[string][]string)
 
 	inputMap := c.Input()
 	if inputMap != nil {
@@ -247,7 +251,7 @@ func (c *EditController) Post() {
 			if strings.HasPrefix(key, "role_") {
 				roleName := key[len("role_"):]
 				if value[0] == "on" {
-					roleSlice = append(roleSlice, &rbac.Role{roleName, nil, ""})
+					roleSlice = append(roleSlice, &rbac.Role{Name: roleName, PermissionSlice: nil, Description: ""})
 				}
 			}
 			if strings.HasPrefix(key, "namespace_") {
@@ -259,14 +263,31 @@ func (c *EditController) Post() {
 					}
 				}
 			}
+			if strings.HasPrefix(key, "namespaceRole_") {
+				namespaceName := key[len("namespaceRole_"):]
+				namespaceRoleMap[namespaceName] = value
+			}
+		}
+	}
+
+	// The namespace with roles granted in it is accessible
+	for namespaceName := range namespaceRoleMap {
+		found := false
+		for _, existingNamespaceName := range namespaceNameSlice {
+			if existingNamespaceName == namespaceName {
+				found = true
+			}
+		}
+		if found == false {
+			namespaceNameSlice = append(namespaceNameSlice, namespaceName)
 		}
 	}
 
 	if hasNamespaceNameAll {
-		resourceSlice = append(resourceSlice, &rbac.Resource{"namespace_*", "*", "/namespaces/"})
+		resourceSlice = append(resourceSlice, &rbac.Resource{Name: "namespace_*", Component: "*", Path: "/namespaces/"})
 	} else {
 		for _, namespaceName := range namespaceNameSlice {
-			resourceSlice = append(resourceSlice, &rbac.Resource{"namespace_" + namespaceName, "*", "/namespaces/" + namespaceName})
+			resourceSlice = append(resourceSlice, &rbac.Resource{Name: "namespace_" + namespaceName, Component: "*", Path: "/namespaces/" + namespaceName})
 		}
 	}
 
@@ -279,6 +300,38 @@ func (c *EditController) Post() {
 		metaDataMap["githubWebhookSecret"] = githubWebhookSecret
 	}
 
+	namespaceRole := identity.FormatNamespaceRole(namespaceRoleMap)
+	if len(namespaceRole) > 0 {
+		metaDataMap[identity.NamespaceRoleMetaDataKey] = namespaceRole
+	}
+
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
+
+	accessChanged := false
+	if action != "create" {
+		// Personal access tokens are managed in their own page so they are kept
+		existingUser, err := cloudoneClient.GetUser(name)
+
+		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+			return
+		}
+
+		if err != nil {
+			// Error
+			guimessage.AddError(err)
+			c.Ctx.Redirect(302, "/gui/system/rbac/user/list")
+			guimessage.RedirectMessage(c)
+			return
+		}
+
+		personalAccessToken := existingUser.MetaDataMap[identity.PersonalAccessTokenMetaDataKey]
+		if len(personalAccessToken) > 0 {
+			metaDataMap[identity.PersonalAccessTokenMetaDataKey] = personalAccessToken
+		}
+
+		accessChanged = isRoleChanged(existingUser, roleSlice, namespaceRole) || isExpiredTimeChanged(existingUser.ExpiredTime, expiredTime)
+	}
+
 	user := rbac.User{
 		name,
 		password,
@@ -290,19 +343,10 @@ func (c *EditController) Post() {
 		disabled,
 	}
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
 	if action == "create" {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/authorizations/users"
-
-		_, err = restclient.RequestPostWithStructure(url, user, nil, tokenHeaderMap)
-
+		err = cloudoneClient.CreateUser(user)
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/authorizations/users/" + name
-
-		_, err = restclient.RequestPutWithStructure(url, user, nil, tokenHeaderMap)
+		err = cloudoneClient.UpdateUser(user)
 	}
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
@@ -311,12 +355,50 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
 		guimessage.AddSuccess("User " + name + " is edited")
+		// The roles and the expired time are copied into the session when login so the session is revoked to apply the change
+		if disabled || accessChanged {
+			revokeSession(guimessage, name)
+		}
 	}
 
 	c.Ctx.Redirect(302, "/gui/system/rbac/user/list")
 
 	guimessage.RedirectMessage(c)
 }
+
+func isRoleChanged(existingUser *rbac.User, roleSlice []*rbac.Role, namespaceRole string) bool {
+	if existingUser.MetaDataMap[identity.NamespaceRoleMetaDataKey] != namespaceRole {
+		return true
+	}
+
+	existingRoleNameSlice := make([]string, 0)
+	for _, role := range existingUser.RoleSlice {
+		existingRoleNameSlice = append(existingRoleNameSlice, role.Name)
+	}
+	roleNameSlice := make([]string, 0)
+	for _, role := range roleSlice {
+		roleNameSlice = append(roleNameSlice, role.Name)
+	}
+	sort.Strings(existingRoleNameSlice)
+	sort.Strings(roleNameSlice)
+
+	return strings.Join(existingRoleNameSlice, ",") != strings.Join(roleNameSlice, ",")
+}
+
+// isExpiredTimeChanged compares in the format of the time picker since the form only has the precision of it
+func isExpiredTimeChanged(existingExpiredTime *time.Time, expiredTime *time.Time) bool {
+	if existingExpiredTime == nil || expiredTime == nil {
+		return existingExpiredTime != expiredTime
+	}
+	return existingExpiredTime.Format(guiWidgetTimePickerFormat) != expiredTime.Format(guiWidgetTimePickerFormat)
+}
+
+func revokeSession(guimessage *guimessagedisplay.GUIMessage, name string) {
+	amount := identity.RevokeUserSession(name)
+	if amount > 0 {
+		guimessage.AddInfo(strconv.Itoa(amount) + " session(s) of user " + name + " are revoked")
+	}
+}
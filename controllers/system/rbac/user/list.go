@@ -17,9 +17,9 @@ package user
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 	"strings"
 )
@@ -37,6 +37,7 @@ type SimplifiedUser struct {
 	Description                      string
 	HiddenTagGuiSystemRBACUserEdit   string
 	HiddenTagGuiSystemRBACUserDelete string
+	HiddenTagGuiSystemRBACUserToken  string
 }
 
 type BySimplifiedUser []SimplifiedUser
@@ -59,19 +60,11 @@ func (c *ListController) Get() {
 	// Tag won't work in loop so need to be placed in data
 	hasGuiSystemRBACUserEdit := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/rbac/user/edit")
 	hasGuiSystemRBACUserDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/rbac/user/delete")
+	hasGuiSystemRBACUserToken := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/rbac/user/token/list")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/users"
-
-	userSlice := make([]rbac.User, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &userSlice, tokenHeaderMap)
+	userSlice, err := cloudoneClient.GetUserSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -79,20 +72,16 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/namespaces"
-
-		namespaceNameSlice := make([]string, 0)
-
-		_, err = restclient.RequestGetWithStructure(url, &namespaceNameSlice, tokenHeaderMap)
+		_, err = cloudoneClient.GetNamespaceNameSlice()
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
 		}
 
 		if err != nil {
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			simplifiedUserSlice := make([]SimplifiedUser, 0)
 			for _, user := range userSlice {
@@ -131,6 +120,7 @@ func (c *ListController) Get() {
 					user.Description,
 					"",
 					"",
+					"",
 				}
 
 				if hasGuiSystemRBACUserEdit {
@@ -143,6 +133,11 @@ func (c *ListController) Get() {
 				} else {
 					simplifiedUser.HiddenTagGuiSystemRBACUserDelete = "<div hidden>"
 				}
+				if hasGuiSystemRBACUserToken {
+					simplifiedUser.HiddenTagGuiSystemRBACUserToken = "<div class='btn-group'>"
+				} else {
+					simplifiedUser.HiddenTagGuiSystemRBACUserToken = "<div hidden>"
+				}
 
 				simplifiedUserSlice = append(simplifiedUserSlice, simplifiedUser)
 			}
@@ -0,0 +1,52 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"net/url"
+)
+
+type DeleteController struct {
+	beego.Controller
+}
+
+func (c *DeleteController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	name := c.GetString("name")
+	id := c.GetString("id")
+
+	err := identity.RevokePersonalAccessToken(backend.NewCloudoneClient(c.Ctx), name, id)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		guimessage.AddSuccess("Personal access token " + id + " of user " + name + " is revoked")
+	}
+
+	// Redirect to list
+	c.Ctx.Redirect(302, "/gui/system/rbac/user/token/list?name="+url.QueryEscape(name))
+
+	guimessage.RedirectMessage(c)
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"net/url"
+	"time"
+)
+
+type EditController struct {
+	beego.Controller
+}
+
+const (
+	guiWidgetTimePickerFormat = "01/02/2006 15:04 PM"
+)
+
+func (c *EditController) Get() {
+	c.TplName = "system/rbac/user/token/edit.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+
+	c.Data["name"] = c.GetString("name")
+	c.Data["scopeSlice"] = identity.GetPersonalAccessTokenScopeSlice()
+	c.Data["actionButtonValue"] = "Create"
+	c.Data["pageHeader"] = "Create Personal Access Token"
+
+	guimessage.OutputMessage(c.Data)
+}
+
+func (c *EditController) Post() {
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	name := c.GetString("name")
+	tokenName := c.GetString("tokenName")
+	expiredTimeText := c.GetString("expiredTime")
+	scopeSlice := c.GetStrings("scope")
+
+	var expiredTime *time.Time = nil
+	expiredTimeData, err := time.Parse(guiWidgetTimePickerFormat, expiredTimeText)
+	if err == nil {
+		expiredTime = &expiredTimeData
+	}
+
+	token, err := identity.CreatePersonalAccessToken(backend.NewCloudoneClient(c.Ctx), name, tokenName, scopeSlice, expiredTime)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		// Only the hash is stored so the token can't be shown again
+		guimessage.AddSuccess("Personal access token " + tokenName + " is created. Copy it now since it won't be shown again: " + token)
+	}
+
+	c.Ctx.Redirect(302, "/gui/system/rbac/user/token/list?name="+url.QueryEscape(name))
+
+	guimessage.RedirectMessage(c)
+}
@@ -0,0 +1,111 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"sort"
+)
+
+type ListController struct {
+	beego.Controller
+}
+
+type SimplifiedPersonalAccessToken struct {
+	ID           string
+	Name         string
+	ScopeSlice   []string
+	CreatedTime  string
+	ExpiredTime  string
+	Expired      bool
+	LastUsedTime string
+	LastUsedIP   string
+}
+
+type BySimplifiedPersonalAccessToken []SimplifiedPersonalAccessToken
+
+func (b BySimplifiedPersonalAccessToken) Len() int           { return len(b) }
+func (b BySimplifiedPersonalAccessToken) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b BySimplifiedPersonalAccessToken) Less(i, j int) bool { return b[i].Name < b[j].Name }
+
+func (c *ListController) Get() {
+	c.TplName = "system/rbac/user/token/list.html"
+	guimessage := guimessagedisplay.GetGUIMessage(c)
+
+	// Authorization for web page display
+	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
+	// System RBAC tab menu
+	user, _ := c.GetSession("user").(*rbac.User)
+	c.Data["systemRBACTabMenu"] = identity.GetSystemRBACTabMenu(user, "user")
+	// Authorization for Button
+	identity.SetPrivilegeHiddenTag(c.Data, "hiddenTagGuiSystemRBACUserTokenEdit", user, "GET", "/gui/system/rbac/user/token/edit")
+	identity.SetPrivilegeHiddenTag(c.Data, "hiddenTagGuiSystemRBACUserTokenDelete", user, "GET", "/gui/system/rbac/user/token/delete")
+
+	name := c.GetString("name")
+	c.Data["name"] = name
+
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
+
+	owner, err := cloudoneClient.GetUser(name)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessage.AddError(err)
+	} else {
+		personalAccessTokenSlice, err := identity.ParsePersonalAccessTokenSlice(owner)
+		if err != nil {
+			guimessage.AddError(err)
+		} else {
+			identity.LoadPersonalAccessTokenUsage(personalAccessTokenSlice)
+
+			simplifiedPersonalAccessTokenSlice := make([]SimplifiedPersonalAccessToken, 0)
+			for _, personalAccessToken := range personalAccessTokenSlice {
+				expiredTimeText := ""
+				if personalAccessToken.ExpiredTime != nil {
+					expiredTimeText = personalAccessToken.ExpiredTime.String()
+				}
+
+				lastUsedTimeText := ""
+				if personalAccessToken.LastUsedTime != nil {
+					lastUsedTimeText = personalAccessToken.LastUsedTime.String()
+				}
+
+				simplifiedPersonalAccessTokenSlice = append(simplifiedPersonalAccessTokenSlice, SimplifiedPersonalAccessToken{
+					personalAccessToken.ID,
+					personalAccessToken.Name,
+					personalAccessToken.ScopeSlice,
+					personalAccessToken.CreatedTime.String(),
+					expiredTimeText,
+					personalAccessToken.IsExpired(),
+					lastUsedTimeText,
+					personalAccessToken.LastUsedIP,
+				})
+			}
+
+			sort.Sort(BySimplifiedPersonalAccessToken(simplifiedPersonalAccessTokenSlice))
+			c.Data["simplifiedPersonalAccessTokenSlice"] = simplifiedPersonalAccessTokenSlice
+		}
+	}
+
+	guimessage.OutputMessage(c.Data)
+}
@@ -17,8 +17,8 @@ package daemon
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strings"
 )
 
@@ -39,32 +39,39 @@ type Zone struct {
 }
 
 type Node struct {
-	Name    string
-	Address string
+	backend.Node
 	Checked string
 }
 
+func newRegionSlice(backendRegionSlice []backend.Region) []Region {
+	regionSlice := make([]Region, 0)
+	for _, backendRegion := range backendRegionSlice {
+		zoneSlice := make([]Zone, 0)
+		for _, backendZone := range backendRegion.ZoneSlice {
+			nodeSlice := make([]Node, 0)
+			for _, backendNode := range backendZone.NodeSlice {
+				nodeSlice = append(nodeSlice, Node{Node: backendNode})
+			}
+			zoneSlice = append(zoneSlice, Zone{backendZone.Name, backendZone.LocationTagged, nodeSlice})
+		}
+		regionSlice = append(regionSlice, Region{backendRegion.Name, backendRegion.LocationTagged, zoneSlice})
+	}
+	return regionSlice
+}
+
 func (c *EditController) Get() {
 	c.TplName = "system/slb/daemon/edit.html"
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	// Authorization for web page display
 	c.Data["layoutMenu"] = c.GetSession("layoutMenu")
 
 	name := c.GetString("name")
 
 	// Get node topology
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/nodes/topology"
-
-	regionSlice := make([]Region, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &regionSlice, tokenHeaderMap)
+	backendRegionSlice, err := cloudoneClient.GetRegionSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -72,29 +79,20 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 		guimessage.OutputMessage(c.Data)
 		return
 	}
 
+	regionSlice := newRegionSlice(backendRegionSlice)
+
 	if name == "" {
 		c.Data["actionButtonValue"] = "Create"
 		c.Data["pageHeader"] = "Create SLB Daemon"
 		c.Data["createOrUpdate"] = "create"
 		c.Data["regionSlice"] = regionSlice
 	} else {
-		cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-		cloudoneHost := beego.AppConfig.String("cloudoneHost")
-		cloudonePort := beego.AppConfig.String("cloudonePort")
-
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/slbs/daemons/" + name
-
-		slbDaemon := SLBDaemon{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &slbDaemon, tokenHeaderMap)
+		slbDaemon, err := cloudoneClient.GetSLBDaemon(name)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -102,7 +100,7 @@ func (c *EditController) Get() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 			guimessage.OutputMessage(c.Data)
 			return
 		}
@@ -147,10 +145,6 @@ func (c *EditController) Get() {
 func (c *EditController) Post() {
 	guimessage := guimessagedisplay.GetGUIMessage(c)
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	createOrUpdate := c.GetString("createOrUpdate")
 	name := c.GetString("name")
 	description := c.GetString("description")
@@ -176,23 +170,15 @@ func (c *EditController) Post() {
 		}
 	}
 
-	slbDaemon := SLBDaemon{
-		name,
-		endPointSlice,
-		nodeHostSlice,
-		description,
-		"",
-		"",
-		"",
+	slbDaemon := backend.SLBDaemon{
+		Name:          name,
+		EndPointSlice: endPointSlice,
+		NodeHostSlice: nodeHostSlice,
+		Description:   description,
 	}
 
 	if createOrUpdate == "create" {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/slbs/daemons/"
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestPostWithStructure(url, slbDaemon, nil, tokenHeaderMap)
+		err := backend.NewCloudoneClient(c.Ctx).CreateSLBDaemon(slbDaemon)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -200,17 +186,12 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("SLB daemon " + name + " is created")
 		}
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/slbs/daemons/" + name
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestPutWithStructure(url, slbDaemon, nil, tokenHeaderMap)
+		err := backend.NewCloudoneClient(c.Ctx).UpdateSLBDaemon(slbDaemon)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -218,7 +199,7 @@ func (c *EditController) Post() {
 
 		if err != nil {
 			// Error
-			guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+			guimessage.AddError(err)
 		} else {
 			guimessage.AddSuccess("SLB daemon " + name + " is updated")
 		}
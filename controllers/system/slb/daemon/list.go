@@ -17,9 +17,9 @@ package daemon
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_utility/rbac"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"sort"
 )
 
@@ -28,10 +28,7 @@ type ListController struct {
 }
 
 type SLBDaemon struct {
-	Name                                 string
-	EndPointSlice                        []string
-	NodeHostSlice                        []string
-	Description                          string
+	backend.SLBDaemon
 	HiddenTagGuiSystemSLBDaemonEdit      string
 	HiddenTagGuiSystemSLBDaemonConfigure string
 	HiddenTagGuiSystemSLBDaemonDelete    string
@@ -57,18 +54,7 @@ func (c *ListController) Get() {
 	hasGuiSystemSLBDaemonConfigure := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/slb/daemon/configure")
 	hasGuiSystemSLBDaemonDelete := user.HasPermission(identity.GetConponentName(), "GET", "/gui/system/slb/daemon/delete")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/slbs/daemons/"
-
-	slbDaemonSlice := make([]SLBDaemon, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &slbDaemonSlice, tokenHeaderMap)
+	backendSLBDaemonSlice, err := backend.NewCloudoneClient(c.Ctx).GetSLBDaemonSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -76,8 +62,13 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		guimessage.AddDanger(guimessagedisplay.GetErrorMessage(err))
+		guimessage.AddError(err)
 	} else {
+		slbDaemonSlice := make([]SLBDaemon, 0)
+		for _, backendSLBDaemon := range backendSLBDaemonSlice {
+			slbDaemonSlice = append(slbDaemonSlice, SLBDaemon{SLBDaemon: backendSLBDaemon})
+		}
+
 		for i := 0; i < len(slbDaemonSlice); i++ {
 			if hasGuiSystemSLBDaemonEdit {
 				slbDaemonSlice[i].HiddenTagGuiSystemSLBDaemonEdit = "<div class='btn-group'>"
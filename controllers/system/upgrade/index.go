@@ -20,15 +20,24 @@ import (
 	"fmt"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/shutdown"
 	"github.com/cloudawan/cloudone_utility/sshclient"
 	"golang.org/x/net/websocket"
 	"io/ioutil"
+	"strconv"
 	"time"
 )
 
+const (
+	upgradeTicketTarget = "upgrade"
+	// stopTimeoutMarginInSecond is added to the grace period for the GUI to flush the audit logs and the spans after draining
+	stopTimeoutMarginInSecond = 10
+)
+
 type IndexController struct {
 	beego.Controller
 }
@@ -42,67 +51,42 @@ func (c *IndexController) Get() {
 
 	cloudoneGUIHost, cloudoneGUIPort := dashboard.GetServerHostAndPortFromUserRequest(c.Ctx.Input)
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-	token, _ := tokenHeaderMap["token"]
+	ticket, err := identity.CreateWebSocketTicket(c.Ctx, upgradeTicketTarget)
+	if err != nil {
+		guimessage.AddError(err)
+	}
 
 	c.Data["cloudoneGUIHost"] = cloudoneGUIHost
 	c.Data["cloudoneGUIPort"] = cloudoneGUIPort
 
-	c.Data["token"] = token
+	c.Data["ticket"] = ticket
 
 	guimessage.OutputMessage(c.Data)
 }
 
-type Credential struct {
-	IP       string
-	SSH      SSH
-	Disabled bool
-}
-
-type SSH struct {
-	Port     int
-	User     string
-	Password string
-}
-
-type ReplicationControllerAndRelatedPod struct {
-	Name               string
-	Namespace          string
-	ReplicaAmount      int
-	AliveReplicaAmount int
-	Selector           map[string]string
-	Label              map[string]string
-	PodSlice           []Pod
-	Display            string
-}
-
-type Pod struct {
-	Name           string
-	Namespace      string
-	HostIP         string
-	PodIP          string
-	ContainerSlice []PodContainer
-}
-
-type PodContainer struct {
-	Name        string
-	Image       string
-	ContainerID string
-	PortSlice   []PodContainerPort
-}
-
-type PodContainerPort struct {
-	Name          string
-	ContainerPort int
-	Protocol      string
-}
-
 type WebSocketController struct {
 	beego.Controller
 }
 
 func (c *WebSocketController) Get() {
-	server := websocket.Server{Handler: ProxyServer}
+	tokenHeaderMap, cluster, err := identity.ExchangeWebSocketTicket(c.Ctx, c.GetString("ticket"), upgradeTicketTarget)
+	if err != nil {
+		c.Ctx.Output.SetStatus(403)
+		c.Ctx.Output.Body([]byte(err.Error()))
+		return
+	}
+
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer metrics.StartWebSocketSession(metrics.WebSocketKindUpgrade)()
+		endWebSocketSession, err := shutdown.StartWebSocketSession(ws, metrics.WebSocketKindUpgrade, true)
+		if err != nil {
+			ws.Write([]byte(err.Error() + "\n"))
+			ws.Close()
+			return
+		}
+		defer endWebSocketSession()
+		ProxyServer(ws, cluster, tokenHeaderMap)
+	}}
 	server.ServeHTTP(c.Ctx.ResponseWriter, c.Ctx.Request)
 }
 
@@ -115,7 +99,7 @@ func getParameter(parameterMap map[string][]string, name string) string {
 	}
 }
 
-func UpgradeDockerImage(ws *websocket.Conn, credentialSlice []Credential, path string, version string) error {
+func UpgradeDockerImage(ws *websocket.Conn, credentialSlice []backend.Credential, path string, version string) error {
 	if len(credentialSlice) == 0 {
 		return errors.New("No credential data")
 	}
@@ -135,7 +119,7 @@ func UpgradeDockerImage(ws *websocket.Conn, credentialSlice []Credential, path s
 
 	for _, credential := range credentialSlice {
 		if credential.Disabled == false {
-			go func(credential Credential) {
+			go func(credential backend.Credential) {
 				ws.Write([]byte("Start to pull " + imageUri + " on host " + credential.IP + "\n"))
 
 				commandSlice := make([]string, 0)
@@ -192,9 +176,14 @@ func UpgradeDockerImage(ws *websocket.Conn, credentialSlice []Credential, path s
 	}
 }
 
-func stopDockerContainer(credential Credential, containerID string) error {
+// stopDockerContainer waits for the container to stop until the timeout and then kills it. The docker default is used if the timeout is 0.
+func stopDockerContainer(credential backend.Credential, containerID string, timeoutInSecond int) error {
+	option := ""
+	if timeoutInSecond > 0 {
+		option = "-t " + strconv.Itoa(timeoutInSecond) + " "
+	}
 	commandSlice := make([]string, 0)
-	commandSlice = append(commandSlice, "sudo docker stop "+containerID+"\n")
+	commandSlice = append(commandSlice, "sudo docker stop "+option+containerID+"\n")
 	interactiveMap := make(map[string]string)
 	interactiveMap["[sudo]"] = credential.SSH.Password + "\n"
 
@@ -242,14 +231,7 @@ func configureCertificate(ws *websocket.Conn, httpsCertFileContent string, https
 	}
 }
 
-func ProxyServer(ws *websocket.Conn) {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
+func ProxyServer(ws *websocket.Conn, cluster string, tokenHeaderMap map[string]string) {
 	parameterMap := ws.Request().URL.Query()
 	upgradeCloudone := getParameter(parameterMap, "upgradeCloudone")
 	upgradeCloudoneImagePath := getParameter(parameterMap, "upgradeCloudoneImagePath")
@@ -269,9 +251,7 @@ func ProxyServer(ws *websocket.Conn) {
 	httpsCertFileContent := getParameter(parameterMap, "httpsCertFile")
 	httpsKeyFileContent := getParameter(parameterMap, "httpsKeyFile")
 
-	token := getParameter(parameterMap, "token")
-	headerMap := make(map[string]string)
-	headerMap["token"] = token
+	cloudoneClient := backend.NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap).WithCluster(cluster)
 
 	// Configre certificate
 	certificateChanged, err := configureCertificate(ws, httpsCertFileContent, httpsKeyFileContent)
@@ -309,12 +289,7 @@ func ProxyServer(ws *websocket.Conn) {
 	}
 
 	// Get credential
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/hosts/credentials/"
-
-	credentialSlice := make([]Credential, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &credentialSlice, headerMap)
+	credentialSlice, err := cloudoneClient.GetHostCredentialSlice()
 
 	if identity.IsTokenInvalid(err) {
 		ws.Write([]byte(err.Error()))
@@ -362,12 +337,9 @@ func ProxyServer(ws *websocket.Conn) {
 
 	// Update service
 	if len(serviceJsonMap) > 0 {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/services/json/" + upgradeNamespace + "/" + upgradeReplicationControllerName
-
 		ws.Write([]byte("Start to update service\n"))
 
-		_, err := restclient.RequestPutWithStructure(url, serviceJsonMap, nil, headerMap)
+		err := cloudoneClient.UpdateServiceWithJson(upgradeNamespace, upgradeReplicationControllerName, serviceJsonMap)
 
 		if identity.IsTokenInvalid(err) {
 			ws.Write([]byte(err.Error()))
@@ -387,12 +359,9 @@ func ProxyServer(ws *websocket.Conn) {
 
 	if len(replicationControllerJsonMap) > 0 {
 		// Update replication controller to change all instances
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/replicationcontrollers/json/" + upgradeNamespace + "/" + upgradeServiceName
-
 		ws.Write([]byte("Stop and recreate the replication controller. Please refresh the page after tens of seconds\n"))
 
-		_, err := restclient.RequestPutWithStructure(url, replicationControllerJsonMap, nil, headerMap)
+		err := cloudoneClient.UpdateReplicationControllerWithJson(upgradeNamespace, upgradeServiceName, replicationControllerJsonMap)
 
 		if identity.IsTokenInvalid(err) {
 			ws.Write([]byte(err.Error()))
@@ -410,11 +379,7 @@ func ProxyServer(ws *websocket.Conn) {
 		}
 	} else {
 		// Only update the specific docker containers
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/replicationcontrollers/" + upgradeNamespace
-
-		replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
-		_, err := restclient.RequestGetWithStructure(url, &replicationControllerAndRelatedPodSlice, headerMap)
+		replicationControllerAndRelatedPodSlice, err := cloudoneClient.GetReplicationControllerAndRelatedPodSlice(upgradeNamespace)
 
 		if identity.IsTokenInvalid(err) {
 			ws.Write([]byte(err.Error()))
@@ -428,7 +393,7 @@ func ProxyServer(ws *websocket.Conn) {
 			ws.Close()
 			return
 		}
-		selectedReplicationControllerAndRelatedPod := ReplicationControllerAndRelatedPod{}
+		selectedReplicationControllerAndRelatedPod := backend.ReplicationControllerAndRelatedPod{}
 		for _, replicationControllerAndRelatedPod := range replicationControllerAndRelatedPodSlice {
 			if replicationControllerAndRelatedPod.Name == upgradeReplicationControllerName {
 				selectedReplicationControllerAndRelatedPod = replicationControllerAndRelatedPod
@@ -436,7 +401,7 @@ func ProxyServer(ws *websocket.Conn) {
 		}
 
 		for _, pod := range selectedReplicationControllerAndRelatedPod.PodSlice {
-			usedCredential := Credential{}
+			usedCredential := backend.Credential{}
 			for _, credential := range credentialSlice {
 				if credential.IP == pod.HostIP {
 					usedCredential = credential
@@ -444,9 +409,9 @@ func ProxyServer(ws *websocket.Conn) {
 				}
 			}
 
-			cloudoneContainer := PodContainer{}
-			cloudoneAnalysisContainer := PodContainer{}
-			cloudoneGUIContainer := PodContainer{}
+			cloudoneContainer := backend.PodContainer{}
+			cloudoneAnalysisContainer := backend.PodContainer{}
+			cloudoneGUIContainer := backend.PodContainer{}
 
 			for _, container := range pod.ContainerSlice {
 				if container.Name == "cloudone" {
@@ -463,7 +428,7 @@ func ProxyServer(ws *websocket.Conn) {
 			if upgradeCloudone == "true" && cloudoneContainer.ContainerID != "" {
 				containerID := cloudoneContainer.ContainerID[9:]
 				ws.Write([]byte("Stop and recreate cloudone\n"))
-				err := stopDockerContainer(usedCredential, containerID)
+				err := stopDockerContainer(usedCredential, containerID, 0)
 				if err != nil {
 					errorMessage := "Can't stop container " + cloudoneContainer.Name + " with error " + err.Error() + "\n"
 					ws.Write([]byte(errorMessage))
@@ -474,7 +439,7 @@ func ProxyServer(ws *websocket.Conn) {
 			if upgradeCloudoneAnalysis == "true" && cloudoneAnalysisContainer.ContainerID != "" {
 				containerID := cloudoneAnalysisContainer.ContainerID[9:]
 				ws.Write([]byte("Stop and recreate cloudone_analysis\n"))
-				err := stopDockerContainer(usedCredential, containerID)
+				err := stopDockerContainer(usedCredential, containerID, 0)
 				if err != nil {
 					errorMessage := "Can't stop container " + cloudoneAnalysisContainer.Name + " with error " + err.Error() + "\n"
 					ws.Write([]byte(errorMessage))
@@ -485,7 +450,8 @@ func ProxyServer(ws *websocket.Conn) {
 			if upgradeCloudoneGUI == "true" && cloudoneGUIContainer.ContainerID != "" {
 				containerID := cloudoneGUIContainer.ContainerID[9:]
 				ws.Write([]byte("Stop and recreate cloudone_gui. Please refresh the page after tens of seconds\n"))
-				err := stopDockerContainer(usedCredential, containerID)
+				// The GUI may stop itself so it is given the grace period to drain the websocket sessions including this one
+				err := stopDockerContainer(usedCredential, containerID, int(shutdown.GetGracePeriod().Seconds())+stopTimeoutMarginInSecond)
 				if err != nil {
 					errorMessage := "Can't stop container " + cloudoneGUIContainer.Name + " with error " + err.Error() + "\n"
 					ws.Write([]byte(errorMessage))
@@ -496,12 +462,9 @@ func ProxyServer(ws *websocket.Conn) {
 		}
 
 		if upgradeCloudone == "true" {
-			url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-				"/api/v1/healthchecks/"
-			cloudoneJsonMap := make(map[string]interface{}, 0)
 			for {
 				time.Sleep(time.Second)
-				_, err := restclient.RequestGetWithStructure(url, &cloudoneJsonMap, headerMap)
+				_, err := cloudoneClient.GetHealthCheck()
 
 				if identity.IsTokenInvalid(err) {
 					ws.Write([]byte(err.Error()))
@@ -518,12 +481,10 @@ func ProxyServer(ws *websocket.Conn) {
 		}
 
 		if upgradeCloudoneAnalysis == "true" {
-			url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-				"/api/v1/healthchecks/"
-			cloudoneAnalysisJsonMap := make(map[string]interface{}, 0)
+			cloudoneAnalysisClient := backend.NewCloudoneAnalysisClientWithTokenHeaderMap(tokenHeaderMap).WithCluster(cluster)
 			for {
 				time.Sleep(time.Second)
-				_, err := restclient.RequestGetWithStructure(url, &cloudoneAnalysisJsonMap, headerMap)
+				_, err := cloudoneAnalysisClient.GetHealthCheck()
 
 				if identity.IsTokenInvalid(err) {
 					ws.Write([]byte(err.Error()))
@@ -0,0 +1,312 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditchain
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_utility/audit"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// The chain is carried in the request header of the audit log since the structure is shared with the other components
+const (
+	HeaderChainID      = "Audit-Chain-Id"
+	HeaderSequence     = "Audit-Chain-Sequence"
+	HeaderPreviousHash = "Audit-Chain-Previous-Hash"
+	HeaderHash         = "Audit-Chain-Hash"
+)
+
+// Chain signs the audit logs of one GUI process. A new chain starts from the sequence 1 each time the GUI starts.
+type Chain struct {
+	key          []byte
+	id           string
+	startTime    time.Time
+	sequence     int64
+	previousHash string
+	headRecorder func(ChainHead)
+	lock         sync.Mutex
+}
+
+// ChainHead is the last sequence signed by the chain. It is kept outside cloudone_analysis so deleting
+// the newest audit logs or a whole chain there is detected.
+type ChainHead struct {
+	ChainID      string
+	StartTime    time.Time
+	LastSequence int64
+	UpdatedTime  time.Time
+}
+
+// NewChain creates the chain. The head recorder is called with the lock held after each audit log is signed so the heads are recorded in order.
+func NewChain(key []byte, id string, headRecorder func(ChainHead)) *Chain {
+	return &Chain{key: key, id: id, startTime: time.Now(), headRecorder: headRecorder}
+}
+
+// canonicalRecord contains the fields set by the GUI. The fields filled by cloudone_analysis are not signed.
+type canonicalRecord struct {
+	ChainID           string
+	Sequence          int64
+	PreviousHash      string
+	Component         string
+	Path              string
+	UserName          string
+	RemoteAddress     string
+	QueryParameterMap map[string][]string
+	RequestMethod     string
+	RequestURI        string
+	CreatedTime       int64
+}
+
+func computeHash(key []byte, record canonicalRecord) string {
+	// Map keys are sorted by json so the encoding is stable
+	byteSlice, _ := json.Marshal(record)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(byteSlice)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func getHeader(headerMap map[string][]string, key string) string {
+	valueSlice := headerMap[key]
+	if len(valueSlice) == 0 {
+		return ""
+	}
+	return valueSlice[0]
+}
+
+// Sign appends the audit log to the chain. The created time is truncated to millisecond since the storage may not keep more.
+func (chain *Chain) Sign(auditLog *audit.AuditLog) {
+	chain.lock.Lock()
+	defer chain.lock.Unlock()
+
+	auditLog.CreatedTime = auditLog.CreatedTime.Truncate(time.Millisecond)
+
+	chain.sequence++
+	hash := computeHash(chain.key, canonicalRecord{
+		chain.id,
+		chain.sequence,
+		chain.previousHash,
+		auditLog.Component,
+		auditLog.Path,
+		auditLog.UserName,
+		auditLog.RemoteAddress,
+		auditLog.QueryParameterMap,
+		auditLog.RequestMethod,
+		auditLog.RequestURI,
+		auditLog.CreatedTime.UnixNano() / int64(time.Millisecond),
+	})
+
+	if auditLog.RequestHeader == nil {
+		auditLog.RequestHeader = make(map[string][]string)
+	}
+	auditLog.RequestHeader[HeaderChainID] = []string{chain.id}
+	auditLog.RequestHeader[HeaderSequence] = []string{strconv.FormatInt(chain.sequence, 10)}
+	auditLog.RequestHeader[HeaderPreviousHash] = []string{chain.previousHash}
+	auditLog.RequestHeader[HeaderHash] = []string{hash}
+
+	chain.previousHash = hash
+
+	if chain.headRecorder != nil {
+		chain.headRecorder(ChainHead{chain.id, chain.startTime, chain.sequence, time.Now()})
+	}
+}
+
+type ProblemRecord struct {
+	Sequence    int64
+	UserName    string
+	Path        string
+	CreatedTime time.Time
+	Reason      string
+}
+
+type ChainReport struct {
+	ChainID       string
+	FirstSequence int64
+	LastSequence  int64
+	// The last sequence in the head. 0 if the head is not recorded.
+	SignedSequence       int64
+	Amount               int
+	MissingSequenceSlice []string
+	ProblemRecordSlice   []ProblemRecord
+}
+
+func (chainReport ChainReport) IsVerified() bool {
+	return chainReport.FirstSequence == 1 && len(chainReport.MissingSequenceSlice) == 0 && len(chainReport.ProblemRecordSlice) == 0
+}
+
+type Report struct {
+	ChainReportSlice []ChainReport
+	UnchainedAmount  int
+	// The audit logs not signed after the first recorded chain starts so the key is configured
+	UnchainedSinceSignedAmount int
+}
+
+func (report Report) IsVerified() bool {
+	if report.UnchainedSinceSignedAmount > 0 {
+		return false
+	}
+	for i := range report.ChainReportSlice {
+		if report.ChainReportSlice[i].IsVerified() == false {
+			return false
+		}
+	}
+	return true
+}
+
+type chainedAuditLog struct {
+	sequence     int64
+	previousHash string
+	hash         string
+	auditLog     *backend.AuditLog
+}
+
+func getMissingSequenceText(firstSequence int64, lastSequence int64) string {
+	if firstSequence == lastSequence {
+		return strconv.FormatInt(firstSequence, 10)
+	}
+	return strconv.FormatInt(firstSequence, 10) + "~" + strconv.FormatInt(lastSequence, 10)
+}
+
+// Verify walks the audit logs of each chain by the sequence and reports the missing sequences,
+// the records modified after signed and the records not linked to the previous one.
+// The heads report the newest records deleted and the chains deleted as a whole. The audit log without
+// the chain is reported if it is created after the earliest head starts since the key is configured then.
+func Verify(key []byte, auditLogSlice []backend.AuditLog, chainHeadSlice []ChainHead) Report {
+	report := Report{make([]ChainReport, 0), 0, 0}
+
+	chainHeadMap := make(map[string]ChainHead)
+	signedTime := time.Time{}
+	for _, chainHead := range chainHeadSlice {
+		chainHeadMap[chainHead.ChainID] = chainHead
+		if signedTime.IsZero() || chainHead.StartTime.Before(signedTime) {
+			signedTime = chainHead.StartTime
+		}
+	}
+
+	chainMap := make(map[string][]chainedAuditLog)
+	for i := range auditLogSlice {
+		auditLog := &auditLogSlice[i]
+		chainID := getHeader(auditLog.RequestHeader, HeaderChainID)
+		sequence, err := strconv.ParseInt(getHeader(auditLog.RequestHeader, HeaderSequence), 10, 64)
+		if chainID == "" || err != nil {
+			report.UnchainedAmount++
+			if signedTime.IsZero() == false && auditLog.CreatedTime.Before(signedTime) == false {
+				report.UnchainedSinceSignedAmount++
+			}
+			continue
+		}
+		chainMap[chainID] = append(chainMap[chainID], chainedAuditLog{
+			sequence,
+			getHeader(auditLog.RequestHeader, HeaderPreviousHash),
+			getHeader(auditLog.RequestHeader, HeaderHash),
+			auditLog,
+		})
+	}
+
+	for chainID, chainedAuditLogSlice := range chainMap {
+		sort.SliceStable(chainedAuditLogSlice, func(i, j int) bool {
+			return chainedAuditLogSlice[i].sequence < chainedAuditLogSlice[j].sequence
+		})
+
+		chainReport := ChainReport{
+			chainID,
+			chainedAuditLogSlice[0].sequence,
+			chainedAuditLogSlice[len(chainedAuditLogSlice)-1].sequence,
+			chainHeadMap[chainID].LastSequence,
+			len(chainedAuditLogSlice),
+			make([]string, 0),
+			make([]ProblemRecord, 0),
+		}
+		addProblem := func(chained chainedAuditLog, reason string) {
+			chainReport.ProblemRecordSlice = append(chainReport.ProblemRecordSlice, ProblemRecord{
+				chained.sequence,
+				chained.auditLog.UserName,
+				chained.auditLog.Path,
+				chained.auditLog.CreatedTime,
+				reason,
+			})
+		}
+
+		if chainReport.FirstSequence != 1 {
+			chainReport.MissingSequenceSlice = append(chainReport.MissingSequenceSlice, getMissingSequenceText(1, chainReport.FirstSequence-1))
+		}
+
+		for i, chained := range chainedAuditLogSlice {
+			expectedHash := computeHash(key, canonicalRecord{
+				chainID,
+				chained.sequence,
+				chained.previousHash,
+				chained.auditLog.Component,
+				chained.auditLog.Path,
+				chained.auditLog.UserName,
+				chained.auditLog.RemoteAddress,
+				chained.auditLog.QueryParameterMap,
+				chained.auditLog.RequestMethod,
+				chained.auditLog.RequestURI,
+				chained.auditLog.CreatedTime.UnixNano() / int64(time.Millisecond),
+			})
+			if hmac.Equal([]byte(expectedHash), []byte(chained.hash)) == false {
+				addProblem(chained, "Modified. The signature doesn't match the content.")
+			}
+
+			if i == 0 {
+				continue
+			}
+			previous := chainedAuditLogSlice[i-1]
+			if chained.sequence == previous.sequence {
+				addProblem(chained, "Duplicated sequence")
+				continue
+			}
+			if chained.sequence > previous.sequence+1 {
+				chainReport.MissingSequenceSlice = append(chainReport.MissingSequenceSlice, getMissingSequenceText(previous.sequence+1, chained.sequence-1))
+			} else if chained.previousHash != previous.hash {
+				addProblem(chained, "Not linked. The previous hash doesn't match the previous record.")
+			}
+		}
+
+		if chainReport.SignedSequence > chainReport.LastSequence {
+			chainReport.MissingSequenceSlice = append(chainReport.MissingSequenceSlice, getMissingSequenceText(chainReport.LastSequence+1, chainReport.SignedSequence))
+		}
+
+		report.ChainReportSlice = append(report.ChainReportSlice, chainReport)
+	}
+
+	// The chain without any audit log is deleted as a whole
+	for chainID, chainHead := range chainHeadMap {
+		if _, ok := chainMap[chainID]; ok || chainHead.LastSequence <= 0 {
+			continue
+		}
+		report.ChainReportSlice = append(report.ChainReportSlice, ChainReport{
+			chainID,
+			0,
+			0,
+			chainHead.LastSequence,
+			0,
+			[]string{getMissingSequenceText(1, chainHead.LastSequence)},
+			make([]ProblemRecord, 0),
+		})
+	}
+
+	sort.Slice(report.ChainReportSlice, func(i, j int) bool {
+		return report.ChainReportSlice[i].ChainID < report.ChainReportSlice[j].ChainID
+	})
+
+	return report
+}
@@ -0,0 +1,219 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditchain
+
+import (
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_utility/audit"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+var testKey = []byte("key")
+
+// signChain signs the amount of audit logs and returns them as stored in cloudone_analysis with the head recorded
+func signChain(chainID string, amount int, startTime time.Time) ([]backend.AuditLog, ChainHead) {
+	var chainHead ChainHead
+	chain := NewChain(testKey, chainID, func(head ChainHead) {
+		chainHead = head
+	})
+	chain.startTime = startTime
+
+	auditLogSlice := make([]backend.AuditLog, 0)
+	for i := 0; i < amount; i++ {
+		auditLog := &audit.AuditLog{
+			Component:         "gui",
+			Path:              "/gui/deploy/deploy/resize",
+			UserName:          "alice",
+			RemoteAddress:     "127.0.0.1",
+			QueryParameterMap: map[string][]string{"size": []string{strconv.Itoa(i)}},
+			RequestMethod:     "POST",
+			RequestURI:        "/gui/deploy/deploy/resize",
+			CreatedTime:       startTime.Add(time.Duration(i+1) * time.Second),
+		}
+		chain.Sign(auditLog)
+		auditLogSlice = append(auditLogSlice, backend.AuditLog{
+			Component:         auditLog.Component,
+			Path:              auditLog.Path,
+			UserName:          auditLog.UserName,
+			RemoteAddress:     auditLog.RemoteAddress,
+			QueryParameterMap: auditLog.QueryParameterMap,
+			RequestMethod:     auditLog.RequestMethod,
+			RequestURI:        auditLog.RequestURI,
+			RequestHeader:     auditLog.RequestHeader,
+			CreatedTime:       auditLog.CreatedTime,
+		})
+	}
+	return auditLogSlice, chainHead
+}
+
+func TestVerifyWithChainHead(t *testing.T) {
+	startTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	Convey("Subject: Verify the audit logs with the chain heads\n", t, func() {
+		auditLogSlice, chainHead := signChain("chain-a", 5, startTime)
+		So(chainHead.ChainID, ShouldEqual, "chain-a")
+		So(chainHead.LastSequence, ShouldEqual, 5)
+
+		unchainedBefore := backend.AuditLog{Component: "gui", CreatedTime: startTime.Add(-time.Second)}
+		unchainedAfter := backend.AuditLog{Component: "gui", CreatedTime: startTime.Add(time.Second)}
+
+		testCaseSlice := []struct {
+			description          string
+			auditLogSlice        []backend.AuditLog
+			chainHeadSlice       []ChainHead
+			verified             bool
+			missingSequenceSlice []string
+		}{
+			{"The complete chain is verified", auditLogSlice, []ChainHead{chainHead}, true, []string{}},
+			{"The newest records deleted are missing", auditLogSlice[:3], []ChainHead{chainHead}, false, []string{"4~5"}},
+			{"The newest record deleted is missing", auditLogSlice[:4], []ChainHead{chainHead}, false, []string{"5"}},
+			{"The newest records deleted are not detected without the head", auditLogSlice[:3], nil, true, []string{}},
+			{"The whole chain deleted is missing", []backend.AuditLog{}, []ChainHead{chainHead}, false, []string{"1~5"}},
+			{"The unchained record before the signing starts is allowed", append([]backend.AuditLog{unchainedBefore}, auditLogSlice...), []ChainHead{chainHead}, true, []string{}},
+			{"The unchained record after the signing starts fails", append([]backend.AuditLog{unchainedAfter}, auditLogSlice...), []ChainHead{chainHead}, false, []string{}},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				report := Verify(testKey, testCase.auditLogSlice, testCase.chainHeadSlice)
+				So(report.IsVerified(), ShouldEqual, testCase.verified)
+				So(len(report.ChainReportSlice), ShouldEqual, 1)
+				So(report.ChainReportSlice[0].MissingSequenceSlice, ShouldResemble, testCase.missingSequenceSlice)
+			})
+		}
+
+		Convey("The unchained records are counted", func() {
+			report := Verify(testKey, append([]backend.AuditLog{unchainedBefore, unchainedAfter}, auditLogSlice...), []ChainHead{chainHead})
+			So(report.UnchainedAmount, ShouldEqual, 2)
+			So(report.UnchainedSinceSignedAmount, ShouldEqual, 1)
+		})
+	})
+}
+
+// copyAuditLogSlice copies the header map too so the test case doesn't change the records shared by the others
+func copyAuditLogSlice(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+	copiedSlice := make([]backend.AuditLog, 0)
+	for _, auditLog := range auditLogSlice {
+		headerMap := make(map[string][]string)
+		for key, value := range auditLog.RequestHeader {
+			headerMap[key] = value
+		}
+		auditLog.RequestHeader = headerMap
+		copiedSlice = append(copiedSlice, auditLog)
+	}
+	return copiedSlice
+}
+
+func TestVerifyTampering(t *testing.T) {
+	startTime := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	Convey("Subject: Verify detects the audit logs deleted, modified and inserted\n", t, func() {
+		auditLogSlice, chainHead := signChain("chain-a", 5, startTime)
+		// The chain signed again with the same id and key has the valid signatures but the different links
+		otherAuditLogSlice, _ := signChain("chain-a", 5, startTime.Add(time.Hour))
+
+		testCaseSlice := []struct {
+			description          string
+			tamper               func(auditLogSlice []backend.AuditLog) []backend.AuditLog
+			missingSequenceSlice []string
+			reasonSlice          []string
+		}{
+			{
+				"The record deleted in the middle is a gap",
+				func(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+					return append(auditLogSlice[:2], auditLogSlice[3:]...)
+				},
+				[]string{"3"},
+				[]string{},
+			},
+			{
+				"The records deleted at the beginning are a gap",
+				func(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+					return auditLogSlice[2:]
+				},
+				[]string{"1~2"},
+				[]string{},
+			},
+			{
+				"The user name changed is modified",
+				func(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+					auditLogSlice[2].UserName = "mallory"
+					return auditLogSlice
+				},
+				[]string{},
+				[]string{"Modified. The signature doesn't match the content."},
+			},
+			{
+				"The query parameter changed is modified",
+				func(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+					auditLogSlice[1].QueryParameterMap = map[string][]string{"size": []string{"100"}}
+					return auditLogSlice
+				},
+				[]string{},
+				[]string{"Modified. The signature doesn't match the content."},
+			},
+			{
+				"The sequence changed is modified and leaves a gap",
+				func(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+					auditLogSlice[4].RequestHeader[HeaderSequence] = []string{"7"}
+					return auditLogSlice
+				},
+				[]string{"5~6"},
+				[]string{"Modified. The signature doesn't match the content."},
+			},
+			{
+				"The record inserted with an existing sequence is duplicated",
+				func(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+					inserted := copyAuditLogSlice(auditLogSlice[1:2])[0]
+					inserted.Path = "/gui/system/rbac/user/delete"
+					return append(auditLogSlice[:2], append([]backend.AuditLog{inserted}, auditLogSlice[2:]...)...)
+				},
+				[]string{},
+				[]string{"Modified. The signature doesn't match the content.", "Duplicated sequence"},
+			},
+			{
+				"The record signed in the other chain is not linked",
+				func(auditLogSlice []backend.AuditLog) []backend.AuditLog {
+					auditLogSlice[2] = otherAuditLogSlice[2]
+					return auditLogSlice
+				},
+				[]string{},
+				[]string{"Not linked. The previous hash doesn't match the previous record.", "Not linked. The previous hash doesn't match the previous record."},
+			},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				report := Verify(testKey, testCase.tamper(copyAuditLogSlice(auditLogSlice)), []ChainHead{chainHead})
+				So(report.IsVerified(), ShouldBeFalse)
+				So(len(report.ChainReportSlice), ShouldEqual, 1)
+				So(report.ChainReportSlice[0].MissingSequenceSlice, ShouldResemble, testCase.missingSequenceSlice)
+				reasonSlice := make([]string, 0)
+				for _, problemRecord := range report.ChainReportSlice[0].ProblemRecordSlice {
+					reasonSlice = append(reasonSlice, problemRecord.Reason)
+				}
+				So(reasonSlice, ShouldResemble, testCase.reasonSlice)
+			})
+		}
+
+		Convey("The record signed with the other key is modified", func() {
+			report := Verify([]byte("other"), auditLogSlice, []ChainHead{chainHead})
+			So(report.IsVerified(), ShouldBeFalse)
+			So(len(report.ChainReportSlice[0].ProblemRecordSlice), ShouldEqual, 5)
+		})
+	})
+}
@@ -0,0 +1,355 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsearch
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// GUIWidgetTimePickerFormat is the format of the time picker in the GUI
+	GUIWidgetTimePickerFormat = "01/02/2006 15:04 PM"
+
+	SortByCreatedTime   = "createdTime"
+	SortByUserName      = "userName"
+	SortByComponent     = "component"
+	SortByPath          = "path"
+	SortByMethod        = "method"
+	SortByRemoteAddress = "remoteAddress"
+
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+
+	scanPageSize               = 500
+	defaultScanMaximum         = 10000
+	defaultSortBy              = SortByCreatedTime
+	parameterNameUserName      = "userName"
+	parameterNameComponent     = "component"
+	parameterNamePathPrefix    = "pathPrefix"
+	parameterNameMethod        = "method"
+	parameterNameRemoteAddress = "remoteAddress"
+	parameterNameRequestID     = "requestID"
+	parameterNameFrom          = "from"
+	parameterNameTo            = "to"
+	parameterNameSortBy        = "sortBy"
+	parameterNameOrder         = "order"
+	orderAscending             = "asc"
+	orderDescending            = "desc"
+)
+
+// Criteria filters the audit logs. The empty field matches all.
+type Criteria struct {
+	UserName      string
+	Component     string
+	PathPrefix    string
+	Method        string
+	RemoteAddress string
+	RequestID     string
+	From          *time.Time
+	To            *time.Time
+	SortBy        string
+	Descending    bool
+}
+
+func parseTime(text string) (*time.Time, error) {
+	if text == "" {
+		return nil, nil
+	}
+	parsedTime, err := time.Parse(time.RFC3339, text)
+	if err == nil {
+		return &parsedTime, nil
+	}
+	parsedTime, err = time.ParseInLocation(GUIWidgetTimePickerFormat, text, time.Local)
+	if err == nil {
+		return &parsedTime, nil
+	}
+	return nil, errors.New("Time " + text + " is neither RFC3339 nor " + GUIWidgetTimePickerFormat)
+}
+
+// ParseCriteria reads the criteria from the query parameters with the getter such as beego.Controller.GetString
+func ParseCriteria(getString func(key string, def ...string) string) (*Criteria, error) {
+	from, err := parseTime(getString(parameterNameFrom))
+	if err != nil {
+		return nil, err
+	}
+	to, err := parseTime(getString(parameterNameTo))
+	if err != nil {
+		return nil, err
+	}
+
+	sortBy := getString(parameterNameSortBy)
+	switch sortBy {
+	case "":
+		sortBy = defaultSortBy
+	case SortByCreatedTime, SortByUserName, SortByComponent, SortByPath, SortByMethod, SortByRemoteAddress:
+	default:
+		return nil, errors.New("Sort by " + sortBy + " is not supported")
+	}
+
+	order := getString(parameterNameOrder)
+	descending := true
+	switch order {
+	case "", orderDescending:
+	case orderAscending:
+		descending = false
+	default:
+		return nil, errors.New("Order " + order + " is not supported")
+	}
+
+	userName := getString(parameterNameUserName)
+	if userName == "All" {
+		userName = ""
+	}
+
+	return &Criteria{
+		userName,
+		getString(parameterNameComponent),
+		getString(parameterNamePathPrefix),
+		strings.ToUpper(getString(parameterNameMethod)),
+		getString(parameterNameRemoteAddress),
+		getString(parameterNameRequestID),
+		from,
+		to,
+		sortBy,
+		descending,
+	}, nil
+}
+
+// Query returns the query parameters to keep the criteria in the links such as pagination and export
+func (criteria *Criteria) Query() url.Values {
+	values := url.Values{}
+	setIfNotEmpty := func(key string, value string) {
+		if value != "" {
+			values.Set(key, value)
+		}
+	}
+	setIfNotEmpty(parameterNameUserName, criteria.UserName)
+	setIfNotEmpty(parameterNameComponent, criteria.Component)
+	setIfNotEmpty(parameterNamePathPrefix, criteria.PathPrefix)
+	setIfNotEmpty(parameterNameMethod, criteria.Method)
+	setIfNotEmpty(parameterNameRemoteAddress, criteria.RemoteAddress)
+	setIfNotEmpty(parameterNameRequestID, criteria.RequestID)
+	if criteria.From != nil {
+		values.Set(parameterNameFrom, criteria.From.Format(time.RFC3339))
+	}
+	if criteria.To != nil {
+		values.Set(parameterNameTo, criteria.To.Format(time.RFC3339))
+	}
+	values.Set(parameterNameSortBy, criteria.SortBy)
+	if criteria.Descending {
+		values.Set(parameterNameOrder, orderDescending)
+	} else {
+		values.Set(parameterNameOrder, orderAscending)
+	}
+	return values
+}
+
+// IsPagedByBackend is true when cloudone_analysis could return the page directly without scanning
+func (criteria *Criteria) IsPagedByBackend() bool {
+	return criteria.Component == "" && criteria.PathPrefix == "" && criteria.Method == "" && criteria.RemoteAddress == "" && criteria.RequestID == "" &&
+		criteria.From == nil && criteria.To == nil && criteria.SortBy == SortByCreatedTime && criteria.Descending
+}
+
+func (criteria *Criteria) Match(auditLog *backend.AuditLog) bool {
+	if criteria.UserName != "" && auditLog.UserName != criteria.UserName {
+		return false
+	}
+	if criteria.Component != "" && auditLog.Component != criteria.Component {
+		return false
+	}
+	if criteria.PathPrefix != "" && strings.HasPrefix(auditLog.Path, criteria.PathPrefix) == false {
+		return false
+	}
+	if criteria.Method != "" && auditLog.RequestMethod != criteria.Method {
+		return false
+	}
+	// The remote address may contain the proxies or the port so it is matched partially
+	if criteria.RemoteAddress != "" && strings.Contains(auditLog.RemoteAddress, criteria.RemoteAddress) == false {
+		return false
+	}
+	if criteria.RequestID != "" && auditLog.GetRequestID() != criteria.RequestID {
+		return false
+	}
+	if criteria.From != nil && auditLog.CreatedTime.Before(*criteria.From) {
+		return false
+	}
+	if criteria.To != nil && auditLog.CreatedTime.After(*criteria.To) {
+		return false
+	}
+	return true
+}
+
+func (criteria *Criteria) less(a *backend.AuditLog, b *backend.AuditLog) bool {
+	var aText, bText string
+	switch criteria.SortBy {
+	case SortByUserName:
+		aText, bText = a.UserName, b.UserName
+	case SortByComponent:
+		aText, bText = a.Component, b.Component
+	case SortByPath:
+		aText, bText = a.Path, b.Path
+	case SortByMethod:
+		aText, bText = a.RequestMethod, b.RequestMethod
+	case SortByRemoteAddress:
+		aText, bText = a.RemoteAddress, b.RemoteAddress
+	default:
+		return a.CreatedTime.Before(b.CreatedTime)
+	}
+	if aText == bText {
+		return a.CreatedTime.Before(b.CreatedTime)
+	}
+	return aText < bText
+}
+
+// GetScanMaximum returns the amount of the audit logs scanned at most by Search
+func GetScanMaximum() int {
+	return beego.AppConfig.DefaultInt("auditLogSearchScanMaximum", defaultScanMaximum)
+}
+
+// Search scans the audit logs since cloudone_analysis only filters by the user name. cloudone_analysis returns
+// the newest first so the scan stops at the audit log older than the criteria From. Otherwise the scan stops at
+// auditLogSearchScanMaximum entries and truncated is true if there are more.
+func Search(cloudoneAnalysisClient *backend.Client, criteria *Criteria) (auditLogSlice []backend.AuditLog, truncated bool, returnedError error) {
+	scanMaximum := GetScanMaximum()
+
+	auditLogSlice = make([]backend.AuditLog, 0)
+	for offset := 0; ; offset += scanPageSize {
+		if offset >= scanMaximum {
+			truncated = true
+			break
+		}
+
+		pageSlice, err := cloudoneAnalysisClient.GetAuditLogSlice(criteria.UserName, scanPageSize, offset)
+		if err != nil {
+			return nil, false, err
+		}
+
+		for i := range pageSlice {
+			if criteria.Match(&pageSlice[i]) {
+				auditLogSlice = append(auditLogSlice, pageSlice[i])
+			}
+		}
+
+		if len(pageSlice) < scanPageSize {
+			break
+		}
+		if criteria.From != nil && pageSlice[len(pageSlice)-1].CreatedTime.Before(*criteria.From) {
+			break
+		}
+	}
+
+	sort.SliceStable(auditLogSlice, func(i, j int) bool {
+		if criteria.Descending {
+			return criteria.less(&auditLogSlice[j], &auditLogSlice[i])
+		}
+		return criteria.less(&auditLogSlice[i], &auditLogSlice[j])
+	})
+
+	return auditLogSlice, truncated, nil
+}
+
+// Page returns the part of the slice without panic when the offset is out of range
+func Page(auditLogSlice []backend.AuditLog, offset int, size int) []backend.AuditLog {
+	if offset < 0 {
+		offset = 0
+	}
+	if offset >= len(auditLogSlice) {
+		return make([]backend.AuditLog, 0)
+	}
+	end := offset + size
+	if end > len(auditLogSlice) || size <= 0 {
+		end = len(auditLogSlice)
+	}
+	return auditLogSlice[offset:end]
+}
+
+func GetContentType(format string) (string, error) {
+	switch format {
+	case FormatCSV:
+		return "text/csv; charset=utf-8", nil
+	case FormatNDJSON:
+		return "application/x-ndjson", nil
+	default:
+		return "", errors.New("Export format " + format + " is not supported")
+	}
+}
+
+func Export(writer io.Writer, format string, auditLogSlice []backend.AuditLog) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(writer, auditLogSlice)
+	case FormatNDJSON:
+		return writeNDJSON(writer, auditLogSlice)
+	default:
+		return errors.New("Export format " + format + " is not supported")
+	}
+}
+
+func writeCSV(writer io.Writer, auditLogSlice []backend.AuditLog) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write([]string{"CreatedTime", "Component", "Kind", "UserName", "RemoteAddress", "RequestMethod", "Path", "RequestURI", "QueryParameters", "PathParameters", "Description", "RequestID"})
+	if err != nil {
+		return err
+	}
+	for _, auditLog := range auditLogSlice {
+		recordSlice := []string{
+			auditLog.CreatedTime.Format(time.RFC3339),
+			auditLog.Component,
+			auditLog.Kind,
+			auditLog.UserName,
+			auditLog.RemoteAddress,
+			auditLog.RequestMethod,
+			auditLog.Path,
+			auditLog.RequestURI,
+			fmt.Sprint(auditLog.QueryParameterMap),
+			fmt.Sprint(auditLog.PathParameterMap),
+			auditLog.Description,
+			auditLog.GetRequestID(),
+		}
+		// The request is from the users so the field looking like a formula is escaped for the spreadsheet
+		for i, record := range recordSlice {
+			if record != "" && strings.ContainsRune("=+-@", rune(record[0])) {
+				recordSlice[i] = "'" + record
+			}
+		}
+		err := csvWriter.Write(recordSlice)
+		if err != nil {
+			return err
+		}
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+func writeNDJSON(writer io.Writer, auditLogSlice []backend.AuditLog) error {
+	encoder := json.NewEncoder(writer)
+	for i := range auditLogSlice {
+		err := encoder.Encode(&auditLogSlice[i])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
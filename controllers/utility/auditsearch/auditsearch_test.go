@@ -0,0 +1,86 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auditsearch
+
+import (
+	"encoding/json"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSearch(t *testing.T) {
+	Convey("Subject: The scan stops at the time from or the maximum amount\n", t, func() {
+		// cloudone_analysis returns the newest first and each audit log is one minute older than the previous one
+		latestTime := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+		auditLogSlice := make([]backend.AuditLog, 2000)
+		for i := range auditLogSlice {
+			auditLogSlice[i] = backend.AuditLog{UserName: "admin", RequestMethod: "GET", CreatedTime: latestTime.Add(-time.Duration(i) * time.Minute)}
+		}
+		requestAmount := 0
+		server := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+			requestAmount++
+			size, _ := strconv.Atoi(request.URL.Query().Get("size"))
+			offset, _ := strconv.Atoi(request.URL.Query().Get("offset"))
+			pageSlice := make([]backend.AuditLog, 0)
+			for i := offset; i < offset+size && i < len(auditLogSlice); i++ {
+				pageSlice = append(pageSlice, auditLogSlice[i])
+			}
+			json.NewEncoder(responseWriter).Encode(pageSlice)
+		}))
+		defer server.Close()
+		host, port, _ := net.SplitHostPort(server.Listener.Addr().String())
+		for key, value := range map[string]string{
+			"cloudoneAnalysisProtocol":  "http",
+			"cloudoneAnalysisHost":      host,
+			"cloudoneAnalysisPort":      port,
+			"auditLogSearchScanMaximum": "1000",
+		} {
+			beego.AppConfig.Set(key, value)
+		}
+		defer beego.AppConfig.Set("auditLogSearchScanMaximum", strconv.Itoa(defaultScanMaximum))
+		client := backend.NewCloudoneAnalysisClientWithTokenHeaderMap(nil)
+
+		from := latestTime.Add(-600 * time.Minute)
+		testCaseSlice := []struct {
+			description   string
+			criteria      *Criteria
+			amount        int
+			truncated     bool
+			requestAmount int
+		}{
+			{"The scan stops at the page older than the time from", &Criteria{Method: "GET", From: &from, SortBy: SortByCreatedTime, Descending: true}, 601, false, 2},
+			{"The scan stops at the maximum amount", &Criteria{Method: "GET", SortBy: SortByCreatedTime, Descending: true}, 1000, true, 2},
+			{"The scan without any match is truncated at the maximum amount", &Criteria{Method: "POST", SortBy: SortByCreatedTime, Descending: true}, 0, true, 2},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				requestAmount = 0
+				resultSlice, truncated, err := Search(client, testCase.criteria)
+				So(err, ShouldBeNil)
+				So(resultSlice, ShouldHaveLength, testCase.amount)
+				So(truncated, ShouldEqual, testCase.truncated)
+				So(requestAmount, ShouldEqual, testCase.requestAmount)
+			})
+		}
+	})
+}
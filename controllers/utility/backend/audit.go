@@ -0,0 +1,62 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"github.com/cloudawan/cloudone_utility/audit"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type AuditLog struct {
+	Component         string
+	Kind              string
+	Path              string
+	UserName          string
+	RemoteAddress     string
+	RemoteHost        string
+	CreatedTime       time.Time
+	QueryParameterMap map[string][]string
+	PathParameterMap  map[string]string
+	RequestMethod     string
+	RequestURI        string
+	RequestBody       string
+	RequestHeader     map[string][]string
+	Description       string
+}
+
+// GetRequestID returns the request id recorded in the header. The name of the header is matched case-insensitively
+// since the backend may record the canonical form X-Request-Id.
+func (auditLog AuditLog) GetRequestID() string {
+	for key, valueSlice := range auditLog.RequestHeader {
+		if strings.EqualFold(key, tracing.HeaderRequestID) && len(valueSlice) > 0 {
+			return valueSlice[0]
+		}
+	}
+	return ""
+}
+
+// GetAuditLogSlice returns the audit logs of the user or of all users if the user name is empty
+func (client *Client) GetAuditLogSlice(userName string, size int, offset int) ([]AuditLog, error) {
+	auditLogSlice := make([]AuditLog, 0)
+	err := client.getWithStructure("/api/v1/auditlogs/"+userName+"?size="+strconv.Itoa(size)+"&offset="+strconv.Itoa(offset), &auditLogSlice)
+	return auditLogSlice, err
+}
+
+func (client *Client) CreateAuditLog(auditLog *audit.AuditLog) error {
+	return client.post("/api/v1/auditlogs", auditLog, false)
+}
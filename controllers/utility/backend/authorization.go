@@ -0,0 +1,104 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/cloudawan/cloudone_utility/rbac"
+)
+
+type UserData struct {
+	Username string
+	Password string
+}
+
+type TokenData struct {
+	Token string
+}
+
+func (client *Client) CreateToken(userData UserData) (string, error) {
+	tokenData := TokenData{}
+	err := client.postWithStructure("/api/v1/authorizations/tokens/", userData, &tokenData)
+	if err != nil {
+		return "", err
+	}
+	return tokenData.Token, nil
+}
+
+func (client *Client) GetUserFromToken(token string, componentName string) (*rbac.User, error) {
+	user := &rbac.User{}
+	err := client.getWithStructure("/api/v1/authorizations/tokens/"+token+"/components/"+componentName, user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (client *Client) GetUserSlice() ([]rbac.User, error) {
+	userSlice := make([]rbac.User, 0)
+	err := client.getWithStructureCached("/api/v1/authorizations/users", &userSlice)
+	return userSlice, err
+}
+
+func (client *Client) GetUser(name string) (*rbac.User, error) {
+	user := &rbac.User{}
+	err := client.getWithStructure("/api/v1/authorizations/users/"+name, user)
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+func (client *Client) CreateUser(user rbac.User) error {
+	return client.postWithStructure("/api/v1/authorizations/users", user, nil)
+}
+
+func (client *Client) UpdateUser(user rbac.User) error {
+	return client.putWithStructure("/api/v1/authorizations/users/"+user.Name, user, nil)
+}
+
+func (client *Client) UpdateUserMetaData(name string, metaDataMap map[string]string) error {
+	return client.putWithStructure("/api/v1/authorizations/users/"+name+"/metadata", metaDataMap, nil)
+}
+
+func (client *Client) DeleteUser(name string) error {
+	return client.delete("/api/v1/authorizations/users/" + name)
+}
+
+func (client *Client) GetRoleSlice() ([]rbac.Role, error) {
+	roleSlice := make([]rbac.Role, 0)
+	err := client.getWithStructure("/api/v1/authorizations/roles", &roleSlice)
+	return roleSlice, err
+}
+
+func (client *Client) GetRole(name string) (*rbac.Role, error) {
+	role := &rbac.Role{}
+	err := client.getWithStructure("/api/v1/authorizations/roles/"+name, role)
+	if err != nil {
+		return nil, err
+	}
+	return role, nil
+}
+
+func (client *Client) CreateRole(role rbac.Role) error {
+	return client.postWithStructure("/api/v1/authorizations/roles", role, nil)
+}
+
+func (client *Client) UpdateRole(role rbac.Role) error {
+	return client.putWithStructure("/api/v1/authorizations/roles/"+role.Name, role, nil)
+}
+
+func (client *Client) DeleteRole(name string) error {
+	return client.delete("/api/v1/authorizations/roles/" + name)
+}
@@ -0,0 +1,68 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"time"
+)
+
+type ReplicationControllerAutoScaler struct {
+	Check                 bool
+	CoolDownDuration      time.Duration
+	RemainingCoolDown     time.Duration
+	KubeApiServerEndPoint string
+	KubeApiServerToken    string
+	Namespace             string
+	Kind                  string
+	Name                  string
+	MaximumReplica        int
+	MinimumReplica        int
+	IndicatorSlice        []Indicator
+}
+
+// Indicator is the threshold setting shared by the auto scaler and the notifier
+type Indicator struct {
+	Type                  string
+	AboveAllOrOne         bool
+	AbovePercentageOfData float64
+	AboveThreshold        int64
+	BelowAllOrOne         bool
+	BelowPercentageOfData float64
+	BelowThreshold        int64
+}
+
+func (client *Client) GetAutoScalerSlice() ([]ReplicationControllerAutoScaler, error) {
+	replicationControllerAutoScalerSlice := make([]ReplicationControllerAutoScaler, 0)
+	err := client.getWithStructure("/api/v1/autoscalers/", &replicationControllerAutoScalerSlice)
+	return replicationControllerAutoScalerSlice, err
+}
+
+func (client *Client) GetAutoScaler(namespace string, kind string, name string) (*ReplicationControllerAutoScaler, error) {
+	replicationControllerAutoScaler := &ReplicationControllerAutoScaler{}
+	err := client.getWithStructure("/api/v1/autoscalers/"+namespace+"/"+kind+"/"+name, replicationControllerAutoScaler)
+	if err != nil {
+		return nil, err
+	}
+	return replicationControllerAutoScaler, nil
+}
+
+// UpdateAutoScaler creates the auto scaler if it doesn't exist
+func (client *Client) UpdateAutoScaler(replicationControllerAutoScaler ReplicationControllerAutoScaler) error {
+	return client.putWithStructure("/api/v1/autoscalers/", replicationControllerAutoScaler, nil)
+}
+
+func (client *Client) DeleteAutoScaler(namespace string, kind string, name string) error {
+	return client.delete("/api/v1/autoscalers/" + namespace + "/" + kind + "/" + name)
+}
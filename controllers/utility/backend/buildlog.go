@@ -0,0 +1,28 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/cloudawan/cloudone_utility/build"
+)
+
+func (client *Client) GetBuildLog(imageInformationName string, version string) (*build.BuildLog, error) {
+	buildLog := &build.BuildLog{}
+	err := client.getWithStructure("/api/v1/buildlogs/"+imageInformationName+"/"+version, buildLog)
+	if err != nil {
+		return nil, err
+	}
+	return buildLog, nil
+}
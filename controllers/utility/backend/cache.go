@@ -0,0 +1,278 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/json"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCacheTTLInSecond  = 30
+	defaultCacheEntryMaximum = 10000
+	cacheResourcePrefix      = "/api/v1/"
+)
+
+// The read-heavy lists rendered on many pages are cached for each user so the permission of the user is respected.
+// A mutating request through the GUI invalidates the cached responses of the same resource for all users.
+// The changes made somewhere else such as another replica are seen after the cache expires.
+type cachedResponse struct {
+	userName    string
+	component   string
+	cluster     string
+	path        string
+	byteSlice   []byte
+	expiredTime time.Time
+}
+
+type cacheCounter struct {
+	hit  int64
+	miss int64
+}
+
+type CacheStatistic struct {
+	Name  string
+	Entry int
+	Hit   int64
+	Miss  int64
+}
+
+// HitRate is the percentage of the requests served by the cache
+func (cacheStatistic CacheStatistic) HitRate() string {
+	total := cacheStatistic.Hit + cacheStatistic.Miss
+	if total == 0 {
+		return "-"
+	}
+	return strconv.FormatFloat(float64(cacheStatistic.Hit)*100/float64(total), 'f', 1, 64) + "%"
+}
+
+type CacheSummary struct {
+	Enabled            bool
+	TTL                time.Duration
+	EntryMaximum       int
+	Invalidated        int64
+	Total              CacheStatistic
+	UserStatisticSlice []CacheStatistic
+	PathStatisticSlice []CacheStatistic
+}
+
+var cachedResponseMap = make(map[string]*cachedResponse)
+
+var cacheUserCounterMap = make(map[string]*cacheCounter)
+
+var cachePathCounterMap = make(map[string]*cacheCounter)
+
+var cacheInvalidated int64
+
+// cacheGeneration is increased by every invalidation so the response requested before it is not cached after it
+var cacheGeneration int64
+
+var cacheLock = sync.Mutex{}
+
+func getCacheTTL() time.Duration {
+	return time.Duration(beego.AppConfig.DefaultInt("backendCacheTTLInSecond", defaultCacheTTLInSecond)) * time.Second
+}
+
+func getCacheEntryMaximum() int {
+	return beego.AppConfig.DefaultInt("backendCacheEntryMaximum", defaultCacheEntryMaximum)
+}
+
+func getCacheKey(userName string, component string, cluster string, path string) string {
+	return userName + "\n" + component + "\n" + cluster + "\n" + path
+}
+
+// getCacheResource returns the resource such as /api/v1/namespaces of the path
+func getCacheResource(path string) string {
+	if strings.HasPrefix(path, cacheResourcePrefix) == false {
+		return path
+	}
+	resource := strings.TrimPrefix(path, cacheResourcePrefix)
+	if index := strings.IndexAny(resource, "/?"); index >= 0 {
+		resource = resource[:index]
+	}
+	return cacheResourcePrefix + resource
+}
+
+func countCache(userName string, path string, hit bool) {
+	for _, item := range []struct {
+		counterMap map[string]*cacheCounter
+		key        string
+	}{
+		{cacheUserCounterMap, userName},
+		{cachePathCounterMap, path},
+	} {
+		counter, ok := item.counterMap[item.key]
+		if ok == false {
+			counter = &cacheCounter{}
+			item.counterMap[item.key] = counter
+		}
+		if hit {
+			counter.hit++
+		} else {
+			counter.miss++
+		}
+	}
+}
+
+// loadCachedResponse decodes the cached response into the returned structure. The generation is used to store the response requested on miss.
+func loadCachedResponse(userName string, component string, cluster string, path string, returnedStructure interface{}) (bool, int64) {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	cached, ok := cachedResponseMap[getCacheKey(userName, component, cluster, path)]
+	if ok && time.Now().After(cached.expiredTime) {
+		delete(cachedResponseMap, getCacheKey(userName, component, cluster, path))
+		ok = false
+	}
+	// Decoding a copy every time prevents the caller from modifying the cached one
+	if ok && json.Unmarshal(cached.byteSlice, returnedStructure) != nil {
+		ok = false
+	}
+
+	countCache(userName, path, ok)
+	metrics.ObserveBackendCache(component, ok)
+	return ok, cacheGeneration
+}
+
+func storeCachedResponse(userName string, component string, cluster string, path string, returnedStructure interface{}, generation int64) {
+	byteSlice, err := json.Marshal(returnedStructure)
+	if err != nil {
+		return
+	}
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	if generation != cacheGeneration {
+		return
+	}
+
+	now := time.Now()
+	if len(cachedResponseMap) >= getCacheEntryMaximum() {
+		for key, cached := range cachedResponseMap {
+			if now.After(cached.expiredTime) {
+				delete(cachedResponseMap, key)
+			}
+		}
+		if len(cachedResponseMap) >= getCacheEntryMaximum() {
+			return
+		}
+	}
+
+	cachedResponseMap[getCacheKey(userName, component, cluster, path)] = &cachedResponse{
+		userName,
+		component,
+		cluster,
+		path,
+		byteSlice,
+		now.Add(getCacheTTL()),
+	}
+}
+
+// invalidateCachedResponse removes the cached responses of the resource of the path for all users
+func invalidateCachedResponse(component string, cluster string, path string) {
+	resource := getCacheResource(path)
+
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	cacheGeneration++
+	for key, cached := range cachedResponseMap {
+		if cached.component == component && cached.cluster == cluster && getCacheResource(cached.path) == resource {
+			delete(cachedResponseMap, key)
+			cacheInvalidated++
+		}
+	}
+}
+
+// FlushCache removes all the cached responses. The statistic is kept.
+func FlushCache() {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	cacheGeneration++
+	cacheInvalidated += int64(len(cachedResponseMap))
+	cachedResponseMap = make(map[string]*cachedResponse)
+}
+
+func getCacheStatisticSlice(counterMap map[string]*cacheCounter, entryMap map[string]int) []CacheStatistic {
+	cacheStatisticSlice := make([]CacheStatistic, 0)
+	for name, counter := range counterMap {
+		cacheStatisticSlice = append(cacheStatisticSlice, CacheStatistic{name, entryMap[name], counter.hit, counter.miss})
+	}
+	sort.Slice(cacheStatisticSlice, func(i, j int) bool {
+		return cacheStatisticSlice[i].Name < cacheStatisticSlice[j].Name
+	})
+	return cacheStatisticSlice
+}
+
+// GetCacheSummary is used to monitor how well the cache works
+func GetCacheSummary() CacheSummary {
+	cacheLock.Lock()
+	defer cacheLock.Unlock()
+
+	now := time.Now()
+	userEntryMap := make(map[string]int)
+	pathEntryMap := make(map[string]int)
+	entry := 0
+	for _, cached := range cachedResponseMap {
+		if now.After(cached.expiredTime) {
+			continue
+		}
+		userEntryMap[cached.userName]++
+		pathEntryMap[cached.path]++
+		entry++
+	}
+
+	total := CacheStatistic{"Total", entry, 0, 0}
+	for _, counter := range cacheUserCounterMap {
+		total.Hit += counter.hit
+		total.Miss += counter.miss
+	}
+
+	return CacheSummary{
+		getCacheTTL() > 0,
+		getCacheTTL(),
+		getCacheEntryMaximum(),
+		cacheInvalidated,
+		total,
+		getCacheStatisticSlice(cacheUserCounterMap, userEntryMap),
+		getCacheStatisticSlice(cachePathCounterMap, pathEntryMap),
+	}
+}
+
+// getWithStructureCached serves the GET from the cache of the user. It is not cached without the user such as websocket and webhook.
+func (client *Client) getWithStructureCached(path string, returnedStructure interface{}) error {
+	if client.userName == "" || getCacheTTL() <= 0 {
+		return client.getWithStructure(path, returnedStructure)
+	}
+
+	hit, generation := loadCachedResponse(client.userName, client.component, client.cluster, path, returnedStructure)
+	if hit {
+		return nil
+	}
+
+	if err := client.getWithStructure(path, returnedStructure); err != nil {
+		return err
+	}
+	storeCachedResponse(client.userName, client.component, client.cluster, path, returnedStructure, generation)
+	return nil
+}
@@ -0,0 +1,157 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+type cacheTestItem struct {
+	Name string
+}
+
+// newCacheTestServer returns the GET amount in the item name so the test tells the cached response from the fetched one
+func newCacheTestServer() *httptest.Server {
+	lock := sync.Mutex{}
+	getAmount := 0
+	return httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		if request.Method != "GET" && strings.HasSuffix(request.URL.Path, "/failure") {
+			http.Error(responseWriter, `{"Error":"Failure"}`, http.StatusInternalServerError)
+			return
+		}
+		if request.Method != "GET" {
+			json.NewEncoder(responseWriter).Encode(map[string]interface{}{})
+			return
+		}
+		lock.Lock()
+		getAmount++
+		name := "item" + strconv.Itoa(getAmount)
+		lock.Unlock()
+		json.NewEncoder(responseWriter).Encode([]cacheTestItem{cacheTestItem{name}})
+	}))
+}
+
+func newCacheTestClient(server *httptest.Server, component string, cluster string, userName string) *Client {
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	return &Client{component, cluster, "http", host, port, nil, context.Background(), 5 * time.Second, userName}
+}
+
+func getCacheTestItemName(client *Client, path string) string {
+	itemSlice := make([]cacheTestItem, 0)
+	So(client.getWithStructureCached(path, &itemSlice), ShouldBeNil)
+	So(len(itemSlice), ShouldEqual, 1)
+	return itemSlice[0].Name
+}
+
+func TestCacheInvalidation(t *testing.T) {
+	server := newCacheTestServer()
+	defer server.Close()
+
+	client := newCacheTestClient(server, componentCloudone, "default", "alice")
+
+	Convey("Subject: The mutating request invalidates the cached responses\n", t, func() {
+		testCaseSlice := []struct {
+			description string
+			mutate      func() error
+			failed      bool
+			invalidated bool
+		}{
+			{"The POST to the resource", func() error {
+				return client.post("/api/v1/namespaces", map[string]string{"Name": "ns"}, true)
+			}, false, true},
+			{"The PUT to the item of the resource", func() error {
+				return client.put("/api/v1/namespaces/ns?force=true", map[string]string{}, true)
+			}, false, true},
+			{"The DELETE of the item of the resource", func() error {
+				return client.delete("/api/v1/namespaces/ns")
+			}, false, true},
+			{"The failed mutation", func() error {
+				return client.post("/api/v1/namespaces/failure", map[string]string{}, true)
+			}, true, true},
+			{"The GET of the resource", func() error {
+				return client.getWithStructure("/api/v1/namespaces/ns", &[]cacheTestItem{})
+			}, false, false},
+			{"The POST to another resource", func() error {
+				return client.post("/api/v1/nodes", map[string]string{}, true)
+			}, false, false},
+			{"The POST to the resource in another cluster", func() error {
+				return newCacheTestClient(server, componentCloudone, "staging", "alice").post("/api/v1/namespaces", map[string]string{}, true)
+			}, false, false},
+			{"The POST to the resource of another component", func() error {
+				return newCacheTestClient(server, componentCloudoneAnalysis, "default", "alice").post("/api/v1/namespaces", map[string]string{}, true)
+			}, false, false},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				FlushCache()
+				name := getCacheTestItemName(client, "/api/v1/namespaces")
+				So(getCacheTestItemName(client, "/api/v1/namespaces"), ShouldEqual, name)
+
+				err := testCase.mutate()
+				So(err != nil, ShouldEqual, testCase.failed)
+				if testCase.invalidated {
+					So(getCacheTestItemName(client, "/api/v1/namespaces"), ShouldNotEqual, name)
+				} else {
+					So(getCacheTestItemName(client, "/api/v1/namespaces"), ShouldEqual, name)
+				}
+			})
+		}
+
+		Convey("Each user has the own cached response", func() {
+			FlushCache()
+			name := getCacheTestItemName(client, "/api/v1/namespaces")
+			So(getCacheTestItemName(newCacheTestClient(server, componentCloudone, "default", "bob"), "/api/v1/namespaces"), ShouldNotEqual, name)
+		})
+
+		Convey("The response requested before the invalidation is not cached", func() {
+			FlushCache()
+			itemSlice := []cacheTestItem{cacheTestItem{"stale"}}
+			hit, generation := loadCachedResponse("alice", componentCloudone, "default", "/api/v1/namespaces", &[]cacheTestItem{})
+			So(hit, ShouldBeFalse)
+			invalidateCachedResponse(componentCloudone, "default", "/api/v1/namespaces/ns")
+			storeCachedResponse("alice", componentCloudone, "default", "/api/v1/namespaces", itemSlice, generation)
+			hit, _ = loadCachedResponse("alice", componentCloudone, "default", "/api/v1/namespaces", &[]cacheTestItem{})
+			So(hit, ShouldBeFalse)
+		})
+	})
+}
+
+func TestGetCacheResource(t *testing.T) {
+	Convey("Subject: The cached path is grouped by the resource\n", t, func() {
+		for _, testCase := range []struct {
+			path     string
+			resource string
+		}{
+			{"/api/v1/namespaces", "/api/v1/namespaces"},
+			{"/api/v1/namespaces/ns", "/api/v1/namespaces"},
+			{"/api/v1/namespaces?kind=selected", "/api/v1/namespaces"},
+			{"/api/v1/nodes/topology", "/api/v1/nodes"},
+			{"/apis/other", "/apis/other"},
+		} {
+			So(getCacheResource(testCase.path), ShouldEqual, testCase.resource)
+		}
+	})
+}
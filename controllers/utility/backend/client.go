@@ -0,0 +1,289 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"github.com/astaxie/beego"
+	beegocontext "github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"github.com/cloudawan/cloudone_utility/restclient"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+const (
+	componentCloudone         = "cloudone"
+	componentCloudoneAnalysis = "cloudoneAnalysis"
+	defaultTimeoutInSecond    = 30
+)
+
+// Client is used to access the REST API of cloudone or cloudone_analysis of the cluster.
+// Every request is bounded by the timeout and cancelled with the context.
+// The request id and the span in the context are forwarded to the backend in the headers.
+type Client struct {
+	component      string
+	cluster        string
+	protocol       string
+	host           string
+	port           string
+	tokenHeaderMap map[string]string
+	ctx            context.Context
+	timeout        time.Duration
+	// userName is the owner of the cached responses. It is empty where there is no session.
+	userName string
+}
+
+// NewCloudoneClient creates the client to cloudone of the cluster with the token kept in the session of the request
+func NewCloudoneClient(ctx *beegocontext.Context) *Client {
+	return newClientFromContext(componentCloudone, ctx)
+}
+
+// NewCloudoneAnalysisClient creates the client to cloudone_analysis of the cluster with the token kept in the session of the request
+func NewCloudoneAnalysisClient(ctx *beegocontext.Context) *Client {
+	return newClientFromContext(componentCloudoneAnalysis, ctx)
+}
+
+// NewCloudoneClientWithTokenHeaderMap is used where there is no session such as websocket and webhook.
+// It accesses the default cluster unless WithCluster is used.
+func NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap map[string]string) *Client {
+	return newClient(componentCloudone, GetDefaultClusterName(), tokenHeaderMap, context.Background())
+}
+
+// NewCloudoneAnalysisClientWithTokenHeaderMap is used where there is no session such as websocket and webhook.
+// It accesses the default cluster unless WithCluster is used.
+func NewCloudoneAnalysisClientWithTokenHeaderMap(tokenHeaderMap map[string]string) *Client {
+	return newClient(componentCloudoneAnalysis, GetDefaultClusterName(), tokenHeaderMap, context.Background())
+}
+
+func newClientFromContext(component string, ctx *beegocontext.Context) *Client {
+	tokenHeaderMap, _ := ctx.Input.Session("tokenHeaderMap").(map[string]string)
+	client := newClient(component, GetClusterName(ctx), tokenHeaderMap, ctx.Request.Context())
+	client.userName, _ = ctx.Input.Session("username").(string)
+	return client
+}
+
+func newClient(component string, cluster string, tokenHeaderMap map[string]string, ctx context.Context) *Client {
+	return &Client{
+		component,
+		cluster,
+		GetClusterConfig(cluster, component+"Protocol"),
+		GetClusterConfig(cluster, component+"Host"),
+		GetClusterConfig(cluster, component+"Port"),
+		tokenHeaderMap,
+		ctx,
+		time.Duration(beego.AppConfig.DefaultInt("backendRequestTimeoutInSecond", defaultTimeoutInSecond)) * time.Second,
+		"",
+	}
+}
+
+// WithTimeout returns a copy of the client using the timeout for each request
+func (client *Client) WithTimeout(timeout time.Duration) *Client {
+	copiedClient := *client
+	copiedClient.timeout = timeout
+	return &copiedClient
+}
+
+// WithContext returns a copy of the client whose requests are cancelled with the context
+func (client *Client) WithContext(ctx context.Context) *Client {
+	copiedClient := *client
+	copiedClient.ctx = ctx
+	return &copiedClient
+}
+
+// WithCluster returns a copy of the client accessing the backend of the cluster
+func (client *Client) WithCluster(cluster string) *Client {
+	copiedClient := newClient(client.component, cluster, client.tokenHeaderMap, client.ctx)
+	copiedClient.timeout = client.timeout
+	copiedClient.userName = client.userName
+	return copiedClient
+}
+
+// Cluster returns the name of the cluster the client accesses
+func (client *Client) Cluster() string {
+	return client.cluster
+}
+
+// TokenHeaderMap returns the header used to authorize to the backend
+func (client *Client) TokenHeaderMap() map[string]string {
+	return client.tokenHeaderMap
+}
+
+// URL returns the full url of the path on the backend
+func (client *Client) URL(path string) string {
+	return client.protocol + "://" + client.host + ":" + client.port + path
+}
+
+type result struct {
+	responseData interface{}
+	err          error
+}
+
+// headerMap returns the token header with the request id and the trace context of the span
+func (client *Client) headerMap(requestID string, span *tracing.Span) map[string]string {
+	headerMap := make(map[string]string)
+	for key, value := range client.tokenHeaderMap {
+		headerMap[key] = value
+	}
+	if requestID != "" {
+		headerMap[tracing.HeaderRequestID] = requestID
+	}
+	headerMap[tracing.HeaderTraceParent] = span.TraceParent()
+	return headerMap
+}
+
+// do runs the request in another goroutine so the caller could return when the timeout or cancellation happens
+func (client *Client) do(method string, path string, request func(url string, headerMap map[string]string) (interface{}, error)) (interface{}, error) {
+	requestID := tracing.GetRequestID(client.ctx)
+	_, span := tracing.StartSpan(client.ctx, method+" "+path, tracing.SpanKindClient)
+	span.SetAttribute("http.method", method)
+	span.SetAttribute("http.url", client.URL(path))
+	span.SetAttribute("peer.service", client.component)
+	span.SetAttribute("cluster", client.cluster)
+	defer span.End()
+
+	startTime := time.Now()
+	responseData, err := client.run(path, func(url string) (interface{}, error) {
+		return request(url, client.headerMap(requestID, span))
+	})
+
+	status := "ok"
+	if err != nil {
+		status = "error"
+		span.SetError(err)
+		if requestError, ok := err.(restclient.RequestError); ok {
+			status = strconv.Itoa(requestError.StatusCode)
+			span.SetAttribute("http.status_code", status)
+			// Let the GUI error show the request id to be found in the logs of the backend
+			if responseDataJsonMap, ok := requestError.ResponseData.(map[string]interface{}); ok && requestID != "" {
+				if _, ok := responseDataJsonMap["RequestID"]; ok == false {
+					responseDataJsonMap["RequestID"] = requestID
+				}
+			}
+		}
+	}
+	metrics.ObserveBackendRequest(client.component, method, path, status, time.Since(startTime))
+
+	// The failed request may still change the data such as timeout
+	if method != "GET" {
+		invalidateCachedResponse(client.component, client.cluster, path)
+	}
+
+	return responseData, err
+}
+
+func (client *Client) run(path string, request func(url string) (interface{}, error)) (interface{}, error) {
+	ctx, cancel := context.WithTimeout(client.ctx, client.timeout)
+	defer cancel()
+
+	resultChannel := make(chan result, 1)
+	go func() {
+		responseData, err := request(client.URL(path))
+		resultChannel <- result{responseData, err}
+	}()
+
+	select {
+	case result := <-resultChannel:
+		return result.responseData, result.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, UnavailableError{"Request " + path + " timeout after " + client.timeout.String()}
+		} else {
+			return nil, errors.New("Request " + path + " is cancelled")
+		}
+	}
+}
+
+// doWithStructure decodes into a new value and only copies it to the returned structure on success.
+// It prevents the abandoned request from writing the returned structure after timeout.
+func (client *Client) doWithStructure(method string, path string, returnedStructure interface{}, request func(url string, headerMap map[string]string, returnedStructure interface{}) (interface{}, error)) error {
+	if returnedStructure == nil {
+		_, err := client.do(method, path, func(url string, headerMap map[string]string) (interface{}, error) {
+			return request(url, headerMap, nil)
+		})
+		return err
+	}
+
+	value := reflect.New(reflect.TypeOf(returnedStructure).Elem())
+	_, err := client.do(method, path, func(url string, headerMap map[string]string) (interface{}, error) {
+		return request(url, headerMap, value.Interface())
+	})
+	if err != nil {
+		return err
+	}
+
+	reflect.ValueOf(returnedStructure).Elem().Set(value.Elem())
+	return nil
+}
+
+func (client *Client) get(path string) (interface{}, error) {
+	return client.do("GET", path, func(url string, headerMap map[string]string) (interface{}, error) {
+		return restclient.RequestGet(url, headerMap, true)
+	})
+}
+
+func (client *Client) getWithStructure(path string, returnedStructure interface{}) error {
+	return client.doWithStructure("GET", path, returnedStructure, func(url string, headerMap map[string]string, returnedStructure interface{}) (interface{}, error) {
+		return restclient.RequestGetWithStructure(url, returnedStructure, headerMap)
+	})
+}
+
+// getWithStructureAndStatusCode doesn't treat the status code as error so the caller could tell the data doesn't exist
+func (client *Client) getWithStructureAndStatusCode(path string, returnedStructure interface{}) (int, error) {
+	statusCode := 0
+	err := client.doWithStructure("GET", path, returnedStructure, func(url string, headerMap map[string]string, returnedStructure interface{}) (interface{}, error) {
+		var responseData interface{}
+		var err error
+		statusCode, responseData, _, err = restclient.RequestWithStructure("GET", url, nil, returnedStructure, headerMap)
+		return responseData, err
+	})
+	return statusCode, err
+}
+
+func (client *Client) post(path string, body interface{}, hasResponseBody bool) error {
+	_, err := client.do("POST", path, func(url string, headerMap map[string]string) (interface{}, error) {
+		return restclient.RequestPost(url, body, headerMap, hasResponseBody)
+	})
+	return err
+}
+
+func (client *Client) postWithStructure(path string, body interface{}, returnedStructure interface{}) error {
+	return client.doWithStructure("POST", path, returnedStructure, func(url string, headerMap map[string]string, returnedStructure interface{}) (interface{}, error) {
+		return restclient.RequestPostWithStructure(url, body, returnedStructure, headerMap)
+	})
+}
+
+func (client *Client) put(path string, body interface{}, hasResponseBody bool) error {
+	_, err := client.do("PUT", path, func(url string, headerMap map[string]string) (interface{}, error) {
+		return restclient.RequestPut(url, body, headerMap, hasResponseBody)
+	})
+	return err
+}
+
+func (client *Client) putWithStructure(path string, body interface{}, returnedStructure interface{}) error {
+	return client.doWithStructure("PUT", path, returnedStructure, func(url string, headerMap map[string]string, returnedStructure interface{}) (interface{}, error) {
+		return restclient.RequestPutWithStructure(url, body, returnedStructure, headerMap)
+	})
+}
+
+func (client *Client) delete(path string) error {
+	_, err := client.do("DELETE", path, func(url string, headerMap map[string]string) (interface{}, error) {
+		return restclient.RequestDelete(url, nil, headerMap, true)
+	})
+	return err
+}
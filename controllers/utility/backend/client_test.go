@@ -0,0 +1,128 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"github.com/cloudawan/cloudone_utility/restclient"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+// newHeaderTestServer records the header of the last request. The path ending with /failure replies the error.
+func newHeaderTestServer(lock *sync.Mutex, header *http.Header) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		lock.Lock()
+		*header = request.Header
+		lock.Unlock()
+		if strings.HasSuffix(request.URL.Path, "/failure") {
+			http.Error(responseWriter, `{"Error":"Failure"}`, http.StatusInternalServerError)
+			return
+		}
+		responseWriter.Write([]byte("[]"))
+	}))
+}
+
+func TestRequestIDPropagation(t *testing.T) {
+	lock := sync.Mutex{}
+	header := http.Header{}
+	server := newHeaderTestServer(&lock, &header)
+	defer server.Close()
+
+	host, port, _ := net.SplitHostPort(strings.TrimPrefix(server.URL, "http://"))
+	beego.AppConfig.Set("cloudoneProtocol", "http")
+	beego.AppConfig.Set("cloudoneHost", host)
+	beego.AppConfig.Set("cloudonePort", port)
+
+	getHeader := func(key string) string {
+		lock.Lock()
+		defer lock.Unlock()
+		return header.Get(key)
+	}
+
+	Convey("Subject: The request id of the context is forwarded to the backend\n", t, func() {
+		tokenHeaderMap := map[string]string{"token": "token-of-alice"}
+		client := NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap).WithContext(tracing.WithRequestID(context.Background(), "request-1"))
+
+		testCaseSlice := []struct {
+			description string
+			request     func() error
+		}{
+			{"The GET", func() error {
+				return client.getWithStructure("/api/v1/header", &[]interface{}{})
+			}},
+			{"The POST", func() error {
+				return client.post("/api/v1/header", map[string]string{}, false)
+			}},
+			{"The DELETE", func() error {
+				return client.delete("/api/v1/header")
+			}},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				So(testCase.request(), ShouldBeNil)
+				So(getHeader(tracing.HeaderRequestID), ShouldEqual, "request-1")
+				So(getHeader("token"), ShouldEqual, "token-of-alice")
+				// version-traceid-parentid-flags
+				So(len(strings.Split(getHeader(tracing.HeaderTraceParent), "-")), ShouldEqual, 4)
+			})
+		}
+
+		Convey("The token header of the client is not changed", func() {
+			So(client.post("/api/v1/header", map[string]string{}, false), ShouldBeNil)
+			So(tokenHeaderMap, ShouldResemble, map[string]string{"token": "token-of-alice"})
+		})
+
+		Convey("The error of the backend has the request id", func() {
+			err := client.post("/api/v1/header/failure", map[string]string{}, false)
+			requestError, ok := err.(restclient.RequestError)
+			So(ok, ShouldBeTrue)
+			So(requestError.ResponseData.(map[string]interface{})["RequestID"], ShouldEqual, "request-1")
+		})
+
+		Convey("The context without the request id sends no request id", func() {
+			So(NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap).post("/api/v1/header", map[string]string{}, false), ShouldBeNil)
+			So(getHeader(tracing.HeaderRequestID), ShouldBeEmpty)
+		})
+	})
+}
+
+func TestAuditLogRequestID(t *testing.T) {
+	Convey("Subject: The request id recorded in the audit log is found in any case of the header name\n", t, func() {
+		testCaseSlice := []struct {
+			description   string
+			requestHeader map[string][]string
+			requestID     string
+		}{
+			{"The header recorded by the GUI", map[string][]string{"X-Request-ID": {"request-1"}}, "request-1"},
+			{"The canonical header", map[string][]string{"X-Request-Id": {"request-1"}}, "request-1"},
+			{"The header without value", map[string][]string{"X-Request-ID": {}}, ""},
+			{"No header", nil, ""},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				So(AuditLog{RequestHeader: testCase.requestHeader}.GetRequestID(), ShouldEqual, testCase.requestID)
+			})
+		}
+	})
+}
@@ -0,0 +1,92 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/astaxie/beego"
+	beegocontext "github.com/astaxie/beego/context"
+	"strings"
+)
+
+const (
+	// DefaultClusterName is used when clusterProfiles is not configured and the top level configuration is the only cluster
+	DefaultClusterName = "default"
+	// SessionNameCluster is the name of the cluster the session works on
+	SessionNameCluster   = "cluster"
+	clusterSectionPrefix = "cluster-"
+)
+
+// GetClusterNameSlice returns the cluster profiles in clusterProfiles such as staging,production.
+// The configuration of the profile is in the section [cluster-<name>] and the missing key falls back to the top level one.
+func GetClusterNameSlice() []string {
+	nameSlice := make([]string, 0)
+	for _, name := range strings.Split(beego.AppConfig.String("clusterProfiles"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name != "" {
+			nameSlice = append(nameSlice, name)
+		}
+	}
+	if len(nameSlice) == 0 {
+		nameSlice = append(nameSlice, DefaultClusterName)
+	}
+	return nameSlice
+}
+
+// GetDefaultClusterName returns the first profile which is used by the login without selecting and the requests without session
+func GetDefaultClusterName() string {
+	return GetClusterNameSlice()[0]
+}
+
+func IsClusterExisting(name string) bool {
+	for _, clusterName := range GetClusterNameSlice() {
+		if clusterName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMultipleCluster is used to hide the cluster selector when there is only one cluster
+func HasMultipleCluster() bool {
+	return len(GetClusterNameSlice()) > 1
+}
+
+// GetClusterName returns the cluster selected in the session or the default one
+func GetClusterName(ctx *beegocontext.Context) string {
+	clusterName, _ := ctx.Input.Session(SessionNameCluster).(string)
+	if clusterName == "" || IsClusterExisting(clusterName) == false {
+		return GetDefaultClusterName()
+	}
+	return clusterName
+}
+
+// GetClusterConfigName returns the name of the key in the section of the cluster such as cluster-staging::cloudoneHost.
+// The key of the default cluster is the top level one.
+func GetClusterConfigName(clusterName string, key string) string {
+	if clusterName != "" && clusterName != DefaultClusterName {
+		return clusterSectionPrefix + clusterName + "::" + key
+	}
+	return key
+}
+
+// GetClusterConfig returns the key in the section of the cluster or the top level one if it is not set there
+func GetClusterConfig(clusterName string, key string) string {
+	if name := GetClusterConfigName(clusterName, key); name != key {
+		if value := beego.AppConfig.String(name); value != "" {
+			return value
+		}
+	}
+	return beego.AppConfig.String(key)
+}
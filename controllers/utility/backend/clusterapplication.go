@@ -0,0 +1,110 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"strconv"
+	"time"
+)
+
+type Cluster struct {
+	Name                      string
+	Description               string
+	ReplicationControllerJson string
+	ServiceJson               string
+	Environment               map[string]string
+	ScriptType                string
+	ScriptContent             string
+}
+
+type ClusterLaunch struct {
+	Size                              int
+	EnvironmentSlice                  []ClusterEnvironment
+	ReplicationControllerExtraJsonMap map[string]interface{}
+}
+
+type ClusterEnvironment struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type DeployClusterApplication struct {
+	Name                              string
+	Namespace                         string
+	Size                              int
+	EnvironmentSlice                  []ClusterEnvironment
+	ReplicationControllerExtraJsonMap map[string]interface{}
+	ServiceName                       string
+	ReplicationControllerNameSlice    []string
+	CreatedTime                       time.Time
+}
+
+func (client *Client) GetClusterApplicationSlice() ([]Cluster, error) {
+	clusterSlice := make([]Cluster, 0)
+	err := client.getWithStructure("/api/v1/clusterapplications/", &clusterSlice)
+	return clusterSlice, err
+}
+
+func (client *Client) GetClusterApplication(name string) (*Cluster, error) {
+	cluster := &Cluster{}
+	err := client.getWithStructure("/api/v1/clusterapplications/"+name, cluster)
+	if err != nil {
+		return nil, err
+	}
+	return cluster, nil
+}
+
+// CreateClusterApplication overwrites the existing one with the same name
+func (client *Client) CreateClusterApplication(cluster Cluster) error {
+	return client.post("/api/v1/clusterapplications/", cluster, true)
+}
+
+func (client *Client) DeleteClusterApplication(name string) error {
+	return client.delete("/api/v1/clusterapplications/" + name)
+}
+
+func (client *Client) LaunchClusterApplication(namespace string, name string, clusterLaunch ClusterLaunch) error {
+	return client.postWithStructure("/api/v1/clusterapplications/launch/"+namespace+"/"+name, clusterLaunch, nil)
+}
+
+// GetAllDeployClusterApplicationSlice returns the deployed cluster applications in all namespaces
+func (client *Client) GetAllDeployClusterApplicationSlice() ([]DeployClusterApplication, error) {
+	deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
+	err := client.getWithStructure("/api/v1/deployclusterapplications/", &deployClusterApplicationSlice)
+	return deployClusterApplicationSlice, err
+}
+
+func (client *Client) GetDeployClusterApplicationSlice(namespace string) ([]DeployClusterApplication, error) {
+	deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
+	err := client.getWithStructure("/api/v1/deployclusterapplications/"+namespace, &deployClusterApplicationSlice)
+	return deployClusterApplicationSlice, err
+}
+
+func (client *Client) GetDeployClusterApplication(namespace string, name string) (*DeployClusterApplication, error) {
+	deployClusterApplication := &DeployClusterApplication{}
+	err := client.getWithStructure("/api/v1/deployclusterapplications/"+namespace+"/"+name, deployClusterApplication)
+	if err != nil {
+		return nil, err
+	}
+	return deployClusterApplication, nil
+}
+
+func (client *Client) ResizeDeployClusterApplication(namespace string, name string, size int, environmentSlice []ClusterEnvironment) error {
+	return client.put("/api/v1/deployclusterapplications/size/"+namespace+"/"+name+"?size="+strconv.Itoa(size), environmentSlice, true)
+}
+
+func (client *Client) DeleteDeployClusterApplication(namespace string, name string) error {
+	return client.delete("/api/v1/deployclusterapplications/" + namespace + "/" + name)
+}
@@ -0,0 +1,54 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type Credential struct {
+	IP       string
+	SSH      SSH
+	Disabled bool
+}
+
+type SSH struct {
+	Port     int
+	User     string
+	Password string
+}
+
+func (client *Client) GetHostCredentialSlice() ([]Credential, error) {
+	credentialSlice := make([]Credential, 0)
+	err := client.getWithStructure("/api/v1/hosts/credentials/", &credentialSlice)
+	return credentialSlice, err
+}
+
+func (client *Client) GetHostCredential(ip string) (*Credential, error) {
+	credential := &Credential{}
+	err := client.getWithStructure("/api/v1/hosts/credentials/"+ip, credential)
+	if err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
+func (client *Client) CreateHostCredential(credential Credential) error {
+	return client.postWithStructure("/api/v1/hosts/credentials/", credential, nil)
+}
+
+func (client *Client) UpdateHostCredential(credential Credential) error {
+	return client.putWithStructure("/api/v1/hosts/credentials/"+credential.IP, credential, nil)
+}
+
+func (client *Client) DeleteHostCredential(ip string) error {
+	return client.delete("/api/v1/hosts/credentials/" + ip)
+}
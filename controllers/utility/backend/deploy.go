@@ -0,0 +1,90 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"strconv"
+	"time"
+)
+
+type DeployInformation struct {
+	Namespace                 string
+	ImageInformationName      string
+	CurrentVersion            string
+	CurrentVersionDescription string
+	Description               string
+	ReplicaAmount             int
+	ContainerPortSlice        []DeployContainerPort
+	EnvironmentSlice          []ReplicationControllerContainerEnvironment
+	ResourceMap               map[string]interface{}
+	ExtraJsonMap              map[string]interface{}
+	AutoUpdateForNewBuild     bool
+	CreatedTime               time.Time
+}
+
+type DeployContainerPort struct {
+	Name          string
+	ContainerPort int
+	NodePort      int
+	Protocol      string
+}
+
+type DeployCreateInput struct {
+	ImageInformationName  string
+	Version               string
+	Description           string
+	ReplicaAmount         int
+	PortSlice             []DeployContainerPort
+	EnvironmentSlice      []ReplicationControllerContainerEnvironment
+	ResourceMap           map[string]interface{}
+	ExtraJsonMap          map[string]interface{}
+	AutoUpdateForNewBuild bool
+}
+
+type DeployUpdateInput struct {
+	ImageInformationName string
+	Version              string
+	Description          string
+	EnvironmentSlice     []ReplicationControllerContainerEnvironment
+}
+
+// GetAllDeployInformationSlice returns the deployments in all namespaces
+func (client *Client) GetAllDeployInformationSlice() ([]DeployInformation, error) {
+	deployInformationSlice := make([]DeployInformation, 0)
+	err := client.getWithStructure("/api/v1/deploys/", &deployInformationSlice)
+	return deployInformationSlice, err
+}
+
+func (client *Client) GetDeployInformationSlice(namespace string) ([]DeployInformation, error) {
+	deployInformationSlice := make([]DeployInformation, 0)
+	err := client.getWithStructure("/api/v1/deploys/"+namespace, &deployInformationSlice)
+	return deployInformationSlice, err
+}
+
+func (client *Client) CreateDeploy(namespace string, deployCreateInput DeployCreateInput) error {
+	return client.postWithStructure("/api/v1/deploys/create/"+namespace, deployCreateInput, nil)
+}
+
+func (client *Client) UpdateDeploy(namespace string, deployUpdateInput DeployUpdateInput) error {
+	return client.putWithStructure("/api/v1/deploys/update/"+namespace, deployUpdateInput, nil)
+}
+
+func (client *Client) ResizeDeploy(namespace string, name string, size int) error {
+	return client.put("/api/v1/deploys/resize/"+namespace+"/"+name+"?size="+strconv.Itoa(size), make(map[string]interface{}), false)
+}
+
+func (client *Client) DeleteDeploy(namespace string, name string) error {
+	return client.delete("/api/v1/deploys/" + namespace + "/" + name)
+}
@@ -0,0 +1,58 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type DeployBlueGreen struct {
+	ImageInformation string
+	Namespace        string
+	NodePort         int
+	Description      string
+	SessionAffinity  string
+}
+
+func (client *Client) GetDeployBlueGreenSlice() ([]DeployBlueGreen, error) {
+	deployBlueGreenSlice := make([]DeployBlueGreen, 0)
+	err := client.getWithStructure("/api/v1/deploybluegreens/", &deployBlueGreenSlice)
+	return deployBlueGreenSlice, err
+}
+
+// GetDeployBlueGreen returns nil without error if the blue green deployment doesn't exist
+func (client *Client) GetDeployBlueGreen(imageInformationName string) (*DeployBlueGreen, error) {
+	deployBlueGreen := &DeployBlueGreen{}
+	statusCode, err := client.getWithStructureAndStatusCode("/api/v1/deploybluegreens/"+imageInformationName, deployBlueGreen)
+	if err != nil {
+		return nil, err
+	}
+	if statusCode == 404 {
+		return nil, nil
+	}
+	return deployBlueGreen, nil
+}
+
+// UpdateDeployBlueGreen creates the blue green deployment if it doesn't exist
+func (client *Client) UpdateDeployBlueGreen(deployBlueGreen DeployBlueGreen) error {
+	return client.putWithStructure("/api/v1/deploybluegreens/", deployBlueGreen, nil)
+}
+
+func (client *Client) DeleteDeployBlueGreen(imageInformationName string) error {
+	return client.delete("/api/v1/deploybluegreens/" + imageInformationName)
+}
+
+// GetDeployBlueGreenDeployableNamespaceSlice returns the namespaces where the image is deployed
+func (client *Client) GetDeployBlueGreenDeployableNamespaceSlice(imageInformationName string) ([]string, error) {
+	namespaceSlice := make([]string, 0)
+	err := client.getWithStructure("/api/v1/deploybluegreens/deployable/"+imageInformationName, &namespaceSlice)
+	return namespaceSlice, err
+}
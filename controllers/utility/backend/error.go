@@ -0,0 +1,77 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"github.com/cloudawan/cloudone_utility/restclient"
+	"net"
+	"strings"
+)
+
+// UnavailableError is returned when the backend doesn't reply in time
+type UnavailableError struct {
+	Message string
+}
+
+func (err UnavailableError) Error() string {
+	return err.Message
+}
+
+// IsUnavailable checks whether the request fails because the backend can't be reached or fails by itself
+// rather than rejects the request. The caller should retry later instead of treating the input as invalid.
+func IsUnavailable(err error) bool {
+	switch typedError := err.(type) {
+	case UnavailableError:
+		return true
+	case restclient.RequestError:
+		return typedError.StatusCode >= 500
+	case net.Error:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsKeyNotFound checks whether the error is caused by the data which doesn't exist in the backend storage
+func IsKeyNotFound(err error) bool {
+	requestError, ok := err.(restclient.RequestError)
+	if ok == false {
+		return false
+	}
+	errorJsonMap, _ := requestError.ResponseData.(map[string]interface{})
+	errorMessage, _ := errorJsonMap["ErrorMessage"].(string)
+	return strings.HasPrefix(errorMessage, "100: Key not found")
+}
+
+// GetResponseError returns the error reason replied by the backend
+func GetResponseError(err error) string {
+	requestError, ok := err.(restclient.RequestError)
+	if ok == false {
+		return ""
+	}
+	errorJsonMap, _ := requestError.ResponseData.(map[string]interface{})
+	errorMessage, _ := errorJsonMap["Error"].(string)
+	return errorMessage
+}
+
+// GetResponseJsonMap returns the json body replied by the backend with the error
+func GetResponseJsonMap(err error) map[string]interface{} {
+	requestError, ok := err.(restclient.RequestError)
+	if ok == false {
+		return nil
+	}
+	errorJsonMap, _ := requestError.ResponseData.(map[string]interface{})
+	return errorJsonMap
+}
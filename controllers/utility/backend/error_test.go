@@ -0,0 +1,46 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"github.com/cloudawan/cloudone_utility/restclient"
+	"net"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestIsUnavailable(t *testing.T) {
+	Convey("Subject: Backend outage is told from the rejection\n", t, func() {
+		for _, testCase := range []struct {
+			name        string
+			err         error
+			unavailable bool
+		}{
+			{"no error", nil, false},
+			{"timeout", UnavailableError{"Request /api/v1/users timeout after 5s"}, true},
+			{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+			{"server error", restclient.RequestError{StatusCode: 503, ResponseData: map[string]interface{}{"Error": "Unavailable"}}, true},
+			{"invalid token", restclient.RequestError{StatusCode: 401, ResponseData: map[string]interface{}{"Error": "Token doesn't exist"}}, false},
+			{"not found", restclient.RequestError{StatusCode: 404, ResponseData: map[string]interface{}{"Error": "Key not found"}}, false},
+			{"other error", errors.New("Personal access token is malformed"), false},
+		} {
+			Convey("The "+testCase.name+" is classified", func() {
+				So(IsUnavailable(testCase.err), ShouldEqual, testCase.unavailable)
+			})
+		}
+	})
+}
@@ -0,0 +1,94 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"strconv"
+	"time"
+)
+
+type KubernetesEvent struct {
+	Namespace      string
+	Name           string
+	Kind           string
+	Source         map[string]interface{}
+	Id             string
+	FirstTimestamp time.Time
+	LastTimestamp  time.Time
+	Count          int
+	Message        string
+	Reason         string
+	Acknowledge    bool
+}
+
+// GetKubernetesEventSlice converts the historical events stored as the search documents
+func (client *Client) GetKubernetesEventSlice(acknowledge bool, size int, offset int) ([]KubernetesEvent, error) {
+	jsonMapSlice := make([]map[string]interface{}, 0)
+	err := client.getWithStructure("/api/v1/historicalevents?acknowledge="+strconv.FormatBool(acknowledge)+"&size="+strconv.Itoa(size)+"&offset="+strconv.Itoa(offset), &jsonMapSlice)
+	if err != nil {
+		return nil, err
+	}
+
+	kubernetesEventSlice := make([]KubernetesEvent, 0)
+	for _, jsonMap := range jsonMapSlice {
+		sourceJsonMap, _ := jsonMap["_source"].(map[string]interface{})
+		metadataJsonMap, _ := sourceJsonMap["metadata"].(map[string]interface{})
+		involvedObjectJsonMap, _ := sourceJsonMap["involvedObject"].(map[string]interface{})
+		searchMetaDataJsonMap, _ := sourceJsonMap["searchMetaData"].(map[string]interface{})
+
+		namespace, _ := metadataJsonMap["namespace"].(string)
+		name, _ := involvedObjectJsonMap["name"].(string)
+		kind, _ := involvedObjectJsonMap["kind"].(string)
+		source, _ := sourceJsonMap["source"].(map[string]interface{})
+		id, _ := jsonMap["_id"].(string)
+		firstTimestamp, _ := sourceJsonMap["firstTimestamp"].(string)
+		lastTimestamp, _ := sourceJsonMap["lastTimestamp"].(string)
+		count, _ := sourceJsonMap["count"].(float64)
+		message, _ := sourceJsonMap["message"].(string)
+		reason, _ := sourceJsonMap["reason"].(string)
+		acknowledge, _ := searchMetaDataJsonMap["acknowledge"].(bool)
+
+		firstTime, err := time.Parse(time.RFC3339, firstTimestamp)
+		if err == nil {
+			firstTime = firstTime.Local()
+		}
+		lastTime, err := time.Parse(time.RFC3339, lastTimestamp)
+		if err == nil {
+			lastTime = lastTime.Local()
+		}
+
+		kubernetesEvent := KubernetesEvent{
+			namespace,
+			name,
+			kind,
+			source,
+			id,
+			firstTime,
+			lastTime,
+			int(count),
+			message,
+			reason,
+			acknowledge,
+		}
+
+		kubernetesEventSlice = append(kubernetesEventSlice, kubernetesEvent)
+	}
+
+	return kubernetesEventSlice, nil
+}
+
+func (client *Client) AcknowledgeKubernetesEvent(namespace string, id string, acknowledge bool) error {
+	return client.putWithStructure("/api/v1/historicalevents/"+namespace+"/"+id+"?acknowledge="+strconv.FormatBool(acknowledge), nil, nil)
+}
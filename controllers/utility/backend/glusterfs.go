@@ -0,0 +1,112 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"time"
+)
+
+type GlusterfsCluster struct {
+	Name              string
+	HostSlice         []string
+	Path              string
+	SSHDialTimeout    time.Duration
+	SSHSessionTimeout time.Duration
+	SSHPort           int
+	SSHUser           string
+	SSHPassword       string
+}
+
+// GlusterfsClusterInput is used to create or update the cluster since the timeout is in millisecond instead of duration
+type GlusterfsClusterInput struct {
+	Name                           string
+	HostSlice                      []string
+	Path                           string
+	SSHDialTimeoutInMilliSecond    int
+	SSHSessionTimeoutInMilliSecond int
+	SSHPort                        int
+	SSHUser                        string
+	SSHPassword                    string
+}
+
+type GlusterfsVolume struct {
+	VolumeName     string
+	Type           string
+	VolumeID       string
+	Status         string
+	NumberOfBricks string
+	TransportType  string
+	Bricks         []string
+	Size           int
+	ClusterName    string
+}
+
+type GlusterfsVolumeCreateParameter struct {
+	ClusterName  string
+	VolumeName   string
+	Stripe       int
+	Replica      int
+	Arbiter      int
+	Disperse     int
+	DisperseData int
+	Redundancy   int
+	Transport    string
+	HostSlice    []string
+}
+
+func (client *Client) GetGlusterfsClusterSlice() ([]GlusterfsCluster, error) {
+	glusterfsClusterSlice := make([]GlusterfsCluster, 0)
+	err := client.getWithStructure("/api/v1/glusterfs/clusters/", &glusterfsClusterSlice)
+	return glusterfsClusterSlice, err
+}
+
+func (client *Client) GetGlusterfsCluster(clusterName string) (*GlusterfsCluster, error) {
+	glusterfsCluster := &GlusterfsCluster{}
+	err := client.getWithStructure("/api/v1/glusterfs/clusters/"+clusterName, glusterfsCluster)
+	if err != nil {
+		return nil, err
+	}
+	return glusterfsCluster, nil
+}
+
+func (client *Client) CreateGlusterfsCluster(glusterfsClusterInput GlusterfsClusterInput) error {
+	return client.postWithStructure("/api/v1/glusterfs/clusters/", glusterfsClusterInput, nil)
+}
+
+func (client *Client) UpdateGlusterfsCluster(glusterfsClusterInput GlusterfsClusterInput) error {
+	return client.putWithStructure("/api/v1/glusterfs/clusters/"+glusterfsClusterInput.Name, glusterfsClusterInput, nil)
+}
+
+func (client *Client) DeleteGlusterfsCluster(clusterName string) error {
+	return client.delete("/api/v1/glusterfs/clusters/" + clusterName)
+}
+
+func (client *Client) GetGlusterfsVolumeSlice(clusterName string) ([]GlusterfsVolume, error) {
+	glusterfsVolumeSlice := make([]GlusterfsVolume, 0)
+	err := client.getWithStructure("/api/v1/glusterfs/clusters/"+clusterName+"/volumes/", &glusterfsVolumeSlice)
+	return glusterfsVolumeSlice, err
+}
+
+func (client *Client) CreateGlusterfsVolume(glusterfsVolumeCreateParameter GlusterfsVolumeCreateParameter) error {
+	return client.postWithStructure("/api/v1/glusterfs/clusters/"+glusterfsVolumeCreateParameter.ClusterName+"/volumes/", glusterfsVolumeCreateParameter, nil)
+}
+
+func (client *Client) DeleteGlusterfsVolume(clusterName string, volumeName string) error {
+	return client.delete("/api/v1/glusterfs/clusters/" + clusterName + "/volumes/" + volumeName)
+}
+
+func (client *Client) ResetGlusterfsVolume(clusterName string, volumeName string) error {
+	return client.put("/api/v1/glusterfs/clusters/"+clusterName+"/volumes/"+volumeName, make(map[string]interface{}), true)
+}
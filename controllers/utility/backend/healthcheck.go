@@ -0,0 +1,22 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+// GetHealthCheck returns the component status reported by cloudone or cloudone_analysis depending on the client
+func (client *Client) GetHealthCheck() (map[string]interface{}, error) {
+	jsonMap := make(map[string]interface{})
+	err := client.getWithStructure("/api/v1/healthchecks/", &jsonMap)
+	return jsonMap, err
+}
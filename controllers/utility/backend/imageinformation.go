@@ -0,0 +1,48 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type ImageInformation struct {
+	Name           string
+	Kind           string
+	Description    string
+	CurrentVersion string
+	BuildParameter map[string]string
+}
+
+type DeployUpgradeInput struct {
+	ImageInformationName string
+	Description          string
+}
+
+func (client *Client) GetImageInformationSlice() ([]ImageInformation, error) {
+	imageInformationSlice := make([]ImageInformation, 0)
+	err := client.getWithStructureCached("/api/v1/imageinformations/", &imageInformationSlice)
+	return imageInformationSlice, err
+}
+
+// CreateImageInformation creates the image information and launches the first build asynchronizedly
+func (client *Client) CreateImageInformation(imageInformation ImageInformation) error {
+	return client.postWithStructure("/api/v1/imageinformations/create/", imageInformation, nil)
+}
+
+// UpgradeImageInformation launches a new build asynchronizedly
+func (client *Client) UpgradeImageInformation(deployUpgradeInput DeployUpgradeInput) error {
+	return client.putWithStructure("/api/v1/imageinformations/upgrade/", deployUpgradeInput, nil)
+}
+
+func (client *Client) DeleteImageInformation(name string) error {
+	return client.delete("/api/v1/imageinformations/" + name)
+}
@@ -0,0 +1,42 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type ImageRecord struct {
+	ImageInformation string
+	Version          string
+	Path             string
+	VersionInfo      map[string]string
+	Environment      map[string]string
+	Description      string
+	CreatedTime      string
+	Failure          bool
+}
+
+type ByImageRecord []ImageRecord
+
+func (b ByImageRecord) Len() int           { return len(b) }
+func (b ByImageRecord) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b ByImageRecord) Less(i, j int) bool { return b[i].Version > b[j].Version } // Use > to list from latest to oldest
+
+func (client *Client) GetImageRecordSlice(imageInformationName string) ([]ImageRecord, error) {
+	imageRecordSlice := make([]ImageRecord, 0)
+	err := client.getWithStructure("/api/v1/imagerecords/"+imageInformationName, &imageRecordSlice)
+	return imageRecordSlice, err
+}
+
+func (client *Client) DeleteImageRecord(imageInformationName string, version string) error {
+	return client.delete("/api/v1/imagerecords/" + imageInformationName + "/" + version)
+}
@@ -0,0 +1,126 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"errors"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type ReplicationControllerMetricList struct {
+	ErrorSlice                       []string
+	ReplicationControllerMetricSlice []ReplicationControllerMetric
+}
+
+type ReplicationControllerMetric struct {
+	Namespace                 string
+	ReplicationControllerName string
+	ValidPodSlice             []bool
+	PodMetricSlice            []PodMetric
+	Size                      int
+}
+
+type PodMetric struct {
+	KubeletHost          string
+	Namespace            string
+	PodName              string
+	ValidContainerSlice  []bool
+	ContainerMetricSlice []ContainerMetric
+}
+
+type ContainerMetric struct {
+	ContainerName                     string
+	CpuUsageTotalSlice                []int64
+	MemoryUsageSlice                  []int64
+	DiskIOServiceBytesStatsTotalSlice []int64
+	DiskIOServicedStatsTotalSlice     []int64
+	NetworkRXBytesSlice               []int64
+	NetworkTXBytesSlice               []int64
+	NetworkRXPacketsSlice             []int64
+	NetworkTXPacketsSlice             []int64
+}
+
+type NodeMetric struct {
+	Valid                             bool
+	KubeletHost                       string
+	CpuUsageTotalSlice                []int64
+	MemoryUsageSlice                  []int64
+	DiskIOServiceBytesStatsTotalSlice []int64
+	DiskIOServicedStatsTotalSlice     []int64
+	NetworkRXBytesSlice               []int64
+	NetworkTXBytesSlice               []int64
+	NetworkRXPacketsSlice             []int64
+	NetworkTXPacketsSlice             []int64
+}
+
+func (client *Client) GetReplicationControllerMetricList(namespace string) (*ReplicationControllerMetricList, error) {
+	replicationControllerMetricList := &ReplicationControllerMetricList{}
+	err := client.getWithStructure("/api/v1/replicationcontrollermetrics/"+namespace, replicationControllerMetricList)
+	if err != nil {
+		return nil, err
+	}
+	return replicationControllerMetricList, nil
+}
+
+func (client *Client) GetReplicationControllerMetric(namespace string, name string) (*ReplicationControllerMetric, error) {
+	replicationControllerMetric := &ReplicationControllerMetric{}
+	err := client.getWithStructure("/api/v1/replicationcontrollermetrics/"+namespace+"/"+name, replicationControllerMetric)
+	if err != nil {
+		return nil, err
+	}
+	return replicationControllerMetric, nil
+}
+
+func (client *Client) GetNodeMetricSlice() ([]NodeMetric, error) {
+	nodeMetricSlice := make([]NodeMetric, 0)
+	err := client.getWithStructure("/api/v1/nodemetrics", &nodeMetricSlice)
+	return nodeMetricSlice, err
+}
+
+// GetHistoricalReplicationControllerNameSlice returns the replication controllers having the historical data
+func (client *Client) GetHistoricalReplicationControllerNameSlice(namespace string) ([]string, error) {
+	nameSlice := make([]string, 0)
+	err := client.getWithStructure("/api/v1/historicalreplicationcontrollers/names/"+namespace, &nameSlice)
+	return nameSlice, err
+}
+
+// GetHistoricalReplicationControllerMetric returns the metrics of the replication controller aggregated into the amount of points
+func (client *Client) GetHistoricalReplicationControllerMetric(namespace string, name string, from time.Time, to time.Time, aggregationAmount int) (map[string]interface{}, error) {
+	return client.getHistoricalMetric("/api/v1/historicalreplicationcontrollermetrics/"+namespace+"/"+name, from, to, aggregationAmount)
+}
+
+// GetAllHistoricalReplicationControllerMetric returns the metrics of all replication controllers in the namespace keyed by the replication controller name
+func (client *Client) GetAllHistoricalReplicationControllerMetric(namespace string, from time.Time, to time.Time, aggregationAmount int) (map[string]interface{}, error) {
+	return client.getHistoricalMetric("/api/v1/historicalreplicationcontrollermetrics/"+namespace, from, to, aggregationAmount)
+}
+
+func (client *Client) getHistoricalMetric(path string, from time.Time, to time.Time, aggregationAmount int) (map[string]interface{}, error) {
+	parameters := url.Values{}
+	parameters.Add("from", from.Format(time.RFC3339Nano))
+	parameters.Add("to", to.Format(time.RFC3339Nano))
+	parameters.Add("aggregationAmount", strconv.Itoa(aggregationAmount))
+
+	result, err := client.get(path + "?" + parameters.Encode())
+	if err != nil {
+		return nil, err
+	}
+	jsonMap, ok := result.(map[string]interface{})
+	if ok == false {
+		return nil, errors.New("Fail to parse the historical metric from " + path)
+	}
+	return jsonMap, nil
+}
@@ -0,0 +1,59 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type Region struct {
+	Name           string
+	LocationTagged bool
+	ZoneSlice      []Zone
+}
+
+type Zone struct {
+	Name           string
+	LocationTagged bool
+	NodeSlice      []Node
+}
+
+type Node struct {
+	Name     string
+	Address  string
+	Capacity Capacity
+}
+
+type Capacity struct {
+	Cpu    string
+	Memory string
+}
+
+func (client *Client) GetRegionSlice() ([]Region, error) {
+	regionSlice := make([]Region, 0)
+	err := client.getWithStructureCached("/api/v1/nodes/topology", &regionSlice)
+	return regionSlice, err
+}
+
+// GetLocationTaggedRegionSlice returns the regions which are tagged to be used as the location of deployment
+func (client *Client) GetLocationTaggedRegionSlice() ([]Region, error) {
+	regionSlice, err := client.GetRegionSlice()
+	if err != nil {
+		return nil, err
+	}
+	filteredRegionSlice := make([]Region, 0)
+	for _, region := range regionSlice {
+		if region.LocationTagged {
+			filteredRegionSlice = append(filteredRegionSlice, region)
+		}
+	}
+	return filteredRegionSlice, nil
+}
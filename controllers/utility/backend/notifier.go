@@ -0,0 +1,115 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"time"
+)
+
+type ReplicationControllerNotifier struct {
+	Check                 bool
+	CoolDownDuration      time.Duration
+	RemainingCoolDown     time.Duration
+	KubeApiServerEndPoint string
+	KubeApiServerToken    string
+	Namespace             string
+	Kind                  string
+	Name                  string
+	NotifierSlice         []Notifier
+	IndicatorSlice        []Indicator
+}
+
+type Notifier struct {
+	Kind string
+	Data string
+}
+
+type NotifierSMSNexmo struct {
+	Destination         string
+	Sender              string
+	ReceiverNumberSlice []string
+}
+
+type NotifierEmail struct {
+	Destination          string
+	ReceiverAccountSlice []string
+}
+
+type EmailServerSMTP struct {
+	Name     string
+	Account  string
+	Password string
+	Host     string
+	Port     int
+}
+
+type SMSNexmo struct {
+	Name      string
+	Url       string
+	APIKey    string
+	APISecret string
+}
+
+func (client *Client) GetNotifierSlice() ([]ReplicationControllerNotifier, error) {
+	replicationControllerNotifierSlice := make([]ReplicationControllerNotifier, 0)
+	err := client.getWithStructure("/api/v1/notifiers/", &replicationControllerNotifierSlice)
+	return replicationControllerNotifierSlice, err
+}
+
+func (client *Client) GetNotifier(namespace string, kind string, name string) (*ReplicationControllerNotifier, error) {
+	replicationControllerNotifier := &ReplicationControllerNotifier{}
+	err := client.getWithStructure("/api/v1/notifiers/"+namespace+"/"+kind+"/"+name, replicationControllerNotifier)
+	if err != nil {
+		return nil, err
+	}
+	return replicationControllerNotifier, nil
+}
+
+// UpdateNotifier creates the notifier if it doesn't exist
+func (client *Client) UpdateNotifier(replicationControllerNotifier ReplicationControllerNotifier) error {
+	return client.putWithStructure("/api/v1/notifiers/", replicationControllerNotifier, nil)
+}
+
+func (client *Client) DeleteNotifier(namespace string, kind string, name string) error {
+	return client.delete("/api/v1/notifiers/" + namespace + "/" + kind + "/" + name)
+}
+
+func (client *Client) GetEmailServerSMTPSlice() ([]EmailServerSMTP, error) {
+	emailServerSMTPSlice := make([]EmailServerSMTP, 0)
+	err := client.getWithStructure("/api/v1/notifiers/emailserversmtp/", &emailServerSMTPSlice)
+	return emailServerSMTPSlice, err
+}
+
+func (client *Client) CreateEmailServerSMTP(emailServerSMTP EmailServerSMTP) error {
+	return client.postWithStructure("/api/v1/notifiers/emailserversmtp/", emailServerSMTP, nil)
+}
+
+func (client *Client) DeleteEmailServerSMTP(name string) error {
+	return client.delete("/api/v1/notifiers/emailserversmtp/" + name)
+}
+
+func (client *Client) GetSMSNexmoSlice() ([]SMSNexmo, error) {
+	smsNexmoSlice := make([]SMSNexmo, 0)
+	err := client.getWithStructure("/api/v1/notifiers/smsnexmo/", &smsNexmoSlice)
+	return smsNexmoSlice, err
+}
+
+func (client *Client) CreateSMSNexmo(smsNexmo SMSNexmo) error {
+	return client.postWithStructure("/api/v1/notifiers/smsnexmo/", smsNexmo, nil)
+}
+
+func (client *Client) DeleteSMSNexmo(name string) error {
+	return client.delete("/api/v1/notifiers/smsnexmo/" + name)
+}
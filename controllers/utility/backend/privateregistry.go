@@ -0,0 +1,68 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type PrivateRegistry struct {
+	Name string
+	Host string
+	Port int
+}
+
+func (client *Client) GetPrivateRegistrySlice() ([]PrivateRegistry, error) {
+	privateRegistrySlice := make([]PrivateRegistry, 0)
+	err := client.getWithStructureCached("/api/v1/privateregistries/servers/", &privateRegistrySlice)
+	return privateRegistrySlice, err
+}
+
+func (client *Client) GetPrivateRegistry(serverName string) (*PrivateRegistry, error) {
+	privateRegistry := &PrivateRegistry{}
+	err := client.getWithStructure("/api/v1/privateregistries/servers/"+serverName, privateRegistry)
+	if err != nil {
+		return nil, err
+	}
+	return privateRegistry, nil
+}
+
+func (client *Client) CreatePrivateRegistry(privateRegistry PrivateRegistry) error {
+	return client.postWithStructure("/api/v1/privateregistries/servers/", privateRegistry, nil)
+}
+
+func (client *Client) UpdatePrivateRegistry(privateRegistry PrivateRegistry) error {
+	return client.putWithStructure("/api/v1/privateregistries/servers/"+privateRegistry.Name, privateRegistry, nil)
+}
+
+func (client *Client) DeletePrivateRegistry(serverName string) error {
+	return client.delete("/api/v1/privateregistries/servers/" + serverName)
+}
+
+func (client *Client) GetPrivateRegistryRepositoryNameSlice(serverName string) ([]string, error) {
+	nameSlice := make([]string, 0)
+	err := client.getWithStructure("/api/v1/privateregistries/servers/"+serverName+"/repositories/", &nameSlice)
+	return nameSlice, err
+}
+
+func (client *Client) DeletePrivateRegistryRepository(serverName string, repositoryName string) error {
+	return client.delete("/api/v1/privateregistries/servers/" + serverName + "/repositories/" + repositoryName)
+}
+
+func (client *Client) GetPrivateRegistryImageTagSlice(serverName string, repositoryName string) ([]string, error) {
+	tagSlice := make([]string, 0)
+	err := client.getWithStructure("/api/v1/privateregistries/servers/"+serverName+"/repositories/"+repositoryName+"/tags/", &tagSlice)
+	return tagSlice, err
+}
+
+func (client *Client) DeletePrivateRegistryImage(serverName string, repositoryName string, tag string) error {
+	return client.delete("/api/v1/privateregistries/servers/" + serverName + "/repositories/" + repositoryName + "/tags/" + tag)
+}
@@ -0,0 +1,132 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type ReplicationController struct {
+	Name           string
+	ReplicaAmount  int
+	Selector       ReplicationControllerSelector
+	Label          ReplicationControllerLabel
+	ContainerSlice []ReplicationControllerContainer
+}
+
+type ReplicationControllerSelector struct {
+	Name    string
+	Version string
+}
+
+type ReplicationControllerLabel struct {
+	Name string
+}
+
+type ReplicationControllerContainer struct {
+	Name             string
+	Image            string
+	PortSlice        []ReplicationControllerContainerPort
+	EnvironmentSlice []ReplicationControllerContainerEnvironment
+}
+
+type ReplicationControllerContainerPort struct {
+	Name          string
+	ContainerPort int
+}
+
+type ReplicationControllerContainerEnvironment struct {
+	Name  string
+	Value string
+}
+
+type ReplicationControllerAndRelatedPod struct {
+	Name               string
+	Namespace          string
+	ReplicaAmount      int
+	AliveReplicaAmount int
+	Selector           map[string]string
+	Label              map[string]string
+	PodSlice           []Pod
+}
+
+type Pod struct {
+	Name           string
+	Namespace      string
+	HostIP         string
+	PodIP          string
+	Phase          string
+	Age            string
+	ContainerSlice []PodContainer
+}
+
+type PodContainer struct {
+	Name         string
+	Image        string
+	ContainerID  string
+	RestartCount int
+	Ready        bool
+	PortSlice    []PodContainerPort
+}
+
+type PodContainerPort struct {
+	Name          string
+	ContainerPort int
+	Protocol      string
+}
+
+func (client *Client) GetReplicationControllerAndRelatedPodSlice(namespace string) ([]ReplicationControllerAndRelatedPod, error) {
+	replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
+	err := client.getWithStructure("/api/v1/replicationcontrollers/"+namespace, &replicationControllerAndRelatedPodSlice)
+	return replicationControllerAndRelatedPodSlice, err
+}
+
+func (client *Client) GetReplicationController(namespace string, name string) (*ReplicationController, error) {
+	replicationController := &ReplicationController{}
+	err := client.getWithStructure("/api/v1/replicationcontrollers/"+namespace+"/"+name, replicationController)
+	if err != nil {
+		return nil, err
+	}
+	return replicationController, nil
+}
+
+func (client *Client) CreateReplicationController(namespace string, replicationController ReplicationController) error {
+	return client.postWithStructure("/api/v1/replicationcontrollers/"+namespace, replicationController, nil)
+}
+
+// UpdateReplicationControllerWithJson replaces the replication controller with the kubernetes json
+func (client *Client) UpdateReplicationControllerWithJson(namespace string, name string, replicationControllerJsonMap map[string]interface{}) error {
+	return client.putWithStructure("/api/v1/replicationcontrollers/json/"+namespace+"/"+name, replicationControllerJsonMap, nil)
+}
+
+func (client *Client) ResizeReplicationController(namespace string, name string, size int) error {
+	putBodyJsonMap := make(map[string]interface{})
+	putBodyJsonMap["Size"] = size
+	return client.put("/api/v1/replicationcontrollers/size/"+namespace+"/"+name, putBodyJsonMap, true)
+}
+
+func (client *Client) DeleteReplicationController(namespace string, name string) error {
+	return client.delete("/api/v1/replicationcontrollers/" + namespace + "/" + name)
+}
+
+func (client *Client) DeletePod(namespace string, name string) error {
+	return client.delete("/api/v1/pods/" + namespace + "/" + name)
+}
+
+// GetPodLog returns the log of each container in the pod keyed by the container name
+func (client *Client) GetPodLog(namespace string, name string) (map[string]interface{}, error) {
+	result, err := client.get("/api/v1/pods/" + namespace + "/" + name + "/logs")
+	if err != nil {
+		return nil, err
+	}
+	jsonMap, _ := result.(map[string]interface{})
+	return jsonMap, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type Service struct {
+	Name            string
+	Namespace       string
+	PortSlice       []ServicePort
+	Selector        map[string]interface{}
+	ClusterIP       string
+	LabelMap        map[string]interface{}
+	SessionAffinity string
+}
+
+type ServicePort struct {
+	Name       string
+	Protocol   string
+	Port       int
+	TargetPort string
+	NodePort   int
+}
+
+func (client *Client) GetServiceSlice(namespace string) ([]Service, error) {
+	serviceSlice := make([]Service, 0)
+	err := client.getWithStructure("/api/v1/services/"+namespace, &serviceSlice)
+	return serviceSlice, err
+}
+
+func (client *Client) CreateService(namespace string, service Service) error {
+	return client.postWithStructure("/api/v1/services/"+namespace, service, nil)
+}
+
+// UpdateServiceWithJson replaces the service with the kubernetes json
+func (client *Client) UpdateServiceWithJson(namespace string, name string, serviceJsonMap map[string]interface{}) error {
+	return client.putWithStructure("/api/v1/services/json/"+namespace+"/"+name, serviceJsonMap, nil)
+}
+
+func (client *Client) DeleteService(namespace string, name string) error {
+	return client.delete("/api/v1/services/" + namespace + "/" + name)
+}
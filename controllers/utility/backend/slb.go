@@ -0,0 +1,54 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type SLBDaemon struct {
+	Name          string
+	EndPointSlice []string
+	NodeHostSlice []string
+	Description   string
+}
+
+func (client *Client) GetSLBDaemonSlice() ([]SLBDaemon, error) {
+	slbDaemonSlice := make([]SLBDaemon, 0)
+	err := client.getWithStructure("/api/v1/slbs/daemons/", &slbDaemonSlice)
+	return slbDaemonSlice, err
+}
+
+func (client *Client) GetSLBDaemon(name string) (*SLBDaemon, error) {
+	slbDaemon := &SLBDaemon{}
+	err := client.getWithStructure("/api/v1/slbs/daemons/"+name, slbDaemon)
+	if err != nil {
+		return nil, err
+	}
+	return slbDaemon, nil
+}
+
+func (client *Client) CreateSLBDaemon(slbDaemon SLBDaemon) error {
+	return client.postWithStructure("/api/v1/slbs/daemons/", slbDaemon, nil)
+}
+
+func (client *Client) UpdateSLBDaemon(slbDaemon SLBDaemon) error {
+	return client.putWithStructure("/api/v1/slbs/daemons/"+slbDaemon.Name, slbDaemon, nil)
+}
+
+func (client *Client) DeleteSLBDaemon(name string) error {
+	return client.delete("/api/v1/slbs/daemons/" + name)
+}
+
+// ConfigureSLBDaemon pushes the current service configuration to the daemon
+func (client *Client) ConfigureSLBDaemon(name string) error {
+	return client.put("/api/v1/slbs/daemons/"+name+"/configure", make(map[string]interface{}), true)
+}
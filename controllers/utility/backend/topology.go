@@ -0,0 +1,89 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"time"
+)
+
+type Topology struct {
+	Name            string
+	SourceNamespace string
+	CreatedUser     string
+	CreatedDate     time.Time
+	Description     string
+	LaunchSlice     []Launch
+}
+
+// Launch is either an application or a cluster application launched in the order
+type Launch struct {
+	Order                    int
+	LaunchApplication        *DeployCreateInput
+	LaunchClusterApplication *LaunchClusterApplication
+}
+
+type LaunchClusterApplication struct {
+	Name                              string
+	Size                              int
+	EnvironmentSlice                  []ClusterEnvironment
+	ReplicationControllerExtraJsonMap map[string]interface{}
+}
+
+func (client *Client) GetTopologySlice() ([]Topology, error) {
+	topologySlice := make([]Topology, 0)
+	err := client.getWithStructure("/api/v1/topology/", &topologySlice)
+	return topologySlice, err
+}
+
+func (client *Client) GetTopology(name string) (*Topology, error) {
+	topology := Topology{}
+	err := client.getWithStructure("/api/v1/topology/"+name, &topology)
+	if err != nil {
+		return nil, err
+	}
+	return &topology, nil
+}
+
+func (client *Client) CreateTopology(topology Topology) error {
+	return client.postWithStructure("/api/v1/topology/", topology, nil)
+}
+
+func (client *Client) DeleteTopology(name string) error {
+	return client.delete("/api/v1/topology/" + name)
+}
+
+// Launch launches the applications and the cluster applications in the slice order into the namespace
+func (client *Client) Launch(namespace string, launchSlice []Launch) error {
+	for _, launch := range launchSlice {
+		if launch.LaunchApplication != nil {
+			err := client.CreateDeploy(namespace, *launch.LaunchApplication)
+			if err != nil {
+				return err
+			}
+		}
+		if launch.LaunchClusterApplication != nil {
+			clusterLaunch := ClusterLaunch{
+				launch.LaunchClusterApplication.Size,
+				launch.LaunchClusterApplication.EnvironmentSlice,
+				launch.LaunchClusterApplication.ReplicationControllerExtraJsonMap,
+			}
+			err := client.LaunchClusterApplication(namespace, launch.LaunchClusterApplication.Name, clusterLaunch)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+type GithubPost struct {
+	User             string
+	ImageInformation string
+	Signature        string
+	Payload          string
+}
+
+func (client *Client) PostGithubWebhook(githubPost GithubPost) error {
+	return client.postWithStructure("/api/v1/webhooks/github", githubPost, nil)
+}
@@ -0,0 +1,462 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bluegreenpromotion promotes a version through the blue green deployment automatically. The version is
+// deployed to the idle namespace, the health gates probe it and wait for the soak period, and then the blue green
+// deployment selects the idle namespace. The namespace selected before is kept running for the instant revert.
+//
+// The promotions are kept in the session store and driven by the runner of the replica holding the lease so
+// the steps are streamed to the GUI and continued by another replica after a restart.
+package bluegreenpromotion
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StateRunning   = "running"
+	StateSucceeded = "succeeded"
+	StateFailed    = "failed"
+	StateReverted  = "reverted"
+
+	StepDeploy = "deploy"
+	StepReady  = "ready"
+	StepProbe  = "probe"
+	StepSoak   = "soak"
+	StepSwitch = "switch"
+
+	ProbeKindHTTP = "http"
+	ProbeKindTCP  = "tcp"
+
+	ActionAbort = "abort"
+
+	// The oldest lines are dropped so the promotion stays small in the session store
+	logLineMaximum = 500
+
+	keyPrefix        = "bluegreenpromotion/"
+	requestKeyPrefix = "bluegreenpromotionrequest/"
+	leaseKeyPrefix   = "bluegreenpromotionlease/"
+)
+
+var stepSlice = []string{StepDeploy, StepReady, StepProbe, StepSoak, StepSwitch}
+
+// Probe checks the idle namespace over HTTP or TCP
+type Probe struct {
+	Kind string
+	// Container port probed on every pod of the idle namespace. It is not used if Address is set.
+	Port int
+	// host:port probed instead of the pods such as the node port of the idle namespace
+	Address string
+	// Path of the HTTP probe. The status 2xx and 3xx are healthy.
+	Path string
+}
+
+// Gate is passed before the idle namespace is selected
+type Gate struct {
+	ProbeSlice           []Probe
+	ProbeTimeoutInSecond int
+	// Consecutive rounds of all the probes succeeding before the soak
+	SuccessThreshold int
+	// Failed rounds of the probes in the probe and the soak steps failing the promotion
+	FailureThreshold int
+	// The probes keep running during the soak
+	SoakInSecond         int
+	ReadyTimeoutInSecond int
+}
+
+type LogLine struct {
+	Sequence int
+	Time     time.Time
+	Step     string
+	Text     string
+}
+
+type Promotion struct {
+	Cluster              string
+	ImageInformationName string
+	Version              string
+	// Selected before the promotion and kept running for the revert
+	ActiveNamespace string
+	// Deployed, probed and then selected
+	IdleNamespace       string
+	IdlePreviousVersion string
+	Gate                Gate
+	Step                string
+	State               string
+	Message             string
+	SuccessCount        int
+	FailureCount        int
+	UserName            string
+	TokenHeaderMap      map[string]string
+	CreatedTime         time.Time
+	StepStartedTime     time.Time
+	UpdatedTime         time.Time
+	LogSlice            []LogLine
+}
+
+// request is the action of the user taken by the runner since only the runner changes the running promotion
+type request struct {
+	Action         string
+	UserName       string
+	TokenHeaderMap map[string]string
+}
+
+type ByPromotion []Promotion
+
+func (b ByPromotion) Len() int      { return len(b) }
+func (b ByPromotion) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b ByPromotion) Less(i, j int) bool {
+	return b[i].ImageInformationName < b[j].ImageInformationName
+}
+
+// IsActive is true until the promotion succeeds or fails
+func (promotion *Promotion) IsActive() bool {
+	return promotion.State == StateRunning
+}
+
+// GetStepText shows the steps with the current one in brackets such as deploy ready [probe] soak switch
+func (promotion *Promotion) GetStepText() string {
+	textSlice := make([]string, 0)
+	for _, step := range stepSlice {
+		if step == promotion.Step && promotion.IsActive() {
+			textSlice = append(textSlice, "["+step+"]")
+		} else {
+			textSlice = append(textSlice, step)
+		}
+	}
+	return strings.Join(textSlice, " ")
+}
+
+// GetLastSequence returns the sequence of the last log line or 0 without any line
+func (promotion *Promotion) GetLastSequence() int {
+	if len(promotion.LogSlice) == 0 {
+		return 0
+	}
+	return promotion.LogSlice[len(promotion.LogSlice)-1].Sequence
+}
+
+func (promotion *Promotion) log(text string) {
+	beego.Info("The blue green promotion of " + promotion.ImageInformationName + " version " + promotion.Version + " to namespace " + promotion.IdleNamespace + ". " + text)
+	promotion.LogSlice = append(promotion.LogSlice, LogLine{promotion.GetLastSequence() + 1, time.Now(), promotion.Step, text})
+	if len(promotion.LogSlice) > logLineMaximum {
+		promotion.LogSlice = promotion.LogSlice[len(promotion.LogSlice)-logLineMaximum:]
+	}
+	promotion.Message = text
+}
+
+func (probe Probe) String() string {
+	target := probe.Address
+	if target == "" {
+		target = ":" + strconv.Itoa(probe.Port)
+	}
+	if probe.Kind == ProbeKindHTTP {
+		return probe.Kind + " " + target + " " + probe.Path
+	}
+	return probe.Kind + " " + target
+}
+
+// ParseProbeSlice parses one probe in each line such as http :80 /health, tcp :5432 or http 10.0.0.11:31080 /health.
+// The port alone probes the pods and host:port probes the address.
+func ParseProbeSlice(text string) ([]Probe, error) {
+	probeSlice := make([]Probe, 0)
+	for _, line := range strings.Split(text, "\n") {
+		fieldSlice := strings.Fields(line)
+		if len(fieldSlice) == 0 {
+			continue
+		}
+		probe := Probe{Kind: strings.ToLower(fieldSlice[0])}
+		if probe.Kind != ProbeKindHTTP && probe.Kind != ProbeKindTCP {
+			return nil, errors.New("Probe " + line + " must start with http or tcp")
+		}
+		if len(fieldSlice) < 2 || (probe.Kind == ProbeKindTCP && len(fieldSlice) > 2) || len(fieldSlice) > 3 {
+			return nil, errors.New("Probe " + line + " must be http port path or tcp port")
+		}
+		host, port, err := net.SplitHostPort(fieldSlice[1])
+		if err != nil {
+			// The port only
+			host = ""
+			port = fieldSlice[1]
+		}
+		portNumber, err := strconv.Atoi(port)
+		if err != nil || portNumber <= 0 || portNumber > 65535 {
+			return nil, errors.New("Probe " + line + " has the invalid port " + port)
+		}
+		if host != "" {
+			probe.Address = fieldSlice[1]
+		} else {
+			probe.Port = portNumber
+		}
+		if probe.Kind == ProbeKindHTTP {
+			probe.Path = "/"
+			if len(fieldSlice) == 3 {
+				probe.Path = fieldSlice[2]
+			}
+			if strings.HasPrefix(probe.Path, "/") == false {
+				return nil, errors.New("Probe " + line + " must have the path starting with /")
+			}
+		}
+		probeSlice = append(probeSlice, probe)
+	}
+	if len(probeSlice) == 0 {
+		return nil, errors.New("At least one probe is required for the health gate")
+	}
+	return probeSlice, nil
+}
+
+// GetProbeText formats the probes in the format of ParseProbeSlice
+func GetProbeText(probeSlice []Probe) string {
+	lineSlice := make([]string, 0)
+	for _, probe := range probeSlice {
+		lineSlice = append(lineSlice, probe.String())
+	}
+	return strings.Join(lineSlice, "\n")
+}
+
+func getKeySuffix(cluster string, name string) string {
+	return cluster + "/" + name
+}
+
+func save(promotion *Promotion) error {
+	promotion.UpdatedTime = time.Now()
+	byteSlice, err := json.Marshal(promotion)
+	if err != nil {
+		return err
+	}
+	return sessionstore.GetStore().Set(keyPrefix+getKeySuffix(promotion.Cluster, promotion.ImageInformationName), byteSlice, 0)
+}
+
+func getPromotionByKey(key string) (*Promotion, error) {
+	byteSlice, err := sessionstore.GetStore().Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if byteSlice == nil {
+		return nil, nil
+	}
+	promotion := &Promotion{}
+	if err := json.Unmarshal(byteSlice, promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+// GetPromotion returns the last promotion of the blue green deployment or nil if it is never promoted
+func GetPromotion(cluster string, name string) (*Promotion, error) {
+	return getPromotionByKey(keyPrefix + getKeySuffix(cluster, name))
+}
+
+// GetPromotionSlice returns the last promotions of the cluster. The empty cluster returns the promotions of all clusters for the runner.
+func GetPromotionSlice(cluster string) ([]Promotion, error) {
+	prefix := keyPrefix
+	if cluster != "" {
+		prefix += cluster + "/"
+	}
+	keySlice, err := sessionstore.GetStore().List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	promotionSlice := make([]Promotion, 0)
+	for _, key := range keySlice {
+		promotion, err := getPromotionByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if promotion != nil {
+			promotionSlice = append(promotionSlice, *promotion)
+		}
+	}
+	sort.Sort(ByPromotion(promotionSlice))
+	return promotionSlice, nil
+}
+
+// StartInput is the promotion from the user
+type StartInput struct {
+	ImageInformationName string
+	Version              string
+	IdleNamespace        string
+	Gate                 Gate
+}
+
+// Start validates the promotion and leaves the steps to the runner
+func Start(ctx *context.Context, startInput StartInput) error {
+	cluster := backend.GetClusterName(ctx)
+	name := startInput.ImageInformationName
+	gate := startInput.Gate
+
+	// The runner holding the lease continues the promotion after the restart of the GUI
+	if err := sessionstore.RequireShared("The blue green promotion"); err != nil {
+		return err
+	}
+
+	if len(gate.ProbeSlice) == 0 {
+		return errors.New("At least one probe is required for the health gate")
+	}
+	if gate.ProbeTimeoutInSecond <= 0 || gate.SuccessThreshold <= 0 || gate.FailureThreshold <= 0 || gate.ReadyTimeoutInSecond <= 0 {
+		return errors.New("Probe timeout, success threshold, failure threshold and ready timeout must be positive")
+	}
+	if gate.SoakInSecond < 0 {
+		return errors.New("Soak must not be negative")
+	}
+
+	existingPromotion, err := GetPromotion(cluster, name)
+	if err != nil {
+		return err
+	}
+	if existingPromotion != nil && existingPromotion.IsActive() {
+		return errors.New("Blue green deployment " + name + " is promoting version " + existingPromotion.Version + " to namespace " + existingPromotion.IdleNamespace)
+	}
+
+	client := backend.NewCloudoneClient(ctx)
+	deployBlueGreen, err := client.GetDeployBlueGreen(name)
+	if err != nil {
+		return err
+	}
+	if deployBlueGreen == nil {
+		return errors.New("Blue green deployment " + name + " doesn't exist")
+	}
+	if deployBlueGreen.Namespace == startInput.IdleNamespace {
+		return errors.New("Namespace " + startInput.IdleNamespace + " is selected so it is not idle")
+	}
+
+	namespaceSlice, err := client.GetDeployBlueGreenDeployableNamespaceSlice(name)
+	if err != nil {
+		return err
+	}
+	deployable := false
+	for _, namespace := range namespaceSlice {
+		if namespace == startInput.IdleNamespace {
+			deployable = true
+		}
+	}
+	if deployable == false {
+		return errors.New(name + " is not deployed in namespace " + startInput.IdleNamespace)
+	}
+
+	imageRecordSlice, err := client.GetImageRecordSlice(name)
+	if err != nil {
+		return err
+	}
+	imageRecordExisting := false
+	for _, imageRecord := range imageRecordSlice {
+		if imageRecord.Version == startInput.Version && imageRecord.Failure == false {
+			imageRecordExisting = true
+		}
+	}
+	if imageRecordExisting == false {
+		return errors.New("Version " + startInput.Version + " of " + name + " doesn't exist or failed to build")
+	}
+
+	userName, _ := ctx.Input.Session("username").(string)
+	tokenHeaderMap, _ := ctx.Input.Session("tokenHeaderMap").(map[string]string)
+	promotion := &Promotion{
+		Cluster:              cluster,
+		ImageInformationName: name,
+		Version:              startInput.Version,
+		ActiveNamespace:      deployBlueGreen.Namespace,
+		IdleNamespace:        startInput.IdleNamespace,
+		Gate:                 gate,
+		Step:                 StepDeploy,
+		State:                StateRunning,
+		UserName:             userName,
+		TokenHeaderMap:       tokenHeaderMap,
+		CreatedTime:          time.Now(),
+		StepStartedTime:      time.Now(),
+		LogSlice:             make([]LogLine, 0),
+	}
+	promotion.log("Started by " + userName + ". Namespace " + promotion.ActiveNamespace + " is selected and namespace " + promotion.IdleNamespace + " is idle")
+	if err := save(promotion); err != nil {
+		return err
+	}
+	sendAuditLog(promotion, userName, tokenHeaderMap, "start")
+	return nil
+}
+
+// Request asks the runner to take the action on the running promotion
+func Request(ctx *context.Context, name string, action string) error {
+	cluster := backend.GetClusterName(ctx)
+	promotion, err := GetPromotion(cluster, name)
+	if err != nil {
+		return err
+	}
+	if promotion == nil || promotion.IsActive() == false {
+		return errors.New("Blue green deployment " + name + " doesn't have the promotion in progress")
+	}
+
+	userName, _ := ctx.Input.Session("username").(string)
+	tokenHeaderMap, _ := ctx.Input.Session("tokenHeaderMap").(map[string]string)
+	byteSlice, err := json.Marshal(request{action, userName, tokenHeaderMap})
+	if err != nil {
+		return err
+	}
+	return sessionstore.GetStore().Set(requestKeyPrefix+getKeySuffix(cluster, name), byteSlice, 0)
+}
+
+// GetPendingAction returns the action requested but not taken by the runner yet
+func GetPendingAction(promotion *Promotion) string {
+	byteSlice, _ := sessionstore.GetStore().Get(requestKeyPrefix + getKeySuffix(promotion.Cluster, promotion.ImageInformationName))
+	request := request{}
+	if byteSlice == nil || json.Unmarshal(byteSlice, &request) != nil {
+		return ""
+	}
+	return request.Action
+}
+
+// Revert selects the namespace kept running by the succeeded promotion again
+func Revert(ctx *context.Context, name string) error {
+	cluster := backend.GetClusterName(ctx)
+	promotion, err := GetPromotion(cluster, name)
+	if err != nil {
+		return err
+	}
+	if promotion == nil || promotion.State != StateSucceeded {
+		return errors.New("Blue green deployment " + name + " doesn't have the succeeded promotion to revert")
+	}
+
+	client := backend.NewCloudoneClient(ctx)
+	deployBlueGreen, err := client.GetDeployBlueGreen(name)
+	if err != nil {
+		return err
+	}
+	if deployBlueGreen == nil {
+		return errors.New("Blue green deployment " + name + " doesn't exist")
+	}
+	if deployBlueGreen.Namespace != promotion.IdleNamespace {
+		return errors.New("Namespace " + deployBlueGreen.Namespace + " is selected after the promotion so it is not reverted")
+	}
+
+	deployBlueGreen.Namespace = promotion.ActiveNamespace
+	if err := client.UpdateDeployBlueGreen(*deployBlueGreen); err != nil {
+		return err
+	}
+
+	userName, _ := ctx.Input.Session("username").(string)
+	tokenHeaderMap, _ := ctx.Input.Session("tokenHeaderMap").(map[string]string)
+	promotion.State = StateReverted
+	promotion.log("Reverted by " + userName + ". Namespace " + promotion.ActiveNamespace + " is selected again")
+	if err := save(promotion); err != nil {
+		return err
+	}
+	sendAuditLog(promotion, userName, tokenHeaderMap, "revert")
+	return nil
+}
@@ -0,0 +1,421 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bluegreenpromotion
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBlueGreenPromotionCheckIntervalInSecond = 5
+	// The steps are recorded in the audit log as the requests to this path followed by the step
+	auditLogPathPrefix = "/gui/deploy/deploybluegreen/promotion/"
+)
+
+var runnerLock = sync.Mutex{}
+var runnerStarted = false
+var stopChannel = make(chan struct{})
+var stoppedChannel = make(chan struct{})
+
+func getCheckInterval() time.Duration {
+	return time.Duration(beego.AppConfig.DefaultInt("blueGreenPromotionCheckIntervalInSecond", defaultBlueGreenPromotionCheckIntervalInSecond)) * time.Second
+}
+
+// StartRunner checks the promotions in the interval until StopRunner
+func StartRunner() {
+	runnerLock.Lock()
+	defer runnerLock.Unlock()
+
+	if runnerStarted {
+		return
+	}
+	runnerStarted = true
+
+	stop := stopChannel
+	stopped := stoppedChannel
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(getCheckInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				Tick()
+			}
+		}
+	}()
+}
+
+// StopRunner waits for the promotion being processed. The lease expires so another replica continues the promotions.
+func StopRunner() {
+	runnerLock.Lock()
+	defer runnerLock.Unlock()
+
+	if runnerStarted == false {
+		return
+	}
+	close(stopChannel)
+	<-stoppedChannel
+
+	runnerStarted = false
+	stopChannel = make(chan struct{})
+	stoppedChannel = make(chan struct{})
+}
+
+// Tick advances all the running promotions by one step or one round of the probes
+func Tick() {
+	promotionSlice, err := GetPromotionSlice("")
+	if err != nil {
+		beego.Error("Fail to get the blue green promotions with error " + err.Error())
+		return
+	}
+	for i := range promotionSlice {
+		if promotionSlice[i].IsActive() && acquireLease(&promotionSlice[i]) {
+			process(&promotionSlice[i])
+		}
+	}
+}
+
+// acquireLease makes sure only one replica changes the promotion. The lease is renewed in each tick.
+func acquireLease(promotion *Promotion) bool {
+	acquired, err := sessionstore.AcquireLease(leaseKeyPrefix+getKeySuffix(promotion.Cluster, promotion.ImageInformationName), 3*getCheckInterval())
+	if err != nil {
+		beego.Error("Fail to acquire the blue green promotion lease with error " + err.Error())
+		return false
+	}
+	return acquired
+}
+
+func takeRequest(promotion *Promotion) *request {
+	byteSlice, err := sessionstore.GetStore().Take(requestKeyPrefix + getKeySuffix(promotion.Cluster, promotion.ImageInformationName))
+	if err != nil || byteSlice == nil {
+		return nil
+	}
+	request := &request{}
+	if err := json.Unmarshal(byteSlice, request); err != nil {
+		return nil
+	}
+	return request
+}
+
+func sendAuditLog(promotion *Promotion, userName string, tokenHeaderMap map[string]string, action string) {
+	identity.SendAuditLogForUser(promotion.Cluster, userName, tokenHeaderMap, "POST", auditLogPathPrefix+action, map[string][]string{
+		"imageInformation": []string{promotion.ImageInformationName},
+		"version":          []string{promotion.Version},
+		"activeNamespace":  []string{promotion.ActiveNamespace},
+		"idleNamespace":    []string{promotion.IdleNamespace},
+		"state":            []string{promotion.State},
+		"message":          []string{promotion.Message},
+	})
+}
+
+func process(promotion *Promotion) {
+	err := advance(promotion)
+	if identity.IsTokenInvalid(err) {
+		fail(promotion, "the user token is expired. Start the promotion again.")
+	} else if err != nil {
+		// Retried in the next tick. The same error is logged once.
+		text := "Fail to continue with error " + err.Error() + ". Retry in the next check."
+		if promotion.Message != text {
+			promotion.log(text)
+		}
+	}
+	if err := save(promotion); err != nil {
+		beego.Error("Fail to save the blue green promotion of " + promotion.ImageInformationName + " with error " + err.Error())
+	}
+}
+
+func getClient(promotion *Promotion) *backend.Client {
+	return backend.NewCloudoneClientWithTokenHeaderMap(promotion.TokenHeaderMap).WithCluster(promotion.Cluster)
+}
+
+func nextStep(promotion *Promotion, step string) {
+	promotion.Step = step
+	promotion.StepStartedTime = time.Now()
+	promotion.SuccessCount = 0
+	promotion.log("Step " + step + " is started")
+	sendAuditLog(promotion, promotion.UserName, promotion.TokenHeaderMap, step)
+}
+
+// fail keeps the selected namespace. The idle namespace keeps the version for the investigation.
+func fail(promotion *Promotion, reason string) {
+	promotion.State = StateFailed
+	promotion.log("Failed in step " + promotion.Step + " since " + reason + " Namespace " + promotion.ActiveNamespace + " is still selected")
+	sendAuditLog(promotion, promotion.UserName, promotion.TokenHeaderMap, "fail")
+}
+
+func advance(promotion *Promotion) error {
+	if request := takeRequest(promotion); request != nil && request.Action == ActionAbort {
+		promotion.State = StateFailed
+		promotion.log("Aborted by " + request.UserName + " in step " + promotion.Step + ". Namespace " + promotion.ActiveNamespace + " is still selected")
+		sendAuditLog(promotion, request.UserName, request.TokenHeaderMap, ActionAbort)
+		return nil
+	}
+
+	client := getClient(promotion)
+	switch promotion.Step {
+	case StepDeploy:
+		return deployStep(client, promotion)
+	case StepReady:
+		return readyStep(client, promotion)
+	case StepProbe:
+		return probeStep(client, promotion)
+	case StepSoak:
+		return soakStep(client, promotion)
+	case StepSwitch:
+		return switchStep(client, promotion)
+	default:
+		fail(promotion, "step "+promotion.Step+" is unknown.")
+		return nil
+	}
+}
+
+func getReplicationControllerName(promotion *Promotion) string {
+	return promotion.ImageInformationName + promotion.Version
+}
+
+func deployStep(client *backend.Client, promotion *Promotion) error {
+	deployInformationSlice, err := client.GetDeployInformationSlice(promotion.IdleNamespace)
+	if err != nil {
+		return err
+	}
+	var deployInformation *backend.DeployInformation
+	for i := range deployInformationSlice {
+		if deployInformationSlice[i].ImageInformationName == promotion.ImageInformationName {
+			deployInformation = &deployInformationSlice[i]
+		}
+	}
+	if deployInformation == nil {
+		fail(promotion, promotion.ImageInformationName+" is not deployed in namespace "+promotion.IdleNamespace+" any more.")
+		return nil
+	}
+
+	promotion.IdlePreviousVersion = deployInformation.CurrentVersion
+	if deployInformation.CurrentVersion == promotion.Version {
+		promotion.log("Version " + promotion.Version + " is already deployed in namespace " + promotion.IdleNamespace)
+	} else {
+		if err := deployrevision.RecordBaselineForUser(client, promotion.Cluster, promotion.UserName, promotion.IdleNamespace, promotion.ImageInformationName); err != nil {
+			beego.Error("Fail to record the revision of deploy " + promotion.ImageInformationName + " before the promotion with error " + err.Error())
+		}
+		err := client.UpdateDeploy(promotion.IdleNamespace, backend.DeployUpdateInput{
+			ImageInformationName: promotion.ImageInformationName,
+			Version:              promotion.Version,
+			Description:          deployInformation.Description,
+			EnvironmentSlice:     deployInformation.EnvironmentSlice,
+		})
+		if err != nil {
+			return err
+		}
+		if err := deployrevision.RecordForUser(client, promotion.Cluster, promotion.UserName, promotion.IdleNamespace, promotion.ImageInformationName, deployrevision.ActionUpdate); err != nil {
+			beego.Error("Fail to record the revision of deploy " + promotion.ImageInformationName + " in the promotion with error " + err.Error())
+		}
+		promotion.log("Version is updated from " + deployInformation.CurrentVersion + " to " + promotion.Version + " in namespace " + promotion.IdleNamespace)
+	}
+
+	nextStep(promotion, StepReady)
+	return nil
+}
+
+func getPodSlice(client *backend.Client, promotion *Promotion) ([]backend.Pod, int, error) {
+	replicationControllerAndRelatedPodSlice, err := client.GetReplicationControllerAndRelatedPodSlice(promotion.IdleNamespace)
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, replicationControllerAndRelatedPod := range replicationControllerAndRelatedPodSlice {
+		if replicationControllerAndRelatedPod.Name == getReplicationControllerName(promotion) {
+			return replicationControllerAndRelatedPod.PodSlice, replicationControllerAndRelatedPod.ReplicaAmount, nil
+		}
+	}
+	return make([]backend.Pod, 0), 0, nil
+}
+
+func readyStep(client *backend.Client, promotion *Promotion) error {
+	podSlice, replicaAmount, err := getPodSlice(client, promotion)
+	if err != nil {
+		return err
+	}
+	readyAmount := 0
+	for _, pod := range podSlice {
+		ready := pod.Phase == "Running"
+		for _, container := range pod.ContainerSlice {
+			if container.Ready == false {
+				ready = false
+			}
+		}
+		if ready {
+			readyAmount++
+		}
+	}
+
+	text := fmt.Sprintf("%d of %d pods are ready", readyAmount, replicaAmount)
+	if replicaAmount > 0 && readyAmount >= replicaAmount {
+		promotion.log(text)
+		nextStep(promotion, StepProbe)
+	} else if time.Since(promotion.StepStartedTime) > time.Duration(promotion.Gate.ReadyTimeoutInSecond)*time.Second {
+		fail(promotion, fmt.Sprintf("only %s after %d seconds.", text, promotion.Gate.ReadyTimeoutInSecond))
+	} else {
+		// Not logged in each check
+		promotion.Message = text
+	}
+	return nil
+}
+
+func check(probe Probe, address string, timeout time.Duration) error {
+	if probe.Kind == ProbeKindTCP {
+		conn, err := net.DialTimeout("tcp", address, timeout)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	}
+
+	// No keep alive so each round connects again like a new client
+	httpClient := &http.Client{
+		Timeout:   timeout,
+		Transport: &http.Transport{DisableKeepAlives: true},
+	}
+	response, err := httpClient.Get("http://" + address + probe.Path)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode >= 400 {
+		return errors.New("status " + strconv.Itoa(response.StatusCode))
+	}
+	return nil
+}
+
+// probeRound returns the failed probe. The error is returned if the pods can't be got.
+func probeRound(client *backend.Client, promotion *Promotion) (string, error) {
+	timeout := time.Duration(promotion.Gate.ProbeTimeoutInSecond) * time.Second
+	var podSlice []backend.Pod
+	for _, probe := range promotion.Gate.ProbeSlice {
+		addressSlice := make([]string, 0)
+		if probe.Address != "" {
+			addressSlice = append(addressSlice, probe.Address)
+		} else {
+			if podSlice == nil {
+				var err error
+				podSlice, _, err = getPodSlice(client, promotion)
+				if err != nil {
+					return "", err
+				}
+			}
+			for _, pod := range podSlice {
+				addressSlice = append(addressSlice, net.JoinHostPort(pod.PodIP, strconv.Itoa(probe.Port)))
+			}
+			if len(addressSlice) == 0 {
+				return "probe " + probe.String() + " has no pod to probe", nil
+			}
+		}
+		for _, address := range addressSlice {
+			if err := check(probe, address, timeout); err != nil {
+				return "probe " + probe.String() + " on " + address + " fails with " + err.Error(), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// countFailure returns true if the promotion fails
+func countFailure(promotion *Promotion, reason string) bool {
+	promotion.SuccessCount = 0
+	promotion.FailureCount++
+	promotion.log(fmt.Sprintf("Failed round %d of %d since %s", promotion.FailureCount, promotion.Gate.FailureThreshold, reason))
+	if promotion.FailureCount >= promotion.Gate.FailureThreshold {
+		fail(promotion, "the health gate fails "+strconv.Itoa(promotion.FailureCount)+" rounds.")
+		return true
+	}
+	return false
+}
+
+func probeStep(client *backend.Client, promotion *Promotion) error {
+	reason, err := probeRound(client, promotion)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		countFailure(promotion, reason)
+		return nil
+	}
+
+	promotion.SuccessCount++
+	promotion.log(fmt.Sprintf("All probes succeed in round %d of %d", promotion.SuccessCount, promotion.Gate.SuccessThreshold))
+	if promotion.SuccessCount >= promotion.Gate.SuccessThreshold {
+		nextStep(promotion, StepSoak)
+	}
+	return nil
+}
+
+func soakStep(client *backend.Client, promotion *Promotion) error {
+	reason, err := probeRound(client, promotion)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		countFailure(promotion, reason)
+		return nil
+	}
+
+	elapsed := time.Since(promotion.StepStartedTime)
+	soakDuration := time.Duration(promotion.Gate.SoakInSecond) * time.Second
+	if elapsed >= soakDuration {
+		promotion.log(fmt.Sprintf("Healthy for the soak of %d seconds", promotion.Gate.SoakInSecond))
+		nextStep(promotion, StepSwitch)
+	} else {
+		// Not logged in each check
+		promotion.Message = fmt.Sprintf("Soaking %d of %d seconds", int(elapsed.Seconds()), promotion.Gate.SoakInSecond)
+	}
+	return nil
+}
+
+func switchStep(client *backend.Client, promotion *Promotion) error {
+	deployBlueGreen, err := client.GetDeployBlueGreen(promotion.ImageInformationName)
+	if err != nil {
+		return err
+	}
+	if deployBlueGreen == nil {
+		fail(promotion, "blue green deployment "+promotion.ImageInformationName+" is deleted.")
+		return nil
+	}
+	if deployBlueGreen.Namespace != promotion.ActiveNamespace {
+		fail(promotion, "namespace "+deployBlueGreen.Namespace+" is selected by others during the promotion.")
+		return nil
+	}
+
+	deployBlueGreen.Namespace = promotion.IdleNamespace
+	if err := client.UpdateDeployBlueGreen(*deployBlueGreen); err != nil {
+		return err
+	}
+
+	promotion.State = StateSucceeded
+	promotion.log("Namespace " + promotion.IdleNamespace + " is selected. Namespace " + promotion.ActiveNamespace + " is kept running for the revert")
+	sendAuditLog(promotion, promotion.UserName, promotion.TokenHeaderMap, "succeed")
+	return nil
+}
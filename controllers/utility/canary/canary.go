@@ -0,0 +1,409 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package canary rolls out a new version of a deploy beside the current one. The canary replication controller
+// has the same name label so the service selecting the name shares the traffic by the ratio of the replicas.
+// The traffic is shifted in steps with a pause between them and the canary is rolled back automatically
+// if its container metrics or kubernetes events cross the thresholds. The last step promotes the version.
+//
+// The canaries are kept in the session store and driven by the runner of the replica holding the lease.
+package canary
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StateRunning = "running"
+	StatePaused  = "paused"
+	// The runner can't continue such as the token is expired. It continues after resume.
+	StateHalted     = "halted"
+	StatePromoted   = "promoted"
+	StateRolledBack = "rolledback"
+
+	ActionPause   = "pause"
+	ActionResume  = "resume"
+	ActionAbort   = "abort"
+	ActionPromote = "promote"
+
+	// The canary has its own version label so its pods are not counted by the replication controller of the promoted version
+	canaryVersionSuffix = "-canary"
+
+	keyPrefix        = "canary/"
+	requestKeyPrefix = "canaryrequest/"
+	leaseKeyPrefix   = "canarylease/"
+)
+
+// Threshold halts the canary and rolls it back when any of the canary containers is above it
+type Threshold struct {
+	// CPU time used between the last two samples like the container monitor. 0 disables it.
+	CpuUsageMaximumInMillisecond int64
+	// 0 disables it
+	MemoryUsageMaximumInMB int64
+	// Total restarts of the canary containers. Negative disables it.
+	RestartMaximum int
+	// Total warning events of the canary pods such as BackOff and FailedSync. Negative disables it.
+	EventMaximum int
+}
+
+type Canary struct {
+	Cluster                         string
+	Namespace                       string
+	ImageInformationName            string
+	StableVersion                   string
+	CanaryVersion                   string
+	StableReplicationControllerName string
+	CanaryReplicationControllerName string
+	// Total replicas of the stable and the canary
+	ReplicaAmount int
+	// Percentage of the canary replicas in each step. The last one is 100 promoting the canary.
+	StepPercentageSlice []int
+	PauseInSecond       int
+	Threshold           Threshold
+	StepIndex           int
+	// -1 before the first step is applied
+	StepAppliedIndex int
+	State            string
+	Message          string
+	UserName         string
+	TokenHeaderMap   map[string]string
+	CreatedTime      time.Time
+	StepStartedTime  time.Time
+	UpdatedTime      time.Time
+}
+
+// request is the action of the user taken by the runner since only the runner changes the running canary
+type request struct {
+	Action         string
+	UserName       string
+	TokenHeaderMap map[string]string
+}
+
+type ByCanary []Canary
+
+func (b ByCanary) Len() int      { return len(b) }
+func (b ByCanary) Swap(i, j int) { b[i], b[j] = b[j], b[i] }
+func (b ByCanary) Less(i, j int) bool {
+	return b[i].Namespace+"_"+b[i].ImageInformationName < b[j].Namespace+"_"+b[j].ImageInformationName
+}
+
+// IsActive is true until the canary is promoted or rolled back
+func (canary *Canary) IsActive() bool {
+	return canary.State == StateRunning || canary.State == StatePaused || canary.State == StateHalted
+}
+
+// GetPercentage returns the percentage of the canary replicas applied
+func (canary *Canary) GetPercentage() int {
+	if canary.StepAppliedIndex < 0 {
+		return 0
+	}
+	return canary.StepPercentageSlice[canary.StepAppliedIndex]
+}
+
+// GetStepText shows the steps with the applied one in brackets such as 5 [25] 50 100
+func (canary *Canary) GetStepText() string {
+	textSlice := make([]string, 0)
+	for i, percentage := range canary.StepPercentageSlice {
+		text := strconv.Itoa(percentage) + "%"
+		if i == canary.StepAppliedIndex {
+			text = "[" + text + "]"
+		}
+		textSlice = append(textSlice, text)
+	}
+	return strings.Join(textSlice, " ")
+}
+
+// GetReplicaAmount splits the replicas. The canary has at least one replica in any step.
+func GetReplicaAmount(replicaAmount int, percentage int) (int, int) {
+	canaryReplicaAmount := int(math.Ceil(float64(replicaAmount) * float64(percentage) / 100.0))
+	if canaryReplicaAmount < 1 {
+		canaryReplicaAmount = 1
+	}
+	if canaryReplicaAmount > replicaAmount {
+		canaryReplicaAmount = replicaAmount
+	}
+	return replicaAmount - canaryReplicaAmount, canaryReplicaAmount
+}
+
+// ParseStepPercentageSlice parses the steps such as 5,25,50,100. 100 is appended if it is not the last.
+func ParseStepPercentageSlice(text string) ([]int, error) {
+	stepPercentageSlice := make([]int, 0)
+	for _, field := range strings.Split(text, ",") {
+		field = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(field), "%"))
+		if field == "" {
+			continue
+		}
+		percentage, err := strconv.Atoi(field)
+		if err != nil || percentage <= 0 || percentage > 100 {
+			return nil, errors.New("Step " + field + " must be a percentage between 1 and 100")
+		}
+		if len(stepPercentageSlice) > 0 && percentage <= stepPercentageSlice[len(stepPercentageSlice)-1] {
+			return nil, errors.New("Steps must be increasing")
+		}
+		stepPercentageSlice = append(stepPercentageSlice, percentage)
+	}
+	if len(stepPercentageSlice) == 0 || stepPercentageSlice[len(stepPercentageSlice)-1] != 100 {
+		stepPercentageSlice = append(stepPercentageSlice, 100)
+	}
+	return stepPercentageSlice, nil
+}
+
+func getKeySuffix(cluster string, namespace string, name string) string {
+	return cluster + "/" + namespace + "/" + name
+}
+
+func save(canary *Canary) error {
+	canary.UpdatedTime = time.Now()
+	byteSlice, err := json.Marshal(canary)
+	if err != nil {
+		return err
+	}
+	return sessionstore.GetStore().Set(keyPrefix+getKeySuffix(canary.Cluster, canary.Namespace, canary.ImageInformationName), byteSlice, 0)
+}
+
+func getCanaryByKey(key string) (*Canary, error) {
+	byteSlice, err := sessionstore.GetStore().Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if byteSlice == nil {
+		return nil, nil
+	}
+	canary := &Canary{}
+	if err := json.Unmarshal(byteSlice, canary); err != nil {
+		return nil, err
+	}
+	return canary, nil
+}
+
+// GetCanary returns nil if the deploy doesn't have the canary
+func GetCanary(cluster string, namespace string, name string) (*Canary, error) {
+	return getCanaryByKey(keyPrefix + getKeySuffix(cluster, namespace, name))
+}
+
+// GetCanarySlice returns the canaries of the namespace. The empty cluster returns the canaries of all clusters for the runner.
+func GetCanarySlice(cluster string, namespace string) ([]Canary, error) {
+	prefix := keyPrefix
+	if cluster != "" {
+		prefix += cluster + "/" + namespace + "/"
+	}
+	keySlice, err := sessionstore.GetStore().List(prefix)
+	if err != nil {
+		return nil, err
+	}
+	canarySlice := make([]Canary, 0)
+	for _, key := range keySlice {
+		canary, err := getCanaryByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if canary != nil {
+			canarySlice = append(canarySlice, *canary)
+		}
+	}
+	sort.Sort(ByCanary(canarySlice))
+	return canarySlice, nil
+}
+
+// GetPendingAction returns the action requested but not taken by the runner yet
+func GetPendingAction(canary *Canary) string {
+	byteSlice, _ := sessionstore.GetStore().Get(requestKeyPrefix + getKeySuffix(canary.Cluster, canary.Namespace, canary.ImageInformationName))
+	request := request{}
+	if byteSlice == nil || json.Unmarshal(byteSlice, &request) != nil {
+		return ""
+	}
+	return request.Action
+}
+
+// CreateInput is the canary from the user
+type CreateInput struct {
+	ImageInformationName string
+	Version              string
+	StepPercentageSlice  []int
+	PauseInSecond        int
+	Threshold            Threshold
+}
+
+func getDeployInformation(client *backend.Client, namespace string, name string) (*backend.DeployInformation, error) {
+	deployInformationSlice, err := client.GetDeployInformationSlice(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployInformation := range deployInformationSlice {
+		if deployInformation.ImageInformationName == name {
+			return &deployInformation, nil
+		}
+	}
+	return nil, errors.New("Deploy " + name + " doesn't exist in namespace " + namespace)
+}
+
+// checkSharedService makes sure the traffic is shared by the service selecting the name only
+func checkSharedService(client *backend.Client, namespace string, name string) error {
+	serviceSlice, err := client.GetServiceSlice(namespace)
+	if err != nil {
+		return err
+	}
+	for _, service := range serviceSlice {
+		selectedName, _ := service.Selector["name"].(string)
+		if selectedName == name && len(service.Selector) == 1 {
+			return nil
+		}
+	}
+	return errors.New("No service selects the pods of deploy " + name + " by the name only so the traffic can't be shared with the canary")
+}
+
+// Create starts the canary with the canary replication controller of no replica. The runner applies the steps.
+func Create(ctx *context.Context, namespace string, createInput CreateInput) error {
+	cluster := backend.GetClusterName(ctx)
+	name := createInput.ImageInformationName
+
+	// The runner holding the lease continues the canary after the restart of the GUI
+	if err := sessionstore.RequireShared("The canary deployment"); err != nil {
+		return err
+	}
+
+	if len(createInput.StepPercentageSlice) == 0 || createInput.StepPercentageSlice[len(createInput.StepPercentageSlice)-1] != 100 {
+		return errors.New("The last step must be 100")
+	}
+	if createInput.PauseInSecond < 0 {
+		return errors.New("Pause must not be negative")
+	}
+
+	existingCanary, err := GetCanary(cluster, namespace, name)
+	if err != nil {
+		return err
+	}
+	if existingCanary != nil && existingCanary.IsActive() {
+		return errors.New("Deploy " + name + " already has the canary of version " + existingCanary.CanaryVersion)
+	}
+
+	client := backend.NewCloudoneClient(ctx)
+	deployInformation, err := getDeployInformation(client, namespace, name)
+	if err != nil {
+		return err
+	}
+	if deployInformation.CurrentVersion == createInput.Version {
+		return errors.New("Version " + createInput.Version + " is the current version")
+	}
+	if deployInformation.ReplicaAmount < 2 {
+		return errors.New("Deploy " + name + " needs at least 2 replicas to share the traffic with the canary")
+	}
+
+	if err := checkSharedService(client, namespace, name); err != nil {
+		return err
+	}
+
+	imageRecordSlice, err := client.GetImageRecordSlice(name)
+	if err != nil {
+		return err
+	}
+	var imageRecord *backend.ImageRecord
+	for i := range imageRecordSlice {
+		if imageRecordSlice[i].Version == createInput.Version && imageRecordSlice[i].Failure == false {
+			imageRecord = &imageRecordSlice[i]
+		}
+	}
+	if imageRecord == nil {
+		return errors.New("Version " + createInput.Version + " of " + name + " doesn't exist or failed to build")
+	}
+
+	// The canary is the same as the stable except the image
+	stableReplicationControllerName := name + deployInformation.CurrentVersion
+	replicationController, err := client.GetReplicationController(namespace, stableReplicationControllerName)
+	if err != nil {
+		return err
+	}
+	canaryReplicationControllerName := name + createInput.Version + canaryVersionSuffix
+	replicationController.Name = canaryReplicationControllerName
+	replicationController.ReplicaAmount = 0
+	replicationController.Selector = backend.ReplicationControllerSelector{Name: name, Version: createInput.Version + canaryVersionSuffix}
+	for i := range replicationController.ContainerSlice {
+		replicationController.ContainerSlice[i].Image = imageRecord.Path
+	}
+	if err := client.CreateReplicationController(namespace, *replicationController); err != nil {
+		return err
+	}
+
+	userName, _ := ctx.Input.Session("username").(string)
+	tokenHeaderMap, _ := ctx.Input.Session("tokenHeaderMap").(map[string]string)
+	return save(&Canary{
+		Cluster:                         cluster,
+		Namespace:                       namespace,
+		ImageInformationName:            name,
+		StableVersion:                   deployInformation.CurrentVersion,
+		CanaryVersion:                   createInput.Version,
+		StableReplicationControllerName: stableReplicationControllerName,
+		CanaryReplicationControllerName: canaryReplicationControllerName,
+		ReplicaAmount:                   deployInformation.ReplicaAmount,
+		StepPercentageSlice:             createInput.StepPercentageSlice,
+		PauseInSecond:                   createInput.PauseInSecond,
+		Threshold:                       createInput.Threshold,
+		StepIndex:                       0,
+		StepAppliedIndex:                -1,
+		State:                           StateRunning,
+		Message:                         "Created",
+		UserName:                        userName,
+		TokenHeaderMap:                  tokenHeaderMap,
+		CreatedTime:                     time.Now(),
+	})
+}
+
+// Request asks the runner to take the action on the canary. The resume also renews the token with the user.
+func Request(ctx *context.Context, namespace string, name string, action string) error {
+	cluster := backend.GetClusterName(ctx)
+	canary, err := GetCanary(cluster, namespace, name)
+	if err != nil {
+		return err
+	}
+	if canary == nil || canary.IsActive() == false {
+		return errors.New("Deploy " + name + " doesn't have the canary in progress")
+	}
+
+	userName, _ := ctx.Input.Session("username").(string)
+	tokenHeaderMap, _ := ctx.Input.Session("tokenHeaderMap").(map[string]string)
+	byteSlice, err := json.Marshal(request{action, userName, tokenHeaderMap})
+	if err != nil {
+		return err
+	}
+	return sessionstore.GetStore().Set(requestKeyPrefix+getKeySuffix(cluster, namespace, name), byteSlice, 0)
+}
+
+// Delete removes the finished canary from the list
+func Delete(ctx *context.Context, namespace string, name string) error {
+	cluster := backend.GetClusterName(ctx)
+	canary, err := GetCanary(cluster, namespace, name)
+	if err != nil {
+		return err
+	}
+	if canary == nil {
+		return errors.New("Deploy " + name + " doesn't have the canary")
+	}
+	if canary.IsActive() {
+		return errors.New("The canary of deploy " + name + " is in progress. Abort it first.")
+	}
+	store := sessionstore.GetStore()
+	if err := store.Delete(requestKeyPrefix + getKeySuffix(cluster, namespace, name)); err != nil {
+		return err
+	}
+	return store.Delete(keyPrefix + getKeySuffix(cluster, namespace, name))
+}
@@ -0,0 +1,383 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package canary
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultCanaryCheckIntervalInSecond = 10
+	eventCheckAmount                   = 200
+)
+
+// The reasons of the kubernetes warning events such as Failed, FailedSync, BackOff, Unhealthy and ErrImagePull
+var warningEventReasonPartSlice = []string{"Fail", "BackOff", "Unhealthy", "Err"}
+
+var runnerLock = sync.Mutex{}
+var runnerStarted = false
+var stopChannel = make(chan struct{})
+var stoppedChannel = make(chan struct{})
+
+func getCheckInterval() time.Duration {
+	return time.Duration(beego.AppConfig.DefaultInt("canaryCheckIntervalInSecond", defaultCanaryCheckIntervalInSecond)) * time.Second
+}
+
+// StartRunner checks the canaries in the interval until StopRunner
+func StartRunner() {
+	runnerLock.Lock()
+	defer runnerLock.Unlock()
+
+	if runnerStarted {
+		return
+	}
+	runnerStarted = true
+
+	stop := stopChannel
+	stopped := stoppedChannel
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(getCheckInterval())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				Tick()
+			}
+		}
+	}()
+}
+
+// StopRunner waits for the canary being processed. The lease expires so another replica continues the canaries.
+func StopRunner() {
+	runnerLock.Lock()
+	defer runnerLock.Unlock()
+
+	if runnerStarted == false {
+		return
+	}
+	close(stopChannel)
+	<-stoppedChannel
+
+	runnerStarted = false
+	stopChannel = make(chan struct{})
+	stoppedChannel = make(chan struct{})
+}
+
+// Tick processes all the active canaries once
+func Tick() {
+	canarySlice, err := GetCanarySlice("", "")
+	if err != nil {
+		beego.Error("Fail to get the canaries with error " + err.Error())
+		return
+	}
+	for i := range canarySlice {
+		if canarySlice[i].IsActive() && acquireLease(&canarySlice[i]) {
+			process(&canarySlice[i])
+		}
+	}
+}
+
+// acquireLease makes sure only one replica changes the canary. The lease is renewed in each tick.
+func acquireLease(canary *Canary) bool {
+	acquired, err := sessionstore.AcquireLease(leaseKeyPrefix+getKeySuffix(canary.Cluster, canary.Namespace, canary.ImageInformationName), 3*getCheckInterval())
+	if err != nil {
+		beego.Error("Fail to acquire the canary lease with error " + err.Error())
+		return false
+	}
+	return acquired
+}
+
+func takeRequest(canary *Canary) *request {
+	byteSlice, err := sessionstore.GetStore().Take(requestKeyPrefix + getKeySuffix(canary.Cluster, canary.Namespace, canary.ImageInformationName))
+	if err != nil || byteSlice == nil {
+		return nil
+	}
+	request := &request{}
+	if err := json.Unmarshal(byteSlice, request); err != nil {
+		return nil
+	}
+	return request
+}
+
+func process(canary *Canary) {
+	err := advance(canary)
+	if identity.IsTokenInvalid(err) {
+		canary.State = StateHalted
+		canary.Message = "User token is expired. Resume the canary to continue with your token."
+	} else if err != nil {
+		// Retried in the next tick
+		canary.Message = "Fail to continue with error " + err.Error()
+	}
+	if err := save(canary); err != nil {
+		beego.Error("Fail to save the canary of deploy " + canary.ImageInformationName + " with error " + err.Error())
+	}
+}
+
+func getClient(canary *Canary) *backend.Client {
+	return backend.NewCloudoneClientWithTokenHeaderMap(canary.TokenHeaderMap).WithCluster(canary.Cluster)
+}
+
+func advance(canary *Canary) error {
+	if request := takeRequest(canary); request != nil {
+		switch request.Action {
+		case ActionPause:
+			if canary.State == StateRunning {
+				canary.State = StatePaused
+				canary.Message = "Paused by " + request.UserName
+			}
+		case ActionResume:
+			if request.TokenHeaderMap != nil {
+				canary.TokenHeaderMap = request.TokenHeaderMap
+			}
+			canary.State = StateRunning
+			canary.StepStartedTime = time.Now()
+			canary.Message = "Resumed by " + request.UserName
+		case ActionAbort:
+			return rollback(canary, "Aborted by "+request.UserName)
+		case ActionPromote:
+			return promote(canary, "Promoted by "+request.UserName)
+		}
+	}
+
+	if canary.State == StateHalted {
+		return nil
+	}
+
+	// The paused canary is still checked
+	if canary.StepAppliedIndex >= 0 {
+		reason, err := checkThreshold(canary)
+		if err != nil {
+			return err
+		}
+		if reason != "" {
+			return rollback(canary, "Rolled back automatically since "+reason)
+		}
+	}
+
+	if canary.State != StateRunning {
+		return nil
+	}
+
+	if canary.StepAppliedIndex == canary.StepIndex && time.Since(canary.StepStartedTime) >= time.Duration(canary.PauseInSecond)*time.Second {
+		canary.StepIndex++
+	}
+	if canary.StepAppliedIndex < canary.StepIndex {
+		if canary.StepPercentageSlice[canary.StepIndex] == 100 {
+			return promote(canary, "Promoted after all steps")
+		}
+		return applyStep(canary)
+	}
+	return nil
+}
+
+// applyStep scales the canary up before the stable down so the capacity is kept
+func applyStep(canary *Canary) error {
+	percentage := canary.StepPercentageSlice[canary.StepIndex]
+	stableReplicaAmount, canaryReplicaAmount := GetReplicaAmount(canary.ReplicaAmount, percentage)
+
+	client := getClient(canary)
+	if err := client.ResizeReplicationController(canary.Namespace, canary.CanaryReplicationControllerName, canaryReplicaAmount); err != nil {
+		return err
+	}
+	if err := client.ResizeReplicationController(canary.Namespace, canary.StableReplicationControllerName, stableReplicaAmount); err != nil {
+		return err
+	}
+
+	canary.StepAppliedIndex = canary.StepIndex
+	canary.StepStartedTime = time.Now()
+	canary.Message = fmt.Sprintf("Shifted %d%% of the traffic with %d canary and %d stable replicas", percentage, canaryReplicaAmount, stableReplicaAmount)
+	return nil
+}
+
+// rollback restores the stable replicas before the canary is deleted
+func rollback(canary *Canary, reason string) error {
+	client := getClient(canary)
+	err := client.ResizeReplicationController(canary.Namespace, canary.StableReplicationControllerName, canary.ReplicaAmount)
+	if err == nil {
+		err = client.DeleteReplicationController(canary.Namespace, canary.CanaryReplicationControllerName)
+	}
+	if err != nil && identity.IsTokenInvalid(err) == false {
+		canary.State = StateHalted
+		canary.Message = reason + " but fail to roll back with error " + err.Error() + ". Abort again to retry."
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	canary.State = StateRolledBack
+	canary.Message = reason
+	beego.Info("The canary of deploy " + canary.ImageInformationName + " version " + canary.CanaryVersion + " in namespace " + canary.Namespace + " is rolled back. " + reason)
+	return nil
+}
+
+// promote rolling updates the deploy to the canary version while the canary still serves its share so the traffic
+// to the stable version only decreases. The canary is deleted after the deploy is in the size again.
+func promote(canary *Canary, reason string) error {
+	canary.StepIndex = len(canary.StepPercentageSlice) - 1
+
+	client := getClient(canary)
+	err := func() error {
+		deployInformation, err := getDeployInformation(client, canary.Namespace, canary.ImageInformationName)
+		if err != nil {
+			return err
+		}
+		replicationController, err := client.GetReplicationController(canary.Namespace, canary.CanaryReplicationControllerName)
+		if err != nil {
+			return err
+		}
+		environmentSlice := make([]backend.ReplicationControllerContainerEnvironment, 0)
+		if len(replicationController.ContainerSlice) > 0 {
+			environmentSlice = replicationController.ContainerSlice[0].EnvironmentSlice
+		}
+
+		if err := deployrevision.RecordBaselineForUser(client, canary.Cluster, canary.UserName, canary.Namespace, canary.ImageInformationName); err != nil {
+			beego.Error("Fail to record the revision of deploy " + canary.ImageInformationName + " before the canary is promoted with error " + err.Error())
+		}
+
+		if deployInformation.CurrentVersion != canary.CanaryVersion {
+			err := client.UpdateDeploy(canary.Namespace, backend.DeployUpdateInput{
+				ImageInformationName: canary.ImageInformationName,
+				Version:              canary.CanaryVersion,
+				Description:          deployInformation.Description,
+				EnvironmentSlice:     environmentSlice,
+			})
+			if err != nil {
+				return err
+			}
+		}
+		if err := client.ResizeDeploy(canary.Namespace, canary.ImageInformationName, canary.ReplicaAmount); err != nil {
+			return err
+		}
+		return client.DeleteReplicationController(canary.Namespace, canary.CanaryReplicationControllerName)
+	}()
+	if err != nil && identity.IsTokenInvalid(err) == false {
+		canary.State = StateHalted
+		canary.Message = reason + " but fail to promote with error " + err.Error() + ". Resume to retry or abort to roll back."
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := deployrevision.RecordForUser(client, canary.Cluster, canary.UserName, canary.Namespace, canary.ImageInformationName, deployrevision.ActionCanary); err != nil {
+		beego.Error("Fail to record the revision of deploy " + canary.ImageInformationName + " after the canary is promoted with error " + err.Error())
+	}
+
+	canary.StepAppliedIndex = canary.StepIndex
+	canary.State = StatePromoted
+	canary.Message = reason
+	beego.Info("The canary of deploy " + canary.ImageInformationName + " version " + canary.CanaryVersion + " in namespace " + canary.Namespace + " is promoted. " + reason)
+	return nil
+}
+
+func isWarningEventReason(reason string) bool {
+	for _, part := range warningEventReasonPartSlice {
+		if strings.Contains(reason, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkThreshold returns the reason if the canary crosses any threshold
+func checkThreshold(canary *Canary) (string, error) {
+	client := getClient(canary)
+	threshold := canary.Threshold
+
+	if threshold.RestartMaximum >= 0 {
+		replicationControllerAndRelatedPodSlice, err := client.GetReplicationControllerAndRelatedPodSlice(canary.Namespace)
+		if err != nil {
+			return "", err
+		}
+		restartCount := 0
+		for _, replicationControllerAndRelatedPod := range replicationControllerAndRelatedPodSlice {
+			if replicationControllerAndRelatedPod.Name != canary.CanaryReplicationControllerName {
+				continue
+			}
+			for _, pod := range replicationControllerAndRelatedPod.PodSlice {
+				for _, container := range pod.ContainerSlice {
+					restartCount += container.RestartCount
+				}
+			}
+		}
+		if restartCount > threshold.RestartMaximum {
+			return fmt.Sprintf("the canary containers restarted %d times above %d", restartCount, threshold.RestartMaximum), nil
+		}
+	}
+
+	if threshold.CpuUsageMaximumInMillisecond > 0 || threshold.MemoryUsageMaximumInMB > 0 {
+		replicationControllerMetric, err := client.GetReplicationControllerMetric(canary.Namespace, canary.CanaryReplicationControllerName)
+		if err != nil {
+			return "", err
+		}
+		for _, podMetric := range replicationControllerMetric.PodMetricSlice {
+			for _, containerMetric := range podMetric.ContainerMetricSlice {
+				// The same units as the container monitor
+				length := len(containerMetric.CpuUsageTotalSlice)
+				if threshold.CpuUsageMaximumInMillisecond > 0 && length > 1 {
+					cpuUsage := (containerMetric.CpuUsageTotalSlice[length-1] - containerMetric.CpuUsageTotalSlice[length-2]) / 1000000
+					if cpuUsage > threshold.CpuUsageMaximumInMillisecond {
+						return fmt.Sprintf("CPU usage of pod %s is %d ms above %d ms", podMetric.PodName, cpuUsage, threshold.CpuUsageMaximumInMillisecond), nil
+					}
+				}
+				length = len(containerMetric.MemoryUsageSlice)
+				if threshold.MemoryUsageMaximumInMB > 0 && length > 0 {
+					memoryUsage := containerMetric.MemoryUsageSlice[length-1] / (1024 * 1024)
+					if memoryUsage > threshold.MemoryUsageMaximumInMB {
+						return fmt.Sprintf("memory usage of pod %s is %d MB above %d MB", podMetric.PodName, memoryUsage, threshold.MemoryUsageMaximumInMB), nil
+					}
+				}
+			}
+		}
+	}
+
+	if threshold.EventMaximum >= 0 {
+		kubernetesEventSlice, err := client.GetKubernetesEventSlice(false, eventCheckAmount, 0)
+		if err != nil {
+			return "", err
+		}
+		// The event timestamps are in seconds
+		createdTime := canary.CreatedTime.Truncate(time.Second)
+		eventCount := 0
+		lastReason := ""
+		for _, kubernetesEvent := range kubernetesEventSlice {
+			if kubernetesEvent.Namespace == canary.Namespace &&
+				strings.HasPrefix(kubernetesEvent.Name, canary.CanaryReplicationControllerName+"-") &&
+				kubernetesEvent.LastTimestamp.Before(createdTime) == false &&
+				isWarningEventReason(kubernetesEvent.Reason) {
+				eventCount += kubernetesEvent.Count
+				lastReason = kubernetesEvent.Reason + " " + kubernetesEvent.Message
+			}
+		}
+		if eventCount > threshold.EventMaximum {
+			return fmt.Sprintf("the canary pods have %d warning events above %d such as %s", eventCount, threshold.EventMaximum, lastReason), nil
+		}
+	}
+
+	return "", nil
+}
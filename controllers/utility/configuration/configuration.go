@@ -0,0 +1,369 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/config"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	// EnvironmentPrefix is the prefix of the environment variables overriding the configuration.
+	// CLOUDONE_GUI_CLOUDONE_HOST sets cloudoneHost and CLOUDONE_GUI_CLUSTER_STAGING__CLOUDONE_HOST sets cloudoneHost in [cluster-staging].
+	EnvironmentPrefix = "CLOUDONE_GUI_"
+	// EnvironmentConfigPath selects the configuration file like the flag -config
+	EnvironmentConfigPath = EnvironmentPrefix + "CONFIG"
+	// DefaultConfigPath is used if it exists. Otherwise conf/app.conf in the working directory is used.
+	DefaultConfigPath = "/etc/cloudone_gui/app.conf"
+
+	SourceDefault     = "default"
+	SourceFile        = "file"
+	SourceEnvironment = "environment"
+	SourceFlag        = "flag"
+	SourceDemo        = "demo"
+
+	adapterName      = "cloudone_gui"
+	defaultSection   = "default"
+	sectionSeparator = "::"
+	redactedValue    = "******"
+)
+
+// defaultValueMap is the lowest layer used when the key is not set in the file
+var defaultValueMap = map[string]string{
+	"cloudoneGUIProtocol":      "https",
+	"cloudoneProtocol":         "https",
+	"cloudonePort":             "8081",
+	"cloudoneAnalysisProtocol": "https",
+	"cloudoneAnalysisPort":     "8082",
+	"HTTPSCertFile":            "/etc/cloudone_gui/cert.pem",
+	"HTTPSKeyFile":             "/etc/cloudone_gui/key.pem",
+}
+
+// The keys ending with these are printed as redacted
+var secretKeySuffixSlice = []string{
+	"password",
+	"secret",
+	"token",
+	"key",
+}
+
+// override is a value set by the environment variable or the flag
+type override struct {
+	section string
+	key     string
+	value   string
+	source  string
+}
+
+func (o override) getName() string {
+	if o.section == defaultSection {
+		return o.key
+	}
+	return o.section + sectionSeparator + o.key
+}
+
+// layeredConfig is registered as the config adapter of beego so beego.AppConfig and beego.BConfig both see the layers
+type layeredConfig struct {
+	overrideSlice []override
+}
+
+func (layered *layeredConfig) Parse(path string) (config.Configer, error) {
+	configer, err := (&config.IniConfig{}).Parse(path)
+	if err != nil {
+		return nil, err
+	}
+	return layered.apply(configer)
+}
+
+func (layered *layeredConfig) ParseData(data []byte) (config.Configer, error) {
+	configer, err := (&config.IniConfig{}).ParseData(data)
+	if err != nil {
+		return nil, err
+	}
+	return layered.apply(configer)
+}
+
+func (layered *layeredConfig) apply(configer config.Configer) (config.Configer, error) {
+	sourceMap := make(map[string]string)
+
+	for key, value := range defaultValueMap {
+		if configer.String(key) == "" {
+			if err := configer.Set(key, value); err != nil {
+				return nil, err
+			}
+			sourceMap[strings.ToLower(key)] = SourceDefault
+		}
+	}
+
+	// The flags are after the environment variables so they win
+	for _, o := range layered.overrideSlice {
+		if err := configer.Set(o.getName(), o.value); err != nil {
+			return nil, err
+		}
+		sourceMap[o.getName()] = o.source
+	}
+
+	lock.Lock()
+	effectiveConfiger = configer
+	effectiveSourceMap = sourceMap
+	lock.Unlock()
+
+	return configer, nil
+}
+
+var lock = sync.Mutex{}
+var effectiveConfiger config.Configer
+var effectiveSourceMap = make(map[string]string)
+var effectiveConfigPath string
+var effectiveSectionSlice = make([]string, 0)
+
+// keyValueSlice is the flag -set used repeatedly
+type keyValueSlice []string
+
+func (k *keyValueSlice) String() string {
+	return strings.Join(*k, ",")
+}
+
+func (k *keyValueSlice) Set(value string) error {
+	*k = append(*k, value)
+	return nil
+}
+
+// Option is the result of the flags not about the configuration values
+type Option struct {
+	PrintConfig bool
+}
+
+// Load reads the configuration in the layers of the defaults, the file, the environment variables and the flags.
+// The later layer overrides the former one. The flags are -config path, -set key=value and -print-config.
+func Load(argumentSlice []string) (*Option, error) {
+	flagSet := flag.NewFlagSet("cloudone_gui", flag.ContinueOnError)
+	configPath := flagSet.String("config", "", "Path to the configuration file. Default is "+DefaultConfigPath+" or conf/app.conf")
+	setSlice := keyValueSlice{}
+	flagSet.Var(&setSlice, "set", "Override the configuration key=value or section::key=value. It could be repeated.")
+	printConfig := flagSet.Bool("print-config", false, "Print the effective configuration with the secrets redacted and exit")
+	if err := flagSet.Parse(argumentSlice); err != nil {
+		return nil, err
+	}
+
+	overrideSlice := getEnvironmentOverrideSlice(os.Environ())
+	for _, keyValue := range setSlice {
+		o, err := parseFlagOverride(keyValue)
+		if err != nil {
+			return nil, err
+		}
+		overrideSlice = append(overrideSlice, o)
+	}
+
+	path, err := getConfigPath(*configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sectionSlice, err := getFileSectionSlice(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range overrideSlice {
+		sectionSlice = appendSection(sectionSlice, o.section)
+	}
+
+	config.Register(adapterName, &layeredConfig{overrideSlice})
+	if err := beego.LoadAppConfig(adapterName, path); err != nil {
+		return nil, errors.New("Fail to load configuration file " + path + " with error " + err.Error())
+	}
+
+	lock.Lock()
+	effectiveConfigPath = path
+	effectiveSectionSlice = sectionSlice
+	lock.Unlock()
+
+	return &Option{*printConfig}, nil
+}
+
+// Set overrides the loaded configuration at startup such as the demo mode pointing the backend to the fake.
+// The name is key or section::key.
+func Set(name string, value string, source string) error {
+	lock.Lock()
+	defer lock.Unlock()
+
+	if effectiveConfiger == nil {
+		return errors.New("Configuration is not loaded")
+	}
+
+	name = strings.ToLower(name)
+	if err := effectiveConfiger.Set(name, value); err != nil {
+		return err
+	}
+	effectiveSourceMap[name] = source
+	if index := strings.Index(name, sectionSeparator); index >= 0 {
+		effectiveSectionSlice = appendSection(effectiveSectionSlice, name[:index])
+	}
+	return nil
+}
+
+func getConfigPath(configPath string) (string, error) {
+	if configPath == "" {
+		configPath = os.Getenv(EnvironmentConfigPath)
+	}
+	if configPath == "" {
+		if _, err := os.Stat(DefaultConfigPath); err == nil {
+			return DefaultConfigPath, nil
+		}
+		configPath = "conf/app.conf"
+	}
+	if _, err := os.Stat(configPath); err != nil {
+		return "", errors.New("Configuration file " + configPath + " doesn't exist")
+	}
+	return configPath, nil
+}
+
+// getEnvironmentOverrideSlice converts CLOUDONE_GUI_SECTION_NAME__KEY_NAME to the key keyname in the section section-name.
+// The keys are case insensitive so the underscores are only used to be readable.
+func getEnvironmentOverrideSlice(environmentSlice []string) []override {
+	overrideSlice := make([]override, 0)
+	for _, environment := range environmentSlice {
+		splitSlice := strings.SplitN(environment, "=", 2)
+		if len(splitSlice) != 2 || strings.HasPrefix(splitSlice[0], EnvironmentPrefix) == false || splitSlice[0] == EnvironmentConfigPath {
+			continue
+		}
+		name := strings.ToLower(strings.TrimPrefix(splitSlice[0], EnvironmentPrefix))
+		section := defaultSection
+		if index := strings.Index(name, "__"); index >= 0 {
+			section = strings.Replace(name[:index], "_", "-", -1)
+			name = name[index+2:]
+		}
+		key := strings.Replace(name, "_", "", -1)
+		if key == "" {
+			continue
+		}
+		overrideSlice = append(overrideSlice, override{section, key, splitSlice[1], SourceEnvironment})
+	}
+	// Environment has no order so the result is stable
+	sort.Slice(overrideSlice, func(i, j int) bool {
+		return overrideSlice[i].getName() < overrideSlice[j].getName()
+	})
+	return overrideSlice
+}
+
+func parseFlagOverride(keyValue string) (override, error) {
+	splitSlice := strings.SplitN(keyValue, "=", 2)
+	if len(splitSlice) != 2 || strings.TrimSpace(splitSlice[0]) == "" {
+		return override{}, errors.New("Flag -set " + keyValue + " is not in the format key=value or section::key=value")
+	}
+	name := strings.ToLower(strings.TrimSpace(splitSlice[0]))
+	section := defaultSection
+	if index := strings.Index(name, sectionSeparator); index >= 0 {
+		section = name[:index]
+		name = name[index+len(sectionSeparator):]
+	}
+	return override{section, name, splitSlice[1], SourceFlag}, nil
+}
+
+// getFileSectionSlice lists the sections in the file since the configer doesn't list them
+func getFileSectionSlice(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	sectionSlice := []string{defaultSection}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sectionSlice = appendSection(sectionSlice, strings.ToLower(strings.TrimSpace(line[1:len(line)-1])))
+		}
+	}
+	return sectionSlice, scanner.Err()
+}
+
+func appendSection(sectionSlice []string, section string) []string {
+	for _, existing := range sectionSlice {
+		if existing == section {
+			return sectionSlice
+		}
+	}
+	return append(sectionSlice, section)
+}
+
+func isSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, suffix := range secretKeySuffixSlice {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetEffectiveConfigText prints the configuration after the layers are applied with the secrets redacted.
+// Each key is followed by where its value comes from if it is not the file.
+func GetEffectiveConfigText() string {
+	lock.Lock()
+	defer lock.Unlock()
+
+	buffer := bytes.Buffer{}
+	if effectiveConfiger == nil {
+		return ""
+	}
+
+	buffer.WriteString("# Configuration file " + effectiveConfigPath + "\n")
+	for _, section := range effectiveSectionSlice {
+		keyValueMap, err := effectiveConfiger.GetSection(section)
+		if err != nil || len(keyValueMap) == 0 {
+			continue
+		}
+		if section != defaultSection {
+			buffer.WriteString("[" + section + "]\n")
+		}
+
+		keySlice := make([]string, 0)
+		for key := range keyValueMap {
+			keySlice = append(keySlice, key)
+		}
+		sort.Strings(keySlice)
+
+		for _, key := range keySlice {
+			value := keyValueMap[key]
+			if isSecretKey(key) && value != "" {
+				value = redactedValue
+			}
+			name := key
+			if section != defaultSection {
+				name = section + sectionSeparator + key
+			}
+			source := effectiveSourceMap[name]
+			if source == "" {
+				source = SourceFile
+			}
+			if source == SourceFile {
+				buffer.WriteString(key + " = " + value + "\n")
+			} else {
+				buffer.WriteString(key + " = " + value + " # " + source + "\n")
+			}
+		}
+	}
+	return buffer.String()
+}
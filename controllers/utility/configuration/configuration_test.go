@@ -0,0 +1,133 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestGetEnvironmentOverrideSlice(t *testing.T) {
+	Convey("Subject: Environment variables are converted to the overrides\n", t, func() {
+		overrideSlice := getEnvironmentOverrideSlice([]string{
+			"PATH=/usr/bin",
+			EnvironmentConfigPath + "=/etc/app.conf",
+			"CLOUDONE_GUI_CLOUDONE_HOST=10.0.0.1",
+			"CLOUDONE_GUI_CLUSTER_STAGING__CLOUDONE_HOST=10.0.0.2",
+			"CLOUDONE_GUI_=ignored",
+			"CLOUDONE_GUI_METRICS_TOKEN=a=b",
+		})
+		So(overrideSlice, ShouldResemble, []override{
+			override{defaultSection, "cloudonehost", "10.0.0.1", SourceEnvironment},
+			override{"cluster-staging", "cloudonehost", "10.0.0.2", SourceEnvironment},
+			override{defaultSection, "metricstoken", "a=b", SourceEnvironment},
+		})
+	})
+}
+
+func TestParseFlagOverride(t *testing.T) {
+	Convey("Subject: Flag -set is parsed to the override\n", t, func() {
+		testCaseSlice := []struct {
+			keyValue string
+			expected override
+			failed   bool
+		}{
+			{"cloudoneHost=10.0.0.1", override{defaultSection, "cloudonehost", "10.0.0.1", SourceFlag}, false},
+			{"cluster-staging::cloudoneHost=10.0.0.2", override{"cluster-staging", "cloudonehost", "10.0.0.2", SourceFlag}, false},
+			{" cloudonePort =8081", override{defaultSection, "cloudoneport", "8081", SourceFlag}, false},
+			{"metricsToken=", override{defaultSection, "metricstoken", "", SourceFlag}, false},
+			{"cloudoneHost", override{}, true},
+			{"=10.0.0.1", override{}, true},
+		}
+		for _, testCase := range testCaseSlice {
+			o, err := parseFlagOverride(testCase.keyValue)
+			So(err != nil, ShouldEqual, testCase.failed)
+			So(o, ShouldResemble, testCase.expected)
+		}
+	})
+}
+
+func TestLayeredConfig(t *testing.T) {
+	Convey("Subject: The layers override the file and the secrets are redacted\n", t, func() {
+		layered := &layeredConfig{[]override{
+			override{defaultSection, "cloudonehost", "10.0.0.1", SourceEnvironment},
+			override{defaultSection, "cloudonehost", "10.0.0.9", SourceFlag},
+			override{"cluster-staging", "cloudonehost", "10.0.0.2", SourceEnvironment},
+		}}
+		configer, err := layered.ParseData([]byte(strings.Join([]string{
+			"cloudoneHost = 127.0.0.1",
+			"cloudonePort = 9090",
+			"metricsToken = plain",
+			"ldapBindPassword = plain",
+			"sessionStore = memory",
+			"[cluster-staging]",
+			"cloudoneHost = 127.0.0.2",
+			"cloudoneAnalysisPort = 9092",
+		}, "\n")))
+		So(err, ShouldBeNil)
+
+		testCaseSlice := []struct {
+			name     string
+			expected string
+		}{
+			{"cloudoneHost", "10.0.0.9"},
+			{"cloudonePort", "9090"},
+			{"cloudoneProtocol", "https"},
+			{"cloudoneAnalysisPort", "8082"},
+			{"cluster-staging::cloudoneHost", "10.0.0.2"},
+			{"cluster-staging::cloudoneAnalysisPort", "9092"},
+		}
+		for _, testCase := range testCaseSlice {
+			So(configer.String(testCase.name), ShouldEqual, testCase.expected)
+		}
+
+		lock.Lock()
+		effectiveConfigPath = "app.conf"
+		effectiveSectionSlice = []string{defaultSection, "cluster-staging"}
+		lock.Unlock()
+
+		text := GetEffectiveConfigText()
+		So(text, ShouldContainSubstring, "cloudonehost = 10.0.0.9 # "+SourceFlag+"\n")
+		So(text, ShouldContainSubstring, "cloudoneport = 9090\n")
+		So(text, ShouldContainSubstring, "cloudoneprotocol = https # "+SourceDefault+"\n")
+		So(text, ShouldContainSubstring, "metricstoken = "+redactedValue+"\n")
+		So(text, ShouldContainSubstring, "ldapbindpassword = "+redactedValue+"\n")
+		So(text, ShouldContainSubstring, "sessionstore = memory\n")
+		So(text, ShouldContainSubstring, "[cluster-staging]\ncloudoneanalysisport = 9092\ncloudonehost = 10.0.0.2 # "+SourceEnvironment+"\n")
+		So(text, ShouldNotContainSubstring, "plain")
+	})
+}
+
+func TestIsSecretKey(t *testing.T) {
+	Convey("Subject: The keys ending with the secret suffixes are redacted\n", t, func() {
+		testCaseSlice := []struct {
+			key    string
+			secret bool
+		}{
+			{"ldapBindPassword", true},
+			{"oidcClientSecret", true},
+			{"metricsToken", true},
+			{"auditLogSigningKey", true},
+			{"HTTPSKeyFile", false},
+			{"cloudoneHost", false},
+			{"tokenName", false},
+		}
+		for _, testCase := range testCaseSlice {
+			So(isSecretKey(testCase.key), ShouldEqual, testCase.secret)
+		}
+	})
+}
@@ -0,0 +1,103 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configuration
+
+import (
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var backendComponentSlice = []string{
+	"cloudone",
+	"cloudoneAnalysis",
+}
+
+// Validate checks the required keys at startup so the wrong configuration fails before serving any request.
+// All problems are returned together.
+func Validate() error {
+	problemSlice := make([]string, 0)
+
+	if problem := validateProtocol("cloudoneGUIProtocol", beego.AppConfig.String("cloudoneGUIProtocol")); problem != "" {
+		problemSlice = append(problemSlice, problem)
+	}
+
+	for _, cluster := range backend.GetClusterNameSlice() {
+		for _, component := range backendComponentSlice {
+			protocolKey := component + "Protocol"
+			hostKey := component + "Host"
+			portKey := component + "Port"
+			if problem := validateProtocol(getClusterKeyName(cluster, protocolKey), backend.GetClusterConfig(cluster, protocolKey)); problem != "" {
+				problemSlice = append(problemSlice, problem)
+			}
+			if backend.GetClusterConfig(cluster, hostKey) == "" {
+				problemSlice = append(problemSlice, getClusterKeyName(cluster, hostKey)+" is required")
+			}
+			if problem := validatePort(getClusterKeyName(cluster, portKey), backend.GetClusterConfig(cluster, portKey)); problem != "" {
+				problemSlice = append(problemSlice, problem)
+			}
+		}
+	}
+
+	if beego.BConfig.Listen.EnableHTTPS {
+		if problem := validateFile("HTTPSCertFile", beego.BConfig.Listen.HTTPSCertFile); problem != "" {
+			problemSlice = append(problemSlice, problem)
+		}
+		if problem := validateFile("HTTPSKeyFile", beego.BConfig.Listen.HTTPSKeyFile); problem != "" {
+			problemSlice = append(problemSlice, problem)
+		}
+	}
+
+	if len(problemSlice) > 0 {
+		return errors.New("Invalid configuration:\n  " + strings.Join(problemSlice, "\n  ") +
+			"\nSet the keys in the configuration file, the environment variables " + EnvironmentPrefix + "<KEY> or the flag -set key=value")
+	}
+	return nil
+}
+
+func getClusterKeyName(cluster string, key string) string {
+	if cluster == backend.DefaultClusterName {
+		return key
+	}
+	return key + " of cluster " + cluster
+}
+
+func validateProtocol(name string, value string) string {
+	if value != "http" && value != "https" {
+		return name + " must be http or https but is " + strconv.Quote(value)
+	}
+	return ""
+}
+
+func validatePort(name string, value string) string {
+	port, err := strconv.Atoi(value)
+	if err != nil || port <= 0 || port > 65535 {
+		return name + " must be a port between 1 and 65535 but is " + strconv.Quote(value)
+	}
+	return ""
+}
+
+func validateFile(name string, path string) string {
+	if path == "" {
+		return name + " is required since HTTPS is enabled"
+	}
+	if _, err := os.Stat(path); err != nil {
+		return name + " " + path + " doesn't exist"
+	}
+	return ""
+}
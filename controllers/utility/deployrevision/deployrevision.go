@@ -0,0 +1,449 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package deployrevision keeps the history of the deploys so a previous revision could be applied again.
+// The revisions are kept in the session store shared by the GUI replicas. They never expire but the memory store
+// loses them when the GUI restarts so use file, redis or etcd to keep the history.
+package deployrevision
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ActionCreate   = "create"
+	ActionUpdate   = "update"
+	ActionRollback = "rollback"
+	// The deploy created before the history is kept
+	ActionBaseline = "baseline"
+	// The canary version is promoted
+	ActionCanary = "canary"
+
+	// The deploy doesn't exist any more so it is created
+	RollbackModeCreate = "create"
+	// The version, the description or the environment differs so it is rolling updated
+	RollbackModeUpdate = "update"
+	// Only the size differs
+	RollbackModeResize = "resize"
+	// The ports, the resources or the extra json differs or the environment differs in the same version.
+	// They can't be rolling updated so the deploy is deleted and created again.
+	RollbackModeRecreate = "recreate"
+
+	defaultDeployRevisionMaximum = 20
+	keyPrefix                    = "deployrevision/"
+	// The replicas recording the same deploy at the same time take the next revision
+	recordRetryMaximum = 10
+)
+
+// RestoreError is returned when the deploy is deleted for the rollback but neither the revision nor its state before is created
+type RestoreError struct {
+	Message string
+}
+
+func (err RestoreError) Error() string {
+	return err.Message
+}
+
+// IsRestoreError checks whether the rollback may leave the deploy deleted
+func IsRestoreError(err error) bool {
+	_, ok := err.(RestoreError)
+	return ok
+}
+
+type DeployRevision struct {
+	Revision          int
+	Cluster           string
+	Namespace         string
+	UserName          string
+	Action            string
+	SourceRevision    int
+	CreatedTime       time.Time
+	DeployCreateInput backend.DeployCreateInput
+}
+
+type ByDeployRevision []DeployRevision
+
+func (b ByDeployRevision) Len() int           { return len(b) }
+func (b ByDeployRevision) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b ByDeployRevision) Less(i, j int) bool { return b[i].Revision > b[j].Revision }
+
+func getDeployRevisionMaximum() int {
+	return beego.AppConfig.DefaultInt("deployRevisionMaximum", defaultDeployRevisionMaximum)
+}
+
+func getKeyPrefix(cluster string, namespace string, name string) string {
+	return keyPrefix + cluster + "/" + namespace + "/" + name + "/"
+}
+
+func getKey(cluster string, namespace string, name string, revision int) string {
+	// Padded so the keys are in order
+	return getKeyPrefix(cluster, namespace, name) + fmt.Sprintf("%010d", revision)
+}
+
+// GetDeployRevisionSlice returns the revisions of the deploy with the latest first
+func GetDeployRevisionSlice(cluster string, namespace string, name string) ([]DeployRevision, error) {
+	store := sessionstore.GetStore()
+	keySlice, err := store.List(getKeyPrefix(cluster, namespace, name))
+	if err != nil {
+		return nil, err
+	}
+
+	deployRevisionSlice := make([]DeployRevision, 0)
+	for _, key := range keySlice {
+		byteSlice, err := store.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		// Deleted by another replica
+		if byteSlice == nil {
+			continue
+		}
+		deployRevision := DeployRevision{}
+		if err := json.Unmarshal(byteSlice, &deployRevision); err != nil {
+			return nil, err
+		}
+		deployRevisionSlice = append(deployRevisionSlice, deployRevision)
+	}
+
+	sort.Sort(ByDeployRevision(deployRevisionSlice))
+	return deployRevisionSlice, nil
+}
+
+func GetDeployRevision(cluster string, namespace string, name string, revision int) (*DeployRevision, error) {
+	byteSlice, err := sessionstore.GetStore().Get(getKey(cluster, namespace, name, revision))
+	if err != nil {
+		return nil, err
+	}
+	if byteSlice == nil {
+		return nil, errors.New("Revision " + strconv.Itoa(revision) + " of deploy " + name + " doesn't exist")
+	}
+	deployRevision := DeployRevision{}
+	if err := json.Unmarshal(byteSlice, &deployRevision); err != nil {
+		return nil, err
+	}
+	return &deployRevision, nil
+}
+
+func getDeployInformation(client *backend.Client, namespace string, name string) (*backend.DeployInformation, error) {
+	deployInformationSlice, err := client.GetDeployInformationSlice(namespace)
+	if err != nil {
+		return nil, err
+	}
+	for _, deployInformation := range deployInformationSlice {
+		if deployInformation.ImageInformationName == name {
+			return &deployInformation, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetDeployCreateInput converts the deploy to the input creating the same deploy
+func GetDeployCreateInput(deployInformation backend.DeployInformation) backend.DeployCreateInput {
+	return backend.DeployCreateInput{
+		ImageInformationName:  deployInformation.ImageInformationName,
+		Version:               deployInformation.CurrentVersion,
+		Description:           deployInformation.Description,
+		ReplicaAmount:         deployInformation.ReplicaAmount,
+		PortSlice:             deployInformation.ContainerPortSlice,
+		EnvironmentSlice:      deployInformation.EnvironmentSlice,
+		ResourceMap:           deployInformation.ResourceMap,
+		ExtraJsonMap:          deployInformation.ExtraJsonMap,
+		AutoUpdateForNewBuild: deployInformation.AutoUpdateForNewBuild,
+	}
+}
+
+// Record saves the deploy as it is in the backend after it is created or updated by the user
+func Record(ctx *context.Context, namespace string, name string, action string) error {
+	return record(ctx, namespace, name, action, 0)
+}
+
+// RecordBaseline saves the deploy before it is updated if it doesn't have any revision so it could be rolled back
+func RecordBaseline(ctx *context.Context, namespace string, name string) error {
+	userName, _ := ctx.Input.Session("username").(string)
+	return RecordBaselineForUser(backend.NewCloudoneClient(ctx), backend.GetClusterName(ctx), userName, namespace, name)
+}
+
+// RecordForUser is Record for the change made in the background without the request such as the canary promotion
+func RecordForUser(client *backend.Client, cluster string, userName string, namespace string, name string, action string) error {
+	return recordForUser(client, cluster, userName, namespace, name, action, 0)
+}
+
+// RecordBaselineForUser is RecordBaseline for the change made in the background without the request
+func RecordBaselineForUser(client *backend.Client, cluster string, userName string, namespace string, name string) error {
+	deployRevisionSlice, err := GetDeployRevisionSlice(cluster, namespace, name)
+	if err != nil {
+		return err
+	}
+	if len(deployRevisionSlice) > 0 {
+		return nil
+	}
+	return recordForUser(client, cluster, userName, namespace, name, ActionBaseline, 0)
+}
+
+func record(ctx *context.Context, namespace string, name string, action string, sourceRevision int) error {
+	userName, _ := ctx.Input.Session("username").(string)
+	return recordForUser(backend.NewCloudoneClient(ctx), backend.GetClusterName(ctx), userName, namespace, name, action, sourceRevision)
+}
+
+func recordForUser(client *backend.Client, cluster string, userName string, namespace string, name string, action string, sourceRevision int) error {
+	deployInformation, err := getDeployInformation(client, namespace, name)
+	if err != nil {
+		return err
+	}
+	if deployInformation == nil {
+		return errors.New("Deploy " + name + " doesn't exist in namespace " + namespace)
+	}
+
+	return saveRevision(cluster, userName, namespace, name, action, sourceRevision, GetDeployCreateInput(*deployInformation))
+}
+
+// saveRevision creates the key of the next revision only if it doesn't exist so the replicas never overwrite the revision of each other
+func saveRevision(cluster string, userName string, namespace string, name string, action string, sourceRevision int, deployCreateInput backend.DeployCreateInput) error {
+	store := sessionstore.GetStore()
+	for i := 0; i < recordRetryMaximum; i++ {
+		deployRevisionSlice, err := GetDeployRevisionSlice(cluster, namespace, name)
+		if err != nil {
+			return err
+		}
+		revision := 1
+		if len(deployRevisionSlice) > 0 {
+			revision = deployRevisionSlice[0].Revision + 1
+		}
+
+		byteSlice, err := json.Marshal(DeployRevision{
+			Revision:          revision,
+			Cluster:           cluster,
+			Namespace:         namespace,
+			UserName:          userName,
+			Action:            action,
+			SourceRevision:    sourceRevision,
+			CreatedTime:       time.Now(),
+			DeployCreateInput: deployCreateInput,
+		})
+		if err != nil {
+			return err
+		}
+		created, err := store.CompareAndSwap(getKey(cluster, namespace, name, revision), nil, byteSlice, 0)
+		if err != nil {
+			return err
+		}
+		if created == false {
+			// Recorded by another replica at the same time
+			continue
+		}
+
+		// The oldest ones are removed. The new one is not in the slice yet.
+		deployRevisionMaximum := getDeployRevisionMaximum()
+		for i := deployRevisionMaximum - 1; i >= 0 && i < len(deployRevisionSlice); i++ {
+			if err := store.Delete(getKey(cluster, namespace, name, deployRevisionSlice[i].Revision)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return errors.New("Fail to record the revision of deploy " + name + " since the other replicas keep recording it")
+}
+
+// isJSONEqual compares in the JSON format since the maps are decoded from JSON. The empty ones are equal whether they are nil or not.
+func isJSONEqual(a interface{}, b interface{}) bool {
+	aByteSlice, _ := json.Marshal(a)
+	bByteSlice, _ := json.Marshal(b)
+	isEmpty := func(byteSlice []byte) bool {
+		text := string(byteSlice)
+		return text == "null" || text == "[]" || text == "{}"
+	}
+	if isEmpty(aByteSlice) && isEmpty(bByteSlice) {
+		return true
+	}
+	return string(aByteSlice) == string(bByteSlice)
+}
+
+// GetRollbackMode returns how the deploy is changed to the input. The deploy is nil if it doesn't exist.
+func GetRollbackMode(deployInformation *backend.DeployInformation, deployCreateInput backend.DeployCreateInput) string {
+	if deployInformation == nil {
+		return RollbackModeCreate
+	}
+	if isJSONEqual(deployInformation.ContainerPortSlice, deployCreateInput.PortSlice) == false ||
+		isJSONEqual(deployInformation.ResourceMap, deployCreateInput.ResourceMap) == false ||
+		isJSONEqual(deployInformation.ExtraJsonMap, deployCreateInput.ExtraJsonMap) == false ||
+		deployInformation.AutoUpdateForNewBuild != deployCreateInput.AutoUpdateForNewBuild {
+		return RollbackModeRecreate
+	}
+	environmentEqual := isJSONEqual(deployInformation.EnvironmentSlice, deployCreateInput.EnvironmentSlice)
+	if deployInformation.CurrentVersion == deployCreateInput.Version {
+		// The replication controller is named after the version so the same version can't be rolling updated
+		if environmentEqual == false {
+			return RollbackModeRecreate
+		}
+		return RollbackModeResize
+	}
+	return RollbackModeUpdate
+}
+
+// GetRollbackModeDescription describes the mode for the user to confirm
+func GetRollbackModeDescription(rollbackMode string) string {
+	switch rollbackMode {
+	case RollbackModeCreate:
+		return "The deploy doesn't exist so it will be created."
+	case RollbackModeUpdate:
+		return "The deploy will be rolling updated to the version with the environment of the revision."
+	case RollbackModeResize:
+		return "The deploy is in the version and the environment of the revision. Only the size will be applied."
+	case RollbackModeRecreate:
+		return "The ports, the resources or the extra settings differ or the environment differs in the same version. They can't be rolling updated so the deploy will be deleted and created again. The application is unavailable in between."
+	default:
+		return ""
+	}
+}
+
+// GetRollbackPlan returns the revision and the mode applying it to the current deploy
+func GetRollbackPlan(ctx *context.Context, namespace string, name string, revision int) (*DeployRevision, string, error) {
+	deployRevision, err := GetDeployRevision(backend.GetClusterName(ctx), namespace, name, revision)
+	if err != nil {
+		return nil, "", err
+	}
+	deployInformation, err := getDeployInformation(backend.NewCloudoneClient(ctx), namespace, name)
+	if err != nil {
+		return nil, "", err
+	}
+	return deployRevision, GetRollbackMode(deployInformation, deployRevision.DeployCreateInput), nil
+}
+
+// Rollback applies the full input of the revision including the ports, the environment and the resources.
+// The rollback is recorded as a new revision.
+func Rollback(ctx *context.Context, namespace string, name string, revision int) (string, error) {
+	deployRevision, rollbackMode, err := GetRollbackPlan(ctx, namespace, name, revision)
+	if err != nil {
+		return "", err
+	}
+	deployCreateInput := deployRevision.DeployCreateInput
+
+	client := backend.NewCloudoneClient(ctx)
+	switch rollbackMode {
+	case RollbackModeCreate:
+		err = client.CreateDeploy(namespace, deployCreateInput)
+	case RollbackModeRecreate:
+		userName, _ := ctx.Input.Session("username").(string)
+		err = recreate(client, backend.GetClusterName(ctx), userName, namespace, deployCreateInput)
+	case RollbackModeUpdate, RollbackModeResize:
+		err = rollbackInPlace(client, namespace, rollbackMode, deployCreateInput)
+	}
+	if err != nil {
+		return rollbackMode, err
+	}
+
+	if err := record(ctx, namespace, name, ActionRollback, revision); err != nil {
+		return "", errors.New("Deploy " + name + " is rolled back but fail to record the revision with error " + err.Error())
+	}
+	return rollbackMode, nil
+}
+
+// recreate deletes the deploy and creates it from the input. The deploy before the deletion is kept as the latest revision
+// first so it is created again if the input fails. The rollback could be retried from the history if even that fails.
+func recreate(client *backend.Client, cluster string, userName string, namespace string, deployCreateInput backend.DeployCreateInput) error {
+	name := deployCreateInput.ImageInformationName
+	deployInformation, err := getDeployInformation(client, namespace, name)
+	if err != nil {
+		return err
+	}
+	if deployInformation == nil {
+		return errors.New("Deploy " + name + " doesn't exist in namespace " + namespace)
+	}
+	currentDeployCreateInput := GetDeployCreateInput(*deployInformation)
+
+	deployRevisionSlice, err := GetDeployRevisionSlice(cluster, namespace, name)
+	if err != nil {
+		return err
+	}
+	if len(deployRevisionSlice) == 0 || isJSONEqual(deployRevisionSlice[0].DeployCreateInput, currentDeployCreateInput) == false {
+		if err := saveRevision(cluster, userName, namespace, name, ActionBaseline, 0, currentDeployCreateInput); err != nil {
+			return errors.New("Fail to keep deploy " + name + " before it is deleted with error " + err.Error())
+		}
+	}
+
+	if err := client.DeleteDeploy(namespace, name); err != nil {
+		return err
+	}
+	createError := client.CreateDeploy(namespace, deployCreateInput)
+	if createError == nil {
+		return nil
+	}
+	if restoreError := client.CreateDeploy(namespace, currentDeployCreateInput); restoreError != nil {
+		return RestoreError{"Deploy " + name + " is deleted but fail to create it from the revision with error " + createError.Error() +
+			" and fail to restore it with error " + restoreError.Error() + ". Roll back to any revision to create it."}
+	}
+	return errors.New("Fail to create deploy " + name + " from the revision with error " + createError.Error() + ". The deploy is restored.")
+}
+
+func rollbackInPlace(client *backend.Client, namespace string, rollbackMode string, deployCreateInput backend.DeployCreateInput) error {
+	deployInformation, err := getDeployInformation(client, namespace, deployCreateInput.ImageInformationName)
+	if err != nil {
+		return err
+	}
+	if deployInformation == nil {
+		return errors.New("Deploy " + deployCreateInput.ImageInformationName + " doesn't exist in namespace " + namespace)
+	}
+
+	if rollbackMode == RollbackModeUpdate {
+		err := client.UpdateDeploy(namespace, backend.DeployUpdateInput{
+			ImageInformationName: deployCreateInput.ImageInformationName,
+			Version:              deployCreateInput.Version,
+			Description:          deployCreateInput.Description,
+			EnvironmentSlice:     deployCreateInput.EnvironmentSlice,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if deployInformation.ReplicaAmount != deployCreateInput.ReplicaAmount {
+		return client.ResizeDeploy(namespace, deployCreateInput.ImageInformationName, deployCreateInput.ReplicaAmount)
+	}
+	return nil
+}
+
+// GetEnvironmentText shows the environment in one line such as A=1, B=2
+func GetEnvironmentText(environmentSlice []backend.ReplicationControllerContainerEnvironment) string {
+	textSlice := make([]string, 0)
+	for _, environment := range environmentSlice {
+		textSlice = append(textSlice, environment.Name+"="+environment.Value)
+	}
+	return strings.Join(textSlice, ", ")
+}
+
+// GetPortText shows the ports in one line such as http 80:31080/TCP
+func GetPortText(portSlice []backend.DeployContainerPort) string {
+	textSlice := make([]string, 0)
+	for _, port := range portSlice {
+		text := port.Name + " " + strconv.Itoa(port.ContainerPort)
+		if port.NodePort > 0 {
+			text += ":" + strconv.Itoa(port.NodePort)
+		}
+		if port.Protocol != "" {
+			text += "/" + port.Protocol
+		}
+		textSlice = append(textSlice, text)
+	}
+	return strings.Join(textSlice, ", ")
+}
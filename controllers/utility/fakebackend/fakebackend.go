@@ -0,0 +1,243 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fakebackend is the in-memory fake of cloudone and cloudone_analysis used by the demo mode and the endpoint tests.
+// It serves the /api/v1 endpoints called by the GUI with the seeded fixtures which could be changed through the GUI.
+package fakebackend
+
+import (
+	"encoding/json"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/configuration"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DemoUserName and DemoPassword log in the seeded user with all the permissions
+	DemoUserName = "admin"
+	DemoPassword = "admin"
+
+	apiPrefix       = "/api/v1/"
+	tokenHeaderName = "token"
+)
+
+var componentSlice = []string{
+	"cloudone",
+	"cloudoneAnalysis",
+}
+
+type account struct {
+	user     rbac.User
+	password string
+}
+
+type replicationController struct {
+	replicationController backend.ReplicationController
+	podSlice              []backend.Pod
+	createdTime           time.Time
+}
+
+// Backend is the fake keeping the data in memory. It is safe to be used by the concurrent requests.
+type Backend struct {
+	lock                        sync.Mutex
+	lastID                      int
+	namespaceSlice              []string
+	deployInformationMap        map[string]map[string]*backend.DeployInformation
+	replicationControllerMap    map[string]map[string]*replicationController
+	serviceMap                  map[string]map[string]*backend.Service
+	deployBlueGreenMap          map[string]*backend.DeployBlueGreen
+	clusterMap                  map[string]*backend.Cluster
+	deployClusterApplicationMap map[string]map[string]*backend.DeployClusterApplication
+	eventSlice                  []map[string]interface{}
+	imageInformationSlice       []backend.ImageInformation
+	imageRecordMap              map[string][]backend.ImageRecord
+	regionSlice                 []backend.Region
+	userMap                     map[string]*account
+	roleSlice                   []rbac.Role
+	tokenMap                    map[string]string
+	auditLogSlice               []backend.AuditLog
+	// The next deploy creations fail by this amount
+	deployCreateFailureAmount int
+}
+
+// New creates the fake seeded with the fixtures
+func New() *Backend {
+	fake := &Backend{}
+	fake.Reset()
+	return fake
+}
+
+// Reset drops the changes and seeds the fixtures again so each test starts from the same data
+func (fake *Backend) Reset() {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	fake.seed()
+}
+
+// nextID must be called with the lock held. It is used instead of the random names so the tests could predict them.
+func (fake *Backend) nextID() int {
+	fake.lastID++
+	return fake.lastID
+}
+
+type handler func(fake *Backend, request *http.Request, segmentSlice []string) (int, interface{})
+
+// handlerMap is keyed by the first segment after /api/v1/
+var handlerMap = map[string]handler{
+	"auditlogs":                    (*Backend).handleAuditLog,
+	"authorizations":               (*Backend).handleAuthorization,
+	"clusterapplications":          (*Backend).handleClusterApplication,
+	"deploybluegreens":             (*Backend).handleDeployBlueGreen,
+	"deployclusterapplications":    (*Backend).handleDeployClusterApplication,
+	"deploys":                      (*Backend).handleDeploy,
+	"healthchecks":                 (*Backend).handleHealthCheck,
+	"historicalevents":             (*Backend).handleHistoricalEvent,
+	"imageinformations":            (*Backend).handleImageInformation,
+	"imagerecords":                 (*Backend).handleImageRecord,
+	"namespaces":                   (*Backend).handleNamespace,
+	"nodemetrics":                  (*Backend).handleNodeMetric,
+	"nodes":                        (*Backend).handleNode,
+	"pods":                         (*Backend).handlePod,
+	"replicationcontrollermetrics": (*Backend).handleReplicationControllerMetric,
+	"replicationcontrollers":       (*Backend).handleReplicationController,
+	"services":                     (*Backend).handleService,
+}
+
+func (fake *Backend) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	statusCode, responseData := fake.serve(request)
+
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.WriteHeader(statusCode)
+	if responseData != nil {
+		json.NewEncoder(responseWriter).Encode(responseData)
+	}
+}
+
+func (fake *Backend) serve(request *http.Request) (int, interface{}) {
+	if strings.HasPrefix(request.URL.Path, apiPrefix) == false {
+		return notFound(request.URL.Path)
+	}
+	segmentSlice := strings.Split(strings.Trim(strings.TrimPrefix(request.URL.Path, apiPrefix), "/"), "/")
+
+	handler, ok := handlerMap[segmentSlice[0]]
+	if ok == false {
+		return errorResponse(http.StatusNotFound, "Not implemented", request.Method+" "+request.URL.Path+" is not served by the fake backend")
+	}
+
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	// The health check and the login don't have the token
+	if segmentSlice[0] != "healthchecks" && isTokenRequest(request, segmentSlice) == false {
+		if _, ok := fake.tokenMap[request.Header.Get(tokenHeaderName)]; ok == false {
+			return errorResponse(http.StatusUnauthorized, "Token doesn't exist", "")
+		}
+	}
+
+	// The collection such as /api/v1/namespaces is served with the empty segment
+	if len(segmentSlice) == 1 {
+		segmentSlice = append(segmentSlice, "")
+	}
+	return handler(fake, request, segmentSlice[1:])
+}
+
+// errorResponse is in the format of cloudone so backend.GetResponseError and identity.IsTokenInvalid work
+func errorResponse(statusCode int, errorText string, errorMessage string) (int, interface{}) {
+	jsonMap := map[string]interface{}{
+		"Error": errorText,
+	}
+	if errorMessage != "" {
+		jsonMap["ErrorMessage"] = errorMessage
+	}
+	return statusCode, jsonMap
+}
+
+// notFound is recognized by backend.IsKeyNotFound
+func notFound(key string) (int, interface{}) {
+	return errorResponse(http.StatusNotFound, "Key not found", "100: Key not found ("+key+")")
+}
+
+func badRequest(errorText string) (int, interface{}) {
+	return errorResponse(http.StatusBadRequest, errorText, "")
+}
+
+func methodNotAllowed(request *http.Request) (int, interface{}) {
+	return errorResponse(http.StatusMethodNotAllowed, "Method not allowed", request.Method+" "+request.URL.Path+" is not served by the fake backend")
+}
+
+func success(responseData interface{}) (int, interface{}) {
+	return http.StatusOK, responseData
+}
+
+func decodeBody(request *http.Request, body interface{}) error {
+	return json.NewDecoder(request.Body).Decode(body)
+}
+
+func getQueryInt(request *http.Request, name string, defaultValue int) int {
+	value, err := strconv.Atoi(request.URL.Query().Get(name))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// Configure points cloudone and cloudone_analysis of all clusters to the fake listening on the address such as 127.0.0.1:8081
+func Configure(address string) error {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	for _, cluster := range backend.GetClusterNameSlice() {
+		for _, component := range componentSlice {
+			for key, value := range map[string]string{
+				component + "Protocol": "http",
+				component + "Host":     host,
+				component + "Port":     port,
+			} {
+				if err := configuration.Set(backend.GetClusterConfigName(cluster, key), value, configuration.SourceDemo); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	// The seeded users are in the fake so the external identity providers are not used
+	return configuration.Set("identityProvider", "cloudone", configuration.SourceDemo)
+}
+
+// IsDemoMode is true when demoMode is set so the GUI runs without cloudone, cloudone_analysis and kubernetes
+func IsDemoMode() bool {
+	return beego.AppConfig.DefaultBool("demoMode", false)
+}
+
+// StartDemo serves the fake on the loopback interface and points the GUI to it
+func StartDemo() error {
+	listener, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(beego.AppConfig.DefaultInt("demoBackendPort", 0)))
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := http.Serve(listener, New()); err != nil {
+			beego.Error("The fake backend stops with error " + err.Error())
+		}
+	}()
+	beego.Warning("Demo mode uses the fake backend on " + listener.Addr().String() + ". Login with " + DemoUserName + "/" + DemoPassword)
+	return Configure(listener.Addr().String())
+}
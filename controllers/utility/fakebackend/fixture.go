@@ -0,0 +1,146 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakebackend
+
+import (
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"strconv"
+	"time"
+)
+
+const (
+	fixturePrivateRegistry = "private-registry:31000"
+)
+
+// seed must be called with the lock held. The fixtures are a small cluster of three nodes running two applications.
+// The web frontend is also deployed in namespace demo as the idle side of its blue green deployment.
+func (fake *Backend) seed() {
+	fake.lastID = 0
+	fake.deployCreateFailureAmount = 0
+	fake.namespaceSlice = []string{"default", "demo"}
+	fake.deployInformationMap = make(map[string]map[string]*backend.DeployInformation)
+	fake.replicationControllerMap = make(map[string]map[string]*replicationController)
+	fake.serviceMap = make(map[string]map[string]*backend.Service)
+	fake.clusterMap = make(map[string]*backend.Cluster)
+	fake.deployClusterApplicationMap = make(map[string]map[string]*backend.DeployClusterApplication)
+	for _, namespace := range fake.namespaceSlice {
+		fake.deployInformationMap[namespace] = make(map[string]*backend.DeployInformation)
+		fake.replicationControllerMap[namespace] = make(map[string]*replicationController)
+		fake.serviceMap[namespace] = make(map[string]*backend.Service)
+		fake.deployClusterApplicationMap[namespace] = make(map[string]*backend.DeployClusterApplication)
+	}
+	fake.eventSlice = make([]map[string]interface{}, 0)
+
+	fake.regionSlice = []backend.Region{
+		backend.Region{Name: "region-a", LocationTagged: true, ZoneSlice: []backend.Zone{
+			backend.Zone{Name: "zone-1", LocationTagged: true, NodeSlice: []backend.Node{
+				backend.Node{Name: "node-1", Address: "10.0.0.11", Capacity: backend.Capacity{Cpu: "4", Memory: "8Gi"}},
+				backend.Node{Name: "node-2", Address: "10.0.0.12", Capacity: backend.Capacity{Cpu: "4", Memory: "8Gi"}},
+			}},
+			backend.Zone{Name: "zone-2", LocationTagged: true, NodeSlice: []backend.Node{
+				backend.Node{Name: "node-3", Address: "10.0.0.13", Capacity: backend.Capacity{Cpu: "8", Memory: "16Gi"}},
+			}},
+		}},
+	}
+
+	now := time.Now()
+	fake.imageInformationSlice = make([]backend.ImageInformation, 0)
+	fake.imageRecordMap = make(map[string][]backend.ImageRecord)
+	for _, fixture := range []struct {
+		name         string
+		description  string
+		versionCount int
+	}{
+		{"web", "Web frontend built from git", 3},
+		{"api", "REST API service", 2},
+	} {
+		imageRecordSlice := make([]backend.ImageRecord, 0)
+		for i := 1; i <= fixture.versionCount; i++ {
+			version := "v" + strconv.Itoa(i)
+			imageRecordSlice = append(imageRecordSlice, backend.ImageRecord{
+				ImageInformation: fixture.name,
+				Version:          version,
+				Path:             fixturePrivateRegistry + "/" + fixture.name + ":" + version,
+				VersionInfo:      map[string]string{"commit": fixture.name + "-" + version},
+				Environment:      map[string]string{},
+				Description:      "Build " + version + " of " + fixture.name,
+				CreatedTime:      now.Add(-time.Duration(24*(fixture.versionCount-i+1)) * time.Hour).Format(time.RFC3339),
+			})
+		}
+		fake.imageRecordMap[fixture.name] = imageRecordSlice
+		fake.imageInformationSlice = append(fake.imageInformationSlice, backend.ImageInformation{
+			Name:           fixture.name,
+			Kind:           "git",
+			Description:    fixture.description,
+			CurrentVersion: "v" + strconv.Itoa(fixture.versionCount),
+			BuildParameter: map[string]string{"sourceCodeURL": "https://example.com/demo/" + fixture.name + ".git"},
+		})
+	}
+
+	fake.createDeploy("default", backend.DeployCreateInput{
+		ImageInformationName: "web",
+		Version:              "v3",
+		Description:          "Web frontend",
+		ReplicaAmount:        2,
+		PortSlice:            []backend.DeployContainerPort{backend.DeployContainerPort{Name: "http", ContainerPort: 80, NodePort: 31080, Protocol: "TCP"}},
+		EnvironmentSlice:     []backend.ReplicationControllerContainerEnvironment{backend.ReplicationControllerContainerEnvironment{Name: "API_HOST", Value: "api"}},
+	})
+	fake.createDeploy("default", backend.DeployCreateInput{
+		ImageInformationName: "api",
+		Version:              "v2",
+		Description:          "REST API service",
+		ReplicaAmount:        1,
+		PortSlice:            []backend.DeployContainerPort{backend.DeployContainerPort{Name: "http", ContainerPort: 8080, Protocol: "TCP"}},
+	})
+	fake.createDeploy("demo", backend.DeployCreateInput{
+		ImageInformationName: "web",
+		Version:              "v3",
+		Description:          "Web frontend",
+		ReplicaAmount:        2,
+		PortSlice:            []backend.DeployContainerPort{backend.DeployContainerPort{Name: "http", ContainerPort: 80, Protocol: "TCP"}},
+		EnvironmentSlice:     []backend.ReplicationControllerContainerEnvironment{backend.ReplicationControllerContainerEnvironment{Name: "API_HOST", Value: "api.default"}},
+	})
+	fake.deployBlueGreenMap = map[string]*backend.DeployBlueGreen{
+		"web": &backend.DeployBlueGreen{
+			ImageInformation: "web",
+			Namespace:        "default",
+			NodePort:         31090,
+			Description:      "Web frontend",
+			SessionAffinity:  "",
+		},
+	}
+
+	adminRole := rbac.Role{
+		Name:            "admin",
+		PermissionSlice: []*rbac.Permission{&rbac.Permission{Name: "all", Component: "*", Method: "*", Path: "*"}},
+		Description:     "All permissions",
+	}
+	fake.roleSlice = []rbac.Role{adminRole}
+	fake.userMap = map[string]*account{
+		DemoUserName: &account{
+			user: rbac.User{
+				Name:          DemoUserName,
+				RoleSlice:     []*rbac.Role{&adminRole},
+				ResourceSlice: []*rbac.Resource{&rbac.Resource{Name: "namespace_*", Component: "*", Path: "/namespaces/"}},
+				Description:   "Demo administrator",
+				MetaDataMap:   map[string]string{},
+			},
+			password: DemoPassword,
+		},
+	}
+	fake.tokenMap = make(map[string]string)
+	fake.auditLogSlice = make([]backend.AuditLog, 0)
+}
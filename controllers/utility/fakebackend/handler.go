@@ -0,0 +1,1130 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fakebackend
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+func isTokenRequest(request *http.Request, segmentSlice []string) bool {
+	return segmentSlice[0] == "authorizations" && len(segmentSlice) > 1 && segmentSlice[1] == "tokens"
+}
+
+func (fake *Backend) isNamespaceExisting(namespace string) bool {
+	for _, existing := range fake.namespaceSlice {
+		if existing == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+func (fake *Backend) handleNamespace(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch {
+	case request.Method == "GET" && segmentSlice[0] == "":
+		namespaceSlice := append([]string{}, fake.namespaceSlice...)
+		sort.Strings(namespaceSlice)
+		return success(namespaceSlice)
+	case request.Method == "POST" && segmentSlice[0] == "":
+		namespace := backend.Namespace{}
+		if err := decodeBody(request, &namespace); err != nil || namespace.Name == "" {
+			return badRequest("Namespace name is required")
+		}
+		if fake.isNamespaceExisting(namespace.Name) {
+			return badRequest("Namespace " + namespace.Name + " already exists")
+		}
+		fake.namespaceSlice = append(fake.namespaceSlice, namespace.Name)
+		fake.deployInformationMap[namespace.Name] = make(map[string]*backend.DeployInformation)
+		fake.replicationControllerMap[namespace.Name] = make(map[string]*replicationController)
+		fake.serviceMap[namespace.Name] = make(map[string]*backend.Service)
+		fake.deployClusterApplicationMap[namespace.Name] = make(map[string]*backend.DeployClusterApplication)
+		return success(map[string]interface{}{})
+	case request.Method == "DELETE" && len(segmentSlice) == 1:
+		namespace := segmentSlice[0]
+		if fake.isNamespaceExisting(namespace) == false {
+			return notFound("/namespaces/" + namespace)
+		}
+		if namespace == "default" {
+			return badRequest("Namespace default can't be deleted")
+		}
+		namespaceSlice := make([]string, 0)
+		for _, existing := range fake.namespaceSlice {
+			if existing != namespace {
+				namespaceSlice = append(namespaceSlice, existing)
+			}
+		}
+		fake.namespaceSlice = namespaceSlice
+		delete(fake.deployInformationMap, namespace)
+		delete(fake.replicationControllerMap, namespace)
+		delete(fake.serviceMap, namespace)
+		delete(fake.deployClusterApplicationMap, namespace)
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+func (fake *Backend) getNodeSlice() []backend.Node {
+	nodeSlice := make([]backend.Node, 0)
+	for _, region := range fake.regionSlice {
+		for _, zone := range region.ZoneSlice {
+			nodeSlice = append(nodeSlice, zone.NodeSlice...)
+		}
+	}
+	return nodeSlice
+}
+
+// createPod schedules the pod to the nodes in turn
+func (fake *Backend) createPod(namespace string, replicationController backend.ReplicationController) backend.Pod {
+	id := fake.nextID()
+	nodeSlice := fake.getNodeSlice()
+	nodeIndex := id % len(nodeSlice)
+
+	containerSlice := make([]backend.PodContainer, 0)
+	for _, container := range replicationController.ContainerSlice {
+		portSlice := make([]backend.PodContainerPort, 0)
+		for _, port := range container.PortSlice {
+			portSlice = append(portSlice, backend.PodContainerPort{Name: port.Name, ContainerPort: port.ContainerPort, Protocol: "TCP"})
+		}
+		containerSlice = append(containerSlice, backend.PodContainer{
+			Name:        container.Name,
+			Image:       container.Image,
+			ContainerID: fmt.Sprintf("docker://%064x", id),
+			Ready:       true,
+			PortSlice:   portSlice,
+		})
+	}
+
+	return backend.Pod{
+		Name:           fmt.Sprintf("%s-%05d", replicationController.Name, id),
+		Namespace:      namespace,
+		HostIP:         nodeSlice[nodeIndex].Address,
+		PodIP:          fmt.Sprintf("10.244.%d.%d", nodeIndex+1, id%250+2),
+		Phase:          "Running",
+		ContainerSlice: containerSlice,
+	}
+}
+
+// putReplicationController replaces all the pods like the rolling update
+func (fake *Backend) putReplicationController(namespace string, spec backend.ReplicationController) {
+	podSlice := make([]backend.Pod, 0)
+	for i := 0; i < spec.ReplicaAmount; i++ {
+		podSlice = append(podSlice, fake.createPod(namespace, spec))
+	}
+	fake.replicationControllerMap[namespace][spec.Name] = &replicationController{spec, podSlice, time.Now()}
+}
+
+func (fake *Backend) resizeReplicationController(namespace string, replicationController *replicationController, size int) {
+	for len(replicationController.podSlice) < size {
+		replicationController.podSlice = append(replicationController.podSlice, fake.createPod(namespace, replicationController.replicationController))
+	}
+	replicationController.podSlice = replicationController.podSlice[:size]
+	replicationController.replicationController.ReplicaAmount = size
+}
+
+func getReplicationControllerAndRelatedPod(namespace string, replicationController *replicationController) backend.ReplicationControllerAndRelatedPod {
+	age := time.Since(replicationController.createdTime).Truncate(time.Second).String()
+	podSlice := make([]backend.Pod, 0)
+	for _, pod := range replicationController.podSlice {
+		pod.Age = age
+		podSlice = append(podSlice, pod)
+	}
+	spec := replicationController.replicationController
+	return backend.ReplicationControllerAndRelatedPod{
+		Name:               spec.Name,
+		Namespace:          namespace,
+		ReplicaAmount:      spec.ReplicaAmount,
+		AliveReplicaAmount: len(podSlice),
+		Selector:           map[string]string{"name": spec.Selector.Name, "version": spec.Selector.Version},
+		Label:              map[string]string{"name": spec.Label.Name},
+		PodSlice:           podSlice,
+	}
+}
+
+// getSortedNameSlice returns the names of the replication controllers or deploys in order
+func getSortedNameSlice(length int, forEach func(func(string))) []string {
+	nameSlice := make([]string, 0, length)
+	forEach(func(name string) {
+		nameSlice = append(nameSlice, name)
+	})
+	sort.Strings(nameSlice)
+	return nameSlice
+}
+
+func (fake *Backend) handleReplicationController(request *http.Request, segmentSlice []string) (int, interface{}) {
+	if (segmentSlice[0] == "size" || segmentSlice[0] == "json") && len(segmentSlice) == 3 && request.Method == "PUT" {
+		replicationController, ok := fake.replicationControllerMap[segmentSlice[1]][segmentSlice[2]]
+		if ok == false {
+			return notFound("/replicationcontrollers/" + segmentSlice[1] + "/" + segmentSlice[2])
+		}
+		jsonMap := make(map[string]interface{})
+		if err := decodeBody(request, &jsonMap); err != nil {
+			return badRequest(err.Error())
+		}
+		// The size is Size in the body of size and spec.replicas in the kubernetes json
+		size, ok := jsonMap["Size"].(float64)
+		if specJsonMap, isMap := jsonMap["spec"].(map[string]interface{}); isMap {
+			size, ok = specJsonMap["replicas"].(float64)
+		}
+		if ok && size >= 0 {
+			fake.resizeReplicationController(segmentSlice[1], replicationController, int(size))
+		}
+		return success(map[string]interface{}{})
+	}
+
+	namespace := segmentSlice[0]
+	replicationControllerMap, ok := fake.replicationControllerMap[namespace]
+	if ok == false {
+		return notFound("/namespaces/" + namespace)
+	}
+
+	switch {
+	case request.Method == "GET" && len(segmentSlice) == 1:
+		replicationControllerAndRelatedPodSlice := make([]backend.ReplicationControllerAndRelatedPod, 0)
+		for _, name := range getSortedNameSlice(len(replicationControllerMap), func(add func(string)) {
+			for name := range replicationControllerMap {
+				add(name)
+			}
+		}) {
+			replicationControllerAndRelatedPodSlice = append(replicationControllerAndRelatedPodSlice, getReplicationControllerAndRelatedPod(namespace, replicationControllerMap[name]))
+		}
+		return success(replicationControllerAndRelatedPodSlice)
+	case request.Method == "GET" && len(segmentSlice) == 2:
+		replicationController, ok := replicationControllerMap[segmentSlice[1]]
+		if ok == false {
+			return notFound("/replicationcontrollers/" + namespace + "/" + segmentSlice[1])
+		}
+		return success(replicationController.replicationController)
+	case request.Method == "POST" && len(segmentSlice) == 1:
+		spec := backend.ReplicationController{}
+		if err := decodeBody(request, &spec); err != nil || spec.Name == "" {
+			return badRequest("Replication controller name is required")
+		}
+		if _, ok := replicationControllerMap[spec.Name]; ok {
+			return badRequest("Replication controller " + spec.Name + " already exists")
+		}
+		fake.putReplicationController(namespace, spec)
+		return success(map[string]interface{}{})
+	case request.Method == "DELETE" && len(segmentSlice) == 2:
+		if _, ok := replicationControllerMap[segmentSlice[1]]; ok == false {
+			return notFound("/replicationcontrollers/" + namespace + "/" + segmentSlice[1])
+		}
+		delete(replicationControllerMap, segmentSlice[1])
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+func (fake *Backend) handlePod(request *http.Request, segmentSlice []string) (int, interface{}) {
+	if len(segmentSlice) < 2 {
+		return methodNotAllowed(request)
+	}
+	namespace := segmentSlice[0]
+	name := segmentSlice[1]
+	for _, replicationController := range fake.replicationControllerMap[namespace] {
+		for i, pod := range replicationController.podSlice {
+			if pod.Name != name {
+				continue
+			}
+			switch {
+			case request.Method == "DELETE" && len(segmentSlice) == 2:
+				// The replication controller creates another one like kubernetes
+				replicationController.podSlice[i] = fake.createPod(namespace, replicationController.replicationController)
+				return success(nil)
+			case request.Method == "GET" && len(segmentSlice) == 3 && segmentSlice[2] == "logs":
+				logJsonMap := make(map[string]interface{})
+				for _, container := range pod.ContainerSlice {
+					logJsonMap[container.Name] = "Container " + container.Name + " of pod " + pod.Name + " started with image " + container.Image + "\n" +
+						"Listening on the ports of the container\n"
+				}
+				return success(logJsonMap)
+			default:
+				return methodNotAllowed(request)
+			}
+		}
+	}
+	return notFound("/pods/" + namespace + "/" + name)
+}
+
+func (fake *Backend) getImageRecord(imageInformationName string, version string) (*backend.ImageRecord, bool) {
+	for _, imageRecord := range fake.imageRecordMap[imageInformationName] {
+		if imageRecord.Version == version {
+			return &imageRecord, true
+		}
+	}
+	return nil, false
+}
+
+func getReplicationControllerContainerPortSlice(deployContainerPortSlice []backend.DeployContainerPort) []backend.ReplicationControllerContainerPort {
+	portSlice := make([]backend.ReplicationControllerContainerPort, 0)
+	for _, port := range deployContainerPortSlice {
+		portSlice = append(portSlice, backend.ReplicationControllerContainerPort{Name: port.Name, ContainerPort: port.ContainerPort})
+	}
+	return portSlice
+}
+
+// createDeploy creates the deploy, its replication controller named after the image information and the version and
+// the service selecting all the versions by the name like cloudone
+func (fake *Backend) createDeploy(namespace string, deployCreateInput backend.DeployCreateInput) (int, interface{}) {
+	deployInformationMap, ok := fake.deployInformationMap[namespace]
+	if ok == false {
+		return notFound("/namespaces/" + namespace)
+	}
+	if fake.deployCreateFailureAmount > 0 {
+		fake.deployCreateFailureAmount--
+		return errorResponse(http.StatusInternalServerError, "Injected failure of the deploy creation", "")
+	}
+	name := deployCreateInput.ImageInformationName
+	imageRecord, ok := fake.getImageRecord(name, deployCreateInput.Version)
+	if ok == false {
+		return notFound("/imagerecords/" + name + "/" + deployCreateInput.Version)
+	}
+	if _, ok := deployInformationMap[name]; ok {
+		return badRequest("Deploy " + name + " already exists in namespace " + namespace)
+	}
+
+	deployInformationMap[name] = &backend.DeployInformation{
+		Namespace:                 namespace,
+		ImageInformationName:      name,
+		CurrentVersion:            deployCreateInput.Version,
+		CurrentVersionDescription: imageRecord.Description,
+		Description:               deployCreateInput.Description,
+		ReplicaAmount:             deployCreateInput.ReplicaAmount,
+		ContainerPortSlice:        deployCreateInput.PortSlice,
+		EnvironmentSlice:          deployCreateInput.EnvironmentSlice,
+		ResourceMap:               deployCreateInput.ResourceMap,
+		ExtraJsonMap:              deployCreateInput.ExtraJsonMap,
+		AutoUpdateForNewBuild:     deployCreateInput.AutoUpdateForNewBuild,
+		CreatedTime:               time.Now(),
+	}
+	fake.putReplicationController(namespace, backend.ReplicationController{
+		Name:          name + deployCreateInput.Version,
+		ReplicaAmount: deployCreateInput.ReplicaAmount,
+		Selector:      backend.ReplicationControllerSelector{Name: name, Version: deployCreateInput.Version},
+		Label:         backend.ReplicationControllerLabel{Name: name},
+		ContainerSlice: []backend.ReplicationControllerContainer{
+			backend.ReplicationControllerContainer{
+				Name:             name,
+				Image:            imageRecord.Path,
+				PortSlice:        getReplicationControllerContainerPortSlice(deployCreateInput.PortSlice),
+				EnvironmentSlice: deployCreateInput.EnvironmentSlice,
+			},
+		},
+	})
+	servicePortSlice := make([]backend.ServicePort, 0)
+	for _, port := range deployCreateInput.PortSlice {
+		servicePortSlice = append(servicePortSlice, backend.ServicePort{
+			Name:       port.Name,
+			Protocol:   port.Protocol,
+			Port:       port.ContainerPort,
+			TargetPort: strconv.Itoa(port.ContainerPort),
+			NodePort:   port.NodePort,
+		})
+	}
+	fake.serviceMap[namespace][name] = &backend.Service{
+		Name:            name,
+		Namespace:       namespace,
+		PortSlice:       servicePortSlice,
+		Selector:        map[string]interface{}{"name": name},
+		ClusterIP:       fmt.Sprintf("10.0.0.%d", len(fake.serviceMap[namespace])+2),
+		LabelMap:        map[string]interface{}{"name": name},
+		SessionAffinity: "None",
+	}
+	return success(map[string]interface{}{})
+}
+
+func (fake *Backend) updateDeploy(namespace string, deployUpdateInput backend.DeployUpdateInput) (int, interface{}) {
+	name := deployUpdateInput.ImageInformationName
+	deployInformation, ok := fake.deployInformationMap[namespace][name]
+	if ok == false {
+		return notFound("/deploys/" + namespace + "/" + name)
+	}
+	imageRecord, ok := fake.getImageRecord(name, deployUpdateInput.Version)
+	if ok == false {
+		return notFound("/imagerecords/" + name + "/" + deployUpdateInput.Version)
+	}
+
+	oldReplicationControllerName := name + deployInformation.CurrentVersion
+	deployInformation.CurrentVersion = deployUpdateInput.Version
+	deployInformation.CurrentVersionDescription = imageRecord.Description
+	deployInformation.Description = deployUpdateInput.Description
+	if deployUpdateInput.EnvironmentSlice != nil {
+		deployInformation.EnvironmentSlice = deployUpdateInput.EnvironmentSlice
+	}
+
+	if replicationController, ok := fake.replicationControllerMap[namespace][oldReplicationControllerName]; ok {
+		delete(fake.replicationControllerMap[namespace], oldReplicationControllerName)
+		spec := replicationController.replicationController
+		spec.Name = name + deployUpdateInput.Version
+		spec.Selector.Version = deployUpdateInput.Version
+		containerSlice := make([]backend.ReplicationControllerContainer, 0)
+		for _, container := range spec.ContainerSlice {
+			container.Image = imageRecord.Path
+			container.EnvironmentSlice = deployInformation.EnvironmentSlice
+			containerSlice = append(containerSlice, container)
+		}
+		spec.ContainerSlice = containerSlice
+		fake.putReplicationController(namespace, spec)
+	}
+	return success(map[string]interface{}{})
+}
+
+func (fake *Backend) handleDeploy(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch {
+	case request.Method == "GET" && len(segmentSlice) == 1:
+		namespaceSlice := append([]string{}, fake.namespaceSlice...)
+		if segmentSlice[0] != "" {
+			if fake.isNamespaceExisting(segmentSlice[0]) == false {
+				return notFound("/namespaces/" + segmentSlice[0])
+			}
+			namespaceSlice = []string{segmentSlice[0]}
+		}
+		sort.Strings(namespaceSlice)
+		deployInformationSlice := make([]backend.DeployInformation, 0)
+		for _, namespace := range namespaceSlice {
+			deployInformationMap := fake.deployInformationMap[namespace]
+			for _, name := range getSortedNameSlice(len(deployInformationMap), func(add func(string)) {
+				for name := range deployInformationMap {
+					add(name)
+				}
+			}) {
+				deployInformationSlice = append(deployInformationSlice, *deployInformationMap[name])
+			}
+		}
+		return success(deployInformationSlice)
+	case request.Method == "POST" && len(segmentSlice) == 2 && segmentSlice[0] == "create":
+		deployCreateInput := backend.DeployCreateInput{}
+		if err := decodeBody(request, &deployCreateInput); err != nil {
+			return badRequest(err.Error())
+		}
+		return fake.createDeploy(segmentSlice[1], deployCreateInput)
+	case request.Method == "PUT" && len(segmentSlice) == 2 && segmentSlice[0] == "update":
+		deployUpdateInput := backend.DeployUpdateInput{}
+		if err := decodeBody(request, &deployUpdateInput); err != nil {
+			return badRequest(err.Error())
+		}
+		return fake.updateDeploy(segmentSlice[1], deployUpdateInput)
+	case request.Method == "PUT" && len(segmentSlice) == 3 && segmentSlice[0] == "resize":
+		deployInformation, ok := fake.deployInformationMap[segmentSlice[1]][segmentSlice[2]]
+		if ok == false {
+			return notFound("/deploys/" + segmentSlice[1] + "/" + segmentSlice[2])
+		}
+		size := getQueryInt(request, "size", -1)
+		if size < 0 {
+			return badRequest("Size must be a non-negative number")
+		}
+		deployInformation.ReplicaAmount = size
+		if replicationController, ok := fake.replicationControllerMap[segmentSlice[1]][segmentSlice[2]+deployInformation.CurrentVersion]; ok {
+			fake.resizeReplicationController(segmentSlice[1], replicationController, size)
+		}
+		return success(nil)
+	case request.Method == "DELETE" && len(segmentSlice) == 2:
+		deployInformation, ok := fake.deployInformationMap[segmentSlice[0]][segmentSlice[1]]
+		if ok == false {
+			return notFound("/deploys/" + segmentSlice[0] + "/" + segmentSlice[1])
+		}
+		delete(fake.deployInformationMap[segmentSlice[0]], segmentSlice[1])
+		delete(fake.replicationControllerMap[segmentSlice[0]], segmentSlice[1]+deployInformation.CurrentVersion)
+		delete(fake.serviceMap[segmentSlice[0]], segmentSlice[1])
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+func (fake *Backend) handleService(request *http.Request, segmentSlice []string) (int, interface{}) {
+	namespace := segmentSlice[0]
+	serviceMap, ok := fake.serviceMap[namespace]
+	if ok == false {
+		return notFound("/namespaces/" + namespace)
+	}
+
+	switch {
+	case request.Method == "GET" && len(segmentSlice) == 1:
+		serviceSlice := make([]backend.Service, 0)
+		for _, name := range getSortedNameSlice(len(serviceMap), func(add func(string)) {
+			for name := range serviceMap {
+				add(name)
+			}
+		}) {
+			serviceSlice = append(serviceSlice, *serviceMap[name])
+		}
+		return success(serviceSlice)
+	case request.Method == "POST" && len(segmentSlice) == 1:
+		service := backend.Service{}
+		if err := decodeBody(request, &service); err != nil || service.Name == "" {
+			return badRequest("Service name is required")
+		}
+		if _, ok := serviceMap[service.Name]; ok {
+			return badRequest("Service " + service.Name + " already exists")
+		}
+		service.Namespace = namespace
+		serviceMap[service.Name] = &service
+		return success(map[string]interface{}{})
+	case request.Method == "DELETE" && len(segmentSlice) == 2:
+		if _, ok := serviceMap[segmentSlice[1]]; ok == false {
+			return notFound("/services/" + namespace + "/" + segmentSlice[1])
+		}
+		delete(serviceMap, segmentSlice[1])
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+// FailDeployCreate makes the next deploy creations fail so the tests could inject the failures
+func (fake *Backend) FailDeployCreate(amount int) {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	fake.deployCreateFailureAmount = amount
+}
+
+// AddKubernetesEvent stores the event of the pod as the search document of cloudone_analysis so the tests could inject the failures
+func (fake *Backend) AddKubernetesEvent(namespace string, podName string, reason string, message string) {
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	fake.eventSlice = append(fake.eventSlice, map[string]interface{}{
+		"_id": strconv.Itoa(fake.nextID()),
+		"_source": map[string]interface{}{
+			"metadata":       map[string]interface{}{"namespace": namespace},
+			"involvedObject": map[string]interface{}{"kind": "Pod", "name": podName},
+			"source":         map[string]interface{}{"component": "kubelet"},
+			"firstTimestamp": timestamp,
+			"lastTimestamp":  timestamp,
+			"count":          1,
+			"message":        message,
+			"reason":         reason,
+			"searchMetaData": map[string]interface{}{"acknowledge": false},
+		},
+	})
+}
+
+// handleHistoricalEvent serves the newest events first like the search of cloudone_analysis
+func (fake *Backend) handleHistoricalEvent(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch {
+	case request.Method == "GET" && segmentSlice[0] == "":
+		acknowledge := request.URL.Query().Get("acknowledge") == "true"
+		size := getQueryInt(request, "size", 10)
+		offset := getQueryInt(request, "offset", 0)
+		eventSlice := make([]map[string]interface{}, 0)
+		for i := len(fake.eventSlice) - 1; i >= 0; i-- {
+			sourceJsonMap := fake.eventSlice[i]["_source"].(map[string]interface{})
+			searchMetaDataJsonMap := sourceJsonMap["searchMetaData"].(map[string]interface{})
+			if searchMetaDataJsonMap["acknowledge"] == acknowledge {
+				eventSlice = append(eventSlice, fake.eventSlice[i])
+			}
+		}
+		if offset > len(eventSlice) {
+			offset = len(eventSlice)
+		}
+		eventSlice = eventSlice[offset:]
+		if size >= 0 && size < len(eventSlice) {
+			eventSlice = eventSlice[:size]
+		}
+		return success(eventSlice)
+	case request.Method == "PUT" && len(segmentSlice) == 2:
+		for _, event := range fake.eventSlice {
+			if event["_id"] == segmentSlice[1] {
+				sourceJsonMap := event["_source"].(map[string]interface{})
+				sourceJsonMap["searchMetaData"] = map[string]interface{}{"acknowledge": request.URL.Query().Get("acknowledge") == "true"}
+				return success(nil)
+			}
+		}
+		return notFound("/historicalevents/" + segmentSlice[0] + "/" + segmentSlice[1])
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+// handleDeployBlueGreen keeps the selected namespace only. The service of the node port is not faked.
+func (fake *Backend) handleDeployBlueGreen(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch {
+	case request.Method == "GET" && len(segmentSlice) == 2 && segmentSlice[0] == "deployable":
+		namespaceSlice := make([]string, 0)
+		for _, namespace := range fake.namespaceSlice {
+			if _, ok := fake.deployInformationMap[namespace][segmentSlice[1]]; ok {
+				namespaceSlice = append(namespaceSlice, namespace)
+			}
+		}
+		sort.Strings(namespaceSlice)
+		return success(namespaceSlice)
+	case request.Method == "GET" && segmentSlice[0] == "":
+		deployBlueGreenSlice := make([]backend.DeployBlueGreen, 0)
+		for _, name := range getSortedNameSlice(len(fake.deployBlueGreenMap), func(add func(string)) {
+			for name := range fake.deployBlueGreenMap {
+				add(name)
+			}
+		}) {
+			deployBlueGreenSlice = append(deployBlueGreenSlice, *fake.deployBlueGreenMap[name])
+		}
+		return success(deployBlueGreenSlice)
+	case request.Method == "GET" && len(segmentSlice) == 1:
+		deployBlueGreen, ok := fake.deployBlueGreenMap[segmentSlice[0]]
+		if ok == false {
+			return notFound("/deploybluegreens/" + segmentSlice[0])
+		}
+		return success(deployBlueGreen)
+	case request.Method == "PUT" && segmentSlice[0] == "":
+		deployBlueGreen := backend.DeployBlueGreen{}
+		if err := decodeBody(request, &deployBlueGreen); err != nil || deployBlueGreen.ImageInformation == "" {
+			return badRequest("Image information is required")
+		}
+		if _, ok := fake.deployInformationMap[deployBlueGreen.Namespace][deployBlueGreen.ImageInformation]; ok == false {
+			return badRequest(deployBlueGreen.ImageInformation + " is not deployed in namespace " + deployBlueGreen.Namespace)
+		}
+		fake.deployBlueGreenMap[deployBlueGreen.ImageInformation] = &deployBlueGreen
+		return success(map[string]interface{}{})
+	case request.Method == "DELETE" && len(segmentSlice) == 1:
+		if _, ok := fake.deployBlueGreenMap[segmentSlice[0]]; ok == false {
+			return notFound("/deploybluegreens/" + segmentSlice[0])
+		}
+		delete(fake.deployBlueGreenMap, segmentSlice[0])
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+// kubernetesReplicationController is the part of the kubernetes json used by the launch. The fields are matched without the case.
+type kubernetesReplicationController struct {
+	Metadata struct {
+		Name   string
+		Labels map[string]string
+	}
+	Spec struct {
+		Template struct {
+			Metadata struct {
+				Labels map[string]string
+			}
+			Spec struct {
+				Containers []struct {
+					Name  string
+					Image string
+					Ports []backend.ReplicationControllerContainerPort
+					Env   []backend.ReplicationControllerContainerEnvironment
+				}
+			}
+		}
+	}
+}
+
+type kubernetesService struct {
+	Metadata struct {
+		Name   string
+		Labels map[string]interface{}
+	}
+	Spec struct {
+		Selector map[string]interface{}
+		Ports    []struct {
+			Name       string
+			Protocol   string
+			Port       int
+			TargetPort interface{}
+			NodePort   int
+		}
+	}
+}
+
+// launchClusterApplication creates the service and the replication controller of the size like cloudone without the script
+func (fake *Backend) launchClusterApplication(namespace string, name string, clusterLaunch backend.ClusterLaunch) (int, interface{}) {
+	cluster, ok := fake.clusterMap[name]
+	if ok == false {
+		return notFound("/clusterapplications/" + name)
+	}
+	deployClusterApplicationMap, ok := fake.deployClusterApplicationMap[namespace]
+	if ok == false {
+		return notFound("/namespaces/" + namespace)
+	}
+	if _, ok := deployClusterApplicationMap[name]; ok {
+		return badRequest("Cluster application " + name + " is already launched in namespace " + namespace)
+	}
+
+	kubernetesReplicationController := kubernetesReplicationController{}
+	if err := json.Unmarshal([]byte(cluster.ReplicationControllerJson), &kubernetesReplicationController); err != nil || kubernetesReplicationController.Metadata.Name == "" {
+		return badRequest("Replication controller json of cluster application " + name + " is invalid")
+	}
+	kubernetesService := kubernetesService{}
+	if err := json.Unmarshal([]byte(cluster.ServiceJson), &kubernetesService); err != nil {
+		return badRequest("Service json of cluster application " + name + " is invalid")
+	}
+	replicationControllerName := kubernetesReplicationController.Metadata.Name
+	if _, ok := fake.replicationControllerMap[namespace][replicationControllerName]; ok {
+		return badRequest("Replication controller " + replicationControllerName + " already exists in namespace " + namespace)
+	}
+	serviceName := kubernetesService.Metadata.Name
+	if _, ok := fake.serviceMap[namespace][serviceName]; ok && serviceName != "" {
+		return badRequest("Service " + serviceName + " already exists in namespace " + namespace)
+	}
+
+	environmentSlice := make([]backend.ReplicationControllerContainerEnvironment, 0)
+	for _, environment := range clusterLaunch.EnvironmentSlice {
+		environmentSlice = append(environmentSlice, backend.ReplicationControllerContainerEnvironment{Name: environment.Name, Value: environment.Value})
+	}
+	labelMap := kubernetesReplicationController.Spec.Template.Metadata.Labels
+	containerSlice := make([]backend.ReplicationControllerContainer, 0)
+	for _, container := range kubernetesReplicationController.Spec.Template.Spec.Containers {
+		containerSlice = append(containerSlice, backend.ReplicationControllerContainer{
+			Name:             container.Name,
+			Image:            container.Image,
+			PortSlice:        container.Ports,
+			EnvironmentSlice: append(container.Env, environmentSlice...),
+		})
+	}
+	fake.putReplicationController(namespace, backend.ReplicationController{
+		Name:           replicationControllerName,
+		ReplicaAmount:  clusterLaunch.Size,
+		Selector:       backend.ReplicationControllerSelector{Name: labelMap["name"], Version: labelMap["version"]},
+		Label:          backend.ReplicationControllerLabel{Name: kubernetesReplicationController.Metadata.Labels["name"]},
+		ContainerSlice: containerSlice,
+	})
+
+	if serviceName != "" {
+		servicePortSlice := make([]backend.ServicePort, 0)
+		for _, port := range kubernetesService.Spec.Ports {
+			targetPort := strconv.Itoa(port.Port)
+			if port.TargetPort != nil {
+				targetPort = fmt.Sprint(port.TargetPort)
+			}
+			servicePortSlice = append(servicePortSlice, backend.ServicePort{
+				Name:       port.Name,
+				Protocol:   port.Protocol,
+				Port:       port.Port,
+				TargetPort: targetPort,
+				NodePort:   port.NodePort,
+			})
+		}
+		fake.serviceMap[namespace][serviceName] = &backend.Service{
+			Name:            serviceName,
+			Namespace:       namespace,
+			PortSlice:       servicePortSlice,
+			Selector:        kubernetesService.Spec.Selector,
+			ClusterIP:       fmt.Sprintf("10.0.0.%d", len(fake.serviceMap[namespace])+2),
+			LabelMap:        kubernetesService.Metadata.Labels,
+			SessionAffinity: "None",
+		}
+	}
+
+	deployClusterApplicationMap[name] = &backend.DeployClusterApplication{
+		Name:                              name,
+		Namespace:                         namespace,
+		Size:                              clusterLaunch.Size,
+		EnvironmentSlice:                  clusterLaunch.EnvironmentSlice,
+		ReplicationControllerExtraJsonMap: clusterLaunch.ReplicationControllerExtraJsonMap,
+		ServiceName:                       serviceName,
+		ReplicationControllerNameSlice:    []string{replicationControllerName},
+		CreatedTime:                       time.Now(),
+	}
+	return success(map[string]interface{}{})
+}
+
+func (fake *Backend) handleClusterApplication(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch {
+	case request.Method == "GET" && segmentSlice[0] == "":
+		clusterSlice := make([]backend.Cluster, 0)
+		for _, name := range getSortedNameSlice(len(fake.clusterMap), func(add func(string)) {
+			for name := range fake.clusterMap {
+				add(name)
+			}
+		}) {
+			clusterSlice = append(clusterSlice, *fake.clusterMap[name])
+		}
+		return success(clusterSlice)
+	case request.Method == "GET" && len(segmentSlice) == 1:
+		cluster, ok := fake.clusterMap[segmentSlice[0]]
+		if ok == false {
+			return notFound("/clusterapplications/" + segmentSlice[0])
+		}
+		return success(cluster)
+	case request.Method == "POST" && segmentSlice[0] == "":
+		cluster := backend.Cluster{}
+		if err := decodeBody(request, &cluster); err != nil || cluster.Name == "" {
+			return badRequest("Cluster application name is required")
+		}
+		// Overwritten like cloudone
+		fake.clusterMap[cluster.Name] = &cluster
+		return success(map[string]interface{}{})
+	case request.Method == "POST" && len(segmentSlice) == 3 && segmentSlice[0] == "launch":
+		clusterLaunch := backend.ClusterLaunch{}
+		if err := decodeBody(request, &clusterLaunch); err != nil {
+			return badRequest(err.Error())
+		}
+		return fake.launchClusterApplication(segmentSlice[1], segmentSlice[2], clusterLaunch)
+	case request.Method == "DELETE" && len(segmentSlice) == 1:
+		if _, ok := fake.clusterMap[segmentSlice[0]]; ok == false {
+			return notFound("/clusterapplications/" + segmentSlice[0])
+		}
+		delete(fake.clusterMap, segmentSlice[0])
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+func (fake *Backend) handleDeployClusterApplication(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch {
+	case request.Method == "GET" && len(segmentSlice) == 1:
+		namespaceSlice := append([]string{}, fake.namespaceSlice...)
+		if segmentSlice[0] != "" {
+			if fake.isNamespaceExisting(segmentSlice[0]) == false {
+				return notFound("/namespaces/" + segmentSlice[0])
+			}
+			namespaceSlice = []string{segmentSlice[0]}
+		}
+		sort.Strings(namespaceSlice)
+		deployClusterApplicationSlice := make([]backend.DeployClusterApplication, 0)
+		for _, namespace := range namespaceSlice {
+			deployClusterApplicationMap := fake.deployClusterApplicationMap[namespace]
+			for _, name := range getSortedNameSlice(len(deployClusterApplicationMap), func(add func(string)) {
+				for name := range deployClusterApplicationMap {
+					add(name)
+				}
+			}) {
+				deployClusterApplicationSlice = append(deployClusterApplicationSlice, *deployClusterApplicationMap[name])
+			}
+		}
+		return success(deployClusterApplicationSlice)
+	case request.Method == "GET" && len(segmentSlice) == 2:
+		deployClusterApplication, ok := fake.deployClusterApplicationMap[segmentSlice[0]][segmentSlice[1]]
+		if ok == false {
+			return notFound("/deployclusterapplications/" + segmentSlice[0] + "/" + segmentSlice[1])
+		}
+		return success(deployClusterApplication)
+	case request.Method == "PUT" && len(segmentSlice) == 3 && segmentSlice[0] == "size":
+		deployClusterApplication, ok := fake.deployClusterApplicationMap[segmentSlice[1]][segmentSlice[2]]
+		if ok == false {
+			return notFound("/deployclusterapplications/" + segmentSlice[1] + "/" + segmentSlice[2])
+		}
+		size := getQueryInt(request, "size", -1)
+		if size < 0 {
+			return badRequest("Size must be a non-negative number")
+		}
+		deployClusterApplication.Size = size
+		for _, replicationControllerName := range deployClusterApplication.ReplicationControllerNameSlice {
+			if replicationController, ok := fake.replicationControllerMap[segmentSlice[1]][replicationControllerName]; ok {
+				fake.resizeReplicationController(segmentSlice[1], replicationController, size)
+			}
+		}
+		return success(nil)
+	case request.Method == "DELETE" && len(segmentSlice) == 2:
+		deployClusterApplication, ok := fake.deployClusterApplicationMap[segmentSlice[0]][segmentSlice[1]]
+		if ok == false {
+			return notFound("/deployclusterapplications/" + segmentSlice[0] + "/" + segmentSlice[1])
+		}
+		delete(fake.deployClusterApplicationMap[segmentSlice[0]], segmentSlice[1])
+		for _, replicationControllerName := range deployClusterApplication.ReplicationControllerNameSlice {
+			delete(fake.replicationControllerMap[segmentSlice[0]], replicationControllerName)
+		}
+		delete(fake.serviceMap[segmentSlice[0]], deployClusterApplication.ServiceName)
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+func (fake *Backend) handleImageInformation(request *http.Request, segmentSlice []string) (int, interface{}) {
+	if request.Method == "GET" && segmentSlice[0] == "" {
+		return success(fake.imageInformationSlice)
+	}
+	return methodNotAllowed(request)
+}
+
+func (fake *Backend) handleImageRecord(request *http.Request, segmentSlice []string) (int, interface{}) {
+	imageRecordSlice, ok := fake.imageRecordMap[segmentSlice[0]]
+	if ok == false {
+		return notFound("/imagerecords/" + segmentSlice[0])
+	}
+	switch {
+	case request.Method == "GET" && len(segmentSlice) == 1:
+		return success(imageRecordSlice)
+	case request.Method == "DELETE" && len(segmentSlice) == 2:
+		if _, ok := fake.getImageRecord(segmentSlice[0], segmentSlice[1]); ok == false {
+			return notFound("/imagerecords/" + segmentSlice[0] + "/" + segmentSlice[1])
+		}
+		remainingImageRecordSlice := make([]backend.ImageRecord, 0)
+		for _, imageRecord := range imageRecordSlice {
+			if imageRecord.Version != segmentSlice[1] {
+				remainingImageRecordSlice = append(remainingImageRecordSlice, imageRecord)
+			}
+		}
+		fake.imageRecordMap[segmentSlice[0]] = remainingImageRecordSlice
+		return success(nil)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+func (fake *Backend) handleNode(request *http.Request, segmentSlice []string) (int, interface{}) {
+	if request.Method == "GET" && segmentSlice[0] == "topology" {
+		return success(fake.regionSlice)
+	}
+	return methodNotAllowed(request)
+}
+
+// getMetricSlice generates the samples moving with the time so the charts of the demo change.
+// The cumulative one such as the cpu usage total keeps increasing.
+func getMetricSlice(seed int, base int64, step int64, cumulative bool) []int64 {
+	const sampleAmount = 12
+	tick := int(time.Now().Unix() / 10)
+	metricSlice := make([]int64, 0, sampleAmount)
+	total := base
+	for i := 0; i < sampleAmount; i++ {
+		value := base + int64((seed*7+tick+i)%10)*step
+		if cumulative {
+			total += value
+			value = total
+		}
+		metricSlice = append(metricSlice, value)
+	}
+	return metricSlice
+}
+
+func getContainerMetric(seed int, containerName string) backend.ContainerMetric {
+	return backend.ContainerMetric{
+		ContainerName:                     containerName,
+		CpuUsageTotalSlice:                getMetricSlice(seed, 100000000, 10000000, true),
+		MemoryUsageSlice:                  getMetricSlice(seed, 64*1024*1024, 4*1024*1024, false),
+		DiskIOServiceBytesStatsTotalSlice: getMetricSlice(seed, 4096, 1024, true),
+		DiskIOServicedStatsTotalSlice:     getMetricSlice(seed, 10, 2, true),
+		NetworkRXBytesSlice:               getMetricSlice(seed, 2048, 512, true),
+		NetworkTXBytesSlice:               getMetricSlice(seed+1, 2048, 512, true),
+		NetworkRXPacketsSlice:             getMetricSlice(seed, 20, 5, true),
+		NetworkTXPacketsSlice:             getMetricSlice(seed+1, 20, 5, true),
+	}
+}
+
+func (fake *Backend) handleNodeMetric(request *http.Request, segmentSlice []string) (int, interface{}) {
+	if request.Method != "GET" {
+		return methodNotAllowed(request)
+	}
+	nodeMetricSlice := make([]backend.NodeMetric, 0)
+	for i, node := range fake.getNodeSlice() {
+		containerMetric := getContainerMetric(i, node.Name)
+		nodeMetricSlice = append(nodeMetricSlice, backend.NodeMetric{
+			Valid:                             true,
+			KubeletHost:                       node.Address,
+			CpuUsageTotalSlice:                containerMetric.CpuUsageTotalSlice,
+			MemoryUsageSlice:                  containerMetric.MemoryUsageSlice,
+			DiskIOServiceBytesStatsTotalSlice: containerMetric.DiskIOServiceBytesStatsTotalSlice,
+			DiskIOServicedStatsTotalSlice:     containerMetric.DiskIOServicedStatsTotalSlice,
+			NetworkRXBytesSlice:               containerMetric.NetworkRXBytesSlice,
+			NetworkTXBytesSlice:               containerMetric.NetworkTXBytesSlice,
+			NetworkRXPacketsSlice:             containerMetric.NetworkRXPacketsSlice,
+			NetworkTXPacketsSlice:             containerMetric.NetworkTXPacketsSlice,
+		})
+	}
+	return success(nodeMetricSlice)
+}
+
+func getReplicationControllerMetric(namespace string, replicationController *replicationController) backend.ReplicationControllerMetric {
+	validPodSlice := make([]bool, 0)
+	podMetricSlice := make([]backend.PodMetric, 0)
+	for i, pod := range replicationController.podSlice {
+		validContainerSlice := make([]bool, 0)
+		containerMetricSlice := make([]backend.ContainerMetric, 0)
+		for j, container := range pod.ContainerSlice {
+			validContainerSlice = append(validContainerSlice, true)
+			containerMetricSlice = append(containerMetricSlice, getContainerMetric(i+j, container.Name))
+		}
+		validPodSlice = append(validPodSlice, true)
+		podMetricSlice = append(podMetricSlice, backend.PodMetric{
+			KubeletHost:          pod.HostIP,
+			Namespace:            namespace,
+			PodName:              pod.Name,
+			ValidContainerSlice:  validContainerSlice,
+			ContainerMetricSlice: containerMetricSlice,
+		})
+	}
+	return backend.ReplicationControllerMetric{
+		Namespace:                 namespace,
+		ReplicationControllerName: replicationController.replicationController.Name,
+		ValidPodSlice:             validPodSlice,
+		PodMetricSlice:            podMetricSlice,
+		Size:                      len(replicationController.podSlice),
+	}
+}
+
+func (fake *Backend) handleReplicationControllerMetric(request *http.Request, segmentSlice []string) (int, interface{}) {
+	if request.Method != "GET" {
+		return methodNotAllowed(request)
+	}
+	namespace := segmentSlice[0]
+	replicationControllerMap, ok := fake.replicationControllerMap[namespace]
+	if ok == false {
+		return notFound("/namespaces/" + namespace)
+	}
+	if len(segmentSlice) == 2 {
+		replicationController, ok := replicationControllerMap[segmentSlice[1]]
+		if ok == false {
+			return notFound("/replicationcontrollers/" + namespace + "/" + segmentSlice[1])
+		}
+		return success(getReplicationControllerMetric(namespace, replicationController))
+	}
+	replicationControllerMetricList := backend.ReplicationControllerMetricList{
+		ErrorSlice:                       make([]string, 0),
+		ReplicationControllerMetricSlice: make([]backend.ReplicationControllerMetric, 0),
+	}
+	for _, name := range getSortedNameSlice(len(replicationControllerMap), func(add func(string)) {
+		for name := range replicationControllerMap {
+			add(name)
+		}
+	}) {
+		replicationControllerMetricList.ReplicationControllerMetricSlice = append(replicationControllerMetricList.ReplicationControllerMetricSlice, getReplicationControllerMetric(namespace, replicationControllerMap[name]))
+	}
+	return success(replicationControllerMetricList)
+}
+
+// handleHealthCheck replies the health of cloudone and cloudone_analysis together since the fake serves both
+func (fake *Backend) handleHealthCheck(request *http.Request, segmentSlice []string) (int, interface{}) {
+	if request.Method != "GET" {
+		return methodNotAllowed(request)
+	}
+	kubernetesJsonMap := make(map[string]interface{})
+	for i, node := range fake.getNodeSlice() {
+		kubernetesJsonMap[node.Address] = map[string]interface{}{
+			"active": true,
+			"service": map[string]interface{}{
+				"docker":                  true,
+				"flanneld":                true,
+				"kube-apiserver":          i == 0,
+				"kube-controller-manager": i == 0,
+				"kube-proxy":              true,
+				"kube-scheduler":          i == 0,
+				"kubelet":                 true,
+			},
+			"docker": map[string]interface{}{
+				"ip": fmt.Sprintf("10.244.%d.1", i+1),
+			},
+			"flannel": map[string]interface{}{
+				"ip": fmt.Sprintf("10.244.%d.0", i+1),
+			},
+		}
+	}
+	return success(map[string]interface{}{
+		"cloudone": map[string]interface{}{
+			"restapi": true,
+			"storage": true,
+			"docker":  true,
+		},
+		"cloudone_analysis": map[string]interface{}{
+			"restapi":       true,
+			"elasticsearch": true,
+		},
+		"kubernetes": kubernetesJsonMap,
+	})
+}
+
+func (fake *Backend) handleAuditLog(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch request.Method {
+	case "POST":
+		auditLog := backend.AuditLog{}
+		if err := decodeBody(request, &auditLog); err != nil {
+			return badRequest(err.Error())
+		}
+		fake.auditLogSlice = append(fake.auditLogSlice, auditLog)
+		return success(nil)
+	case "GET":
+		// The latest is the first like cloudone_analysis
+		userName := segmentSlice[0]
+		size := getQueryInt(request, "size", 10)
+		offset := getQueryInt(request, "offset", 0)
+		auditLogSlice := make([]backend.AuditLog, 0)
+		skipped := 0
+		for i := len(fake.auditLogSlice) - 1; i >= 0 && len(auditLogSlice) < size; i-- {
+			if userName != "" && fake.auditLogSlice[i].UserName != userName {
+				continue
+			}
+			if skipped < offset {
+				skipped++
+				continue
+			}
+			auditLogSlice = append(auditLogSlice, fake.auditLogSlice[i])
+		}
+		return success(auditLogSlice)
+	default:
+		return methodNotAllowed(request)
+	}
+}
+
+func (fake *Backend) handleAuthorization(request *http.Request, segmentSlice []string) (int, interface{}) {
+	switch {
+	case segmentSlice[0] == "tokens" && request.Method == "POST":
+		userData := backend.UserData{}
+		if err := decodeBody(request, &userData); err != nil {
+			return badRequest(err.Error())
+		}
+		account, ok := fake.userMap[userData.Username]
+		if ok == false || account.password != userData.Password {
+			return errorResponse(http.StatusUnauthorized, "User name or password is incorrect", "")
+		}
+		token := "fake-token-" + strconv.Itoa(fake.nextID())
+		fake.tokenMap[token] = account.user.Name
+		return success(backend.TokenData{Token: token})
+	case segmentSlice[0] == "tokens" && request.Method == "GET" && len(segmentSlice) == 4 && segmentSlice[2] == "components":
+		userName, ok := fake.tokenMap[segmentSlice[1]]
+		if ok == false {
+			return errorResponse(http.StatusUnauthorized, "Token doesn't exist", "")
+		}
+		return success(fake.userMap[userName].user)
+	case segmentSlice[0] == "users" && request.Method == "GET" && len(segmentSlice) == 1:
+		userSlice := make([]rbac.User, 0)
+		for _, name := range getSortedNameSlice(len(fake.userMap), func(add func(string)) {
+			for name := range fake.userMap {
+				add(name)
+			}
+		}) {
+			userSlice = append(userSlice, fake.userMap[name].user)
+		}
+		return success(userSlice)
+	case segmentSlice[0] == "users" && request.Method == "GET" && len(segmentSlice) == 2:
+		account, ok := fake.userMap[segmentSlice[1]]
+		if ok == false {
+			return notFound("/users/" + segmentSlice[1])
+		}
+		return success(account.user)
+	case segmentSlice[0] == "users" && request.Method == "PUT" && len(segmentSlice) == 3 && segmentSlice[2] == "metadata":
+		account, ok := fake.userMap[segmentSlice[1]]
+		if ok == false {
+			return notFound("/users/" + segmentSlice[1])
+		}
+		metaDataMap := make(map[string]string)
+		if err := decodeBody(request, &metaDataMap); err != nil {
+			return badRequest(err.Error())
+		}
+		account.user.MetaDataMap = metaDataMap
+		return success(map[string]interface{}{})
+	case segmentSlice[0] == "roles" && request.Method == "GET" && len(segmentSlice) == 1:
+		return success(fake.roleSlice)
+	case segmentSlice[0] == "roles" && request.Method == "GET" && len(segmentSlice) == 2:
+		for _, role := range fake.roleSlice {
+			if role.Name == segmentSlice[1] {
+				return success(role)
+			}
+		}
+		return notFound("/roles/" + segmentSlice[1])
+	default:
+		return methodNotAllowed(request)
+	}
+}
@@ -0,0 +1,132 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package guimessagedisplay
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"github.com/cloudawan/cloudone_utility/restclient"
+	"strconv"
+	"strings"
+)
+
+// GUIError is the structured error shown in the flash message and replied in the json body of guirestapi.
+// The json field error keeps the text so the existing clients reading the field error still work.
+type GUIError struct {
+	StatusCode int    `json:"statusCode,omitempty"`
+	ErrorCode  string `json:"errorCode,omitempty"`
+	Message    string `json:"error"`
+	RequestID  string `json:"requestID,omitempty"`
+	Resource   string `json:"resource,omitempty"`
+}
+
+func (guiError *GUIError) Error() string {
+	return guiError.Message
+}
+
+// SetResource sets the affected resource and returns the same error for chaining
+func (guiError *GUIError) SetResource(resource string) *GUIError {
+	guiError.Resource = resource
+	return guiError
+}
+
+// SetRequestID sets the request id and returns the same error for chaining
+func (guiError *GUIError) SetRequestID(requestID string) *GUIError {
+	guiError.RequestID = requestID
+	return guiError
+}
+
+// HasDetail is used by the template to decide whether to show the detail line
+func (guiError *GUIError) HasDetail() bool {
+	return guiError.StatusCode != 0 || guiError.ErrorCode != "" || guiError.RequestID != "" || guiError.Resource != ""
+}
+
+// GetDetail returns the text of status code, error code, request id and resource
+func (guiError *GUIError) GetDetail() string {
+	detailSlice := make([]string, 0)
+	if guiError.StatusCode != 0 {
+		detailSlice = append(detailSlice, "Status "+strconv.Itoa(guiError.StatusCode))
+	}
+	if guiError.ErrorCode != "" {
+		detailSlice = append(detailSlice, "Code "+guiError.ErrorCode)
+	}
+	if guiError.RequestID != "" {
+		detailSlice = append(detailSlice, "Request ID "+guiError.RequestID)
+	}
+	if guiError.Resource != "" {
+		detailSlice = append(detailSlice, "Resource "+guiError.Resource)
+	}
+	return strings.Join(detailSlice, ", ")
+}
+
+// NewGUIErrorMessage creates the error with only the text
+func NewGUIErrorMessage(message string) *GUIError {
+	return &GUIError{
+		Message: message,
+	}
+}
+
+// NewGUIError converts the error into the structured one.
+// The status code, error code, request id and resource are taken from the backend reply if the error is from the backend.
+func NewGUIError(err error) *GUIError {
+	if err == nil {
+		return nil
+	}
+
+	guiError, ok := err.(*GUIError)
+	if ok {
+		return guiError
+	}
+
+	guiError = &GUIError{
+		Message: GetErrorMessage(err),
+	}
+
+	requestError, ok := err.(restclient.RequestError)
+	if ok == false {
+		return guiError
+	}
+
+	guiError.StatusCode = requestError.StatusCode
+
+	responseDataJsonMap, _ := requestError.ResponseData.(map[string]interface{})
+	if errorCode, ok := responseDataJsonMap["ErrorCode"].(string); ok {
+		guiError.ErrorCode = errorCode
+	} else if errorField, ok := responseDataJsonMap["Error"].(string); ok {
+		guiError.ErrorCode = errorField
+	}
+	guiError.RequestID, _ = responseDataJsonMap["RequestID"].(string)
+	guiError.Resource, _ = responseDataJsonMap["Resource"].(string)
+
+	return guiError
+}
+
+// OutputJSONError replies the structured error as the json body with the status code.
+// The requested path and the request id of the GUI are used if the backend doesn't tell.
+func OutputJSONError(c *beego.Controller, statusCode int, err error) {
+	guiError := NewGUIError(err)
+	if guiError.Resource == "" {
+		guiError.Resource = c.Ctx.Input.URL()
+	}
+	if guiError.RequestID == "" {
+		guiError.RequestID = tracing.GetRequestIDFromContext(c.Ctx)
+	}
+
+	c.Data["json"] = guiError
+	if statusCode != 0 {
+		c.Ctx.Output.Status = statusCode
+	}
+	c.ServeJSON()
+}
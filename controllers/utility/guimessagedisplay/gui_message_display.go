@@ -15,7 +15,10 @@
 package guimessagedisplay
 
 import (
+	"bytes"
+	"encoding/gob"
 	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 )
 
 type GUIMessage struct {
@@ -23,13 +26,49 @@ type GUIMessage struct {
 	successSlice   []string
 	infoSlice      []string
 	warningSlice   []string
-	dangerSlice    []string
+	dangerSlice    []*GUIError
 }
 
 const (
 	sessionNameGUIMessage = "guiMessage"
 )
 
+func init() {
+	// The session store shared by the replicas decodes the message redirected from another replica
+	gob.Register(&GUIMessage{})
+}
+
+// guiMessageData is the serialized GUIMessage without the session utility of the request
+type guiMessageData struct {
+	SuccessSlice []string
+	InfoSlice    []string
+	WarningSlice []string
+	DangerSlice  []*GUIError
+}
+
+func (guiMessage *GUIMessage) GobEncode() ([]byte, error) {
+	buffer := bytes.Buffer{}
+	err := gob.NewEncoder(&buffer).Encode(guiMessageData{
+		guiMessage.successSlice,
+		guiMessage.infoSlice,
+		guiMessage.warningSlice,
+		guiMessage.dangerSlice,
+	})
+	return buffer.Bytes(), err
+}
+
+func (guiMessage *GUIMessage) GobDecode(byteSlice []byte) error {
+	data := guiMessageData{}
+	if err := gob.NewDecoder(bytes.NewReader(byteSlice)).Decode(&data); err != nil {
+		return err
+	}
+	guiMessage.successSlice = append(make([]string, 0), data.SuccessSlice...)
+	guiMessage.infoSlice = append(make([]string, 0), data.InfoSlice...)
+	guiMessage.warningSlice = append(make([]string, 0), data.WarningSlice...)
+	guiMessage.dangerSlice = append(make([]*GUIError, 0), data.DangerSlice...)
+	return nil
+}
+
 func GetGUIMessageFromContext(ctx *context.Context) *GUIMessage {
 	guiMessage, _ := ctx.Input.Session(sessionNameGUIMessage).(*GUIMessage)
 	return guiMessage
@@ -43,8 +82,10 @@ func GetGUIMessage(sessionUtility SessionUtility) *GUIMessage {
 		guiMessage.successSlice = make([]string, 0)
 		guiMessage.infoSlice = make([]string, 0)
 		guiMessage.warningSlice = make([]string, 0)
-		guiMessage.dangerSlice = make([]string, 0)
+		guiMessage.dangerSlice = make([]*GUIError, 0)
 	}
+	// The message may be created by the previous request or decoded from the session store
+	guiMessage.sessionUtility = sessionUtility
 	return guiMessage
 }
 
@@ -68,7 +109,12 @@ func (guiMessage *GUIMessage) AddWarning(text string) {
 }
 
 func (guiMessage *GUIMessage) AddDanger(text string) {
-	guiMessage.dangerSlice = append(guiMessage.dangerSlice, text)
+	guiMessage.dangerSlice = append(guiMessage.dangerSlice, NewGUIErrorMessage(text))
+}
+
+// AddError adds the danger message with the status code, error code, request id and resource carried by the error
+func (guiMessage *GUIMessage) AddError(err error) {
+	guiMessage.dangerSlice = append(guiMessage.dangerSlice, NewGUIError(err))
 }
 
 type SessionUtility interface {
@@ -94,6 +140,15 @@ func (guiMessage *GUIMessage) OutputMessage(data map[interface{}]interface{}) bo
 		if guiMessage.sessionUtility != nil {
 			data["layoutLabelCurrentNamespace"] = guiMessage.sessionUtility.GetSession("namespace")
 			data["layoutLabelCurrentUserName"] = guiMessage.sessionUtility.GetSession("username")
+			// The cluster selector is only displayed when there are multiple cluster profiles
+			if backend.HasMultipleCluster() {
+				cluster, _ := guiMessage.sessionUtility.GetSession(backend.SessionNameCluster).(string)
+				if cluster == "" {
+					cluster = backend.GetDefaultClusterName()
+				}
+				data["layoutLabelCurrentCluster"] = cluster
+				data["layoutClusterNameSlice"] = backend.GetClusterNameSlice()
+			}
 		}
 
 		has := false
@@ -0,0 +1,468 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package manifest imports the kubernetes manifests of the replication controllers and the services.
+// Each replication controller with the service selecting it is launched as a third-party service so it is tracked
+// like the ones launched from the repository.
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/ghodss/yaml"
+	"regexp"
+	"strings"
+)
+
+const (
+	KindReplicationController = "ReplicationController"
+	KindService               = "Service"
+	// The documents in the items of the list are imported as the separate documents
+	KindList = "List"
+
+	apiVersion = "v1"
+)
+
+// The name of the third-party service and the service need to be a DNS 952 label
+var nameRegexp = regexp.MustCompile("^[a-z]{1}[a-z0-9-]{1,23}$")
+
+type Document struct {
+	// Position is 1 based such as 2 or 2.1 for the first item of the list in the second document
+	Position string
+	Kind     string
+	Name     string
+	JsonMap  map[string]interface{}
+}
+
+// Application is the replication controller and the service selecting it which are launched as a third-party service
+type Application struct {
+	Name                      string
+	Size                      int
+	ImageSlice                []string
+	ServiceName               string
+	ServicePortSlice          []string
+	ReplicationControllerJson string
+	ServiceJson               string
+}
+
+type Plan struct {
+	Namespace        string
+	ApplicationSlice []Application
+	// The manifest is applied only when there is no problem
+	ProblemSlice []string
+}
+
+func (plan *Plan) IsValid() bool {
+	return len(plan.ProblemSlice) == 0 && len(plan.ApplicationSlice) > 0
+}
+
+func (plan *Plan) addProblem(format string, argumentSlice ...interface{}) {
+	plan.ProblemSlice = append(plan.ProblemSlice, fmt.Sprintf(format, argumentSlice...))
+}
+
+// splitDocument splits the multi-document yaml by the lines of --- and skips the documents with only the comments
+func splitDocument(text string) []string {
+	documentSlice := make([]string, 0)
+	lineSlice := make([]string, 0)
+	isEmpty := true
+	flush := func() {
+		if isEmpty == false {
+			documentSlice = append(documentSlice, strings.Join(lineSlice, "\n"))
+		}
+		lineSlice = make([]string, 0)
+		isEmpty = true
+	}
+	for _, line := range strings.Split(strings.Replace(text, "\r\n", "\n", -1), "\n") {
+		if line == "---" || strings.HasPrefix(line, "--- ") {
+			flush()
+			continue
+		}
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && strings.HasPrefix(trimmed, "#") == false {
+			isEmpty = false
+		}
+		lineSlice = append(lineSlice, line)
+	}
+	flush()
+	return documentSlice
+}
+
+func getMap(jsonMap map[string]interface{}, keySlice ...string) map[string]interface{} {
+	for _, key := range keySlice {
+		jsonMap, _ = jsonMap[key].(map[string]interface{})
+	}
+	return jsonMap
+}
+
+func getString(jsonMap map[string]interface{}, key string) string {
+	value, _ := jsonMap[key].(string)
+	return value
+}
+
+// getLabelMap returns nil if any value isn't a string
+func getLabelMap(jsonMap map[string]interface{}) map[string]string {
+	labelMap := make(map[string]string)
+	for key, value := range jsonMap {
+		text, ok := value.(string)
+		if ok == false {
+			return nil
+		}
+		labelMap[key] = text
+	}
+	return labelMap
+}
+
+func isSelected(selectorMap map[string]string, labelMap map[string]string) bool {
+	if len(selectorMap) == 0 {
+		return false
+	}
+	for key, value := range selectorMap {
+		if labelMap[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseDocumentSlice parses the yaml or json documents and expands the lists
+func ParseDocumentSlice(text string) ([]Document, error) {
+	documentSlice := make([]Document, 0)
+	for i, documentText := range splitDocument(text) {
+		position := fmt.Sprintf("%d", i+1)
+		jsonMap := make(map[string]interface{})
+		// Yaml is the superset of json
+		if err := yaml.Unmarshal([]byte(documentText), &jsonMap); err != nil {
+			return nil, errors.New("Document " + position + " can't be parsed by json or yaml: " + err.Error())
+		}
+		if getString(jsonMap, "kind") != KindList {
+			documentSlice = append(documentSlice, newDocument(position, jsonMap))
+			continue
+		}
+		itemSlice, ok := jsonMap["items"].([]interface{})
+		if ok == false {
+			return nil, errors.New("Document " + position + " is a list without the items")
+		}
+		for j, item := range itemSlice {
+			itemJsonMap, ok := item.(map[string]interface{})
+			if ok == false {
+				return nil, fmt.Errorf("Document %s.%d is not an object", position, j+1)
+			}
+			documentSlice = append(documentSlice, newDocument(fmt.Sprintf("%s.%d", position, j+1), itemJsonMap))
+		}
+	}
+	return documentSlice, nil
+}
+
+func newDocument(position string, jsonMap map[string]interface{}) Document {
+	return Document{
+		position,
+		getString(jsonMap, "kind"),
+		getString(getMap(jsonMap, "metadata"), "name"),
+		jsonMap,
+	}
+}
+
+type replicationController struct {
+	document Document
+	labelMap map[string]string
+	service  *Document
+}
+
+// NewPlan validates the documents and pairs each replication controller with the service selecting it.
+// The objects existing in the cluster are checked by CheckConflict.
+func NewPlan(namespace string, text string) *Plan {
+	plan := &Plan{namespace, make([]Application, 0), make([]string, 0)}
+
+	documentSlice, err := ParseDocumentSlice(text)
+	if err != nil {
+		plan.addProblem("%s", err.Error())
+		return plan
+	}
+	if len(documentSlice) == 0 {
+		plan.addProblem("The manifest doesn't have any document")
+		return plan
+	}
+
+	replicationControllerSlice := make([]*replicationController, 0)
+	serviceSlice := make([]Document, 0)
+	nameMap := make(map[string]string)
+	for _, document := range documentSlice {
+		if document.Kind != KindReplicationController && document.Kind != KindService {
+			plan.addProblem("Document %s kind %q is not supported. Only %s and %s could be imported", document.Position, document.Kind, KindReplicationController, KindService)
+			continue
+		}
+		if version := getString(document.JsonMap, "apiVersion"); version != apiVersion {
+			plan.addProblem("Document %s %s apiVersion %q is not supported. Only %s could be imported", document.Position, document.Kind, version, apiVersion)
+			continue
+		}
+		if nameRegexp.MatchString(document.Name) == false {
+			plan.addProblem("Document %s %s name %q need to be a DNS 952 label ^[a-z]{1}[a-z0-9-]{1,23}$", document.Position, document.Kind, document.Name)
+			continue
+		}
+		if documentNamespace := getString(getMap(document.JsonMap, "metadata"), "namespace"); documentNamespace != "" && documentNamespace != namespace {
+			plan.addProblem("Document %s %s %s is in namespace %s rather than the current namespace %s", document.Position, document.Kind, document.Name, documentNamespace, namespace)
+			continue
+		}
+		if position, ok := nameMap[document.Kind+"/"+document.Name]; ok {
+			plan.addProblem("Document %s %s %s is already defined in document %s", document.Position, document.Kind, document.Name, position)
+			continue
+		}
+		nameMap[document.Kind+"/"+document.Name] = document.Position
+
+		if document.Kind == KindService {
+			if len(getMap(document.JsonMap, "spec", "selector")) == 0 {
+				plan.addProblem("Document %s %s %s doesn't have the selector", document.Position, document.Kind, document.Name)
+				continue
+			}
+			serviceSlice = append(serviceSlice, document)
+		} else if labelMap := validateReplicationController(plan, document); labelMap != nil {
+			replicationControllerSlice = append(replicationControllerSlice, &replicationController{document, labelMap, nil})
+		}
+	}
+
+	for i, service := range serviceSlice {
+		selectorMap := getLabelMap(getMap(service.JsonMap, "spec", "selector"))
+		var selected *replicationController
+		for _, replicationController := range replicationControllerSlice {
+			if isSelected(selectorMap, replicationController.labelMap) {
+				if selected != nil {
+					plan.addProblem("Service %s selects both replication controller %s and %s. A service could select only one", service.Name, selected.document.Name, replicationController.document.Name)
+				}
+				selected = replicationController
+			}
+		}
+		if selected == nil {
+			plan.addProblem("Service %s doesn't select any replication controller in the manifest", service.Name)
+		} else if selected.service != nil {
+			plan.addProblem("Replication controller %s is selected by both service %s and %s. Only one service is launched with a replication controller", selected.document.Name, selected.service.Name, service.Name)
+		} else {
+			selected.service = &serviceSlice[i]
+		}
+	}
+
+	if len(plan.ProblemSlice) > 0 {
+		return plan
+	}
+
+	for _, replicationController := range replicationControllerSlice {
+		application, err := newApplication(replicationController)
+		if err != nil {
+			plan.addProblem("%s", err.Error())
+			continue
+		}
+		plan.ApplicationSlice = append(plan.ApplicationSlice, *application)
+	}
+	if len(plan.ApplicationSlice) == 0 && len(plan.ProblemSlice) == 0 {
+		plan.addProblem("The manifest doesn't have any replication controller")
+	}
+	return plan
+}
+
+// validateReplicationController returns the labels of the pod template or nil if there is any problem
+func validateReplicationController(plan *Plan, document Document) map[string]string {
+	prefix := "Document " + document.Position + " " + document.Kind + " " + document.Name
+	if replicas, ok := getMap(document.JsonMap, "spec")["replicas"]; ok {
+		number, ok := replicas.(float64)
+		if ok == false || number < 0 || number != float64(int(number)) {
+			plan.addProblem("%s replicas need to be a non-negative integer", prefix)
+			return nil
+		}
+	}
+
+	labelMap := getLabelMap(getMap(document.JsonMap, "spec", "template", "metadata", "labels"))
+	if len(labelMap) == 0 {
+		plan.addProblem("%s doesn't have the labels in the pod template", prefix)
+		return nil
+	}
+	// The selector is defaulted to the labels of the pod template by kubernetes
+	if selectorJsonMap := getMap(document.JsonMap, "spec", "selector"); len(selectorJsonMap) > 0 {
+		if isSelected(getLabelMap(selectorJsonMap), labelMap) == false {
+			plan.addProblem("%s selector doesn't match the labels in the pod template", prefix)
+			return nil
+		}
+	}
+
+	containerSlice, _ := getMap(document.JsonMap, "spec", "template", "spec")["containers"].([]interface{})
+	if len(containerSlice) == 0 {
+		plan.addProblem("%s doesn't have any container", prefix)
+		return nil
+	}
+	for i, container := range containerSlice {
+		containerJsonMap, _ := container.(map[string]interface{})
+		if getString(containerJsonMap, "name") == "" || getString(containerJsonMap, "image") == "" {
+			plan.addProblem("%s container %d need to have the name and the image", prefix, i+1)
+			return nil
+		}
+	}
+	return labelMap
+}
+
+func newApplication(replicationController *replicationController) (*Application, error) {
+	document := replicationController.document
+	size := 1
+	if replicas, ok := getMap(document.JsonMap, "spec")["replicas"].(float64); ok {
+		size = int(replicas)
+	}
+
+	imageSlice := make([]string, 0)
+	containerSlice, _ := getMap(document.JsonMap, "spec", "template", "spec")["containers"].([]interface{})
+	for _, container := range containerSlice {
+		containerJsonMap, _ := container.(map[string]interface{})
+		imageSlice = append(imageSlice, getString(containerJsonMap, "image"))
+	}
+
+	replicationControllerByteSlice, err := json.Marshal(document.JsonMap)
+	if err != nil {
+		return nil, err
+	}
+
+	application := &Application{
+		Name:                      document.Name,
+		Size:                      size,
+		ImageSlice:                imageSlice,
+		ServicePortSlice:          make([]string, 0),
+		ReplicationControllerJson: string(replicationControllerByteSlice),
+		// The same as the third-party service without the service
+		ServiceJson: "{}",
+	}
+
+	if replicationController.service != nil {
+		serviceByteSlice, err := json.Marshal(replicationController.service.JsonMap)
+		if err != nil {
+			return nil, err
+		}
+		application.ServiceName = replicationController.service.Name
+		application.ServiceJson = string(serviceByteSlice)
+
+		portSlice, _ := getMap(replicationController.service.JsonMap, "spec")["ports"].([]interface{})
+		for _, port := range portSlice {
+			portJsonMap, _ := port.(map[string]interface{})
+			protocol := getString(portJsonMap, "protocol")
+			if protocol == "" {
+				protocol = "TCP"
+			}
+			text := fmt.Sprintf("%v/%s", portJsonMap["port"], protocol)
+			if nodePort, ok := portJsonMap["nodePort"]; ok {
+				text += fmt.Sprintf(" (node port %v)", nodePort)
+			}
+			application.ServicePortSlice = append(application.ServicePortSlice, text)
+		}
+	}
+
+	return application, nil
+}
+
+// CheckConflict adds the problems for the objects already existing in the namespace and the third-party services
+// which would be overwritten
+func CheckConflict(client *backend.Client, plan *Plan) error {
+	clusterSlice, err := client.GetClusterApplicationSlice()
+	if err != nil {
+		return err
+	}
+	deployClusterApplicationSlice, err := client.GetDeployClusterApplicationSlice(plan.Namespace)
+	if err != nil {
+		return err
+	}
+	deployInformationSlice, err := client.GetDeployInformationSlice(plan.Namespace)
+	if err != nil {
+		return err
+	}
+	replicationControllerAndRelatedPodSlice, err := client.GetReplicationControllerAndRelatedPodSlice(plan.Namespace)
+	if err != nil {
+		return err
+	}
+	serviceSlice, err := client.GetServiceSlice(plan.Namespace)
+	if err != nil {
+		return err
+	}
+
+	existingMap := make(map[string]string)
+	for _, cluster := range clusterSlice {
+		existingMap[KindReplicationController+"/"+cluster.Name] = "Third-party service " + cluster.Name + " already exists in the repository"
+	}
+	for _, deployClusterApplication := range deployClusterApplicationSlice {
+		existingMap[KindReplicationController+"/"+deployClusterApplication.Name] = "Third-party service " + deployClusterApplication.Name + " is already launched in namespace " + plan.Namespace
+	}
+	for _, deployInformation := range deployInformationSlice {
+		existingMap[KindReplicationController+"/"+deployInformation.ImageInformationName] = "Application " + deployInformation.ImageInformationName + " is already deployed in namespace " + plan.Namespace
+	}
+	for _, replicationControllerAndRelatedPod := range replicationControllerAndRelatedPodSlice {
+		existingMap[KindReplicationController+"/"+replicationControllerAndRelatedPod.Name] = "Replication controller " + replicationControllerAndRelatedPod.Name + " already exists in namespace " + plan.Namespace
+	}
+	for _, service := range serviceSlice {
+		existingMap[KindService+"/"+service.Name] = "Service " + service.Name + " already exists in namespace " + plan.Namespace
+	}
+
+	for _, application := range plan.ApplicationSlice {
+		if problem, ok := existingMap[KindReplicationController+"/"+application.Name]; ok {
+			plan.addProblem("%s", problem)
+		}
+		if problem, ok := existingMap[KindService+"/"+application.ServiceName]; ok && application.ServiceName != "" {
+			plan.addProblem("%s", problem)
+		}
+	}
+	return nil
+}
+
+// Preview returns the plan with the problems of the manifest and the conflicts in the namespace.
+// The error is only returned when the backend fails.
+func Preview(client *backend.Client, namespace string, text string) (*Plan, error) {
+	plan := NewPlan(namespace, text)
+	if len(plan.ProblemSlice) > 0 {
+		return plan, nil
+	}
+	if err := CheckConflict(client, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Apply saves each application as a third-party service and launches it in the namespace of the plan.
+// The names of the launched ones are returned even if it fails in the middle.
+func Apply(client *backend.Client, plan *Plan, userName string) ([]string, error) {
+	if plan.IsValid() == false {
+		return nil, errors.New("The manifest has problems so it can't be applied")
+	}
+
+	launchedSlice := make([]string, 0)
+	for _, application := range plan.ApplicationSlice {
+		cluster := backend.Cluster{
+			Name:                      application.Name,
+			Description:               "Imported from the manifest by " + userName,
+			ReplicationControllerJson: application.ReplicationControllerJson,
+			ServiceJson:               application.ServiceJson,
+			Environment:               make(map[string]string),
+			ScriptType:                "none",
+			ScriptContent:             "",
+		}
+		if err := client.CreateClusterApplication(cluster); err != nil {
+			return launchedSlice, errors.New("Fail to save third-party service " + application.Name + " with error " + err.Error())
+		}
+
+		clusterLaunch := backend.ClusterLaunch{
+			Size:                              application.Size,
+			EnvironmentSlice:                  make([]backend.ClusterEnvironment, 0),
+			ReplicationControllerExtraJsonMap: nil,
+		}
+		if err := client.LaunchClusterApplication(plan.Namespace, application.Name, clusterLaunch); err != nil {
+			// Not to leave the third-party service which is never launched
+			client.DeleteClusterApplication(application.Name)
+			return launchedSlice, errors.New("Fail to launch third-party service " + application.Name + " with error " + err.Error())
+		}
+		launchedSlice = append(launchedSlice, application.Name)
+	}
+	return launchedSlice, nil
+}
@@ -0,0 +1,219 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package manifest
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testReplicationController = `apiVersion: v1
+kind: ReplicationController
+metadata:
+  name: web
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        app: web
+        tier: frontend
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.9`
+
+const testService = `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+  - port: 80
+    nodePort: 30080`
+
+func TestSplitDocument(t *testing.T) {
+	Convey("Subject: The multi-document yaml is split by ---\n", t, func() {
+		testCaseSlice := []struct {
+			description string
+			text        string
+			amount      int
+		}{
+			{"The single document", "kind: Service", 1},
+			{"The separator between the documents", "kind: Service\n---\nkind: Service", 2},
+			{"The separator with the comment", "kind: Service\n--- # next\nkind: Service", 2},
+			{"The windows line ending", "kind: Service\r\n---\r\nkind: Service\r\n", 2},
+			{"The leading separator and the empty document", "---\nkind: Service\n---\n\n---\nkind: Service\n---\n", 2},
+			{"The document with only the comments", "# header\n---\nkind: Service\n---\n# footer", 1},
+			{"The dashes inside the value", "kind: Service\ndescription: a---b", 1},
+			{"The empty text", "", 0},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				So(len(splitDocument(testCase.text)), ShouldEqual, testCase.amount)
+			})
+		}
+	})
+}
+
+func TestParseDocumentSlice(t *testing.T) {
+	Convey("Subject: The documents are parsed and the lists are expanded\n", t, func() {
+		testCaseSlice := []struct {
+			description   string
+			text          string
+			positionSlice []string
+			kindSlice     []string
+			failed        bool
+		}{
+			{"The yaml documents", testReplicationController + "\n---\n" + testService, []string{"1", "2"}, []string{KindReplicationController, KindService}, false},
+			{"The json document", `{"kind": "Service", "metadata": {"name": "web"}}`, []string{"1"}, []string{KindService}, false},
+			{"The list is expanded", "kind: Service\n---\nkind: List\nitems:\n- kind: ReplicationController\n- kind: Service\n", []string{"1", "2.1", "2.2"}, []string{KindService, KindReplicationController, KindService}, false},
+			{"The empty list", "kind: List\nitems: []", []string{}, []string{}, false},
+			{"The list without the items", "kind: List", nil, nil, true},
+			{"The list with an item not an object", "kind: List\nitems:\n- web", nil, nil, true},
+			{"The invalid yaml", "kind: [Service", nil, nil, true},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				documentSlice, err := ParseDocumentSlice(testCase.text)
+				So(err != nil, ShouldEqual, testCase.failed)
+				if testCase.failed {
+					return
+				}
+				positionSlice := make([]string, 0)
+				kindSlice := make([]string, 0)
+				for _, document := range documentSlice {
+					positionSlice = append(positionSlice, document.Position)
+					kindSlice = append(kindSlice, document.Kind)
+				}
+				So(positionSlice, ShouldResemble, testCase.positionSlice)
+				So(kindSlice, ShouldResemble, testCase.kindSlice)
+			})
+		}
+	})
+}
+
+func TestIsSelected(t *testing.T) {
+	Convey("Subject: The selector matches the labels\n", t, func() {
+		labelMap := map[string]string{"app": "web", "tier": "frontend"}
+		testCaseSlice := []struct {
+			selectorMap map[string]string
+			selected    bool
+		}{
+			{map[string]string{"app": "web"}, true},
+			{map[string]string{"app": "web", "tier": "frontend"}, true},
+			{map[string]string{"app": "api"}, false},
+			{map[string]string{"app": "web", "version": "1"}, false},
+			{map[string]string{}, false},
+			{nil, false},
+		}
+		for _, testCase := range testCaseSlice {
+			So(isSelected(testCase.selectorMap, labelMap), ShouldEqual, testCase.selected)
+		}
+	})
+}
+
+func TestNewPlan(t *testing.T) {
+	Convey("Subject: The plan pairs the replication controllers with the services\n", t, func() {
+		Convey("The replication controller with the service selecting it is an application", func() {
+			plan := NewPlan("default", testReplicationController+"\n---\n"+testService)
+			So(plan.ProblemSlice, ShouldBeEmpty)
+			So(plan.IsValid(), ShouldBeTrue)
+			So(len(plan.ApplicationSlice), ShouldEqual, 1)
+			application := plan.ApplicationSlice[0]
+			So(application.Name, ShouldEqual, "web")
+			So(application.Size, ShouldEqual, 2)
+			So(application.ImageSlice, ShouldResemble, []string{"nginx:1.9"})
+			So(application.ServiceName, ShouldEqual, "web")
+			So(application.ServicePortSlice, ShouldResemble, []string{"80/TCP (node port 30080)"})
+		})
+
+		Convey("The replication controller without the service is an application without the service", func() {
+			plan := NewPlan("default", strings.Replace(testReplicationController, "  replicas: 2\n", "", 1))
+			So(plan.IsValid(), ShouldBeTrue)
+			So(plan.ApplicationSlice[0].Size, ShouldEqual, 1)
+			So(plan.ApplicationSlice[0].ServiceName, ShouldBeEmpty)
+			So(plan.ApplicationSlice[0].ServiceJson, ShouldEqual, "{}")
+		})
+
+		Convey("The documents in the list are paired", func() {
+			text := "apiVersion: v1\nkind: List\nitems:\n- " + strings.Replace(testReplicationController, "\n", "\n  ", -1) + "\n- " + strings.Replace(testService, "\n", "\n  ", -1)
+			plan := NewPlan("default", text)
+			So(plan.ProblemSlice, ShouldBeEmpty)
+			So(plan.ApplicationSlice[0].ServiceName, ShouldEqual, "web")
+		})
+
+		testCaseSlice := []struct {
+			description string
+			namespace   string
+			text        string
+			problem     string
+		}{
+			{"The namespace mismatch", "default",
+				strings.Replace(testReplicationController, "  name: web\n", "  name: web\n  namespace: staging\n", 1),
+				"is in namespace staging rather than the current namespace default"},
+			{"The unsupported kind", "default",
+				"apiVersion: v1\nkind: Pod\nmetadata:\n  name: web",
+				`kind "Pod" is not supported`},
+			{"The unsupported api version", "default",
+				strings.Replace(testReplicationController, "apiVersion: v1", "apiVersion: apps/v1", 1),
+				`apiVersion "apps/v1" is not supported`},
+			{"The invalid name", "default",
+				strings.Replace(testReplicationController, "  name: web\n", "  name: Web_1\n", 1),
+				"need to be a DNS 952 label"},
+			{"The duplicated name", "default",
+				testReplicationController + "\n---\n" + testReplicationController,
+				"is already defined in document 1"},
+			{"The service without the selector", "default",
+				strings.Replace(testService, "  selector:\n    app: web\n", "", 1),
+				"doesn't have the selector"},
+			{"The service selecting nothing", "default",
+				testReplicationController + "\n---\n" + strings.Replace(testService, "app: web", "app: api", 1),
+				"doesn't select any replication controller"},
+			{"The service selecting two replication controllers", "default",
+				testReplicationController + "\n---\n" + strings.Replace(testReplicationController, "  name: web\n", "  name: web2\n", 1) + "\n---\n" + testService,
+				"selects both replication controller web and web2"},
+			{"The replication controller selected by two services", "default",
+				testReplicationController + "\n---\n" + testService + "\n---\n" + strings.Replace(testService, "  name: web\n", "  name: web2\n", 1),
+				"is selected by both service web and web2"},
+			{"The selector not matching the pod template", "default",
+				strings.Replace(testReplicationController, "spec:\n  replicas: 2\n", "spec:\n  replicas: 2\n  selector:\n    app: api\n", 1),
+				"selector doesn't match the labels in the pod template"},
+			{"The negative replicas", "default",
+				strings.Replace(testReplicationController, "replicas: 2", "replicas: -1", 1),
+				"replicas need to be a non-negative integer"},
+			{"The container without the image", "default",
+				strings.Replace(testReplicationController, "        image: nginx:1.9", "", 1),
+				"container 1 need to have the name and the image"},
+			{"Only the service", "default",
+				testService,
+				"doesn't select any replication controller"},
+			{"No document", "default",
+				"# nothing",
+				"doesn't have any document"},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				plan := NewPlan(testCase.namespace, testCase.text)
+				So(plan.IsValid(), ShouldBeFalse)
+				So(strings.Join(plan.ProblemSlice, "\n"), ShouldContainSubstring, testCase.problem)
+			})
+		}
+	})
+}
@@ -0,0 +1,280 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"github.com/astaxie/beego"
+	beegocontext "github.com/astaxie/beego/context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	namespace = "cloudone_gui"
+
+	KindGUI        = "gui"
+	KindGUIRestAPI = "guirestapi"
+	KindAPI        = "api"
+	KindStatic     = "static"
+	KindOther      = "other"
+
+	WebSocketKindTerminal  = "terminal"
+	WebSocketKindBuildLog  = "buildlog"
+	WebSocketKindUpgrade   = "upgrade"
+	WebSocketKindPromotion = "promotion"
+
+	// routeUnknown is used when the request is stopped by a filter before the router is found such as the login redirection
+	routeUnknown = "unknown"
+)
+
+var registry = prometheus.NewRegistry()
+
+var httpRequestCounterVec = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_requests_total",
+		Help:      "The amount of the requests served by the GUI by the kind, the route pattern, the method and the status code.",
+	},
+	[]string{"kind", "route", "method", "status"},
+)
+
+var httpRequestDurationHistogramVec = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "The time to serve the requests by the kind, the route pattern and the method.",
+		Buckets:   prometheus.DefBuckets,
+	},
+	[]string{"kind", "route", "method"},
+)
+
+var backendRequestCounterVec = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backend_requests_total",
+		Help:      "The amount of the requests sent to cloudone and cloudone_analysis by the component, the method, the endpoint and the status.",
+	},
+	[]string{"component", "method", "endpoint", "status"},
+)
+
+var backendRequestDurationHistogramVec = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "backend_request_duration_seconds",
+		Help:      "The time of the requests sent to cloudone and cloudone_analysis by the component, the method and the endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	},
+	[]string{"component", "method", "endpoint"},
+)
+
+var backendCacheCounterVec = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "backend_cache_requests_total",
+		Help:      "The amount of the cached requests to cloudone and cloudone_analysis by the component and the result hit or miss.",
+	},
+	[]string{"component", "result"},
+)
+
+var webSocketSessionGaugeVec = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "websocket_sessions",
+		Help:      "The amount of the active websocket sessions by the kind such as terminal, buildlog and upgrade.",
+	},
+	[]string{"kind"},
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestCounterVec,
+		httpRequestDurationHistogramVec,
+		backendRequestCounterVec,
+		backendRequestDurationHistogramVec,
+		backendCacheCounterVec,
+		webSocketSessionGaugeVec,
+	)
+	// Show the kinds with zero before any session starts
+	for _, kind := range []string{WebSocketKindTerminal, WebSocketKindBuildLog, WebSocketKindUpgrade, WebSocketKindPromotion} {
+		webSocketSessionGaugeVec.WithLabelValues(kind)
+	}
+}
+
+// MustRegister registers the collectors of the other packages such as the session and the audit log queue
+func MustRegister(collectorSlice ...prometheus.Collector) {
+	registry.MustRegister(collectorSlice...)
+}
+
+// Handler serves the metrics to the request with the bearer token metricsToken. Only the demo mode serves them
+// without the token configured.
+func Handler() http.Handler {
+	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		token := beego.AppConfig.String("metricsToken")
+		if token == "" && beego.AppConfig.DefaultBool("demoMode", false) == false {
+			http.Error(responseWriter, "Forbidden. Set metricsToken to scrape the metrics", http.StatusForbidden)
+			return
+		}
+		if token != "" {
+			requestToken := strings.TrimPrefix(request.Header.Get("Authorization"), "Bearer ")
+			if subtle.ConstantTimeCompare([]byte(requestToken), []byte(token)) != 1 {
+				http.Error(responseWriter, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+		}
+		handler.ServeHTTP(responseWriter, request)
+	})
+}
+
+func getKind(path string) string {
+	switch {
+	case strings.HasPrefix(path, "/gui/"):
+		return KindGUI
+	case strings.HasPrefix(path, "/guirestapi/"):
+		return KindGUIRestAPI
+	case strings.HasPrefix(path, "/api/"):
+		return KindAPI
+	case strings.HasPrefix(path, "/static/"):
+		return KindStatic
+	default:
+		return KindOther
+	}
+}
+
+type routeContextKey struct{}
+
+// requestRoute is put into the request by MiddleWare so FilterRoute could tell it the route pattern found by beego
+type requestRoute struct {
+	pattern string
+}
+
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (responseWriter *statusResponseWriter) WriteHeader(statusCode int) {
+	if responseWriter.statusCode == 0 {
+		responseWriter.statusCode = statusCode
+	}
+	responseWriter.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (responseWriter *statusResponseWriter) Write(byteSlice []byte) (int, error) {
+	if responseWriter.statusCode == 0 {
+		responseWriter.statusCode = http.StatusOK
+	}
+	return responseWriter.ResponseWriter.Write(byteSlice)
+}
+
+// Hijack is required by the websocket so the response writer in the middle is unwrapped
+func (responseWriter *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := responseWriter.ResponseWriter.(http.Hijacker)
+	if ok == false {
+		return nil, nil, errors.New("The response writer doesn't support hijack")
+	}
+	if responseWriter.statusCode == 0 {
+		responseWriter.statusCode = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+func (responseWriter *statusResponseWriter) Flush() {
+	if flusher, ok := responseWriter.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// MiddleWare counts the requests and observes the time to serve. It is used with beego.RunWithMiddleWares.
+func MiddleWare(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		startTime := time.Now()
+		route := &requestRoute{}
+		recordedResponseWriter := &statusResponseWriter{responseWriter, 0}
+
+		handler.ServeHTTP(recordedResponseWriter, request.WithContext(context.WithValue(request.Context(), routeContextKey{}, route)))
+
+		statusCode := recordedResponseWriter.statusCode
+		if statusCode == 0 {
+			statusCode = http.StatusOK
+		}
+		pattern := route.pattern
+		if pattern == "" {
+			pattern = routeUnknown
+		}
+		kind := getKind(request.URL.Path)
+
+		httpRequestCounterVec.WithLabelValues(kind, pattern, request.Method, strconv.Itoa(statusCode)).Inc()
+		httpRequestDurationHistogramVec.WithLabelValues(kind, pattern, request.Method).Observe(time.Since(startTime).Seconds())
+	})
+}
+
+// FilterRoute records the route pattern instead of the path to keep the amount of the label values small.
+// It is inserted at beego.BeforeExec since the pattern is only known after the router is found.
+func FilterRoute(ctx *beegocontext.Context) {
+	pattern, _ := ctx.Input.GetData("RouterPattern").(string)
+	if route, ok := ctx.Request.Context().Value(routeContextKey{}).(*requestRoute); ok {
+		route.pattern = pattern
+	}
+}
+
+// getEndpoint keeps only the resource of the backend path such as /api/v1/replicationcontrollers
+// since the rest are the names of the namespace and the resource
+func getEndpoint(path string) string {
+	if index := strings.Index(path, "?"); index >= 0 {
+		path = path[:index]
+	}
+	segmentSlice := strings.Split(path, "/")
+	if len(segmentSlice) > 4 {
+		segmentSlice = segmentSlice[:4]
+	}
+	return strings.Join(segmentSlice, "/")
+}
+
+// ObserveBackendRequest is called by the backend client for each request. The status is the status code replied
+// by the backend or a word such as ok and error when there is no status code.
+func ObserveBackendRequest(component string, method string, path string, status string, duration time.Duration) {
+	endpoint := getEndpoint(path)
+	backendRequestCounterVec.WithLabelValues(component, method, endpoint, status).Inc()
+	backendRequestDurationHistogramVec.WithLabelValues(component, method, endpoint).Observe(duration.Seconds())
+}
+
+// ObserveBackendCache is called by the backend client for each cached request
+func ObserveBackendCache(component string, hit bool) {
+	result := "miss"
+	if hit {
+		result = "hit"
+	}
+	backendCacheCounterVec.WithLabelValues(component, result).Inc()
+}
+
+// StartWebSocketSession counts the websocket session until the returned function is called
+func StartWebSocketSession(kind string) func() {
+	gauge := webSocketSessionGaugeVec.WithLabelValues(kind)
+	gauge.Inc()
+	return gauge.Dec
+}
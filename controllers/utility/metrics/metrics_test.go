@@ -0,0 +1,57 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"github.com/astaxie/beego"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestHandler(t *testing.T) {
+	Convey("Subject: The metrics are served only with the token except in the demo mode\n", t, func() {
+		testCaseSlice := []struct {
+			description   string
+			token         string
+			demoMode      string
+			authorization string
+			status        int
+		}{
+			{"The token is not configured", "", "false", "", 403},
+			{"The token is not configured in the demo mode", "", "true", "", 200},
+			{"The request without the token", "scraper", "false", "", 401},
+			{"The request with the wrong token", "scraper", "true", "Bearer wrong", 401},
+			{"The request with the token", "scraper", "false", "Bearer scraper", 200},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				beego.AppConfig.Set("metricsToken", testCase.token)
+				beego.AppConfig.Set("demoMode", testCase.demoMode)
+				defer beego.AppConfig.Set("metricsToken", "")
+				defer beego.AppConfig.Set("demoMode", "false")
+
+				request := httptest.NewRequest("GET", "http://gui.example/metrics", nil)
+				if testCase.authorization != "" {
+					request.Header.Set("Authorization", testCase.authorization)
+				}
+				recorder := httptest.NewRecorder()
+				Handler().ServeHTTP(recorder, request)
+				So(recorder.Code, ShouldEqual, testCase.status)
+			})
+		}
+	})
+}
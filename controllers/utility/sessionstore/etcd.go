@@ -0,0 +1,236 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// etcdStore uses the v3 JSON gateway of etcd so no client library is needed.
+// The key with ttl is attached to a new lease which etcd revokes when it expires.
+type etcdStore struct {
+	endpoint   string
+	httpClient *http.Client
+}
+
+type etcdKeyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type etcdRangeResponse struct {
+	KeyValueSlice []etcdKeyValue `json:"kvs"`
+}
+
+type etcdDeleteRangeResponse struct {
+	PreviousKeyValueSlice []etcdKeyValue `json:"prev_kvs"`
+}
+
+type etcdLeaseGrantResponse struct {
+	ID string `json:"ID"`
+}
+
+type etcdTransactionResponse struct {
+	Succeeded bool `json:"succeeded"`
+}
+
+func newEtcdStore(endpoint string, timeout time.Duration) (*etcdStore, error) {
+	if endpoint == "" {
+		return nil, errors.New("sessionStoreAddress is required to be the URL of the etcd session store such as http://127.0.0.1:2379")
+	}
+	store := &etcdStore{
+		strings.TrimSuffix(endpoint, "/"),
+		&http.Client{Timeout: timeout},
+	}
+
+	// Fail at startup if etcd is not reachable
+	if _, err := store.Get("sessionstore/ping"); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func (store *etcdStore) GetKind() string {
+	return KindEtcd
+}
+
+func encodeEtcd(text string) string {
+	return base64.StdEncoding.EncodeToString([]byte(text))
+}
+
+func decodeEtcd(text string) []byte {
+	byteSlice, _ := base64.StdEncoding.DecodeString(text)
+	return byteSlice
+}
+
+// getPrefixRangeEnd is the key after all keys with the prefix as etcd defines
+func getPrefixRangeEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	// All keys
+	return "\x00"
+}
+
+func (store *etcdStore) post(path string, request interface{}, response interface{}) error {
+	byteSlice, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	httpResponse, err := store.httpClient.Post(store.endpoint+path, "application/json", bytes.NewReader(byteSlice))
+	if err != nil {
+		return err
+	}
+	defer httpResponse.Body.Close()
+
+	responseByteSlice, err := ioutil.ReadAll(httpResponse.Body)
+	if err != nil {
+		return err
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return errors.New("Etcd " + path + " returns status " + strconv.Itoa(httpResponse.StatusCode) + " " + string(responseByteSlice))
+	}
+	return json.Unmarshal(responseByteSlice, response)
+}
+
+func (store *etcdStore) Get(key string) ([]byte, error) {
+	response := etcdRangeResponse{}
+	err := store.post("/v3/kv/range", map[string]interface{}{"key": encodeEtcd(key)}, &response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.KeyValueSlice) == 0 {
+		return nil, nil
+	}
+	return decodeEtcd(response.KeyValueSlice[0].Value), nil
+}
+
+func (store *etcdStore) Set(key string, value []byte, ttl time.Duration) error {
+	request := map[string]interface{}{
+		"key":   encodeEtcd(key),
+		"value": base64.StdEncoding.EncodeToString(value),
+	}
+	if ttl > 0 {
+		leaseID, err := store.grantLease(ttl)
+		if err != nil {
+			return err
+		}
+		request["lease"] = leaseID
+	}
+	return store.post("/v3/kv/put", request, &map[string]interface{}{})
+}
+
+func (store *etcdStore) grantLease(ttl time.Duration) (string, error) {
+	second := int64(ttl / time.Second)
+	if second < 1 {
+		second = 1
+	}
+	leaseGrantResponse := etcdLeaseGrantResponse{}
+	err := store.post("/v3/lease/grant", map[string]interface{}{"TTL": second}, &leaseGrantResponse)
+	if err != nil {
+		return "", err
+	}
+	return leaseGrantResponse.ID, nil
+}
+
+func (store *etcdStore) Delete(key string) error {
+	return store.post("/v3/kv/deleterange", map[string]interface{}{"key": encodeEtcd(key)}, &etcdDeleteRangeResponse{})
+}
+
+func (store *etcdStore) Take(key string) ([]byte, error) {
+	// Only one replica gets the previous value of the deletion
+	response := etcdDeleteRangeResponse{}
+	err := store.post("/v3/kv/deleterange", map[string]interface{}{"key": encodeEtcd(key), "prev_kv": true}, &response)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.PreviousKeyValueSlice) == 0 {
+		return nil, nil
+	}
+	return decodeEtcd(response.PreviousKeyValueSlice[0].Value), nil
+}
+
+// CompareAndSwap puts the value in a transaction. The key doesn't exist if its create revision is 0.
+func (store *etcdStore) CompareAndSwap(key string, oldValue []byte, newValue []byte, ttl time.Duration) (bool, error) {
+	compare := map[string]interface{}{
+		"key":    encodeEtcd(key),
+		"result": "EQUAL",
+	}
+	if oldValue == nil {
+		compare["target"] = "CREATE"
+		compare["create_revision"] = "0"
+	} else {
+		compare["target"] = "VALUE"
+		compare["value"] = base64.StdEncoding.EncodeToString(oldValue)
+	}
+
+	putRequest := map[string]interface{}{
+		"key":   encodeEtcd(key),
+		"value": base64.StdEncoding.EncodeToString(newValue),
+	}
+	if ttl > 0 {
+		// The lease not attached expires by itself
+		leaseID, err := store.grantLease(ttl)
+		if err != nil {
+			return false, err
+		}
+		putRequest["lease"] = leaseID
+	}
+
+	response := etcdTransactionResponse{}
+	err := store.post("/v3/kv/txn", map[string]interface{}{
+		"compare": []interface{}{compare},
+		"success": []interface{}{map[string]interface{}{"request_put": putRequest}},
+	}, &response)
+	if err != nil {
+		return false, err
+	}
+	return response.Succeeded, nil
+}
+
+func (store *etcdStore) List(prefix string) ([]string, error) {
+	response := etcdRangeResponse{}
+	err := store.post("/v3/kv/range", map[string]interface{}{
+		"key":       encodeEtcd(prefix),
+		"range_end": encodeEtcd(getPrefixRangeEnd(prefix)),
+		"keys_only": true,
+	}, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	keySlice := make([]string, 0, len(response.KeyValueSlice))
+	for _, keyValue := range response.KeyValueSlice {
+		keySlice = append(keySlice, string(decodeEtcd(keyValue.Key)))
+	}
+	return keySlice, nil
+}
+
+// GC does nothing since etcd revokes the expired leases
+func (store *etcdStore) GC() {
+}
@@ -0,0 +1,228 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/random"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// The temporary files are hidden from List
+	fileTemporaryPrefix = "."
+	// The lock file of the key is hidden from List too
+	fileLockPrefix = fileTemporaryPrefix + "lock-"
+	// The lock left by the crashed replica is broken after it
+	fileLockStaleTime  = 30 * time.Second
+	fileLockTimeout    = 5 * time.Second
+	fileLockRetryDelay = 10 * time.Millisecond
+)
+
+type fileItem struct {
+	Value       []byte
+	ExpiredTime time.Time
+}
+
+// fileStore keeps each key in a file of the directory shared by the replicas such as NFS.
+// The file is replaced by rename so the reader never sees the partial content.
+type fileStore struct {
+	directory string
+}
+
+func newFileStore(directory string) (*fileStore, error) {
+	if directory == "" {
+		return nil, errors.New("sessionStoreAddress is required to be the directory of the file session store")
+	}
+	if err := os.MkdirAll(directory, 0700); err != nil {
+		return nil, err
+	}
+	return &fileStore{directory}, nil
+}
+
+func (store *fileStore) GetKind() string {
+	return KindFile
+}
+
+func (store *fileStore) getPath(key string) string {
+	// Escaping keeps the prefix so List filters the file names directly
+	return filepath.Join(store.directory, url.PathEscape(key))
+}
+
+func (store *fileStore) readItem(path string) ([]byte, error) {
+	value, _, err := store.readExistingItem(path)
+	return value, err
+}
+
+// readExistingItem returns whether the key exists besides the value since the empty value is valid
+func (store *fileStore) readExistingItem(path string) ([]byte, bool, error) {
+	byteSlice, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	item := fileItem{}
+	if err := json.Unmarshal(byteSlice, &item); err != nil {
+		return nil, false, err
+	}
+	if isExpired(item.ExpiredTime) {
+		os.Remove(path)
+		return nil, false, nil
+	}
+	return item.Value, true, nil
+}
+
+func (store *fileStore) Get(key string) ([]byte, error) {
+	return store.readItem(store.getPath(key))
+}
+
+func (store *fileStore) Set(key string, value []byte, ttl time.Duration) error {
+	byteSlice, err := json.Marshal(fileItem{value, getExpiredTime(ttl)})
+	if err != nil {
+		return err
+	}
+
+	temporaryPath := filepath.Join(store.directory, fileTemporaryPrefix+random.UUID())
+	if err := ioutil.WriteFile(temporaryPath, byteSlice, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(temporaryPath, store.getPath(key)); err != nil {
+		os.Remove(temporaryPath)
+		return err
+	}
+	return nil
+}
+
+func (store *fileStore) Delete(key string) error {
+	err := os.Remove(store.getPath(key))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (store *fileStore) Take(key string) ([]byte, error) {
+	// Only one replica could rename the file
+	takenPath := filepath.Join(store.directory, fileTemporaryPrefix+random.UUID())
+	err := os.Rename(store.getPath(key), takenPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(takenPath)
+
+	return store.readItem(takenPath)
+}
+
+// lock creates the lock file of the key exclusively. The file system shared by the replicas must support O_EXCL such as NFSv3 or later.
+func (store *fileStore) lock(key string) (func(), error) {
+	lockPath := filepath.Join(store.directory, fileLockPrefix+url.PathEscape(key))
+	deadline := time.Now().Add(fileLockTimeout)
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			file.Close()
+			return func() { os.Remove(lockPath) }, nil
+		}
+		if os.IsExist(err) == false {
+			return nil, err
+		}
+		if fileInfo, err := os.Stat(lockPath); err == nil && time.Since(fileInfo.ModTime()) > fileLockStaleTime {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, errors.New("Timeout to lock the key " + key + " of the file session store")
+		}
+		time.Sleep(fileLockRetryDelay)
+	}
+}
+
+func (store *fileStore) CompareAndSwap(key string, oldValue []byte, newValue []byte, ttl time.Duration) (bool, error) {
+	unlock, err := store.lock(key)
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	value, existing, err := store.readExistingItem(store.getPath(key))
+	if err != nil {
+		return false, err
+	}
+	if isValueMatched(value, existing, oldValue) == false {
+		return false, nil
+	}
+	if err := store.Set(key, newValue, ttl); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (store *fileStore) List(prefix string) ([]string, error) {
+	fileInfoSlice, err := ioutil.ReadDir(store.directory)
+	if err != nil {
+		return nil, err
+	}
+
+	escapedPrefix := url.PathEscape(prefix)
+	keySlice := make([]string, 0)
+	for _, fileInfo := range fileInfoSlice {
+		name := fileInfo.Name()
+		if strings.HasPrefix(name, fileTemporaryPrefix) || strings.HasPrefix(name, escapedPrefix) == false {
+			continue
+		}
+		key, err := url.PathUnescape(name)
+		if err != nil {
+			continue
+		}
+		// The expired key is removed by reading like the other stores never list it
+		if _, existing, err := store.readExistingItem(filepath.Join(store.directory, name)); err != nil || existing == false {
+			continue
+		}
+		keySlice = append(keySlice, key)
+	}
+	return keySlice, nil
+}
+
+func (store *fileStore) GC() {
+	fileInfoSlice, err := ioutil.ReadDir(store.directory)
+	if err != nil {
+		beego.Error("Fail to read the session store directory", store.directory, err)
+		return
+	}
+	for _, fileInfo := range fileInfoSlice {
+		if strings.HasPrefix(fileInfo.Name(), fileTemporaryPrefix) {
+			// The temporary file left by the crash
+			if time.Since(fileInfo.ModTime()) > time.Hour {
+				os.Remove(filepath.Join(store.directory, fileInfo.Name()))
+			}
+			continue
+		}
+		// Reading removes the expired one
+		store.readItem(filepath.Join(store.directory, fileInfo.Name()))
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"errors"
+	"github.com/astaxie/beego"
+	"os"
+	"strconv"
+	"time"
+)
+
+// replicaID identifies this GUI replica as the holder of the leases
+var replicaID = getReplicaID()
+
+func getReplicaID() string {
+	hostname, _ := os.Hostname()
+	return hostname + "-" + strconv.Itoa(os.Getpid()) + "-" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// GetReplicaID returns the holder of the leases acquired by this replica
+func GetReplicaID() string {
+	return replicaID
+}
+
+// AcquireLease makes sure only one replica holds the key until the ttl expires. The holder renews it by acquiring again.
+// Both the acquisition and the renewal are compare and swap so two replicas never hold the same lease.
+func AcquireLease(key string, ttl time.Duration) (bool, error) {
+	store := GetStore()
+	acquired, err := store.CompareAndSwap(key, nil, []byte(replicaID), ttl)
+	if err != nil || acquired {
+		return acquired, err
+	}
+	return store.CompareAndSwap(key, []byte(replicaID), []byte(replicaID), ttl)
+}
+
+// RequireShared returns the error if the store is only in the memory of this replica. The state of the workflow driven
+// by the lease such as the canary must survive the restart and be seen by the other replicas. The demo mode is allowed
+// since its fake backend is also in memory.
+func RequireShared(feature string) error {
+	if GetStore().GetKind() == KindMemory && beego.AppConfig.DefaultBool("demoMode", false) == false {
+		return errors.New(feature + " requires the shared session store. Set sessionStore to file, redis or etcd.")
+	}
+	return nil
+}
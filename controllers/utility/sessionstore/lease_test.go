@@ -0,0 +1,113 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompareAndSwap(t *testing.T) {
+	storeSlice, cleanup := newTestStoreSlice(t)
+	defer cleanup()
+
+	for _, store := range storeSlice {
+		Convey("Subject: Compare and swap of the "+store.GetKind()+" store\n", t, func() {
+			store.Delete("cas")
+
+			swapped, err := store.CompareAndSwap("cas", nil, []byte("a"), 0)
+			So(err, ShouldBeNil)
+			So(swapped, ShouldBeTrue)
+
+			testCaseSlice := []struct {
+				description string
+				oldValue    []byte
+				newValue    []byte
+				swapped     bool
+				value       string
+			}{
+				{"The existing key is not created again", nil, []byte("b"), false, "a"},
+				{"The different old value is not swapped", []byte("b"), []byte("c"), false, "a"},
+				{"The same old value is swapped", []byte("a"), []byte("c"), true, "c"},
+				{"The empty value is a value", []byte("c"), []byte{}, true, ""},
+				{"The empty value is not absent", nil, []byte("d"), false, ""},
+			}
+			for _, testCase := range testCaseSlice {
+				swapped, err := store.CompareAndSwap("cas", testCase.oldValue, testCase.newValue, 0)
+				So(err, ShouldBeNil)
+				So(swapped, ShouldEqual, testCase.swapped)
+				value, err := store.Get("cas")
+				So(err, ShouldBeNil)
+				So(string(value), ShouldEqual, testCase.value)
+			}
+
+			Convey("Only one of the concurrent creations succeeds", func() {
+				store.Delete("cas")
+				waitGroup := sync.WaitGroup{}
+				lock := sync.Mutex{}
+				swappedAmount := 0
+				for i := 0; i < 20; i++ {
+					waitGroup.Add(1)
+					go func() {
+						defer waitGroup.Done()
+						swapped, err := store.CompareAndSwap("cas", nil, []byte("owner"), time.Minute)
+						if err == nil && swapped {
+							lock.Lock()
+							swappedAmount++
+							lock.Unlock()
+						}
+					}()
+				}
+				waitGroup.Wait()
+				So(swappedAmount, ShouldEqual, 1)
+			})
+		})
+	}
+}
+
+func TestAcquireLease(t *testing.T) {
+	storeLock.Lock()
+	defaultStore = newMemoryStore()
+	storeLock.Unlock()
+
+	Convey("Subject: Lease\n", t, func() {
+		acquired, err := AcquireLease("lease", time.Minute)
+		So(err, ShouldBeNil)
+		So(acquired, ShouldBeTrue)
+
+		Convey("The holder renews the lease", func() {
+			acquired, err := AcquireLease("lease", time.Minute)
+			So(err, ShouldBeNil)
+			So(acquired, ShouldBeTrue)
+		})
+		Convey("The lease of another replica is not acquired until it expires", func() {
+			GetStore().Set("lease", []byte("another"), 10*time.Millisecond)
+			acquired, err := AcquireLease("lease", time.Minute)
+			So(err, ShouldBeNil)
+			So(acquired, ShouldBeFalse)
+
+			time.Sleep(20 * time.Millisecond)
+			acquired, err = AcquireLease("lease", time.Minute)
+			So(err, ShouldBeNil)
+			So(acquired, ShouldBeTrue)
+		})
+		Convey("The memory store is not shared", func() {
+			So(RequireShared("The canary deployment"), ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,122 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+type memoryItem struct {
+	value       []byte
+	expiredTime time.Time
+}
+
+// memoryStore only works with one replica
+type memoryStore struct {
+	itemMap map[string]memoryItem
+	lock    sync.Mutex
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{itemMap: make(map[string]memoryItem)}
+}
+
+func (store *memoryStore) GetKind() string {
+	return KindMemory
+}
+
+// getItem must be called with the lock held
+func (store *memoryStore) getItem(key string) ([]byte, bool) {
+	item, ok := store.itemMap[key]
+	if ok == false {
+		return nil, false
+	}
+	if isExpired(item.expiredTime) {
+		delete(store.itemMap, key)
+		return nil, false
+	}
+	return item.value, true
+}
+
+func (store *memoryStore) Get(key string) ([]byte, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	value, _ := store.getItem(key)
+	return value, nil
+}
+
+func (store *memoryStore) Set(key string, value []byte, ttl time.Duration) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	store.itemMap[key] = memoryItem{value, getExpiredTime(ttl)}
+	return nil
+}
+
+func (store *memoryStore) Delete(key string) error {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	delete(store.itemMap, key)
+	return nil
+}
+
+func (store *memoryStore) Take(key string) ([]byte, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	value, _ := store.getItem(key)
+	delete(store.itemMap, key)
+	return value, nil
+}
+
+func (store *memoryStore) CompareAndSwap(key string, oldValue []byte, newValue []byte, ttl time.Duration) (bool, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	value, existing := store.getItem(key)
+	if isValueMatched(value, existing, oldValue) == false {
+		return false, nil
+	}
+	store.itemMap[key] = memoryItem{newValue, getExpiredTime(ttl)}
+	return true, nil
+}
+
+func (store *memoryStore) List(prefix string) ([]string, error) {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	keySlice := make([]string, 0)
+	for key := range store.itemMap {
+		if strings.HasPrefix(key, prefix) {
+			if _, ok := store.getItem(key); ok {
+				keySlice = append(keySlice, key)
+			}
+		}
+	}
+	return keySlice, nil
+}
+
+func (store *memoryStore) GC() {
+	store.lock.Lock()
+	defer store.lock.Unlock()
+
+	for key := range store.itemMap {
+		store.getItem(key)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/session"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// ProviderName is the beego session provider backed by the store
+	ProviderName = "sessionstore"
+
+	sessionKeyPrefix = "session/"
+	// The session changed by the concurrent requests is saved again with the value read at most this times
+	sessionReleaseAttemptMaximum = 3
+)
+
+// provider keeps each session as the gob encoded values so any replica could read it.
+// The types kept in the session must be registered with gob.Register by the package using them.
+type provider struct {
+	store    Store
+	lifetime time.Duration
+}
+
+func (p *provider) SessionInit(gclifetime int64, config string) error {
+	p.lifetime = time.Duration(gclifetime) * time.Second
+	return nil
+}
+
+func (p *provider) SessionRead(sid string) (session.Store, error) {
+	valueMap := make(map[interface{}]interface{})
+
+	byteSlice, err := p.store.Get(sessionKeyPrefix + sid)
+	if err != nil {
+		return nil, err
+	}
+	if len(byteSlice) > 0 {
+		decodedValueMap, err := session.DecodeGob(byteSlice)
+		if err != nil {
+			// Such as the type removed by the upgrade. The user logins again.
+			beego.Error("Fail to decode the session", err)
+		} else {
+			valueMap = decodedValueMap
+		}
+	}
+
+	return &sessionState{sid: sid, valueMap: valueMap, loadedByteSlice: byteSlice, provider: p}, nil
+}
+
+func (p *provider) SessionExist(sid string) bool {
+	byteSlice, err := p.store.Get(sessionKeyPrefix + sid)
+	return err == nil && byteSlice != nil
+}
+
+func (p *provider) SessionRegenerate(oldsid, sid string) (session.Store, error) {
+	byteSlice, err := p.store.Take(sessionKeyPrefix + oldsid)
+	if err != nil {
+		return nil, err
+	}
+	if byteSlice != nil {
+		if err := p.store.Set(sessionKeyPrefix+sid, byteSlice, p.lifetime); err != nil {
+			return nil, err
+		}
+	}
+	return p.SessionRead(sid)
+}
+
+func (p *provider) SessionDestroy(sid string) error {
+	return p.store.Delete(sessionKeyPrefix + sid)
+}
+
+func (p *provider) SessionAll() int {
+	keySlice, err := p.store.List(sessionKeyPrefix)
+	if err != nil {
+		beego.Error("Fail to list the sessions", err)
+		return 0
+	}
+	return len(keySlice)
+}
+
+func (p *provider) SessionGC() {
+	p.store.GC()
+}
+
+// sessionState is the session of one request. It is written back to the store when the request ends.
+// The value read is kept to save the session only if it is not destroyed during the request.
+type sessionState struct {
+	sid             string
+	valueMap        map[interface{}]interface{}
+	loadedByteSlice []byte
+	provider        *provider
+	lock            sync.RWMutex
+}
+
+func (state *sessionState) Set(key, value interface{}) error {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	state.valueMap[key] = value
+	return nil
+}
+
+func (state *sessionState) Get(key interface{}) interface{} {
+	state.lock.RLock()
+	defer state.lock.RUnlock()
+
+	return state.valueMap[key]
+}
+
+func (state *sessionState) Delete(key interface{}) error {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	delete(state.valueMap, key)
+	return nil
+}
+
+func (state *sessionState) SessionID() string {
+	return state.sid
+}
+
+// SessionRelease saves the values and renews the expiration even if nothing changes. The session revoked or logged out
+// by the other request is not saved again. The session changed by the concurrent request of it is overwritten
+// like the last write wins.
+func (state *sessionState) SessionRelease(w http.ResponseWriter) {
+	state.lock.RLock()
+	byteSlice, err := session.EncodeGob(state.valueMap)
+	state.lock.RUnlock()
+	if err != nil {
+		beego.Error("Fail to encode the session", state.sid, err)
+		return
+	}
+
+	key := sessionKeyPrefix + state.sid
+	oldByteSlice := state.loadedByteSlice
+	for i := 0; i < sessionReleaseAttemptMaximum; i++ {
+		swapped, err := state.provider.store.CompareAndSwap(key, oldByteSlice, byteSlice, state.provider.lifetime)
+		if err != nil {
+			beego.Error("Fail to save the session", state.sid, err)
+			return
+		}
+		if swapped {
+			return
+		}
+
+		oldByteSlice, err = state.provider.store.Get(key)
+		if err != nil {
+			beego.Error("Fail to save the session", state.sid, err)
+			return
+		}
+		if oldByteSlice == nil {
+			// Destroyed during the request
+			return
+		}
+	}
+	beego.Error("Fail to save the session changed by the concurrent requests", state.sid)
+}
+
+func (state *sessionState) Flush() error {
+	state.lock.Lock()
+	defer state.lock.Unlock()
+
+	state.valueMap = make(map[interface{}]interface{})
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestSessionRelease(t *testing.T) {
+	storeSlice, cleanup := newTestStoreSlice(t)
+	defer cleanup()
+
+	for _, store := range storeSlice {
+		Convey("Subject: The session of the "+store.GetKind()+" store is saved unless it is destroyed during the request\n", t, func() {
+			p := &provider{store: store, lifetime: time.Minute}
+			for _, sid := range []string{"created", "destroyed", "concurrent"} {
+				p.SessionDestroy(sid)
+			}
+
+			Convey("The new session is saved", func() {
+				state, err := p.SessionRead("created")
+				So(err, ShouldBeNil)
+				state.Set("username", "operator")
+				state.SessionRelease(nil)
+
+				So(p.SessionExist("created"), ShouldBeTrue)
+				savedState, err := p.SessionRead("created")
+				So(err, ShouldBeNil)
+				So(savedState.Get("username"), ShouldEqual, "operator")
+			})
+
+			Convey("The session destroyed during the request is not saved again", func() {
+				state, _ := p.SessionRead("destroyed")
+				state.Set("username", "operator")
+				state.SessionRelease(nil)
+
+				state, err := p.SessionRead("destroyed")
+				So(err, ShouldBeNil)
+				So(p.SessionDestroy("destroyed"), ShouldBeNil)
+				state.Set("namespace", "default")
+				state.SessionRelease(nil)
+
+				So(p.SessionExist("destroyed"), ShouldBeFalse)
+			})
+
+			Convey("The session changed by the concurrent request is saved by the last one", func() {
+				state, _ := p.SessionRead("concurrent")
+				state.Set("username", "operator")
+				state.SessionRelease(nil)
+
+				firstState, _ := p.SessionRead("concurrent")
+				secondState, _ := p.SessionRead("concurrent")
+				firstState.Set("namespace", "first")
+				firstState.SessionRelease(nil)
+				secondState.Set("namespace", "second")
+				secondState.SessionRelease(nil)
+
+				savedState, err := p.SessionRead("concurrent")
+				So(err, ShouldBeNil)
+				So(savedState.Get("username"), ShouldEqual, "operator")
+				So(savedState.Get("namespace"), ShouldEqual, "second")
+			})
+		})
+	}
+}
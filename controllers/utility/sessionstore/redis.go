@@ -0,0 +1,348 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	redisConnectionPoolSize = 8
+	redisScanCount          = "100"
+	// redisCompareAndSwapScript runs atomically in redis. ARGV is whether the key must not exist, the old value, the new value and the ttl in millisecond.
+	redisCompareAndSwapScript = `
+local value = redis.call('GET', KEYS[1])
+if ARGV[1] == '1' then
+	if value then
+		return 0
+	end
+elseif value ~= ARGV[2] then
+	return 0
+end
+if ARGV[4] == '0' then
+	redis.call('SET', KEYS[1], ARGV[3])
+else
+	redis.call('SET', KEYS[1], ARGV[3], 'PX', ARGV[4])
+end
+return 1`
+)
+
+// redisStore speaks the RESP protocol of redis directly with a small connection pool
+type redisStore struct {
+	address        string
+	password       string
+	timeout        time.Duration
+	connectionPool chan *redisConnection
+}
+
+type redisConnection struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// redisNil is the null bulk string returned for the missing key
+type redisNil struct{}
+
+func newRedisStore(address string, password string, timeout time.Duration) (*redisStore, error) {
+	if address == "" {
+		return nil, errors.New("sessionStoreAddress is required to be host:port of the redis session store")
+	}
+	store := &redisStore{
+		address,
+		password,
+		timeout,
+		make(chan *redisConnection, redisConnectionPoolSize),
+	}
+
+	// Fail at startup if redis is not reachable
+	connection, err := store.getConnection()
+	if err != nil {
+		return nil, err
+	}
+	store.putConnection(connection)
+
+	return store, nil
+}
+
+func (store *redisStore) GetKind() string {
+	return KindRedis
+}
+
+func (store *redisStore) getConnection() (*redisConnection, error) {
+	select {
+	case connection := <-store.connectionPool:
+		return connection, nil
+	default:
+	}
+
+	conn, err := net.DialTimeout("tcp", store.address, store.timeout)
+	if err != nil {
+		return nil, err
+	}
+	connection := &redisConnection{conn, bufio.NewReader(conn)}
+	if store.password != "" {
+		if _, err := connection.do(store.timeout, "AUTH", store.password); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return connection, nil
+}
+
+func (store *redisStore) putConnection(connection *redisConnection) {
+	select {
+	case store.connectionPool <- connection:
+	default:
+		connection.conn.Close()
+	}
+}
+
+// do sends the commands in a pipeline and returns the reply of each
+func (store *redisStore) do(commandSlice ...[]string) ([]interface{}, error) {
+	connection, err := store.getConnection()
+	if err != nil {
+		return nil, err
+	}
+
+	connection.conn.SetDeadline(time.Now().Add(store.timeout))
+	for _, command := range commandSlice {
+		if err := connection.write(command...); err != nil {
+			connection.conn.Close()
+			return nil, err
+		}
+	}
+
+	// All replies are read even after the error reply so the connection is reusable
+	replySlice := make([]interface{}, 0)
+	var replyError error
+	for range commandSlice {
+		reply, err := connection.read()
+		if err != nil {
+			if _, ok := err.(redisError); ok == false {
+				// The connection state is unknown after the network error
+				connection.conn.Close()
+				return nil, err
+			}
+			if replyError == nil {
+				replyError = err
+			}
+		}
+		replySlice = append(replySlice, reply)
+	}
+
+	store.putConnection(connection)
+	if replyError != nil {
+		return nil, replyError
+	}
+	return replySlice, nil
+}
+
+func (connection *redisConnection) do(timeout time.Duration, argumentSlice ...string) (interface{}, error) {
+	connection.conn.SetDeadline(time.Now().Add(timeout))
+	if err := connection.write(argumentSlice...); err != nil {
+		return nil, err
+	}
+	return connection.read()
+}
+
+func (connection *redisConnection) write(argumentSlice ...string) error {
+	writer := bufio.NewWriter(connection.conn)
+	writer.WriteString("*" + strconv.Itoa(len(argumentSlice)) + "\r\n")
+	for _, argument := range argumentSlice {
+		writer.WriteString("$" + strconv.Itoa(len(argument)) + "\r\n" + argument + "\r\n")
+	}
+	return writer.Flush()
+}
+
+type redisError string
+
+func (err redisError) Error() string {
+	return "Redis error " + string(err)
+}
+
+func (connection *redisConnection) readLine() (string, error) {
+	line, err := connection.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(line, "\r\n"), nil
+}
+
+// read parses one reply. The bulk string is []byte, the integer is int64 and the array is []interface{}.
+func (connection *redisConnection) read() (interface{}, error) {
+	line, err := connection.readLine()
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("Redis reply is empty")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return nil, redisError(line[1:])
+	case ':':
+		return strconv.ParseInt(line[1:], 10, 64)
+	case '$':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return redisNil{}, nil
+		}
+		byteSlice := make([]byte, length+2)
+		if _, err := io.ReadFull(connection.reader, byteSlice); err != nil {
+			return nil, err
+		}
+		return byteSlice[:length], nil
+	case '*':
+		length, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, err
+		}
+		if length < 0 {
+			return redisNil{}, nil
+		}
+		replySlice := make([]interface{}, 0, length)
+		for i := 0; i < length; i++ {
+			reply, err := connection.read()
+			if err != nil {
+				return nil, err
+			}
+			replySlice = append(replySlice, reply)
+		}
+		return replySlice, nil
+	default:
+		return nil, errors.New("Redis reply " + line + " is not supported")
+	}
+}
+
+func toByteSlice(reply interface{}) []byte {
+	byteSlice, _ := reply.([]byte)
+	return byteSlice
+}
+
+func (store *redisStore) Get(key string) ([]byte, error) {
+	replySlice, err := store.do([]string{"GET", key})
+	if err != nil {
+		return nil, err
+	}
+	return toByteSlice(replySlice[0]), nil
+}
+
+func (store *redisStore) Set(key string, value []byte, ttl time.Duration) error {
+	command := []string{"SET", key, string(value)}
+	if ttl > 0 {
+		command = append(command, "PX", strconv.FormatInt(int64(ttl/time.Millisecond), 10))
+	}
+	_, err := store.do(command)
+	return err
+}
+
+func (store *redisStore) Delete(key string) error {
+	_, err := store.do([]string{"DEL", key})
+	return err
+}
+
+func (store *redisStore) Take(key string) ([]byte, error) {
+	// The transaction works on the versions of redis without GETDEL
+	replySlice, err := store.do([]string{"MULTI"}, []string{"GET", key}, []string{"DEL", key}, []string{"EXEC"})
+	if err != nil {
+		return nil, err
+	}
+	execReplySlice, ok := replySlice[3].([]interface{})
+	if ok == false || len(execReplySlice) != 2 {
+		return nil, errors.New("Redis transaction is aborted")
+	}
+	return toByteSlice(execReplySlice[0]), nil
+}
+
+func (store *redisStore) CompareAndSwap(key string, oldValue []byte, newValue []byte, ttl time.Duration) (bool, error) {
+	absent := "0"
+	if oldValue == nil {
+		absent = "1"
+	}
+	millisecond := int64(0)
+	if ttl > 0 {
+		millisecond = int64(ttl / time.Millisecond)
+		if millisecond < 1 {
+			millisecond = 1
+		}
+	}
+	replySlice, err := store.do([]string{"EVAL", redisCompareAndSwapScript, "1", key, absent, string(oldValue), string(newValue), strconv.FormatInt(millisecond, 10)})
+	if err != nil {
+		return false, err
+	}
+	swapped, ok := replySlice[0].(int64)
+	if ok == false {
+		return false, errors.New("Redis reply of compare and swap is invalid")
+	}
+	return swapped == 1, nil
+}
+
+func escapeRedisPattern(text string) string {
+	buffer := make([]byte, 0, len(text))
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '*', '?', '[', ']', '\\':
+			buffer = append(buffer, '\\')
+		}
+		buffer = append(buffer, text[i])
+	}
+	return string(buffer)
+}
+
+func (store *redisStore) List(prefix string) ([]string, error) {
+	keyMap := make(map[string]bool)
+	cursor := "0"
+	for {
+		replySlice, err := store.do([]string{"SCAN", cursor, "MATCH", escapeRedisPattern(prefix) + "*", "COUNT", redisScanCount})
+		if err != nil {
+			return nil, err
+		}
+		scanReplySlice, ok := replySlice[0].([]interface{})
+		if ok == false || len(scanReplySlice) != 2 {
+			return nil, errors.New("Redis reply of SCAN is invalid")
+		}
+		// SCAN may return the same key more than once
+		keyReplySlice, _ := scanReplySlice[1].([]interface{})
+		for _, keyReply := range keyReplySlice {
+			keyMap[string(toByteSlice(keyReply))] = true
+		}
+		cursor = string(toByteSlice(scanReplySlice[0]))
+		if cursor == "0" || cursor == "" {
+			break
+		}
+	}
+
+	keySlice := make([]string, 0, len(keyMap))
+	for key := range keyMap {
+		keySlice = append(keySlice, key)
+	}
+	return keySlice, nil
+}
+
+// GC does nothing since redis expires the keys
+func (store *redisStore) GC() {
+}
@@ -0,0 +1,131 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"bytes"
+	"errors"
+	"github.com/astaxie/beego"
+	"github.com/astaxie/beego/session"
+	"sync"
+	"time"
+)
+
+const (
+	KindMemory = "memory"
+	KindFile   = "file"
+	KindRedis  = "redis"
+	KindEtcd   = "etcd"
+
+	defaultTimeoutInSecond = 5
+)
+
+// Store keeps the state shared by the GUI replicas such as the sessions and the websocket tickets.
+// The value expires after ttl and never expires if ttl is 0.
+type Store interface {
+	GetKind() string
+	// Get returns nil if the key doesn't exist or is expired
+	Get(key string) ([]byte, error)
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+	// Take gets and deletes the key atomically so only one replica gets the value
+	Take(key string) ([]byte, error)
+	// CompareAndSwap sets the value only if the current value is oldValue atomically and returns whether it is set.
+	// The nil oldValue means the key doesn't exist or is expired so only one replica creates the key.
+	CompareAndSwap(key string, oldValue []byte, newValue []byte, ttl time.Duration) (bool, error)
+	// List returns the keys with the prefix
+	List(prefix string) ([]string, error)
+	// GC removes the expired keys for the store without the expiration of its own
+	GC()
+}
+
+var storeLock = sync.Mutex{}
+var defaultStore Store
+
+// NewStore creates the store of the kind. The address is the directory for file,
+// host:port for redis and the URL of the v3 JSON gateway such as http://127.0.0.1:2379 for etcd.
+func NewStore(kind string, address string, password string, timeout time.Duration) (Store, error) {
+	switch kind {
+	case "", KindMemory:
+		return newMemoryStore(), nil
+	case KindFile:
+		return newFileStore(address)
+	case KindRedis:
+		return newRedisStore(address, password, timeout)
+	case KindEtcd:
+		return newEtcdStore(address, timeout)
+	default:
+		return nil, errors.New("Session store " + kind + " is not supported. Use memory, file, redis or etcd")
+	}
+}
+
+// Initialize creates the store configured by sessionStore and lets beego keep the sessions in it.
+// The in-memory provider of beego is kept for memory since the sessions don't need to be serialized.
+func Initialize() error {
+	store, err := NewStore(
+		beego.AppConfig.DefaultString("sessionStore", KindMemory),
+		beego.AppConfig.String("sessionStoreAddress"),
+		beego.AppConfig.String("sessionStorePassword"),
+		time.Duration(beego.AppConfig.DefaultInt("sessionStoreTimeoutInSecond", defaultTimeoutInSecond))*time.Second,
+	)
+	if err != nil {
+		return err
+	}
+
+	storeLock.Lock()
+	defaultStore = store
+	storeLock.Unlock()
+
+	if store.GetKind() != KindMemory {
+		session.Register(ProviderName, &provider{store: store})
+		beego.BConfig.WebConfig.Session.SessionProvider = ProviderName
+	}
+	return nil
+}
+
+// GetStore returns the store created by Initialize or the memory store if it is not initialized
+func GetStore() Store {
+	storeLock.Lock()
+	defer storeLock.Unlock()
+
+	if defaultStore == nil {
+		defaultStore = newMemoryStore()
+	}
+	return defaultStore
+}
+
+// GetSessionLifetime is how long the session and the state bound to it are kept without the activity
+func GetSessionLifetime() time.Duration {
+	return time.Duration(beego.BConfig.WebConfig.Session.SessionGCMaxLifetime) * time.Second
+}
+
+func getExpiredTime(ttl time.Duration) time.Time {
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(ttl)
+}
+
+func isExpired(expiredTime time.Time) bool {
+	return expiredTime.IsZero() == false && time.Now().After(expiredTime)
+}
+
+// isValueMatched must be called with the current value read atomically with the swap
+func isValueMatched(value []byte, existing bool, oldValue []byte) bool {
+	if oldValue == nil {
+		return existing == false
+	}
+	return existing && bytes.Equal(value, oldValue)
+}
@@ -0,0 +1,363 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sessionstore
+
+import (
+	"bufio"
+	"github.com/cloudawan/cloudone_gui/tool/etcdstandin/standin"
+	"io/ioutil"
+	"net"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+const testRedisPassword = "password"
+
+// redisStandIn serves the commands used by the redis store over RESP with the keys in a memory store.
+// There is no Lua here so the compare and swap script is recognized by its text and run in Go.
+type redisStandIn struct {
+	listener net.Listener
+	store    *memoryStore
+}
+
+func newRedisStandIn() (*redisStandIn, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	standIn := &redisStandIn{listener, newMemoryStore()}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go standIn.serve(conn)
+		}
+	}()
+	return standIn, nil
+}
+
+func getRedisBulkString(text string) string {
+	return "$" + strconv.Itoa(len(text)) + "\r\n" + text + "\r\n"
+}
+
+func (standIn *redisStandIn) serve(conn net.Conn) {
+	defer conn.Close()
+	connection := &redisConnection{conn, bufio.NewReader(conn)}
+
+	authenticated := false
+	queuedSlice := make([][]string, 0)
+	inTransaction := false
+	for {
+		request, err := connection.read()
+		if err != nil {
+			return
+		}
+		requestSlice, _ := request.([]interface{})
+		argumentSlice := make([]string, 0)
+		for _, argument := range requestSlice {
+			argumentSlice = append(argumentSlice, string(toByteSlice(argument)))
+		}
+		if len(argumentSlice) == 0 {
+			return
+		}
+
+		reply := ""
+		switch command := strings.ToUpper(argumentSlice[0]); {
+		case command == "AUTH":
+			authenticated = len(argumentSlice) == 2 && argumentSlice[1] == testRedisPassword
+			if authenticated {
+				reply = "+OK\r\n"
+			} else {
+				reply = "-WRONGPASS invalid password\r\n"
+			}
+		case authenticated == false:
+			reply = "-NOAUTH Authentication required.\r\n"
+		case command == "MULTI":
+			inTransaction = true
+			queuedSlice = make([][]string, 0)
+			reply = "+OK\r\n"
+		case command == "EXEC":
+			standIn.store.lock.Lock()
+			reply = "*" + strconv.Itoa(len(queuedSlice)) + "\r\n"
+			for _, queued := range queuedSlice {
+				reply += standIn.execute(queued)
+			}
+			standIn.store.lock.Unlock()
+			inTransaction = false
+		case inTransaction:
+			queuedSlice = append(queuedSlice, argumentSlice)
+			reply = "+QUEUED\r\n"
+		default:
+			standIn.store.lock.Lock()
+			reply = standIn.execute(argumentSlice)
+			standIn.store.lock.Unlock()
+		}
+		if _, err := conn.Write([]byte(reply)); err != nil {
+			return
+		}
+	}
+}
+
+// execute must be called with the lock of the store held
+func (standIn *redisStandIn) execute(argumentSlice []string) string {
+	store := standIn.store
+	switch strings.ToUpper(argumentSlice[0]) {
+	case "GET":
+		value, ok := store.getItem(argumentSlice[1])
+		if ok == false {
+			return "$-1\r\n"
+		}
+		return getRedisBulkString(string(value))
+	case "SET":
+		ttl := time.Duration(0)
+		if len(argumentSlice) == 5 && strings.ToUpper(argumentSlice[3]) == "PX" {
+			millisecond, _ := strconv.ParseInt(argumentSlice[4], 10, 64)
+			ttl = time.Duration(millisecond) * time.Millisecond
+		}
+		store.itemMap[argumentSlice[1]] = memoryItem{[]byte(argumentSlice[2]), getExpiredTime(ttl)}
+		return "+OK\r\n"
+	case "DEL":
+		_, ok := store.getItem(argumentSlice[1])
+		delete(store.itemMap, argumentSlice[1])
+		if ok {
+			return ":1\r\n"
+		}
+		return ":0\r\n"
+	case "SCAN":
+		// Only the pattern of the escaped prefix followed by * is supported
+		pattern := argumentSlice[3]
+		if strings.HasSuffix(pattern, "*") == false {
+			return "-ERR pattern is not supported\r\n"
+		}
+		prefix := ""
+		for i := 0; i < len(pattern)-1; i++ {
+			if pattern[i] == '\\' {
+				i++
+			}
+			prefix += string(pattern[i])
+		}
+		keyReply := ""
+		amount := 0
+		for key := range store.itemMap {
+			if _, ok := store.getItem(key); ok && strings.HasPrefix(key, prefix) {
+				keyReply += getRedisBulkString(key)
+				amount++
+			}
+		}
+		return "*2\r\n" + getRedisBulkString("0") + "*" + strconv.Itoa(amount) + "\r\n" + keyReply
+	case "EVAL":
+		if argumentSlice[1] != redisCompareAndSwapScript {
+			return "-ERR script is not supported\r\n"
+		}
+		key, absent, oldValue, newValue, millisecond := argumentSlice[3], argumentSlice[4], argumentSlice[5], argumentSlice[6], argumentSlice[7]
+		value, ok := store.getItem(key)
+		if (absent == "1" && ok) || (absent != "1" && (ok == false || string(value) != oldValue)) {
+			return ":0\r\n"
+		}
+		ttl, _ := strconv.ParseInt(millisecond, 10, 64)
+		store.itemMap[key] = memoryItem{[]byte(newValue), getExpiredTime(time.Duration(ttl) * time.Millisecond)}
+		return ":1\r\n"
+	default:
+		return "-ERR unknown command " + argumentSlice[0] + "\r\n"
+	}
+}
+
+// newTestStoreSlice creates the store of each kind. The redis and etcd stores use the stand-ins.
+func newTestStoreSlice(t *testing.T) ([]Store, func()) {
+	directory, err := ioutil.TempDir("", "sessionstore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileStore, err := newFileStore(directory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	redis, err := newRedisStandIn()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newRedisStore(redis.listener.Addr().String(), "wrong", time.Second); err == nil {
+		t.Fatal("The wrong password of redis is accepted")
+	}
+	redisStore, err := newRedisStore(redis.listener.Addr().String(), testRedisPassword, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	etcd := httptest.NewServer(standin.NewHandler())
+	etcdStore, err := newEtcdStore(etcd.URL, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return []Store{newMemoryStore(), fileStore, redisStore, etcdStore}, func() {
+		etcd.Close()
+		redis.listener.Close()
+		os.RemoveAll(directory)
+	}
+}
+
+func TestStore(t *testing.T) {
+	storeSlice, cleanup := newTestStoreSlice(t)
+	defer cleanup()
+
+	for _, store := range storeSlice {
+		Convey("Subject: Semantics of the "+store.GetKind()+" store\n", t, func() {
+			Convey("The value set is got", func() {
+				testCaseSlice := []struct {
+					key   string
+					value []byte
+				}{
+					{"text", []byte("value")},
+					{"empty", []byte{}},
+					{"binary", []byte{0, 255, '\r', '\n'}},
+					{"escaped key/with space?", []byte("value")},
+				}
+				for _, testCase := range testCaseSlice {
+					So(store.Set(testCase.key, testCase.value, time.Minute), ShouldBeNil)
+					value, err := store.Get(testCase.key)
+					So(err, ShouldBeNil)
+					So(string(value), ShouldEqual, string(testCase.value))
+				}
+
+				So(store.Set("text", []byte("overwritten"), 0), ShouldBeNil)
+				value, err := store.Get("text")
+				So(err, ShouldBeNil)
+				So(string(value), ShouldEqual, "overwritten")
+			})
+
+			Convey("The missing key is nil", func() {
+				value, err := store.Get("missing")
+				So(err, ShouldBeNil)
+				So(value, ShouldBeNil)
+				So(store.Delete("missing"), ShouldBeNil)
+				value, err = store.Take("missing")
+				So(err, ShouldBeNil)
+				So(value, ShouldBeNil)
+			})
+
+			Convey("The key deleted is missing", func() {
+				So(store.Set("deleted", []byte("value"), 0), ShouldBeNil)
+				So(store.Delete("deleted"), ShouldBeNil)
+				value, err := store.Get("deleted")
+				So(err, ShouldBeNil)
+				So(value, ShouldBeNil)
+			})
+
+			Convey("The key taken is deleted", func() {
+				So(store.Set("taken", []byte("value"), time.Minute), ShouldBeNil)
+				value, err := store.Take("taken")
+				So(err, ShouldBeNil)
+				So(string(value), ShouldEqual, "value")
+				value, err = store.Take("taken")
+				So(err, ShouldBeNil)
+				So(value, ShouldBeNil)
+			})
+
+			Convey("Only one of the concurrent takes gets the value", func() {
+				for round := 0; round < 5; round++ {
+					So(store.Set("ticket", []byte("value"), time.Minute), ShouldBeNil)
+					waitGroup := sync.WaitGroup{}
+					lock := sync.Mutex{}
+					takenAmount := 0
+					for i := 0; i < 20; i++ {
+						waitGroup.Add(1)
+						go func() {
+							defer waitGroup.Done()
+							value, err := store.Take("ticket")
+							if err == nil && value != nil {
+								lock.Lock()
+								takenAmount++
+								lock.Unlock()
+							}
+						}()
+					}
+					waitGroup.Wait()
+					So(takenAmount, ShouldEqual, 1)
+				}
+			})
+
+			Convey("The keys with the prefix are listed", func() {
+				for _, key := range []string{"list/a", "list/b", "listx", "glob*/a", "globx/a"} {
+					So(store.Set(key, []byte("value"), time.Minute), ShouldBeNil)
+				}
+				testCaseSlice := []struct {
+					prefix   string
+					keySlice []string
+				}{
+					{"list/", []string{"list/a", "list/b"}},
+					{"list", []string{"list/a", "list/b", "listx"}},
+					{"glob*/", []string{"glob*/a"}},
+					{"none/", []string{}},
+				}
+				for _, testCase := range testCaseSlice {
+					keySlice, err := store.List(testCase.prefix)
+					So(err, ShouldBeNil)
+					sort.Strings(keySlice)
+					So(keySlice, ShouldResemble, testCase.keySlice)
+				}
+			})
+		})
+	}
+}
+
+func TestStoreExpiry(t *testing.T) {
+	storeSlice, cleanup := newTestStoreSlice(t)
+	defer cleanup()
+
+	// The lease of etcd is at least one second so all stores wait once
+	for _, store := range storeSlice {
+		for _, key := range []string{"expiry/get", "expiry/take", "expiry/cas"} {
+			if err := store.Set(key, []byte("value"), time.Second); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := store.Set("expiry/kept", []byte("value"), time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(1500 * time.Millisecond)
+
+	for _, store := range storeSlice {
+		Convey("Subject: Expiry of the "+store.GetKind()+" store\n", t, func() {
+			value, err := store.Get("expiry/get")
+			So(err, ShouldBeNil)
+			So(value, ShouldBeNil)
+
+			value, err = store.Take("expiry/take")
+			So(err, ShouldBeNil)
+			So(value, ShouldBeNil)
+
+			keySlice, err := store.List("expiry/")
+			So(err, ShouldBeNil)
+			So(keySlice, ShouldResemble, []string{"expiry/kept"})
+
+			swapped, err := store.CompareAndSwap("expiry/cas", nil, []byte("created"), time.Minute)
+			So(err, ShouldBeNil)
+			So(swapped, ShouldBeTrue)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"github.com/astaxie/beego"
+	beegocontext "github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"golang.org/x/net/websocket"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultGracePeriodInSecond = 30
+	// retryAfterInSecond tells the client to retry when the other replica or the restarted GUI is serving
+	retryAfterInSecond = 5
+)
+
+var ErrDraining = errors.New("The GUI server is shutting down. Please try again later.")
+
+type webSocketSession struct {
+	ws   *websocket.Conn
+	kind string
+	// waited is true for the operation given the grace period to finish such as the upgrade and the terminal.
+	// The session only following the output such as the build log is closed at once.
+	waited bool
+}
+
+type drainer struct {
+	lock                sync.Mutex
+	draining            bool
+	lastID              int
+	webSocketSessionMap map[int]*webSocketSession
+	// endedChannel is signaled whenever a websocket session ends so draining checks whether any is left
+	endedChannel chan struct{}
+	// stoppedChannel is closed after the in-flight requests end
+	stoppedChannel chan struct{}
+}
+
+var theDrainer = &drainer{
+	webSocketSessionMap: make(map[int]*webSocketSession),
+	endedChannel:        make(chan struct{}, 1),
+	stoppedChannel:      make(chan struct{}),
+}
+
+// GetGracePeriod is how long the in-flight requests and websocket sessions are waited when the GUI shuts down
+func GetGracePeriod() time.Duration {
+	return time.Duration(beego.AppConfig.DefaultInt("shutdownGracePeriodInSecond", defaultGracePeriodInSecond)) * time.Second
+}
+
+// IsDraining is true after the GUI starts to shut down
+func IsDraining() bool {
+	theDrainer.lock.Lock()
+	defer theDrainer.lock.Unlock()
+	return theDrainer.draining
+}
+
+// FilterDraining rejects the new requests after the GUI starts to shut down so the SLB sends them to the other replicas
+func FilterDraining(ctx *beegocontext.Context) {
+	if IsDraining() == false {
+		return
+	}
+
+	ctx.Output.Header("Retry-After", strconv.Itoa(retryAfterInSecond))
+	ctx.Output.Header("Connection", "close")
+	ctx.Output.SetStatus(503)
+	if strings.HasPrefix(ctx.Input.URL(), "/guirestapi/") {
+		ctx.Output.JSON(guimessagedisplay.NewGUIErrorMessage(ErrDraining.Error()).SetResource(ctx.Input.URL()).SetRequestID(tracing.GetRequestIDFromContext(ctx)), false, false)
+	} else {
+		ctx.Output.Body([]byte(ErrDraining.Error()))
+	}
+}
+
+// StartWebSocketSession tracks the websocket until the returned function is called so shutting down tells the client and waits for it.
+// waited is true for the operation given the grace period to finish and false for the session only following the output.
+// ErrDraining is returned after the GUI starts to shut down.
+func StartWebSocketSession(ws *websocket.Conn, kind string, waited bool) (func(), error) {
+	theDrainer.lock.Lock()
+	defer theDrainer.lock.Unlock()
+
+	if theDrainer.draining {
+		return nil, ErrDraining
+	}
+
+	theDrainer.lastID++
+	id := theDrainer.lastID
+	theDrainer.webSocketSessionMap[id] = &webSocketSession{ws, kind, waited}
+
+	once := sync.Once{}
+	return func() {
+		once.Do(func() {
+			theDrainer.lock.Lock()
+			delete(theDrainer.webSocketSessionMap, id)
+			theDrainer.lock.Unlock()
+
+			select {
+			case theDrainer.endedChannel <- struct{}{}:
+			default:
+			}
+		})
+	}, nil
+}
+
+// getWebSocketSessionSlice must be called with the lock held
+func (drainer *drainer) getWebSocketSessionSlice() []*webSocketSession {
+	webSocketSessionSlice := make([]*webSocketSession, 0, len(drainer.webSocketSessionMap))
+	for _, webSocketSession := range drainer.webSocketSessionMap {
+		webSocketSessionSlice = append(webSocketSessionSlice, webSocketSession)
+	}
+	return webSocketSessionSlice
+}
+
+func (drainer *drainer) startDraining() []*webSocketSession {
+	drainer.lock.Lock()
+	defer drainer.lock.Unlock()
+	drainer.draining = true
+	return drainer.getWebSocketSessionSlice()
+}
+
+func (drainer *drainer) getRemainingWebSocketSessionSlice() []*webSocketSession {
+	drainer.lock.Lock()
+	defer drainer.lock.Unlock()
+	return drainer.getWebSocketSessionSlice()
+}
+
+// drain tells the websocket clients, closes the sessions only following the output and waits for the others until the deadline
+func (drainer *drainer) drain(deadline time.Time) {
+	webSocketSessionSlice := drainer.startDraining()
+	beego.Info("Start draining " + strconv.Itoa(len(webSocketSessionSlice)) + " websocket sessions")
+
+	for _, webSocketSession := range webSocketSessionSlice {
+		if webSocketSession.waited {
+			webSocketSession.ws.Write([]byte("\nThe GUI server is shutting down. The session is closed in " +
+				strconv.Itoa(int(time.Until(deadline).Round(time.Second).Seconds())) + " seconds if it is not done.\n"))
+		} else {
+			webSocketSession.ws.Write([]byte("\nThe GUI server is shutting down. Please refresh the page later.\n"))
+			webSocketSession.ws.Close()
+		}
+	}
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		remainingWebSocketSessionSlice := drainer.getRemainingWebSocketSessionSlice()
+		// The session only following the output may not be removed yet since it is removed after the handler returns
+		waitedAmount := 0
+		for _, webSocketSession := range remainingWebSocketSessionSlice {
+			if webSocketSession.waited {
+				waitedAmount++
+			}
+		}
+		if waitedAmount == 0 {
+			return
+		}
+
+		select {
+		case <-drainer.endedChannel:
+		case <-timer.C:
+			for _, webSocketSession := range remainingWebSocketSessionSlice {
+				beego.Warning("Close the " + webSocketSession.kind + " websocket session since the grace period ends")
+				webSocketSession.ws.Write([]byte("\nThe GUI server is shutting down. The session is closed.\n"))
+				webSocketSession.ws.Close()
+			}
+			return
+		}
+	}
+}
+
+// Shutdown stops accepting the new requests and websocket sessions, waits for the in-flight ones until the grace period ends
+// and then stops beego so beego.Run returns.
+func Shutdown(gracePeriod time.Duration) {
+	defer close(theDrainer.stoppedChannel)
+
+	deadline := time.Now().Add(gracePeriod)
+
+	theDrainer.drain(deadline)
+
+	// The in-flight requests still have the rest of the grace period. The hijacked websocket connections are not waited by the server.
+	ctx, cancel := context.WithDeadline(context.Background(), deadline)
+	defer cancel()
+	if err := beego.BeeApp.Server.Shutdown(ctx); err != nil {
+		beego.Error("Fail to wait for the in-flight requests with error " + err.Error())
+		beego.BeeApp.Server.Close()
+	}
+}
+
+// Wait returns after Shutdown completes. beego.Run returns once the listener is closed while the in-flight requests may not end yet.
+// It returns at once if the GUI doesn't start to shut down such as beego fails to listen.
+func Wait() {
+	if IsDraining() {
+		<-theDrainer.stoppedChannel
+	}
+}
+
+// HandleSignal shuts down after SIGINT or SIGTERM is received. The second signal exits at once.
+func HandleSignal() {
+	signalChannel := make(chan os.Signal, 2)
+	signal.Notify(signalChannel, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		receivedSignal := <-signalChannel
+		gracePeriod := GetGracePeriod()
+		beego.Info("Receive " + receivedSignal.String() + " and shut down in the grace period " + gracePeriod.String())
+		go func() {
+			receivedSignal := <-signalChannel
+			beego.Warning("Receive " + receivedSignal.String() + " again and exit without draining")
+			os.Exit(1)
+		}()
+		Shutdown(gracePeriod)
+	}()
+}
@@ -0,0 +1,166 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package shutdown
+
+import (
+	beegocontext "github.com/astaxie/beego/context"
+	"golang.org/x/net/websocket"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func resetTestDrainer() {
+	theDrainer = &drainer{
+		webSocketSessionMap: make(map[int]*webSocketSession),
+		endedChannel:        make(chan struct{}, 1),
+		stoppedChannel:      make(chan struct{}),
+	}
+}
+
+// testWebSocket is the server side of the websocket used by the drainer and the client side read by the test
+type testWebSocket struct {
+	server *websocket.Conn
+	client *websocket.Conn
+}
+
+// newTestWebSocketSlice connects the amount of websockets. The server side is kept open until the returned function is called.
+func newTestWebSocketSlice(t *testing.T, amount int) ([]testWebSocket, func()) {
+	serverChannel := make(chan *websocket.Conn)
+	doneChannel := make(chan struct{})
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		serverChannel <- ws
+		<-doneChannel
+	}))
+
+	testWebSocketSlice := make([]testWebSocket, 0)
+	for i := 0; i < amount; i++ {
+		client, err := websocket.Dial(strings.Replace(server.URL, "http://", "ws://", 1), "", server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testWebSocketSlice = append(testWebSocketSlice, testWebSocket{<-serverChannel, client})
+	}
+
+	return testWebSocketSlice, func() {
+		close(doneChannel)
+		for _, testWebSocket := range testWebSocketSlice {
+			testWebSocket.client.Close()
+		}
+		server.Close()
+	}
+}
+
+// receiveText returns the text received by the client until the server closes the websocket or nothing more is sent
+func receiveText(ws *websocket.Conn) (string, bool) {
+	text := ""
+	for {
+		ws.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		message := ""
+		if err := websocket.Message.Receive(ws, &message); err != nil {
+			return text, strings.Contains(err.Error(), "timeout") == false
+		}
+		text += message
+	}
+}
+
+func TestFilterDraining(t *testing.T) {
+	Convey("Subject: The new request is rejected after draining starts\n", t, func() {
+		resetTestDrainer()
+
+		testCaseSlice := []struct {
+			description string
+			path        string
+			draining    bool
+			status      int
+			body        string
+		}{
+			{"The page before draining", "/gui/dashboard/topology/", false, 200, ""},
+			{"The REST API before draining", "/guirestapi/v1/namespaces", false, 200, ""},
+			{"The page while draining", "/gui/dashboard/topology/", true, 503, ErrDraining.Error()},
+			{"The REST API while draining", "/guirestapi/v1/namespaces", true, 503, `"error":"` + ErrDraining.Error() + `"`},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				theDrainer.draining = testCase.draining
+				recorder := httptest.NewRecorder()
+				ctx := beegocontext.NewContext()
+				ctx.Reset(recorder, httptest.NewRequest("GET", testCase.path, nil))
+
+				FilterDraining(ctx)
+
+				So(recorder.Code, ShouldEqual, testCase.status)
+				So(recorder.Body.String(), ShouldContainSubstring, testCase.body)
+				So(ctx.ResponseWriter.Started, ShouldEqual, testCase.draining)
+				if testCase.draining {
+					So(recorder.Header().Get("Retry-After"), ShouldNotBeEmpty)
+				}
+			})
+		}
+	})
+}
+
+func TestDrain(t *testing.T) {
+	Convey("Subject: Draining waits for the websocket sessions of the operations within the grace period\n", t, func() {
+		resetTestDrainer()
+		testWebSocketSlice, cleanup := newTestWebSocketSlice(t, 2)
+		defer cleanup()
+		followed, waited := testWebSocketSlice[0], testWebSocketSlice[1]
+
+		_, err := StartWebSocketSession(followed.server, "build log", false)
+		So(err, ShouldBeNil)
+		end, err := StartWebSocketSession(waited.server, "upgrade", true)
+		So(err, ShouldBeNil)
+
+		Convey("The operation ending within the grace period is waited", func() {
+			go func() {
+				time.Sleep(200 * time.Millisecond)
+				end()
+			}()
+			startTime := time.Now()
+			theDrainer.drain(startTime.Add(5 * time.Second))
+			So(time.Since(startTime), ShouldBeBetween, 200*time.Millisecond, 5*time.Second)
+
+			text, closed := receiveText(followed.client)
+			So(text, ShouldContainSubstring, "Please refresh the page later")
+			So(closed, ShouldBeTrue)
+
+			text, closed = receiveText(waited.client)
+			So(text, ShouldContainSubstring, "The session is closed in 5 seconds if it is not done")
+			So(closed, ShouldBeFalse)
+		})
+
+		Convey("The operation not ending within the grace period is closed", func() {
+			startTime := time.Now()
+			theDrainer.drain(startTime.Add(300 * time.Millisecond))
+			So(time.Since(startTime), ShouldBeGreaterThanOrEqualTo, 300*time.Millisecond)
+
+			text, closed := receiveText(waited.client)
+			So(text, ShouldContainSubstring, "if it is not done")
+			So(text, ShouldEndWith, "The session is closed.\n")
+			So(closed, ShouldBeTrue)
+		})
+
+		Convey("The new websocket session is rejected after draining starts", func() {
+			theDrainer.drain(time.Now())
+			So(IsDraining(), ShouldBeTrue)
+			_, err := StartWebSocketSession(followed.server, "build log", false)
+			So(err, ShouldEqual, ErrDraining)
+		})
+	})
+}
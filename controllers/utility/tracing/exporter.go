@@ -0,0 +1,309 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"github.com/astaxie/beego"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	defaultTracingServiceName           = "cloudone_gui"
+	defaultTracingQueueSize             = 2048
+	defaultTracingBatchSize             = 100
+	defaultTracingFlushIntervalInSecond = 5
+	tracingExportTimeout                = 10 * time.Second
+	instrumentationScopeName            = "github.com/cloudawan/cloudone_gui"
+)
+
+// The structures below are the OTLP/HTTP json encoding of ExportTraceServiceRequest.
+// The ids are hex strings and the times are the unix nanoseconds in strings as the encoding requires.
+
+type ExportTraceServiceRequest struct {
+	ResourceSpans []ResourceSpans `json:"resourceSpans"`
+}
+
+type ResourceSpans struct {
+	Resource   Resource     `json:"resource"`
+	ScopeSpans []ScopeSpans `json:"scopeSpans"`
+}
+
+type Resource struct {
+	Attributes []KeyValue `json:"attributes"`
+}
+
+type ScopeSpans struct {
+	Scope InstrumentationScope `json:"scope"`
+	Spans []OTLPSpan           `json:"spans"`
+}
+
+type InstrumentationScope struct {
+	Name string `json:"name"`
+}
+
+type OTLPSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []KeyValue `json:"attributes,omitempty"`
+	Status            Status     `json:"status"`
+}
+
+type KeyValue struct {
+	Key   string   `json:"key"`
+	Value AnyValue `json:"value"`
+}
+
+type AnyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type Status struct {
+	Code    int    `json:"code,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// ExporterStatistic counts the spans since the GUI starts except Queued which is the current amount
+type ExporterStatistic struct {
+	Enabled  bool
+	Queued   int
+	Exported int64
+	Failed   int64
+	Dropped  int64
+}
+
+// exporter sends the ended spans in batch to the OTLP collector configured with tracingOTLPEndpoint.
+// Tracing is best-effort so the spans are dropped when the queue is full or the collector fails.
+type exporter struct {
+	endpoint       string
+	serviceName    string
+	channel        chan *Span
+	batchSize      int
+	flushInterval  time.Duration
+	httpClient     *http.Client
+	exported       int64
+	failed         int64
+	dropped        int64
+	stopOnce       sync.Once
+	stopChannel    chan struct{}
+	stoppedChannel chan struct{}
+}
+
+var exporterOnce sync.Once
+var defaultExporter *exporter
+
+// getExporter returns nil if the endpoint is not configured
+func getExporter() *exporter {
+	exporterOnce.Do(func() {
+		endpoint := beego.AppConfig.String("tracingOTLPEndpoint")
+		if endpoint == "" {
+			return
+		}
+
+		batchSize := beego.AppConfig.DefaultInt("tracingBatchSize", defaultTracingBatchSize)
+		if batchSize <= 0 {
+			batchSize = defaultTracingBatchSize
+		}
+		flushIntervalInSecond := beego.AppConfig.DefaultInt("tracingFlushIntervalInSecond", defaultTracingFlushIntervalInSecond)
+		if flushIntervalInSecond <= 0 {
+			flushIntervalInSecond = defaultTracingFlushIntervalInSecond
+		}
+
+		defaultExporter = &exporter{
+			endpoint:       endpoint,
+			serviceName:    beego.AppConfig.DefaultString("tracingServiceName", defaultTracingServiceName),
+			channel:        make(chan *Span, beego.AppConfig.DefaultInt("tracingQueueSize", defaultTracingQueueSize)),
+			batchSize:      batchSize,
+			flushInterval:  time.Duration(flushIntervalInSecond) * time.Second,
+			httpClient:     &http.Client{Timeout: tracingExportTimeout},
+			stopChannel:    make(chan struct{}),
+			stoppedChannel: make(chan struct{}),
+		}
+
+		go defaultExporter.run()
+	})
+	return defaultExporter
+}
+
+// exportSpan never blocks the request
+func exportSpan(span *Span) {
+	exporter := getExporter()
+	if exporter == nil {
+		return
+	}
+	select {
+	case exporter.channel <- span:
+	default:
+		atomic.AddInt64(&exporter.dropped, 1)
+	}
+}
+
+// GetExporterStatistic is used to monitor the export of the spans
+func GetExporterStatistic() ExporterStatistic {
+	exporter := getExporter()
+	if exporter == nil {
+		return ExporterStatistic{}
+	}
+	return ExporterStatistic{
+		true,
+		len(exporter.channel),
+		atomic.LoadInt64(&exporter.exported),
+		atomic.LoadInt64(&exporter.failed),
+		atomic.LoadInt64(&exporter.dropped),
+	}
+}
+
+// StopExporter exports the spans still in memory
+func StopExporter() {
+	exporter := getExporter()
+	if exporter == nil {
+		return
+	}
+	exporter.stopOnce.Do(func() {
+		close(exporter.stopChannel)
+	})
+	<-exporter.stoppedChannel
+}
+
+func (exporter *exporter) run() {
+	defer close(exporter.stoppedChannel)
+
+	ticker := time.NewTicker(exporter.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, exporter.batchSize)
+	for {
+		select {
+		case span := <-exporter.channel:
+			batch = append(batch, span)
+			if len(batch) < exporter.batchSize {
+				continue
+			}
+		case <-ticker.C:
+		case <-exporter.stopChannel:
+			for {
+				select {
+				case span := <-exporter.channel:
+					batch = append(batch, span)
+				default:
+					exporter.export(batch)
+					return
+				}
+			}
+		}
+
+		exporter.export(batch)
+		batch = make([]*Span, 0, exporter.batchSize)
+	}
+}
+
+func (exporter *exporter) export(batch []*Span) {
+	if len(batch) == 0 {
+		return
+	}
+
+	err := exporter.send(NewExportTraceServiceRequest(exporter.serviceName, batch))
+	if err != nil {
+		atomic.AddInt64(&exporter.failed, int64(len(batch)))
+		beego.Error("Fail to export spans to", exporter.endpoint, err)
+	} else {
+		atomic.AddInt64(&exporter.exported, int64(len(batch)))
+	}
+}
+
+func (exporter *exporter) send(request ExportTraceServiceRequest) error {
+	byteSlice, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	response, err := exporter.httpClient.Post(exporter.endpoint, "application/json", bytes.NewReader(byteSlice))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	io.Copy(ioutil.Discard, response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return errors.New("Status code " + strconv.Itoa(response.StatusCode))
+	}
+	return nil
+}
+
+func formatUnixNano(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// NewExportTraceServiceRequest converts the spans into the OTLP request of the service
+func NewExportTraceServiceRequest(serviceName string, spanSlice []*Span) ExportTraceServiceRequest {
+	otlpSpanSlice := make([]OTLPSpan, 0, len(spanSlice))
+	for _, span := range spanSlice {
+		span.lock.Lock()
+		keySlice := make([]string, 0, len(span.AttributeMap))
+		for key := range span.AttributeMap {
+			keySlice = append(keySlice, key)
+		}
+		sort.Strings(keySlice)
+		attributeSlice := make([]KeyValue, 0, len(keySlice))
+		for _, key := range keySlice {
+			attributeSlice = append(attributeSlice, KeyValue{key, AnyValue{span.AttributeMap[key]}})
+		}
+
+		otlpSpanSlice = append(otlpSpanSlice, OTLPSpan{
+			span.TraceID,
+			span.SpanID,
+			span.ParentSpanID,
+			span.Name,
+			span.Kind,
+			formatUnixNano(span.StartTime),
+			formatUnixNano(span.EndTime),
+			attributeSlice,
+			Status{span.StatusCode, span.StatusMessage},
+		})
+		span.lock.Unlock()
+	}
+
+	return ExportTraceServiceRequest{
+		[]ResourceSpans{
+			ResourceSpans{
+				Resource{
+					[]KeyValue{
+						KeyValue{"service.name", AnyValue{serviceName}},
+					},
+				},
+				[]ScopeSpans{
+					ScopeSpans{
+						InstrumentationScope{instrumentationScopeName},
+						otlpSpanSlice,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	beegocontext "github.com/astaxie/beego/context"
+	"net"
+	"net/http"
+	"strconv"
+)
+
+type requestTraceContextKey struct{}
+
+// requestTrace is put into the request by MiddleWare so the span started by FilterRequestID is ended
+// even when a filter of beego stops the request before FinishRouter.
+type requestTrace struct {
+	span *Span
+}
+
+// statusResponseWriter records the status code replied to the client
+type statusResponseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (responseWriter *statusResponseWriter) WriteHeader(statusCode int) {
+	if responseWriter.statusCode == 0 {
+		responseWriter.statusCode = statusCode
+	}
+	responseWriter.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (responseWriter *statusResponseWriter) Write(byteSlice []byte) (int, error) {
+	if responseWriter.statusCode == 0 {
+		responseWriter.statusCode = http.StatusOK
+	}
+	return responseWriter.ResponseWriter.Write(byteSlice)
+}
+
+// Hijack is required by the websocket of the docker terminal
+func (responseWriter *statusResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := responseWriter.ResponseWriter.(http.Hijacker)
+	if ok == false {
+		return nil, nil, errors.New("The response writer doesn't support hijack")
+	}
+	if responseWriter.statusCode == 0 {
+		responseWriter.statusCode = http.StatusSwitchingProtocols
+	}
+	return hijacker.Hijack()
+}
+
+func (responseWriter *statusResponseWriter) Flush() {
+	if flusher, ok := responseWriter.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// MiddleWare ends the span of the request with the replied status code. It is used with beego.RunWithMiddleWares.
+func MiddleWare(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		trace := &requestTrace{}
+		recordedResponseWriter := &statusResponseWriter{responseWriter, 0}
+
+		handler.ServeHTTP(recordedResponseWriter, request.WithContext(context.WithValue(request.Context(), requestTraceContextKey{}, trace)))
+
+		if trace.span != nil {
+			statusCode := recordedResponseWriter.statusCode
+			if statusCode == 0 {
+				statusCode = http.StatusOK
+			}
+			trace.span.SetAttribute("http.status_code", strconv.Itoa(statusCode))
+			if statusCode >= http.StatusInternalServerError {
+				trace.span.SetError(errors.New(http.StatusText(statusCode)))
+			}
+			trace.span.End()
+		}
+	})
+}
+
+// FilterRequestID accepts the header X-Request-ID from the client or assigns a new one.
+// The request id is replied in the header, kept in the data and the context of the request
+// and forwarded by the backend client together with the span started for the request.
+func FilterRequestID(ctx *beegocontext.Context) {
+	requestID := ctx.Input.Header(HeaderRequestID)
+	if IsValidRequestID(requestID) == false {
+		requestID = NewRequestID()
+	}
+
+	ctx.Output.Header(HeaderRequestID, requestID)
+	ctx.Input.SetData(DataNameRequestID, requestID)
+
+	requestContext := WithRequestID(ctx.Request.Context(), requestID)
+	requestContext, span := startSpanWithTraceParent(requestContext, ctx.Input.Method()+" "+ctx.Input.URL(), ctx.Input.Header(HeaderTraceParent))
+	span.SetAttribute("http.method", ctx.Input.Method())
+	span.SetAttribute("http.target", ctx.Input.URI())
+	span.SetAttribute("http.client_ip", ctx.Input.IP())
+
+	ctx.Request = ctx.Request.WithContext(requestContext)
+
+	// The span is only ended and exported when the request is served through MiddleWare
+	if trace, ok := requestContext.Value(requestTraceContextKey{}).(*requestTrace); ok {
+		trace.span = span
+	}
+}
+
+// GetRequestIDFromContext returns the request id assigned by FilterRequestID
+func GetRequestIDFromContext(ctx *beegocontext.Context) string {
+	requestID, _ := ctx.Input.GetData(DataNameRequestID).(string)
+	return requestID
+}
@@ -0,0 +1,73 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	beegocontext "github.com/astaxie/beego/context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestFilterRequestID(t *testing.T) {
+	Convey("Subject: The request id is accepted from the client or assigned\n", t, func() {
+		testCaseSlice := []struct {
+			description string
+			requestID   string
+			accepted    bool
+		}{
+			{"The valid request id", "request-1.a_b:c", true},
+			{"The missing request id", "", false},
+			{"The request id with the space", "request 1", false},
+			{"The request id with the line break", "request-1\r\nSet-Cookie: a=b", false},
+			{"The too long request id", strings.Repeat("a", 129), false},
+		}
+		for _, testCase := range testCaseSlice {
+			Convey(testCase.description, func() {
+				request := httptest.NewRequest("GET", "/gui/dashboard/topology/", nil)
+				if testCase.requestID != "" {
+					request.Header.Set(HeaderRequestID, testCase.requestID)
+				}
+				recorder := httptest.NewRecorder()
+				ctx := beegocontext.NewContext()
+				ctx.Reset(recorder, request)
+
+				FilterRequestID(ctx)
+
+				requestID := GetRequestIDFromContext(ctx)
+				So(IsValidRequestID(requestID), ShouldBeTrue)
+				So(requestID == testCase.requestID, ShouldEqual, testCase.accepted)
+				So(recorder.Header().Get(HeaderRequestID), ShouldEqual, requestID)
+				So(GetRequestID(ctx.Request.Context()), ShouldEqual, requestID)
+				So(GetSpan(ctx.Request.Context()).AttributeMap["request.id"], ShouldEqual, requestID)
+			})
+		}
+
+		Convey("The trace of the caller is continued", func() {
+			request := httptest.NewRequest("GET", "/gui/dashboard/topology/", nil)
+			request.Header.Set(HeaderTraceParent, "00-0123456789abcdef0123456789abcdef-0123456789abcdef-01")
+			ctx := beegocontext.NewContext()
+			ctx.Reset(httptest.NewRecorder(), request)
+
+			FilterRequestID(ctx)
+
+			span := GetSpan(ctx.Request.Context())
+			So(span.TraceID, ShouldEqual, "0123456789abcdef0123456789abcdef")
+			So(span.ParentSpanID, ShouldEqual, "0123456789abcdef")
+		})
+	})
+}
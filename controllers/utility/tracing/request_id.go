@@ -0,0 +1,53 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/random"
+	"regexp"
+)
+
+// HeaderRequestID is accepted from the client, replied to the client and forwarded to the backend
+const HeaderRequestID = "X-Request-ID"
+
+// DataNameRequestID is the name of the request id in the data of the beego context
+const DataNameRequestID = "requestID"
+
+type requestIDContextKey struct{}
+
+// The request id from the client is only accepted if it is reasonable to be put into the header and the log
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+func IsValidRequestID(requestID string) bool {
+	return requestIDRegexp.MatchString(requestID)
+}
+
+func NewRequestID() string {
+	return random.UUID()
+}
+
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, requestID)
+}
+
+// GetRequestID returns the request id in the context or empty if there is none
+func GetRequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDContextKey{}).(string)
+	return requestID
+}
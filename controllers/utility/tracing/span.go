@@ -0,0 +1,148 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	SpanKindInternal = 1
+	SpanKindServer   = 2
+	SpanKindClient   = 3
+)
+
+const (
+	SpanStatusUnset = 0
+	SpanStatusOK    = 1
+	SpanStatusError = 2
+)
+
+// HeaderTraceParent is the W3C trace context header forwarded to the backend
+const HeaderTraceParent = "traceparent"
+
+type spanContextKey struct{}
+
+// Span is the OpenTelemetry-style span. It is exported with OTLP when it ends.
+type Span struct {
+	TraceID       string
+	SpanID        string
+	ParentSpanID  string
+	Name          string
+	Kind          int
+	StartTime     time.Time
+	EndTime       time.Time
+	AttributeMap  map[string]string
+	StatusCode    int
+	StatusMessage string
+	lock          sync.Mutex
+	ended         bool
+}
+
+func randomHex(length int) string {
+	byteSlice := make([]byte, length)
+	io.ReadFull(rand.Reader, byteSlice)
+	return hex.EncodeToString(byteSlice)
+}
+
+// StartSpan starts the span as the child of the span in the context or a new trace if there is none.
+// The returned context carries the new span.
+func StartSpan(ctx context.Context, name string, kind int) (context.Context, *Span) {
+	span := &Span{
+		SpanID:       randomHex(8),
+		Name:         name,
+		Kind:         kind,
+		StartTime:    time.Now(),
+		AttributeMap: make(map[string]string),
+	}
+
+	if parentSpan := GetSpan(ctx); parentSpan != nil {
+		span.TraceID = parentSpan.TraceID
+		span.ParentSpanID = parentSpan.SpanID
+	} else {
+		span.TraceID = randomHex(16)
+	}
+
+	if requestID := GetRequestID(ctx); requestID != "" {
+		span.AttributeMap["request.id"] = requestID
+	}
+
+	return context.WithValue(ctx, spanContextKey{}, span), span
+}
+
+// startSpanWithTraceParent starts the server span continuing the trace of the caller if the header traceparent is valid
+func startSpanWithTraceParent(ctx context.Context, name string, traceParent string) (context.Context, *Span) {
+	ctx, span := StartSpan(ctx, name, SpanKindServer)
+	// version-traceid-parentid-flags
+	fieldSlice := strings.Split(traceParent, "-")
+	if len(fieldSlice) == 4 && len(fieldSlice[1]) == 32 && len(fieldSlice[2]) == 16 && isHex(fieldSlice[1]) && isHex(fieldSlice[2]) {
+		span.TraceID = fieldSlice[1]
+		span.ParentSpanID = fieldSlice[2]
+	}
+	return ctx, span
+}
+
+func isHex(text string) bool {
+	_, err := hex.DecodeString(text)
+	return err == nil && strings.Trim(text, "0") != ""
+}
+
+// GetSpan returns the span in the context or nil if there is none
+func GetSpan(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanContextKey{}).(*Span)
+	return span
+}
+
+// TraceParent returns the value of the header traceparent to propagate the span
+func (span *Span) TraceParent() string {
+	return "00-" + span.TraceID + "-" + span.SpanID + "-01"
+}
+
+func (span *Span) SetAttribute(key string, value string) {
+	span.lock.Lock()
+	defer span.lock.Unlock()
+	span.AttributeMap[key] = value
+}
+
+// SetError marks the span failed
+func (span *Span) SetError(err error) {
+	span.lock.Lock()
+	defer span.lock.Unlock()
+	span.StatusCode = SpanStatusError
+	span.StatusMessage = err.Error()
+}
+
+// End ends the span and queues it to the exporter. Only the first call takes effect.
+func (span *Span) End() {
+	span.lock.Lock()
+	if span.ended {
+		span.lock.Unlock()
+		return
+	}
+	span.ended = true
+	span.EndTime = time.Now()
+	span.lock.Unlock()
+
+	exportSpan(span)
+}
@@ -7,14 +7,18 @@ cp /src/cloudone_gui/conf/app.conf /etc/cloudone_gui/app.conf
 cp -n /src/cloudone_gui/conf/development_cert.pem /etc/cloudone_gui/cert.pem
 cp -n /src/cloudone_gui/conf/development_key.pem /etc/cloudone_gui/key.pem
 
-# Use environment
-sed -i "s/{{CLOUDONE_HOST}}/$CLOUDONE_HOST/g" /etc/cloudone_gui/app.conf
-sed -i "s/{{CLOUDONE_PORT}}/$CLOUDONE_PORT/g" /etc/cloudone_gui/app.conf
-sed -i "s/{{CLOUDONE_ANALYSIS_HOST}}/$CLOUDONE_ANALYSIS_HOST/g" /etc/cloudone_gui/app.conf
-sed -i "s/{{CLOUDONE_ANALYSIS_PORT}}/$CLOUDONE_ANALYSIS_PORT/g" /etc/cloudone_gui/app.conf
+# Use environment. The configuration reads CLOUDONE_GUI_<KEY> and the variables without the prefix are kept for the existing deployments.
+export CLOUDONE_GUI_CLOUDONE_HOST=${CLOUDONE_GUI_CLOUDONE_HOST:-$CLOUDONE_HOST}
+export CLOUDONE_GUI_CLOUDONE_PORT=${CLOUDONE_GUI_CLOUDONE_PORT:-$CLOUDONE_PORT}
+export CLOUDONE_GUI_CLOUDONE_ANALYSIS_HOST=${CLOUDONE_GUI_CLOUDONE_ANALYSIS_HOST:-$CLOUDONE_ANALYSIS_HOST}
+export CLOUDONE_GUI_CLOUDONE_ANALYSIS_PORT=${CLOUDONE_GUI_CLOUDONE_ANALYSIS_PORT:-$CLOUDONE_ANALYSIS_PORT}
 
 cd /src/cloudone_gui
-./cloudone_gui &
+./cloudone_gui -config /etc/cloudone_gui/app.conf &
+pid=$!
+
+# Forward the stop signal so the GUI drains the websocket sessions before it exits
+trap 'kill -TERM $pid; wait $pid; exit 0' TERM INT
 
 while :
 do
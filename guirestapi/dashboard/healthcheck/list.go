@@ -19,7 +19,8 @@ import (
 	"fmt"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"sort"
 	"strings"
 )
@@ -75,21 +76,7 @@ func (sortKubernetesStatusByIP *SortKubernetesStatusByIP) Less(i, j int) bool {
 // @Success 200 (string) {}
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/healthchecks/"
-
-	cloudoneJsonMap := make(map[string]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &cloudoneJsonMap, tokenHeaderMap)
+	cloudoneJsonMap, err := backend.NewCloudoneClient(c.Ctx).GetHealthCheck()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -97,19 +84,11 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	url = cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/healthchecks/"
-
-	cloudoneAnalysisJsonMap := make(map[string]interface{}, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &cloudoneAnalysisJsonMap, tokenHeaderMap)
+	cloudoneAnalysisJsonMap, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetHealthCheck()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -117,20 +96,14 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
 	componentStatus, err := parseComponentStatus(cloudoneJsonMap, cloudoneAnalysisJsonMap)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 	componentStatusSlice := make([]ComponentStatus, 0)
@@ -139,10 +112,7 @@ func (c *ListController) Get() {
 	kubernetesStatusSlice, err := parseKubernetesStatusSlice(cloudoneJsonMap)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 	sortKubernetesStatusByIP := SortKubernetesStatusByIP(kubernetesStatusSlice)
@@ -17,40 +17,10 @@ package topology
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"strconv"
 )
 
-type ReplicationControllerAndRelatedPod struct {
-	Name               string
-	Namespace          string
-	ReplicaAmount      int
-	AliveReplicaAmount int
-	Selector           map[string]string
-	Label              map[string]string
-	PodSlice           []Pod
-}
-
-type Pod struct {
-	Name           string
-	Namespace      string
-	HostIP         string
-	PodIP          string
-	ContainerSlice []PodContainer
-}
-
-type PodContainer struct {
-	Name      string
-	Image     string
-	PortSlice []PodContainerPort
-}
-
-type PodContainerPort struct {
-	Name          string
-	ContainerPort int
-	Protocol      string
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -60,20 +30,9 @@ type IndexController struct {
 // @Success 200 (string) {}
 // @router / [get]
 func (c *IndexController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace
-
-	replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerAndRelatedPodSlice, tokenHeaderMap)
+	replicationControllerAndRelatedPodSlice, err := backend.NewCloudoneClient(c.Ctx).GetReplicationControllerAndRelatedPodSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
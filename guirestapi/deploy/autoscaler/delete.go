@@ -17,7 +17,8 @@ package autoscaler
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type DeleteController struct {
@@ -35,18 +36,9 @@ func (c *DeleteController) Delete() {
 	kind := c.GetString(":kind")
 	name := c.GetString(":name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/" + namespace + "/" + kind + "/" + name
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+	err := backend.NewCloudoneClient(c.Ctx).DeleteAutoScaler(namespace, kind, name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -54,10 +46,7 @@ func (c *DeleteController) Delete() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 
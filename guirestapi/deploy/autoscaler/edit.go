@@ -18,8 +18,9 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
@@ -30,27 +31,16 @@ type EditController struct {
 // @Description get the autoscaler
 // @Param kind path string true "The type of target autoscaler configured for"
 // @Param name path string true "The name of target autoscaler configured for"
-// @Success 200 {object} guirestapi.deploy.autoscaler.ReplicationControllerAutoScaler
+// @Success 200 {object} backend.ReplicationControllerAutoScaler
 // @Failure 404 error reason
 // @router /:kind/:name [get]
 func (c *EditController) Get() {
 	kind := c.GetString(":kind")
 	name := c.GetString(":name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/" + namespace + "/" + kind + "/" + name
-
-	replicationControllerAutoScaler := ReplicationControllerAutoScaler{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerAutoScaler, tokenHeaderMap)
+	replicationControllerAutoScaler, err := backend.NewCloudoneClient(c.Ctx).GetAutoScaler(namespace, kind, name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -58,10 +48,7 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = replicationControllerAutoScaler
@@ -71,37 +58,25 @@ func (c *EditController) Get() {
 
 // @Title update
 // @Description update the autoscaler
-// @Param body body guirestapi.deploy.autoscaler.ReplicationControllerAutoScaler true "body for autoscaler"
+// @Param body body backend.ReplicationControllerAutoScaler true "body for autoscaler"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [put]
 func (c *EditController) Put() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	replicationControllerAutoScaler := ReplicationControllerAutoScaler{}
+	replicationControllerAutoScaler := backend.ReplicationControllerAutoScaler{}
 	err := json.Unmarshal(inputBody, &replicationControllerAutoScaler)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	replicationControllerAutoScaler.Namespace = namespace
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPutWithStructure(url, replicationControllerAutoScaler, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).UpdateAutoScaler(replicationControllerAutoScaler)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -109,10 +84,7 @@ func (c *EditController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
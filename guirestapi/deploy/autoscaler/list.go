@@ -17,56 +17,21 @@ package autoscaler
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
-	"time"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type ReplicationControllerAutoScaler struct {
-	Check             bool
-	CoolDownDuration  time.Duration
-	RemainingCoolDown time.Duration
-	KubeapiHost       string
-	KubeapiPort       int
-	Namespace         string
-	Kind              string
-	Name              string
-	MaximumReplica    int
-	MinimumReplica    int
-	IndicatorSlice    []Indicator
-}
-
-type Indicator struct {
-	Type                  string
-	AboveAllOrOne         bool
-	AbovePercentageOfData float64
-	AboveThreshold        int64
-	BelowAllOrOne         bool
-	BelowPercentageOfData float64
-	BelowThreshold        int64
-}
-
 // @Title get
 // @Description get all autoscalers
-// @Success 200 {string} []ReplicationControllerAutoScaler
+// @Success 200 {string} []backend.ReplicationControllerAutoScaler
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) GetAll() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/autoscalers/"
-
-	replicationControllerAutoScalerSlice := make([]ReplicationControllerAutoScaler, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerAutoScalerSlice, tokenHeaderMap)
+	replicationControllerAutoScalerSlice, err := backend.NewCloudoneClient(c.Ctx).GetAutoScalerSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -74,10 +39,7 @@ func (c *ListController) GetAll() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = replicationControllerAutoScalerSlice
@@ -18,44 +18,16 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type CreateController struct {
 	beego.Controller
 }
 
-type DeployCreateInput struct {
-	ImageInformationName string
-	Version              string
-	Description          string
-	ReplicaAmount        int
-	PortSlice            []ReplicationControllerContainerPort
-	EnvironmentSlice     []ReplicationControllerContainerEnvironment
-	ExtraJsonMap         map[string]interface{}
-}
-
-type ReplicationControllerContainerPort struct {
-	Name          string
-	ContainerPort int
-}
-
-type ReplicationControllerContainerEnvironment struct {
-	Name  string
-	Value string
-}
-
-type ImageRecord struct {
-	ImageInformation string
-	Version          string
-	Path             string
-	VersionInfo      map[string]string
-	Environment      map[string]string
-	Description      string
-	CreatedTime      string
-}
-
 // @Title get
 // @Description get the related selection in order to create a new deployment
 // @Param name path string true "The name of image record"
@@ -65,18 +37,7 @@ type ImageRecord struct {
 func (c *CreateController) Get() {
 	name := c.GetString(":name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imagerecords/" + name
-
-	imageRecordSlice := make([]ImageRecord, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &imageRecordSlice, tokenHeaderMap)
+	imageRecordSlice, err := backend.NewCloudoneClient(c.Ctx).GetImageRecordSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -84,10 +45,7 @@ func (c *CreateController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		versionEnvironmentMap := make(map[string]map[string]string)
@@ -107,35 +65,23 @@ func (c *CreateController) Get() {
 
 // @Title create
 // @Description create the autoscaler
-// @Param body body guirestapi.deploy.deploy.DeployCreateInput true "body for deploy"
+// @Param body body backend.DeployCreateInput true "body for deploy"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [post]
 func (c *CreateController) Post() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	deployCreateInput := DeployCreateInput{}
+	deployCreateInput := backend.DeployCreateInput{}
 	err := json.Unmarshal(inputBody, &deployCreateInput)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespaces, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/create/" + namespaces
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPostWithStructure(url, deployCreateInput, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).CreateDeploy(namespaces, deployCreateInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -143,12 +89,12 @@ func (c *CreateController) Post() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
+		if err := deployrevision.Record(c.Ctx, namespaces, deployCreateInput.ImageInformationName, deployrevision.ActionCreate); err != nil {
+			beego.Error("Fail to record the revision of deploy " + deployCreateInput.ImageInformationName + " with error " + err.Error())
+		}
 		c.Data["json"] = make(map[string]interface{})
 		c.ServeJSON()
 	}
@@ -17,40 +17,23 @@ package deploy
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type DeployInformation struct {
-	Namespace            string
-	ImageInformationName string
-	CurrentVersion       string
-	Description          string
-}
-
 // @Title get
 // @Description get all deployments
-// @Success 200 {string} []DeployInformation
+// @Success 200 {string} []backend.DeployInformation
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/" + namespace
-
-	deployInformationSlice := make([]DeployInformation, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployInformationSlice, tokenHeaderMap)
+	deployInformationSlice, err := backend.NewCloudoneClient(c.Ctx).GetDeployInformationSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -58,14 +41,11 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		// Only show those belonging to this namespace
-		filteredDeployInformationSlice := make([]DeployInformation, 0)
+		filteredDeployInformationSlice := make([]backend.DeployInformation, 0)
 		for _, deployInformation := range deployInformationSlice {
 			filteredDeployInformationSlice = append(filteredDeployInformationSlice, deployInformation)
 		}
@@ -18,21 +18,16 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/deployrevision"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type UpdateController struct {
 	beego.Controller
 }
 
-type DeployUpdateInput struct {
-	ImageInformationName string
-	Version              string
-	Description          string
-	EnvironmentSlice     []ReplicationControllerContainerEnvironment
-}
-
 // @Title get
 // @Description get the related selection in order to create a new deployment
 // @Param name path string true "The name of image record"
@@ -44,18 +39,7 @@ func (c *UpdateController) Get() {
 	name := c.GetString(":name")
 	oldVersion := c.GetString("oldVersion")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imagerecords/" + name
-
-	imageRecordSlice := make([]ImageRecord, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &imageRecordSlice, tokenHeaderMap)
+	imageRecordSlice, err := backend.NewCloudoneClient(c.Ctx).GetImageRecordSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -63,10 +47,7 @@ func (c *UpdateController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		versionEnvironmentMap := make(map[string]map[string]string)
@@ -88,35 +69,27 @@ func (c *UpdateController) Get() {
 
 // @Title update
 // @Description update the deploy
-// @Param body body guirestapi.deploy.deploy.DeployUpdateInput true "body for deploy"
+// @Param body body backend.DeployUpdateInput true "body for deploy"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [put]
 func (c *UpdateController) Put() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	deployUpdateInput := DeployUpdateInput{}
+	deployUpdateInput := backend.DeployUpdateInput{}
 	err := json.Unmarshal(inputBody, &deployUpdateInput)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespaces, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploys/update/" + namespaces
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	if err := deployrevision.RecordBaseline(c.Ctx, namespaces, deployUpdateInput.ImageInformationName); err != nil {
+		beego.Error("Fail to record the revision of deploy " + deployUpdateInput.ImageInformationName + " before update with error " + err.Error())
+	}
 
-	_, err = restclient.RequestPutWithStructure(url, deployUpdateInput, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).UpdateDeploy(namespaces, deployUpdateInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -124,12 +97,12 @@ func (c *UpdateController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
+		if err := deployrevision.Record(c.Ctx, namespaces, deployUpdateInput.ImageInformationName, deployrevision.ActionUpdate); err != nil {
+			beego.Error("Fail to record the revision of deploy " + deployUpdateInput.ImageInformationName + " with error " + err.Error())
+		}
 		c.Data["json"] = make(map[string]interface{})
 		c.ServeJSON()
 	}
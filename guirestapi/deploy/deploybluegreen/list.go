@@ -17,39 +17,21 @@ package deploybluegreen
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type DeployBlueGreen struct {
-	ImageInformation string
-	Namespace        string
-	NodePort         int
-	Description      string
-	SessionAffinity  string
-}
-
 // @Title get
 // @Description get all blue green deployments
-// @Success 200 {string} []DeployBlueGreen
+// @Success 200 {string} []backend.DeployBlueGreen
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploybluegreens/"
-
-	deployBlueGreenSlice := make([]DeployBlueGreen, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployBlueGreenSlice, tokenHeaderMap)
+	deployBlueGreenSlice, err := backend.NewCloudoneClient(c.Ctx).GetDeployBlueGreenSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -57,10 +39,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = deployBlueGreenSlice
@@ -18,8 +18,9 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type SelectController struct {
@@ -35,18 +36,7 @@ type SelectController struct {
 func (c *SelectController) Get() {
 	imageInformation := c.GetString(":imageInformation")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploybluegreens/deployable/" + imageInformation
-
-	namespaceSlice := make([]string, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &namespaceSlice, tokenHeaderMap)
+	namespaceSlice, err := backend.NewCloudoneClient(c.Ctx).GetDeployBlueGreenDeployableNamespaceSlice(imageInformation)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -54,10 +44,7 @@ func (c *SelectController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
@@ -68,33 +55,21 @@ func (c *SelectController) Get() {
 
 // @Title select
 // @Description select the different namespace
-// @Param body body guirestapi.deploy.deploybluegreen.DeployBlueGreen true "body for blue green deployment"
+// @Param body body backend.DeployBlueGreen true "body for blue green deployment"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [put]
 func (c *SelectController) Put() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	deployBlueGreen := DeployBlueGreen{}
+	deployBlueGreen := backend.DeployBlueGreen{}
 	err := json.Unmarshal(inputBody, &deployBlueGreen)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deploybluegreens/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPutWithStructure(url, deployBlueGreen, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).UpdateDeployBlueGreen(deployBlueGreen)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -102,10 +77,7 @@ func (c *SelectController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
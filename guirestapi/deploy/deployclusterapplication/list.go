@@ -17,40 +17,23 @@ package deployclusterapplication
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type DeployClusterApplication struct {
-	Name                           string
-	Size                           int
-	ServiceName                    string
-	ReplicationControllerNameSlice []string
-}
-
 // @Title get
 // @Description get all cluster application deployments
-// @Success 200 {string} []DeployClusterApplication
+// @Success 200 {string} []backend.DeployClusterApplication
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/" + namespace
-
-	deployClusterApplicationSlice := make([]DeployClusterApplication, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &deployClusterApplicationSlice, tokenHeaderMap)
+	deployClusterApplicationSlice, err := backend.NewCloudoneClient(c.Ctx).GetDeployClusterApplicationSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -58,10 +41,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = deployClusterApplicationSlice
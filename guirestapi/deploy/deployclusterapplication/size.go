@@ -18,59 +18,11 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
-type Cluster struct {
-	Name                      string
-	Description               string
-	ReplicationControllerJson string
-	ServiceJson               string
-	Environment               map[string]string
-	ScriptType                string
-	ScriptContent             string
-}
-
-type ReplicationController struct {
-	Name           string
-	ReplicaAmount  int
-	Selector       ReplicationControllerSelector
-	Label          ReplicationControllerLabel
-	ContainerSlice []ReplicationControllerContainer
-}
-
-type ReplicationControllerSelector struct {
-	Name    string
-	Version string
-}
-
-type ReplicationControllerLabel struct {
-	Name string
-}
-
-type ReplicationControllerContainer struct {
-	Name             string
-	Image            string
-	PortSlice        []ReplicationControllerContainerPort
-	EnvironmentSlice []ReplicationControllerContainerEnvironment
-}
-
-type ReplicationControllerContainerPort struct {
-	Name          string
-	ContainerPort int
-}
-
-type ReplicationControllerContainerEnvironment struct {
-	Name  string
-	Value string
-}
-
-type Environment struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
-}
-
 type SizeController struct {
 	beego.Controller
 }
@@ -84,19 +36,11 @@ type SizeController struct {
 func (c *SizeController) Get() {
 	name := c.GetString(":name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/" + name
-	cluster := Cluster{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	_, err := restclient.RequestGetWithStructure(url, &cluster, tokenHeaderMap)
+	cluster, err := cloudoneClient.GetClusterApplication(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -104,21 +48,14 @@ func (c *SizeController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = "Fail to get cluster application with error" + err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, guimessagedisplay.NewGUIErrorMessage("Fail to get cluster application with error"+err.Error()))
 		return
 	}
 
 	c.Data["name"] = name
 
 	// Get configured environment from any one of replication controller belonging to this cluster application
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/" + namespace + "/" + name
-	deployClusterApplication := DeployClusterApplication{}
-
-	_, err = restclient.RequestGetWithStructure(url, &deployClusterApplication, tokenHeaderMap)
+	deployClusterApplication, err := cloudoneClient.GetDeployClusterApplication(namespace, name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -126,30 +63,19 @@ func (c *SizeController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = "Fail to get cluster application deployment with error" + err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, guimessagedisplay.NewGUIErrorMessage("Fail to get cluster application deployment with error"+err.Error()))
 		return
 	}
 
 	if len(deployClusterApplication.ReplicationControllerNameSlice) == 0 {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = "The replication controller name slice is empty for the cluster application deployment with name " + name
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, guimessagedisplay.NewGUIErrorMessage("The replication controller name slice is empty for the cluster application deployment with name "+name))
 		return
 	}
 
 	replicationControllerName := deployClusterApplication.ReplicationControllerNameSlice[0]
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace + "/" + replicationControllerName
-
-	replicationController := ReplicationController{}
-
-	_, err = restclient.RequestGetWithStructure(url, &replicationController, tokenHeaderMap)
+	replicationController, err := cloudoneClient.GetReplicationController(namespace, replicationControllerName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -157,10 +83,7 @@ func (c *SizeController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = "Fail to get the replication controller with name " + replicationControllerName
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, guimessagedisplay.NewGUIErrorMessage("Fail to get the replication controller with name "+replicationControllerName))
 		return
 	}
 
@@ -177,7 +100,7 @@ func (c *SizeController) Get() {
 
 // @Title resize
 // @Description resize the cluster application
-// @Param body body []Environment true "Array of environment"
+// @Param body body []backend.ClusterEnvironment true "Array of environment"
 // @Param name path string true "The name of cluster application deployment"
 // @Param size query string true "The size to change"
 // @Success 200 {string} {}
@@ -185,32 +108,20 @@ func (c *SizeController) Get() {
 // @router /size/:name [put]
 func (c *SizeController) Put() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	environmentSlice := make([]Environment, 0)
+	environmentSlice := make([]backend.ClusterEnvironment, 0)
 	err := json.Unmarshal(inputBody, &environmentSlice)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
 	name := c.GetString(":name")
-	size := c.GetString("size")
-
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
+	size, _ := c.GetInt("size")
 
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/deployclusterapplications/size/" + namespace + "/" + name + "?size=" + size
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPut(url, environmentSlice, tokenHeaderMap, true)
+	err = backend.NewCloudoneClient(c.Ctx).ResizeDeployClusterApplication(namespace, name, size, environmentSlice)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -218,10 +129,7 @@ func (c *SizeController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
@@ -0,0 +1,73 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/auditsearch"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"strconv"
+	"time"
+)
+
+type ExportController struct {
+	beego.Controller
+}
+
+// @Title export
+// @Description export the audit logs matched with the same query parameters as get. The header X-Audit-Log-Truncated is true if only the latest ones are scanned.
+// @Param format path string true "csv or ndjson"
+// @Success 200 {string} the file
+// @Failure 400 error reason
+// @Failure 404 error reason
+// @router /export/:format [get]
+func (c *ExportController) Get() {
+	format := c.GetString(":format")
+
+	contentType, err := auditsearch.GetContentType(format)
+	if err != nil {
+		// Error
+		guimessagedisplay.OutputJSONError(&c.Controller, 400, err)
+		return
+	}
+
+	criteria, err := auditsearch.ParseCriteria(c.GetString)
+	if err != nil {
+		// Error
+		guimessagedisplay.OutputJSONError(&c.Controller, 400, err)
+		return
+	}
+
+	auditLogSlice, truncated, err := auditsearch.Search(backend.NewCloudoneAnalysisClient(c.Ctx), criteria)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
+		return
+	}
+
+	// The file is written directly instead of the json
+	c.EnableRender = false
+	c.Ctx.Output.Header("Content-Type", contentType)
+	c.Ctx.Output.Header("X-Audit-Log-Truncated", strconv.FormatBool(truncated))
+	c.Ctx.Output.Header("Content-Disposition", "attachment; filename=auditlog_"+time.Now().Format("20060102150405")+"."+format)
+	auditsearch.Export(c.Ctx.ResponseWriter, format, auditLogSlice)
+}
@@ -0,0 +1,82 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package audit
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/auditsearch"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+	"strconv"
+)
+
+type ListController struct {
+	beego.Controller
+}
+
+const (
+	defaultSize = 100
+)
+
+// @Title get
+// @Description search the audit logs. The header X-Audit-Log-Total and X-Audit-Log-Truncated describe the whole result.
+// @Param userName query string false "The user name"
+// @Param component query string false "The component"
+// @Param pathPrefix query string false "The prefix of the path"
+// @Param method query string false "The HTTP method"
+// @Param remoteAddress query string false "Part of the remote address"
+// @Param requestID query string false "The request id of the GUI"
+// @Param from query string false "The RFC3339 time from"
+// @Param to query string false "The RFC3339 time to"
+// @Param sortBy query string false "createdTime, userName, component, path, method or remoteAddress"
+// @Param order query string false "asc or desc"
+// @Param offset query int false "The offset"
+// @Param size query int false "The size"
+// @Success 200 {string} []backend.AuditLog
+// @Failure 400 error reason
+// @Failure 404 error reason
+// @router / [get]
+func (c *ListController) Get() {
+	offset, _ := c.GetInt("offset")
+	size, err := c.GetInt("size")
+	if err != nil || size <= 0 {
+		size = defaultSize
+	}
+
+	criteria, err := auditsearch.ParseCriteria(c.GetString)
+	if err != nil {
+		// Error
+		guimessagedisplay.OutputJSONError(&c.Controller, 400, err)
+		return
+	}
+
+	auditLogSlice, truncated, err := auditsearch.Search(backend.NewCloudoneAnalysisClient(c.Ctx), criteria)
+
+	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+		return
+	}
+
+	if err != nil {
+		// Error
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
+		return
+	} else {
+		c.Ctx.Output.Header("X-Audit-Log-Total", strconv.Itoa(len(auditLogSlice)))
+		c.Ctx.Output.Header("X-Audit-Log-Truncated", strconv.FormatBool(truncated))
+		c.Data["json"] = auditsearch.Page(auditLogSlice, offset, size)
+		c.ServeJSON()
+	}
+}
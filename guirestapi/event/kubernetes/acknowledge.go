@@ -17,7 +17,8 @@ package kubernetes
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type AcknowledgeController struct {
@@ -37,16 +38,12 @@ func (c *AcknowledgeController) Put() {
 	id := c.GetString(":id")
 	acknowledge := c.GetString("acknowledge")
 
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/historicalevents/" + namespace + "/" + id + "?acknowledge=" + acknowledge
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	if err := identity.AuthorizeNamespace(c.Ctx, namespace); err != nil {
+		guimessagedisplay.OutputJSONError(&c.Controller, 403, err)
+		return
+	}
 
-	_, err := restclient.RequestPutWithStructure(url, nil, nil, tokenHeaderMap)
+	err := backend.NewCloudoneAnalysisClient(c.Ctx).AcknowledgeKubernetesEvent(namespace, id, acknowledge == "true")
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -54,10 +51,7 @@ func (c *AcknowledgeController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
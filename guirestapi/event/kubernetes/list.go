@@ -17,29 +17,14 @@ package kubernetes
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
-	"strconv"
-	"time"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type KubernetesEvent struct {
-	Namespace      string
-	Name           string
-	Kind           string
-	Source         map[string]interface{}
-	Id             string
-	FirstTimestamp time.Time
-	LastTimestamp  time.Time
-	Count          int
-	Message        string
-	Reason         string
-	Acknowledge    bool
-}
-
 const (
 	amountPerPage = 10
 )
@@ -50,10 +35,6 @@ const (
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) GetAll() {
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
 	acknowledge := c.GetString("acknowledge")
 	if acknowledge == "" {
 		acknowledge = "false"
@@ -61,14 +42,7 @@ func (c *ListController) GetAll() {
 
 	offset, _ := c.GetInt("offset")
 
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/historicalevents?acknowledge=" + acknowledge + "&size=" + strconv.Itoa(amountPerPage) + "&offset=" + strconv.Itoa(offset)
-
-	jsonMapSlice := make([]map[string]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &jsonMapSlice, tokenHeaderMap)
+	kubernetesEventSlice, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetKubernetesEventSlice(acknowledge == "true", amountPerPage, offset)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -76,55 +50,10 @@ func (c *ListController) GetAll() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	kubernetesEventSlice := make([]KubernetesEvent, 0)
-	for _, jsonMap := range jsonMapSlice {
-		sourceJsonMap, _ := jsonMap["_source"].(map[string]interface{})
-
-		namespace, _ := sourceJsonMap["metadata"].(map[string]interface{})["namespace"].(string)
-		name, _ := sourceJsonMap["involvedObject"].(map[string]interface{})["name"].(string)
-		kind, _ := sourceJsonMap["involvedObject"].(map[string]interface{})["kind"].(string)
-		source, _ := sourceJsonMap["source"].(map[string]interface{})
-		id, _ := jsonMap["_id"].(string)
-		firstTimestamp, _ := sourceJsonMap["firstTimestamp"].(string)
-		lastTimestamp, _ := sourceJsonMap["lastTimestamp"].(string)
-		count, _ := sourceJsonMap["count"].(float64)
-		message, _ := sourceJsonMap["message"].(string)
-		reason, _ := sourceJsonMap["reason"].(string)
-		acknowledge, _ := sourceJsonMap["searchMetaData"].(map[string]interface{})["acknowledge"].(bool)
-
-		firstTime, err := time.Parse(time.RFC3339, firstTimestamp)
-		if err == nil {
-			firstTime = firstTime.Local()
-		}
-		lastTime, err := time.Parse(time.RFC3339, lastTimestamp)
-		if err == nil {
-			lastTime = lastTime.Local()
-		}
-
-		kubernetesEvent := KubernetesEvent{
-			namespace,
-			name,
-			kind,
-			source,
-			id,
-			firstTime,
-			lastTime,
-			int(count),
-			message,
-			reason,
-			acknowledge,
-		}
-
-		kubernetesEventSlice = append(kubernetesEventSlice, kubernetesEvent)
-	}
-
 	previousOffset := offset - amountPerPage
 	if previousOffset < 0 {
 		previousOffset = 0
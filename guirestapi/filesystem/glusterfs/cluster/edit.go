@@ -18,8 +18,9 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
@@ -29,24 +30,13 @@ type EditController struct {
 // @Title get
 // @Description get the glusterfs cluster configuration
 // @Param clusterName path string true "The name of glusterfs cluster configuration"
-// @Success 200 {object} guirestapi.filesystem.glusterfs.cluster.GlusterfsCluster
+// @Success 200 {object} backend.GlusterfsCluster
 // @Failure 404 error reason
 // @router /:clusterName [get]
 func (c *EditController) Get() {
 	clusterName := c.GetString(":clusterName")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName
-
-	glusterfsCluster := GlusterfsCluster{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &glusterfsCluster, tokenHeaderMap)
+	glusterfsCluster, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsCluster(clusterName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -54,10 +44,7 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = glusterfsCluster
@@ -65,46 +52,23 @@ func (c *EditController) Get() {
 	}
 }
 
-type GlusterfsClusterInput struct {
-	Name                           string
-	HostSlice                      []string
-	Path                           string
-	SSHDialTimeoutInMilliSecond    int
-	SSHSessionTimeoutInMilliSecond int
-	SSHPort                        int
-	SSHUser                        string
-	SSHPassword                    string
-}
-
 // @Title create
 // @Description create gluster cluster configuration
-// @Param body body guirestapi.filesystem.glusterfs.cluster.GlusterfsClusterInput true "body for glusterfs cluster configuration"
+// @Param body body backend.GlusterfsClusterInput true "body for glusterfs cluster configuration"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [post]
 func (c *EditController) Post() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	glusterfsClusterInput := GlusterfsClusterInput{}
+	glusterfsClusterInput := backend.GlusterfsClusterInput{}
 	err := json.Unmarshal(inputBody, &glusterfsClusterInput)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPostWithStructure(url, glusterfsClusterInput, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).CreateGlusterfsCluster(glusterfsClusterInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -112,10 +76,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
@@ -125,33 +86,21 @@ func (c *EditController) Post() {
 
 // @Title update
 // @Description update gluster cluster configuration
-// @Param body body guirestapi.filesystem.glusterfs.cluster.GlusterfsClusterInput true "body for glusterfs cluster configuration"
+// @Param body body backend.GlusterfsClusterInput true "body for glusterfs cluster configuration"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [put]
 func (c *EditController) Put() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	glusterfsClusterInput := GlusterfsClusterInput{}
+	glusterfsClusterInput := backend.GlusterfsClusterInput{}
 	err := json.Unmarshal(inputBody, &glusterfsClusterInput)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + glusterfsClusterInput.Name
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPutWithStructure(url, glusterfsClusterInput, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).UpdateGlusterfsCluster(glusterfsClusterInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -159,10 +108,7 @@ func (c *EditController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
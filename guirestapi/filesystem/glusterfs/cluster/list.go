@@ -17,43 +17,21 @@ package cluster
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
-	"time"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type GlusterfsCluster struct {
-	Name              string
-	HostSlice         []string
-	Path              string
-	SSHDialTimeout    time.Duration
-	SSHSessionTimeout time.Duration
-	SSHPort           int
-	SSHUser           string
-	SSHPassword       string
-}
-
 // @Title get
 // @Description get all glusterfs cluster configurations
-// @Success 200 {string} []GlusterfsCluster
+// @Success 200 {string} []backend.GlusterfsCluster
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/"
-
-	glusterfsClusterSlice := make([]GlusterfsCluster, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &glusterfsClusterSlice, tokenHeaderMap)
+	glusterfsClusterSlice, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsClusterSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -61,10 +39,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = glusterfsClusterSlice
@@ -17,10 +17,10 @@ package volume
 import (
 	"encoding/json"
 	"github.com/astaxie/beego"
-	"github.com/cloudawan/cloudone_gui/controllers/filesystem/glusterfs/cluster"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type GlusterfsVolumeInput struct {
@@ -38,24 +38,13 @@ type CreateController struct {
 // @Title get
 // @Description get the glusterfs cluster configuration
 // @Param clusterName path string true "The name of glusterfs cluster configuration"
-// @Success 200 {object} guirestapi.filesystem.glusterfs.cluster.GlusterfsCluster
+// @Success 200 {object} backend.GlusterfsCluster
 // @Failure 404 error reason
 // @router /createinformation/:clusterName [get]
 func (c *CreateController) Get() {
 	clusterName := c.GetString(":clusterName")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName
-
-	glusterfsCluster := cluster.GlusterfsCluster{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &glusterfsCluster, tokenHeaderMap)
+	glusterfsCluster, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsCluster(clusterName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -63,10 +52,7 @@ func (c *CreateController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = glusterfsCluster
@@ -86,25 +72,22 @@ func (c *CreateController) Post() {
 	err := json.Unmarshal(inputBody, &glusterfsVolumeInput)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
 	clusterName := c.GetString("clusterName")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName + "/volumes/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	glusterfsVolumeCreateParameter := backend.GlusterfsVolumeCreateParameter{
+		ClusterName: clusterName,
+		VolumeName:  glusterfsVolumeInput.Name,
+		Stripe:      glusterfsVolumeInput.Stripe,
+		Replica:     glusterfsVolumeInput.Replica,
+		Transport:   glusterfsVolumeInput.Transport,
+		HostSlice:   glusterfsVolumeInput.HostSlice,
+	}
 
-	_, err = restclient.RequestPostWithStructure(url, glusterfsVolumeInput, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).CreateGlusterfsVolume(glusterfsVolumeCreateParameter)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -112,10 +95,7 @@ func (c *CreateController) Post() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
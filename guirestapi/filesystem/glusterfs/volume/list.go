@@ -17,46 +17,24 @@ package volume
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type GlusterfsVolume struct {
-	VolumeName     string
-	Type           string
-	VolumeID       string
-	Status         string
-	NumberOfBricks string
-	TransportType  string
-	Bricks         []string
-	Size           int
-	ClusterName    string
-}
-
 // @Title get
 // @Description get all glusterfs cluster configurations
 // @Param clusterName path string true "The name of gluster cluster configuration"
-// @Success 200 {string} []GlusterfsVolume
+// @Success 200 {string} []backend.GlusterfsVolume
 // @Failure 404 error reason
 // @router /:clusterName [get]
 func (c *ListController) Get() {
 	clusterName := c.GetString(":clusterName")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/glusterfs/clusters/" + clusterName + "/volumes/"
-
-	glusterfsVolumeSlice := make([]GlusterfsVolume, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &glusterfsVolumeSlice, tokenHeaderMap)
+	glusterfsVolumeSlice, err := backend.NewCloudoneClient(c.Ctx).GetGlusterfsVolumeSlice(clusterName)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -64,10 +42,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = glusterfsVolumeSlice
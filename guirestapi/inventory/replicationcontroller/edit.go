@@ -18,73 +18,34 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
 	beego.Controller
 }
 
-type ReplicationController struct {
-	Name           string
-	ReplicaAmount  int
-	Selector       ReplicationControllerSelector
-	Label          ReplicationControllerLabel
-	ContainerSlice []ReplicationControllerContainer
-}
-
-type ReplicationControllerSelector struct {
-	Name    string
-	Version string
-}
-
-type ReplicationControllerLabel struct {
-	Name string
-}
-
-type ReplicationControllerContainer struct {
-	Name      string
-	Image     string
-	PortSlice []ReplicationControllerContainerPort
-}
-
-type ReplicationControllerContainerPort struct {
-	Name          string
-	ContainerPort int
-}
-
 // @Title create
 // @Description create replication controller
-// @Param body body guirestapi.inventory.replicationcontroller.ReplicationController true "body for replication controller"
+// @Param body body backend.ReplicationController true "body for replication controller"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [post]
 func (c *EditController) Post() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	replicationController := ReplicationController{}
+	replicationController := backend.ReplicationController{}
 	err := json.Unmarshal(inputBody, &replicationController)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPostWithStructure(url, replicationController, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).CreateReplicationController(namespace, replicationController)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -92,10 +53,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
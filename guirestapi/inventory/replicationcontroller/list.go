@@ -17,63 +17,23 @@ package replicationcontroller
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type ReplicationControllerAndRelatedPod struct {
-	Name               string
-	Namespace          string
-	ReplicaAmount      int
-	AliveReplicaAmount int
-	Selector           map[string]string
-	Label              map[string]string
-	PodSlice           []Pod
-}
-
-type Pod struct {
-	Name           string
-	Namespace      string
-	HostIP         string
-	PodIP          string
-	ContainerSlice []PodContainer
-}
-
-type PodContainer struct {
-	Name      string
-	Image     string
-	PortSlice []PodContainerPort
-}
-
-type PodContainerPort struct {
-	Name          string
-	ContainerPort int
-	Protocol      string
-}
-
 // @Title get
 // @Description get all replication controllers
-// @Success 200 {string} []ReplicationControllerAndRelatedPod
+// @Success 200 {string} []backend.ReplicationControllerAndRelatedPod
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespace
-
-	replicationControllerAndRelatedPodSlice := make([]ReplicationControllerAndRelatedPod, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerAndRelatedPodSlice, tokenHeaderMap)
+	replicationControllerAndRelatedPodSlice, err := backend.NewCloudoneClient(c.Ctx).GetReplicationControllerAndRelatedPodSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -81,10 +41,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = replicationControllerAndRelatedPodSlice
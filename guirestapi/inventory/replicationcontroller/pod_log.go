@@ -17,7 +17,8 @@ package replicationcontroller
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type PodLogController struct {
@@ -35,29 +36,20 @@ func (c *PodLogController) Get() {
 	namespace := c.GetString(":namespace")
 	pod := c.GetString(":pod")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/podlogs/" + namespace + "/" + pod
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
+	if err := identity.AuthorizeNamespace(c.Ctx, namespace); err != nil {
+		guimessagedisplay.OutputJSONError(&c.Controller, 403, err)
+		return
+	}
 
-	result, err := restclient.RequestGet(url, tokenHeaderMap, true)
+	jsonMap, err := backend.NewCloudoneClient(c.Ctx).GetPodLog(namespace, pod)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
 	}
 
-	jsonMap, _ := result.(map[string]interface{})
-
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
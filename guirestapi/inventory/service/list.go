@@ -17,51 +17,23 @@ package service
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type Service struct {
-	Name            string
-	Namespace       string
-	PortSlice       []ServicePort
-	Selector        map[string]interface{}
-	ClusterIP       string
-	LabelMap        map[string]interface{}
-	SessionAffinity string
-}
-
-type ServicePort struct {
-	Name       string
-	Protocol   string
-	Port       string
-	TargetPort string
-	NodePort   int
-}
-
 // @Title get
 // @Description get all services
-// @Success 200 {string} []Service
+// @Success 200 {string} []backend.Service
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/services/" + namespace
-
-	serviceSlice := make([]Service, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &serviceSlice, tokenHeaderMap)
+	serviceSlice, err := backend.NewCloudoneClient(c.Ctx).GetServiceSlice(namespace)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -69,10 +41,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = serviceSlice
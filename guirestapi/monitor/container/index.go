@@ -17,44 +17,12 @@ package container
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"time"
 )
 
-type ReplicationControllerMetricList struct {
-	ErrorSlice                       []string
-	ReplicationControllerMetricSlice []ReplicationControllerMetric
-}
-
-type ReplicationControllerMetric struct {
-	Namespace                 string
-	ReplicationControllerName string
-	ValidPodSlice             []bool
-	PodMetricSlice            []PodMetric
-	Size                      int
-}
-
-type PodMetric struct {
-	KubeletHost          string
-	Namespace            string
-	PodName              string
-	ValidContainerSlice  []bool
-	ContainerMetricSlice []ContainerMetric
-}
-
-type ContainerMetric struct {
-	ContainerName                     string
-	CpuUsageTotalSlice                []int64
-	MemoryUsageSlice                  []int64
-	DiskIOServiceBytesStatsTotalSlice []int64
-	DiskIOServicedStatsTotalSlice     []int64
-	NetworkRXBytesSlice               []int64
-	NetworkTXBytesSlice               []int64
-	NetworkRXPacketsSlice             []int64
-	NetworkTXPacketsSlice             []int64
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -69,22 +37,12 @@ const (
 // @Failure 404 error reason
 // @router /selectinformation [get]
 func (c *IndexController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
 	cloudoneGUIProtocol := beego.AppConfig.String("cloudoneGUIProtocol")
 	cloudoneGUIHost, cloudoneGUIPort := dashboard.GetServerHostAndPortFromUserRequest(c.Ctx.Input)
 
 	namespaces, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/replicationcontrollers/" + namespaces
-
-	jsonMapSlice := make([]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &jsonMapSlice, tokenHeaderMap)
+	replicationControllerAndRelatedPodSlice, err := backend.NewCloudoneClient(c.Ctx).GetReplicationControllerAndRelatedPodSlice(namespaces)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -92,19 +50,15 @@ func (c *IndexController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		replicationControllerNameSlice := make([]string, 0)
 		replicationControllerNameSlice = append(replicationControllerNameSlice, allKeyword)
 
-		for _, jsonMap := range jsonMapSlice {
-			name, _ := jsonMap.(map[string]interface{})["Name"].(string)
-			if name != "" {
-				replicationControllerNameSlice = append(replicationControllerNameSlice, name)
+		for _, replicationControllerAndRelatedPod := range replicationControllerAndRelatedPodSlice {
+			if replicationControllerAndRelatedPod.Name != "" {
+				replicationControllerNameSlice = append(replicationControllerNameSlice, replicationControllerAndRelatedPod.Name)
 			}
 		}
 
@@ -130,22 +84,12 @@ type DataController struct {
 func (c *DataController) Get() {
 	replicationControllerName := c.GetString(":replicationController")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespaces, _ := c.GetSession("namespace").(string)
 
-	replicationControllerMetricSlice := make([]ReplicationControllerMetric, 0)
+	replicationControllerMetricSlice := make([]backend.ReplicationControllerMetric, 0)
 	replicationControllerMetricAmount := 0
 	if replicationControllerName != "" && replicationControllerName != allKeyword {
-		replicationControllerMetric := ReplicationControllerMetric{}
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/replicationcontrollermetrics/" + namespaces + "/" + replicationControllerName
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &replicationControllerMetric, tokenHeaderMap)
+		replicationControllerMetric, err := backend.NewCloudoneClient(c.Ctx).GetReplicationControllerMetric(namespaces, replicationControllerName)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -153,23 +97,13 @@ func (c *DataController) Get() {
 
 		if err != nil {
 			// Error
-			errorJsonMap := make(map[string]interface{})
-			errorJsonMap["error"] = err.Error()
-			c.Data["json"] = errorJsonMap
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 			return
 		}
-		replicationControllerMetricSlice = append(replicationControllerMetricSlice, replicationControllerMetric)
+		replicationControllerMetricSlice = append(replicationControllerMetricSlice, *replicationControllerMetric)
 		replicationControllerMetricAmount = 1
 	} else {
-		url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-			"/api/v1/replicationcontrollermetrics/" + namespaces
-
-		replicationControllerMetricList := ReplicationControllerMetricList{}
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		_, err := restclient.RequestGetWithStructure(url, &replicationControllerMetricList, tokenHeaderMap)
+		replicationControllerMetricList, err := backend.NewCloudoneClient(c.Ctx).GetReplicationControllerMetricList(namespaces)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
@@ -177,10 +111,7 @@ func (c *DataController) Get() {
 
 		if err != nil {
 			// Error
-			errorJsonMap := make(map[string]interface{})
-			errorJsonMap["error"] = err.Error()
-			c.Data["json"] = errorJsonMap
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 			return
 		}
 		replicationControllerMetricSlice = replicationControllerMetricList.ReplicationControllerMetricSlice
@@ -18,11 +18,10 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
-	"github.com/cloudawan/cloudone_utility/restclient"
-	"net/url"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"sort"
-	"strconv"
 	"time"
 )
 
@@ -40,22 +39,12 @@ const (
 // @Failure 404 error reason
 // @router /selectinformation [get]
 func (c *IndexController) Get() {
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
 	cloudoneGUIProtocol := beego.AppConfig.String("cloudoneGUIProtocol")
 	cloudoneGUIHost, cloudoneGUIPort := dashboard.GetServerHostAndPortFromUserRequest(c.Ctx.Input)
 
 	namespaces, _ := c.GetSession("namespace").(string)
 
-	url := cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-		"/api/v1/historicalreplicationcontrollers/names/" + namespaces
-
-	jsonMapSlice := make([]interface{}, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &jsonMapSlice, tokenHeaderMap)
+	nameSlice, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetHistoricalReplicationControllerNameSlice(namespaces)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -63,20 +52,10 @@ func (c *IndexController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
-		nameSlice := make([]string, 0)
-		nameSlice = append(nameSlice, allKeyword)
-		for _, value := range jsonMapSlice {
-			name, ok := value.(string)
-			if ok {
-				nameSlice = append(nameSlice, name)
-			}
-		}
+		nameSlice = append([]string{allKeyword}, nameSlice...)
 
 		c.Data["json"] = make(map[string]interface{})
 		c.Data["json"].(map[string]interface{})["cloudoneGUIProtocol"] = cloudoneGUIProtocol
@@ -108,10 +87,6 @@ func (c *DataController) Get() {
 	fromText := c.GetString("from")
 	toText := c.GetString("to")
 
-	cloudoneAnalysisProtocol := beego.AppConfig.String("cloudoneAnalysisProtocol")
-	cloudoneAnalysisHost := beego.AppConfig.String("cloudoneAnalysisHost")
-	cloudoneAnalysisPort := beego.AppConfig.String("cloudoneAnalysisPort")
-
 	namespaces, _ := c.GetSession("namespace").(string)
 	timeZoneOffset, _ := c.GetSession("timeZoneOffset").(int)
 
@@ -140,8 +115,6 @@ func (c *DataController) Get() {
 		from = from.Add(time.Minute * time.Duration(timeZoneOffset))
 	}
 
-	fromInRFC3339Nano := from.Format(time.RFC3339Nano)
-
 	to, err := time.Parse("01/02/2006 15:04 PM", toText)
 	if err != nil {
 		// Error
@@ -164,8 +137,6 @@ func (c *DataController) Get() {
 		to = to.Add(time.Minute * time.Duration(timeZoneOffset))
 	}
 
-	toInRFC3339Nano := to.Format(time.RFC3339Nano)
-
 	// Make sure from is before to
 	if from.Before(to) == false {
 		// Error
@@ -178,58 +149,28 @@ func (c *DataController) Get() {
 
 	allHistoricalReplicationControllerMetricJsonMap := make(map[string]interface{})
 	if replicationControllerName != "" && replicationControllerName != allKeyword {
-
-		encodingUrl, _ := url.Parse(cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-			"/api/v1/historicalreplicationcontrollermetrics/" + namespaces + "/" + replicationControllerName)
-		parameters := url.Values{}
-		parameters.Add("from", fromInRFC3339Nano)
-		parameters.Add("to", toInRFC3339Nano)
-		parameters.Add("aggregationAmount", strconv.Itoa(aggregationAmount))
-		encodingUrl.RawQuery = parameters.Encode()
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		result, err := restclient.RequestGet(encodingUrl.String(), tokenHeaderMap, true)
+		historicalReplicationControllerMetricJsonMap, err := backend.NewCloudoneAnalysisClient(c.Ctx).GetHistoricalReplicationControllerMetric(namespaces, replicationControllerName, from, to, aggregationAmount)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
 		}
 
-		historicalReplicationControllerMetricJsonMap, ok := result.(map[string]interface{})
-		if err != nil || ok == false {
+		if err != nil {
 			// Error
-			errorJsonMap := make(map[string]interface{})
-			errorJsonMap["error"] = err.Error()
-			c.Data["json"] = errorJsonMap
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 			return
 		}
 		allHistoricalReplicationControllerMetricJsonMap[replicationControllerName] = historicalReplicationControllerMetricJsonMap
 	} else {
-		encodingUrl, _ := url.Parse(cloudoneAnalysisProtocol + "://" + cloudoneAnalysisHost + ":" + cloudoneAnalysisPort +
-			"/api/v1/historicalreplicationcontrollermetrics/" + namespaces)
-		parameters := url.Values{}
-		parameters.Add("from", fromInRFC3339Nano)
-		parameters.Add("to", toInRFC3339Nano)
-		parameters.Add("aggregationAmount", strconv.Itoa(aggregationAmount))
-		encodingUrl.RawQuery = parameters.Encode()
-
-		tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-		result, err := restclient.RequestGet(encodingUrl.String(), tokenHeaderMap, true)
+		allHistoricalReplicationControllerMetricJsonMap, err = backend.NewCloudoneAnalysisClient(c.Ctx).GetAllHistoricalReplicationControllerMetric(namespaces, from, to, aggregationAmount)
 
 		if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 			return
 		}
 
-		var ok bool
-		allHistoricalReplicationControllerMetricJsonMap, ok = result.(map[string]interface{})
-		if err != nil || ok == false {
+		if err != nil {
 			// Error
-			errorJsonMap := make(map[string]interface{})
-			errorJsonMap["error"] = err.Error()
-			c.Data["json"] = errorJsonMap
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 			return
 		}
 	}
@@ -248,10 +189,7 @@ func (c *DataController) Get() {
 	// No data to show
 	if len(filterHistoricalReplicationControllerMetricJsonMap) == 0 {
 		// Error
-		errorJsonMap := make(map[string]interface{})
-		errorJsonMap["error"] = "Insufficient data"
-		c.Data["json"] = errorJsonMap
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 0, guimessagedisplay.NewGUIErrorMessage("Insufficient data"))
 		return
 	}
 
@@ -17,24 +17,12 @@ package node
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/dashboard"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"time"
 )
 
-type NodeMetric struct {
-	Valid                             bool
-	KubeletHost                       string
-	CpuUsageTotalSlice                []int64
-	MemoryUsageSlice                  []int64
-	DiskIOServiceBytesStatsTotalSlice []int64
-	DiskIOServicedStatsTotalSlice     []int64
-	NetworkRXBytesSlice               []int64
-	NetworkTXBytesSlice               []int64
-	NetworkRXPacketsSlice             []int64
-	NetworkTXPacketsSlice             []int64
-}
-
 type IndexController struct {
 	beego.Controller
 }
@@ -65,17 +53,7 @@ type DataController struct {
 // @Failure 404 error reason
 // @router / [get]
 func (c *DataController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort + "/api/v1/nodemetrics"
-
-	nodeMetricSlice := make([]NodeMetric, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &nodeMetricSlice, tokenHeaderMap)
+	nodeMetricSlice, err := backend.NewCloudoneClient(c.Ctx).GetNodeMetricSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -83,10 +61,7 @@ func (c *DataController) Get() {
 
 	if err != nil {
 		// Error
-		errorJsonMap := make(map[string]interface{})
-		errorJsonMap["error"] = err.Error()
-		c.Data["json"] = errorJsonMap
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 0, err)
 		return
 	}
 
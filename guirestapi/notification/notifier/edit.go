@@ -18,51 +18,29 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
 	beego.Controller
 }
 
-type EmailServerSMTP struct {
-	Name     string
-	Account  string
-	Password string
-	Host     string
-	Port     int
-}
-
-type SMSNexmo struct {
-	Name      string
-	Url       string
-	APIKey    string
-	APISecret string
-}
-
 // @Title get
 // @Description get the notifier
 // @Param kind path string true "The type of target notifier configured for"
 // @Param name path string true "The name of target notifier configured for"
-// @Success 200 {object} guirestapi.notification.notifier.ReplicationControllerNotifier
+// @Success 200 {object} backend.ReplicationControllerNotifier
 // @Failure 404 error reason
 // @router /:kind/:name [get]
 func (c *EditController) Get() {
 	kind := c.GetString("kind")
 	name := c.GetString("name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
+	cloudoneClient := backend.NewCloudoneClient(c.Ctx)
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/emailserversmtp"
-	emailServerSMTPSlice := make([]EmailServerSMTP, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &emailServerSMTPSlice, tokenHeaderMap)
+	emailServerSMTPSlice, err := cloudoneClient.GetEmailServerSMTPSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -70,18 +48,11 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/smsnexmo"
-	smsNexmoSlice := make([]SMSNexmo, 0)
-
-	_, err = restclient.RequestGetWithStructure(url, &smsNexmoSlice, tokenHeaderMap)
+	smsNexmoSlice, err := cloudoneClient.GetSMSNexmoSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -93,39 +64,25 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
 	if len(emailServerSMTPSlice) == 0 {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = "No Email server is configured"
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, guimessagedisplay.NewGUIErrorMessage("No Email server is configured"))
 		return
 	}
 
 	if len(smsNexmoSlice) == 0 {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = "No SMS server is configured"
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, guimessagedisplay.NewGUIErrorMessage("No SMS server is configured"))
 		return
 	}
 
 	namespace, _ := c.GetSession("namespace").(string)
 
-	url = cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/" + namespace + "/" + kind + "/" + name
-
-	replicationControllerNotifier := ReplicationControllerNotifier{}
-
-	_, err = restclient.RequestGetWithStructure(url, &replicationControllerNotifier, tokenHeaderMap)
+	replicationControllerNotifier, err := cloudoneClient.GetNotifier(namespace, kind, name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -133,10 +90,7 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
@@ -149,37 +103,25 @@ func (c *EditController) Get() {
 
 // @Title update
 // @Description update the notifier
-// @Param body body guirestapi.notification.notifier.ReplicationControllerNotifier true "body for notifier"
+// @Param body body backend.ReplicationControllerNotifier true "body for notifier"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [put]
 func (c *EditController) Put() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	replicationControllerNotifier := ReplicationControllerNotifier{}
+	replicationControllerNotifier := backend.ReplicationControllerNotifier{}
 	err := json.Unmarshal(inputBody, &replicationControllerNotifier)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
 	replicationControllerNotifier.Namespace = namespace
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPutWithStructure(url, replicationControllerNotifier, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).UpdateNotifier(replicationControllerNotifier)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -187,10 +129,7 @@ func (c *EditController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
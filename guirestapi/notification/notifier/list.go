@@ -17,71 +17,21 @@ package notifier
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
-	"time"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type ReplicationControllerNotifier struct {
-	Check             bool
-	CoolDownDuration  time.Duration
-	RemainingCoolDown time.Duration
-	KubeapiHost       string
-	KubeapiPort       int
-	Namespace         string
-	Kind              string
-	Name              string
-	NotifierSlice     []Notifier
-	IndicatorSlice    []Indicator
-}
-
-type Notifier struct {
-	Kind string
-	Data string
-}
-
-type NotifierSMSNexmo struct {
-	Destination         string
-	Sender              string
-	ReceiverNumberSlice []string
-}
-
-type NotifierEmail struct {
-	Destination          string
-	ReceiverAccountSlice []string
-}
-
-type Indicator struct {
-	Type                  string
-	AboveAllOrOne         bool
-	AbovePercentageOfData float64
-	AboveThreshold        int64
-	BelowAllOrOne         bool
-	BelowPercentageOfData float64
-	BelowThreshold        int64
-}
-
 // @Title get
 // @Description get all notifiers
-// @Success 200 {string} []ReplicationControllerNotifier
+// @Success 200 {string} []backend.ReplicationControllerNotifier
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/notifiers/"
-
-	replicationControllerNotifierSlice := make([]ReplicationControllerNotifier, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &replicationControllerNotifierSlice, tokenHeaderMap)
+	replicationControllerNotifierSlice, err := backend.NewCloudoneClient(c.Ctx).GetNotifierSlice()
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -89,10 +39,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = replicationControllerNotifierSlice
@@ -18,48 +18,32 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type UpgradeController struct {
 	beego.Controller
 }
 
-type DeployUpgradeInput struct {
-	ImageInformationName string
-	Description          string
-}
-
 // @Title upgrade
 // @Description upgrade the image information with new version
-// @Param body body guirestapi.repository.imageinformation.DeployUpgradeInput true "body for deploy upgrade input"
+// @Param body body backend.DeployUpgradeInput true "body for deploy upgrade input"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router /upgrade/ [put]
 func (c *UpgradeController) Put() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	deployUpgradeInput := DeployUpgradeInput{}
+	deployUpgradeInput := backend.DeployUpgradeInput{}
 	err := json.Unmarshal(inputBody, &deployUpgradeInput)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imageinformations/upgrade/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPutWithStructure(url, deployUpgradeInput, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).UpgradeImageInformation(deployUpgradeInput)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -67,10 +51,7 @@ func (c *UpgradeController) Put() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
@@ -17,44 +17,24 @@ package imagerecord
 import (
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type ListController struct {
 	beego.Controller
 }
 
-type ImageRecord struct {
-	ImageInformation string
-	Version          string
-	Path             string
-	VersionInfo      map[string]string
-	Environment      map[string]string
-	Description      string
-	CreatedTime      string
-}
-
 // @Title get
 // @Description get all image informations
 // @Param name query string true "The name of image information"
-// @Success 200 {string} []ImageRecord
+// @Success 200 {string} []backend.ImageRecord
 // @Failure 404 error reason
 // @router / [get]
 func (c *ListController) Get() {
 	name := c.GetString("name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imagerecords/" + name
-
-	imageRecordSlice := make([]ImageRecord, 0)
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &imageRecordSlice, tokenHeaderMap)
+	imageRecordSlice, err := backend.NewCloudoneClient(c.Ctx).GetImageRecordSlice(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -62,10 +42,7 @@ func (c *ListController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = imageRecordSlice
@@ -4,8 +4,9 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type EditController struct {
@@ -15,23 +16,13 @@ type EditController struct {
 // @Title get
 // @Description get the cluster application template
 // @Param name path string true "The name of cluster application template"
-// @Success 200 {object} guirestapi.repository.thirdparty.Cluster
+// @Success 200 {object} backend.Cluster
 // @Failure 404 error reason
 // @router /:name [get]
 func (c *EditController) Get() {
 	name := c.GetString("name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/" + name
-	cluster := Cluster{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &cluster, tokenHeaderMap)
+	cluster, err := backend.NewCloudoneClient(c.Ctx).GetClusterApplication(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -39,10 +30,7 @@ func (c *EditController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = cluster
@@ -52,27 +40,20 @@ func (c *EditController) Get() {
 
 // @Title create
 // @Description create the cluster application template
-// @Param body body guirestapi.repository.thirdparty.Cluster true "body for cluster application template"
+// @Param body body backend.Cluster true "body for cluster application template"
 // @Success 200 {string} {}
 // @Failure 404 error reason
 // @router / [post]
 func (c *EditController) Post() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	cluster := Cluster{}
+	cluster := backend.Cluster{}
 	err := json.Unmarshal(inputBody, &cluster)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	if cluster.ReplicationControllerJson == "" {
 		cluster.ReplicationControllerJson = "{}"
 	}
@@ -80,12 +61,7 @@ func (c *EditController) Post() {
 		cluster.ServiceJson = "{}"
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/"
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPost(url, cluster, tokenHeaderMap, true)
+	err = backend.NewCloudoneClient(c.Ctx).CreateClusterApplication(cluster)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -93,10 +69,7 @@ func (c *EditController) Post() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = make(map[string]interface{})
@@ -18,27 +18,12 @@ import (
 	"encoding/json"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 	"strings"
 )
 
-type Cluster struct {
-	Name                      string
-	Description               string
-	ReplicationControllerJson string
-	ServiceJson               string
-	Environment               map[string]string
-	ScriptType                string
-	ScriptContent             string
-}
-
-type ClusterLaunch struct {
-	Size                              int
-	EnvironmentSlice                  []interface{}
-	ReplicationControllerExtraJsonMap map[string]interface{}
-}
-
 type LaunchController struct {
 	beego.Controller
 }
@@ -46,23 +31,13 @@ type LaunchController struct {
 // @Title get
 // @Description get the cluster application template
 // @Param name path string true "The name of cluster application template"
-// @Success 200 {object} guirestapi.repository.thirdparty.Cluster
+// @Success 200 {object} backend.Cluster
 // @Failure 404 error reason
 // @router /launchinformation/:name [get]
 func (c *LaunchController) Get() {
 	name := c.GetString("name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/" + name
-	cluster := Cluster{}
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestGetWithStructure(url, &cluster, tokenHeaderMap)
+	cluster, err := backend.NewCloudoneClient(c.Ctx).GetClusterApplication(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -70,10 +45,7 @@ func (c *LaunchController) Get() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		c.Data["json"] = cluster
@@ -83,7 +55,7 @@ func (c *LaunchController) Get() {
 
 // @Title launch
 // @Description launch the cluster application template
-// @Param body body guirestapi.repository.thirdparty.Cluster true "body for cluster application template"
+// @Param body body backend.Cluster true "body for cluster application template"
 // @Param name query string true "The name to use"
 // @Param size query string true "The size to use"
 // @Success 200 {string} {}
@@ -94,36 +66,23 @@ func (c *LaunchController) Post() {
 	size, _ := c.GetInt("size")
 
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	environmentSlice := make([]interface{}, 0)
+	environmentSlice := make([]backend.ClusterEnvironment, 0)
 	err := json.Unmarshal(inputBody, &environmentSlice)
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	namespace, _ := c.GetSession("namespace").(string)
 
-	clusterLaunch := ClusterLaunch{
-		size,
-		environmentSlice,
-		nil,
+	clusterLaunch := backend.ClusterLaunch{
+		Size:                              size,
+		EnvironmentSlice:                  environmentSlice,
+		ReplicationControllerExtraJsonMap: nil,
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/clusterapplications/launch/" + namespace + "/" + name
-	jsonMap := make(map[string]interface{})
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err = restclient.RequestPostWithStructure(url, clusterLaunch, &jsonMap, tokenHeaderMap)
+	err = backend.NewCloudoneClient(c.Ctx).LaunchClusterApplication(namespace, name, clusterLaunch)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -131,20 +90,13 @@ func (c *LaunchController) Post() {
 
 	if err != nil {
 		// Error
-		errorMessage, _ := jsonMap["Error"].(string)
-		if strings.HasPrefix(errorMessage, "Replication controller already exists") {
+		if strings.HasPrefix(backend.GetResponseError(err), "Replication controller already exists") {
 			// Error
-			c.Data["json"] = make(map[string]interface{})
-			c.Data["json"].(map[string]interface{})["error"] = "Replication controller " + name + " already exists"
-			c.Ctx.Output.Status = 404
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 404, guimessagedisplay.NewGUIErrorMessage("Replication controller "+name+" already exists"))
 			return
 		} else {
 			// Error
-			c.Data["json"] = make(map[string]interface{})
-			c.Data["json"].(map[string]interface{})["error"] = err.Error()
-			c.Ctx.Output.Status = 404
-			c.ServeJSON()
+			guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 			return
 		}
 	} else {
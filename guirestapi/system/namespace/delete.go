@@ -15,9 +15,11 @@
 package namespace
 
 import (
+	"errors"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 	"time"
 )
 
@@ -34,16 +36,7 @@ type DeleteController struct {
 func (c *DeleteController) Delete() {
 	name := c.GetString(":name")
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/namespaces/" + name
-
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestDelete(url, nil, tokenHeaderMap, true)
+	err := backend.NewCloudoneClient(c.Ctx).DeleteNamespace(name)
 
 	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
 		return
@@ -51,15 +44,16 @@ func (c *DeleteController) Delete() {
 
 	if err != nil {
 		// Error
-		c.Data["json"] = make(map[string]interface{})
-		c.Data["json"].(map[string]interface{})["error"] = err.Error()
-		c.Ctx.Output.Status = 404
-		c.ServeJSON()
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
 		return
 	} else {
 		selectedNamespace := c.GetSession("namespace")
 		if selectedNamespace.(string) == name {
-			c.SetSession("namespace", "default")
+			if err := identity.SetNamespace(c, "default"); err != nil {
+				// The namespace is deleted but the session can't be switched to another namespace
+				guimessagedisplay.OutputJSONError(&c.Controller, 403, errors.New(err.Error()+". Please select another namespace"))
+				return
+			}
 		}
 
 		time.Sleep(1000 * time.Millisecond)
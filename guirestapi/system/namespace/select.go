@@ -16,6 +16,8 @@ package namespace
 
 import (
 	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
 )
 
 type SelectController struct {
@@ -26,12 +28,16 @@ type SelectController struct {
 // @Description select the current namespace
 // @Param name path string true "The name of namespace"
 // @Success 200 {string} {}
-// @Failure 404 error reason
+// @Failure 403 error reason
 // @router /select/:name [put]
 func (c *SelectController) Put() {
 	name := c.GetString(":name")
 
-	c.SetSession("namespace", name)
+	if err := identity.SetNamespace(c, name); err != nil {
+		// Error
+		guimessagedisplay.OutputJSONError(&c.Controller, 403, err)
+		return
+	}
 
 	c.Data["json"] = make(map[string]interface{})
 	c.ServeJSON()
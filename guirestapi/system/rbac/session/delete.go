@@ -0,0 +1,45 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/guimessagedisplay"
+)
+
+type DeleteController struct {
+	beego.Controller
+}
+
+// @Title delete
+// @Description terminate the GUI session
+// @Param id path string true "The id of session"
+// @Success 200 {string} {}
+// @Failure 404 error reason
+// @router /:id [delete]
+func (c *DeleteController) Delete() {
+	id := c.GetString(":id")
+
+	err := identity.RevokeSession(id)
+	if err != nil {
+		// Error
+		guimessagedisplay.OutputJSONError(&c.Controller, 404, err)
+		return
+	} else {
+		c.Data["json"] = make(map[string]interface{})
+		c.ServeJSON()
+	}
+}
@@ -0,0 +1,33 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package session
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+)
+
+type ListController struct {
+	beego.Controller
+}
+
+// @Title get
+// @Description get all active GUI sessions
+// @Success 200 {string} []identity.ActiveSession
+// @router / [get]
+func (c *ListController) Get() {
+	c.Data["json"] = identity.GetActiveSessionSlice()
+	c.ServeJSON()
+}
@@ -15,19 +15,79 @@
 package main
 
 import (
+	"fmt"
 	"github.com/astaxie/beego"
 	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/bluegreenpromotion"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/canary"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/configuration"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/fakebackend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/shutdown"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
 	_ "github.com/cloudawan/cloudone_gui/docs" // Import document generation
 	restapiidentity "github.com/cloudawan/cloudone_gui/restapi/v1/identity"
 	_ "github.com/cloudawan/cloudone_gui/routers"
+	"os"
 )
 
 func main() {
+	// Defaults, the file, the environment variables and the flags are layered in order
+	option, err := configuration.Load(os.Args[1:])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if option.PrintConfig {
+		fmt.Print(configuration.GetEffectiveConfigText())
+		return
+	}
+	// The demo mode replaces cloudone and cloudone_analysis of all clusters with the embedded fake
+	if fakebackend.IsDemoMode() {
+		if err := fakebackend.StartDemo(); err != nil {
+			fmt.Fprintln(os.Stderr, "Fail to start the demo backend with error "+err.Error())
+			os.Exit(1)
+		}
+	}
+	if err := configuration.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	beego.Info("Effective configuration\n" + configuration.GetEffectiveConfigText())
+
+	// The replicas share the sessions and the websocket tickets in the session store
+	if err := sessionstore.Initialize(); err != nil {
+		fmt.Fprintln(os.Stderr, "Fail to initialize the session store with error "+err.Error())
+		os.Exit(1)
+	}
+
+	beego.InsertFilter("*", beego.BeforeRouter, tracing.FilterRequestID)
+	beego.InsertFilter("*", beego.BeforeRouter, shutdown.FilterDraining)
+	beego.InsertFilter("*", beego.BeforeExec, metrics.FilterRoute)
 	beego.InsertFilter("/gui/*", beego.BeforeRouter, identity.FilterUser)
+	beego.InsertFilter("/gui/*", beego.BeforeRouter, identity.FilterCSRF)
 	beego.InsertFilter("/api/v1/*", beego.BeforeRouter, restapiidentity.FilterToken)
+	beego.InsertFilter("/guirestapi/v1/*", beego.BeforeRouter, identity.FilterPersonalAccessToken)
 	beego.InsertFilter("/guirestapi/v1/*", beego.BeforeRouter, identity.FilterUser)
+	beego.InsertFilter("/guirestapi/v1/*", beego.BeforeRouter, identity.FilterCSRF)
+
+	// Advance the canary deployments and roll them back when the thresholds are crossed
+	canary.StartRunner()
+	// Advance the blue green promotions through the health gates
+	bluegreenpromotion.StartRunner()
+
+	// SIGINT and SIGTERM drain the requests and the websocket sessions and then stop beego
+	shutdown.HandleSignal()
 
-	beego.AppConfigPath = "/etc/cloudone_gui/app.conf"
+	// The middlewares end the span of the request started by FilterRequestID and count the request
+	beego.RunWithMiddleWares("", tracing.MiddleWare, metrics.MiddleWare)
 
-	beego.Run()
+	// Flush what is still in memory after no request is served
+	shutdown.Wait()
+	canary.StopRunner()
+	bluegreenpromotion.StopRunner()
+	identity.StopAuditLogQueue()
+	tracing.StopExporter()
+	beego.Info("The GUI server is stopped")
 }
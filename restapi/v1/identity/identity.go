@@ -16,18 +16,126 @@ package identity
 
 import (
 	"github.com/astaxie/beego/context"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
+	"github.com/cloudawan/cloudone_utility/rbac"
 )
 
 const (
-	loginURL         = "/api/v1/identity/login"
-	webhookGithubURL = "/api/v1/webhook/github" // The webhook has its own verification
+	loginURL = "/api/v1/identity/login"
 )
 
+// FilterToken verifies the token of each request against cloudone and checks the permission of the route.
+// The token is read from the header Token or from the query parameter token for the clients such as GitHub webhook which can't set the header.
 func FilterToken(ctx *context.Context) {
-	if (ctx.Input.IsGet() || ctx.Input.IsPost()) && (ctx.Input.URL() == loginURL || ctx.Input.URL() == webhookGithubURL) {
-		// Don't redirect itself to prevent the circle
+	if (ctx.Input.IsGet() || ctx.Input.IsPost()) && ctx.Input.URL() == loginURL {
+		// Don't verify the login itself to prevent the circle
+		return
+	}
+
+	token := GetToken(ctx)
+	if token == "" {
+		outputError(ctx, 401, "Unauthorized. Token is required")
+		return
+	}
+
+	var user *rbac.User
+	tokenHeaderMap := make(map[string]string)
+	if identity.IsPersonalAccessToken(token) {
+		// The backend is accessed with the service account while the owner of the token is authorized here.
+		// The REST API for the integration always works on the default cluster.
+		var err error
+		user, tokenHeaderMap, err = identity.AuthenticatePersonalAccessToken(backend.GetDefaultClusterName(), token, ctx.Input.Method(), ctx.Input.URL(), ctx.Input.IP())
+		if backend.IsUnavailable(err) {
+			outputError(ctx, 503, "Service unavailable. Fail to verify token with error "+err.Error())
+			return
+		}
+		if err != nil {
+			outputError(ctx, 401, "Unauthorized. "+err.Error())
+			return
+		}
+	} else {
+		var err error
+		user, err = getUserFromToken(token)
+		if identity.IsTokenInvalid(err) {
+			outputError(ctx, 401, "Unauthorized. Token is invalid or expired")
+			return
+		}
+		// The client should retry rather than login again when the backend is down
+		if backend.IsUnavailable(err) {
+			outputError(ctx, 503, "Service unavailable. Fail to verify token with error "+err.Error())
+			return
+		}
+		if err != nil {
+			outputError(ctx, 401, "Unauthorized. Fail to verify token with error "+err.Error())
+			return
+		}
+		tokenHeaderMap["token"] = token
+	}
+
+	if user.HasPermission(identity.GetConponentName(), ctx.Input.Method(), ctx.Input.URL()) == false {
+		outputError(ctx, 403, "Forbidden. User "+user.Name+" is not authorized to "+ctx.Input.Method()+" "+ctx.Input.URL())
+		return
+	}
+
+	ctx.Input.SetData("user", user)
+	ctx.Input.SetData("tokenHeaderMap", tokenHeaderMap)
+}
+
+// GetToken returns the token sent by the client
+func GetToken(ctx *context.Context) string {
+	token := ctx.Input.Header("Token")
+	if token == "" {
+		token = ctx.Input.Query("token")
+	}
+	return token
+}
+
+// GetTokenHeaderMap returns the verified token set by FilterToken to relay to the backend
+func GetTokenHeaderMap(ctx *context.Context) map[string]string {
+	tokenHeaderMap, _ := ctx.Input.GetData("tokenHeaderMap").(map[string]string)
+	return tokenHeaderMap
+}
+
+// GetUser returns the verified user set by FilterToken
+func GetUser(ctx *context.Context) *rbac.User {
+	user, _ := ctx.Input.GetData("user").(*rbac.User)
+	return user
+}
+
+// IsTokenInvalidAndUnauthorized is used when the backend rejects the token which is still cached here
+func IsTokenInvalidAndUnauthorized(ctx *context.Context, err error) bool {
+	if identity.IsTokenInvalid(err) {
+		removeCachedToken(GetToken(ctx))
+		outputError(ctx, 401, "Unauthorized. Token is invalid or expired")
+		return true
 	} else {
-		// No need to verify here since it is just relay the data.
-		// The authorization happens in the real processing places
+		return false
+	}
+}
+
+func getUserFromToken(token string) (*rbac.User, error) {
+	user := getCachedUser(token)
+	if user != nil {
+		return user, nil
 	}
+
+	tokenHeaderMap := make(map[string]string)
+	tokenHeaderMap["token"] = token
+
+	user, err := backend.NewCloudoneClientWithTokenHeaderMap(tokenHeaderMap).GetUserFromToken(token, identity.GetConponentName())
+	if err != nil {
+		return nil, err
+	}
+
+	cacheUser(token, user)
+
+	return user, nil
+}
+
+func outputError(ctx *context.Context, statusCode int, errorMessage string) {
+	errorJsonMap := make(map[string]interface{})
+	errorJsonMap["error"] = errorMessage
+	ctx.Output.SetStatus(statusCode)
+	ctx.Output.JSON(errorJsonMap, false, false)
 }
@@ -17,23 +17,14 @@ package identity
 import (
 	"encoding/json"
 	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
 )
 
 type LoginController struct {
 	beego.Controller
 }
 
-type UserData struct {
-	Username string
-	Password string
-}
-
-type TokenData struct {
-	Token string
-}
-
 func (c *LoginController) Get() {
 	errorJsonMap := make(map[string]interface{})
 	errorJsonMap["error"] = "Unauthorized"
@@ -44,7 +35,7 @@ func (c *LoginController) Get() {
 
 func (c *LoginController) Post() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
-	userData := UserData{}
+	userData := backend.UserData{}
 	err := json.Unmarshal(inputBody, &userData)
 	if err != nil {
 		// Error
@@ -56,28 +47,19 @@ func (c *LoginController) Post() {
 		return
 	}
 
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	// User
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/authorizations/tokens/"
-
-	tokenData := TokenData{}
-	errorInterface, err := restclient.RequestPostWithStructure(url, userData, &tokenData, nil)
-	errorJsonMap, _ := errorInterface.(map[string]interface{})
+	token, err := backend.NewCloudoneClientWithTokenHeaderMap(nil).CreateToken(userData)
 
 	if err != nil {
 		// Error
-		c.Data["json"] = errorJsonMap
+		c.Data["json"] = backend.GetResponseJsonMap(err)
 		c.Ctx.Output.Status = 401
 		c.ServeJSON()
 		return
 	}
 
 	jsonMap := make(map[string]interface{})
-	jsonMap["Token"] = tokenData.Token
+	jsonMap["Token"] = token
 	c.Data["json"] = jsonMap
 
 	c.ServeJSON()
@@ -0,0 +1,81 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package identity
+
+import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_utility/rbac"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTokenCacheTTLInSecond = 60
+)
+
+type cachedToken struct {
+	user        *rbac.User
+	expiredTime time.Time
+}
+
+var tokenCacheMap = make(map[string]cachedToken)
+var tokenCacheLock = sync.Mutex{}
+
+func getTokenCacheTTL() time.Duration {
+	return time.Duration(beego.AppConfig.DefaultInt("restapiTokenCacheTTLInSecond", defaultTokenCacheTTLInSecond)) * time.Second
+}
+
+// getCachedUser returns nil if the token is not cached or expired
+func getCachedUser(token string) *rbac.User {
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+
+	cached, ok := tokenCacheMap[token]
+	if ok == false {
+		return nil
+	}
+	if time.Now().After(cached.expiredTime) {
+		delete(tokenCacheMap, token)
+		return nil
+	}
+	return cached.user
+}
+
+func cacheUser(token string, user *rbac.User) {
+	ttl := getTokenCacheTTL()
+	if ttl <= 0 {
+		return
+	}
+
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+
+	now := time.Now()
+	// Clean the expired tokens so the map doesn't grow with the tokens never used again
+	for key, cached := range tokenCacheMap {
+		if now.After(cached.expiredTime) {
+			delete(tokenCacheMap, key)
+		}
+	}
+
+	tokenCacheMap[token] = cachedToken{user, now.Add(ttl)}
+}
+
+func removeCachedToken(token string) {
+	tokenCacheLock.Lock()
+	defer tokenCacheLock.Unlock()
+
+	delete(tokenCacheMap, token)
+}
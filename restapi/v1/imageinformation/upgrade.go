@@ -17,30 +17,19 @@ package imageinformation
 import (
 	"encoding/json"
 	"github.com/astaxie/beego"
-	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/restapi/v1/identity"
 )
 
 type UpdateController struct {
 	beego.Controller
 }
 
-type DeployUpgradeInput struct {
-	ImageInformationName string
-	Description          string
-}
-
 func (c *UpdateController) Post() {
 	inputBody := c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum)
 
-	token := c.Ctx.Input.Header("Token")
-
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
-	deployUpgradeInput := DeployUpgradeInput{}
+	deployUpgradeInput := backend.DeployUpgradeInput{}
 	err := json.Unmarshal(inputBody, &deployUpgradeInput)
 	if err != nil {
 		// Error
@@ -52,15 +41,9 @@ func (c *UpdateController) Post() {
 		return
 	}
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/imageinformations/upgrade/"
-
-	tokenHeaderMap := make(map[string]string, 0)
-	tokenHeaderMap["token"] = token
-
-	_, err = restclient.RequestPutWithStructure(url, deployUpgradeInput, nil, tokenHeaderMap)
+	err = backend.NewCloudoneClientWithTokenHeaderMap(identity.GetTokenHeaderMap(c.Ctx)).UpgradeImageInformation(deployUpgradeInput)
 
-	if identity.IsTokenInvalidAndRedirect(c, c.Ctx, err) {
+	if identity.IsTokenInvalidAndUnauthorized(c.Ctx, err) {
 		return
 	}
 
@@ -16,46 +16,33 @@ package github
 
 import (
 	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/backend"
 	"github.com/cloudawan/cloudone_gui/controllers/utility/limit"
-	"github.com/cloudawan/cloudone_utility/restclient"
+	"github.com/cloudawan/cloudone_gui/restapi/v1/identity"
 )
 
-type GithubPost struct {
-	User             string
-	ImageInformation string
-	Signature        string
-	Payload          string
-}
-
 type PushController struct {
 	beego.Controller
 }
 
 func (c *PushController) Post() {
-	cloudoneProtocol := beego.AppConfig.String("cloudoneProtocol")
-	cloudoneHost := beego.AppConfig.String("cloudoneHost")
-	cloudonePort := beego.AppConfig.String("cloudonePort")
-
 	user := c.GetString("user")
 	imageInformation := c.GetString("imageInformation")
 	signature := c.Ctx.Input.Header("X-Hub-Signature")
 	payload := string(c.Ctx.Input.CopyBody(limit.InputPostBodyMaximum))
 
-	url := cloudoneProtocol + "://" + cloudoneHost + ":" + cloudonePort +
-		"/api/v1/webhooks/github"
-
-	githubPost := GithubPost{
-		user,
-		imageInformation,
-		signature,
-		payload,
+	githubPost := backend.GithubPost{
+		User:             user,
+		ImageInformation: imageInformation,
+		Signature:        signature,
+		Payload:          payload,
 	}
 
-	errorJsonMap := make(map[string]interface{})
+	err := backend.NewCloudoneClientWithTokenHeaderMap(identity.GetTokenHeaderMap(c.Ctx)).PostGithubWebhook(githubPost)
 
-	tokenHeaderMap, _ := c.GetSession("tokenHeaderMap").(map[string]string)
-
-	_, err := restclient.RequestPostWithStructure(url, githubPost, &errorJsonMap, tokenHeaderMap)
+	if identity.IsTokenInvalidAndUnauthorized(c.Ctx, err) {
+		return
+	}
 
 	if err != nil {
 		// Error
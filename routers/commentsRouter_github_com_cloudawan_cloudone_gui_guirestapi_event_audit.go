@@ -0,0 +1,23 @@
+package routers
+
+import (
+	"github.com/astaxie/beego"
+)
+
+func init() {
+
+	beego.GlobalControllerRouter["github.com/cloudawan/cloudone_gui/guirestapi/event/audit:ExportController"] = append(beego.GlobalControllerRouter["github.com/cloudawan/cloudone_gui/guirestapi/event/audit:ExportController"],
+		beego.ControllerComments{
+			"Get",
+			`/export/:format`,
+			[]string{"get"},
+			nil})
+
+	beego.GlobalControllerRouter["github.com/cloudawan/cloudone_gui/guirestapi/event/audit:ListController"] = append(beego.GlobalControllerRouter["github.com/cloudawan/cloudone_gui/guirestapi/event/audit:ListController"],
+		beego.ControllerComments{
+			"Get",
+			`/`,
+			[]string{"get"},
+			nil})
+
+}
@@ -27,6 +27,7 @@ import (
 	"github.com/cloudawan/cloudone_gui/guirestapi/deploy/deploy"
 	"github.com/cloudawan/cloudone_gui/guirestapi/deploy/deploybluegreen"
 	"github.com/cloudawan/cloudone_gui/guirestapi/deploy/deployclusterapplication"
+	"github.com/cloudawan/cloudone_gui/guirestapi/event/audit"
 	"github.com/cloudawan/cloudone_gui/guirestapi/event/kubernetes"
 	"github.com/cloudawan/cloudone_gui/guirestapi/filesystem/glusterfs/cluster"
 	"github.com/cloudawan/cloudone_gui/guirestapi/filesystem/glusterfs/volume"
@@ -42,6 +43,7 @@ import (
 	"github.com/cloudawan/cloudone_gui/guirestapi/system/namespace"
 	"github.com/cloudawan/cloudone_gui/guirestapi/system/notification/emailserver"
 	"github.com/cloudawan/cloudone_gui/guirestapi/system/notification/sms"
+	"github.com/cloudawan/cloudone_gui/guirestapi/system/rbac/session"
 )
 
 // Beego Rest API generation will use this file router.go
@@ -86,6 +88,12 @@ func init() {
 				&deployclusterapplication.SizeController{},
 			),
 		),
+		beego.NSNamespace("/eventaudit",
+			beego.NSInclude(
+				&audit.ExportController{},
+				&audit.ListController{},
+			),
+		),
 		beego.NSNamespace("/eventkubernetes",
 			beego.NSInclude(
 				&kubernetes.AcknowledgeController{},
@@ -191,6 +199,12 @@ func init() {
 				&sms.ListController{},
 			),
 		),
+		beego.NSNamespace("/systemrbacsession",
+			beego.NSInclude(
+				&session.DeleteController{},
+				&session.ListController{},
+			),
+		),
 	)
 	beego.AddNamespace(ns)
 }
@@ -26,7 +26,9 @@ import (
 	"github.com/cloudawan/cloudone_gui/controllers/deploy/clone"
 	"github.com/cloudawan/cloudone_gui/controllers/deploy/deploy"
 	"github.com/cloudawan/cloudone_gui/controllers/deploy/deploybluegreen"
+	"github.com/cloudawan/cloudone_gui/controllers/deploy/deploycanary"
 	"github.com/cloudawan/cloudone_gui/controllers/deploy/deployclusterapplication"
+	"github.com/cloudawan/cloudone_gui/controllers/deploy/deploymanifest"
 	"github.com/cloudawan/cloudone_gui/controllers/event/audit"
 	"github.com/cloudawan/cloudone_gui/controllers/event/kubernetes"
 	"github.com/cloudawan/cloudone_gui/controllers/filesystem/glusterfs/cluster"
@@ -43,6 +45,8 @@ import (
 	"github.com/cloudawan/cloudone_gui/controllers/repository/thirdparty"
 	"github.com/cloudawan/cloudone_gui/controllers/repository/topologytemplate"
 	"github.com/cloudawan/cloudone_gui/controllers/system/about"
+	systemcache "github.com/cloudawan/cloudone_gui/controllers/system/cache"
+	systemcluster "github.com/cloudawan/cloudone_gui/controllers/system/cluster"
 	"github.com/cloudawan/cloudone_gui/controllers/system/host/credential"
 	"github.com/cloudawan/cloudone_gui/controllers/system/namespace"
 	"github.com/cloudawan/cloudone_gui/controllers/system/notification/emailserver"
@@ -51,15 +55,21 @@ import (
 	privateregistryrepository "github.com/cloudawan/cloudone_gui/controllers/system/privateregistry/repository"
 	privateregistryserver "github.com/cloudawan/cloudone_gui/controllers/system/privateregistry/server"
 	"github.com/cloudawan/cloudone_gui/controllers/system/rbac/role"
+	"github.com/cloudawan/cloudone_gui/controllers/system/rbac/session"
 	"github.com/cloudawan/cloudone_gui/controllers/system/rbac/user"
+	"github.com/cloudawan/cloudone_gui/controllers/system/rbac/user/token"
 	"github.com/cloudawan/cloudone_gui/controllers/system/slb/daemon"
 	"github.com/cloudawan/cloudone_gui/controllers/system/upgrade"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/metrics"
 )
 
 func init() {
 
 	beego.Router("/", &controllers.MainController{})
+	beego.Handler("/metrics", metrics.Handler())
 	beego.Router("/gui/login", &identity.LoginController{})
+	beego.Router("/gui/login/oidc", &identity.OIDCLoginController{})
+	beego.Router("/gui/login/oidc/callback", &identity.OIDCCallbackController{})
 	beego.Router("/gui/logout", &identity.LogoutController{})
 
 	beego.Router("/gui/dashboard/topology", &topology.IndexController{})
@@ -91,16 +101,32 @@ func init() {
 	beego.Router("/gui/deploy/deploy/create", &deploy.CreateController{})
 	beego.Router("/gui/deploy/deploy/update", &deploy.UpdateController{})
 	beego.Router("/gui/deploy/deploy/resize", &deploy.ResizeController{})
+	beego.Router("/gui/deploy/deploy/revision", &deploy.RevisionController{})
+	beego.Router("/gui/deploy/deploy/rollback", &deploy.RollbackController{})
 	beego.Router("/gui/deploy/deploy/delete", &deploy.DeleteController{})
 	beego.Router("/gui/deploy/deploybluegreen/list", &deploybluegreen.ListController{})
 	beego.Router("/gui/deploy/deploybluegreen/select", &deploybluegreen.SelectController{})
+	beego.Router("/gui/deploy/deploybluegreen/promote", &deploybluegreen.PromoteController{})
+	beego.Router("/gui/deploy/deploybluegreen/promotion", &deploybluegreen.PromotionController{})
+	beego.Router("/gui/deploy/deploybluegreen/promotion/websocket", &deploybluegreen.PromotionWebSocketController{})
+	beego.Router("/gui/deploy/deploybluegreen/abort", &deploybluegreen.PromotionAbortController{})
+	beego.Router("/gui/deploy/deploybluegreen/revert", &deploybluegreen.PromotionRevertController{})
 	beego.Router("/gui/deploy/deploybluegreen/delete", &deploybluegreen.DeleteController{})
+	beego.Router("/gui/deploy/deploycanary/list", &deploycanary.ListController{})
+	beego.Router("/gui/deploy/deploycanary/create", &deploycanary.CreateController{})
+	beego.Router("/gui/deploy/deploycanary/pause", &deploycanary.PauseController{})
+	beego.Router("/gui/deploy/deploycanary/resume", &deploycanary.ResumeController{})
+	beego.Router("/gui/deploy/deploycanary/promote", &deploycanary.PromoteController{})
+	beego.Router("/gui/deploy/deploycanary/abort", &deploycanary.AbortController{})
+	beego.Router("/gui/deploy/deploycanary/delete", &deploycanary.DeleteController{})
 	beego.Router("/gui/deploy/autoscaler/list", &autoscaler.ListController{})
 	beego.Router("/gui/deploy/autoscaler/edit", &autoscaler.EditController{})
 	beego.Router("/gui/deploy/autoscaler/delete", &autoscaler.DeleteController{})
 	beego.Router("/gui/deploy/deployclusterapplication/list", &deployclusterapplication.ListController{})
 	beego.Router("/gui/deploy/deployclusterapplication/size", &deployclusterapplication.SizeController{})
 	beego.Router("/gui/deploy/deployclusterapplication/delete", &deployclusterapplication.DeleteController{})
+	beego.Router("/gui/deploy/deploymanifest/import", &deploymanifest.ImportController{})
+	beego.Router("/gui/deploy/deploymanifest/apply", &deploymanifest.ApplyController{})
 	beego.Router("/gui/deploy/clone/select", &clone.SelectController{})
 	beego.Router("/gui/deploy/clone/topology", &clone.TopologyController{})
 	beego.Router("/gui/inventory/replicationcontroller/list", &replicationcontroller.ListController{})
@@ -128,12 +154,17 @@ func init() {
 	beego.Router("/gui/monitor/historicalcontainer", &historicalcontainer.IndexController{})
 	beego.Router("/gui/monitor/historicalcontainer/data", &historicalcontainer.DataController{})
 	beego.Router("/gui/event/audit/list", &audit.ListController{})
+	beego.Router("/gui/event/audit/export", &audit.ExportController{})
+	beego.Router("/gui/event/audit/verify", &audit.VerifyController{})
 	beego.Router("/gui/event/kubernetes/list", &kubernetes.ListController{})
 	beego.Router("/gui/event/kubernetes/acknowledge", &kubernetes.AcknowledgeController{})
 	beego.Router("/gui/notification/notifier/list", &notifier.ListController{})
 	beego.Router("/gui/notification/notifier/edit", &notifier.EditController{})
 	beego.Router("/gui/notification/notifier/delete", &notifier.DeleteController{})
 	beego.Router("/gui/system/about", &about.IndexController{})
+	beego.Router("/gui/system/cache/list", &systemcache.ListController{})
+	beego.Router("/gui/system/cache/flush", &systemcache.FlushController{})
+	beego.Router("/gui/system/cluster/select", &systemcluster.SelectController{})
 	beego.Router("/gui/system/namespace/list", &namespace.ListController{})
 	beego.Router("/gui/system/namespace/edit", &namespace.EditController{})
 	beego.Router("/gui/system/namespace/select", &namespace.SelectController{})
@@ -151,9 +182,14 @@ func init() {
 	beego.Router("/gui/system/rbac/user/list", &user.ListController{})
 	beego.Router("/gui/system/rbac/user/delete", &user.DeleteController{})
 	beego.Router("/gui/system/rbac/user/edit", &user.EditController{})
+	beego.Router("/gui/system/rbac/user/token/list", &token.ListController{})
+	beego.Router("/gui/system/rbac/user/token/delete", &token.DeleteController{})
+	beego.Router("/gui/system/rbac/user/token/edit", &token.EditController{})
 	beego.Router("/gui/system/rbac/role/list", &role.ListController{})
 	beego.Router("/gui/system/rbac/role/delete", &role.DeleteController{})
 	beego.Router("/gui/system/rbac/role/edit", &role.EditController{})
+	beego.Router("/gui/system/rbac/session/list", &session.ListController{})
+	beego.Router("/gui/system/rbac/session/delete", &session.DeleteController{})
 	beego.Router("/gui/system/privateregistry/server/list", &privateregistryserver.ListController{})
 	beego.Router("/gui/system/privateregistry/server/edit", &privateregistryserver.EditController{})
 	beego.Router("/gui/system/privateregistry/server/delete", &privateregistryserver.DeleteController{})
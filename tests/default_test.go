@@ -1,39 +1,596 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
 package test
 
 import (
+	"github.com/astaxie/beego"
+	"github.com/cloudawan/cloudone_gui/controllers/identity"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/bluegreenpromotion"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/canary"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/configuration"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/fakebackend"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/sessionstore"
+	_ "github.com/cloudawan/cloudone_gui/routers"
+	"golang.org/x/net/websocket"
+	"io/ioutil"
+	"net"
 	"net/http"
+	"net/http/cookiejar"
 	"net/http/httptest"
-	"testing"
-	"runtime"
+	"net/url"
 	"path/filepath"
-	_ "github.com/cloudawan/cloudone_gui/routers"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
 
-	"github.com/astaxie/beego"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
+var (
+	fakeBackend = fakebackend.New()
+	guiServer   *httptest.Server
+	csrfRegexp  = regexp.MustCompile(`<meta name="csrf-token" content="([^"]*)">`)
+	// The login form has the token of the session started before login
+	loginCSRFRegexp = regexp.MustCompile(`<input type="hidden" name="_csrf" value="([^"]*)">`)
+	// The ticket is escaped as the javascript string in the page
+	ticketRegexp = regexp.MustCompile(`ticket=" \+ encodeURIComponent\("([^"]*)"\)`)
+)
+
+// init points the GUI to the fake backend so the endpoints are tested without cloudone, cloudone_analysis and kubernetes
 func init() {
-	_, file, _, _ := runtime.Caller(1)
-	apppath, _ := filepath.Abs(filepath.Dir(filepath.Join(file, ".." + string(filepath.Separator))))
+	_, file, _, _ := runtime.Caller(0)
+	apppath, _ := filepath.Abs(filepath.Dir(filepath.Join(file, ".."+string(filepath.Separator))))
+	if _, err := configuration.Load([]string{"-config", filepath.Join(apppath, "conf", "app.conf")}); err != nil {
+		panic(err)
+	}
+	// The workflows driven by the lease such as the canary require the shared store so the file store is used
+	sessionStoreDirectory, err := ioutil.TempDir("", "sessionstore")
+	if err != nil {
+		panic(err)
+	}
+	beego.AppConfig.Set("sessionStore", sessionstore.KindFile)
+	beego.AppConfig.Set("sessionStoreAddress", sessionStoreDirectory)
+	if err := sessionstore.Initialize(); err != nil {
+		panic(err)
+	}
 	beego.TestBeegoInit(apppath)
+
+	fakeServer := httptest.NewServer(fakeBackend)
+	if err := fakebackend.Configure(fakeServer.Listener.Addr().String()); err != nil {
+		panic(err)
+	}
+
+	beego.InsertFilter("/gui/*", beego.BeforeRouter, identity.FilterUser)
+	beego.InsertFilter("/gui/*", beego.BeforeRouter, identity.FilterCSRF)
+	guiServer = httptest.NewServer(beego.BeeApp.Handlers)
 }
 
+// newClient keeps the session cookie and doesn't follow the redirect so the location is checked
+func newClient() *http.Client {
+	jar, _ := cookiejar.New(nil)
+	return &http.Client{
+		Jar: jar,
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func get(client *http.Client, path string) (*http.Response, string) {
+	response, err := client.Get(guiServer.URL + path)
+	So(err, ShouldBeNil)
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	So(err, ShouldBeNil)
+	return response, string(body)
+}
+
+func postForm(client *http.Client, path string, values url.Values) *http.Response {
+	response, err := client.PostForm(guiServer.URL+path, values)
+	So(err, ShouldBeNil)
+	response.Body.Close()
+	return response
+}
+
+func postFormAndRead(client *http.Client, path string, values url.Values) (*http.Response, string) {
+	response, err := client.PostForm(guiServer.URL+path, values)
+	So(err, ShouldBeNil)
+	defer response.Body.Close()
+	body, err := ioutil.ReadAll(response.Body)
+	So(err, ShouldBeNil)
+	return response, string(body)
+}
+
+// login returns the CSRF token of the session
+// getLoginCSRFToken opens the login page to start the session before login and returns the token of the login form
+func getLoginCSRFToken(client *http.Client) string {
+	_, body := get(client, "/gui/login")
+	matchSlice := loginCSRFRegexp.FindStringSubmatch(body)
+	So(matchSlice, ShouldHaveLength, 2)
+	return matchSlice[1]
+}
 
-// TestMain is a sample to run an endpoint test
-func TestMain(t *testing.T) {
-	r, _ := http.NewRequest("GET", "/", nil)
-	w := httptest.NewRecorder()
-	beego.BeeApp.Handlers.ServeHTTP(w, r)
+func login(client *http.Client, username string, password string) string {
+	loginCSRFToken := getLoginCSRFToken(client)
+	response := postForm(client, "/gui/login", url.Values{
+		"username":       {username},
+		"password":       {password},
+		"timeZoneOffset": {"0"},
+		"_csrf":          {loginCSRFToken},
+	})
+	So(response.StatusCode, ShouldEqual, 302)
+	So(response.Header.Get("Location"), ShouldEqual, "/gui/dashboard/topology/")
 
-	beego.Trace("testing", "TestMain", "Code[%d]\n%s", w.Code, w.Body.String())
+	_, body := get(client, "/gui/system/namespace/list")
+	matchSlice := csrfRegexp.FindStringSubmatch(body)
+	So(matchSlice, ShouldHaveLength, 2)
+	// The token is renewed when the user logins
+	So(matchSlice[1], ShouldNotEqual, loginCSRFToken)
+	return matchSlice[1]
+}
 
-	Convey("Subject: Test Station Endpoint\n", t, func() {
-	        Convey("Status Code Should Be 200", func() {
-	                So(w.Code, ShouldEqual, 200)
-	        })
-	        Convey("The Result Should Not Be Empty", func() {
-	                So(w.Body.Len(), ShouldBeGreaterThan, 0)
-	        })
+func TestLoginPage(t *testing.T) {
+	Convey("Subject: Login page\n", t, func() {
+		response, body := get(newClient(), "/gui/login")
+		Convey("Status Code Should Be 200", func() {
+			So(response.StatusCode, ShouldEqual, 200)
+		})
+		Convey("The Login Form Should Be Rendered", func() {
+			So(body, ShouldContainSubstring, "Please sign in")
+		})
 	})
 }
 
+func TestLogin(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Login with the fake backend\n", t, func() {
+		Convey("The Page Without Login Should Redirect To Login", func() {
+			response, _ := get(newClient(), "/gui/system/namespace/list")
+			So(response.StatusCode, ShouldEqual, 302)
+			So(response.Header.Get("Location"), ShouldStartWith, "/gui/login")
+		})
+		Convey("The Wrong Password Should Be Rejected", func() {
+			client := newClient()
+			response := postForm(client, "/gui/login", url.Values{
+				"username":       {fakebackend.DemoUserName},
+				"password":       {"wrong"},
+				"timeZoneOffset": {"0"},
+				"_csrf":          {getLoginCSRFToken(client)},
+			})
+			So(response.StatusCode, ShouldEqual, 302)
+			So(response.Header.Get("Location"), ShouldEqual, "/gui/login/")
+		})
+		Convey("The Login Without CSRF Token Should Be Rejected", func() {
+			client := newClient()
+			getLoginCSRFToken(client)
+			postForm(client, "/gui/login", url.Values{
+				"username":       {fakebackend.DemoUserName},
+				"password":       {fakebackend.DemoPassword},
+				"timeZoneOffset": {"0"},
+			})
+			response, _ := get(client, "/gui/system/namespace/list")
+			So(response.StatusCode, ShouldEqual, 302)
+			So(response.Header.Get("Location"), ShouldStartWith, "/gui/login")
+		})
+		Convey("The Demo User Should Login", func() {
+			So(login(newClient(), fakebackend.DemoUserName, fakebackend.DemoPassword), ShouldNotBeEmpty)
+		})
+	})
+}
+
+func TestNamespace(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Namespace endpoints\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		Convey("The Seeded Namespaces Should Be Listed", func() {
+			response, body := get(client, "/gui/system/namespace/list")
+			So(response.StatusCode, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, "demo")
+		})
+		Convey("The Form Without CSRF Token Should Be Rejected", func() {
+			postForm(client, "/gui/system/namespace/edit", url.Values{"name": {"rejected"}})
+			_, body := get(client, "/gui/system/namespace/list")
+			So(body, ShouldNotContainSubstring, "rejected")
+		})
+		Convey("The Created Namespace Should Be Listed", func() {
+			response := postForm(client, "/gui/system/namespace/edit", url.Values{"name": {"created"}, "_csrf": {csrfToken}})
+			So(response.Header.Get("Location"), ShouldEqual, "/gui/system/namespace/list")
+			_, body := get(client, "/gui/system/namespace/list")
+			So(body, ShouldContainSubstring, "created")
+		})
+	})
+}
+
+func TestDeploy(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Deploy endpoints\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		Convey("The Seeded Deploys Should Be Listed", func() {
+			response, body := get(client, "/gui/deploy/deploy/list")
+			So(response.StatusCode, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, "web")
+			So(body, ShouldContainSubstring, "api")
+		})
+		Convey("The Resized Deploy Should Have The Pods", func() {
+			response := postForm(client, "/gui/deploy/deploy/resize", url.Values{"name": {"web"}, "size": {"4"}, "_csrf": {csrfToken}})
+			So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploy/list")
+			_, body := get(client, "/gui/inventory/replicationcontroller/list")
+			So(strings.Count(body, "webv3-"), ShouldBeGreaterThanOrEqualTo, 4)
+		})
+	})
+}
+
+func TestDeployRollback(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Deploy rollback endpoints\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		response := postForm(client, "/gui/deploy/deploy/update", url.Values{"name": {"api"}, "version": {"v1"}, "_csrf": {csrfToken}})
+		So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploy/list")
+
+		Convey("The Version Before Update Should Be Recorded", func() {
+			_, body := get(client, "/gui/deploy/deploy/revision?name=api")
+			So(body, ShouldContainSubstring, "baseline")
+			So(body, ShouldContainSubstring, "/gui/deploy/deploy/rollback?name=api&revision=1")
+		})
+		Convey("The Rollback Should Be Confirmed With The Mode", func() {
+			response, body := get(client, "/gui/deploy/deploy/rollback?name=api&revision=1")
+			So(response.StatusCode, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, "rolling updated")
+		})
+		Convey("The Rollback Should Apply The Revision", func() {
+			response := postForm(client, "/gui/deploy/deploy/rollback", url.Values{"name": {"api"}, "revision": {"1"}, "_csrf": {csrfToken}})
+			So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploy/revision?name=api")
+			_, body := get(client, "/gui/inventory/replicationcontroller/list")
+			So(body, ShouldContainSubstring, "apiv2")
+			So(body, ShouldNotContainSubstring, "apiv1")
+			_, body = get(client, "/gui/deploy/deploy/revision?name=api")
+			So(body, ShouldContainSubstring, "rollback to revision 1")
+		})
+	})
+}
+
+func TestDeployRollbackRecreateFailure(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Deploy rollback recreating the deploy fails\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		// The environment differs in the same version so the rollback deletes and creates the deploy
+		for _, value := range []string{"1", "2"} {
+			response := postForm(client, "/gui/deploy/deploy/update", url.Values{"name": {"api"}, "version": {"v2"}, "v2_MODE": {value}, "_csrf": {csrfToken}})
+			So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploy/list")
+		}
+		_, body := get(client, "/gui/deploy/deploy/revision?name=api")
+		revisionSlice := regexp.MustCompile(`rollback\?name=api&revision=(\d+)`).FindAllStringSubmatch(body, -1)
+		So(len(revisionSlice), ShouldBeGreaterThanOrEqualTo, 2)
+		// The latest first
+		previousRevision := revisionSlice[1][1]
+		response, body := get(client, "/gui/deploy/deploy/rollback?name=api&revision="+previousRevision)
+		So(response.StatusCode, ShouldEqual, 200)
+		So(body, ShouldContainSubstring, "deleted and created again")
+
+		Convey("The Deploy Should Be Restored When The Creation Fails", func() {
+			fakeBackend.FailDeployCreate(1)
+			response := postForm(client, "/gui/deploy/deploy/rollback", url.Values{"name": {"api"}, "revision": {previousRevision}, "_csrf": {csrfToken}})
+			So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploy/revision?name=api")
+			_, body := get(client, "/gui/deploy/deploy/revision?name=api")
+			So(body, ShouldContainSubstring, "The deploy is restored.")
+			So(body, ShouldNotContainSubstring, "may be deleted without being created again")
+			_, body = get(client, "/gui/inventory/replicationcontroller/list")
+			So(body, ShouldContainSubstring, "apiv2")
+		})
+		Convey("The Rollback Should Be Retried When The Restoration Fails", func() {
+			fakeBackend.FailDeployCreate(2)
+			postForm(client, "/gui/deploy/deploy/rollback", url.Values{"name": {"api"}, "revision": {previousRevision}, "_csrf": {csrfToken}})
+			_, body := get(client, "/gui/deploy/deploy/revision?name=api")
+			So(body, ShouldContainSubstring, "Roll back to any revision to create it.")
+			So(body, ShouldContainSubstring, "may be deleted without being created again")
+			_, body = get(client, "/gui/inventory/replicationcontroller/list")
+			So(body, ShouldNotContainSubstring, "apiv2")
+
+			response, body := get(client, "/gui/deploy/deploy/rollback?name=api&revision="+previousRevision)
+			So(response.StatusCode, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, "will be created")
+			postForm(client, "/gui/deploy/deploy/rollback", url.Values{"name": {"api"}, "revision": {previousRevision}, "_csrf": {csrfToken}})
+			_, body = get(client, "/gui/inventory/replicationcontroller/list")
+			So(body, ShouldContainSubstring, "apiv2")
+		})
+	})
+}
+
+func TestDeployCanaryPromote(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Canary deployment promoted\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		Convey("The Versions Other Than The Current Should Be Offered", func() {
+			response, body := get(client, "/gui/deploy/deploycanary/create?name=web&currentVersion=v3")
+			So(response.StatusCode, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, `<option value="v2">`)
+			So(body, ShouldNotContainSubstring, `<option value="v3">`)
+
+			Convey("The Canary Should Take Its Share Of The Replicas", func() {
+				response := postForm(client, "/gui/deploy/deploycanary/create", url.Values{
+					"name":           {"web"},
+					"version":        {"v2"},
+					"step":           {"50,100"},
+					"pauseInSecond":  {"0"},
+					"restartMaximum": {"0"},
+					"eventMaximum":   {"0"},
+					"_csrf":          {csrfToken},
+				})
+				So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploycanary/list")
+				canary.Tick()
+				_, body := get(client, "/gui/inventory/replicationcontroller/list")
+				So(strings.Count(body, "webv2-canary-"), ShouldBeGreaterThanOrEqualTo, 1)
+				So(strings.Count(body, "webv3-"), ShouldBeGreaterThanOrEqualTo, 1)
+
+				Convey("The Last Step Should Promote The Canary", func() {
+					canary.Tick()
+					_, body := get(client, "/gui/deploy/deploycanary/list")
+					So(body, ShouldContainSubstring, canary.StatePromoted)
+					_, body = get(client, "/gui/inventory/replicationcontroller/list")
+					So(body, ShouldContainSubstring, "webv2-")
+					So(body, ShouldNotContainSubstring, "webv2-canary")
+					So(body, ShouldNotContainSubstring, "webv3")
+					_, body = get(client, "/gui/deploy/deploy/revision?name=web")
+					So(body, ShouldContainSubstring, "canary")
+				})
+			})
+		})
+	})
+}
+
+func TestDeployCanaryRollback(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Canary deployment rolled back by the events\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		response := postForm(client, "/gui/deploy/deploycanary/create", url.Values{
+			"name":           {"web"},
+			"version":        {"v1"},
+			"step":           {"50,100"},
+			"pauseInSecond":  {"3600"},
+			"restartMaximum": {"0"},
+			"eventMaximum":   {"0"},
+			"_csrf":          {csrfToken},
+		})
+		So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploycanary/list")
+		canary.Tick()
+
+		Convey("The Warning Event Of The Canary Pod Should Roll It Back", func() {
+			fakeBackend.AddKubernetesEvent("default", "webv1-canary-00042", "BackOff", "Back-off restarting failed container")
+			canary.Tick()
+			_, body := get(client, "/gui/deploy/deploycanary/list")
+			So(body, ShouldContainSubstring, canary.StateRolledBack)
+			So(body, ShouldContainSubstring, "BackOff")
+			_, body = get(client, "/gui/inventory/replicationcontroller/list")
+			So(body, ShouldNotContainSubstring, "webv1-canary")
+			So(strings.Count(body, "webv3-"), ShouldBeGreaterThanOrEqualTo, 2)
+		})
+	})
+}
+
+// promote starts the promotion of web v2 to the idle namespace demo and ticks until it ends
+func promote(client *http.Client, csrfToken string, probe string) {
+	response := postForm(client, "/gui/deploy/deploybluegreen/promote", url.Values{
+		"imageInformation":     {"web"},
+		"version":              {"v2"},
+		"idleNamespace":        {"demo"},
+		"probe":                {probe},
+		"probeTimeoutInSecond": {"1"},
+		"successThreshold":     {"2"},
+		"failureThreshold":     {"1"},
+		"soakInSecond":         {"0"},
+		"readyTimeoutInSecond": {"60"},
+		"_csrf":                {csrfToken},
+	})
+	So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploybluegreen/promotion?imageInformation=web")
+	for i := 0; i < 20; i++ {
+		bluegreenpromotion.Tick()
+	}
+}
+
+// readPromotionWebSocket returns the steps streamed by the websocket of the promotion page
+func readPromotionWebSocket(client *http.Client) string {
+	_, body := get(client, "/gui/deploy/deploybluegreen/promotion?imageInformation=web")
+	matchSlice := ticketRegexp.FindStringSubmatch(body)
+	So(matchSlice, ShouldHaveLength, 2)
+
+	guiURL, _ := url.Parse(guiServer.URL)
+	config, err := websocket.NewConfig("ws://"+guiURL.Host+"/gui/deploy/deploybluegreen/promotion/websocket?imageInformation=web&ticket="+url.QueryEscape(matchSlice[1]), guiServer.URL)
+	So(err, ShouldBeNil)
+	for _, cookie := range client.Jar.Cookies(guiURL) {
+		config.Header.Add("Cookie", cookie.String())
+	}
+	ws, err := websocket.DialConfig(config)
+	So(err, ShouldBeNil)
+	defer ws.Close()
+	byteSlice, _ := ioutil.ReadAll(ws)
+	return string(byteSlice)
+}
+
+func TestDeployBlueGreenPromotion(t *testing.T) {
+	fakeBackend.Reset()
+
+	healthServer := httptest.NewServer(http.HandlerFunc(func(responseWriter http.ResponseWriter, request *http.Request) {
+		responseWriter.WriteHeader(http.StatusOK)
+	}))
+	defer healthServer.Close()
+	healthAddress := healthServer.Listener.Addr().String()
+
+	Convey("Subject: Blue green promotion passing the health gates\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		Convey("The Idle Namespace Should Be Offered", func() {
+			response, body := get(client, "/gui/deploy/deploybluegreen/promote?imageInformation=web")
+			So(response.StatusCode, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, `<option value="demo">`)
+			So(body, ShouldNotContainSubstring, `<option value="default">`)
+
+			Convey("The Healthy Version Should Be Selected", func() {
+				promote(client, csrfToken, "http "+healthAddress+" /health\ntcp "+healthAddress)
+				_, body := get(client, "/gui/deploy/deploybluegreen/list")
+				So(body, ShouldContainSubstring, "v2 to demo "+bluegreenpromotion.StateSucceeded)
+				So(body, ShouldContainSubstring, "<td>demo</td>")
+
+				Convey("The Steps Should Be Streamed", func() {
+					output := readPromotionWebSocket(client)
+					So(output, ShouldContainSubstring, "Version is updated from v3 to v2 in namespace demo")
+					So(output, ShouldContainSubstring, "[probe] All probes succeed in round 2 of 2")
+					So(output, ShouldContainSubstring, "Namespace demo is selected. Namespace default is kept running")
+					So(output, ShouldEndWith, "The promotion is "+bluegreenpromotion.StateSucceeded+"\n")
+
+					Convey("The Revert Should Select The Previous Namespace", func() {
+						response := postForm(client, "/gui/deploy/deploybluegreen/revert", url.Values{"imageInformation": {"web"}, "_csrf": {csrfToken}})
+						So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploybluegreen/promotion?imageInformation=web")
+						_, body := get(client, "/gui/deploy/deploybluegreen/list")
+						So(body, ShouldContainSubstring, "v2 to demo "+bluegreenpromotion.StateReverted)
+						So(body, ShouldContainSubstring, "<td>default</td>")
+					})
+				})
+			})
+		})
+	})
+}
+
+func TestDeployBlueGreenPromotionFailure(t *testing.T) {
+	fakeBackend.Reset()
+
+	// Nothing listens on the port after it is closed
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedAddress := listener.Addr().String()
+	listener.Close()
+
+	Convey("Subject: Blue green promotion failing the health gates\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		promote(client, csrfToken, "tcp "+closedAddress)
+
+		Convey("The Selected Namespace Should Be Kept", func() {
+			_, body := get(client, "/gui/deploy/deploybluegreen/list")
+			So(body, ShouldContainSubstring, "v2 to demo "+bluegreenpromotion.StateFailed)
+			So(body, ShouldContainSubstring, "<td>default</td>")
+			output := readPromotionWebSocket(client)
+			So(output, ShouldContainSubstring, "Failed in step probe since the health gate fails 1 rounds.")
+		})
+	})
+}
+
+const manifestText = `# Redis with its service
+apiVersion: v1
+kind: ReplicationController
+metadata:
+  name: redis
+spec:
+  replicas: 2
+  template:
+    metadata:
+      labels:
+        name: redis
+    spec:
+      containers:
+      - name: redis
+        image: redis:3.0
+        ports:
+        - containerPort: 6379
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: redis
+spec:
+  selector:
+    name: redis
+  ports:
+  - port: 6379
+---
+{"apiVersion": "v1", "kind": "ReplicationController", "metadata": {"name": "worker"},
+ "spec": {"template": {"metadata": {"labels": {"name": "worker"}}, "spec": {"containers": [{"name": "worker", "image": "busybox"}]}}}}
+`
+
+func TestDeployManifest(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Manifest import\n", t, func() {
+		client := newClient()
+		csrfToken := login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		Convey("The Preview Should Show What Will Be Created", func() {
+			response, body := postFormAndRead(client, "/gui/deploy/deploymanifest/import", url.Values{"manifest": {manifestText}, "_csrf": {csrfToken}})
+			So(response.StatusCode, ShouldEqual, 200)
+			So(body, ShouldContainSubstring, "redis:3.0")
+			So(body, ShouldContainSubstring, "6379/TCP")
+			So(body, ShouldContainSubstring, "busybox")
+			So(body, ShouldNotContainSubstring, `value="Apply" hidden`)
+			_, body = get(client, "/gui/deploy/deployclusterapplication/list")
+			So(body, ShouldNotContainSubstring, "worker")
+		})
+		Convey("The Unsupported Document Should Not Be Applied", func() {
+			_, body := postFormAndRead(client, "/gui/deploy/deploymanifest/import", url.Values{"manifest": {manifestText + "---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: setting\n"}, "_csrf": {csrfToken}})
+			So(body, ShouldContainSubstring, "is not supported")
+			So(body, ShouldContainSubstring, `value="Apply" hidden`)
+		})
+		Convey("The Applied Manifest Should Be Tracked As Third-Party Services", func() {
+			response := postForm(client, "/gui/deploy/deploymanifest/apply", url.Values{"manifest": {manifestText}, "_csrf": {csrfToken}})
+			So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deployclusterapplication/list")
+			_, body := get(client, "/gui/deploy/deployclusterapplication/list")
+			So(body, ShouldContainSubstring, "deployclusterapplication/size?name=redis&size=2")
+			So(body, ShouldContainSubstring, "deployclusterapplication/size?name=worker&size=1")
+			_, body = get(client, "/gui/inventory/replicationcontroller/list")
+			So(strings.Count(body, "redis-0"), ShouldBeGreaterThanOrEqualTo, 2)
+
+			Convey("The Same Manifest Should Conflict With The Launched Ones", func() {
+				response := postForm(client, "/gui/deploy/deploymanifest/apply", url.Values{"manifest": {manifestText}, "_csrf": {csrfToken}})
+				So(response.Header.Get("Location"), ShouldEqual, "/gui/deploy/deploymanifest/import")
+			})
+		})
+	})
+}
+
+func TestHealthCheck(t *testing.T) {
+	fakeBackend.Reset()
+
+	Convey("Subject: Health check endpoint\n", t, func() {
+		client := newClient()
+		login(client, fakebackend.DemoUserName, fakebackend.DemoPassword)
+
+		response, body := get(client, "/guirestapi/v1/dashboardhealthcheck/")
+		Convey("Status Code Should Be 200", func() {
+			So(response.StatusCode, ShouldEqual, 200)
+		})
+		Convey("The Nodes Of The Fake Should Be Reported", func() {
+			So(body, ShouldContainSubstring, "10.0.0.11")
+		})
+	})
+}
@@ -0,0 +1,35 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// etcdstandin is the local stand-in of etcd to run several GUI replicas sharing the sessions
+// without deploying the real one. It serves the subset of the v3 JSON gateway used by the session store
+// and keeps the keys in memory. Set sessionStore = etcd and sessionStoreAddress = http://127.0.0.1:2379 and run
+//
+//	go run ./tool/etcdstandin -address 127.0.0.1:2379
+package main
+
+import (
+	"flag"
+	"github.com/cloudawan/cloudone_gui/tool/etcdstandin/standin"
+	"log"
+	"net/http"
+)
+
+func main() {
+	address := flag.String("address", "127.0.0.1:2379", "The address to listen")
+	flag.Parse()
+
+	log.Println("etcd stand-in listens on", *address)
+	log.Fatal(http.ListenAndServe(*address, standin.NewHandler()))
+}
@@ -0,0 +1,353 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package standin serves the subset of the etcd v3 JSON gateway used by the session store with the keys in memory.
+// It is shared by the etcdstandin tool and the tests of the session store.
+package standin
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type keyValue struct {
+	Key   string `json:"key"`
+	Value string `json:"value,omitempty"`
+}
+
+type rangeRequest struct {
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end"`
+	KeysOnly bool   `json:"keys_only"`
+	PrevKV   bool   `json:"prev_kv"`
+}
+
+type putRequest struct {
+	Key   string      `json:"key"`
+	Value string      `json:"value"`
+	Lease json.Number `json:"lease"`
+}
+
+type compare struct {
+	Key            string      `json:"key"`
+	Target         string      `json:"target"`
+	Result         string      `json:"result"`
+	CreateRevision json.Number `json:"create_revision"`
+	Value          string      `json:"value"`
+}
+
+type requestOperation struct {
+	RequestPut *putRequest `json:"request_put"`
+}
+
+// txnRequest supports the comparison of equal value or create revision and the put operation only
+type txnRequest struct {
+	CompareSlice []compare          `json:"compare"`
+	SuccessSlice []requestOperation `json:"success"`
+	FailureSlice []requestOperation `json:"failure"`
+}
+
+type leaseGrantRequest struct {
+	TTL json.Number `json:"TTL"`
+}
+
+type item struct {
+	value   []byte
+	leaseID int64
+}
+
+type standin struct {
+	lock             sync.Mutex
+	itemMap          map[string]item
+	leaseExpiredMap  map[int64]time.Time
+	lastLeaseID      int64
+	revision         int64
+	cleanedLeaseTime time.Time
+}
+
+func decode(text string) string {
+	byteSlice, _ := base64.StdEncoding.DecodeString(text)
+	return string(byteSlice)
+}
+
+func encode(text string) string {
+	return base64.StdEncoding.EncodeToString([]byte(text))
+}
+
+// removeExpired must be called with the lock held
+func (standin *standin) removeExpired() {
+	now := time.Now()
+	for key, item := range standin.itemMap {
+		if expiredTime, ok := standin.leaseExpiredMap[item.leaseID]; item.leaseID != 0 && (ok == false || now.After(expiredTime)) {
+			delete(standin.itemMap, key)
+		}
+	}
+	if now.Sub(standin.cleanedLeaseTime) > time.Minute {
+		for leaseID, expiredTime := range standin.leaseExpiredMap {
+			if now.After(expiredTime) {
+				delete(standin.leaseExpiredMap, leaseID)
+			}
+		}
+		standin.cleanedLeaseTime = now
+	}
+}
+
+// getKeySlice returns the keys of the range in order. The empty range end means the key only.
+func (standin *standin) getKeySlice(key string, rangeEnd string) []string {
+	keySlice := make([]string, 0)
+	if rangeEnd == "" {
+		if _, ok := standin.itemMap[key]; ok {
+			keySlice = append(keySlice, key)
+		}
+		return keySlice
+	}
+	for existingKey := range standin.itemMap {
+		if existingKey >= key && (rangeEnd == "\x00" || existingKey < rangeEnd) {
+			keySlice = append(keySlice, existingKey)
+		}
+	}
+	sort.Strings(keySlice)
+	return keySlice
+}
+
+func (standin *standin) header() map[string]string {
+	return map[string]string{"revision": strconv.FormatInt(standin.revision, 10)}
+}
+
+func writeJSON(responseWriter http.ResponseWriter, response interface{}) {
+	responseWriter.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(responseWriter).Encode(response)
+}
+
+func (standin *standin) handleRange(responseWriter http.ResponseWriter, request *http.Request) {
+	rangeRequest := rangeRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&rangeRequest); err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	standin.lock.Lock()
+	defer standin.lock.Unlock()
+	standin.removeExpired()
+
+	keyValueSlice := make([]keyValue, 0)
+	for _, key := range standin.getKeySlice(decode(rangeRequest.Key), decode(rangeRequest.RangeEnd)) {
+		kv := keyValue{Key: encode(key)}
+		if rangeRequest.KeysOnly == false {
+			kv.Value = base64.StdEncoding.EncodeToString(standin.itemMap[key].value)
+		}
+		keyValueSlice = append(keyValueSlice, kv)
+	}
+
+	writeJSON(responseWriter, map[string]interface{}{
+		"header": standin.header(),
+		"kvs":    keyValueSlice,
+		"count":  strconv.Itoa(len(keyValueSlice)),
+	})
+}
+
+func (standin *standin) handlePut(responseWriter http.ResponseWriter, request *http.Request) {
+	putRequest := putRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&putRequest); err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	standin.lock.Lock()
+	defer standin.lock.Unlock()
+	standin.removeExpired()
+
+	if status, err := standin.put(putRequest); err != nil {
+		http.Error(responseWriter, err.Error(), status)
+		return
+	}
+
+	writeJSON(responseWriter, map[string]interface{}{"header": standin.header()})
+}
+
+// put must be called with the lock held. It returns the status code with the error.
+func (standin *standin) put(putRequest putRequest) (int, error) {
+	value, err := base64.StdEncoding.DecodeString(putRequest.Value)
+	if err != nil {
+		return http.StatusBadRequest, err
+	}
+	leaseID := int64(0)
+	if putRequest.Lease != "" {
+		leaseID, err = putRequest.Lease.Int64()
+		if err != nil {
+			return http.StatusBadRequest, err
+		}
+	}
+
+	if _, ok := standin.leaseExpiredMap[leaseID]; leaseID != 0 && ok == false {
+		return http.StatusNotFound, errors.New(`{"error":"etcdserver: requested lease not found","code":5}`)
+	}
+	standin.revision++
+	standin.itemMap[decode(putRequest.Key)] = item{value, leaseID}
+	return http.StatusOK, nil
+}
+
+// isCompareSucceeded must be called with the lock held. The missing key has the create revision 0.
+func (standin *standin) isCompareSucceeded(compare compare) (bool, error) {
+	if compare.Result != "" && compare.Result != "EQUAL" {
+		return false, errors.New("Only the result EQUAL is supported")
+	}
+	item, ok := standin.itemMap[decode(compare.Key)]
+	switch compare.Target {
+	case "CREATE":
+		createRevision, err := compare.CreateRevision.Int64()
+		if err != nil || createRevision != 0 {
+			return false, errors.New("Only the create revision 0 is supported")
+		}
+		return ok == false, nil
+	case "VALUE":
+		value, err := base64.StdEncoding.DecodeString(compare.Value)
+		if err != nil {
+			return false, err
+		}
+		return ok && bytes.Equal(item.value, value), nil
+	default:
+		return false, errors.New("Only the target CREATE and VALUE are supported")
+	}
+}
+
+func (standin *standin) handleTxn(responseWriter http.ResponseWriter, request *http.Request) {
+	txnRequest := txnRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&txnRequest); err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	standin.lock.Lock()
+	defer standin.lock.Unlock()
+	standin.removeExpired()
+
+	succeeded := true
+	for _, compare := range txnRequest.CompareSlice {
+		result, err := standin.isCompareSucceeded(compare)
+		if err != nil {
+			http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+			return
+		}
+		succeeded = succeeded && result
+	}
+	operationSlice := txnRequest.FailureSlice
+	if succeeded {
+		operationSlice = txnRequest.SuccessSlice
+	}
+	for _, operation := range operationSlice {
+		if operation.RequestPut == nil {
+			http.Error(responseWriter, "Only the put operation is supported", http.StatusBadRequest)
+			return
+		}
+		if status, err := standin.put(*operation.RequestPut); err != nil {
+			http.Error(responseWriter, err.Error(), status)
+			return
+		}
+	}
+
+	writeJSON(responseWriter, map[string]interface{}{
+		"header":    standin.header(),
+		"succeeded": succeeded,
+	})
+}
+
+func (standin *standin) handleDeleteRange(responseWriter http.ResponseWriter, request *http.Request) {
+	rangeRequest := rangeRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&rangeRequest); err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	standin.lock.Lock()
+	defer standin.lock.Unlock()
+	standin.removeExpired()
+
+	previousKeyValueSlice := make([]keyValue, 0)
+	keySlice := standin.getKeySlice(decode(rangeRequest.Key), decode(rangeRequest.RangeEnd))
+	for _, key := range keySlice {
+		previousKeyValueSlice = append(previousKeyValueSlice, keyValue{encode(key), base64.StdEncoding.EncodeToString(standin.itemMap[key].value)})
+		delete(standin.itemMap, key)
+	}
+	if len(keySlice) > 0 {
+		standin.revision++
+	}
+
+	response := map[string]interface{}{
+		"header":  standin.header(),
+		"deleted": strconv.Itoa(len(keySlice)),
+	}
+	if rangeRequest.PrevKV {
+		response["prev_kvs"] = previousKeyValueSlice
+	}
+	writeJSON(responseWriter, response)
+}
+
+func (standin *standin) handleLeaseGrant(responseWriter http.ResponseWriter, request *http.Request) {
+	leaseGrantRequest := leaseGrantRequest{}
+	if err := json.NewDecoder(request.Body).Decode(&leaseGrantRequest); err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+	ttl, err := leaseGrantRequest.TTL.Int64()
+	if err != nil || ttl <= 0 {
+		http.Error(responseWriter, "TTL must be positive", http.StatusBadRequest)
+		return
+	}
+
+	standin.lock.Lock()
+	defer standin.lock.Unlock()
+
+	standin.lastLeaseID++
+	standin.leaseExpiredMap[standin.lastLeaseID] = time.Now().Add(time.Duration(ttl) * time.Second)
+
+	writeJSON(responseWriter, map[string]interface{}{
+		"header": standin.header(),
+		"ID":     strconv.FormatInt(standin.lastLeaseID, 10),
+		"TTL":    strconv.FormatInt(ttl, 10),
+	})
+}
+
+func post(handler func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(responseWriter http.ResponseWriter, request *http.Request) {
+		if request.Method != "POST" {
+			http.Error(responseWriter, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		handler(responseWriter, request)
+	}
+}
+
+// NewHandler serves /v3/kv/range, /v3/kv/put, /v3/kv/deleterange, /v3/kv/txn and /v3/lease/grant
+func NewHandler() http.Handler {
+	standin := &standin{
+		itemMap:         make(map[string]item),
+		leaseExpiredMap: make(map[int64]time.Time),
+	}
+
+	serveMux := http.NewServeMux()
+	serveMux.Handle("/v3/kv/range", post(standin.handleRange))
+	serveMux.Handle("/v3/kv/put", post(standin.handlePut))
+	serveMux.Handle("/v3/kv/deleterange", post(standin.handleDeleteRange))
+	serveMux.Handle("/v3/kv/txn", post(standin.handleTxn))
+	serveMux.Handle("/v3/lease/grant", post(standin.handleLeaseGrant))
+	return serveMux
+}
@@ -0,0 +1,135 @@
+// Copyright 2015 CloudAwan LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// otlpcollector is the local stand-in of the OTLP collector to check the spans exported by the GUI
+// without deploying the real one. Set tracingOTLPEndpoint = http://127.0.0.1:4318/v1/traces and run
+//
+//	go run ./tool/otlpcollector -address 127.0.0.1:4318
+//
+// Each received span is printed as a json line. The latest spans are replied by GET /v1/traces
+// and could be filtered by the query traceId or requestId to connect the GUI request with the backend calls.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"github.com/cloudawan/cloudone_gui/controllers/utility/tracing"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+)
+
+type receivedSpan struct {
+	ServiceName string `json:"serviceName"`
+	tracing.OTLPSpan
+}
+
+func (span receivedSpan) getAttribute(key string) string {
+	for _, attribute := range span.Attributes {
+		if attribute.Key == key {
+			return attribute.Value.StringValue
+		}
+	}
+	return ""
+}
+
+type collector struct {
+	lock              sync.Mutex
+	receivedSpanSlice []receivedSpan
+	maximum           int
+	encoder           *json.Encoder
+}
+
+func (collector *collector) ServeHTTP(responseWriter http.ResponseWriter, request *http.Request) {
+	switch request.Method {
+	case "POST":
+		collector.receive(responseWriter, request)
+	case "GET":
+		collector.list(responseWriter, request)
+	default:
+		http.Error(responseWriter, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (collector *collector) receive(responseWriter http.ResponseWriter, request *http.Request) {
+	exportTraceServiceRequest := tracing.ExportTraceServiceRequest{}
+	err := json.NewDecoder(request.Body).Decode(&exportTraceServiceRequest)
+	if err != nil {
+		http.Error(responseWriter, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	collector.lock.Lock()
+	defer collector.lock.Unlock()
+
+	for _, resourceSpans := range exportTraceServiceRequest.ResourceSpans {
+		serviceName := ""
+		for _, attribute := range resourceSpans.Resource.Attributes {
+			if attribute.Key == "service.name" {
+				serviceName = attribute.Value.StringValue
+			}
+		}
+		for _, scopeSpans := range resourceSpans.ScopeSpans {
+			for _, span := range scopeSpans.Spans {
+				span := receivedSpan{serviceName, span}
+				collector.encoder.Encode(span)
+				collector.receivedSpanSlice = append(collector.receivedSpanSlice, span)
+			}
+		}
+	}
+	if len(collector.receivedSpanSlice) > collector.maximum {
+		collector.receivedSpanSlice = collector.receivedSpanSlice[len(collector.receivedSpanSlice)-collector.maximum:]
+	}
+
+	responseWriter.Header().Set("Content-Type", "application/json")
+	responseWriter.Write([]byte("{}"))
+}
+
+func (collector *collector) list(responseWriter http.ResponseWriter, request *http.Request) {
+	traceID := request.URL.Query().Get("traceId")
+	requestID := request.URL.Query().Get("requestId")
+
+	collector.lock.Lock()
+	filteredSpanSlice := make([]receivedSpan, 0)
+	for _, span := range collector.receivedSpanSlice {
+		if traceID != "" && span.TraceID != traceID {
+			continue
+		}
+		if requestID != "" && span.getAttribute("request.id") != requestID {
+			continue
+		}
+		filteredSpanSlice = append(filteredSpanSlice, span)
+	}
+	collector.lock.Unlock()
+
+	responseWriter.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(responseWriter).Encode(filteredSpanSlice)
+}
+
+func main() {
+	address := flag.String("address", "127.0.0.1:4318", "The address to listen")
+	maximum := flag.Int("maximum", 10000, "The amount of the latest spans kept for GET /v1/traces")
+	flag.Parse()
+
+	collector := &collector{
+		receivedSpanSlice: make([]receivedSpan, 0),
+		maximum:           *maximum,
+		encoder:           json.NewEncoder(os.Stdout),
+	}
+
+	http.Handle("/v1/traces", collector)
+	log.Println("OTLP collector stand-in listens on", *address)
+	log.Fatal(http.ListenAndServe(*address, nil))
+}